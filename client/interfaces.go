@@ -181,6 +181,10 @@ type InstanceServer interface {
 	UpdateInstanceUEFIVars(name string, instanceUEFI api.InstanceUEFIVars, ETag string) (err error)
 
 	ExecInstance(instanceName string, exec api.InstanceExecPost, args *InstanceExecArgs) (op Operation, err error)
+	GetInstanceExecSessions(instanceName string) (sessions []api.InstanceExecSession, err error)
+	GetInstanceExecSession(instanceName string, id string) (session *api.InstanceExecSession, err error)
+	AttachInstanceExecSession(instanceName string, id string, attach api.InstanceExecSessionPost, args *InstanceExecArgs) (op Operation, err error)
+	DeleteInstanceExecSession(instanceName string, id string) (err error)
 	ConsoleInstance(instanceName string, console api.InstanceConsolePost, args *InstanceConsoleArgs) (op Operation, err error)
 	ConsoleInstanceDynamic(instanceName string, console api.InstanceConsolePost, args *InstanceConsoleArgs) (Operation, func(io.ReadWriteCloser) error, error)
 
@@ -218,7 +222,13 @@ type InstanceServer interface {
 
 	GetInstanceLogfiles(name string) (logfiles []string, err error)
 	GetInstanceLogfile(name string, filename string) (content io.ReadCloser, err error)
+	GetInstanceLogfileFollow(name string, filename string) (content io.ReadCloser, err error)
 	DeleteInstanceLogfile(name string, filename string) (err error)
+	GetInstanceSessionRecordings(name string) (recordings []api.InstanceSessionRecording, err error)
+	GetInstanceSessionRecordingFile(name string, filename string) (content io.ReadCloser, err error)
+	DeleteInstanceSessionRecording(name string, filename string) (err error)
+
+	GetInstanceDiff(name string, snapshot string) (changes []api.InstanceFileChange, err error)
 
 	GetInstanceMetadata(name string) (metadata *api.ImageMetadata, ETag string, err error)
 	UpdateInstanceMetadata(name string, metadata api.ImageMetadata, ETag string) (err error)
@@ -228,6 +238,14 @@ type InstanceServer interface {
 	CreateInstanceTemplateFile(instanceName string, templateName string, content io.ReadSeeker) (err error)
 	DeleteInstanceTemplateFile(name string, templateName string) (err error)
 
+	// Instance group functions ("instance_groups" API extension)
+	GetInstanceGroupNames() (names []string, err error)
+	GetInstanceGroups() (groups []api.InstanceGroup, err error)
+	GetInstanceGroup(name string) (group *api.InstanceGroup, ETag string, err error)
+	CreateInstanceGroup(group api.InstanceGroupsPost) (err error)
+	UpdateInstanceGroup(name string, group api.InstanceGroupPut, ETag string) (err error)
+	DeleteInstanceGroup(name string) (err error)
+
 	// Event handling functions
 	GetEvents() (listener *EventListener, err error)
 	GetEventsAllProjects() (listener *EventListener, err error)
@@ -282,11 +300,20 @@ type InstanceServer interface {
 	UpdateNetworkPeer(networkName string, peerName string, peer api.NetworkPeerPut, ETag string) (err error)
 	DeleteNetworkPeer(networkName string, peerName string) (err error)
 
+	// Network reservation functions ("network_reservation" API extension)
+	GetNetworkReservationAddresses(networkName string) ([]string, error)
+	GetNetworkReservations(networkName string) ([]api.NetworkReservation, error)
+	GetNetworkReservation(networkName string, address string) (reservation *api.NetworkReservation, ETag string, err error)
+	CreateNetworkReservation(networkName string, reservation api.NetworkReservationsPost) error
+	UpdateNetworkReservation(networkName string, address string, reservation api.NetworkReservationPut, ETag string) (err error)
+	DeleteNetworkReservation(networkName string, address string) (err error)
+
 	// Network ACL functions ("network_acl" API extension)
 	GetNetworkACLNames() (names []string, err error)
 	GetNetworkACLs() (acls []api.NetworkACL, err error)
 	GetNetworkACL(name string) (acl *api.NetworkACL, ETag string, err error)
 	GetNetworkACLLogfile(name string) (log io.ReadCloser, err error)
+	GetNetworkACLState(name string) (aclState *api.NetworkACLState, err error)
 	CreateNetworkACL(acl api.NetworkACLsPost) (err error)
 	UpdateNetworkACL(name string, acl api.NetworkACLPut, ETag string) (err error)
 	RenameNetworkACL(name string, acl api.NetworkACLPost) (err error)
@@ -303,6 +330,8 @@ type InstanceServer interface {
 	CreateNetworkZone(zone api.NetworkZonesPost) (err error)
 	UpdateNetworkZone(name string, zone api.NetworkZonePut, ETag string) (err error)
 	DeleteNetworkZone(name string) (err error)
+	GetNetworkZoneFile(name string) (content string, err error)
+	ImportNetworkZoneFile(name string, content io.Reader) (err error)
 
 	GetNetworkZoneRecordNames(zone string) (names []string, err error)
 	GetNetworkZoneRecords(zone string) (records []api.NetworkZoneRecord, err error)
@@ -321,6 +350,14 @@ type InstanceServer interface {
 	GetOperationWebsocket(uuid string, secret string) (conn *websocket.Conn, err error)
 	DeleteOperation(uuid string) (err error)
 
+	// Placement group functions ("placement_groups" API extension)
+	GetPlacementGroupNames() (names []string, err error)
+	GetPlacementGroups() (groups []api.PlacementGroup, err error)
+	GetPlacementGroup(name string) (group *api.PlacementGroup, ETag string, err error)
+	CreatePlacementGroup(group api.PlacementGroupsPost) (err error)
+	UpdatePlacementGroup(name string, group api.PlacementGroupPut, ETag string) (err error)
+	DeletePlacementGroup(name string) (err error)
+
 	// Profile functions
 	GetProfilesAllProjects() (profiles []api.Profile, err error)
 	GetProfileNames() (names []string, err error)
@@ -678,6 +715,9 @@ type InstanceConsoleArgs struct {
 // The InstanceConsoleLogArgs struct is used to pass additional options during a
 // instance console log request.
 type InstanceConsoleLogArgs struct {
+	// Keep the connection open and stream new console output
+	// API extension: instance_log_follow
+	Follow bool
 }
 
 // The InstanceExecArgs struct is used to pass additional options during instance exec.
@@ -0,0 +1,110 @@
+package lxd
+
+import (
+	"net/url"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+// GetInstanceGroupNames returns a list of instance group names.
+func (r *ProtocolLXD) GetInstanceGroupNames() ([]string, error) {
+	err := r.CheckExtension("instance_groups")
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch the raw URL values.
+	urls := []string{}
+	baseURL := "/instance-groups"
+	_, err = r.queryStruct("GET", baseURL, nil, "", &urls)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse it.
+	return urlsToResourceNames(baseURL, urls...)
+}
+
+// GetInstanceGroups returns a list of instance group structs.
+func (r *ProtocolLXD) GetInstanceGroups() ([]api.InstanceGroup, error) {
+	err := r.CheckExtension("instance_groups")
+	if err != nil {
+		return nil, err
+	}
+
+	groups := []api.InstanceGroup{}
+
+	// Fetch the raw value.
+	_, err = r.queryStruct("GET", "/instance-groups?recursion=1", nil, "", &groups)
+	if err != nil {
+		return nil, err
+	}
+
+	return groups, nil
+}
+
+// GetInstanceGroup returns an instance group entry for the provided name.
+func (r *ProtocolLXD) GetInstanceGroup(name string) (*api.InstanceGroup, string, error) {
+	err := r.CheckExtension("instance_groups")
+	if err != nil {
+		return nil, "", err
+	}
+
+	group := api.InstanceGroup{}
+
+	// Fetch the raw value.
+	etag, err := r.queryStruct("GET", "/instance-groups/"+url.PathEscape(name), nil, "", &group)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &group, etag, nil
+}
+
+// CreateInstanceGroup defines a new instance group using the provided struct.
+func (r *ProtocolLXD) CreateInstanceGroup(group api.InstanceGroupsPost) error {
+	err := r.CheckExtension("instance_groups")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	_, _, err = r.query("POST", "/instance-groups", group, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateInstanceGroup updates the instance group to match the provided struct.
+func (r *ProtocolLXD) UpdateInstanceGroup(name string, group api.InstanceGroupPut, ETag string) error {
+	err := r.CheckExtension("instance_groups")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	_, _, err = r.query("PUT", "/instance-groups/"+url.PathEscape(name), group, ETag)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteInstanceGroup deletes an existing instance group.
+func (r *ProtocolLXD) DeleteInstanceGroup(name string) error {
+	err := r.CheckExtension("instance_groups")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	_, _, err = r.query("DELETE", "/instance-groups/"+url.PathEscape(name), nil, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -1285,6 +1285,13 @@ func (r *ProtocolLXD) ExecInstance(instanceName string, exec api.InstanceExecPos
 		}
 	}
 
+	if exec.Persistent {
+		err := r.CheckExtension("instance_exec_sessions")
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	var uri string
 
 	if r.IsAgent() {
@@ -1309,22 +1316,7 @@ func (r *ProtocolLXD) ExecInstance(instanceName string, exec api.InstanceExecPos
 	// Process additional arguments
 
 	// Parse the fds
-	fds := map[string]string{}
-
-	value, ok := opAPI.Metadata["fds"]
-	if ok {
-		values, ok := value.(map[string]any)
-		if ok {
-			for k, v := range values {
-				vStr, ok := v.(string)
-				if !ok {
-					continue
-				}
-
-				fds[k] = vStr
-			}
-		}
-	}
+	fds := execOperationFds(opAPI)
 
 	if exec.RecordOutput && (args.Stdout != nil || args.Stderr != nil) {
 		err = op.Wait()
@@ -1388,10 +1380,143 @@ func (r *ProtocolLXD) ExecInstance(instanceName string, exec api.InstanceExecPos
 		}
 	}
 
+	err = r.execInstanceWebsockets(opAPI.ID, fds, exec.Interactive, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
+
+// GetInstanceExecSessions returns the persistent exec sessions of the instance.
+func (r *ProtocolLXD) GetInstanceExecSessions(instanceName string) ([]api.InstanceExecSession, error) {
+	err := r.CheckExtension("instance_exec_sessions")
+	if err != nil {
+		return nil, err
+	}
+
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := []api.InstanceExecSession{}
+
+	// Fetch the raw value
+	_, err = r.queryStruct("GET", path+"/"+url.PathEscape(instanceName)+"/exec-sessions?recursion=1", nil, "", &sessions)
+	if err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// GetInstanceExecSession returns the persistent exec session of the instance with the given ID.
+func (r *ProtocolLXD) GetInstanceExecSession(instanceName string, id string) (*api.InstanceExecSession, error) {
+	err := r.CheckExtension("instance_exec_sessions")
+	if err != nil {
+		return nil, err
+	}
+
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, err
+	}
+
+	session := api.InstanceExecSession{}
+
+	// Fetch the raw value
+	_, err = r.queryStruct("GET", path+"/"+url.PathEscape(instanceName)+"/exec-sessions/"+url.PathEscape(id), nil, "", &session)
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// AttachInstanceExecSession attaches to a persistent exec session of the instance.
+// The returned operation is the exec operation of the session.
+func (r *ProtocolLXD) AttachInstanceExecSession(instanceName string, id string, attach api.InstanceExecSessionPost, args *InstanceExecArgs) (Operation, error) {
+	err := r.CheckExtension("instance_exec_sessions")
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure args are equivalent to empty InstanceExecArgs.
+	if args == nil {
+		args = &InstanceExecArgs{}
+	}
+
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, err
+	}
+
+	// Send the request
+	op, _, err := r.queryOperation("POST", path+"/"+url.PathEscape(instanceName)+"/exec-sessions/"+url.PathEscape(id), attach, "", true)
+	if err != nil {
+		return nil, err
+	}
+
+	opAPI := op.Get()
+
+	err = r.execInstanceWebsockets(opAPI.ID, execOperationFds(opAPI), true, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
+
+// DeleteInstanceExecSession kills the command of a persistent exec session of the instance.
+func (r *ProtocolLXD) DeleteInstanceExecSession(instanceName string, id string) error {
+	err := r.CheckExtension("instance_exec_sessions")
+	if err != nil {
+		return err
+	}
+
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return err
+	}
+
+	// Send the request
+	_, _, err = r.query("DELETE", path+"/"+url.PathEscape(instanceName)+"/exec-sessions/"+url.PathEscape(id), nil, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// execOperationFds returns the websocket secrets found in the metadata of an exec operation.
+func execOperationFds(opAPI api.Operation) map[string]string {
+	fds := map[string]string{}
+
+	value, ok := opAPI.Metadata["fds"]
+	if ok {
+		values, ok := value.(map[string]any)
+		if ok {
+			for k, v := range values {
+				vStr, ok := v.(string)
+				if !ok {
+					continue
+				}
+
+				fds[k] = vStr
+			}
+		}
+	}
+
+	return fds
+}
+
+// execInstanceWebsockets connects to the websockets of an exec operation and mirrors them to the provided arguments.
+func (r *ProtocolLXD) execInstanceWebsockets(operationID string, fds map[string]string, interactive bool, args *InstanceExecArgs) error {
 	if fds[api.SecretNameControl] != "" {
-		conn, err := r.GetOperationWebsocket(opAPI.ID, fds[api.SecretNameControl])
+		conn, err := r.GetOperationWebsocket(operationID, fds[api.SecretNameControl])
 		if err != nil {
-			return nil, err
+			return err
 		}
 
 		go func() {
@@ -1404,13 +1529,13 @@ func (r *ProtocolLXD) ExecInstance(instanceName string, exec api.InstanceExecPos
 		}
 	}
 
-	if exec.Interactive {
+	if interactive {
 		// Handle interactive sections
 		if args.Stdin != nil && args.Stdout != nil {
 			// Connect to the websocket
-			conn, err := r.GetOperationWebsocket(opAPI.ID, fds["0"])
+			conn, err := r.GetOperationWebsocket(operationID, fds["0"])
 			if err != nil {
-				return nil, err
+				return err
 			}
 
 			// And attach stdin and stdout to it
@@ -1435,9 +1560,9 @@ func (r *ProtocolLXD) ExecInstance(instanceName string, exec api.InstanceExecPos
 
 		// Handle stdin
 		if fds["0"] != "" {
-			conn, err := r.GetOperationWebsocket(opAPI.ID, fds["0"])
+			conn, err := r.GetOperationWebsocket(operationID, fds["0"])
 			if err != nil {
-				return nil, err
+				return err
 			}
 
 			go func() {
@@ -1452,9 +1577,9 @@ func (r *ProtocolLXD) ExecInstance(instanceName string, exec api.InstanceExecPos
 
 		// Handle stdout
 		if fds["1"] != "" {
-			conn, err := r.GetOperationWebsocket(opAPI.ID, fds["1"])
+			conn, err := r.GetOperationWebsocket(operationID, fds["1"])
 			if err != nil {
-				return nil, err
+				return err
 			}
 
 			// Discard Stdout from remote command if output writer not supplied.
@@ -1469,9 +1594,9 @@ func (r *ProtocolLXD) ExecInstance(instanceName string, exec api.InstanceExecPos
 
 		// Handle stderr
 		if fds["2"] != "" {
-			conn, err := r.GetOperationWebsocket(opAPI.ID, fds["2"])
+			conn, err := r.GetOperationWebsocket(operationID, fds["2"])
 			if err != nil {
-				return nil, err
+				return err
 			}
 
 			// Discard Stderr from remote command if output writer not supplied.
@@ -1519,7 +1644,7 @@ func (r *ProtocolLXD) ExecInstance(instanceName string, exec api.InstanceExecPos
 		}()
 	}
 
-	return op, nil
+	return nil
 }
 
 // GetInstanceFile retrieves the provided path from the instance.
@@ -2405,6 +2530,51 @@ func (r *ProtocolLXD) GetInstanceLogfile(name string, filename string) (io.ReadC
 	return resp.Body, err
 }
 
+// GetInstanceLogfileFollow returns the content of the requested logfile, followed by any data appended to it.
+// The returned stream stays open until it is closed by the caller or the connection is lost.
+//
+// Note that it's the caller's responsibility to close the returned ReadCloser.
+func (r *ProtocolLXD) GetInstanceLogfileFollow(name string, filename string) (io.ReadCloser, error) {
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.CheckExtension("instance_log_follow")
+	if err != nil {
+		return nil, err
+	}
+
+	// Prepare the HTTP request
+	url := r.httpBaseURL.String() + "/1.0" + path + "/" + url.PathEscape(name) + "/logs/" + url.PathEscape(filename) + "?follow=true"
+
+	url, err = r.setQueryAttributes(url)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Send the request
+	resp, err := r.DoHTTP(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the return value for a cleaner error
+	if resp.StatusCode != http.StatusOK {
+		_, _, err := lxdParseResponse(resp)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return resp.Body, err
+}
+
 // DeleteInstanceLogfile deletes the requested logfile.
 func (r *ProtocolLXD) DeleteInstanceLogfile(name string, filename string) error {
 	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
@@ -2421,6 +2591,92 @@ func (r *ProtocolLXD) DeleteInstanceLogfile(name string, filename string) error
 	return nil
 }
 
+// GetInstanceSessionRecordings returns the recordings of the exec and console sessions of the instance.
+func (r *ProtocolLXD) GetInstanceSessionRecordings(name string) ([]api.InstanceSessionRecording, error) {
+	err := r.CheckExtension("instance_session_recording")
+	if err != nil {
+		return nil, err
+	}
+
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, err
+	}
+
+	recordings := []api.InstanceSessionRecording{}
+
+	// Fetch the raw value
+	_, err = r.queryStruct("GET", path+"/"+url.PathEscape(name)+"/logs/session-recordings?recursion=1", nil, "", &recordings)
+	if err != nil {
+		return nil, err
+	}
+
+	return recordings, nil
+}
+
+// GetInstanceSessionRecordingFile returns the content of a session recording of the instance, in asciicast v2 format.
+func (r *ProtocolLXD) GetInstanceSessionRecordingFile(name string, filename string) (io.ReadCloser, error) {
+	err := r.CheckExtension("instance_session_recording")
+	if err != nil {
+		return nil, err
+	}
+
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, err
+	}
+
+	// Prepare the HTTP request
+	url := r.httpBaseURL.String() + "/1.0" + path + "/" + url.PathEscape(name) + "/logs/session-recordings/" + url.PathEscape(filename)
+
+	url, err = r.setQueryAttributes(url)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Send the request
+	resp, err := r.DoHTTP(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the return value for a cleaner error
+	if resp.StatusCode != http.StatusOK {
+		_, _, err := lxdParseResponse(resp)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return resp.Body, nil
+}
+
+// DeleteInstanceSessionRecording deletes a session recording of the instance.
+func (r *ProtocolLXD) DeleteInstanceSessionRecording(name string, filename string) error {
+	err := r.CheckExtension("instance_session_recording")
+	if err != nil {
+		return err
+	}
+
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return err
+	}
+
+	// Send the request
+	_, _, err = r.query("DELETE", path+"/"+url.PathEscape(name)+"/logs/session-recordings/"+url.PathEscape(filename), nil, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // getInstanceExecOutputLogFile returns the content of the requested exec logfile.
 //
 // Note that it's the caller's responsibility to close the returned ReadCloser.
@@ -2486,6 +2742,50 @@ func (r *ProtocolLXD) deleteInstanceExecOutputLogFile(instanceName string, filen
 	return nil
 }
 
+// GetInstanceDiff returns the files of the instance that changed since it was created from its image, or since
+// the given snapshot was taken if snapshot isn't empty.
+func (r *ProtocolLXD) GetInstanceDiff(name string, snapshot string) ([]api.InstanceFileChange, error) {
+	err := r.CheckExtension("instance_diff")
+	if err != nil {
+		return nil, err
+	}
+
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, err
+	}
+
+	uri := path + "/" + url.PathEscape(name) + "/diff"
+	if snapshot != "" {
+		uri += "?snapshot=" + url.QueryEscape(snapshot)
+	}
+
+	// The comparison runs as a background operation.
+	op, _, err := r.queryOperation("GET", uri, nil, "", true)
+	if err != nil {
+		return nil, err
+	}
+
+	err = op.Wait()
+	if err != nil {
+		return nil, err
+	}
+
+	// Extract the changes from the operation metadata.
+	buf, err := json.Marshal(op.Get().Metadata["changes"])
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []api.InstanceFileChange{}
+	err = json.Unmarshal(buf, &changes)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing file changes: %w", err)
+	}
+
+	return changes, nil
+}
+
 // GetInstanceMetadata returns instance metadata.
 func (r *ProtocolLXD) GetInstanceMetadata(name string) (*api.ImageMetadata, string, error) {
 	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
@@ -2861,6 +3161,15 @@ func (r *ProtocolLXD) GetInstanceConsoleLog(instanceName string, args *InstanceC
 	// Prepare the HTTP request
 	url := r.httpBaseURL.String() + "/1.0" + path + "/" + url.PathEscape(instanceName) + "/console"
 
+	if args != nil && args.Follow {
+		err = r.CheckExtension("instance_log_follow")
+		if err != nil {
+			return nil, err
+		}
+
+		url += "?follow=true"
+	}
+
 	url, err = r.setQueryAttributes(url)
 	if err != nil {
 		return nil, err
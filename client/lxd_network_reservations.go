@@ -0,0 +1,110 @@
+package lxd
+
+import (
+	"net/url"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+// GetNetworkReservationAddresses returns a list of network DHCP reservation addresses.
+func (r *ProtocolLXD) GetNetworkReservationAddresses(networkName string) ([]string, error) {
+	err := r.CheckExtension("network_reservation")
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch the raw URL values.
+	urls := []string{}
+	baseURL := "/networks/" + url.PathEscape(networkName) + "/reservations"
+	_, err = r.queryStruct("GET", baseURL, nil, "", &urls)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse it.
+	return urlsToResourceNames(baseURL, urls...)
+}
+
+// GetNetworkReservations returns a list of network DHCP reservation structs.
+func (r *ProtocolLXD) GetNetworkReservations(networkName string) ([]api.NetworkReservation, error) {
+	err := r.CheckExtension("network_reservation")
+	if err != nil {
+		return nil, err
+	}
+
+	reservations := []api.NetworkReservation{}
+
+	// Fetch the raw value.
+	_, err = r.queryStruct("GET", "/networks/"+url.PathEscape(networkName)+"/reservations?recursion=1", nil, "", &reservations)
+	if err != nil {
+		return nil, err
+	}
+
+	return reservations, nil
+}
+
+// GetNetworkReservation returns a network DHCP reservation entry for the provided network and address.
+func (r *ProtocolLXD) GetNetworkReservation(networkName string, address string) (*api.NetworkReservation, string, error) {
+	err := r.CheckExtension("network_reservation")
+	if err != nil {
+		return nil, "", err
+	}
+
+	reservation := api.NetworkReservation{}
+
+	// Fetch the raw value.
+	etag, err := r.queryStruct("GET", "/networks/"+url.PathEscape(networkName)+"/reservations/"+url.PathEscape(address), nil, "", &reservation)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &reservation, etag, nil
+}
+
+// CreateNetworkReservation defines a new network DHCP reservation using the provided struct.
+func (r *ProtocolLXD) CreateNetworkReservation(networkName string, reservation api.NetworkReservationsPost) error {
+	err := r.CheckExtension("network_reservation")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	_, _, err = r.query("POST", "/networks/"+url.PathEscape(networkName)+"/reservations", reservation, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateNetworkReservation updates the network DHCP reservation to match the provided struct.
+func (r *ProtocolLXD) UpdateNetworkReservation(networkName string, address string, reservation api.NetworkReservationPut, ETag string) error {
+	err := r.CheckExtension("network_reservation")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	_, _, err = r.query("PUT", "/networks/"+url.PathEscape(networkName)+"/reservations/"+url.PathEscape(address), reservation, ETag)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteNetworkReservation deletes an existing network DHCP reservation.
+func (r *ProtocolLXD) DeleteNetworkReservation(networkName string, address string) error {
+	err := r.CheckExtension("network_reservation")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	_, _, err = r.query("DELETE", "/networks/"+url.PathEscape(networkName)+"/reservations/"+url.PathEscape(address), nil, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -2,6 +2,8 @@ package lxd
 
 import (
 	"fmt"
+	"io"
+	"net/http"
 	"net/url"
 
 	"github.com/canonical/lxd/shared/api"
@@ -128,6 +130,67 @@ func (r *ProtocolLXD) DeleteNetworkZone(name string) error {
 	return nil
 }
 
+// GetNetworkZoneFile returns the content of the network zone as a RFC 1035 master file.
+func (r *ProtocolLXD) GetNetworkZoneFile(name string) (string, error) {
+	err := r.CheckExtension("network_zone_import_export")
+	if err != nil {
+		return "", err
+	}
+
+	// Prepare the request.
+	requestURL, err := r.setQueryAttributes(fmt.Sprintf("%s/1.0/network-zones/%s/export", r.httpBaseURL.String(), url.PathEscape(name)))
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequest("GET", requestURL, nil)
+	if err != nil {
+		return "", err
+	}
+
+	// Send the request.
+	resp, err := r.DoHTTP(req)
+	if err != nil {
+		return "", err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	// Check the return value for a cleaner error.
+	if resp.StatusCode != http.StatusOK {
+		_, _, err := lxdParseResponse(resp)
+		if err != nil {
+			return "", err
+		}
+
+		return "", fmt.Errorf("Bad HTTP status: %d", resp.StatusCode)
+	}
+
+	// Get the content.
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	return string(content), nil
+}
+
+// ImportNetworkZoneFile adds the records of a RFC 1035 master file to the network zone.
+func (r *ProtocolLXD) ImportNetworkZoneFile(name string, content io.Reader) error {
+	err := r.CheckExtension("network_zone_import_export")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	_, _, err = r.query("POST", fmt.Sprintf("/network-zones/%s/import", url.PathEscape(name)), content, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // GetNetworkZoneRecordNames returns a list of network zone record names.
 func (r *ProtocolLXD) GetNetworkZoneRecordNames(zone string) ([]string, error) {
 	err := r.CheckExtension("network_dns_records")
@@ -0,0 +1,110 @@
+package lxd
+
+import (
+	"net/url"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+// GetPlacementGroupNames returns a list of placement group names.
+func (r *ProtocolLXD) GetPlacementGroupNames() ([]string, error) {
+	err := r.CheckExtension("placement_groups")
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch the raw URL values.
+	urls := []string{}
+	baseURL := "/placement-groups"
+	_, err = r.queryStruct("GET", baseURL, nil, "", &urls)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse it.
+	return urlsToResourceNames(baseURL, urls...)
+}
+
+// GetPlacementGroups returns a list of placement group structs.
+func (r *ProtocolLXD) GetPlacementGroups() ([]api.PlacementGroup, error) {
+	err := r.CheckExtension("placement_groups")
+	if err != nil {
+		return nil, err
+	}
+
+	groups := []api.PlacementGroup{}
+
+	// Fetch the raw value.
+	_, err = r.queryStruct("GET", "/placement-groups?recursion=1", nil, "", &groups)
+	if err != nil {
+		return nil, err
+	}
+
+	return groups, nil
+}
+
+// GetPlacementGroup returns a placement group entry for the provided name.
+func (r *ProtocolLXD) GetPlacementGroup(name string) (*api.PlacementGroup, string, error) {
+	err := r.CheckExtension("placement_groups")
+	if err != nil {
+		return nil, "", err
+	}
+
+	group := api.PlacementGroup{}
+
+	// Fetch the raw value.
+	etag, err := r.queryStruct("GET", "/placement-groups/"+url.PathEscape(name), nil, "", &group)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &group, etag, nil
+}
+
+// CreatePlacementGroup defines a new placement group using the provided struct.
+func (r *ProtocolLXD) CreatePlacementGroup(group api.PlacementGroupsPost) error {
+	err := r.CheckExtension("placement_groups")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	_, _, err = r.query("POST", "/placement-groups", group, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdatePlacementGroup updates the placement group to match the provided struct.
+func (r *ProtocolLXD) UpdatePlacementGroup(name string, group api.PlacementGroupPut, ETag string) error {
+	err := r.CheckExtension("placement_groups")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	_, _, err = r.query("PUT", "/placement-groups/"+url.PathEscape(name), group, ETag)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeletePlacementGroup deletes an existing placement group.
+func (r *ProtocolLXD) DeletePlacementGroup(name string) error {
+	err := r.CheckExtension("placement_groups")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	_, _, err = r.query("DELETE", "/placement-groups/"+url.PathEscape(name), nil, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -4,10 +4,13 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/canonical/lxd/client"
 	"github.com/canonical/lxd/lxc/config"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
@@ -31,7 +34,10 @@ func (c *cmdStart) command() *cobra.Command {
 	cmd.Use = usage("start", i18n.G("[<remote>:]<instance> [[<remote>:]<instance>...]"))
 	cmd.Short = i18n.G("Start instances")
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
-		`Start instances`))
+		`Start instances
+
+With --with-deps, the instances listed in the boot.depends_on configuration key of an
+instance are started first, and the instance is only started once they are ready.`))
 
 	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		return c.global.cmpInstancesAction(toComplete, "start", c.action.flagForce)
@@ -127,6 +133,7 @@ type cmdAction struct {
 	flagStateful  bool
 	flagStateless bool
 	flagTimeout   int
+	flagWithDeps  bool
 }
 
 // Command is a method of the cmdAction structure which constructs and configures a cobra Command object.
@@ -141,6 +148,7 @@ func (c *cmdAction) Command(action string) *cobra.Command {
 		cmd.Flags().BoolVar(&c.flagStateful, "stateful", false, i18n.G("Store the instance state"))
 	} else if action == "start" {
 		cmd.Flags().BoolVar(&c.flagStateless, "stateless", false, i18n.G("Ignore the instance state"))
+		cmd.Flags().BoolVar(&c.flagWithDeps, "with-deps", false, i18n.G("Start the instances that the instance depends on first"))
 	}
 
 	if shared.ValueInSlice(action, []string{"start", "restart", "stop"}) {
@@ -250,6 +258,13 @@ func (c *cmdAction) doAction(action string, conf *config.Config, nameArg string)
 		return fmt.Errorf(i18n.G("Must supply instance name for: ")+"\"%s\"", nameArg)
 	}
 
+	if action == "start" && c.flagWithDeps {
+		err := c.startDependencies(d, name, map[string]bool{name: true})
+		if err != nil {
+			return err
+		}
+	}
+
 	if action == "start" {
 		current, _, err := d.GetInstance(name)
 		if err != nil {
@@ -317,6 +332,81 @@ func (c *cmdAction) doAction(action string, conf *config.Config, nameArg string)
 	return nil
 }
 
+// startDependencies starts the instances listed in the boot.depends_on configuration key of an instance, along
+// with their own dependencies, and waits for each of them to be ready. The seen map tracks the instances that were
+// already handled so that dependency cycles are ignored.
+func (c *cmdAction) startDependencies(d lxd.InstanceServer, name string, seen map[string]bool) error {
+	inst, _, err := d.GetInstance(name)
+	if err != nil {
+		return err
+	}
+
+	timeout := 300 * time.Second
+	timeoutSeconds, err := strconv.Atoi(inst.ExpandedConfig["boot.depends_on.timeout"])
+	if err == nil {
+		timeout = time.Duration(timeoutSeconds) * time.Second
+	}
+
+	for _, dependency := range shared.SplitNTrimSpace(inst.ExpandedConfig["boot.depends_on"], ",", -1, true) {
+		if seen[dependency] {
+			continue
+		}
+
+		seen[dependency] = true
+
+		// Start the dependencies of the dependency first.
+		err := c.startDependencies(d, dependency, seen)
+		if err != nil {
+			return err
+		}
+
+		current, _, err := d.GetInstance(dependency)
+		if err != nil {
+			return fmt.Errorf(i18n.G("Failed getting dependency %q: %w"), dependency, err)
+		}
+
+		if current.StatusCode == api.Stopped {
+			op, err := d.UpdateInstanceState(dependency, api.InstanceStatePut{Action: "start", Timeout: -1, Stateful: current.Stateful && !c.flagStateless}, "")
+			if err == nil {
+				err = op.Wait()
+			}
+
+			if err != nil {
+				// Another instance of the batch may have started it in the meantime.
+				current, _, getErr := d.GetInstance(dependency)
+				if getErr != nil || current.StatusCode == api.Stopped {
+					return fmt.Errorf(i18n.G("Failed starting dependency %q: %w"), dependency, err)
+				}
+			}
+		}
+
+		// Wait for the dependency to be ready.
+		deadline := time.Now().Add(timeout)
+		for {
+			current, _, err := d.GetInstance(dependency)
+			if err != nil {
+				return fmt.Errorf(i18n.G("Failed getting dependency %q: %w"), dependency, err)
+			}
+
+			if current.StatusCode == api.Ready {
+				break
+			}
+
+			if current.StatusCode != api.Running {
+				return fmt.Errorf(i18n.G("Dependency %q is not running"), dependency)
+			}
+
+			if time.Now().After(deadline) {
+				return fmt.Errorf(i18n.G("Timed out after %s waiting for dependency %q to be ready"), timeout, dependency)
+			}
+
+			time.Sleep(time.Second)
+		}
+	}
+
+	return nil
+}
+
 // Run is a method of the cmdAction structure that implements the execution logic for the given Cobra command.
 // It handles actions on instances (single or all) and manages error handling, console flag restrictions, and batch operations.
 func (c *cmdAction) run(cmd *cobra.Command, args []string) error {
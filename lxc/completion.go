@@ -235,6 +235,45 @@ func (g *cmdGlobal) cmpClusterMembers(toComplete string) ([]string, cobra.ShellC
 	return results, cmpDirectives
 }
 
+// cmpInstanceGroups provides shell completion for instance groups.
+// It takes a partial input string and returns a list of instance groups along with a shell completion directive.
+func (g *cmdGlobal) cmpInstanceGroups(toComplete string) ([]string, cobra.ShellCompDirective) {
+	var results []string
+	cmpDirectives := cobra.ShellCompDirectiveNoFileComp
+
+	resources, _ := g.ParseServers(toComplete)
+
+	if len(resources) > 0 {
+		resource := resources[0]
+
+		groups, err := resource.server.GetInstanceGroupNames()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		results = make([]string, 0, len(groups))
+		for _, group := range groups {
+			var name string
+
+			if resource.remote == g.conf.DefaultRemote && !strings.Contains(toComplete, g.conf.DefaultRemote) {
+				name = group
+			} else {
+				name = resource.remote + ":" + group
+			}
+
+			results = append(results, name)
+		}
+	}
+
+	if !strings.Contains(toComplete, ":") {
+		remotes, directives := g.cmpRemotes(toComplete, false)
+		results = append(results, remotes...)
+		cmpDirectives |= directives
+	}
+
+	return results, cmpDirectives
+}
+
 // cmpImages provides shell completion for image aliases.
 // It takes a partial input string and returns a list of matching image aliases along with a shell completion directive.
 func (g *cmdGlobal) cmpImages(toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -588,6 +627,65 @@ func (g *cmdGlobal) cmpInstanceDeviceNames(instanceName string) ([]string, cobra
 	return results, cobra.ShellCompDirectiveNoFileComp
 }
 
+// cmpInstanceExecSessions provides shell completion for the persistent exec sessions of an instance.
+// It takes an instance name and returns a list of session IDs along with a shell completion directive.
+func (g *cmdGlobal) cmpInstanceExecSessions(instanceName string) ([]string, cobra.ShellCompDirective) {
+	resources, err := g.ParseServers(instanceName)
+	if err != nil || len(resources) == 0 {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	resource := resources[0]
+
+	sessions, err := resource.server.GetInstanceExecSessions(resource.name)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	results := make([]string, 0, len(sessions))
+	for _, session := range sessions {
+		results = append(results, session.ID)
+	}
+
+	return results, cobra.ShellCompDirectiveNoFileComp
+}
+
+// cmpInstanceLogFiles provides shell completion for the log files of an instance.
+// It takes an instance name and returns a list of its log files along with a shell completion directive.
+func (g *cmdGlobal) cmpInstanceLogFiles(instanceName string) ([]string, cobra.ShellCompDirective) {
+	resources, err := g.ParseServers(instanceName)
+	if err != nil || len(resources) == 0 {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	resource := resources[0]
+
+	logFiles, err := resource.server.GetInstanceLogfiles(resource.name)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	return logFiles, cobra.ShellCompDirectiveNoFileComp
+}
+
+// cmpInstanceSnapshots provides shell completion for the snapshots of an instance.
+// It takes an instance name and returns a list of its snapshot names along with a shell completion directive.
+func (g *cmdGlobal) cmpInstanceSnapshots(instanceName string) ([]string, cobra.ShellCompDirective) {
+	resources, err := g.ParseServers(instanceName)
+	if err != nil || len(resources) == 0 {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	resource := resources[0]
+
+	snapshots, err := resource.server.GetInstanceSnapshotNames(resource.name)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	return snapshots, cobra.ShellCompDirectiveNoFileComp
+}
+
 // cmpInstanceAllDevices provides shell completion for all instance devices.
 // It takes an instance name and returns a list of all possible instance devices along with a shell completion directive.
 func (g *cmdGlobal) cmpInstanceAllDevices(instanceName string) ([]string, cobra.ShellCompDirective) {
@@ -1045,6 +1143,52 @@ func (g *cmdGlobal) cmpNetworkPeers(networkName string) ([]string, cobra.ShellCo
 	return results, cmpDirectives
 }
 
+// cmpNetworkReservationConfigs provides shell completion for network DHCP reservation configs.
+// It takes a network name and address, and returns a list of network reservation configs along with a shell completion directive.
+func (g *cmdGlobal) cmpNetworkReservationConfigs(networkName string, address string) ([]string, cobra.ShellCompDirective) {
+	// Parse remote
+	resources, err := g.ParseServers(networkName)
+	if err != nil || len(resources) == 0 {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	resource := resources[0]
+	client := resource.server
+
+	reservation, _, err := client.GetNetworkReservation(resource.name, address)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	results := make([]string, 0, len(reservation.Config))
+	for k := range reservation.Config {
+		results = append(results, k)
+	}
+
+	return results, cobra.ShellCompDirectiveNoFileComp
+}
+
+// cmpNetworkReservations provides shell completion for network DHCP reservations.
+// It takes a network name and returns a list of reserved addresses along with a shell completion directive.
+func (g *cmdGlobal) cmpNetworkReservations(networkName string) ([]string, cobra.ShellCompDirective) {
+	cmpDirectives := cobra.ShellCompDirectiveNoFileComp
+
+	resources, _ := g.ParseServers(networkName)
+
+	if len(resources) <= 0 {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	resource := resources[0]
+
+	results, err := resource.server.GetNetworkReservationAddresses(resource.name)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	return results, cmpDirectives
+}
+
 // cmpNetworks provides shell completion for networks.
 // It takes a partial input string and returns a list of matching networks along with a shell completion directive.
 func (g *cmdGlobal) cmpNetworks(toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -1280,6 +1424,45 @@ func (g *cmdGlobal) cmpNetworkZones(toComplete string) ([]string, cobra.ShellCom
 	return results, cmpDirectives
 }
 
+// cmpPlacementGroups provides shell completion for placement groups.
+// It takes a partial input string and returns a list of placement groups along with a shell completion directive.
+func (g *cmdGlobal) cmpPlacementGroups(toComplete string) ([]string, cobra.ShellCompDirective) {
+	var results []string
+	cmpDirectives := cobra.ShellCompDirectiveNoFileComp
+
+	resources, _ := g.ParseServers(toComplete)
+
+	if len(resources) > 0 {
+		resource := resources[0]
+
+		groups, err := resource.server.GetPlacementGroupNames()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		results = make([]string, 0, len(groups))
+		for _, group := range groups {
+			var name string
+
+			if resource.remote == g.conf.DefaultRemote && !strings.Contains(toComplete, g.conf.DefaultRemote) {
+				name = group
+			} else {
+				name = resource.remote + ":" + group
+			}
+
+			results = append(results, name)
+		}
+	}
+
+	if !strings.Contains(toComplete, ":") {
+		remotes, directives := g.cmpRemotes(toComplete, false)
+		results = append(results, remotes...)
+		cmpDirectives |= directives
+	}
+
+	return results, cmpDirectives
+}
+
 // cmpProfileConfigs provides shell completion for profile configs.
 // It takes a profile name and returns a list of profile configs along with a shell completion directive.
 func (g *cmdGlobal) cmpProfileConfigs(profileName string) ([]string, cobra.ShellCompDirective) {
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+
+	cli "github.com/canonical/lxd/shared/cmd"
+	"github.com/canonical/lxd/shared/i18n"
+)
+
+type cmdDiff struct {
+	global *cmdGlobal
+
+	flagFormat string
+}
+
+func (c *cmdDiff) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("diff", i18n.G("[<remote>:]<instance> [<snapshot>]"))
+	cmd.Short = i18n.G("Show file changes of instances")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Show file changes of instances
+
+Lists the files of the container that were added, removed or modified since it
+was created from its image, or since the given snapshot was taken.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc diff c1
+    Show the files of container c1 that differ from its image.
+
+lxc diff c1 snap0
+    Show the files of container c1 that changed since snapshot snap0.`))
+
+	cmd.RunE = c.run
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpInstances(toComplete)
+		}
+
+		if len(args) == 1 {
+			return c.global.cmpInstanceSnapshots(args[0])
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdDiff) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing instance name"))
+	}
+
+	snapshotName := ""
+	if len(args) > 1 {
+		snapshotName = args[1]
+	}
+
+	changes, err := resource.server.GetInstanceDiff(resource.name, snapshotName)
+	if err != nil {
+		return err
+	}
+
+	// The changes are already sorted by path.
+	data := [][]string{}
+	for _, change := range changes {
+		data = append(data, []string{change.Type, change.Path})
+	}
+
+	header := []string{
+		i18n.G("CHANGE"),
+		i18n.G("PATH"),
+	}
+
+	return cli.RenderTable(c.flagFormat, header, data, changes)
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"io"
 	"os"
 	"strconv"
@@ -30,6 +31,7 @@ type cmdExec struct {
 	flagUser                uint32
 	flagGroup               uint32
 	flagCwd                 string
+	flagPersistent          bool
 
 	interactive bool
 }
@@ -48,7 +50,11 @@ executable, passing the shell commands as arguments, for example:
 
   lxc exec <instance> -- sh -c "cd /tmp && pwd"
 
-Mode defaults to non-interactive, interactive mode is selected if both stdin AND stdout are terminals (stderr is ignored).`))
+Mode defaults to non-interactive, interactive mode is selected if both stdin AND stdout are terminals (stderr is ignored).
+
+With --persistent, the command keeps running when the client disconnects.
+Press ctrl-p ctrl-q to detach from the command, and use "lxc exec-session"
+to attach to it again.`))
 
 	cmd.RunE = c.run
 	cmd.Flags().StringArrayVar(&c.flagEnvironment, "env", nil, i18n.G("Environment variable to set (e.g. HOME=/home/foo)")+"``")
@@ -59,6 +65,7 @@ Mode defaults to non-interactive, interactive mode is selected if both stdin AND
 	cmd.Flags().Uint32Var(&c.flagUser, "user", 0, i18n.G("User ID to run the command as (default 0)")+"``")
 	cmd.Flags().Uint32Var(&c.flagGroup, "group", 0, i18n.G("Group ID to run the command as (default 0)")+"``")
 	cmd.Flags().StringVar(&c.flagCwd, "cwd", "", i18n.G("Directory to run the command in (default /root)")+"``")
+	cmd.Flags().BoolVar(&c.flagPersistent, "persistent", false, i18n.G("Keep the command running when detaching from it (requires interactive mode)"))
 
 	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		if len(args) == 0 {
@@ -148,6 +155,10 @@ func (c *cmdExec) run(cmd *cobra.Command, args []string) error {
 		c.interactive = stdinTerminal && stdoutTerminal
 	}
 
+	if c.flagPersistent && !c.interactive {
+		return errors.New(i18n.G("--persistent requires interactive mode"))
+	}
+
 	// Record terminal state
 	var oldttystate *termios.State
 	if c.interactive && stdinTerminal {
@@ -177,6 +188,12 @@ func (c *cmdExec) run(cmd *cobra.Command, args []string) error {
 		stdin = bytes.NewReader(nil)
 	}
 
+	var detachReader *execDetachReader
+	if c.flagPersistent {
+		detachReader = &execDetachReader{reader: stdin}
+		stdin = detachReader
+	}
+
 	stdout := getStdout()
 
 	// Prepare the command
@@ -190,6 +207,7 @@ func (c *cmdExec) run(cmd *cobra.Command, args []string) error {
 		User:        c.flagUser,
 		Group:       c.flagGroup,
 		Cwd:         c.flagCwd,
+		Persistent:  c.flagPersistent,
 	}
 
 	execArgs := lxd.InstanceExecArgs{
@@ -206,8 +224,24 @@ func (c *cmdExec) run(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	return c.wait(op, &execArgs, detachReader)
+}
+
+// wait waits for the command to complete and records its exit status.
+// For persistent sessions, it returns early if the user detaches from the command.
+func (c *cmdExec) wait(op lxd.Operation, execArgs *lxd.InstanceExecArgs, detachReader *execDetachReader) error {
+	if detachReader != nil {
+		// Wait for the server to acknowledge the detach before checking for it.
+		<-execArgs.DataDone
+
+		if detachReader.detached {
+			fmt.Printf("\r\n"+i18n.G("Detached from exec session %s")+"\r\n", op.Get().ID)
+			return nil
+		}
+	}
+
 	// Wait for the operation to complete
-	err = op.Wait()
+	err := op.Wait()
 	opAPI := op.Get()
 	if opAPI.Metadata != nil {
 		exitStatusRaw, ok := opAPI.Metadata["return"].(float64)
@@ -225,3 +259,53 @@ func (c *cmdExec) run(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// execDetachReader wraps the standard input of a persistent exec session.
+// It ends the input when the detach sequence (ctrl-p ctrl-q) is typed.
+type execDetachReader struct {
+	reader   io.Reader
+	pending  bool
+	detached bool
+}
+
+// Read reads from the wrapped reader, returning io.EOF once the detach sequence is read.
+func (r *execDetachReader) Read(p []byte) (int, error) {
+	if r.detached {
+		return 0, io.EOF
+	}
+
+	// Leave room for a ctrl-p held back by the previous read.
+	size := len(p)
+	if r.pending && size > 1 {
+		size--
+	}
+
+	buf := make([]byte, size)
+	n, err := r.reader.Read(buf)
+
+	out := p[:0]
+	for _, b := range buf[:n] {
+		if r.pending {
+			r.pending = false
+			if b == 0x11 {
+				r.detached = true
+				break
+			}
+
+			out = append(out, 0x10)
+		}
+
+		if b == 0x10 {
+			r.pending = true
+			continue
+		}
+
+		out = append(out, b)
+	}
+
+	if r.detached && len(out) == 0 {
+		return 0, io.EOF
+	}
+
+	return len(out), err
+}
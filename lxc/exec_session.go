@@ -0,0 +1,281 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/canonical/lxd/client"
+	"github.com/canonical/lxd/shared/api"
+	cli "github.com/canonical/lxd/shared/cmd"
+	"github.com/canonical/lxd/shared/i18n"
+	"github.com/canonical/lxd/shared/termios"
+)
+
+type cmdExecSession struct {
+	global *cmdGlobal
+}
+
+func (c *cmdExecSession) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("exec-session")
+	cmd.Short = i18n.G("Manage persistent exec sessions")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(`Manage persistent exec sessions
+
+Persistent exec sessions are started with "lxc exec --persistent" and keep
+running when the client detaches from them.`))
+
+	// List.
+	execSessionListCmd := cmdExecSessionList{global: c.global, execSession: c}
+	cmd.AddCommand(execSessionListCmd.command())
+
+	// Attach.
+	execSessionAttachCmd := cmdExecSessionAttach{global: c.global, execSession: c}
+	cmd.AddCommand(execSessionAttachCmd.command())
+
+	// Delete.
+	execSessionDeleteCmd := cmdExecSessionDelete{global: c.global, execSession: c}
+	cmd.AddCommand(execSessionDeleteCmd.command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
+	return cmd
+}
+
+// List.
+type cmdExecSessionList struct {
+	global      *cmdGlobal
+	execSession *cmdExecSession
+
+	flagFormat string
+}
+
+func (c *cmdExecSessionList) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("list", i18n.G("[<remote>:]<instance>"))
+	cmd.Aliases = []string{"ls"}
+	cmd.Short = i18n.G("List persistent exec sessions")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("List persistent exec sessions of an instance"))
+
+	cmd.RunE = c.run
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpInstances(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdExecSessionList) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing instance name"))
+	}
+
+	sessions, err := resource.server.GetInstanceExecSessions(resource.name)
+	if err != nil {
+		return err
+	}
+
+	data := make([][]string, 0, len(sessions))
+	for _, session := range sessions {
+		lastAttached := ""
+		if !session.LastAttachedAt.IsZero() {
+			lastAttached = session.LastAttachedAt.UTC().Format("2006/01/02 15:04 UTC")
+		}
+
+		details := []string{
+			session.ID,
+			strings.Join(session.Command, " "),
+			fmt.Sprint(session.Attached),
+			session.CreatedAt.UTC().Format("2006/01/02 15:04 UTC"),
+			lastAttached,
+		}
+
+		data = append(data, details)
+	}
+
+	sort.Sort(cli.SortColumnsNaturally(data))
+
+	header := []string{
+		i18n.G("ID"),
+		i18n.G("COMMAND"),
+		i18n.G("ATTACHED"),
+		i18n.G("CREATED AT"),
+		i18n.G("LAST ATTACHED AT"),
+	}
+
+	return cli.RenderTable(c.flagFormat, header, data, sessions)
+}
+
+// Attach.
+type cmdExecSessionAttach struct {
+	global      *cmdGlobal
+	execSession *cmdExecSession
+}
+
+func (c *cmdExecSessionAttach) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("attach", i18n.G("[<remote>:]<instance> <session>"))
+	cmd.Short = i18n.G("Attach to persistent exec sessions")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(`Attach to persistent exec sessions
+
+Press ctrl-p ctrl-q to detach from the session again.`))
+
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpInstances(toComplete)
+		}
+
+		if len(args) == 1 {
+			return c.global.cmpInstanceExecSessions(args[0])
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdExecSessionAttach) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing instance name"))
+	}
+
+	stdinFd := getStdinFd()
+	stdoutFd := getStdoutFd()
+
+	if !termios.IsTerminal(stdinFd) || !termios.IsTerminal(stdoutFd) {
+		return errors.New(i18n.G("Attaching to an exec session requires a terminal"))
+	}
+
+	oldttystate, err := termios.MakeRaw(stdinFd)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = termios.Restore(stdinFd, oldttystate) }()
+
+	width, height, err := termios.GetSize(stdoutFd)
+	if err != nil {
+		return err
+	}
+
+	exec := cmdExec{global: c.global, interactive: true}
+	detachReader := &execDetachReader{reader: os.Stdin}
+
+	execArgs := lxd.InstanceExecArgs{
+		Stdin:    detachReader,
+		Stdout:   getStdout(),
+		Stderr:   os.Stderr,
+		Control:  exec.controlSocketHandler,
+		DataDone: make(chan bool),
+	}
+
+	op, err := resource.server.AttachInstanceExecSession(resource.name, args[1], api.InstanceExecSessionPost{Width: width, Height: height}, &execArgs)
+	if err != nil {
+		return err
+	}
+
+	return exec.wait(op, &execArgs, detachReader)
+}
+
+// Delete.
+type cmdExecSessionDelete struct {
+	global      *cmdGlobal
+	execSession *cmdExecSession
+}
+
+func (c *cmdExecSessionDelete) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("delete", i18n.G("[<remote>:]<instance> <session>"))
+	cmd.Aliases = []string{"rm"}
+	cmd.Short = i18n.G("Terminate persistent exec sessions")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Terminate persistent exec sessions by killing their command"))
+
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpInstances(toComplete)
+		}
+
+		if len(args) == 1 {
+			return c.global.cmpInstanceExecSessions(args[0])
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdExecSessionDelete) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing instance name"))
+	}
+
+	err = resource.server.DeleteInstanceExecSession(resource.name, args[1])
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Exec session %s terminated")+"\n", args[1])
+	}
+
+	return nil
+}
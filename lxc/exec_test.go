@@ -0,0 +1,41 @@
+package main
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecDetachReader(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		oneByte  bool
+		expected string
+		detached bool
+	}{
+		{name: "no detach", input: "ls -l\r", expected: "ls -l\r"},
+		{name: "detach", input: "ls\x10\x11exit\r", expected: "ls", detached: true},
+		{name: "detach across reads", input: "ls\x10\x11exit\r", oneByte: true, expected: "ls", detached: true},
+		{name: "lone ctrl-p", input: "a\x10b", expected: "a\x10b"},
+		{name: "lone ctrl-p across reads", input: "a\x10b", oneByte: true, expected: "a\x10b"},
+		{name: "double ctrl-p", input: "\x10\x10\x11", expected: "\x10", detached: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var reader io.Reader = &execDetachReader{reader: strings.NewReader(test.input)}
+			if test.oneByte {
+				reader = &execDetachReader{reader: iotest.OneByteReader(strings.NewReader(test.input))}
+			}
+
+			out, err := io.ReadAll(reader)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, string(out))
+			assert.Equal(t, test.detached, reader.(*execDetachReader).detached)
+		})
+	}
+}
@@ -0,0 +1,904 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	cli "github.com/canonical/lxd/shared/cmd"
+	"github.com/canonical/lxd/shared/i18n"
+	"github.com/canonical/lxd/shared/termios"
+)
+
+type cmdInstanceGroup struct {
+	global *cmdGlobal
+}
+
+func (c *cmdInstanceGroup) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("group")
+	cmd.Short = i18n.G("Manage instance groups")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(`Manage instance groups
+
+Instance groups keep a number of identical instances running, named <group>-1, <group>-2 and so on.`))
+
+	// List.
+	instanceGroupListCmd := cmdInstanceGroupList{global: c.global, instanceGroup: c}
+	cmd.AddCommand(instanceGroupListCmd.command())
+
+	// Show.
+	instanceGroupShowCmd := cmdInstanceGroupShow{global: c.global, instanceGroup: c}
+	cmd.AddCommand(instanceGroupShowCmd.command())
+
+	// Create.
+	instanceGroupCreateCmd := cmdInstanceGroupCreate{global: c.global, instanceGroup: c}
+	cmd.AddCommand(instanceGroupCreateCmd.command())
+
+	// Scale.
+	instanceGroupScaleCmd := cmdInstanceGroupScale{global: c.global, instanceGroup: c}
+	cmd.AddCommand(instanceGroupScaleCmd.command())
+
+	// Get.
+	instanceGroupGetCmd := cmdInstanceGroupGet{global: c.global, instanceGroup: c}
+	cmd.AddCommand(instanceGroupGetCmd.command())
+
+	// Set.
+	instanceGroupSetCmd := cmdInstanceGroupSet{global: c.global, instanceGroup: c}
+	cmd.AddCommand(instanceGroupSetCmd.command())
+
+	// Unset.
+	instanceGroupUnsetCmd := cmdInstanceGroupUnset{global: c.global, instanceGroup: c, instanceGroupSet: &instanceGroupSetCmd}
+	cmd.AddCommand(instanceGroupUnsetCmd.command())
+
+	// Edit.
+	instanceGroupEditCmd := cmdInstanceGroupEdit{global: c.global, instanceGroup: c}
+	cmd.AddCommand(instanceGroupEditCmd.command())
+
+	// Delete.
+	instanceGroupDeleteCmd := cmdInstanceGroupDelete{global: c.global, instanceGroup: c}
+	cmd.AddCommand(instanceGroupDeleteCmd.command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
+	return cmd
+}
+
+// List.
+type cmdInstanceGroupList struct {
+	global        *cmdGlobal
+	instanceGroup *cmdInstanceGroup
+
+	flagFormat string
+}
+
+func (c *cmdInstanceGroupList) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("list", i18n.G("[<remote>:]"))
+	cmd.Aliases = []string{"ls"}
+	cmd.Short = i18n.G("List available instance groups")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("List available instance groups"))
+
+	cmd.RunE = c.run
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpRemotes(toComplete, false)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdInstanceGroupList) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 0, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	remote := ""
+	if len(args) > 0 {
+		remote = args[0]
+	}
+
+	resources, err := c.global.ParseServers(remote)
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	groups, err := resource.server.GetInstanceGroups()
+	if err != nil {
+		return err
+	}
+
+	data := make([][]string, 0, len(groups))
+	for _, group := range groups {
+		details := []string{
+			group.Name,
+			strconv.Itoa(group.Replicas),
+			strconv.Itoa(len(group.UsedBy)),
+			group.Description,
+		}
+
+		data = append(data, details)
+	}
+
+	sort.Sort(cli.SortColumnsNaturally(data))
+
+	header := []string{
+		i18n.G("NAME"),
+		i18n.G("REPLICAS"),
+		i18n.G("INSTANCES"),
+		i18n.G("DESCRIPTION"),
+	}
+
+	return cli.RenderTable(c.flagFormat, header, data, groups)
+}
+
+// Show.
+type cmdInstanceGroupShow struct {
+	global        *cmdGlobal
+	instanceGroup *cmdInstanceGroup
+}
+
+func (c *cmdInstanceGroupShow) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("show", i18n.G("[<remote>:]<group>"))
+	cmd.Short = i18n.G("Show instance group configurations")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Show instance group configurations"))
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpInstanceGroups(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdInstanceGroupShow) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing instance group name"))
+	}
+
+	// Show the instance group config.
+	group, _, err := resource.server.GetInstanceGroup(resource.name)
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(&group)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s", data)
+
+	return nil
+}
+
+// Create.
+type cmdInstanceGroupCreate struct {
+	global        *cmdGlobal
+	instanceGroup *cmdInstanceGroup
+
+	flagConfig      []string
+	flagProfile     []string
+	flagNoProfiles  bool
+	flagVM          bool
+	flagReplicas    int
+	flagDescription string
+}
+
+func (c *cmdInstanceGroupCreate) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("create", i18n.G("[<remote>:]<group> [[<remote>:]<image>] [key=value...]"))
+	cmd.Short = i18n.G("Create instance groups")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(`Create instance groups
+
+The key=value pairs are instance group configuration keys. Use --config to set instance configuration keys in the template.
+The image must be available on the server or on a public remote.`))
+	cmd.Example = cli.FormatSection("", i18n.G(`lxc group create web ubuntu:24.04 --replicas 3 -p default -p web
+    Create an instance group that keeps 3 containers running with the default and web profiles
+
+lxc group create web ubuntu:24.04 --replicas 3 load_balancer.network=ovn0 load_balancer.listen_address=192.0.2.10 load_balancer.listen_port=80
+    Create an instance group whose members are registered in the load balancer 192.0.2.10 of network ovn0
+
+lxc group create web < group.yaml
+    Create an instance group from group.yaml`))
+
+	cmd.RunE = c.run
+	cmd.Flags().StringArrayVarP(&c.flagConfig, "config", "c", nil, i18n.G("Config key/value to apply to the members")+"``")
+	cmd.Flags().StringArrayVarP(&c.flagProfile, "profile", "p", nil, i18n.G("Profile to apply to the members")+"``")
+	cmd.Flags().BoolVar(&c.flagNoProfiles, "no-profiles", false, i18n.G("Create the members with no profiles applied"))
+	cmd.Flags().BoolVar(&c.flagVM, "vm", false, i18n.G("Create virtual machines"))
+	cmd.Flags().IntVar(&c.flagReplicas, "replicas", 1, i18n.G("Number of instances to keep running")+"``")
+	cmd.Flags().StringVar(&c.flagDescription, "description", "", i18n.G("Instance group description")+"``")
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 1 {
+			return c.global.cmpImages(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	_ = cmd.RegisterFlagCompletionFunc("profile", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return c.global.cmpProfiles(toComplete, false)
+	})
+
+	return cmd
+}
+
+func (c *cmdInstanceGroupCreate) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, -1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing instance group name"))
+	}
+
+	// If stdin isn't a terminal, read yaml from it.
+	groupPut := api.InstanceGroupPut{Replicas: c.flagReplicas}
+	if !termios.IsTerminal(getStdinFd()) {
+		contents, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		err = yaml.UnmarshalStrict(contents, &groupPut)
+		if err != nil {
+			return err
+		}
+	}
+
+	if cmd.Flags().Changed("replicas") {
+		groupPut.Replicas = c.flagReplicas
+	}
+
+	if c.flagDescription != "" {
+		groupPut.Description = c.flagDescription
+	}
+
+	if groupPut.Config == nil {
+		groupPut.Config = map[string]string{}
+	}
+
+	if groupPut.Template.Config == nil {
+		groupPut.Template.Config = map[string]string{}
+	}
+
+	// Get the image from arguments.
+	configArgs := args[1:]
+	if len(configArgs) > 0 && !strings.Contains(configArgs[0], "=") {
+		err = c.imageSource(resource.remote, configArgs[0], &groupPut.Template)
+		if err != nil {
+			return err
+		}
+
+		configArgs = configArgs[1:]
+	}
+
+	if groupPut.Template.Source.Type == "" {
+		return errors.New(i18n.G("Missing image to create the members from"))
+	}
+
+	// Get the instance group config from arguments.
+	for _, arg := range configArgs {
+		entry := strings.SplitN(arg, "=", 2)
+		if len(entry) < 2 {
+			return fmt.Errorf(i18n.G("Bad key/value pair: %s"), arg)
+		}
+
+		groupPut.Config[entry[0]] = entry[1]
+	}
+
+	// Get the template from flags.
+	for _, entry := range c.flagConfig {
+		key, value, found := strings.Cut(entry, "=")
+		if !found {
+			return fmt.Errorf(i18n.G("Bad key=value pair: %q"), entry)
+		}
+
+		groupPut.Template.Config[key] = value
+	}
+
+	if c.flagNoProfiles {
+		groupPut.Template.Profiles = []string{}
+	} else if len(c.flagProfile) > 0 {
+		groupPut.Template.Profiles = c.flagProfile
+	}
+
+	if c.flagVM {
+		groupPut.Template.Type = api.InstanceTypeVM
+	}
+
+	// Create the instance group.
+	group := api.InstanceGroupsPost{
+		Name:             resource.name,
+		InstanceGroupPut: groupPut,
+	}
+
+	group.Normalise()
+
+	err = resource.server.CreateInstanceGroup(group)
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Instance group %s created")+"\n", resource.name)
+	}
+
+	return nil
+}
+
+// imageSource fills the source of the template with the given image.
+func (c *cmdInstanceGroupCreate) imageSource(remote string, image string, template *api.InstanceGroupTemplate) error {
+	conf := c.global.conf
+
+	iremote, imageRef, err := conf.ParseRemote(image)
+	if err != nil {
+		return err
+	}
+
+	d, err := conf.GetInstanceServer(remote)
+	if err != nil {
+		return err
+	}
+
+	iremote, imageRef = guessImage(conf, d, remote, iremote, imageRef)
+
+	source := api.InstanceSource{Type: api.SourceTypeImage}
+
+	_, imgInfo, err := getImgInfo(d, conf, iremote, remote, imageRef, &source)
+	if err != nil {
+		return err
+	}
+
+	if source.Alias == "" {
+		source.Fingerprint = imgInfo.Fingerprint
+	}
+
+	// The members are created by the server, which can only pull images from public remotes.
+	if iremote != remote {
+		if !imgInfo.Public {
+			return fmt.Errorf(i18n.G("The image %q must be public to be used by an instance group"), image)
+		}
+
+		source.Server = conf.Remotes[iremote].Addr
+		source.Protocol = conf.Remotes[iremote].Protocol
+		source.Mode = "pull"
+	}
+
+	if conf.Remotes[iremote].Protocol != "simplestreams" {
+		template.Type = api.InstanceType(imgInfo.Type)
+	}
+
+	template.Source = source
+
+	return nil
+}
+
+// Scale.
+type cmdInstanceGroupScale struct {
+	global        *cmdGlobal
+	instanceGroup *cmdInstanceGroup
+}
+
+func (c *cmdInstanceGroupScale) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("scale", i18n.G("[<remote>:]<group> <replicas>"))
+	cmd.Short = i18n.G("Change the number of instances of instance groups")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(`Change the number of instances of instance groups
+
+Instances are created or deleted in the background. When scaling down, the members with the highest index are deleted first.`))
+	cmd.Example = cli.FormatSection("", i18n.G(`lxc group scale web 5
+    Keep 5 instances of the web group running`))
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpInstanceGroups(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdInstanceGroupScale) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing instance group name"))
+	}
+
+	replicas, err := strconv.Atoi(args[1])
+	if err != nil || replicas < 0 {
+		return fmt.Errorf(i18n.G("Invalid number of replicas %q"), args[1])
+	}
+
+	client := resource.server
+
+	// Get the current config.
+	group, etag, err := client.GetInstanceGroup(resource.name)
+	if err != nil {
+		return err
+	}
+
+	writable := group.Writable()
+	writable.Replicas = replicas
+
+	return client.UpdateInstanceGroup(resource.name, writable, etag)
+}
+
+// Get.
+type cmdInstanceGroupGet struct {
+	global        *cmdGlobal
+	instanceGroup *cmdInstanceGroup
+
+	flagIsProperty bool
+}
+
+func (c *cmdInstanceGroupGet) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("get", i18n.G("[<remote>:]<group> <key>"))
+	cmd.Short = i18n.G("Get values for instance group configuration keys")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Get values for instance group configuration keys"))
+
+	cmd.Flags().BoolVarP(&c.flagIsProperty, "property", "p", false, i18n.G("Get the key as an instance group property"))
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpInstanceGroups(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdInstanceGroupGet) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing instance group name"))
+	}
+
+	// Get the current config.
+	group, _, err := resource.server.GetInstanceGroup(resource.name)
+	if err != nil {
+		return err
+	}
+
+	if c.flagIsProperty {
+		w := group.Writable()
+		res, err := getFieldByJsonTag(&w, args[1])
+		if err != nil {
+			return fmt.Errorf(i18n.G("The property %q does not exist on the instance group %q: %v"), args[1], resource.name, err)
+		}
+
+		fmt.Printf("%v\n", res)
+	} else {
+		for k, v := range group.Config {
+			if k == args[1] {
+				fmt.Printf("%s\n", v)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Set.
+type cmdInstanceGroupSet struct {
+	global        *cmdGlobal
+	instanceGroup *cmdInstanceGroup
+
+	flagIsProperty bool
+}
+
+func (c *cmdInstanceGroupSet) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("set", i18n.G("[<remote>:]<group> <key>=<value>..."))
+	cmd.Short = i18n.G("Set instance group configuration keys")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Set instance group configuration keys"))
+	cmd.RunE = c.run
+
+	cmd.Flags().BoolVarP(&c.flagIsProperty, "property", "p", false, i18n.G("Set the key as an instance group property"))
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpInstanceGroups(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdInstanceGroupSet) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, -1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing instance group name"))
+	}
+
+	client := resource.server
+
+	// Get the current config.
+	group, etag, err := client.GetInstanceGroup(resource.name)
+	if err != nil {
+		return err
+	}
+
+	if group.Config == nil {
+		group.Config = map[string]string{}
+	}
+
+	// Set the keys.
+	keys, err := getConfig(args[1:]...)
+	if err != nil {
+		return err
+	}
+
+	writable := group.Writable()
+	if c.flagIsProperty {
+		if cmd.Name() == "unset" {
+			for k := range keys {
+				err := unsetFieldByJsonTag(&writable, k)
+				if err != nil {
+					return fmt.Errorf(i18n.G("Error unsetting property: %v"), err)
+				}
+			}
+		} else {
+			err := unpackKVToWritable(&writable, keys)
+			if err != nil {
+				return fmt.Errorf(i18n.G("Error setting properties: %v"), err)
+			}
+		}
+	} else {
+		for k, v := range keys {
+			writable.Config[k] = v
+		}
+	}
+
+	writable.Normalise()
+
+	return client.UpdateInstanceGroup(resource.name, writable, etag)
+}
+
+// Unset.
+type cmdInstanceGroupUnset struct {
+	global           *cmdGlobal
+	instanceGroup    *cmdInstanceGroup
+	instanceGroupSet *cmdInstanceGroupSet
+
+	flagIsProperty bool
+}
+
+func (c *cmdInstanceGroupUnset) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("unset", i18n.G("[<remote>:]<group> <key>"))
+	cmd.Short = i18n.G("Unset instance group configuration keys")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Unset instance group configuration keys"))
+	cmd.RunE = c.run
+
+	cmd.Flags().BoolVarP(&c.flagIsProperty, "property", "p", false, i18n.G("Unset the key as an instance group property"))
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpInstanceGroups(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdInstanceGroupUnset) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	c.instanceGroupSet.flagIsProperty = c.flagIsProperty
+
+	args = append(args, "")
+	return c.instanceGroupSet.run(cmd, args)
+}
+
+// Edit.
+type cmdInstanceGroupEdit struct {
+	global        *cmdGlobal
+	instanceGroup *cmdInstanceGroup
+}
+
+func (c *cmdInstanceGroupEdit) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("edit", i18n.G("[<remote>:]<group>"))
+	cmd.Short = i18n.G("Edit instance group configurations as YAML")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Edit instance group configurations as YAML"))
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpInstanceGroups(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdInstanceGroupEdit) helpTemplate() string {
+	return i18n.G(
+		`### This is a YAML representation of the instance group.
+### Any line starting with a '# will be ignored.
+###
+### An instance group keeps a number of identical instances running.
+###
+### An example would look like:
+### name: web
+### description: Web servers
+### replicas: 3
+### template:
+###   type: container
+###   source:
+###     type: image
+###     alias: "24.04"
+###     server: https://cloud-images.ubuntu.com/releases
+###     protocol: simplestreams
+###   profiles:
+###   - default
+###   config:
+###     limits.cpu: "2"
+###   devices: {}
+### config:
+###   load_balancer.network: ovn0
+###   load_balancer.listen_address: 192.0.2.10
+###   load_balancer.listen_port: "80"
+###
+### Note that the name is shown but cannot be changed.
+### Changes to the template only apply to the members that are created afterwards.`)
+}
+
+func (c *cmdInstanceGroupEdit) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing instance group name"))
+	}
+
+	client := resource.server
+
+	// If stdin isn't a terminal, read text from it
+	if !termios.IsTerminal(getStdinFd()) {
+		contents, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		// Allow output of `lxc group show` command to be passed in here, but only take the contents
+		// of the InstanceGroupPut fields when updating. The other fields are silently discarded.
+		newData := api.InstanceGroup{}
+		err = yaml.UnmarshalStrict(contents, &newData)
+		if err != nil {
+			return err
+		}
+
+		writable := newData.Writable()
+		writable.Normalise()
+
+		return client.UpdateInstanceGroup(resource.name, writable, "")
+	}
+
+	// Get the current config.
+	group, etag, err := client.GetInstanceGroup(resource.name)
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(&group)
+	if err != nil {
+		return err
+	}
+
+	// Spawn the editor.
+	content, err := shared.TextEditor("", []byte(c.helpTemplate()+"\n\n"+string(data)))
+	if err != nil {
+		return err
+	}
+
+	for {
+		// Parse the text received from the editor.
+		newData := api.InstanceGroup{} // We show the full info, but only send the writable fields.
+		err = yaml.UnmarshalStrict(content, &newData)
+		if err == nil {
+			writable := newData.Writable()
+			writable.Normalise()
+			err = client.UpdateInstanceGroup(resource.name, writable, etag)
+		}
+
+		// Respawn the editor.
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.G("Config parsing error: %s")+"\n", err)
+			fmt.Println(i18n.G("Press enter to open the editor again or ctrl+c to abort change"))
+
+			_, err := os.Stdin.Read(make([]byte, 1))
+			if err != nil {
+				return err
+			}
+
+			content, err = shared.TextEditor("", content)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		break
+	}
+
+	return nil
+}
+
+// Delete.
+type cmdInstanceGroupDelete struct {
+	global        *cmdGlobal
+	instanceGroup *cmdInstanceGroup
+}
+
+func (c *cmdInstanceGroupDelete) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("delete", i18n.G("[<remote>:]<group>"))
+	cmd.Aliases = []string{"rm"}
+	cmd.Short = i18n.G("Delete instance groups")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(`Delete instance groups
+
+The instances of the group are deleted in the background.`))
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpInstanceGroups(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdInstanceGroupDelete) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing instance group name"))
+	}
+
+	// Delete the instance group.
+	err = resource.server.DeleteInstanceGroup(resource.name)
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Instance group %s deleted")+"\n", resource.name)
+	}
+
+	return nil
+}
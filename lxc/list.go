@@ -110,6 +110,7 @@ Pre-defined column shorthand chars:
   L - Location of the instance (e.g. its cluster member)
   f - Base Image Fingerprint (short)
   F - Base Image Fingerprint (long)
+  H - Health (result of the instance health check)
 
 Custom columns are defined with "[config:|devices:]key[:name][:maxWidth]":
   KEY: The (extended) config or devices key to display. If [config:|devices:] is omitted then it defaults to config key.
@@ -567,6 +568,7 @@ func (c *cmdList) parseColumns(clustered bool) ([]column, bool, error) {
 		'e': {i18n.G("PROJECT"), c.projectColumnData, false, false},
 		'f': {i18n.G("BASE IMAGE"), c.baseImageColumnData, false, false},
 		'F': {i18n.G("BASE IMAGE"), c.baseImageFullColumnData, false, false},
+		'H': {i18n.G("HEALTH"), c.healthColumnData, false, false},
 		'l': {i18n.G("LAST USED AT"), c.lastUsedColumnData, false, false},
 		'm': {i18n.G("MEMORY USAGE"), c.memoryUsageColumnData, true, false},
 		'M': {i18n.G("MEMORY USAGE%"), c.memoryUsagePercentColumnData, true, false},
@@ -764,6 +766,14 @@ func (c *cmdList) statusColumnData(cInfo api.InstanceFull) string {
 	return strings.ToUpper(cInfo.Status)
 }
 
+func (c *cmdList) healthColumnData(cInfo api.InstanceFull) string {
+	if !cInfo.IsActive() || cInfo.ExpandedConfig["healthcheck.type"] == "" {
+		return ""
+	}
+
+	return strings.ToUpper(cInfo.ExpandedConfig["volatile.last_state.health"])
+}
+
 func (c *cmdList) ipv4ColumnData(cInfo api.InstanceFull) string {
 	if cInfo.IsActive() && cInfo.State != nil && cInfo.State.Network != nil {
 		ipv4s := []string{}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/canonical/lxd/client"
+	"github.com/canonical/lxd/shared/api"
+	cli "github.com/canonical/lxd/shared/cmd"
+	"github.com/canonical/lxd/shared/i18n"
+)
+
+type cmdLogs struct {
+	global *cmdGlobal
+
+	flagFollow  bool
+	flagConsole bool
+}
+
+func (c *cmdLogs) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("logs", i18n.G("[<remote>:]<instance> [<file>]"))
+	cmd.Short = i18n.G("Show instance log files")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Show instance log files
+
+If no log file is given, the main log of the instance is shown
+(lxc.log for containers and qemu.log for virtual machines).
+
+With --follow, new log entries are shown as they are written, including
+across restarts of the instance, until the command is interrupted.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc logs c1 -f
+    Follow the LXC log of container c1.
+
+lxc logs c1 --console -f
+    Follow the console output of container c1.
+
+lxc logs v1 qemu.log
+    Show the QEMU log of virtual machine v1.`))
+
+	cmd.RunE = c.run
+	cmd.Flags().BoolVarP(&c.flagFollow, "follow", "f", false, i18n.G("Keep showing new log entries"))
+	cmd.Flags().BoolVar(&c.flagConsole, "console", false, i18n.G("Show the console log"))
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpInstances(toComplete)
+		}
+
+		if len(args) == 1 && !c.flagConsole {
+			return c.global.cmpInstanceLogFiles(args[0])
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdLogs) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing instance name"))
+	}
+
+	if c.flagConsole && len(args) > 1 {
+		return errors.New(i18n.G("A log file can't be specified with --console"))
+	}
+
+	var log io.ReadCloser
+	if c.flagConsole {
+		log, err = resource.server.GetInstanceConsoleLog(resource.name, &lxd.InstanceConsoleLogArgs{Follow: c.flagFollow})
+		if err != nil {
+			return err
+		}
+	} else {
+		fileName, err := c.logFileName(resource.server, resource.name, args)
+		if err != nil {
+			return err
+		}
+
+		if c.flagFollow {
+			log, err = resource.server.GetInstanceLogfileFollow(resource.name, fileName)
+		} else {
+			log, err = resource.server.GetInstanceLogfile(resource.name, fileName)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	defer func() { _ = log.Close() }()
+
+	_, err = io.Copy(os.Stdout, log)
+	return err
+}
+
+// logFileName returns the log file to show, defaulting to the main log of the instance.
+func (c *cmdLogs) logFileName(d lxd.InstanceServer, instanceName string, args []string) (string, error) {
+	if len(args) > 1 {
+		return args[1], nil
+	}
+
+	inst, _, err := d.GetInstance(instanceName)
+	if err != nil {
+		return "", err
+	}
+
+	switch api.InstanceType(inst.Type) {
+	case api.InstanceTypeContainer:
+		return "lxc.log", nil
+	case api.InstanceTypeVM:
+		return "qemu.log", nil
+	}
+
+	return "", fmt.Errorf(i18n.G("Unsupported instance type: %s"), inst.Type)
+}
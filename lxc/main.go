@@ -143,10 +143,18 @@ For help with any of those, simply call them with --help.`))
 	deleteCmd := cmdDelete{global: &globalCmd}
 	app.AddCommand(deleteCmd.command())
 
+	// diff sub-command
+	diffCmd := cmdDiff{global: &globalCmd}
+	app.AddCommand(diffCmd.command())
+
 	// exec sub-command
 	execCmd := cmdExec{global: &globalCmd}
 	app.AddCommand(execCmd.command())
 
+	// exec-session sub-command
+	execSessionCmd := cmdExecSession{global: &globalCmd}
+	app.AddCommand(execSessionCmd.command())
+
 	// export sub-command
 	exportCmd := cmdExport{global: &globalCmd}
 	app.AddCommand(exportCmd.command())
@@ -155,6 +163,10 @@ For help with any of those, simply call them with --help.`))
 	fileCmd := cmdFile{global: &globalCmd}
 	app.AddCommand(fileCmd.command())
 
+	// group sub-command
+	instanceGroupCmd := cmdInstanceGroup{global: &globalCmd}
+	app.AddCommand(instanceGroupCmd.command())
+
 	// import sub-command
 	importCmd := cmdImport{global: &globalCmd}
 	app.AddCommand(importCmd.command())
@@ -179,6 +191,10 @@ For help with any of those, simply call them with --help.`))
 	listCmd := cmdList{global: &globalCmd}
 	app.AddCommand(listCmd.command())
 
+	// logs sub-command
+	logsCmd := cmdLogs{global: &globalCmd}
+	app.AddCommand(logsCmd.command())
+
 	// manpage sub-command
 	manpageCmd := cmdManpage{global: &globalCmd}
 	app.AddCommand(manpageCmd.command())
@@ -203,6 +219,10 @@ For help with any of those, simply call them with --help.`))
 	pauseCmd := cmdPause{global: &globalCmd}
 	app.AddCommand(pauseCmd.command())
 
+	// placement-group sub-command
+	placementGroupCmd := cmdPlacementGroup{global: &globalCmd}
+	app.AddCommand(placementGroupCmd.command())
+
 	// publish sub-command
 	publishCmd := cmdPublish{global: &globalCmd}
 	app.AddCommand(publishCmd.command())
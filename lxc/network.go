@@ -7,6 +7,7 @@ import (
 	"os"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v2"
@@ -113,6 +114,10 @@ func (c *cmdNetwork) command() *cobra.Command {
 	networkPeerCmd := cmdNetworkPeer{global: c.global}
 	cmd.AddCommand(networkPeerCmd.command())
 
+	// Reservation
+	networkReservationCmd := cmdNetworkReservation{global: c.global}
+	cmd.AddCommand(networkReservationCmd.command())
+
 	// Zone
 	networkZoneCmd := cmdNetworkZone{global: c.global}
 	cmd.AddCommand(networkZoneCmd.command())
@@ -986,6 +991,54 @@ func (c *cmdNetworkInfo) run(cmd *cobra.Command, args []string) error {
 		fmt.Printf("  %s: %s\n", i18n.G("Chassis"), state.OVN.Chassis)
 	}
 
+	// WireGuard information.
+	if state.WireGuard != nil {
+		fmt.Println("")
+		fmt.Println(i18n.G("WireGuard:"))
+		fmt.Printf("  %s: %s\n", i18n.G("Public key"), state.WireGuard.PublicKey)
+		fmt.Printf("  %s: %d\n", i18n.G("Listen port"), state.WireGuard.ListenPort)
+		if state.WireGuard.Endpoint != "" {
+			fmt.Printf("  %s: %s\n", i18n.G("Endpoint"), state.WireGuard.Endpoint)
+		}
+
+		if len(state.WireGuard.Peers) > 0 {
+			fmt.Printf("  %s:\n", i18n.G("Peers"))
+			for _, peer := range state.WireGuard.Peers {
+				fmt.Printf("    - %s\n", peer.PublicKey)
+				if peer.Endpoint != "" {
+					fmt.Printf("      %s: %s\n", i18n.G("Endpoint"), peer.Endpoint)
+				}
+
+				fmt.Printf("      %s: %s\n", i18n.G("Allowed IPs"), strings.Join(peer.AllowedIPs, ", "))
+				if !peer.LatestHandshake.IsZero() {
+					fmt.Printf("      %s: %s\n", i18n.G("Latest handshake"), peer.LatestHandshake.Local().Format("2006/01/02 15:04 MST"))
+				}
+
+				fmt.Printf("      %s: %s\n", i18n.G("Bytes received"), units.GetByteSizeString(int64(peer.BytesReceived), 2))
+				fmt.Printf("      %s: %s\n", i18n.G("Bytes sent"), units.GetByteSizeString(int64(peer.BytesSent), 2))
+			}
+		}
+	}
+
+	// BGP information.
+	if state.BGP != nil && len(state.BGP.Peers) > 0 {
+		fmt.Println("")
+		fmt.Println(i18n.G("BGP peers:"))
+		for _, peer := range state.BGP.Peers {
+			fmt.Printf("  - %s (%s)\n", peer.Name, peer.Address)
+			fmt.Printf("    %s: %d\n", i18n.G("ASN"), peer.ASN)
+			fmt.Printf("    %s: %s\n", i18n.G("State"), peer.State)
+			if peer.State == "established" {
+				fmt.Printf("    %s: %s\n", i18n.G("Uptime"), (time.Duration(peer.Uptime) * time.Second).String())
+			}
+
+			fmt.Printf("    %s: %d\n", i18n.G("Prefixes received"), peer.PrefixesReceived)
+			fmt.Printf("    %s: %d\n", i18n.G("Prefixes accepted"), peer.PrefixesAccepted)
+			fmt.Printf("    %s: %d\n", i18n.G("Prefixes advertised"), peer.PrefixesAdvertised)
+			fmt.Printf("    %s: %v\n", i18n.G("Import routes"), peer.Import)
+		}
+	}
+
 	return nil
 }
 
@@ -41,6 +41,10 @@ func (c *cmdNetworkACL) command() *cobra.Command {
 	networkACLShowLogCmd := cmdNetworkACLShowLog{global: c.global, networkACL: c}
 	cmd.AddCommand(networkACLShowLogCmd.command())
 
+	// Show state.
+	networkACLShowStateCmd := cmdNetworkACLShowState{global: c.global, networkACL: c}
+	cmd.AddCommand(networkACLShowStateCmd.command())
+
 	// Get.
 	networkACLGetCmd := cmdNetworkACLGet{global: c.global, networkACL: c}
 	cmd.AddCommand(networkACLGetCmd.command())
@@ -276,6 +280,66 @@ func (c *cmdNetworkACLShowLog) run(cmd *cobra.Command, args []string) error {
 	return err
 }
 
+// Show state.
+type cmdNetworkACLShowState struct {
+	global     *cmdGlobal
+	networkACL *cmdNetworkACL
+}
+
+func (c *cmdNetworkACLShowState) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("show-state", i18n.G("[<remote>:]<ACL>"))
+	cmd.Short = i18n.G("Show network ACL rule counters")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(`Show network ACL rule counters
+
+The packet and byte counters are listed in the same order as the ACL rules.`))
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworkACLs(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkACLShowState) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing network ACL name"))
+	}
+
+	// Get the ACL state.
+	aclState, err := resource.server.GetNetworkACLState(resource.name)
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(&aclState)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s", data)
+
+	return nil
+}
+
 // Get.
 type cmdNetworkACLGet struct {
 	global     *cmdGlobal
@@ -0,0 +1,755 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	cli "github.com/canonical/lxd/shared/cmd"
+	"github.com/canonical/lxd/shared/i18n"
+	"github.com/canonical/lxd/shared/termios"
+)
+
+type cmdNetworkReservation struct {
+	global *cmdGlobal
+}
+
+func (c *cmdNetworkReservation) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("reservation")
+	cmd.Short = i18n.G("Manage network DHCP reservations")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Manage network DHCP reservations"))
+
+	// List.
+	networkReservationListCmd := cmdNetworkReservationList{global: c.global, networkReservation: c}
+	cmd.AddCommand(networkReservationListCmd.command())
+
+	// Show.
+	networkReservationShowCmd := cmdNetworkReservationShow{global: c.global, networkReservation: c}
+	cmd.AddCommand(networkReservationShowCmd.command())
+
+	// Create.
+	networkReservationCreateCmd := cmdNetworkReservationCreate{global: c.global, networkReservation: c}
+	cmd.AddCommand(networkReservationCreateCmd.command())
+
+	// Get.
+	networkReservationGetCmd := cmdNetworkReservationGet{global: c.global, networkReservation: c}
+	cmd.AddCommand(networkReservationGetCmd.command())
+
+	// Set.
+	networkReservationSetCmd := cmdNetworkReservationSet{global: c.global, networkReservation: c}
+	cmd.AddCommand(networkReservationSetCmd.command())
+
+	// Unset.
+	networkReservationUnsetCmd := cmdNetworkReservationUnset{global: c.global, networkReservation: c, networkReservationSet: &networkReservationSetCmd}
+	cmd.AddCommand(networkReservationUnsetCmd.command())
+
+	// Edit.
+	networkReservationEditCmd := cmdNetworkReservationEdit{global: c.global, networkReservation: c}
+	cmd.AddCommand(networkReservationEditCmd.command())
+
+	// Delete.
+	networkReservationDeleteCmd := cmdNetworkReservationDelete{global: c.global, networkReservation: c}
+	cmd.AddCommand(networkReservationDeleteCmd.command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
+	return cmd
+}
+
+// List.
+type cmdNetworkReservationList struct {
+	global             *cmdGlobal
+	networkReservation *cmdNetworkReservation
+
+	flagFormat string
+}
+
+func (c *cmdNetworkReservationList) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("list", i18n.G("[<remote>:]<network>"))
+	cmd.Aliases = []string{"ls"}
+	cmd.Short = i18n.G("List available network DHCP reservations")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("List available network DHCP reservations"))
+
+	cmd.RunE = c.run
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworks(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkReservationList) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing network name"))
+	}
+
+	reservations, err := resource.server.GetNetworkReservations(resource.name)
+	if err != nil {
+		return err
+	}
+
+	data := make([][]string, 0, len(reservations))
+	for _, reservation := range reservations {
+		host := reservation.Config["hwaddr"]
+		if host == "" {
+			host = reservation.Config["client_id"]
+		}
+
+		details := []string{
+			reservation.Address,
+			host,
+			reservation.Config["hostname"],
+			reservation.Description,
+		}
+
+		data = append(data, details)
+	}
+
+	sort.Sort(cli.SortColumnsNaturally(data))
+
+	header := []string{
+		i18n.G("ADDRESS"),
+		i18n.G("HOST"),
+		i18n.G("HOSTNAME"),
+		i18n.G("DESCRIPTION"),
+	}
+
+	return cli.RenderTable(c.flagFormat, header, data, reservations)
+}
+
+// Show.
+type cmdNetworkReservationShow struct {
+	global             *cmdGlobal
+	networkReservation *cmdNetworkReservation
+}
+
+func (c *cmdNetworkReservationShow) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("show", i18n.G("[<remote>:]<network> <address>"))
+	cmd.Short = i18n.G("Show network DHCP reservation configurations")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Show network DHCP reservation configurations"))
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworks(toComplete)
+		}
+
+		if len(args) == 1 {
+			return c.global.cmpNetworkReservations(args[0])
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkReservationShow) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing network name"))
+	}
+
+	if args[1] == "" {
+		return errors.New(i18n.G("Missing reservation address"))
+	}
+
+	// Show the network reservation config.
+	reservation, _, err := resource.server.GetNetworkReservation(resource.name, args[1])
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(&reservation)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s", data)
+
+	return nil
+}
+
+// Create.
+type cmdNetworkReservationCreate struct {
+	global             *cmdGlobal
+	networkReservation *cmdNetworkReservation
+}
+
+func (c *cmdNetworkReservationCreate) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("create", i18n.G("[<remote>:]<network> <address> [key=value...]"))
+	cmd.Short = i18n.G("Create new network DHCP reservations")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Create new network DHCP reservations"))
+	cmd.Example = cli.FormatSection("", i18n.G(`lxc network reservation create n1 10.0.0.10 hwaddr=00:16:3e:11:22:33 hostname=nas
+    Reserve 10.0.0.10 on network n1 for the host with MAC address 00:16:3e:11:22:33
+
+lxc network reservation create n1 10.0.0.10 < config.yaml
+    Create a new network DHCP reservation for network n1 from config.yaml`))
+
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworks(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkReservationCreate) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, -1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing network name"))
+	}
+
+	if args[1] == "" {
+		return errors.New(i18n.G("Missing reservation address"))
+	}
+
+	// If stdin isn't a terminal, read yaml from it.
+	var reservationPut api.NetworkReservationPut
+	if !termios.IsTerminal(getStdinFd()) {
+		contents, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		err = yaml.UnmarshalStrict(contents, &reservationPut)
+		if err != nil {
+			return err
+		}
+	}
+
+	if reservationPut.Config == nil {
+		reservationPut.Config = map[string]string{}
+	}
+
+	// Get config from arguments.
+	for i := 2; i < len(args); i++ {
+		entry := strings.SplitN(args[i], "=", 2)
+		if len(entry) < 2 {
+			return fmt.Errorf(i18n.G("Bad key/value pair: %s"), args[i])
+		}
+
+		reservationPut.Config[entry[0]] = entry[1]
+	}
+
+	// Create the network reservation.
+	reservation := api.NetworkReservationsPost{
+		Address:               args[1],
+		NetworkReservationPut: reservationPut,
+	}
+
+	reservation.Normalise()
+
+	err = resource.server.CreateNetworkReservation(resource.name, reservation)
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Network reservation %s created")+"\n", reservation.Address)
+	}
+
+	return nil
+}
+
+// Get.
+type cmdNetworkReservationGet struct {
+	global             *cmdGlobal
+	networkReservation *cmdNetworkReservation
+
+	flagIsProperty bool
+}
+
+func (c *cmdNetworkReservationGet) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("get", i18n.G("[<remote>:]<network> <address> <key>"))
+	cmd.Short = i18n.G("Get values for network DHCP reservation configuration keys")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Get values for network DHCP reservation configuration keys"))
+
+	cmd.Flags().BoolVarP(&c.flagIsProperty, "property", "p", false, i18n.G("Get the key as a network reservation property"))
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworks(toComplete)
+		}
+
+		if len(args) == 1 {
+			return c.global.cmpNetworkReservations(args[0])
+		}
+
+		if len(args) == 2 {
+			return c.global.cmpNetworkReservationConfigs(args[0], args[1])
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkReservationGet) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 3, 3)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing network name"))
+	}
+
+	if args[1] == "" {
+		return errors.New(i18n.G("Missing reservation address"))
+	}
+
+	// Get the current config.
+	reservation, _, err := resource.server.GetNetworkReservation(resource.name, args[1])
+	if err != nil {
+		return err
+	}
+
+	if c.flagIsProperty {
+		w := reservation.Writable()
+		res, err := getFieldByJsonTag(&w, args[2])
+		if err != nil {
+			return fmt.Errorf(i18n.G("The property %q does not exist on the network reservation %q: %v"), args[2], args[1], err)
+		}
+
+		fmt.Printf("%v\n", res)
+	} else {
+		for k, v := range reservation.Config {
+			if k == args[2] {
+				fmt.Printf("%s\n", v)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Set.
+type cmdNetworkReservationSet struct {
+	global             *cmdGlobal
+	networkReservation *cmdNetworkReservation
+
+	flagIsProperty bool
+}
+
+func (c *cmdNetworkReservationSet) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("set", i18n.G("[<remote>:]<network> <address> <key>=<value>..."))
+	cmd.Short = i18n.G("Set network DHCP reservation keys")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Set network DHCP reservation keys"))
+	cmd.RunE = c.run
+
+	cmd.Flags().BoolVarP(&c.flagIsProperty, "property", "p", false, i18n.G("Set the key as a network reservation property"))
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworks(toComplete)
+		}
+
+		if len(args) == 1 {
+			return c.global.cmpNetworkReservations(args[0])
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkReservationSet) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 3, -1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing network name"))
+	}
+
+	if args[1] == "" {
+		return errors.New(i18n.G("Missing reservation address"))
+	}
+
+	client := resource.server
+
+	// Get the current config.
+	reservation, etag, err := client.GetNetworkReservation(resource.name, args[1])
+	if err != nil {
+		return err
+	}
+
+	if reservation.Config == nil {
+		reservation.Config = map[string]string{}
+	}
+
+	// Set the keys.
+	keys, err := getConfig(args[2:]...)
+	if err != nil {
+		return err
+	}
+
+	writable := reservation.Writable()
+	if c.flagIsProperty {
+		if cmd.Name() == "unset" {
+			for k := range keys {
+				err := unsetFieldByJsonTag(&writable, k)
+				if err != nil {
+					return fmt.Errorf(i18n.G("Error unsetting property: %v"), err)
+				}
+			}
+		} else {
+			err := unpackKVToWritable(&writable, keys)
+			if err != nil {
+				return fmt.Errorf(i18n.G("Error setting properties: %v"), err)
+			}
+		}
+	} else {
+		for k, v := range keys {
+			writable.Config[k] = v
+		}
+	}
+
+	writable.Normalise()
+
+	return client.UpdateNetworkReservation(resource.name, reservation.Address, writable, etag)
+}
+
+// Unset.
+type cmdNetworkReservationUnset struct {
+	global                *cmdGlobal
+	networkReservation    *cmdNetworkReservation
+	networkReservationSet *cmdNetworkReservationSet
+
+	flagIsProperty bool
+}
+
+func (c *cmdNetworkReservationUnset) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("unset", i18n.G("[<remote>:]<network> <address> <key>"))
+	cmd.Short = i18n.G("Unset network DHCP reservation configuration keys")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Unset network DHCP reservation keys"))
+	cmd.RunE = c.run
+
+	cmd.Flags().BoolVarP(&c.flagIsProperty, "property", "p", false, i18n.G("Unset the key as a network reservation property"))
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworks(toComplete)
+		}
+
+		if len(args) == 1 {
+			return c.global.cmpNetworkReservations(args[0])
+		}
+
+		if len(args) == 2 {
+			return c.global.cmpNetworkReservationConfigs(args[0], args[1])
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkReservationUnset) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 3, 3)
+	if exit {
+		return err
+	}
+
+	c.networkReservationSet.flagIsProperty = c.flagIsProperty
+
+	args = append(args, "")
+	return c.networkReservationSet.run(cmd, args)
+}
+
+// Edit.
+type cmdNetworkReservationEdit struct {
+	global             *cmdGlobal
+	networkReservation *cmdNetworkReservation
+}
+
+func (c *cmdNetworkReservationEdit) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("edit", i18n.G("[<remote>:]<network> <address>"))
+	cmd.Short = i18n.G("Edit network DHCP reservation configurations as YAML")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Edit network DHCP reservation configurations as YAML"))
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworks(toComplete)
+		}
+
+		if len(args) == 1 {
+			return c.global.cmpNetworkReservations(args[0])
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkReservationEdit) helpTemplate() string {
+	return i18n.G(
+		`### This is a YAML representation of the network DHCP reservation.
+### Any line starting with a '# will be ignored.
+###
+### A network DHCP reservation assigns a fixed address to a host identified by its MAC address or DHCP client identifier.
+###
+### An example would look like:
+### address: 10.0.0.10
+### config:
+###   hwaddr: 00:16:3e:11:22:33
+###   hostname: nas
+### description: Storage appliance
+###
+### Note that the address cannot be changed.`)
+}
+
+func (c *cmdNetworkReservationEdit) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing network name"))
+	}
+
+	if args[1] == "" {
+		return errors.New(i18n.G("Missing reservation address"))
+	}
+
+	client := resource.server
+
+	// If stdin isn't a terminal, read text from it
+	if !termios.IsTerminal(getStdinFd()) {
+		contents, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		// Allow output of `lxc network reservation show` command to be passed in here, but only take the
+		// contents of the NetworkReservationPut fields when updating. The other fields are silently discarded.
+		newData := api.NetworkReservation{}
+		err = yaml.UnmarshalStrict(contents, &newData)
+		if err != nil {
+			return err
+		}
+
+		newData.Normalise()
+
+		return client.UpdateNetworkReservation(resource.name, args[1], newData.Writable(), "")
+	}
+
+	// Get the current config.
+	reservation, etag, err := client.GetNetworkReservation(resource.name, args[1])
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(&reservation)
+	if err != nil {
+		return err
+	}
+
+	// Spawn the editor.
+	content, err := shared.TextEditor("", []byte(c.helpTemplate()+"\n\n"+string(data)))
+	if err != nil {
+		return err
+	}
+
+	for {
+		// Parse the text received from the editor.
+		newData := api.NetworkReservation{} // We show the full info, but only send the writable fields.
+		err = yaml.UnmarshalStrict(content, &newData)
+		if err == nil {
+			newData.Normalise()
+			err = client.UpdateNetworkReservation(resource.name, args[1], newData.Writable(), etag)
+		}
+
+		// Respawn the editor.
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.G("Config parsing error: %s")+"\n", err)
+			fmt.Println(i18n.G("Press enter to open the editor again or ctrl+c to abort change"))
+
+			_, err := os.Stdin.Read(make([]byte, 1))
+			if err != nil {
+				return err
+			}
+
+			content, err = shared.TextEditor("", content)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		break
+	}
+
+	return nil
+}
+
+// Delete.
+type cmdNetworkReservationDelete struct {
+	global             *cmdGlobal
+	networkReservation *cmdNetworkReservation
+}
+
+func (c *cmdNetworkReservationDelete) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("delete", i18n.G("[<remote>:]<network> <address>"))
+	cmd.Aliases = []string{"rm"}
+	cmd.Short = i18n.G("Delete network DHCP reservations")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Delete network DHCP reservations"))
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworks(toComplete)
+		}
+
+		if len(args) == 1 {
+			return c.global.cmpNetworkReservations(args[0])
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkReservationDelete) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing network name"))
+	}
+
+	if args[1] == "" {
+		return errors.New(i18n.G("Missing reservation address"))
+	}
+
+	// Delete the network reservation.
+	err = resource.server.DeleteNetworkReservation(resource.name, args[1])
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Network reservation %s deleted")+"\n", args[1])
+	}
+
+	return nil
+}
@@ -60,6 +60,14 @@ func (c *cmdNetworkZone) command() *cobra.Command {
 	networkZoneDeleteCmd := cmdNetworkZoneDelete{global: c.global, networkZone: c}
 	cmd.AddCommand(networkZoneDeleteCmd.command())
 
+	// Export.
+	networkZoneExportCmd := cmdNetworkZoneExport{global: c.global, networkZone: c}
+	cmd.AddCommand(networkZoneExportCmd.command())
+
+	// Import.
+	networkZoneImportCmd := cmdNetworkZoneImport{global: c.global, networkZone: c}
+	cmd.AddCommand(networkZoneImportCmd.command())
+
 	// Record.
 	networkZoneRecordCmd := cmdNetworkZoneRecord{global: c.global, networkZone: c}
 	cmd.AddCommand(networkZoneRecordCmd.command())
@@ -715,6 +723,163 @@ func (c *cmdNetworkZoneDelete) run(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// Export.
+type cmdNetworkZoneExport struct {
+	global      *cmdGlobal
+	networkZone *cmdNetworkZone
+}
+
+func (c *cmdNetworkZoneExport) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("export", i18n.G("[<remote>:]<Zone> [<file>]"))
+	cmd.Short = i18n.G("Export network zones as zone files")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(`Export network zones as zone files
+
+The zone is exported as a RFC 1035 master file containing both the records
+generated from the networks using the zone and the custom records.
+If no file is provided, the zone is written to the standard output.`))
+	cmd.Example = cli.FormatSection("", i18n.G(`lxc network zone export example.net example.net.db
+    Export the network zone "example.net" to the "example.net.db" file.`))
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworkZones(toComplete)
+		}
+
+		if len(args) == 1 {
+			return nil, cobra.ShellCompDirectiveDefault
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkZoneExport) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing network zone name"))
+	}
+
+	// Get the zone file.
+	content, err := resource.server.GetNetworkZoneFile(resource.name)
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 1 {
+		fmt.Print(content)
+		return nil
+	}
+
+	err = os.WriteFile(args[1], []byte(content), 0644)
+	if err != nil {
+		return fmt.Errorf(i18n.G("Failed writing zone file: %w"), err)
+	}
+
+	return nil
+}
+
+// Import.
+type cmdNetworkZoneImport struct {
+	global      *cmdGlobal
+	networkZone *cmdNetworkZone
+}
+
+func (c *cmdNetworkZoneImport) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("import", i18n.G("[<remote>:]<Zone> [<file>]"))
+	cmd.Short = i18n.G("Import network zone records from zone files")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(`Import network zone records from zone files
+
+The records of a RFC 1035 (BIND format) master file are added as custom records
+of the network zone. Entries are added to the existing records of the same name,
+and the records of the zone apex are added to the "@" record.
+The records generated by LXD, such as the SOA and NS records of the zone apex, are
+skipped so that an exported zone can be imported back.
+All records are imported at once, the zone is left unchanged if any of them fails.
+If no file is provided, the zone file is read from the standard input.`))
+	cmd.Example = cli.FormatSection("", i18n.G(`lxc network zone import example.net example.net.db
+    Add the records of the "example.net.db" zone file to the network zone "example.net".`))
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworkZones(toComplete)
+		}
+
+		if len(args) == 1 {
+			return nil, cobra.ShellCompDirectiveDefault
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkZoneImport) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing network zone name"))
+	}
+
+	// Open the zone file.
+	var reader io.Reader
+	if len(args) == 2 {
+		file, err := os.Open(args[1])
+		if err != nil {
+			return err
+		}
+
+		defer func() { _ = file.Close() }()
+
+		reader = file
+	} else {
+		reader = os.Stdin
+	}
+
+	// Import the records.
+	err = resource.server.ImportNetworkZoneFile(resource.name, reader)
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Network zone %s imported")+"\n", resource.name)
+	}
+
+	return nil
+}
+
 // Add/Remove Rule.
 type cmdNetworkZoneRecord struct {
 	global      *cmdGlobal
@@ -0,0 +1,716 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	cli "github.com/canonical/lxd/shared/cmd"
+	"github.com/canonical/lxd/shared/i18n"
+	"github.com/canonical/lxd/shared/termios"
+)
+
+type cmdPlacementGroup struct {
+	global *cmdGlobal
+}
+
+func (c *cmdPlacementGroup) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("placement-group")
+	cmd.Short = i18n.G("Manage placement groups")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(`Manage placement groups
+
+Placement groups control how their instances are spread across cluster members.`))
+
+	// List.
+	placementGroupListCmd := cmdPlacementGroupList{global: c.global, placementGroup: c}
+	cmd.AddCommand(placementGroupListCmd.command())
+
+	// Show.
+	placementGroupShowCmd := cmdPlacementGroupShow{global: c.global, placementGroup: c}
+	cmd.AddCommand(placementGroupShowCmd.command())
+
+	// Create.
+	placementGroupCreateCmd := cmdPlacementGroupCreate{global: c.global, placementGroup: c}
+	cmd.AddCommand(placementGroupCreateCmd.command())
+
+	// Get.
+	placementGroupGetCmd := cmdPlacementGroupGet{global: c.global, placementGroup: c}
+	cmd.AddCommand(placementGroupGetCmd.command())
+
+	// Set.
+	placementGroupSetCmd := cmdPlacementGroupSet{global: c.global, placementGroup: c}
+	cmd.AddCommand(placementGroupSetCmd.command())
+
+	// Unset.
+	placementGroupUnsetCmd := cmdPlacementGroupUnset{global: c.global, placementGroup: c, placementGroupSet: &placementGroupSetCmd}
+	cmd.AddCommand(placementGroupUnsetCmd.command())
+
+	// Edit.
+	placementGroupEditCmd := cmdPlacementGroupEdit{global: c.global, placementGroup: c}
+	cmd.AddCommand(placementGroupEditCmd.command())
+
+	// Delete.
+	placementGroupDeleteCmd := cmdPlacementGroupDelete{global: c.global, placementGroup: c}
+	cmd.AddCommand(placementGroupDeleteCmd.command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
+	return cmd
+}
+
+// List.
+type cmdPlacementGroupList struct {
+	global         *cmdGlobal
+	placementGroup *cmdPlacementGroup
+
+	flagFormat string
+}
+
+func (c *cmdPlacementGroupList) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("list", i18n.G("[<remote>:]"))
+	cmd.Aliases = []string{"ls"}
+	cmd.Short = i18n.G("List available placement groups")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("List available placement groups"))
+
+	cmd.RunE = c.run
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpRemotes(toComplete, false)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdPlacementGroupList) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 0, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	remote := ""
+	if len(args) > 0 {
+		remote = args[0]
+	}
+
+	resources, err := c.global.ParseServers(remote)
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	groups, err := resource.server.GetPlacementGroups()
+	if err != nil {
+		return err
+	}
+
+	data := make([][]string, 0, len(groups))
+	for _, group := range groups {
+		details := []string{
+			group.Name,
+			group.Config["policy"],
+			group.Config["mode"],
+			strconv.Itoa(len(group.UsedBy)),
+			group.Description,
+		}
+
+		data = append(data, details)
+	}
+
+	sort.Sort(cli.SortColumnsNaturally(data))
+
+	header := []string{
+		i18n.G("NAME"),
+		i18n.G("POLICY"),
+		i18n.G("MODE"),
+		i18n.G("USED BY"),
+		i18n.G("DESCRIPTION"),
+	}
+
+	return cli.RenderTable(c.flagFormat, header, data, groups)
+}
+
+// Show.
+type cmdPlacementGroupShow struct {
+	global         *cmdGlobal
+	placementGroup *cmdPlacementGroup
+}
+
+func (c *cmdPlacementGroupShow) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("show", i18n.G("[<remote>:]<group>"))
+	cmd.Short = i18n.G("Show placement group configurations")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Show placement group configurations"))
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpPlacementGroups(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdPlacementGroupShow) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing placement group name"))
+	}
+
+	// Show the placement group config.
+	group, _, err := resource.server.GetPlacementGroup(resource.name)
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(&group)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s", data)
+
+	return nil
+}
+
+// Create.
+type cmdPlacementGroupCreate struct {
+	global         *cmdGlobal
+	placementGroup *cmdPlacementGroup
+
+	flagDescription string
+}
+
+func (c *cmdPlacementGroupCreate) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("create", i18n.G("[<remote>:]<group> [key=value...]"))
+	cmd.Short = i18n.G("Create placement groups")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Create placement groups"))
+	cmd.Example = cli.FormatSection("", i18n.G(`lxc placement-group create db policy=spread mode=strict
+    Create a placement group that places each of its instances on a different cluster member
+
+lxc placement-group create cache policy=compact mode=soft
+    Create a placement group that keeps its instances together when possible
+
+lxc placement-group create db < group.yaml
+    Create a placement group from group.yaml`))
+
+	cmd.RunE = c.run
+	cmd.Flags().StringVar(&c.flagDescription, "description", "", i18n.G("Placement group description")+"``")
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpRemotes(toComplete, false)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdPlacementGroupCreate) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, -1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing placement group name"))
+	}
+
+	// If stdin isn't a terminal, read yaml from it.
+	var groupPut api.PlacementGroupPut
+	if !termios.IsTerminal(getStdinFd()) {
+		contents, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		err = yaml.UnmarshalStrict(contents, &groupPut)
+		if err != nil {
+			return err
+		}
+	}
+
+	if c.flagDescription != "" {
+		groupPut.Description = c.flagDescription
+	}
+
+	if groupPut.Config == nil {
+		groupPut.Config = map[string]string{}
+	}
+
+	// Get the placement group config from arguments.
+	for _, arg := range args[1:] {
+		entry := strings.SplitN(arg, "=", 2)
+		if len(entry) < 2 {
+			return fmt.Errorf(i18n.G("Bad key/value pair: %s"), arg)
+		}
+
+		groupPut.Config[entry[0]] = entry[1]
+	}
+
+	// Create the placement group.
+	group := api.PlacementGroupsPost{
+		Name:              resource.name,
+		PlacementGroupPut: groupPut,
+	}
+
+	group.Normalise()
+
+	err = resource.server.CreatePlacementGroup(group)
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Placement group %s created")+"\n", resource.name)
+	}
+
+	return nil
+}
+
+// Get.
+type cmdPlacementGroupGet struct {
+	global         *cmdGlobal
+	placementGroup *cmdPlacementGroup
+
+	flagIsProperty bool
+}
+
+func (c *cmdPlacementGroupGet) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("get", i18n.G("[<remote>:]<group> <key>"))
+	cmd.Short = i18n.G("Get values for placement group configuration keys")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Get values for placement group configuration keys"))
+
+	cmd.Flags().BoolVarP(&c.flagIsProperty, "property", "p", false, i18n.G("Get the key as a placement group property"))
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpPlacementGroups(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdPlacementGroupGet) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing placement group name"))
+	}
+
+	// Get the current config.
+	group, _, err := resource.server.GetPlacementGroup(resource.name)
+	if err != nil {
+		return err
+	}
+
+	if c.flagIsProperty {
+		w := group.Writable()
+		res, err := getFieldByJsonTag(&w, args[1])
+		if err != nil {
+			return fmt.Errorf(i18n.G("The property %q does not exist on the placement group %q: %v"), args[1], resource.name, err)
+		}
+
+		fmt.Printf("%v\n", res)
+	} else {
+		for k, v := range group.Config {
+			if k == args[1] {
+				fmt.Printf("%s\n", v)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Set.
+type cmdPlacementGroupSet struct {
+	global         *cmdGlobal
+	placementGroup *cmdPlacementGroup
+
+	flagIsProperty bool
+}
+
+func (c *cmdPlacementGroupSet) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("set", i18n.G("[<remote>:]<group> <key>=<value>..."))
+	cmd.Short = i18n.G("Set placement group configuration keys")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Set placement group configuration keys"))
+	cmd.RunE = c.run
+
+	cmd.Flags().BoolVarP(&c.flagIsProperty, "property", "p", false, i18n.G("Set the key as a placement group property"))
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpPlacementGroups(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdPlacementGroupSet) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, -1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing placement group name"))
+	}
+
+	client := resource.server
+
+	// Get the current config.
+	group, etag, err := client.GetPlacementGroup(resource.name)
+	if err != nil {
+		return err
+	}
+
+	if group.Config == nil {
+		group.Config = map[string]string{}
+	}
+
+	// Set the keys.
+	keys, err := getConfig(args[1:]...)
+	if err != nil {
+		return err
+	}
+
+	writable := group.Writable()
+	if c.flagIsProperty {
+		if cmd.Name() == "unset" {
+			for k := range keys {
+				err := unsetFieldByJsonTag(&writable, k)
+				if err != nil {
+					return fmt.Errorf(i18n.G("Error unsetting property: %v"), err)
+				}
+			}
+		} else {
+			err := unpackKVToWritable(&writable, keys)
+			if err != nil {
+				return fmt.Errorf(i18n.G("Error setting properties: %v"), err)
+			}
+		}
+	} else {
+		for k, v := range keys {
+			writable.Config[k] = v
+		}
+	}
+
+	writable.Normalise()
+
+	return client.UpdatePlacementGroup(resource.name, writable, etag)
+}
+
+// Unset.
+type cmdPlacementGroupUnset struct {
+	global            *cmdGlobal
+	placementGroup    *cmdPlacementGroup
+	placementGroupSet *cmdPlacementGroupSet
+
+	flagIsProperty bool
+}
+
+func (c *cmdPlacementGroupUnset) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("unset", i18n.G("[<remote>:]<group> <key>"))
+	cmd.Short = i18n.G("Unset placement group configuration keys")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Unset placement group configuration keys"))
+	cmd.RunE = c.run
+
+	cmd.Flags().BoolVarP(&c.flagIsProperty, "property", "p", false, i18n.G("Unset the key as a placement group property"))
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpPlacementGroups(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdPlacementGroupUnset) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	c.placementGroupSet.flagIsProperty = c.flagIsProperty
+
+	args = append(args, "")
+	return c.placementGroupSet.run(cmd, args)
+}
+
+// Edit.
+type cmdPlacementGroupEdit struct {
+	global         *cmdGlobal
+	placementGroup *cmdPlacementGroup
+}
+
+func (c *cmdPlacementGroupEdit) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("edit", i18n.G("[<remote>:]<group>"))
+	cmd.Short = i18n.G("Edit placement group configurations as YAML")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Edit placement group configurations as YAML"))
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpPlacementGroups(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdPlacementGroupEdit) helpTemplate() string {
+	return i18n.G(
+		`### This is a YAML representation of the placement group.
+### Any line starting with a '# will be ignored.
+###
+### A placement group controls how its instances are spread across cluster members.
+### Instances join the group through their placement.group configuration key.
+###
+### An example would look like:
+### name: db
+### description: Database servers
+### config:
+###   policy: spread
+###   mode: strict
+###
+### Note that the name is shown but cannot be changed.
+### Changes only affect where instances are placed from now on, running instances are not moved.`)
+}
+
+func (c *cmdPlacementGroupEdit) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing placement group name"))
+	}
+
+	client := resource.server
+
+	// If stdin isn't a terminal, read text from it
+	if !termios.IsTerminal(getStdinFd()) {
+		contents, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		// Allow output of `lxc placement-group show` command to be passed in here, but only take the contents
+		// of the InstanceGroupPut fields when updating. The other fields are silently discarded.
+		newData := api.PlacementGroup{}
+		err = yaml.UnmarshalStrict(contents, &newData)
+		if err != nil {
+			return err
+		}
+
+		writable := newData.Writable()
+		writable.Normalise()
+
+		return client.UpdatePlacementGroup(resource.name, writable, "")
+	}
+
+	// Get the current config.
+	group, etag, err := client.GetPlacementGroup(resource.name)
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(&group)
+	if err != nil {
+		return err
+	}
+
+	// Spawn the editor.
+	content, err := shared.TextEditor("", []byte(c.helpTemplate()+"\n\n"+string(data)))
+	if err != nil {
+		return err
+	}
+
+	for {
+		// Parse the text received from the editor.
+		newData := api.PlacementGroup{} // We show the full info, but only send the writable fields.
+		err = yaml.UnmarshalStrict(content, &newData)
+		if err == nil {
+			writable := newData.Writable()
+			writable.Normalise()
+			err = client.UpdatePlacementGroup(resource.name, writable, etag)
+		}
+
+		// Respawn the editor.
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.G("Config parsing error: %s")+"\n", err)
+			fmt.Println(i18n.G("Press enter to open the editor again or ctrl+c to abort change"))
+
+			_, err := os.Stdin.Read(make([]byte, 1))
+			if err != nil {
+				return err
+			}
+
+			content, err = shared.TextEditor("", content)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		break
+	}
+
+	return nil
+}
+
+// Delete.
+type cmdPlacementGroupDelete struct {
+	global         *cmdGlobal
+	placementGroup *cmdPlacementGroup
+}
+
+func (c *cmdPlacementGroupDelete) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("delete", i18n.G("[<remote>:]<group>"))
+	cmd.Aliases = []string{"rm"}
+	cmd.Short = i18n.G("Delete placement groups")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(`Delete placement groups
+
+A placement group can only be deleted once no instance refers to it.`))
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpPlacementGroups(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdPlacementGroupDelete) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing placement group name"))
+	}
+
+	// Delete the placement group.
+	err = resource.server.DeletePlacementGroup(resource.name)
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Placement group %s deleted")+"\n", resource.name)
+	}
+
+	return nil
+}
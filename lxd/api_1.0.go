@@ -59,10 +59,17 @@ var api10 = []APIEndpoint{
 	instanceBackupsCmd,
 	instanceCmd,
 	instanceConsoleCmd,
+	instanceDiffCmd,
 	instanceExecCmd,
+	instanceExecSessionsCmd,
+	instanceExecSessionCmd,
 	instanceFileCmd,
 	instanceExecOutputCmd,
 	instanceExecOutputsCmd,
+	instanceSessionRecordingCmd,
+	instanceSessionRecordingsCmd,
+	instanceGroupCmd,
+	instanceGroupsCmd,
 	instanceLogCmd,
 	instanceLogsCmd,
 	instanceMetadataCmd,
@@ -90,6 +97,7 @@ var api10 = []APIEndpoint{
 	networkACLCmd,
 	networkACLsCmd,
 	networkACLLogCmd,
+	networkACLStateCmd,
 	networkAllocationsCmd,
 	networkForwardCmd,
 	networkForwardsCmd,
@@ -97,14 +105,20 @@ var api10 = []APIEndpoint{
 	networkLoadBalancersCmd,
 	networkPeerCmd,
 	networkPeersCmd,
+	networkReservationCmd,
+	networkReservationsCmd,
 	networkZoneCmd,
 	networkZonesCmd,
+	networkZoneExportCmd,
+	networkZoneImportCmd,
 	networkZoneRecordCmd,
 	networkZoneRecordsCmd,
 	operationCmd,
 	operationsCmd,
 	operationWait,
 	operationWebsocket,
+	placementGroupCmd,
+	placementGroupsCmd,
 	profileCmd,
 	profilesCmd,
 	projectCmd,
@@ -3317,7 +3317,8 @@ func evacuateInstances(ctx context.Context, opts evacuateOpts) error {
 
 	metadata := make(map[string]any)
 
-	for _, inst := range opts.instances {
+	// Move the instances in dependency order so that dependencies are started on their new member first.
+	for _, inst := range instancesSortByDependencies(opts.instances) {
 		instProject := inst.Project()
 		l := logger.AddContext(logger.Ctx{"project": instProject.Name, "instance": inst.Name()})
 
@@ -3370,10 +3371,33 @@ func evacuateInstances(ctx context.Context, opts evacuateOpts) error {
 				return err
 			}
 
+			// Only keep the members that satisfy the placement group of the instance.
+			candidateMembers, err = placementGroupCandidates(ctx, tx, instProject.Name, inst.Name(), inst.ExpandedConfig(), candidateMembers)
+			if err != nil {
+				return err
+			}
+
 			return nil
 		})
 		if err != nil {
-			return err
+			if !api.StatusErrorCheck(err, http.StatusNotFound) {
+				return err
+			}
+
+			// The instance can't be moved without breaking its placement group, so it is stopped instead.
+			l.Warn("Not migrating instance as no cluster member satisfies its placement group", logger.Ctx{"err": err})
+
+			if opts.stopInstance != nil && inst.IsRunning() {
+				metadata["evacuation_progress"] = fmt.Sprintf("Stopping %q in project %q", inst.Name(), instProject.Name)
+				_ = opts.op.UpdateMetadata(metadata)
+
+				err := opts.stopInstance(inst)
+				if err != nil {
+					return err
+				}
+			}
+
+			continue
 		}
 
 		targetMemberInfo, err := evacuateClusterSelectTarget(ctx, opts.s, opts.gateway, inst, candidateMembers)
@@ -3395,6 +3419,16 @@ func evacuateInstances(ctx context.Context, opts evacuateOpts) error {
 		}
 
 		start := isRunning || instanceShouldAutoStart(inst)
+
+		// Wait for the instances it depends on to be ready before starting it on its new member.
+		if start && !(isRunning && live) {
+			err = instanceDependenciesWait(ctx, opts.s, inst)
+			if err != nil {
+				l.Warn("Not starting instance after migration as its dependencies aren't ready", logger.Ctx{"err": err})
+				start = false
+			}
+		}
+
 		err = opts.migrateInstance(opts.s, opts.r, inst, targetMemberInfo, live, start, metadata, opts.op)
 		if err != nil {
 			return err
@@ -3476,8 +3510,8 @@ func restoreClusterMember(d *Daemon, r *http.Request) response.Response {
 			return err
 		}
 
-		// Restart the local instances.
-		for _, inst := range localInstances {
+		// Restart the local instances, starting dependencies first.
+		for _, inst := range instancesSortByDependencies(localInstances) {
 			// Don't start instances which were stopped by the user.
 			if inst.LocalConfig()["volatile.last_state.power"] != instance.PowerStateRunning {
 				continue
@@ -3488,6 +3522,13 @@ func restoreClusterMember(d *Daemon, r *http.Request) response.Response {
 				continue
 			}
 
+			// Wait for the instances it depends on to be ready.
+			err = instanceDependenciesWait(s.ShutdownCtx, s, inst)
+			if err != nil {
+				logger.Warn("Not starting instance as its dependencies aren't ready", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "err": err})
+				continue
+			}
+
 			// Start the instance.
 			metadata["evacuation_progress"] = fmt.Sprintf("Starting %q in project %q", inst.Name(), inst.Project().Name)
 			_ = op.UpdateMetadata(metadata)
@@ -3498,8 +3539,8 @@ func restoreClusterMember(d *Daemon, r *http.Request) response.Response {
 			}
 		}
 
-		// Migrate back the remote instances.
-		for _, inst := range instances {
+		// Migrate back the remote instances, moving dependencies first.
+		for _, inst := range instancesSortByDependencies(instances) {
 			l := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
 
 			// Check if live-migratable.
@@ -3616,6 +3657,13 @@ func restoreClusterMember(d *Daemon, r *http.Request) response.Response {
 				continue
 			}
 
+			// Wait for the instances it depends on to be ready.
+			err = instanceDependenciesWait(s.ShutdownCtx, s, inst)
+			if err != nil {
+				l.Warn("Not starting instance as its dependencies aren't ready", logger.Ctx{"err": err})
+				continue
+			}
+
 			metadata["evacuation_progress"] = fmt.Sprintf("Starting %q in project %q", inst.Name(), inst.Project().Name)
 			_ = op.UpdateMetadata(metadata)
 
@@ -7,6 +7,7 @@ import (
 	"net"
 	"net/http"
 	"runtime"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -20,6 +21,7 @@ import (
 	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/lxd/locking"
 	"github.com/canonical/lxd/lxd/metrics"
+	"github.com/canonical/lxd/lxd/network/acl"
 	"github.com/canonical/lxd/lxd/request"
 	"github.com/canonical/lxd/lxd/response"
 	"github.com/canonical/lxd/lxd/state"
@@ -137,6 +139,17 @@ func metricsGet(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
+	// Include the network ACL rule counters with the internal metrics so they are not cached.
+	for _, projectName := range projectNames {
+		aclMetrics, err := acl.Metrics(s, projectName)
+		if err != nil {
+			logger.Warn("Failed getting network ACL metrics", logger.Ctx{"project": projectName, "err": err})
+			continue
+		}
+
+		intMetrics.Merge(aclMetrics)
+	}
+
 	// invalidProjectFilters returns project filters which are either not in cache or have expired.
 	invalidProjectFilters := func(projectNames []string) []dbCluster.InstanceFilter {
 		metricsCacheLock.Lock()
@@ -450,6 +463,29 @@ func internalMetrics(ctx context.Context, s *state.State, tx *db.ClusterTx) *met
 		}
 	}
 
+	// BGP peer sessions
+	bgpPeers, err := s.BGP.PeerStatuses()
+	if err != nil {
+		logger.Warn("Failed to get BGP peer status", logger.Ctx{"err": err})
+	} else {
+		for _, peer := range bgpPeers {
+			labels := func() map[string]string {
+				return map[string]string{"peer": peer.Address.String(), "asn": strconv.FormatUint(uint64(peer.ASN), 10)}
+			}
+
+			established := 0.0
+			if peer.State == "established" {
+				established = 1
+			}
+
+			out.AddSamples(metrics.BGPPeerSessionEstablished, metrics.Sample{Value: established, Labels: labels()})
+			out.AddSamples(metrics.BGPPeerUptimeSeconds, metrics.Sample{Value: peer.Uptime.Seconds(), Labels: labels()})
+			out.AddSamples(metrics.BGPPeerPrefixesReceived, metrics.Sample{Value: float64(peer.PrefixesReceived), Labels: labels()})
+			out.AddSamples(metrics.BGPPeerPrefixesAccepted, metrics.Sample{Value: float64(peer.PrefixesAccepted), Labels: labels()})
+			out.AddSamples(metrics.BGPPeerPrefixesAdvertised, metrics.Sample{Value: float64(peer.PrefixesAdvertised), Labels: labels()})
+		}
+	}
+
 	// Daemon uptime
 	out.AddSamples(metrics.UptimeSeconds, metrics.Sample{Value: time.Since(s.StartTime).Seconds()})
 
@@ -14,10 +14,15 @@ import (
 
 	"github.com/gorilla/mux"
 
+	"github.com/canonical/lxd/client"
 	"github.com/canonical/lxd/lxd/auth"
+	lxdCluster "github.com/canonical/lxd/lxd/cluster"
 	"github.com/canonical/lxd/lxd/db"
 	"github.com/canonical/lxd/lxd/db/cluster"
 	"github.com/canonical/lxd/lxd/db/operationtype"
+	"github.com/canonical/lxd/lxd/device/nictype"
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/lxd/lifecycle"
 	"github.com/canonical/lxd/lxd/network"
 	"github.com/canonical/lxd/lxd/operations"
@@ -551,6 +556,16 @@ func projectPut(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
+	// The project has already been updated by the member that received the request, only apply it locally.
+	if isClusterNotification(r) {
+		err = projectApplyNetworkLimits(s, project.Name, project.Config)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		return response.EmptySyncResponse
+	}
+
 	// Validate ETag
 	etag := []any{
 		project.Description,
@@ -792,9 +807,83 @@ func projectChange(s *state.State, project *api.Project, req api.ProjectPut) res
 		return response.SmartError(err)
 	}
 
+	// Apply the changed network limits to the instances of the project on all cluster members.
+	if shared.ValueInSlice("limits.network.ingress", configChanged) || shared.ValueInSlice("limits.network.egress", configChanged) {
+		err = projectApplyNetworkLimits(s, project.Name, req.Config)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		notifier, err := lxdCluster.NewNotifier(s, s.Endpoints.NetworkCert(), s.ServerCert(), lxdCluster.NotifyAll)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		err = notifier(func(member db.NodeInfo, client lxd.InstanceServer) error {
+			return client.UpdateProject(project.Name, req, "")
+		})
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
 	return response.EmptySyncResponse
 }
 
+// projectApplyNetworkLimits applies the aggregate network limits of the project to the NICs of its instances running
+// on this member.
+func projectApplyNetworkLimits(s *state.State, projectName string, config map[string]string) error {
+	ingress, egress, err := limits.NetworkRates(config)
+	if err != nil {
+		return err
+	}
+
+	insts, err := instance.LoadNodeAll(s, instancetype.Any)
+	if err != nil {
+		return fmt.Errorf("Failed loading instances: %w", err)
+	}
+
+	for _, inst := range insts {
+		if inst.Project().Name != projectName || !inst.IsRunning() {
+			continue
+		}
+
+		for devName, devConfig := range inst.ExpandedDevices() {
+			if devConfig["type"] != "nic" {
+				continue
+			}
+
+			nicType, err := nictype.NICType(s, projectName, devConfig)
+			if err != nil {
+				return err
+			}
+
+			// Only the NIC types using a host side veth pair support network limits.
+			if !shared.ValueInSlice(nicType, []string{"bridged", "p2p", "routed"}) {
+				continue
+			}
+
+			hostName := inst.LocalConfig()["volatile."+devName+".host_name"]
+			if hostName == "" {
+				continue
+			}
+
+			err = s.Firewall.InstanceSetupNetLimits(projectName, inst.Name(), hostName, ingress, egress)
+			if err != nil {
+				return fmt.Errorf("Failed applying network limits to device %q of instance %q: %w", devName, inst.Name(), err)
+			}
+		}
+	}
+
+	// Remove the limits of the previous project configuration now that no NIC references them anymore.
+	err = s.Firewall.ProjectClearUnusedNetLimits(projectName, ingress, egress)
+	if err != nil {
+		return fmt.Errorf("Failed clearing unused network limits: %w", err)
+	}
+
+	return nil
+}
+
 // swagger:operation POST /1.0/projects/{name} projects project_post
 //
 //	Rename the project
@@ -1222,6 +1311,26 @@ func projectValidateConfig(s *state.State, config map[string]string, defaultNetw
 		//  type: string
 		//  shortdesc: Maximum disk space used by the project
 		"limits.disk": validate.Optional(validate.IsSize),
+		// lxdmeta:generate(entities=project; group=limits; key=limits.network.egress)
+		// This value is the maximum aggregate bit rate of the traffic sent by all instance NICs of the project on each cluster member, for example, `100Mbit`.
+		// The limit applies to `bridged`, `p2p` and `routed` NICs and requires the `nftables` firewall driver.
+		// See {ref}`project-limits-network` for more information.
+		// ---
+		//  type: string
+		//  shortdesc: Maximum aggregate egress bit rate of the project
+		"limits.network.egress": validate.Optional(func(value string) error {
+			return projectValidateNetworkRate(s, value)
+		}),
+		// lxdmeta:generate(entities=project; group=limits; key=limits.network.ingress)
+		// This value is the maximum aggregate bit rate of the traffic received by all instance NICs of the project on each cluster member, for example, `100Mbit`.
+		// The limit applies to `bridged`, `p2p` and `routed` NICs and requires the `nftables` firewall driver.
+		// See {ref}`project-limits-network` for more information.
+		// ---
+		//  type: string
+		//  shortdesc: Maximum aggregate ingress bit rate of the project
+		"limits.network.ingress": validate.Optional(func(value string) error {
+			return projectValidateNetworkRate(s, value)
+		}),
 		// lxdmeta:generate(entities=project; group=limits; key=limits.networks)
 		//
 		// ---
@@ -1450,6 +1559,15 @@ func projectValidateConfig(s *state.State, config map[string]string, defaultNetw
 		//  defaultdesc: `block`
 		//  shortdesc: Whether to prevent creating instance or volume snapshots
 		"restricted.snapshots": isEitherAllowOrBlock,
+		// lxdmeta:generate(entities=project; group=specific; key=security.exec.record)
+		// When enabled, the input and output of `exec` and `console` sessions of the instances in the project are recorded.
+		// Instances can't disable recording when it is enabled for the project, but can enable it with their {config:option}`instance-security:security.exec.record` option.
+		// See {ref}`run-commands-record` for more information.
+		// ---
+		//  type: bool
+		//  defaultdesc: `false`
+		//  shortdesc: Whether to record `exec` and `console` sessions of the project's instances
+		"security.exec.record": validate.Optional(validate.IsBool),
 	}
 
 	// Add the storage pool keys.
@@ -1585,6 +1703,20 @@ func projectValidateName(name string) error {
 	return nil
 }
 
+// projectValidateNetworkRate validates a project network rate limit and checks that the firewall driver supports it.
+func projectValidateNetworkRate(s *state.State, value string) error {
+	err := limits.ValidateNetworkRate(value)
+	if err != nil {
+		return err
+	}
+
+	if s.Firewall.String() == "xtables" {
+		return fmt.Errorf("Project network limits are not supported by the xtables firewall driver")
+	}
+
+	return nil
+}
+
 // projectValidateRestrictedSubnets checks that the project's restricted.networks.subnets are properly formatted
 // and are within the specified uplink network's routes.
 func projectValidateRestrictedSubnets(s *state.State, value string) error {
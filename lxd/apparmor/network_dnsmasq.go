@@ -33,6 +33,9 @@ profile "{{ .name }}" flags=(attach_disconnected,mediate_deleted) {
 
   # Network-specific paths
   {{ .varPath }}/networks/{{ .networkName }}/dnsmasq.hosts/{,*} r,
+  {{ .varPath }}/networks/{{ .networkName }}/dnsmasq.reservations/{,*} r,
+  {{ .varPath }}/networks/{{ .networkName }}/dnsmasq.reservations.options/{,*} r,
+  {{ .varPath }}/networks/{{ .networkName }}/dnsmasq.options/{,*} r,
   {{ .varPath }}/networks/{{ .networkName }}/dnsmasq.leases rw,
   {{ .varPath }}/networks/{{ .networkName }}/dnsmasq.raw r,
 
@@ -5,6 +5,7 @@ type DebugInfo struct {
 	Server   DebugInfoServer   `json:"server" yaml:"server"`
 	Prefixes []DebugInfoPrefix `json:"prefixes" yaml:"prefixes"`
 	Peers    []DebugInfoPeer   `json:"peers" yaml:"peers"`
+	Routes   []DebugInfoRoute  `json:"routes" yaml:"routes"`
 }
 
 // DebugInfoServer exposes the shared listener configuration.
@@ -31,6 +32,13 @@ type DebugInfoPeer struct {
 	HoldTime uint64 `json:"holdtime" yaml:"holdtime"`
 }
 
+// DebugInfoRoute exposes details on a single route learned from a BGP peer.
+type DebugInfoRoute struct {
+	Prefix  string `json:"prefix" yaml:"prefix"`
+	Nexthop string `json:"nexthop" yaml:"nexthop"`
+	Peer    string `json:"peer" yaml:"peer"`
+}
+
 // Debug returns a dump of the current configuration.
 func (s *Server) Debug() DebugInfo {
 	// Locking.
@@ -73,5 +81,16 @@ func (s *Server) debug() DebugInfo {
 		debug.Prefixes = append(debug.Prefixes, entry)
 	}
 
+	// Fill in the learned routes.
+	debug.Routes = []DebugInfoRoute{}
+	for _, route := range s.routes {
+		entry := DebugInfoRoute{}
+		entry.Prefix = route.Prefix.String()
+		entry.Nexthop = route.Nexthop.String()
+		entry.Peer = route.Peer.String()
+
+		debug.Routes = append(debug.Routes, entry)
+	}
+
 	return debug
 }
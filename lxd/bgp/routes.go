@@ -0,0 +1,250 @@
+package bgp
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	bgpAPI "github.com/osrg/gobgp/v3/api"
+
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// Route represents a route learned from a BGP peer.
+type Route struct {
+	Prefix  net.IPNet
+	Nexthop net.IP
+	Peer    net.IP
+}
+
+// RouteHandler is called with the full list of routes currently learned from the peers of a route importer.
+type RouteHandler func(routes []Route)
+
+type importer struct {
+	peers   []string
+	handler RouteHandler
+}
+
+// watchRoutes subscribes to the best path changes so that the routes learned from the peers can be imported.
+func (s *Server) watchRoutes() {
+	req := &bgpAPI.WatchEventRequest{
+		Table: &bgpAPI.WatchEventRequest_Table{
+			Filters: []*bgpAPI.WatchEventRequest_Table_Filter{
+				{
+					Type: bgpAPI.WatchEventRequest_Table_Filter_BEST,
+					Init: true,
+				},
+			},
+		},
+	}
+
+	err := s.bgp.WatchEvent(context.Background(), req, s.handleTableEvent)
+	if err != nil {
+		logger.Warn("Unable to watch BGP routes", logger.Ctx{"err": err})
+	}
+}
+
+// handleTableEvent records the best path changes and notifies the affected route importers.
+func (s *Server) handleTableEvent(r *bgpAPI.WatchEventResponse) {
+	table := r.GetTable()
+	if table == nil {
+		return
+	}
+
+	// Serialize the calls to the route handlers.
+	s.importMu.Lock()
+	defer s.importMu.Unlock()
+
+	s.mu.Lock()
+
+	changedPeers := map[string]bool{}
+	for _, p := range table.Paths {
+		prefix, err := pathPrefix(p)
+		if err != nil {
+			continue // Skip families other than IPv4 and IPv6 unicast.
+		}
+
+		// Any previous best path for the prefix is replaced.
+		oldRoute, found := s.routes[prefix.String()]
+		if found {
+			changedPeers[oldRoute.Peer.String()] = true
+			delete(s.routes, prefix.String())
+		}
+
+		// Locally originated paths have no neighbor address.
+		peerAddress := net.ParseIP(p.NeighborIp)
+		if p.IsWithdraw || peerAddress == nil {
+			continue
+		}
+
+		nexthop := pathNexthop(p)
+		if nexthop == nil {
+			continue
+		}
+
+		s.routes[prefix.String()] = Route{
+			Prefix:  *prefix,
+			Nexthop: nexthop,
+			Peer:    peerAddress,
+		}
+
+		changedPeers[peerAddress.String()] = true
+	}
+
+	notify := []func(){}
+	for _, imp := range s.importers {
+		for _, peerAddress := range imp.peers {
+			if changedPeers[peerAddress] {
+				routes := s.importerRoutes(imp.peers)
+				handler := imp.handler
+				notify = append(notify, func() { handler(routes) })
+				break
+			}
+		}
+	}
+
+	s.mu.Unlock()
+
+	for _, fn := range notify {
+		fn()
+	}
+}
+
+// importerRoutes returns the routes currently learned from the specified peers.
+func (s *Server) importerRoutes(peers []string) []Route {
+	routes := []Route{}
+	for _, route := range s.routes {
+		for _, peerAddress := range peers {
+			if route.Peer.String() == peerAddress {
+				routes = append(routes, route)
+				break
+			}
+		}
+	}
+
+	return routes
+}
+
+// AddRouteImporter registers a handler that is called with the routes learned from the specified peers every time
+// they change. The handler is called straight away with the routes already learned.
+// Any existing route importer of the owner is removed first.
+func (s *Server) AddRouteImporter(owner string, peers []net.IP, handler RouteHandler) {
+	s.importMu.Lock()
+	defer s.importMu.Unlock()
+
+	s.removeRouteImporter(owner)
+
+	peerAddresses := make([]string, 0, len(peers))
+	for _, peerAddress := range peers {
+		peerAddresses = append(peerAddresses, peerAddress.String())
+	}
+
+	s.mu.Lock()
+	s.importers[owner] = importer{
+		peers:   peerAddresses,
+		handler: handler,
+	}
+
+	routes := s.importerRoutes(peerAddresses)
+	s.mu.Unlock()
+
+	handler(routes)
+}
+
+// RemoveRouteImporter removes the route importer of the owner.
+// Its handler is called one last time without any routes so that the imported routes can be removed.
+func (s *Server) RemoveRouteImporter(owner string) {
+	s.importMu.Lock()
+	defer s.importMu.Unlock()
+
+	s.removeRouteImporter(owner)
+}
+
+// HasRouteImporter returns whether the owner has a route importer.
+func (s *Server) HasRouteImporter(owner string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, found := s.importers[owner]
+
+	return found
+}
+
+// ForgetRouteImporter removes the route importer of the owner without calling its handler.
+// This is used when the imported routes are handed over to another cluster member.
+func (s *Server) ForgetRouteImporter(owner string) {
+	s.importMu.Lock()
+	defer s.importMu.Unlock()
+
+	s.mu.Lock()
+	delete(s.importers, owner)
+	s.mu.Unlock()
+}
+
+// notifyImporters calls the handler of every route importer with the routes currently learned from its peers.
+func (s *Server) notifyImporters() {
+	s.importMu.Lock()
+	defer s.importMu.Unlock()
+
+	s.mu.Lock()
+	notify := make([]func(), 0, len(s.importers))
+	for _, imp := range s.importers {
+		routes := s.importerRoutes(imp.peers)
+		handler := imp.handler
+		notify = append(notify, func() { handler(routes) })
+	}
+
+	s.mu.Unlock()
+
+	for _, fn := range notify {
+		fn()
+	}
+}
+
+func (s *Server) removeRouteImporter(owner string) {
+	s.mu.Lock()
+	imp, found := s.importers[owner]
+	delete(s.importers, owner)
+	s.mu.Unlock()
+
+	if found {
+		imp.handler(nil)
+	}
+}
+
+// pathPrefix returns the prefix of an IPv4 or IPv6 unicast path.
+func pathPrefix(p *bgpAPI.Path) (*net.IPNet, error) {
+	if p.GetNlri() == nil {
+		return nil, fmt.Errorf("Path has no NLRI")
+	}
+
+	nlri := &bgpAPI.IPAddressPrefix{}
+	err := p.Nlri.UnmarshalTo(nlri)
+	if err != nil {
+		return nil, err
+	}
+
+	_, prefix, err := net.ParseCIDR(fmt.Sprintf("%s/%d", nlri.Prefix, nlri.PrefixLen))
+	if err != nil {
+		return nil, err
+	}
+
+	return prefix, nil
+}
+
+// pathNexthop returns the next hop of an IPv4 or IPv6 unicast path.
+func pathNexthop(p *bgpAPI.Path) net.IP {
+	for _, attr := range p.Pattrs {
+		nextHopAttr := &bgpAPI.NextHopAttribute{}
+		if attr.UnmarshalTo(nextHopAttr) == nil {
+			return net.ParseIP(nextHopAttr.NextHop)
+		}
+
+		mpReachAttr := &bgpAPI.MpReachNLRIAttribute{}
+		if attr.UnmarshalTo(mpReachAttr) == nil && len(mpReachAttr.NextHops) > 0 {
+			return net.ParseIP(mpReachAttr.NextHops[0])
+		}
+	}
+
+	return nil
+}
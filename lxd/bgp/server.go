@@ -28,7 +28,12 @@ type Server struct {
 	paths    map[string]path
 	peers    map[string]peer
 
-	mu sync.Mutex
+	// Routes learned from the peers and their importers.
+	routes    map[string]Route
+	importers map[string]importer
+
+	mu       sync.Mutex
+	importMu sync.Mutex
 }
 
 type path struct {
@@ -49,8 +54,10 @@ type peer struct {
 func NewServer() *Server {
 	// Setup new struct.
 	s := &Server{
-		paths: map[string]path{},
-		peers: map[string]peer{},
+		paths:     map[string]path{},
+		peers:     map[string]peer{},
+		routes:    map[string]Route{},
+		importers: map[string]importer{},
 	}
 
 	return s
@@ -65,6 +72,9 @@ func (s *Server) setup() {
 	s.bgp = bgpServer.NewBgpServer()
 	go s.bgp.Serve()
 
+	// Keep track of the routes learned from the peers.
+	s.watchRoutes()
+
 	// Insert any path that's already defined.
 	if len(s.paths) > 0 {
 		// Reset the path list.
@@ -180,6 +190,14 @@ func (s *Server) stop() error {
 	s.address = ""
 	s.asn = 0
 	s.routerID = nil
+
+	// The learned routes are gone along with the listener, so let the importers remove them.
+	// This runs asynchronously as the route handlers must not be called with the lock held.
+	if len(s.routes) > 0 {
+		s.routes = map[string]Route{}
+		go s.notifyImporters()
+	}
+
 	return nil
 }
 
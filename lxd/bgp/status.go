@@ -0,0 +1,78 @@
+package bgp
+
+import (
+	"context"
+	"net"
+	"strings"
+	"time"
+
+	bgpAPI "github.com/osrg/gobgp/v3/api"
+)
+
+// PeerStatus represents the session status of a BGP peer.
+type PeerStatus struct {
+	Address            net.IP
+	ASN                uint32
+	State              string
+	Uptime             time.Duration
+	PrefixesReceived   uint64
+	PrefixesAccepted   uint64
+	PrefixesAdvertised uint64
+}
+
+// PeerStatuses returns the session status of all the configured peers.
+func (s *Server) PeerStatuses() ([]PeerStatus, error) {
+	// Locking.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make(map[string]*PeerStatus, len(s.peers))
+	for peerAddress, peer := range s.peers {
+		statuses[peerAddress] = &PeerStatus{
+			Address: peer.address,
+			ASN:     peer.asn,
+			State:   "idle",
+		}
+	}
+
+	// Only the running server has session information.
+	if s.bgp != nil && s.address != "" {
+		err := s.bgp.ListPeer(context.Background(), &bgpAPI.ListPeerRequest{EnableAdvertised: true}, func(p *bgpAPI.Peer) {
+			if p.GetState() == nil || p.GetConf() == nil {
+				return
+			}
+
+			status, found := statuses[net.ParseIP(p.Conf.NeighborAddress).String()]
+			if !found {
+				return
+			}
+
+			status.State = strings.ToLower(p.State.SessionState.String())
+
+			uptime := p.GetTimers().GetState().GetUptime()
+			if p.State.SessionState == bgpAPI.PeerState_ESTABLISHED && uptime != nil {
+				status.Uptime = time.Since(uptime.AsTime()).Truncate(time.Second)
+			}
+
+			for _, afiSafi := range p.AfiSafis {
+				if afiSafi.GetState() == nil {
+					continue
+				}
+
+				status.PrefixesReceived += afiSafi.State.Received
+				status.PrefixesAccepted += afiSafi.State.Accepted
+				status.PrefixesAdvertised += afiSafi.State.Advertised
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result := make([]PeerStatus, 0, len(statuses))
+	for _, status := range statuses {
+		result = append(result, *status)
+	}
+
+	return result, nil
+}
@@ -248,6 +248,35 @@ func (c *Config) ClusterHealingThreshold() time.Duration {
 	return healingThreshold
 }
 
+// ClusterRebalanceInterval returns the interval between two automatic rebalancing runs of the cluster.
+// If this feature is disabled, it returns 0.
+func (c *Config) ClusterRebalanceInterval() time.Duration {
+	return time.Duration(c.m.GetInt64("cluster.rebalance.interval")) * time.Minute
+}
+
+// ClusterRebalanceThreshold returns the difference in load, in percent, between the busiest and the least
+// busy cluster members above which instances are moved.
+func (c *Config) ClusterRebalanceThreshold() int64 {
+	return c.m.GetInt64("cluster.rebalance.threshold")
+}
+
+// ClusterRebalanceBatch returns the maximum number of instances moved by a single rebalancing run.
+func (c *Config) ClusterRebalanceBatch() int64 {
+	return c.m.GetInt64("cluster.rebalance.batch")
+}
+
+// ClusterRebalanceCooldown returns the minimum time to wait before moving an instance again.
+func (c *Config) ClusterRebalanceCooldown() string {
+	return c.m.GetString("cluster.rebalance.cooldown")
+}
+
+// ClusterRebalanceWindow returns the start and end of the daily window during which instances can be moved,
+// as offsets from midnight. Both are 0 if instances can be moved at any time.
+func (c *Config) ClusterRebalanceWindow() (start time.Duration, end time.Duration) {
+	start, end, _ = parseRebalanceWindow(c.m.GetString("cluster.rebalance.window"))
+	return start, end
+}
+
 // Dump current configuration keys and their values. Keys with values matching
 // their defaults are omitted.
 func (c *Config) Dump() map[string]any {
@@ -389,6 +418,55 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: Number of database stand-by members
 	"cluster.max_standby": {Type: config.Int64, Default: "2", Validator: maxStandByValidator},
 
+	// lxdmeta:generate(entities=server; group=cluster; key=cluster.rebalance.interval)
+	// Specify the number of minutes between two checks of the load of the cluster members.
+	// When the load is unbalanced, LXD moves instances from the busiest member to the least busy one.
+	// To disable automatic rebalancing, set this option to `0`.
+	// See {ref}`cluster-rebalance` for more information.
+	// ---
+	//  type: integer
+	//  scope: global
+	//  defaultdesc: `0`
+	//  shortdesc: Interval in minutes between automatic rebalancing checks
+	"cluster.rebalance.interval": {Type: config.Int64, Default: "0", Validator: validate.Optional(validate.IsInRange(0, 10080))},
+
+	// lxdmeta:generate(entities=server; group=cluster; key=cluster.rebalance.threshold)
+	// Instances are only moved if the load of the busiest cluster member exceeds the load of the least busy member by this many percentage points.
+	// ---
+	//  type: integer
+	//  scope: global
+	//  defaultdesc: `20`
+	//  shortdesc: Load difference in percent that triggers rebalancing
+	"cluster.rebalance.threshold": {Type: config.Int64, Default: "20", Validator: validate.Optional(validate.IsInRange(1, 100))},
+
+	// lxdmeta:generate(entities=server; group=cluster; key=cluster.rebalance.batch)
+	// Specify the maximum number of instances that are moved during a single rebalancing check.
+	// ---
+	//  type: integer
+	//  scope: global
+	//  defaultdesc: `1`
+	//  shortdesc: Maximum number of instances moved per rebalancing check
+	"cluster.rebalance.batch": {Type: config.Int64, Default: "1", Validator: validate.Optional(validate.IsInRange(1, 100))},
+
+	// lxdmeta:generate(entities=server; group=cluster; key=cluster.rebalance.cooldown)
+	// Specify the minimum time to wait before an instance is moved again by rebalancing.
+	// The value is a number followed by a unit, for example, `6H` or `2d`.
+	// ---
+	//  type: string
+	//  scope: global
+	//  defaultdesc: `6H`
+	//  shortdesc: Minimum time between two moves of the same instance
+	"cluster.rebalance.cooldown": {Type: config.String, Default: "6H", Validator: expiryValidator},
+
+	// lxdmeta:generate(entities=server; group=cluster; key=cluster.rebalance.window)
+	// Specify the daily window, in local time of the cluster leader, during which rebalancing can move instances, for example, `01:00-05:00`.
+	// The window can span midnight. If not set, instances can be moved at any time.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Daily window during which instances can be moved
+	"cluster.rebalance.window": {Type: config.String, Validator: rebalanceWindowValidator},
+
 	// lxdmeta:generate(entities=server; group=core; key=core.metrics_authentication)
 	//
 	// ---
@@ -632,13 +710,13 @@ var ConfigSchema = config.Schema{
 
 	// lxdmeta:generate(entities=server; group=loki; key=loki.types)
 	// Specify a comma-separated list of events to send to the Loki server.
-	// The events can be any combination of `lifecycle`, `logging`, and `ovn`.
+	// The events can be any combination of `lifecycle`, `logging`, `ovn`, and `network-flow`.
 	// ---
 	//  type: string
 	//  scope: global
 	//  defaultdesc: `lifecycle,logging`
 	//  shortdesc: Events to send to the Loki server
-	"loki.types": {Validator: validate.Optional(validate.IsListOf(validate.IsOneOf("lifecycle", "logging", "ovn"))), Default: "lifecycle,logging"},
+	"loki.types": {Validator: validate.Optional(validate.IsListOf(validate.IsOneOf("lifecycle", "logging", "ovn", "network-flow"))), Default: "lifecycle,logging"},
 
 	// lxdmeta:generate(entities=server; group=miscellaneous; key=maas.api.key)
 	//
@@ -768,6 +846,42 @@ func expiryValidator(value string) error {
 	return nil
 }
 
+// parseRebalanceWindow parses a daily window in the HH:MM-HH:MM format and returns its start and end
+// as offsets from midnight.
+func parseRebalanceWindow(value string) (start time.Duration, end time.Duration, err error) {
+	if value == "" {
+		return 0, 0, nil
+	}
+
+	startValue, endValue, found := strings.Cut(value, "-")
+	if !found {
+		return 0, 0, fmt.Errorf("Window must be in the HH:MM-HH:MM format")
+	}
+
+	for _, part := range []struct {
+		value  string
+		offset *time.Duration
+	}{{startValue, &start}, {endValue, &end}} {
+		t, err := time.Parse("15:04", strings.TrimSpace(part.value))
+		if err != nil {
+			return 0, 0, fmt.Errorf("Invalid time %q in window: %w", part.value, err)
+		}
+
+		*part.offset = time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	}
+
+	if start == end {
+		return 0, 0, fmt.Errorf("Window start and end must be different")
+	}
+
+	return start, end, nil
+}
+
+func rebalanceWindowValidator(value string) error {
+	_, _, err := parseRebalanceWindow(value)
+	return err
+}
+
 func logLevelValidator(value string) error {
 	if value == "" {
 		return nil
@@ -3,6 +3,7 @@ package config_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -77,6 +78,28 @@ func TestConfigLoad_MaxVotersValidator(t *testing.T) {
 	require.EqualError(t, err, `Cannot set "cluster.max_voters" to "4": Value must be an odd number equal to or higher than 3`)
 }
 
+// Rebalancing window must be a valid daily time range.
+func TestConfigLoad_RebalanceWindowValidator(t *testing.T) {
+	tx, cleanup := db.NewTestClusterTx(t)
+	defer cleanup()
+
+	config, err := clusterConfig.Load(context.Background(), tx)
+	require.NoError(t, err)
+
+	_, err = config.Patch(map[string]any{"cluster.rebalance.window": "22:00-04:30"})
+	require.NoError(t, err)
+
+	start, end := config.ClusterRebalanceWindow()
+	assert.Equal(t, 22*time.Hour, start)
+	assert.Equal(t, 4*time.Hour+30*time.Minute, end)
+
+	_, err = config.Patch(map[string]any{"cluster.rebalance.window": "22:00"})
+	require.EqualError(t, err, `Cannot set "cluster.rebalance.window" to "22:00": Window must be in the HH:MM-HH:MM format`)
+
+	_, err = config.Patch(map[string]any{"cluster.rebalance.window": "04:00-04:00"})
+	require.EqualError(t, err, `Cannot set "cluster.rebalance.window" to "04:00-04:00": Window start and end must be different`)
+}
+
 // If some previously set values are missing from the ones passed to Replace(),
 // they are deleted from the configuration.
 func TestConfig_ReplaceDeleteValues(t *testing.T) {
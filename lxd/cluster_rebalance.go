@@ -0,0 +1,473 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/canonical/lxd/lxd/cluster"
+	"github.com/canonical/lxd/lxd/db"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/db/operationtype"
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/operations"
+	"github.com/canonical/lxd/lxd/project/limits"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/task"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/revert"
+)
+
+// clusterRebalanceMember holds the load of a cluster member as seen by the rebalancing task.
+type clusterRebalanceMember struct {
+	info      db.NodeInfo
+	load      float64 // Average of the memory and CPU usage, in percent.
+	totalRAM  uint64
+	instances int
+}
+
+// clusterRebalanceLoad returns the load of a cluster member, in percent, as the average of its memory usage and of
+// its CPU usage (load average over the last minute divided by the number of logical CPUs).
+func clusterRebalanceLoad(sysInfo api.ClusterMemberSysInfo) float64 {
+	var memory float64
+	if sysInfo.TotalRAM > 0 {
+		memory = float64(sysInfo.TotalRAM-min(sysInfo.TotalRAM, sysInfo.FreeRAM+sysInfo.BufferRAM)) * 100 / float64(sysInfo.TotalRAM)
+	}
+
+	var cpu float64
+	if sysInfo.LogicalCPUs > 0 && len(sysInfo.LoadAverages) > 0 {
+		cpu = min(sysInfo.LoadAverages[0]*100/float64(sysInfo.LogicalCPUs), 100)
+	}
+
+	return (memory + cpu) / 2
+}
+
+// clusterRebalanceMemoryLoad returns the share of the load of a cluster member that is due to the given memory usage.
+func clusterRebalanceMemoryLoad(usage float64, totalRAM uint64) float64 {
+	if totalRAM == 0 {
+		return 0
+	}
+
+	return usage * 100 / float64(totalRAM) / 2
+}
+
+// clusterRebalanceInWindow returns whether the given time is within the daily rebalancing window.
+// The window can span midnight, and a window whose start and end are both 0 includes all times.
+func clusterRebalanceInWindow(start time.Duration, end time.Duration, now time.Time) bool {
+	if start == end {
+		return true
+	}
+
+	offset := time.Duration(now.Hour())*time.Hour + time.Duration(now.Minute())*time.Minute
+	if start < end {
+		return offset >= start && offset < end
+	}
+
+	return offset >= start || offset < end
+}
+
+func autoRebalanceClusterTask(d *Daemon) (task.Func, task.Schedule) {
+	var lastRun time.Time
+
+	f := func(ctx context.Context) {
+		s := d.State()
+		interval := s.GlobalConfig.ClusterRebalanceInterval()
+		if interval == 0 {
+			return // Skip rebalancing if it's disabled.
+		}
+
+		if time.Since(lastRun) < interval {
+			return
+		}
+
+		start, end := s.GlobalConfig.ClusterRebalanceWindow()
+		if !clusterRebalanceInWindow(start, end, time.Now()) {
+			return // Skip rebalancing outside of the maintenance window.
+		}
+
+		leaderInfo, err := s.LeaderInfo()
+		if err != nil {
+			logger.Error("Failed to determine cluster leader", logger.Ctx{"err": err})
+			return
+		}
+
+		if !leaderInfo.Clustered || !leaderInfo.Leader {
+			return // Skip rebalancing if not cluster leader.
+		}
+
+		lastRun = time.Now()
+
+		opRun := func(op *operations.Operation) error {
+			err := autoRebalanceCluster(ctx, s, op)
+			if err != nil {
+				logger.Error("Failed rebalancing cluster instances", logger.Ctx{"err": err})
+				return err
+			}
+
+			return nil
+		}
+
+		op, err := operations.OperationCreate(s, "", operations.OperationClassTask, operationtype.ClusterRebalance, nil, nil, opRun, nil, nil, nil)
+		if err != nil {
+			logger.Error("Failed creating cluster rebalance operation", logger.Ctx{"err": err})
+			return
+		}
+
+		err = op.Start()
+		if err != nil {
+			logger.Error("Failed starting cluster rebalance operation", logger.Ctx{"err": err})
+			return
+		}
+
+		err = op.Wait(ctx)
+		if err != nil {
+			logger.Error("Failed rebalancing cluster instances", logger.Ctx{"err": err})
+			return
+		}
+	}
+
+	return f, task.Every(time.Minute)
+}
+
+// clusterRebalanceMembers returns the online cluster members along with their current load, busiest first.
+func clusterRebalanceMembers(ctx context.Context, s *state.State) ([]*clusterRebalanceMember, error) {
+	var members []*clusterRebalanceMember
+
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		nodes, err := tx.GetNodes(ctx)
+		if err != nil {
+			return fmt.Errorf("Failed getting cluster members: %w", err)
+		}
+
+		instances, err := dbCluster.GetInstances(ctx, tx.Tx())
+		if err != nil {
+			return fmt.Errorf("Failed getting instances: %w", err)
+		}
+
+		counts := make(map[string]int, len(nodes))
+		for _, inst := range instances {
+			counts[inst.Node]++
+		}
+
+		for _, node := range nodes {
+			// Skip pending, evacuated and offline members.
+			if node.State != db.ClusterMemberStateCreated || node.IsOffline(s.GlobalConfig.OfflineThreshold()) {
+				continue
+			}
+
+			members = append(members, &clusterRebalanceMember{info: node, instances: counts[node.Name]})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := cluster.Connect(s.LocalConfig.ClusterAddress(), s.Endpoints.NetworkCert(), s.ServerCert(), nil, true)
+	if err != nil {
+		return nil, err
+	}
+
+	loaded := make([]*clusterRebalanceMember, 0, len(members))
+	for _, member := range members {
+		memberState, _, err := client.GetClusterMemberState(member.info.Name)
+		if err != nil {
+			logger.Warn("Failed getting cluster member state, skipping it for rebalancing", logger.Ctx{"member": member.info.Name, "err": err})
+			continue
+		}
+
+		member.load = clusterRebalanceLoad(memberState.SysInfo)
+		member.totalRAM = memberState.SysInfo.TotalRAM
+		loaded = append(loaded, member)
+	}
+
+	slices.SortFunc(loaded, func(a *clusterRebalanceMember, b *clusterRebalanceMember) int {
+		return cmp.Or(cmp.Compare(b.load, a.load), cmp.Compare(b.instances, a.instances))
+	})
+
+	return loaded, nil
+}
+
+// autoRebalanceCluster moves running instances from the busiest cluster member to the least busy ones, as long as
+// their difference in load exceeds cluster.rebalance.threshold and within the limit of cluster.rebalance.batch.
+// The instances that fail to be moved are skipped.
+func autoRebalanceCluster(ctx context.Context, s *state.State, op *operations.Operation) error {
+	members, err := clusterRebalanceMembers(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	if len(members) < 2 {
+		return nil
+	}
+
+	threshold := float64(s.GlobalConfig.ClusterRebalanceThreshold())
+	source := members[0]
+	if source.load-members[len(members)-1].load < threshold {
+		return nil // Nothing to do if the cluster is balanced.
+	}
+
+	logger.Info("Rebalancing cluster instances", logger.Ctx{"member": source.info.Name, "load": strconv.FormatFloat(source.load, 'f', 1, 64)})
+
+	// Get the running instances of the busiest member along with their memory usage.
+	src, err := cluster.Connect(source.info.Address, s.Endpoints.NetworkCert(), s.ServerCert(), nil, true)
+	if err != nil {
+		return err
+	}
+
+	srcInstances, err := src.GetInstancesFullAllProjects(api.InstanceTypeAny)
+	if err != nil {
+		return fmt.Errorf("Failed getting instances of cluster member %q: %w", source.info.Name, err)
+	}
+
+	srcInstances = slices.DeleteFunc(srcInstances, func(inst api.InstanceFull) bool {
+		return inst.StatusCode != api.Running || inst.State == nil
+	})
+
+	// Move the instances that use the most memory first.
+	slices.SortFunc(srcInstances, func(a api.InstanceFull, b api.InstanceFull) int {
+		return cmp.Compare(b.State.Memory.Usage, a.State.Memory.Usage)
+	})
+
+	cooldown := s.GlobalConfig.ClusterRebalanceCooldown()
+	budget := s.GlobalConfig.ClusterRebalanceBatch()
+	metadata := make(map[string]any)
+
+	for _, srcInst := range srcInstances {
+		if budget <= 0 {
+			break
+		}
+
+		l := logger.AddContext(logger.Ctx{"project": srcInst.Project, "instance": srcInst.Name})
+
+		inst, err := instance.LoadByProjectAndName(s, srcInst.Project, srcInst.Name)
+		if err != nil {
+			l.Warn("Failed to load instance, skipping it for rebalancing", logger.Ctx{"err": err})
+			continue
+		}
+
+		// Respect the cluster.evacuate policy of the instance.
+		migrate, live := inst.CanMigrate()
+		if !migrate {
+			continue
+		}
+
+		// Don't move the same instance back and forth.
+		lastMove, err := time.Parse(time.RFC3339, inst.LocalConfig()["volatile.rebalance.last_move"])
+		if err == nil {
+			nextMove, err := shared.GetExpiry(lastMove, cooldown)
+			if err == nil && time.Now().Before(nextMove) {
+				continue
+			}
+		}
+
+		target, err := clusterRebalanceSelectTarget(ctx, s, inst, source, members[1:], threshold)
+		if err != nil {
+			l.Warn("Failed selecting rebalancing target for instance", logger.Ctx{"err": err})
+			continue
+		}
+
+		if target == nil {
+			l.Debug("No rebalancing target available for instance")
+			continue
+		}
+
+		// Account for the memory of the instance until the next check measures the actual load, and skip the
+		// instances that would make the target busier than the source.
+		usage := float64(max(srcInst.State.Memory.Usage, 0))
+		sourceLoad := source.load - clusterRebalanceMemoryLoad(usage, source.totalRAM)
+		targetLoad := target.load + clusterRebalanceMemoryLoad(usage, target.totalRAM)
+		if targetLoad > sourceLoad {
+			continue
+		}
+
+		metadata["rebalance_progress"] = fmt.Sprintf("Migrating %q in project %q to %q", inst.Name(), srcInst.Project, target.info.Name)
+		_ = op.UpdateMetadata(metadata)
+
+		err = inst.VolatileSet(map[string]string{"volatile.rebalance.last_move": time.Now().UTC().Format(time.RFC3339)})
+		if err != nil {
+			l.Warn("Failed recording instance rebalancing", logger.Ctx{"err": err})
+			continue
+		}
+
+		err = clusterRebalanceMigrate(s, inst, source.info, target.info, live)
+		if err != nil {
+			l.Warn("Failed rebalancing instance", logger.Ctx{"target": target.info.Name, "err": err})
+			continue
+		}
+
+		budget--
+		source.load = sourceLoad
+		target.load = targetLoad
+		source.instances--
+		target.instances++
+	}
+
+	logger.Info("Done rebalancing cluster instances")
+
+	return nil
+}
+
+// clusterRebalanceSelectTarget returns the least busy cluster member that the instance can be moved to, or nil if
+// no member is less busy than the source by at least the threshold.
+// The placement group of the instance and the cluster groups allowed by its project are respected.
+func clusterRebalanceSelectTarget(ctx context.Context, s *state.State, inst instance.Instance, source *clusterRebalanceMember, members []*clusterRebalanceMember, threshold float64) (*clusterRebalanceMember, error) {
+	var candidateMembers []db.NodeInfo
+
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		allMembers := clusterRebalanceEligibleMembers(source, members, threshold)
+
+		instProject := inst.Project()
+		clusterGroupsAllowed := limits.GetRestrictedClusterGroups(&instProject)
+
+		var err error
+		candidateMembers, err = tx.GetCandidateMembers(ctx, allMembers, []int{inst.Architecture()}, "", clusterGroupsAllowed, s.GlobalConfig.OfflineThreshold())
+		if err != nil {
+			return err
+		}
+
+		// Only keep the members that satisfy the placement group of the instance.
+		candidateMembers, err = placementGroupCandidates(ctx, tx, instProject.Name, inst.Name(), inst.ExpandedConfig(), candidateMembers)
+		if err != nil {
+			if api.StatusErrorCheck(err, http.StatusNotFound) {
+				return nil // The instance can't be moved without breaking its placement group.
+			}
+
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return clusterRebalancePickTarget(members, candidateMembers), nil
+}
+
+// clusterRebalanceEligibleMembers returns the cluster members that are less busy than the source by at least the
+// threshold.
+func clusterRebalanceEligibleMembers(source *clusterRebalanceMember, members []*clusterRebalanceMember, threshold float64) []db.NodeInfo {
+	eligible := make([]db.NodeInfo, 0, len(members))
+	for _, member := range members {
+		if source.load-member.load >= threshold {
+			eligible = append(eligible, member.info)
+		}
+	}
+
+	return eligible
+}
+
+// clusterRebalancePickTarget returns the least busy of the members that are candidates, preferring the members with
+// the fewest instances on equal load, or nil if none of them is a candidate.
+func clusterRebalancePickTarget(members []*clusterRebalanceMember, candidateMembers []db.NodeInfo) *clusterRebalanceMember {
+	var target *clusterRebalanceMember
+	for _, member := range members {
+		if !slices.ContainsFunc(candidateMembers, func(candidate db.NodeInfo) bool { return candidate.Name == member.info.Name }) {
+			continue
+		}
+
+		if target == nil || member.load < target.load || (member.load == target.load && member.instances < target.instances) {
+			target = member
+		}
+	}
+
+	return target
+}
+
+// clusterRebalanceMigrate moves a running instance to the target cluster member. Instances that can't be
+// live-migrated are stopped, moved and started again on the target.
+func clusterRebalanceMigrate(s *state.State, inst instance.Instance, source db.NodeInfo, target db.NodeInfo, live bool) error {
+	projectName := inst.Project().Name
+
+	src, err := cluster.Connect(source.Address, s.Endpoints.NetworkCert(), s.ServerCert(), nil, true)
+	if err != nil {
+		return err
+	}
+
+	src = src.UseProject(projectName)
+
+	dest, err := cluster.Connect(target.Address, s.Endpoints.NetworkCert(), s.ServerCert(), nil, true)
+	if err != nil {
+		return err
+	}
+
+	dest = dest.UseProject(projectName)
+	dest = dest.UseTarget(target.Name)
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	if !live {
+		timeout := evacuateHostShutdownDefaultTimeout
+		value := inst.ExpandedConfig()["boot.host_shutdown_timeout"]
+		if value != "" {
+			timeout, err = strconv.Atoi(value)
+			if err != nil {
+				timeout = evacuateHostShutdownDefaultTimeout
+			}
+		}
+
+		stopOp, err := src.UpdateInstanceState(inst.Name(), api.InstanceStatePut{Action: "stop", Timeout: timeout}, "")
+		if err != nil {
+			return err
+		}
+
+		err = stopOp.Wait()
+		if err != nil {
+			// Fallback to forced stop.
+			stopOp, err = src.UpdateInstanceState(inst.Name(), api.InstanceStatePut{Action: "stop", Force: true}, "")
+			if err != nil {
+				return err
+			}
+
+			err = stopOp.Wait()
+			if err != nil {
+				return fmt.Errorf("Failed to stop instance %q in project %q: %w", inst.Name(), projectName, err)
+			}
+		}
+
+		// Start the instance back up on the source if it can't be moved.
+		revert.Add(func() {
+			startOp, err := src.UpdateInstanceState(inst.Name(), api.InstanceStatePut{Action: "start"}, "")
+			if err == nil {
+				err = startOp.Wait()
+			}
+
+			if err != nil {
+				logger.Warn("Failed to restart instance after failed rebalancing", logger.Ctx{"project": projectName, "instance": inst.Name(), "err": err})
+			}
+		})
+	}
+
+	migrateOp, err := dest.MigrateInstance(inst.Name(), api.InstancePost{Migration: true, Live: live})
+	if err != nil {
+		return fmt.Errorf("Failed to migrate instance %q in project %q: %w", inst.Name(), projectName, err)
+	}
+
+	err = migrateOp.Wait()
+	if err != nil {
+		return fmt.Errorf("Failed to migrate instance %q in project %q: %w", inst.Name(), projectName, err)
+	}
+
+	revert.Success()
+
+	if live {
+		return nil
+	}
+
+	// Start it back up on target.
+	startOp, err := dest.UpdateInstanceState(inst.Name(), api.InstanceStatePut{Action: "start"}, "")
+	if err != nil {
+		return err
+	}
+
+	return startOp.Wait()
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/shared/api"
+)
+
+func Test_clusterRebalanceLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		sysInfo api.ClusterMemberSysInfo
+		want    float64
+	}{
+		{
+			name: "No information",
+			want: 0,
+		},
+		{
+			name:    "Idle",
+			sysInfo: api.ClusterMemberSysInfo{TotalRAM: 1000, FreeRAM: 1000, LogicalCPUs: 4, LoadAverages: []float64{0}},
+			want:    0,
+		},
+		{
+			name:    "Memory only",
+			sysInfo: api.ClusterMemberSysInfo{TotalRAM: 1000, FreeRAM: 300, BufferRAM: 100},
+			want:    30,
+		},
+		{
+			name:    "CPU only",
+			sysInfo: api.ClusterMemberSysInfo{LogicalCPUs: 4, LoadAverages: []float64{2, 3, 4}},
+			want:    25,
+		},
+		{
+			name:    "Memory and CPU",
+			sysInfo: api.ClusterMemberSysInfo{TotalRAM: 1000, FreeRAM: 500, LogicalCPUs: 2, LoadAverages: []float64{1}},
+			want:    50,
+		},
+		{
+			name:    "Overloaded",
+			sysInfo: api.ClusterMemberSysInfo{TotalRAM: 1000, FreeRAM: 800, BufferRAM: 400, LogicalCPUs: 2, LoadAverages: []float64{8}},
+			want:    50,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.want, clusterRebalanceLoad(tt.sysInfo), 0.001)
+		})
+	}
+}
+
+func Test_clusterRebalanceInWindow(t *testing.T) {
+	at := func(hour int, minute int) time.Time {
+		return time.Date(2024, 1, 1, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name  string
+		start time.Duration
+		end   time.Duration
+		now   time.Time
+		want  bool
+	}{
+		{
+			name: "No window",
+			now:  at(12, 0),
+			want: true,
+		},
+		{
+			name:  "Within daytime window",
+			start: 9 * time.Hour,
+			end:   17 * time.Hour,
+			now:   at(9, 0),
+			want:  true,
+		},
+		{
+			name:  "End of daytime window",
+			start: 9 * time.Hour,
+			end:   17 * time.Hour,
+			now:   at(17, 0),
+			want:  false,
+		},
+		{
+			name:  "Before daytime window",
+			start: 9 * time.Hour,
+			end:   17 * time.Hour,
+			now:   at(8, 59),
+			want:  false,
+		},
+		{
+			name:  "Within overnight window before midnight",
+			start: 22 * time.Hour,
+			end:   4*time.Hour + 30*time.Minute,
+			now:   at(23, 15),
+			want:  true,
+		},
+		{
+			name:  "Within overnight window after midnight",
+			start: 22 * time.Hour,
+			end:   4*time.Hour + 30*time.Minute,
+			now:   at(4, 29),
+			want:  true,
+		},
+		{
+			name:  "Outside overnight window",
+			start: 22 * time.Hour,
+			end:   4*time.Hour + 30*time.Minute,
+			now:   at(4, 30),
+			want:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, clusterRebalanceInWindow(tt.start, tt.end, tt.now))
+		})
+	}
+}
+
+func Test_clusterRebalanceTarget(t *testing.T) {
+	source := &clusterRebalanceMember{info: db.NodeInfo{Name: "m1"}, load: 80, instances: 10}
+	members := []*clusterRebalanceMember{
+		{info: db.NodeInfo{Name: "m2"}, load: 70, instances: 2},
+		{info: db.NodeInfo{Name: "m3"}, load: 30, instances: 5},
+		{info: db.NodeInfo{Name: "m4"}, load: 30, instances: 3},
+		{info: db.NodeInfo{Name: "m5"}, load: 50, instances: 1},
+	}
+
+	names := func(infos []db.NodeInfo) []string {
+		result := make([]string, 0, len(infos))
+		for _, info := range infos {
+			result = append(result, info.Name)
+		}
+
+		return result
+	}
+
+	// Only the members less busy than the source by at least the threshold are eligible.
+	assert.Equal(t, []string{"m3", "m4", "m5"}, names(clusterRebalanceEligibleMembers(source, members, 20)))
+	assert.Equal(t, []string{"m3", "m4"}, names(clusterRebalanceEligibleMembers(source, members, 50)))
+	assert.Empty(t, clusterRebalanceEligibleMembers(source, members, 60))
+
+	// The least busy candidate is picked, with the fewest instances on equal load.
+	target := clusterRebalancePickTarget(members, clusterRebalanceEligibleMembers(source, members, 20))
+	assert.Equal(t, "m4", target.info.Name)
+
+	// Members that aren't candidates, for example due to placement groups, are ignored.
+	target = clusterRebalancePickTarget(members, []db.NodeInfo{{Name: "m2"}, {Name: "m5"}})
+	assert.Equal(t, "m5", target.info.Name)
+
+	assert.Nil(t, clusterRebalancePickTarget(members, nil))
+}
@@ -139,6 +139,9 @@ type Daemon struct {
 	// Device monitor for watching filesystem events
 	devmonitor fsmonitor.FSMonitor
 
+	// Network monitor for watching the network state directories (e.g. DHCP leases)
+	networkmonitor fsmonitor.FSMonitor
+
 	// Keep track of skews.
 	timeSkew bool
 
@@ -685,6 +688,7 @@ func (d *Daemon) State() *state.State {
 		IdentityCache:       d.identityCache,
 		InstanceTypes:       instanceTypes,
 		DevMonitor:          d.devmonitor,
+		NetworkMonitor:      d.networkmonitor,
 		GlobalConfig:        globalConfig,
 		LocalConfig:         localConfig,
 		ServerName:          d.serverName,
@@ -1749,6 +1753,14 @@ func (d *Daemon) init() error {
 		return resp, nil
 	})
 
+	// Setup the network monitor (used to follow the DHCP leases of the networks).
+	if !d.os.MockMode {
+		d.networkmonitor, err = fsmonitorDrivers.Load(d.State().ShutdownCtx, shared.VarPath("networks"), fsmonitor.EventWrite, fsmonitor.EventModify)
+		if err != nil {
+			logger.Warn("Failed starting network monitor", logger.Ctx{"err": err})
+		}
+	}
+
 	// Setup the networks.
 	if !d.db.Cluster.LocalNodeIsEvacuated() {
 		logger.Infof("Initializing networks")
@@ -1945,6 +1957,15 @@ func (d *Daemon) init() error {
 		// Prune expired instance snapshots and take snapshot of instances (minutely check of configurable cron expression)
 		d.tasks.Add(pruneExpiredAndAutoCreateInstanceSnapshotsTask(d))
 
+		// Run instance health checks (every 10 seconds, configurable interval per instance)
+		d.tasks.Add(instanceHealthCheckTask(d))
+
+		// Start and stop instances on schedule (minutely check of configurable cron expression)
+		d.tasks.Add(instanceScheduleTask(d))
+
+		// Keep the instances of the instance groups in line with their number of replicas (minutely)
+		d.tasks.Add(instanceGroupsReconcileTask(d))
+
 		// Prune expired custom volume snapshots and take snapshots of custom volumes (minutely check of configurable cron expression)
 		d.tasks.Add(pruneExpiredAndAutoCreateCustomVolumeSnapshotsTask(d))
 
@@ -1994,6 +2015,9 @@ func (d *Daemon) startClusterTasks() {
 	// Perform automatic evacuation for offline cluster members
 	d.clusterTasks.Add(autoHealClusterTask(d))
 
+	// Move instances away from overloaded cluster members
+	d.clusterTasks.Add(autoRebalanceClusterTask(d))
+
 	// Start all background tasks
 	d.clusterTasks.Start(d.shutdownCtx)
 }
@@ -2400,7 +2424,7 @@ func (d *Daemon) heartbeatHandler(w http.ResponseWriter, r *http.Request, isLead
 }
 
 // nodeRefreshTask is run when a full state heartbeat is sent (on the leader) or received (by a non-leader member).
-// Is is used to check for member state changes and trigger refreshes of the certificate cache and forkdns peers.
+// Is is used to check for member state changes and trigger refreshes of the certificate cache and network peers.
 // It also triggers member role promotion when run on the isLeader is true.
 // When run on the leader, it accepts a list of unavailableMembers that have not responded to the current heartbeat
 // round (but may not be considered actually offline at this stage). These unavailable members will not be used for
@@ -2438,18 +2462,23 @@ func (d *Daemon) nodeRefreshTask(heartbeatData *cluster.APIHeartbeat, isLeader b
 		logger.Error("Error restarting OVN networks", logger.Ctx{"err": err})
 	}
 
-	if d.hasMemberStateChanged(heartbeatData) {
+	memberStateChanged := d.hasMemberStateChanged(heartbeatData)
+	if memberStateChanged {
 		logger.Info("Cluster member state has changed", logger.Ctx{"local": localClusterAddress})
 
 		// Refresh the identity cache.
 		updateIdentityCache(d)
+	}
 
-		// Refresh forkdns peers.
-		err := networkUpdateForkdnsServersTask(s, heartbeatData)
-		if err != nil {
+	// Refresh the cluster member peers of the networks. This runs on every heartbeat, so only failures to apply
+	// a member state change need recording for the refresh to be retried with the state change next heartbeat.
+	err = networkHeartbeatTask(s, heartbeatData, memberStateChanged)
+	if err != nil {
+		if memberStateChanged {
 			stateChangeTaskFailure = true
-			logger.Error("Error refreshing forkdns", logger.Ctx{"err": err, "local": localClusterAddress})
 		}
+
+		logger.Error("Error refreshing network peers", logger.Ctx{"err": err, "local": localClusterAddress})
 	}
 
 	// Refresh event listeners from heartbeat members (after certificates refreshed if needed).
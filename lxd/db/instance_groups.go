@@ -0,0 +1,222 @@
+//go:build linux && cgo && !agent
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/canonical/lxd/lxd/db/query"
+	"github.com/canonical/lxd/shared/api"
+)
+
+// GetInstanceGroupNames returns the names of the instance groups in the given project.
+// If projectName is empty, the instance groups of all projects are returned as a map of project name to names.
+func (c *ClusterTx) GetInstanceGroupNames(ctx context.Context, projectName string) (map[string][]string, error) {
+	q := `SELECT projects.name, instances_groups.name FROM instances_groups
+		JOIN projects ON projects.id = instances_groups.project_id
+	`
+
+	var args []any
+	if projectName != "" {
+		q += "WHERE projects.name = ? "
+		args = append(args, projectName)
+	}
+
+	q += "ORDER BY instances_groups.id"
+
+	names := make(map[string][]string)
+
+	err := query.Scan(ctx, c.tx, q, func(scan func(dest ...any) error) error {
+		var groupProject, groupName string
+
+		err := scan(&groupProject, &groupName)
+		if err != nil {
+			return err
+		}
+
+		names[groupProject] = append(names[groupProject], groupName)
+
+		return nil
+	}, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return names, nil
+}
+
+// GetInstanceGroup returns the instance group with the given name in the given project.
+func (c *ClusterTx) GetInstanceGroup(ctx context.Context, projectName string, name string) (int64, *api.InstanceGroup, error) {
+	var id = int64(-1)
+	var templateJSON string
+
+	group := api.InstanceGroup{
+		Name: name,
+	}
+
+	q := `
+		SELECT id, description, replicas, template
+		FROM instances_groups
+		WHERE project_id = (SELECT id FROM projects WHERE name = ? LIMIT 1) AND name=?
+		LIMIT 1
+	`
+
+	err := c.tx.QueryRowContext(ctx, q, projectName, name).Scan(&id, &group.Description, &group.Replicas, &templateJSON)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return -1, nil, api.StatusErrorf(http.StatusNotFound, "Instance group not found")
+		}
+
+		return -1, nil, err
+	}
+
+	err = json.Unmarshal([]byte(templateJSON), &group.Template)
+	if err != nil {
+		return -1, nil, fmt.Errorf("Failed unmarshalling instance group template: %w", err)
+	}
+
+	err = instanceGroupConfig(ctx, c, id, &group)
+	if err != nil {
+		return -1, nil, fmt.Errorf("Failed loading config: %w", err)
+	}
+
+	return id, &group, nil
+}
+
+// instanceGroupConfig populates the config map of the instance group with the given ID.
+func instanceGroupConfig(ctx context.Context, tx *ClusterTx, id int64, group *api.InstanceGroup) error {
+	q := `
+		SELECT key, value
+		FROM instances_groups_config
+		WHERE instance_group_id=?
+	`
+
+	group.Config = make(map[string]string)
+	return query.Scan(ctx, tx.Tx(), q, func(scan func(dest ...any) error) error {
+		var key, value string
+
+		err := scan(&key, &value)
+		if err != nil {
+			return err
+		}
+
+		_, found := group.Config[key]
+		if found {
+			return fmt.Errorf("Duplicate config row found for key %q for instance group ID %d", key, id)
+		}
+
+		group.Config[key] = value
+
+		return nil
+	}, id)
+}
+
+// CreateInstanceGroup creates a new instance group.
+func (c *ClusterTx) CreateInstanceGroup(ctx context.Context, projectName string, info *api.InstanceGroupsPost) (int64, error) {
+	templateJSON, err := json.Marshal(info.Template)
+	if err != nil {
+		return -1, fmt.Errorf("Failed marshalling instance group template: %w", err)
+	}
+
+	// Insert a new instance group record.
+	result, err := c.tx.ExecContext(ctx, `
+			INSERT INTO instances_groups (project_id, name, description, replicas, template)
+			VALUES ((SELECT id FROM projects WHERE name = ? LIMIT 1), ?, ?, ?, ?)
+		`, projectName, info.Name, info.Description, info.Replicas, string(templateJSON))
+	if err != nil {
+		return -1, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return -1, err
+	}
+
+	err = instanceGroupConfigAdd(c.tx, id, info.Config)
+	if err != nil {
+		return -1, err
+	}
+
+	return id, nil
+}
+
+// instanceGroupConfigAdd inserts instance group config keys.
+func instanceGroupConfigAdd(tx *sql.Tx, id int64, config map[string]string) error {
+	stmt, err := tx.Prepare("INSERT INTO instances_groups_config (instance_group_id, key, value) VALUES(?, ?, ?)")
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = stmt.Close() }()
+
+	for k, v := range config {
+		if v == "" {
+			continue
+		}
+
+		_, err = stmt.Exec(id, k, v)
+		if err != nil {
+			return fmt.Errorf("Failed inserting config: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// UpdateInstanceGroup updates the instance group with the given ID.
+func (c *ClusterTx) UpdateInstanceGroup(ctx context.Context, id int64, info api.InstanceGroupPut) error {
+	templateJSON, err := json.Marshal(info.Template)
+	if err != nil {
+		return fmt.Errorf("Failed marshalling instance group template: %w", err)
+	}
+
+	_, err = c.tx.ExecContext(ctx, `
+			UPDATE instances_groups
+			SET description = ?, replicas = ?, template = ?
+			WHERE id=?
+		`, info.Description, info.Replicas, string(templateJSON), id)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.tx.ExecContext(ctx, "DELETE FROM instances_groups_config WHERE instance_group_id=?", id)
+	if err != nil {
+		return err
+	}
+
+	err = instanceGroupConfigAdd(c.tx, id, info.Config)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteInstanceGroup deletes the instance group with the given ID.
+func (c *ClusterTx) DeleteInstanceGroup(ctx context.Context, id int64) error {
+	_, err := c.tx.ExecContext(ctx, "DELETE FROM instances_groups WHERE id=?", id)
+
+	return err
+}
+
+// GetInstanceGroupMembers returns the names of the instances of the given project that have been created by the
+// instance group with the given name.
+func (c *ClusterTx) GetInstanceGroupMembers(ctx context.Context, projectName string, name string) ([]string, error) {
+	q := `SELECT instances.name FROM instances
+		JOIN projects ON projects.id = instances.project_id
+		JOIN instances_config ON instances_config.instance_id = instances.id
+		WHERE projects.name = ? AND instances_config.key = 'volatile.instance_group' AND instances_config.value = ?
+		ORDER BY instances.name
+	`
+
+	members, err := query.SelectStrings(ctx, c.tx, q, projectName, name)
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading instance group members: %w", err)
+	}
+
+	return members, nil
+}
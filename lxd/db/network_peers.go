@@ -349,6 +349,41 @@ func (c *ClusterTx) GetNetworkPeerNames(ctx context.Context, networkID int64) (m
 	return peers, nil
 }
 
+// GetNetworkPeerNetworks returns the names of the networks having a peer linked to the given target network ID,
+// keyed on project name. The peers remain linked to the target network after its mutual peer has been deleted.
+func (c *ClusterTx) GetNetworkPeerNetworks(ctx context.Context, targetNetworkID int64) (map[string][]string, error) {
+	q := `
+	SELECT DISTINCT
+		projects.name,
+		networks.name
+	FROM networks_peers
+	JOIN networks ON networks.id = networks_peers.network_id
+	JOIN projects ON projects.id = networks.project_id
+	WHERE networks_peers.target_network_id = ?
+	`
+
+	networks := make(map[string][]string)
+
+	err := query.Scan(ctx, c.tx, q, func(scan func(dest ...any) error) error {
+		var projectName string
+		var networkName string
+
+		err := scan(&projectName, &networkName)
+		if err != nil {
+			return err
+		}
+
+		networks[projectName] = append(networks[projectName], networkName)
+
+		return nil
+	}, targetNetworkID)
+	if err != nil {
+		return nil, err
+	}
+
+	return networks, nil
+}
+
 // UpdateNetworkPeer updates an existing Network Peer.
 func (c *ClusterTx) UpdateNetworkPeer(ctx context.Context, networkID int64, peerID int64, info api.NetworkPeerPut) error {
 	// Update existing Network peer record.
@@ -0,0 +1,254 @@
+//go:build linux && cgo && !agent
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/canonical/lxd/lxd/db/query"
+	"github.com/canonical/lxd/shared/api"
+)
+
+// CreateNetworkReservation creates a new Network DHCP Reservation.
+func (c *ClusterTx) CreateNetworkReservation(ctx context.Context, networkID int64, info *api.NetworkReservationsPost) (int64, error) {
+	// Insert a new Network reservation record.
+	result, err := c.tx.ExecContext(ctx, `
+		INSERT INTO networks_reservations
+		(network_id, address, description)
+		VALUES (?, ?, ?)
+		`, networkID, info.Address, info.Description)
+	if err != nil {
+		return -1, err
+	}
+
+	reservationID, err := result.LastInsertId()
+	if err != nil {
+		return -1, err
+	}
+
+	// Save config.
+	err = networkReservationConfigAdd(c.tx, reservationID, info.Config)
+	if err != nil {
+		return -1, err
+	}
+
+	return reservationID, nil
+}
+
+// networkReservationConfigAdd inserts Network reservation config keys.
+func networkReservationConfigAdd(tx *sql.Tx, reservationID int64, config map[string]string) error {
+	stmt, err := tx.Prepare(`
+	INSERT INTO networks_reservations_config
+	(network_reservation_id, key, value)
+	VALUES(?, ?, ?)
+	`)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = stmt.Close() }()
+
+	for k, v := range config {
+		if v == "" {
+			continue
+		}
+
+		_, err = stmt.Exec(reservationID, k, v)
+		if err != nil {
+			return fmt.Errorf("Failed inserting config: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// UpdateNetworkReservation updates an existing Network DHCP Reservation.
+func (c *ClusterTx) UpdateNetworkReservation(ctx context.Context, networkID int64, reservationID int64, info api.NetworkReservationPut) error {
+	// Update existing Network reservation record.
+	res, err := c.tx.ExecContext(ctx, `
+		UPDATE networks_reservations
+		SET description = ?
+		WHERE network_id = ? and id = ?
+		`, info.Description, networkID, reservationID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected <= 0 {
+		return api.StatusErrorf(http.StatusNotFound, "Network reservation not found")
+	}
+
+	// Save config.
+	_, err = c.tx.ExecContext(ctx, "DELETE FROM networks_reservations_config WHERE network_reservation_id=?", reservationID)
+	if err != nil {
+		return err
+	}
+
+	err = networkReservationConfigAdd(c.tx, reservationID, info.Config)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteNetworkReservation deletes an existing Network DHCP Reservation.
+func (c *ClusterTx) DeleteNetworkReservation(ctx context.Context, networkID int64, reservationID int64) error {
+	// Delete existing Network reservation record.
+	res, err := c.tx.ExecContext(ctx, `
+			DELETE FROM networks_reservations
+			WHERE network_id = ? and id = ?
+		`, networkID, reservationID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected <= 0 {
+		return api.StatusErrorf(http.StatusNotFound, "Network reservation not found")
+	}
+
+	return nil
+}
+
+// GetNetworkReservation returns the Network Reservation ID and info for the given network ID and address.
+func (c *ClusterTx) GetNetworkReservation(ctx context.Context, networkID int64, address string) (int64, *api.NetworkReservation, error) {
+	reservations, err := c.GetNetworkReservations(ctx, networkID, address)
+	if err != nil {
+		return -1, nil, err
+	}
+
+	for reservationID, reservation := range reservations {
+		return reservationID, reservation, nil // Only single reservation in map.
+	}
+
+	return -1, nil, api.StatusErrorf(http.StatusNotFound, "Network reservation not found")
+}
+
+// networkReservationConfig populates the config map of the Network Reservation with the given ID.
+func networkReservationConfig(ctx context.Context, tx *ClusterTx, reservationID int64, reservation *api.NetworkReservation) error {
+	q := `
+	SELECT
+		key,
+		value
+	FROM networks_reservations_config
+	WHERE network_reservation_id=?
+	`
+
+	reservation.Config = make(map[string]string)
+	return query.Scan(ctx, tx.Tx(), q, func(scan func(dest ...any) error) error {
+		var key, value string
+
+		err := scan(&key, &value)
+		if err != nil {
+			return err
+		}
+
+		_, found := reservation.Config[key]
+		if found {
+			return fmt.Errorf("Duplicate config row found for key %q for network reservation ID %d", key, reservationID)
+		}
+
+		reservation.Config[key] = value
+
+		return nil
+	}, reservationID)
+}
+
+// GetNetworkReservationAddresses returns map of Network Reservation addresses for the given network ID keyed on
+// Reservation ID.
+func (c *ClusterTx) GetNetworkReservationAddresses(ctx context.Context, networkID int64) (map[int64]string, error) {
+	q := `
+	SELECT
+		id,
+		address
+	FROM networks_reservations
+	WHERE networks_reservations.network_id = ?
+	`
+
+	reservations := make(map[int64]string)
+
+	err := query.Scan(ctx, c.tx, q, func(scan func(dest ...any) error) error {
+		var reservationID = int64(-1)
+		var address string
+
+		err := scan(&reservationID, &address)
+		if err != nil {
+			return err
+		}
+
+		reservations[reservationID] = address
+
+		return nil
+	}, networkID)
+	if err != nil {
+		return nil, err
+	}
+
+	return reservations, nil
+}
+
+// GetNetworkReservations returns map of Network Reservations for the given network ID keyed on Reservation ID.
+// Can optionally retrieve only specific network reservations by address.
+func (c *ClusterTx) GetNetworkReservations(ctx context.Context, networkID int64, addresses ...string) (map[int64]*api.NetworkReservation, error) {
+	var q = &strings.Builder{}
+	args := []any{networkID}
+
+	q.WriteString(`
+	SELECT
+		networks_reservations.id,
+		networks_reservations.address,
+		networks_reservations.description
+	FROM networks_reservations
+	WHERE networks_reservations.network_id = ?
+	`)
+
+	if len(addresses) > 0 {
+		q.WriteString(fmt.Sprintf("AND networks_reservations.address IN %s ", query.Params(len(addresses))))
+		for _, address := range addresses {
+			args = append(args, address)
+		}
+	}
+
+	reservations := make(map[int64]*api.NetworkReservation)
+
+	err := query.Scan(ctx, c.tx, q.String(), func(scan func(dest ...any) error) error {
+		var reservationID = int64(-1)
+		var reservation api.NetworkReservation
+
+		err := scan(&reservationID, &reservation.Address, &reservation.Description)
+		if err != nil {
+			return err
+		}
+
+		reservations[reservationID] = &reservation
+
+		return nil
+	}, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Populate config.
+	for reservationID := range reservations {
+		err = networkReservationConfig(ctx, c, reservationID, reservations[reservationID])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return reservations, nil
+}
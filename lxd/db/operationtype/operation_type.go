@@ -76,6 +76,8 @@ const (
 	RenewServerCertificate
 	RemoveExpiredTokens
 	ClusterHeal
+	ClusterRebalance
+	InstanceDiff
 )
 
 // Description return a human-readable description of the operation type.
@@ -199,6 +201,10 @@ func (t Type) Description() string {
 		return "Remove expired tokens"
 	case ClusterHeal:
 		return "Healing cluster"
+	case ClusterRebalance:
+		return "Rebalancing cluster"
+	case InstanceDiff:
+		return "Comparing instance files"
 	default:
 		return "Executing operation"
 	}
@@ -239,6 +245,8 @@ func (t Type) Permission() (entity.Type, auth.Entitlement) {
 		return entity.TypeInstance, auth.EntitlementCanManageSnapshots
 	case SnapshotDelete:
 		return entity.TypeInstance, auth.EntitlementCanManageSnapshots
+	case InstanceDiff:
+		return entity.TypeInstance, auth.EntitlementCanAccessFiles
 
 	case InstanceCreate:
 		return entity.TypeInstance, auth.EntitlementCanEdit
@@ -21,6 +21,7 @@ import (
 	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/lxd/ip"
 	"github.com/canonical/lxd/lxd/network"
+	"github.com/canonical/lxd/lxd/project/limits"
 	"github.com/canonical/lxd/lxd/state"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/logger"
@@ -588,6 +589,20 @@ func networkSetupHostVethLimits(d *deviceCommon, oldConfig deviceConfig.Device,
 		}
 	}
 
+	// Apply the aggregate network limits of the project. Changes to the limits of the project are applied to the
+	// running instances by the project update, so there is nothing to clear when the project has no limits.
+	projectIngress, projectEgress, err := limits.NetworkRates(d.inst.Project().Config)
+	if err != nil {
+		return err
+	}
+
+	if projectIngress > 0 || projectEgress > 0 {
+		err = d.state.Firewall.InstanceSetupNetLimits(d.inst.Project().Name, d.inst.Name(), veth, projectIngress, projectEgress)
+		if err != nil {
+			return fmt.Errorf("Failed to setup project network limits: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -598,6 +613,19 @@ func networkClearHostVethLimits(d *deviceCommon) error {
 		return err
 	}
 
+	// The project update clears the network limits of the running instances when the project limits are removed.
+	projectIngress, projectEgress, err := limits.NetworkRates(d.inst.Project().Config)
+	if err != nil {
+		return err
+	}
+
+	if projectIngress > 0 || projectEgress > 0 {
+		err = d.state.Firewall.InstanceClearNetLimits(d.inst.Project().Name, d.inst.Name(), d.config["host_name"])
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
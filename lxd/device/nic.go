@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/network"
 	"github.com/canonical/lxd/lxd/network/acl"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/validate"
@@ -307,6 +308,16 @@ func nicValidationRules(requiredFields []string, optionalFields []string, instCo
 		//  managed: no
 		//  shortdesc: Whether to respect port isolation
 		"security.port_isolation": validate.Optional(validate.IsBool),
+		// lxdmeta:generate(entities=device-nic-bridged; group=device-conf; key=multicast.router)
+		// Possible values are `auto` (the port becomes a multicast router port when it receives IGMP or MLD queries), `permanent` (all multicast traffic is forwarded to the port) and `disabled` (no multicast traffic is forwarded to the port unless it joined the group).
+		// Only supported on native bridges.
+		// See {ref}`network-bridge-multicast`.
+		// ---
+		//  type: string
+		//  defaultdesc: `auto`
+		//  managed: no
+		//  shortdesc: Multicast router mode of the bridge port
+		"multicast.router": validate.Optional(validate.IsOneOf("auto", "disabled", "permanent")),
 		// lxdmeta:generate(entities=device-nic-{bridged+macvlan+sriov}; group=device-conf; key=maas.subnet.ipv4)
 		//
 		// ---
@@ -562,6 +573,85 @@ func nicValidationRules(requiredFields []string, optionalFields []string, instCo
 		//  managed: no
 		//  shortdesc: IPv6 static routes to route to NIC
 		"ipv6.routes.external": validate.Optional(validate.IsListOf(validate.IsNetworkV6)),
+		// lxdmeta:generate(entities=device-nic-bridged; group=device-conf; key=ipv4.nat.address)
+		// The outbound IPv4 traffic of the NIC is translated to this address instead of the network's {config:option}`network-bridge-network-conf:ipv4.nat.address`.
+		// Requires {config:option}`device-nic-bridged-device-conf:ipv4.address` to be set.
+		// See {ref}`devices-nic-snat` for more information.
+		// ---
+		//  type: string
+		//  managed: no
+		//  shortdesc: Source address used for outbound IPv4 traffic from the NIC
+
+		// lxdmeta:generate(entities=device-nic-ovn; group=device-conf; key=ipv4.nat.address)
+		// The outbound IPv4 traffic of the NIC is translated to this address instead of the network's {config:option}`network-ovn-network-conf:ipv4.nat.address`.
+		// See {ref}`devices-nic-snat` for more information.
+		// ---
+		//  type: string
+		//  managed: no
+		//  shortdesc: Source address used for outbound IPv4 traffic from the NIC
+		"ipv4.nat.address": validate.Optional(validate.IsNetworkAddressV4),
+		// lxdmeta:generate(entities=device-nic-bridged; group=device-conf; key=ipv6.nat.address)
+		// The outbound IPv6 traffic of the NIC is translated to this address instead of the network's {config:option}`network-bridge-network-conf:ipv6.nat.address`.
+		// Requires {config:option}`device-nic-bridged-device-conf:ipv6.address` to be set.
+		// See {ref}`devices-nic-snat` for more information.
+		// ---
+		//  type: string
+		//  managed: no
+		//  shortdesc: Source address used for outbound IPv6 traffic from the NIC
+
+		// lxdmeta:generate(entities=device-nic-ovn; group=device-conf; key=ipv6.nat.address)
+		// The outbound IPv6 traffic of the NIC is translated to this address instead of the network's {config:option}`network-ovn-network-conf:ipv6.nat.address`.
+		// See {ref}`devices-nic-snat` for more information.
+		// ---
+		//  type: string
+		//  managed: no
+		//  shortdesc: Source address used for outbound IPv6 traffic from the NIC
+		"ipv6.nat.address": validate.Optional(validate.IsNetworkAddressV6),
+		// lxdmeta:generate(entities=device-nic-bridged; group=device-conf; key=ipv4.dhcp.boot.filename)
+		// This overrides the network's {config:option}`network-bridge-network-conf:ipv4.dhcp.boot.filename` for the NIC.
+		// ---
+		//  type: string
+		//  managed: no
+		//  shortdesc: Boot file name sent to the NIC over DHCP
+
+		// lxdmeta:generate(entities=device-nic-ovn; group=device-conf; key=ipv4.dhcp.boot.filename)
+		// This overrides the network's {config:option}`network-ovn-network-conf:ipv4.dhcp.boot.filename` for the NIC.
+		// ---
+		//  type: string
+		//  managed: no
+		//  shortdesc: Boot file name sent to the NIC over DHCP
+		"ipv4.dhcp.boot.filename": validate.Optional(network.DHCPBootFilenameValidate),
+		// lxdmeta:generate(entities=device-nic-bridged; group=device-conf; key=ipv4.dhcp.boot.next_server)
+		// This overrides the network's {config:option}`network-bridge-network-conf:ipv4.dhcp.boot.next_server` for the NIC.
+		// ---
+		//  type: string
+		//  managed: no
+		//  shortdesc: Next server address sent to the NIC over DHCP
+
+		// lxdmeta:generate(entities=device-nic-ovn; group=device-conf; key=ipv4.dhcp.boot.next_server)
+		// This overrides the network's {config:option}`network-ovn-network-conf:ipv4.dhcp.boot.next_server` for the NIC.
+		// ---
+		//  type: string
+		//  managed: no
+		//  shortdesc: Next server address sent to the NIC over DHCP
+		"ipv4.dhcp.boot.next_server": validate.Optional(validate.IsNetworkAddressV4),
+
+		// lxdmeta:generate(entities=device-nic-{bridged+ovn}; group=device-conf; key=ipv4.dhcp.option.NAME)
+		// This overrides the DHCPv4 option of the network for the NIC.
+		// See {ref}`network-dhcp-options` for more information.
+		// ---
+		//  type: string
+		//  managed: no
+		//  shortdesc: Additional DHCPv4 option sent to the NIC
+
+		// lxdmeta:generate(entities=device-nic-{bridged+ovn}; group=device-conf; key=ipv6.dhcp.option.NAME)
+		// This overrides the DHCPv6 option of the network for the NIC.
+		// See {ref}`network-dhcp-options` for more information.
+		// ---
+		//  type: string
+		//  managed: no
+		//  shortdesc: Additional DHCPv6 option sent to the NIC
+
 		// lxdmeta:generate(entities=device-nic-ovn; group=device-conf; key=nested)
 		// See also {config:option}`device-nic-ovn-device-conf:vlan`.
 		// ---
@@ -23,6 +23,7 @@ import (
 	deviceConfig "github.com/canonical/lxd/lxd/device/config"
 	"github.com/canonical/lxd/lxd/dnsmasq"
 	"github.com/canonical/lxd/lxd/dnsmasq/dhcpalloc"
+	firewallDrivers "github.com/canonical/lxd/lxd/firewall/drivers"
 	"github.com/canonical/lxd/lxd/instance"
 	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/lxd/ip"
@@ -39,6 +40,7 @@ import (
 
 type bridgeNetwork interface {
 	UsesDNSMasq() bool
+	InstanceDeviceValidateNATAddresses(p *api.Project, natAddresses []net.IP) error
 }
 
 type nicBridged struct {
@@ -82,14 +84,19 @@ func (d *nicBridged) validateConfig(instConf instance.ConfigReader) error {
 		"ipv6.routes",
 		"ipv4.routes.external",
 		"ipv6.routes.external",
+		"ipv4.nat.address",
+		"ipv6.nat.address",
 		"security.mac_filtering",
 		"security.ipv4_filtering",
 		"security.ipv6_filtering",
 		"security.port_isolation",
+		"multicast.router",
 		"maas.subnet.ipv4",
 		"maas.subnet.ipv6",
 		"boot.priority",
 		"vlan",
+		"ipv4.dhcp.boot.filename",
+		"ipv4.dhcp.boot.next_server",
 	}
 
 	// checkWithManagedNetwork validates the device's settings against the managed network.
@@ -139,6 +146,17 @@ func (d *nicBridged) validateConfig(instConf instance.ConfigReader) error {
 			}
 		}
 
+		// The subnet of networks using IPv6 prefix delegation changes with the delegated prefix.
+		if netConfig["ipv6.delegation.parent"] != "" {
+			if d.config["ipv6.address"] != "" {
+				return fmt.Errorf(`Cannot specify "ipv6.address" on network %q as it uses IPv6 prefix delegation`, n.Name())
+			}
+
+			if shared.IsTrue(d.config["security.ipv6_filtering"]) {
+				return fmt.Errorf(`Cannot enable "security.ipv6_filtering" on network %q as it uses IPv6 prefix delegation`, n.Name())
+			}
+		}
+
 		if d.config["ipv6.address"] != "" {
 			dhcpv6Subnet := n.DHCPv6Subnet()
 
@@ -282,6 +300,53 @@ func (d *nicBridged) validateConfig(instConf instance.ConfigReader) error {
 		}
 	}
 
+	// Check the NIC's own SNAT addresses are allowed on the managed network.
+	natAddresses := []net.IP{}
+	for _, keyPrefix := range []string{"ipv4", "ipv6"} {
+		natAddressKey := keyPrefix + ".nat.address"
+		if d.config[natAddressKey] == "" {
+			continue
+		}
+
+		if d.network == nil {
+			return fmt.Errorf("Cannot specify %q when not using a managed parent network", natAddressKey)
+		}
+
+		// The SNAT rules match the NIC's traffic on its static address.
+		if validate.IsOneOf("", "none")(d.config[keyPrefix+".address"]) == nil {
+			return fmt.Errorf("Cannot specify %q without %q", natAddressKey, keyPrefix+".address")
+		}
+
+		natAddress := net.ParseIP(d.config[natAddressKey])
+		if natAddress == nil {
+			return fmt.Errorf("Invalid %q", natAddressKey)
+		}
+
+		natAddresses = append(natAddresses, natAddress)
+	}
+
+	if len(natAddresses) > 0 {
+		n, ok := d.network.(bridgeNetwork)
+		if !ok {
+			return fmt.Errorf("Network %q does not support NIC SNAT addresses", d.network.Name())
+		}
+
+		p := instConf.Project()
+		err := n.InstanceDeviceValidateNATAddresses(&p, natAddresses)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Check that DHCP options are only specified when the parent network provides DHCP.
+	if d.network == nil {
+		for k, v := range d.config {
+			if v != "" && (strings.HasPrefix(k, "ipv4.dhcp.") || strings.HasPrefix(k, "ipv6.dhcp.")) {
+				return fmt.Errorf("Cannot specify %q when not using a managed parent network", k)
+			}
+		}
+	}
+
 	// Check that IP filtering isn't being used with VLAN filtering.
 	if shared.IsTrue(d.config["security.ipv4_filtering"]) || shared.IsTrue(d.config["security.ipv6_filtering"]) {
 		if d.config["vlan"] != "" || d.config["vlan.tagged"] != "" {
@@ -300,6 +365,11 @@ func (d *nicBridged) validateConfig(instConf instance.ConfigReader) error {
 
 	rules := nicValidationRules(requiredFields, optionalFields, instConf)
 
+	// Add the DHCP option validation rules.
+	for k, v := range network.DHCPOptionsValidationRules(d.config, false) {
+		rules[k] = v
+	}
+
 	// Add bridge specific vlan validation.
 	rules["vlan"] = func(value string) error {
 		if value == "" || value == "none" {
@@ -381,6 +451,40 @@ func (d *nicBridged) checkAddressConflict() error {
 		networkName = d.network.Name()
 	}
 
+	// Check this NIC's static IPs aren't reserved for another host on the managed network.
+	if d.network != nil && (ourNICIPs["ipv4.address"] != nil || ourNICIPs["ipv6.address"] != nil) {
+		var reservations map[int64]*api.NetworkReservation
+
+		err := d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			var err error
+
+			reservations, err = tx.GetNetworkReservations(ctx, d.network.ID())
+
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("Failed loading network reservations: %w", err)
+		}
+
+		for _, reservation := range reservations {
+			reservedIP := net.ParseIP(reservation.Address)
+			if reservedIP == nil {
+				continue
+			}
+
+			reservedMAC, _ := net.ParseMAC(reservation.Config["hwaddr"])
+			if ourNICMAC != nil && reservedMAC != nil && bytes.Equal(ourNICMAC, reservedMAC) {
+				continue // The reservation is for this NIC.
+			}
+
+			for _, key := range []string{"ipv4.address", "ipv6.address"} {
+				if ourNICIPs[key] != nil && ourNICIPs[key].Equal(reservedIP) {
+					return api.StatusErrorf(http.StatusConflict, "IP address %q is reserved for another host", reservedIP.String())
+				}
+			}
+		}
+	}
+
 	// Bridge networks are always in the default project.
 	return network.UsedByInstanceDevices(d.state, api.ProjectDefaultName, networkName, "bridge", func(inst db.InstanceArgs, nicName string, nicConfig map[string]string) error {
 		// Skip our own device. This avoids triggering duplicate device errors during
@@ -452,12 +556,23 @@ func (d *nicBridged) validateEnvironment() error {
 // UpdatableFields returns a list of fields that can be updated without triggering a device remove & add.
 func (d *nicBridged) UpdatableFields(oldDevice Type) []string {
 	// Check old and new device types match.
-	_, match := oldDevice.(*nicBridged)
+	oldNIC, match := oldDevice.(*nicBridged)
 	if !match {
 		return []string{}
 	}
 
-	return []string{"limits.ingress", "limits.egress", "limits.max", "limits.priority", "ipv4.routes", "ipv6.routes", "ipv4.routes.external", "ipv6.routes.external", "ipv4.address", "ipv6.address", "security.mac_filtering", "security.ipv4_filtering", "security.ipv6_filtering"}
+	fields := []string{"limits.ingress", "limits.egress", "limits.max", "limits.priority", "ipv4.routes", "ipv6.routes", "ipv4.routes.external", "ipv6.routes.external", "ipv4.address", "ipv6.address", "ipv4.nat.address", "ipv6.nat.address", "security.mac_filtering", "security.ipv4_filtering", "security.ipv6_filtering", "ipv4.dhcp.boot.filename", "ipv4.dhcp.boot.next_server", "multicast.router"}
+
+	// DHCP options are applied by rebuilding the dnsmasq host entry, so can be added, changed and removed.
+	for _, config := range []deviceConfig.Device{d.config, oldNIC.config} {
+		for k := range config {
+			if strings.HasPrefix(k, "ipv4.dhcp.option.") || strings.HasPrefix(k, "ipv6.dhcp.option.") {
+				fields = append(fields, k)
+			}
+		}
+	}
+
+	return fields
 }
 
 // Add is run when a device is added to a non-snapshot instance whether or not the instance is running.
@@ -563,6 +678,14 @@ func (d *nicBridged) Start() (*deviceConfig.RunConfig, error) {
 		return nil, err
 	}
 
+	// Apply the SNAT rules for the NIC's own SNAT addresses.
+	err = d.setupSNAT()
+	if err != nil {
+		return nil, err
+	}
+
+	revert.Add(func() { _ = d.state.Firewall.InstanceClearSNAT(d.inst.Project().Name, d.inst.Name(), d.name) })
+
 	// Disable IPv6 on host-side veth interface (prevents host-side interface getting link-local address)
 	// which isn't needed because the host-side interface is connected to a bridge.
 	err = util.SysctlSet(fmt.Sprintf("net/ipv6/conf/%s/disable_ipv6", saveData["host_name"]), "1")
@@ -615,6 +738,18 @@ func (d *nicBridged) Start() (*deviceConfig.RunConfig, error) {
 		return nil, err
 	}
 
+	// Setup multicast router mode on bridge port.
+	if d.config["multicast.router"] != "" {
+		if !nativeBridge {
+			return nil, fmt.Errorf(`"multicast.router" is only supported on native bridges`)
+		}
+
+		err = network.BridgePortMulticastRouterSet(saveData["host_name"], d.config["multicast.router"])
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Check if hairpin mode needs to be enabled.
 	if nativeBridge && d.network != nil {
 		brNetfilterEnabled := false
@@ -760,6 +895,31 @@ func (d *nicBridged) Update(oldDevices deviceConfig.Devices, isRunning bool) err
 		}
 
 		revert.Add(r)
+
+		// Rebuild the SNAT rules as they match on the NIC's static addresses.
+		if d.config["ipv4.address"] != oldConfig["ipv4.address"] || d.config["ipv6.address"] != oldConfig["ipv6.address"] || d.config["ipv4.nat.address"] != oldConfig["ipv4.nat.address"] || d.config["ipv6.nat.address"] != oldConfig["ipv6.nat.address"] {
+			err = d.state.Firewall.InstanceClearSNAT(d.inst.Project().Name, d.inst.Name(), d.name)
+			if err != nil {
+				return err
+			}
+
+			err = d.setupSNAT()
+			if err != nil {
+				return err
+			}
+		}
+
+		// Apply multicast router mode on bridge port.
+		if d.config["multicast.router"] != oldConfig["multicast.router"] {
+			if !network.IsNativeBridge(d.config["parent"]) {
+				return fmt.Errorf(`"multicast.router" is only supported on native bridges`)
+			}
+
+			err = network.BridgePortMulticastRouterSet(d.config["host_name"], d.config["multicast.router"])
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	// Rebuild dnsmasq entry if needed and reload.
@@ -815,6 +975,13 @@ func (d *nicBridged) Stop() (*deviceConfig.RunConfig, error) {
 		return nil, err
 	}
 
+	if d.config["ipv4.nat.address"] != "" || d.config["ipv6.nat.address"] != "" {
+		err = d.state.Firewall.InstanceClearSNAT(d.inst.Project().Name, d.inst.Name(), d.name)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Setup post-stop actions.
 	runConf := deviceConfig.RunConfig{
 		PostHooks: []func() error{d.postStop},
@@ -934,7 +1101,7 @@ func (d *nicBridged) rebuildDnsmasqEntry() error {
 		}
 	}
 
-	err := dnsmasq.UpdateStaticEntry(d.config["parent"], d.inst.Project().Name, d.inst.Name(), d.Name(), d.network.Config(), d.config["hwaddr"], ipv4Address, ipv6Address)
+	err := dnsmasq.UpdateStaticEntry(d.config["parent"], d.inst.Project().Name, d.inst.Name(), d.Name(), d.network.Config(), d.config["hwaddr"], ipv4Address, ipv6Address, network.DHCPOptionsDNSMasq(d.config))
 	if err != nil {
 		return err
 	}
@@ -1105,6 +1272,7 @@ func (d *nicBridged) setFilters() (err error) {
 			DeviceName:  d.Name(),
 			HostMAC:     mac,
 			Network:     d.network,
+			DHCPOptions: network.DHCPOptionsDNSMasq(d.config),
 		}
 
 		err = dhcpalloc.AllocateTask(opts, func(t *dhcpalloc.Transaction) error {
@@ -1770,3 +1938,30 @@ func (d *nicBridged) Register() error {
 
 	return nil
 }
+
+// setupSNAT applies the SNAT rules translating the outbound traffic of the NIC's static addresses to the NIC's own
+// SNAT addresses.
+func (d *nicBridged) setupSNAT() error {
+	var SNATV4, SNATV6 *firewallDrivers.SNATOpts
+
+	if d.config["ipv4.nat.address"] != "" {
+		ipNet := network.IPToNet(net.ParseIP(d.config["ipv4.address"]))
+		SNATV4 = &firewallDrivers.SNATOpts{Subnet: &ipNet, SNATAddress: net.ParseIP(d.config["ipv4.nat.address"])}
+	}
+
+	if d.config["ipv6.nat.address"] != "" {
+		ipNet := network.IPToNet(net.ParseIP(d.config["ipv6.address"]))
+		SNATV6 = &firewallDrivers.SNATOpts{Subnet: &ipNet, SNATAddress: net.ParseIP(d.config["ipv6.nat.address"])}
+	}
+
+	if SNATV4 == nil && SNATV6 == nil {
+		return nil
+	}
+
+	err := d.state.Firewall.InstanceSetupSNAT(d.inst.Project().Name, d.inst.Name(), d.name, d.config["parent"], SNATV4, SNATV6)
+	if err != nil {
+		return fmt.Errorf("Failed setting up SNAT rules: %w", err)
+	}
+
+	return nil
+}
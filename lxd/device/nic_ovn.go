@@ -36,6 +36,7 @@ type ovnNet interface {
 	network.Network
 
 	InstanceDevicePortValidateExternalRoutes(deviceInstance instance.Instance, deviceName string, externalRoutes []*net.IPNet) error
+	InstanceDevicePortValidateNATAddresses(p *api.Project, natAddresses []net.IP) error
 	InstanceDevicePortAdd(instanceUUID string, deviceName string, deviceConfig deviceConfig.Device) error
 	InstanceDevicePortStart(opts *network.OVNInstanceNICSetupOpts, securityACLsRemove []string) (openvswitch.OVNSwitchPort, error)
 	InstanceDevicePortRemove(instanceUUID string, deviceName string, deviceConfig deviceConfig.Device) error
@@ -90,6 +91,8 @@ func (d *nicOVN) validateConfig(instConf instance.ConfigReader) error {
 		"ipv6.routes",
 		"ipv4.routes.external",
 		"ipv6.routes.external",
+		"ipv4.nat.address",
+		"ipv6.nat.address",
 		"boot.priority",
 		"security.acls",
 		"security.acls.default.ingress.action",
@@ -99,6 +102,8 @@ func (d *nicOVN) validateConfig(instConf instance.ConfigReader) error {
 		"acceleration",
 		"nested",
 		"vlan",
+		"ipv4.dhcp.boot.filename",
+		"ipv4.dhcp.boot.next_server",
 	}
 
 	// The NIC's network may be a non-default project, so lookup project and get network's project name.
@@ -244,6 +249,11 @@ func (d *nicOVN) validateConfig(instConf instance.ConfigReader) error {
 
 	rules := nicValidationRules(requiredFields, optionalFields, instConf)
 
+	// Add the DHCP option validation rules.
+	for k, v := range network.DHCPOptionsValidationRules(d.config, true) {
+		rules[k] = v
+	}
+
 	// Now run normal validation.
 	err = d.config.Validate(rules)
 	if err != nil {
@@ -270,6 +280,24 @@ func (d *nicOVN) validateConfig(instConf instance.ConfigReader) error {
 		}
 	}
 
+	// Check the NIC's own SNAT addresses are allowed on the network's uplink.
+	var natAddresses []net.IP
+	for _, k := range []string{"ipv4.nat.address", "ipv6.nat.address"} {
+		if d.config[k] == "" {
+			continue
+		}
+
+		natAddresses = append(natAddresses, net.ParseIP(d.config[k]))
+	}
+
+	if len(natAddresses) > 0 {
+		p := instConf.Project()
+		err = d.network.InstanceDevicePortValidateNATAddresses(&p, natAddresses)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Check Security ACLs exist.
 	if d.config["security.acls"] != "" {
 		err = acl.Exists(d.state, networkProjectName, shared.SplitNTrimSpace(d.config["security.acls"], ",", -1, true)...)
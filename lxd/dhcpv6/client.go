@@ -0,0 +1,531 @@
+package dhcpv6
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/big"
+	"net"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"golang.org/x/sys/unix"
+
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// Retransmission parameters (RFC 8415 section 7.6).
+const (
+	solicitTimeout    = 1 * time.Second
+	solicitMaxTimeout = 3600 * time.Second
+	requestTimeout    = 1 * time.Second
+	requestMaxTimeout = 30 * time.Second
+	requestMaxCount   = 10
+	renewTimeout      = 10 * time.Second
+	renewMaxTimeout   = 600 * time.Second
+	rebindTimeout     = 10 * time.Second
+	rebindMaxTimeout  = 600 * time.Second
+	releaseTimeout    = 1 * time.Second
+	releaseMaxCount   = 4
+)
+
+// allServers is the All_DHCP_Relay_Agents_and_Servers multicast address.
+var allServers = net.ParseIP("ff02::1:2")
+
+// errNoPrefix is returned when a server doesn't delegate any usable prefix.
+var errNoPrefix = errors.New("No prefix delegated")
+
+// Lease represents a prefix delegated by a DHCPv6 server.
+type Lease struct {
+	Prefix            net.IPNet
+	PreferredLifetime time.Duration
+	ValidLifetime     time.Duration
+	T1                time.Duration
+	T2                time.Duration
+	Obtained          time.Time
+
+	serverID []byte
+}
+
+// LeaseHandler is called every time the delegated prefix changes.
+// It is called with nil when the prefix is lost.
+type LeaseHandler func(lease *Lease)
+
+// Client is a DHCPv6 client requesting a prefix delegation (RFC 8415) on an interface.
+type Client struct {
+	iface        string
+	prefixLength int
+	hint         *net.IPNet
+	handler      LeaseHandler
+
+	duid []byte
+	iaid uint32
+	conn net.PacketConn
+
+	mu     sync.Mutex
+	lease  *Lease
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewClient returns a new prefix delegation client for the interface.
+// The prefix length and prefix hint are optional and are passed to the servers as a preference.
+func NewClient(iface string, prefixLength int, hint *net.IPNet, handler LeaseHandler) (*Client, error) {
+	netIf, err := net.InterfaceByName(iface)
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting interface %q: %w", iface, err)
+	}
+
+	if len(netIf.HardwareAddr) == 0 {
+		return nil, fmt.Errorf("Interface %q has no hardware address", iface)
+	}
+
+	// Derive a stable IAID from the interface name.
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(iface))
+
+	return &Client{
+		iface:        iface,
+		prefixLength: prefixLength,
+		hint:         hint,
+		handler:      handler,
+		duid:         duidLL(netIf.HardwareAddr),
+		iaid:         h.Sum32(),
+	}, nil
+}
+
+// Start opens the DHCPv6 client socket and starts requesting a prefix in the background.
+func (c *Client) Start() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cancel != nil {
+		return nil
+	}
+
+	lc := net.ListenConfig{
+		Control: func(network string, address string, conn syscall.RawConn) error {
+			var sockErr error
+			err := conn.Control(func(fd uintptr) {
+				sockErr = unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_REUSEADDR, 1)
+				if sockErr != nil {
+					return
+				}
+
+				sockErr = unix.SetsockoptString(int(fd), unix.SOL_SOCKET, unix.SO_BINDTODEVICE, c.iface)
+			})
+			if err != nil {
+				return err
+			}
+
+			return sockErr
+		},
+	}
+
+	conn, err := lc.ListenPacket(context.Background(), "udp6", "[::]:546")
+	if err != nil {
+		return fmt.Errorf("Failed opening DHCPv6 client socket on %q: %w", c.iface, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.conn = conn
+	c.cancel = cancel
+	c.done = make(chan struct{})
+
+	go c.run(ctx)
+
+	return nil
+}
+
+// Stop releases the delegated prefix and stops the client.
+func (c *Client) Stop() {
+	c.mu.Lock()
+	cancel := c.cancel
+	done := c.done
+	c.cancel = nil
+	c.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+
+	cancel()
+	<-done
+}
+
+// Lease returns the current lease (nil if no prefix is delegated).
+func (c *Client) Lease() *Lease {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.lease
+}
+
+// setLease records the new lease and calls the handler if the prefix changed.
+func (c *Client) setLease(lease *Lease) {
+	c.mu.Lock()
+	oldLease := c.lease
+	c.lease = lease
+	c.mu.Unlock()
+
+	if lease != nil {
+		// Ask for the same prefix again if the lease is lost.
+		c.hint = &lease.Prefix
+	}
+
+	if oldLease == nil && lease == nil {
+		return
+	}
+
+	if oldLease != nil && lease != nil && oldLease.Prefix.String() == lease.Prefix.String() {
+		return
+	}
+
+	c.handler(lease)
+}
+
+// run obtains a prefix and keeps it renewed until the context is cancelled.
+func (c *Client) run(ctx context.Context) {
+	defer close(c.done)
+	defer func() { _ = c.conn.Close() }()
+
+	l := logger.AddContext(logger.Ctx{"interface": c.iface})
+
+	for {
+		lease, err := c.obtain(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+
+			l.Warn("Failed obtaining delegated IPv6 prefix", logger.Ctx{"err": err})
+
+			// Wait before soliciting again.
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(solicitTimeout):
+			}
+
+			continue
+		}
+
+		l.Info("Obtained delegated IPv6 prefix", logger.Ctx{"prefix": lease.Prefix.String(), "validLifetime": lease.ValidLifetime})
+		c.setLease(lease)
+
+		for lease != nil {
+			// Wait until the lease needs renewing.
+			select {
+			case <-ctx.Done():
+				c.release(lease)
+				return
+			case <-time.After(time.Until(lease.Obtained.Add(lease.T1))):
+			}
+
+			lease, err = c.extend(ctx, lease)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+
+				l.Warn("Lost delegated IPv6 prefix", logger.Ctx{"err": err})
+				c.setLease(nil)
+				break
+			}
+
+			c.setLease(lease)
+		}
+	}
+}
+
+// obtain solicits the servers and requests a prefix from the first one offering one.
+func (c *Client) obtain(ctx context.Context) (*Lease, error) {
+	hint := iaPD{iaid: c.iaid}
+	if c.hint != nil {
+		hint.prefixes = []iaPrefix{{prefix: *c.hint}}
+	} else if c.prefixLength > 0 {
+		hint.prefixes = []iaPrefix{{prefix: net.IPNet{Mask: net.CIDRMask(c.prefixLength, 128)}}}
+	}
+
+	advertise, err := c.exchange(ctx, layers.DHCPv6MsgTypeSolicit, nil, hint, solicitTimeout, solicitMaxTimeout, 0, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+
+	offer, err := c.parseLease(advertise)
+	if err != nil {
+		return nil, err
+	}
+
+	offered := iaPD{iaid: c.iaid, prefixes: []iaPrefix{{prefix: offer.Prefix}}}
+
+	reply, err := c.exchange(ctx, layers.DHCPv6MsgTypeRequest, offer.serverID, offered, requestTimeout, requestMaxTimeout, requestMaxCount, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+
+	return c.parseLease(reply)
+}
+
+// extend renews the lease with the server that delegated the prefix and, failing that, rebinds it with any
+// server until the prefix is no longer valid.
+func (c *Client) extend(ctx context.Context, lease *Lease) (*Lease, error) {
+	current := iaPD{iaid: c.iaid, prefixes: []iaPrefix{{prefix: lease.Prefix}}}
+
+	reply, err := c.exchange(ctx, layers.DHCPv6MsgTypeRenew, lease.serverID, current, renewTimeout, renewMaxTimeout, 0, lease.Obtained.Add(lease.T2))
+	if err == nil {
+		return c.parseLease(reply)
+	}
+
+	if ctx.Err() != nil {
+		return nil, err
+	}
+
+	reply, err = c.exchange(ctx, layers.DHCPv6MsgTypeRebind, nil, current, rebindTimeout, rebindMaxTimeout, 0, lease.Obtained.Add(lease.ValidLifetime))
+	if err != nil {
+		return nil, err
+	}
+
+	return c.parseLease(reply)
+}
+
+// release gives the prefix back to the server that delegated it.
+func (c *Client) release(lease *Lease) {
+	ctx, cancel := context.WithTimeout(context.Background(), releaseMaxCount*releaseTimeout)
+	defer cancel()
+
+	current := iaPD{iaid: c.iaid, prefixes: []iaPrefix{{prefix: lease.Prefix}}}
+
+	_, err := c.exchange(ctx, layers.DHCPv6MsgTypeRelease, lease.serverID, current, releaseTimeout, releaseTimeout, releaseMaxCount, time.Time{})
+	if err != nil {
+		logger.Debug("Failed releasing delegated IPv6 prefix", logger.Ctx{"interface": c.iface, "prefix": lease.Prefix.String(), "err": err})
+	}
+
+	c.mu.Lock()
+	c.lease = nil
+	c.mu.Unlock()
+}
+
+// exchange sends a message to the servers and waits for the response, retransmitting as described in RFC 8415
+// section 15. A zero maxCount or deadline means no limit.
+func (c *Client) exchange(ctx context.Context, msgType layers.DHCPv6MsgType, serverID []byte, ia iaPD, timeout time.Duration, maxTimeout time.Duration, maxCount int, deadline time.Time) (*layers.DHCPv6, error) {
+	xid := make([]byte, 3)
+	_, err := rand.Read(xid)
+	if err != nil {
+		return nil, err
+	}
+
+	expectedType := layers.DHCPv6MsgTypeReply
+	if msgType == layers.DHCPv6MsgTypeSolicit {
+		expectedType = layers.DHCPv6MsgTypeAdverstise
+	}
+
+	start := time.Now()
+	rt := jitter(timeout)
+
+	for count := 1; ; count++ {
+		msg := layers.DHCPv6{
+			MsgType:       msgType,
+			TransactionID: xid,
+		}
+
+		msg.Options = append(msg.Options,
+			layers.NewDHCPv6Option(layers.DHCPv6OptClientID, c.duid),
+			layers.NewDHCPv6Option(layers.DHCPv6OptElapsedTime, elapsedTime(start)),
+			layers.NewDHCPv6Option(layers.DHCPv6OptIAPD, ia.encode()),
+		)
+
+		if serverID != nil {
+			msg.Options = append(msg.Options, layers.NewDHCPv6Option(layers.DHCPv6OptServerID, serverID))
+		}
+
+		buf := gopacket.NewSerializeBuffer()
+		err = gopacket.SerializeLayers(buf, gopacket.SerializeOptions{FixLengths: true}, &msg)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = c.conn.WriteTo(buf.Bytes(), &net.UDPAddr{IP: allServers, Port: 547, Zone: c.iface})
+		if err != nil {
+			return nil, fmt.Errorf("Failed sending DHCPv6 %s: %w", msgType, err)
+		}
+
+		waitUntil := time.Now().Add(rt)
+		if !deadline.IsZero() && deadline.Before(waitUntil) {
+			waitUntil = deadline
+		}
+
+		reply, err := c.receive(ctx, xid, expectedType, waitUntil)
+		if err != nil {
+			return nil, err
+		}
+
+		if reply != nil {
+			return reply, nil
+		}
+
+		if maxCount > 0 && count >= maxCount {
+			return nil, fmt.Errorf("No response to DHCPv6 %s after %d attempts", msgType, count)
+		}
+
+		if !deadline.IsZero() && !time.Now().Before(deadline) {
+			return nil, fmt.Errorf("No response to DHCPv6 %s before deadline", msgType)
+		}
+
+		// Double the retransmission timeout up to the maximum.
+		rt = jitter(2 * rt)
+		if maxTimeout > 0 && rt > maxTimeout {
+			rt = jitter(maxTimeout)
+		}
+	}
+}
+
+// receive waits until the deadline for a message of the expected type matching the transaction.
+// It returns nil without an error if no such message is received in time.
+func (c *Client) receive(ctx context.Context, xid []byte, expectedType layers.DHCPv6MsgType, deadline time.Time) (*layers.DHCPv6, error) {
+	buf := make([]byte, 1500)
+
+	for {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		// Wake up regularly to check for cancellation.
+		readDeadline := time.Now().Add(time.Second)
+		if deadline.Before(readDeadline) {
+			readDeadline = deadline
+		}
+
+		err := c.conn.SetReadDeadline(readDeadline)
+		if err != nil {
+			return nil, err
+		}
+
+		n, _, err := c.conn.ReadFrom(buf)
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				if !time.Now().Before(deadline) {
+					return nil, nil
+				}
+
+				continue
+			}
+
+			return nil, err
+		}
+
+		msg := &layers.DHCPv6{}
+		err = msg.DecodeFromBytes(append([]byte(nil), buf[:n]...), gopacket.NilDecodeFeedback)
+		if err != nil {
+			continue
+		}
+
+		if msg.MsgType != expectedType || !bytes.Equal(msg.TransactionID, xid) {
+			continue
+		}
+
+		// Ignore messages meant for other clients.
+		clientID := getOption(msg.Options, layers.DHCPv6OptClientID)
+		if clientID == nil || !bytes.Equal(clientID.Data, c.duid) {
+			continue
+		}
+
+		return msg, nil
+	}
+}
+
+// parseLease extracts the delegated prefix from a server message.
+func (c *Client) parseLease(msg *layers.DHCPv6) (*Lease, error) {
+	serverID := getOption(msg.Options, layers.DHCPv6OptServerID)
+	if serverID == nil {
+		return nil, fmt.Errorf("DHCPv6 %s without server identifier", msg.MsgType)
+	}
+
+	// Check the status of the whole message.
+	statusOpt := getOption(msg.Options, layers.DHCPv6OptStatusCode)
+	if statusOpt != nil {
+		s, err := parseStatus(statusOpt.Data)
+		if err != nil {
+			return nil, err
+		}
+
+		if s.code != statusSuccess {
+			return nil, fmt.Errorf("DHCPv6 server returned status %d: %s", s.code, s.message)
+		}
+	}
+
+	for _, opt := range msg.Options {
+		if opt.Code != layers.DHCPv6OptIAPD {
+			continue
+		}
+
+		ia, err := parseIAPD(opt.Data)
+		if err != nil {
+			return nil, err
+		}
+
+		if ia.iaid != c.iaid {
+			continue
+		}
+
+		if ia.status != nil && ia.status.code != statusSuccess {
+			if ia.status.code == statusNoBinding || ia.status.code == statusNoPrefixAvail {
+				return nil, fmt.Errorf("%w: %s", errNoPrefix, ia.status.message)
+			}
+
+			return nil, fmt.Errorf("DHCPv6 server returned status %d: %s", ia.status.code, ia.status.message)
+		}
+
+		for _, p := range ia.prefixes {
+			if p.validLifetime == 0 || p.preferredLifetime > p.validLifetime {
+				continue
+			}
+
+			if p.status != nil && p.status.code != statusSuccess {
+				continue
+			}
+
+			lease := &Lease{
+				Prefix:            p.prefix,
+				PreferredLifetime: time.Duration(p.preferredLifetime) * time.Second,
+				ValidLifetime:     time.Duration(p.validLifetime) * time.Second,
+				T1:                time.Duration(ia.t1) * time.Second,
+				T2:                time.Duration(ia.t2) * time.Second,
+				Obtained:          time.Now(),
+				serverID:          serverID.Data,
+			}
+
+			// Pick the renewal times if left to the client (RFC 8415 section 21.21).
+			if lease.T1 == 0 || lease.T2 == 0 || lease.T1 > lease.T2 {
+				lease.T1 = lease.PreferredLifetime / 2
+				lease.T2 = lease.PreferredLifetime * 4 / 5
+			}
+
+			return lease, nil
+		}
+	}
+
+	return nil, errNoPrefix
+}
+
+// jitter randomizes the retransmission timeout by up to 10% in either direction.
+func jitter(timeout time.Duration) time.Duration {
+	spread := int64(timeout / 5)
+	if spread <= 0 {
+		return timeout
+	}
+
+	n, err := rand.Int(rand.Reader, big.NewInt(spread))
+	if err != nil {
+		return timeout
+	}
+
+	return timeout - timeout/10 + time.Duration(n.Int64())
+}
@@ -0,0 +1,222 @@
+package dhcpv6
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/google/gopacket/layers"
+)
+
+// Status codes (RFC 8415 section 21.13).
+const (
+	statusSuccess       = 0
+	statusNoBinding     = 3
+	statusNoPrefixAvail = 6
+)
+
+// iaPrefix represents an IA Prefix option (RFC 8415 section 21.22).
+type iaPrefix struct {
+	preferredLifetime uint32
+	validLifetime     uint32
+	prefix            net.IPNet
+	status            *status
+}
+
+// iaPD represents an Identity Association for Prefix Delegation option (RFC 8415 section 21.21).
+type iaPD struct {
+	iaid     uint32
+	t1       uint32
+	t2       uint32
+	prefixes []iaPrefix
+	status   *status
+}
+
+// status represents a Status Code option (RFC 8415 section 21.13).
+type status struct {
+	code    uint16
+	message string
+}
+
+// duidLL returns a DUID based on the link-layer address of the interface (RFC 8415 section 11.4).
+func duidLL(hwaddr net.HardwareAddr) []byte {
+	data := make([]byte, 4, 4+len(hwaddr))
+	binary.BigEndian.PutUint16(data[0:2], uint16(layers.DHCPv6DUIDTypeLL))
+	binary.BigEndian.PutUint16(data[2:4], uint16(layers.LinkTypeEthernet))
+
+	return append(data, hwaddr...)
+}
+
+// elapsedTime returns the content of an Elapsed Time option for an exchange started at the time supplied.
+func elapsedTime(start time.Time) []byte {
+	// The elapsed time is expressed in hundredths of a second and saturates at 0xffff.
+	elapsed := time.Since(start).Milliseconds() / 10
+	if elapsed > 0xffff {
+		elapsed = 0xffff
+	}
+
+	data := make([]byte, 2)
+	binary.BigEndian.PutUint16(data, uint16(elapsed))
+
+	return data
+}
+
+// encode returns the content of the IA_PD option.
+func (ia *iaPD) encode() []byte {
+	data := make([]byte, 12)
+	binary.BigEndian.PutUint32(data[0:4], ia.iaid)
+	binary.BigEndian.PutUint32(data[4:8], ia.t1)
+	binary.BigEndian.PutUint32(data[8:12], ia.t2)
+
+	for _, prefix := range ia.prefixes {
+		data = appendOption(data, layers.DHCPv6OptIAPrefix, prefix.encode())
+	}
+
+	return data
+}
+
+// encode returns the content of the IA Prefix option.
+func (p *iaPrefix) encode() []byte {
+	data := make([]byte, 25)
+	binary.BigEndian.PutUint32(data[0:4], p.preferredLifetime)
+	binary.BigEndian.PutUint32(data[4:8], p.validLifetime)
+
+	prefixLength, _ := p.prefix.Mask.Size()
+	data[8] = byte(prefixLength)
+
+	if p.prefix.IP != nil {
+		copy(data[9:25], p.prefix.IP.To16())
+	}
+
+	return data
+}
+
+// appendOption appends an option with the code and content supplied to data.
+func appendOption(data []byte, code layers.DHCPv6Opt, content []byte) []byte {
+	header := make([]byte, 4)
+	binary.BigEndian.PutUint16(header[0:2], uint16(code))
+	binary.BigEndian.PutUint16(header[2:4], uint16(len(content)))
+
+	data = append(data, header...)
+
+	return append(data, content...)
+}
+
+// parseOptions splits the data into the options it contains.
+func parseOptions(data []byte) (map[layers.DHCPv6Opt][][]byte, error) {
+	options := map[layers.DHCPv6Opt][][]byte{}
+
+	for len(data) > 0 {
+		if len(data) < 4 {
+			return nil, fmt.Errorf("Truncated option header")
+		}
+
+		code := layers.DHCPv6Opt(binary.BigEndian.Uint16(data[0:2]))
+		length := int(binary.BigEndian.Uint16(data[2:4]))
+
+		if len(data) < 4+length {
+			return nil, fmt.Errorf("Truncated %s option", code)
+		}
+
+		options[code] = append(options[code], data[4:4+length])
+		data = data[4+length:]
+	}
+
+	return options, nil
+}
+
+// parseStatus parses the content of a Status Code option.
+func parseStatus(data []byte) (*status, error) {
+	if len(data) < 2 {
+		return nil, fmt.Errorf("Truncated status code option")
+	}
+
+	return &status{
+		code:    binary.BigEndian.Uint16(data[0:2]),
+		message: string(data[2:]),
+	}, nil
+}
+
+// parseIAPD parses the content of an IA_PD option.
+func parseIAPD(data []byte) (*iaPD, error) {
+	if len(data) < 12 {
+		return nil, fmt.Errorf("Truncated IA_PD option")
+	}
+
+	ia := &iaPD{
+		iaid: binary.BigEndian.Uint32(data[0:4]),
+		t1:   binary.BigEndian.Uint32(data[4:8]),
+		t2:   binary.BigEndian.Uint32(data[8:12]),
+	}
+
+	options, err := parseOptions(data[12:])
+	if err != nil {
+		return nil, err
+	}
+
+	for _, content := range options[layers.DHCPv6OptStatusCode] {
+		ia.status, err = parseStatus(content)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, content := range options[layers.DHCPv6OptIAPrefix] {
+		prefix, err := parseIAPrefix(content)
+		if err != nil {
+			return nil, err
+		}
+
+		ia.prefixes = append(ia.prefixes, *prefix)
+	}
+
+	return ia, nil
+}
+
+// parseIAPrefix parses the content of an IA Prefix option.
+func parseIAPrefix(data []byte) (*iaPrefix, error) {
+	if len(data) < 25 {
+		return nil, fmt.Errorf("Truncated IA Prefix option")
+	}
+
+	prefixLength := int(data[8])
+	if prefixLength > 128 {
+		return nil, fmt.Errorf("Invalid delegated prefix length %d", prefixLength)
+	}
+
+	mask := net.CIDRMask(prefixLength, 128)
+	ip := make(net.IP, net.IPv6len)
+	copy(ip, data[9:25])
+
+	p := &iaPrefix{
+		preferredLifetime: binary.BigEndian.Uint32(data[0:4]),
+		validLifetime:     binary.BigEndian.Uint32(data[4:8]),
+		prefix:            net.IPNet{IP: ip.Mask(mask), Mask: mask},
+	}
+
+	options, err := parseOptions(data[25:])
+	if err != nil {
+		return nil, err
+	}
+
+	for _, content := range options[layers.DHCPv6OptStatusCode] {
+		p.status, err = parseStatus(content)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
+
+// getOption returns the first option with the code supplied (nil if not found).
+func getOption(options layers.DHCPv6Options, code layers.DHCPv6Opt) *layers.DHCPv6Option {
+	for i := range options {
+		if options[i].Code == code {
+			return &options[i]
+		}
+	}
+
+	return nil
+}
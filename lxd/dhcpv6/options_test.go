@@ -0,0 +1,109 @@
+package dhcpv6
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket/layers"
+)
+
+func TestIAPDRoundTrip(t *testing.T) {
+	_, prefix, _ := net.ParseCIDR("2001:db8:1200::/56")
+
+	ia := iaPD{
+		iaid: 42,
+		t1:   1800,
+		t2:   2880,
+		prefixes: []iaPrefix{{
+			preferredLifetime: 3600,
+			validLifetime:     7200,
+			prefix:            *prefix,
+		}},
+	}
+
+	parsed, err := parseIAPD(ia.encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if parsed.iaid != 42 || parsed.t1 != 1800 || parsed.t2 != 2880 {
+		t.Fatalf("Unexpected IA_PD header: %+v", parsed)
+	}
+
+	if len(parsed.prefixes) != 1 {
+		t.Fatalf("Expected 1 prefix, got %d", len(parsed.prefixes))
+	}
+
+	p := parsed.prefixes[0]
+	if p.prefix.String() != "2001:db8:1200::/56" || p.preferredLifetime != 3600 || p.validLifetime != 7200 {
+		t.Fatalf("Unexpected IA Prefix: %+v", p)
+	}
+}
+
+func TestIAPDPrefixLengthHint(t *testing.T) {
+	ia := iaPD{iaid: 1, prefixes: []iaPrefix{{prefix: net.IPNet{Mask: net.CIDRMask(60, 128)}}}}
+
+	parsed, err := parseIAPD(ia.encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	size, _ := parsed.prefixes[0].prefix.Mask.Size()
+	if size != 60 || !parsed.prefixes[0].prefix.IP.Equal(net.IPv6zero) {
+		t.Fatalf("Unexpected prefix hint: %s", parsed.prefixes[0].prefix.String())
+	}
+}
+
+func TestParseIAPDTruncated(t *testing.T) {
+	ia := iaPD{iaid: 1, prefixes: []iaPrefix{{prefix: net.IPNet{Mask: net.CIDRMask(56, 128)}}}}
+	data := ia.encode()
+
+	_, err := parseIAPD(data[:len(data)-1])
+	if err == nil {
+		t.Fatal("Expected an error for a truncated IA_PD option")
+	}
+}
+
+func TestParseLease(t *testing.T) {
+	c := &Client{iaid: 7, duid: duidLL(net.HardwareAddr{0x00, 0x16, 0x3e, 0x00, 0x00, 0x01})}
+	_, prefix, _ := net.ParseCIDR("2001:db8:abcd::/48")
+
+	reply := func(ia iaPD) *layers.DHCPv6 {
+		return &layers.DHCPv6{
+			MsgType: layers.DHCPv6MsgTypeReply,
+			Options: layers.DHCPv6Options{
+				layers.NewDHCPv6Option(layers.DHCPv6OptServerID, []byte{0, 3, 0, 1, 1, 2, 3, 4, 5, 6}),
+				layers.NewDHCPv6Option(layers.DHCPv6OptClientID, c.duid),
+				layers.NewDHCPv6Option(layers.DHCPv6OptIAPD, ia.encode()),
+			},
+		}
+	}
+
+	// Renewal times are derived from the preferred lifetime when left to the client.
+	lease, err := c.parseLease(reply(iaPD{iaid: 7, prefixes: []iaPrefix{{preferredLifetime: 1000, validLifetime: 2000, prefix: *prefix}}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if lease.Prefix.String() != "2001:db8:abcd::/48" {
+		t.Fatalf("Unexpected prefix %s", lease.Prefix.String())
+	}
+
+	if lease.T1 != 500*time.Second || lease.T2 != 800*time.Second {
+		t.Fatalf("Unexpected renewal times T1=%s T2=%s", lease.T1, lease.T2)
+	}
+
+	// Prefixes for another IAID are ignored.
+	_, err = c.parseLease(reply(iaPD{iaid: 8, prefixes: []iaPrefix{{preferredLifetime: 1000, validLifetime: 2000, prefix: *prefix}}}))
+	if !errors.Is(err, errNoPrefix) {
+		t.Fatalf("Expected errNoPrefix, got %v", err)
+	}
+
+	// Expired prefixes are ignored.
+	_, err = c.parseLease(reply(iaPD{iaid: 7, prefixes: []iaPrefix{{prefix: *prefix}}}))
+	if !errors.Is(err, errNoPrefix) {
+		t.Fatalf("Expected errNoPrefix, got %v", err)
+	}
+}
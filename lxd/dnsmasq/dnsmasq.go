@@ -3,8 +3,10 @@ package dnsmasq
 import (
 	"bufio"
 	"fmt"
+	"hash/fnv"
 	"net"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
@@ -23,15 +25,30 @@ type DHCPAllocation struct {
 	IP             net.IP
 	StaticFileName string
 	MAC            net.HardwareAddr
+	Reservation    bool
 }
 
 // ConfigMutex used to coordinate access to the dnsmasq config files.
 var ConfigMutex sync.Mutex
 
 // UpdateStaticEntry writes a single dhcp-host line for a network/instance combination.
-func UpdateStaticEntry(network string, projectName string, instanceName string, deviceName string, netConfig map[string]string, hwaddr string, ipv4Address string, ipv6Address string) error {
+// If dhcpOptions are supplied then they are written to the device's DHCP options file and only sent to the device.
+func UpdateStaticEntry(network string, projectName string, instanceName string, deviceName string, netConfig map[string]string, hwaddr string, ipv4Address string, ipv6Address string, dhcpOptions []string) error {
 	hwaddr = strings.ToLower(hwaddr)
 	line := hwaddr
+	deviceStaticFileName := StaticAllocationFileName(projectName, instanceName, deviceName)
+
+	// Tag the host so that the device specific DHCP options only apply to it.
+	tag := ""
+	if len(dhcpOptions) > 0 {
+		tag = staticAllocationTag(deviceStaticFileName)
+		line += fmt.Sprintf(",set:%s", tag)
+	}
+
+	err := updateStaticOptions(network, deviceStaticFileName, tag, dhcpOptions)
+	if err != nil {
+		return err
+	}
 
 	// Generate the dhcp-host line
 	if ipv4Address != "" {
@@ -50,8 +67,7 @@ func UpdateStaticEntry(network string, projectName string, instanceName string,
 		return nil
 	}
 
-	deviceStaticFileName := StaticAllocationFileName(projectName, instanceName, deviceName)
-	err := os.WriteFile(shared.VarPath("networks", network, "dnsmasq.hosts", deviceStaticFileName), []byte(line+"\n"), 0644)
+	err = os.WriteFile(shared.VarPath("networks", network, "dnsmasq.hosts", deviceStaticFileName), []byte(line+"\n"), 0644)
 	if err != nil {
 		return err
 	}
@@ -67,6 +83,137 @@ func RemoveStaticEntry(network string, projectName string, instanceName string,
 		return err
 	}
 
+	err = os.Remove(filepath.Join(OptionsPath(network), deviceStaticFileName))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// OptionsPath returns the path to the directory holding the instance device specific DHCP options of a network.
+func OptionsPath(network string) string {
+	return shared.VarPath("networks", network, "dnsmasq.options")
+}
+
+// staticAllocationTag returns the dnsmasq tag used to match the DHCP options of an instance device.
+func staticAllocationTag(deviceStaticFileName string) string {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(deviceStaticFileName))
+
+	return fmt.Sprintf("lxd-%08x", hash.Sum32())
+}
+
+// updateStaticOptions writes the DHCP options file of an instance device, restricting the options to the tag.
+// The file is removed if there are no options.
+func updateStaticOptions(network string, deviceStaticFileName string, tag string, dhcpOptions []string) error {
+	optionsFile := filepath.Join(OptionsPath(network), deviceStaticFileName)
+
+	if len(dhcpOptions) == 0 {
+		err := os.Remove(optionsFile)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		return nil
+	}
+
+	if !shared.PathExists(OptionsPath(network)) {
+		return fmt.Errorf("DHCP options aren't available on network %q", network)
+	}
+
+	var content strings.Builder
+	for _, option := range dhcpOptions {
+		content.WriteString(fmt.Sprintf("tag:%s,%s\n", tag, option))
+	}
+
+	return os.WriteFile(optionsFile, []byte(content.String()), 0644)
+}
+
+// ReservationsPath returns the path to the directory holding the DHCP reservations of a network.
+// Reservations are kept separately from the instance static allocations as those are regenerated on startup.
+func ReservationsPath(network string) string {
+	return shared.VarPath("networks", network, "dnsmasq.reservations")
+}
+
+// ReservationOptionsPath returns the path to the directory holding the DHCP reservation specific DHCP options of a network.
+func ReservationOptionsPath(network string) string {
+	return shared.VarPath("networks", network, "dnsmasq.reservations.options")
+}
+
+// reservationTag returns the dnsmasq tag used to match the DHCP options of a reservation.
+func reservationTag(ip net.IP) string {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(ip.String()))
+
+	return fmt.Sprintf("lxd-reservation-%08x", hash.Sum32())
+}
+
+// reservationLine returns the dhcp-host line for a network DHCP reservation.
+func reservationLine(ip net.IP, hwaddr string, clientID string, hostname string, leaseTime string, tag string) string {
+	var line string
+	if hwaddr != "" {
+		line = strings.ToLower(hwaddr)
+	} else {
+		line = fmt.Sprintf("id:%s", clientID)
+	}
+
+	if tag != "" {
+		line += fmt.Sprintf(",set:%s", tag)
+	}
+
+	if ip.To4() != nil {
+		line += fmt.Sprintf(",%s", ip.String())
+	} else {
+		line += fmt.Sprintf(",[%s]", ip.String())
+	}
+
+	if hostname != "" {
+		line += fmt.Sprintf(",%s", hostname)
+	}
+
+	if leaseTime != "" {
+		line += fmt.Sprintf(",%s", leaseTime)
+	}
+
+	return line
+}
+
+// UpdateReservation writes the dhcp-host line and DHCP options for a network DHCP reservation.
+// The host is identified by its MAC address or, if hwaddr is empty, by its DHCP client identifier.
+func UpdateReservation(network string, address string, hwaddr string, clientID string, hostname string, leaseTime string, dhcpOptions []string) error {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return fmt.Errorf("Invalid reservation address %q", address)
+	}
+
+	// Tag the host so that the reservation specific DHCP options only apply to it.
+	tag := ""
+	if len(dhcpOptions) > 0 {
+		tag = reservationTag(ip)
+
+		if !shared.PathExists(ReservationOptionsPath(network)) {
+			return fmt.Errorf("DHCP options aren't available on network %q", network)
+		}
+
+		var content strings.Builder
+		for _, option := range dhcpOptions {
+			content.WriteString(fmt.Sprintf("tag:%s,%s\n", tag, option))
+		}
+
+		err := os.WriteFile(filepath.Join(ReservationOptionsPath(network), ip.String()), []byte(content.String()), 0644)
+		if err != nil {
+			return err
+		}
+	}
+
+	line := reservationLine(ip, hwaddr, clientID, hostname, leaseTime, tag)
+
+	err := os.WriteFile(filepath.Join(ReservationsPath(network), ip.String()), []byte(line+"\n"), 0644)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -123,10 +270,15 @@ func DHCPStaticAllocationPath(network string, deviceStaticFileName string) strin
 // DHCPStaticAllocation retrieves the dnsmasq statically allocated MAC and IPs for an instance device static file.
 // Returns MAC, IPv4 and IPv6 DHCPAllocation structs respectively.
 func DHCPStaticAllocation(network string, deviceStaticFileName string) (net.HardwareAddr, DHCPAllocation, DHCPAllocation, error) {
+	return dhcpHostFileAllocation(DHCPStaticAllocationPath(network, deviceStaticFileName), deviceStaticFileName)
+}
+
+// dhcpHostFileAllocation parses the MAC and IPs from a dnsmasq dhcp-host file.
+func dhcpHostFileAllocation(path string, deviceStaticFileName string) (net.HardwareAddr, DHCPAllocation, DHCPAllocation, error) {
 	var IPv4, IPv6 DHCPAllocation
 	var mac net.HardwareAddr
 
-	file, err := os.Open(DHCPStaticAllocationPath(network, deviceStaticFileName))
+	file, err := os.Open(path)
 	if err != nil {
 		return nil, IPv4, IPv6, err
 	}
@@ -137,6 +289,11 @@ func DHCPStaticAllocation(network string, deviceStaticFileName string) (net.Hard
 	for scanner.Scan() {
 		fields := strings.SplitN(scanner.Text(), ",", -1)
 		for _, field := range fields {
+			// Skip client identifiers as these can look like MAC addresses.
+			if strings.HasPrefix(field, "id:") {
+				continue
+			}
+
 			// Check if field is IPv4 or IPv6 address.
 			if strings.Count(field, ".") == 3 {
 				IP := net.ParseIP(field)
@@ -211,6 +368,33 @@ func DHCPAllAllocations(network string) (map[[4]byte]DHCPAllocation, map[[16]byt
 		}
 	}
 
+	// Then read all reserved IPs, these are kept so that they aren't allocated to instances.
+	files, err = os.ReadDir(ReservationsPath(network))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, nil, err
+	}
+
+	for _, entry := range files {
+		_, IPv4, IPv6, err := dhcpHostFileAllocation(filepath.Join(ReservationsPath(network), entry.Name()), "")
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if IPv4.IP != nil {
+			var IPKey [4]byte
+			copy(IPKey[:], IPv4.IP.To4())
+			IPv4.Reservation = true
+			IPv4s[IPKey] = IPv4
+		}
+
+		if IPv6.IP != nil {
+			var IPKey [16]byte
+			copy(IPKey[:], IPv6.IP.To16())
+			IPv6.Reservation = true
+			IPv6s[IPKey] = IPv6
+		}
+	}
+
 	// Next read all dynamic allocated IPs.
 	file, err := os.Open(shared.VarPath("networks", network, "dnsmasq.leases"))
 	if err != nil {
@@ -234,7 +418,7 @@ func DHCPAllAllocations(network string) (map[[4]byte]DHCPAllocation, map[[16]byt
 				copy(IPKey[:], IP.To16())
 
 				// Don't replace IPs from static config as more reliable.
-				if IPv6s[IPKey].StaticFileName != "" {
+				if IPv6s[IPKey].StaticFileName != "" || IPv6s[IPKey].Reservation {
 					continue
 				}
 
@@ -252,7 +436,7 @@ func DHCPAllAllocations(network string) (map[[4]byte]DHCPAllocation, map[[16]byt
 				copy(IPKey[:], IP.To4())
 
 				// Don't replace IPs from static config as more reliable.
-				if IPv4s[IPKey].StaticFileName != "" {
+				if IPv4s[IPKey].StaticFileName != "" || IPv4s[IPKey].Reservation {
 					continue
 				}
 
@@ -1,6 +1,7 @@
 package dnsmasq
 
 import (
+	"net"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -13,3 +14,45 @@ func Test_staticAllocationFileName(t *testing.T) {
 	fileName := StaticAllocationFileName(projectName, instanceName, deviceName)
 	assert.Equal(t, "test.project_test-instance.test-.--_----.device", fileName)
 }
+
+func Test_reservationLine(t *testing.T) {
+	tests := []struct {
+		address   string
+		hwaddr    string
+		clientID  string
+		hostname  string
+		leaseTime string
+		tag       string
+		want      string
+	}{
+		{
+			address: "192.0.2.10",
+			hwaddr:  "00:16:3E:00:00:01",
+			want:    "00:16:3e:00:00:01,192.0.2.10",
+		},
+		{
+			address:   "fd42::10",
+			clientID:  "01:02:03",
+			hostname:  "foo",
+			leaseTime: "infinite",
+			want:      "id:01:02:03,[fd42::10],foo,infinite",
+		},
+		{
+			address:  "192.0.2.10",
+			hwaddr:   "00:16:3e:00:00:01",
+			hostname: "foo",
+			tag:      reservationTag(net.ParseIP("192.0.2.10")),
+			want:     "00:16:3e:00:00:01,set:" + reservationTag(net.ParseIP("192.0.2.10")) + ",192.0.2.10,foo",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			line := reservationLine(net.ParseIP(tt.address), tt.hwaddr, tt.clientID, tt.hostname, tt.leaseTime, tt.tag)
+			assert.Equal(t, tt.want, line)
+		})
+	}
+
+	// Each reservation gets its own tag.
+	assert.NotEqual(t, reservationTag(net.ParseIP("192.0.2.10")), reservationTag(net.ParseIP("192.0.2.11")))
+}
@@ -32,6 +32,7 @@ type ACLRule struct {
 	Action          string
 	Log             bool   // Whether or not to log matched packets.
 	LogName         string // Log label name (requires Log be true).
+	CounterName     string // Counter label name. If set, matched packets and bytes are counted.
 	Source          string
 	Destination     string
 	Protocol        string
@@ -39,6 +40,14 @@ type ACLRule struct {
 	DestinationPort string
 	ICMPType        string
 	ICMPCode        string
+	RateLimit       string // Packet rate limit in the format "<packets>/<unit>". Packets exceeding it don't match.
+	ConnectionLimit string // Maximum concurrent connections per source address. Connections exceeding it don't match.
+}
+
+// ACLRuleCounters represents the packet and byte counters of one or more ACL rules sharing a counter name.
+type ACLRuleCounters struct {
+	Packets uint64
+	Bytes   uint64
 }
 
 // AddressForward represents a NAT address forward.
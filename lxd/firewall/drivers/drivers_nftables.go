@@ -2,6 +2,7 @@ package drivers
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
@@ -126,11 +127,11 @@ func (d Nftables) Compat() (bool, error) {
 
 // nftGenericItem represents some common fields amongst the different nftables types.
 type nftGenericItem struct {
-	ItemType string `json:"-"`      // Type of item (table, chain or rule). Populated by LXD.
+	ItemType string `json:"-"`      // Type of item (table, chain, set, limit or rule). Populated by LXD.
 	Family   string `json:"family"` // Family of item (ip, ip6, bridge etc).
 	Table    string `json:"table"`  // Table the item belongs to (for chains and rules).
 	Chain    string `json:"chain"`  // Chain the item belongs to (for rules).
-	Name     string `json:"name"`   // Name of item (for tables and chains).
+	Name     string `json:"name"`   // Name of item (for tables, chains, sets and limits).
 }
 
 // nftParseRuleset parses the ruleset and returns the generic parts as a slice of items.
@@ -163,6 +164,8 @@ func (d Nftables) nftParseRuleset() ([]nftGenericItem, error) {
 	for _, item := range v.Nftables {
 		rule, foundRule := item["rule"]
 		chain, foundChain := item["chain"]
+		set, foundSet := item["set"]
+		limit, foundLimit := item["limit"]
 		table, foundTable := item["table"]
 		if foundRule {
 			rule.ItemType = "rule"
@@ -170,6 +173,12 @@ func (d Nftables) nftParseRuleset() ([]nftGenericItem, error) {
 		} else if foundChain {
 			chain.ItemType = "chain"
 			items = append(items, chain)
+		} else if foundSet {
+			set.ItemType = "set"
+			items = append(items, set)
+		} else if foundLimit {
+			limit.ItemType = "limit"
+			items = append(items, limit)
 		} else if foundTable {
 			table.ItemType = "table"
 			items = append(items, table)
@@ -373,6 +382,12 @@ func (d Nftables) NetworkClear(networkName string, _ bool, _ []uint) error {
 		return fmt.Errorf("Failed clearing nftables rules for network %q: %w", networkName, err)
 	}
 
+	// Remove sets used by ACL rule connection limits (now that the rules referencing them are gone).
+	err = d.removeSets("inet", d.aclConnLimitSetPrefix(networkName))
+	if err != nil {
+		return fmt.Errorf("Failed clearing nftables sets for network %q: %w", networkName, err)
+	}
+
 	return nil
 }
 
@@ -627,6 +642,27 @@ func (d Nftables) removeChains(families []string, chainSuffix string, chains ...
 	return nil
 }
 
+// removeSets removes the sets whose name starts with the specified prefix, except those listed in keepSets.
+func (d Nftables) removeSets(family string, setPrefix string, keepSets ...string) error {
+	ruleset, err := d.nftParseRuleset()
+	if err != nil {
+		return err
+	}
+
+	for _, item := range ruleset {
+		if item.ItemType != "set" || item.Family != family || item.Table != nftablesNamespace || !strings.HasPrefix(item.Name, setPrefix) || shared.ValueInSlice(item.Name, keepSets) {
+			continue
+		}
+
+		_, err = shared.RunCommand("nft", "delete", "set", item.Family, nftablesNamespace, item.Name)
+		if err != nil {
+			return fmt.Errorf("Failed deleting nftables set %q (%s): %w", item.Name, item.Family, err)
+		}
+	}
+
+	return nil
+}
+
 // InstanceSetupRPFilter activates reverse path filtering for the specified instance device on the host interface.
 func (d Nftables) InstanceSetupRPFilter(projectName string, instanceName string, deviceName string, hostName string) error {
 	deviceLabel := d.instanceDeviceLabel(projectName, instanceName, deviceName)
@@ -696,24 +732,199 @@ func (d Nftables) InstanceClearNetPrio(projectName string, instanceName string,
 	return nil
 }
 
+// InstanceSetupSNAT applies the SNAT rules translating the outbound traffic of the instance device to its own
+// SNAT addresses rather than those of the network.
+func (d Nftables) InstanceSetupSNAT(projectName string, instanceName string, deviceName string, networkName string, SNATV4 *SNATOpts, SNATV6 *SNATOpts) error {
+	deviceLabel := d.instanceDeviceLabel(projectName, instanceName, deviceName)
+
+	err := d.removeChains([]string{"inet"}, deviceLabel, "snat")
+	if err != nil {
+		return fmt.Errorf("Failed clearing SNAT rules for instance device %q: %w", deviceLabel, err)
+	}
+
+	rules := make(map[string]*SNATOpts, 0)
+
+	if SNATV4 != nil {
+		rules["ip"] = SNATV4
+	}
+
+	if SNATV6 != nil {
+		rules["ip6"] = SNATV6
+	}
+
+	if len(rules) == 0 {
+		return nil
+	}
+
+	tplFields := map[string]any{
+		"namespace":      nftablesNamespace,
+		"family":         "inet",
+		"chainSeparator": nftablesChainSeparator,
+		"deviceLabel":    deviceLabel,
+		"networkName":    networkName,
+		"rules":          rules,
+	}
+
+	err = d.applyNftConfig(nftablesInstanceSNAT, tplFields)
+	if err != nil {
+		return fmt.Errorf("Failed adding SNAT rules for instance device %q: %w", deviceLabel, err)
+	}
+
+	return nil
+}
+
+// InstanceClearSNAT removes the SNAT rules of the instance device.
+func (d Nftables) InstanceClearSNAT(projectName string, instanceName string, deviceName string) error {
+	deviceLabel := d.instanceDeviceLabel(projectName, instanceName, deviceName)
+
+	err := d.removeChains([]string{"inet"}, deviceLabel, "snat")
+	if err != nil {
+		return fmt.Errorf("Failed clearing SNAT rules for instance device %q: %w", deviceLabel, err)
+	}
+
+	return nil
+}
+
+// netLimitName returns the name of the named limit used for the project's aggregate rate limit in the direction
+// specified. The rate is part of the name so that changing it creates a new limit rather than altering one in use.
+func (d Nftables) netLimitName(projectName string, direction string, rate int64) string {
+	return "netlimit" + nftablesChainSeparator + projectName + nftablesChainSeparator + direction + nftablesChainSeparator + strconv.FormatInt(rate, 10)
+}
+
+// netLimitBytesRate converts a rate in bits per second to the bytes per second rate used by nftables.
+func (d Nftables) netLimitBytesRate(rate int64) int64 {
+	return max(rate/8, 1)
+}
+
+// InstanceSetupNetLimits applies the aggregate rate limits of the project to the specified instance device on the
+// host interface. The rates are in bits per second, with 0 meaning unlimited.
+// The limits of the project that are no longer used are left in place, see ProjectClearUnusedNetLimits.
+func (d Nftables) InstanceSetupNetLimits(projectName string, instanceName string, deviceName string, ingressRate int64, egressRate int64) error {
+	deviceLabel := d.instanceDeviceLabel(projectName, instanceName, deviceName)
+	chainLabel := "netlimit" + nftablesChainSeparator + deviceLabel
+
+	err := d.removeChains([]string{"netdev"}, chainLabel, "egress", "ingress")
+	if err != nil {
+		return fmt.Errorf("Failed clearing network limits rules for instance device %q: %w", deviceLabel, err)
+	}
+
+	tplFields := map[string]any{
+		"namespace":      nftablesNamespace,
+		"family":         "netdev",
+		"chainSeparator": nftablesChainSeparator,
+		"deviceLabel":    deviceLabel,
+		"deviceName":     deviceName,
+	}
+
+	if ingressRate > 0 {
+		ingressRate = d.netLimitBytesRate(ingressRate)
+		tplFields["ingressLimit"] = d.netLimitName(projectName, "ingress", ingressRate)
+		tplFields["ingressRate"] = ingressRate
+	}
+
+	if egressRate > 0 {
+		egressRate = d.netLimitBytesRate(egressRate)
+		tplFields["egressLimit"] = d.netLimitName(projectName, "egress", egressRate)
+		tplFields["egressRate"] = egressRate
+	}
+
+	if ingressRate > 0 || egressRate > 0 {
+		err = d.applyNftConfig(nftablesInstanceNetLimits, tplFields)
+		if err != nil {
+			return fmt.Errorf("Failed adding network limits rules for instance device %q: %w", deviceLabel, err)
+		}
+	}
+
+	return nil
+}
+
+// InstanceClearNetLimits removes the aggregate rate limits of the project from the specified instance device.
+func (d Nftables) InstanceClearNetLimits(projectName string, instanceName string, deviceName string) error {
+	if deviceName == "" {
+		return fmt.Errorf("Failed clearing network limits rules for instance %q in project %q: device name is empty", instanceName, projectName)
+	}
+
+	deviceLabel := d.instanceDeviceLabel(projectName, instanceName, deviceName)
+	chainLabel := "netlimit" + nftablesChainSeparator + deviceLabel
+
+	err := d.removeChains([]string{"netdev"}, chainLabel, "egress", "ingress")
+	if err != nil {
+		return fmt.Errorf("Failed clearing network limits rules for instance device %q: %w", deviceLabel, err)
+	}
+
+	return nil
+}
+
+// ProjectClearUnusedNetLimits removes the named limits of the project other than those for the specified rates.
+// Limits still referenced by the devices of other instances cannot be removed and are left in place.
+func (d Nftables) ProjectClearUnusedNetLimits(projectName string, ingressRate int64, egressRate int64) error {
+	keepLimits := []string{}
+
+	if ingressRate > 0 {
+		keepLimits = append(keepLimits, d.netLimitName(projectName, "ingress", d.netLimitBytesRate(ingressRate)))
+	}
+
+	if egressRate > 0 {
+		keepLimits = append(keepLimits, d.netLimitName(projectName, "egress", d.netLimitBytesRate(egressRate)))
+	}
+
+	ruleset, err := d.nftParseRuleset()
+	if err != nil {
+		return err
+	}
+
+	prefix := "netlimit" + nftablesChainSeparator + projectName + nftablesChainSeparator
+	for _, item := range ruleset {
+		if item.ItemType != "limit" || item.Family != "netdev" || item.Table != nftablesNamespace || !strings.HasPrefix(item.Name, prefix) || shared.ValueInSlice(item.Name, keepLimits) {
+			continue
+		}
+
+		_, _ = shared.RunCommand("nft", "delete", "limit", item.Family, nftablesNamespace, item.Name)
+	}
+
+	return nil
+}
+
 // NetworkApplyACLRules applies ACL rules to the existing firewall chains.
 func (d Nftables) NetworkApplyACLRules(networkName string, rules []ACLRule) error {
 	nftRules := make([]string, 0)
+	connLimitSets := make([]map[string]string, 0)
+	connLimitSetNames := make([]string, 0)
+
+	// addConnLimitSet records the set needed to track the connections of a rule using a connection limit.
+	addConnLimitSet := func(rule *ACLRule, ipVersion uint, setName string) {
+		// Rules with the same criteria share a set, as they track the same connections.
+		if rule.ConnectionLimit == "" || shared.ValueInSlice(setName, connLimitSetNames) {
+			return
+		}
+
+		setType := "ipv4_addr"
+		if ipVersion == 6 {
+			setType = "ipv6_addr"
+		}
+
+		connLimitSets = append(connLimitSets, map[string]string{"name": setName, "type": setType})
+		connLimitSetNames = append(connLimitSetNames, setName)
+	}
+
 	for _, rule := range rules {
 		// First try generating rules with IPv4 or IP agnostic criteria.
-		nftRule, partial, err := d.aclRuleCriteriaToRules(networkName, 4, &rule)
+		connLimitSetName := d.aclConnLimitSetName(networkName, &rule, 4)
+		nftRule, partial, err := d.aclRuleCriteriaToRules(networkName, 4, connLimitSetName, &rule)
 		if err != nil {
 			return err
 		}
 
 		if nftRule != "" {
 			nftRules = append(nftRules, nftRule)
+			addConnLimitSet(&rule, 4, connLimitSetName)
 		}
 
 		if partial {
 			// If we couldn't fully generate the ruleset with only IPv4 or IP agnostic criteria, then
 			// fill in the remaining parts using IPv6 criteria.
-			nftRule, _, err = d.aclRuleCriteriaToRules(networkName, 6, &rule)
+			connLimitSetName = d.aclConnLimitSetName(networkName, &rule, 6)
+			nftRule, _, err = d.aclRuleCriteriaToRules(networkName, 6, connLimitSetName, &rule)
 			if err != nil {
 				return err
 			}
@@ -723,6 +934,7 @@ func (d Nftables) NetworkApplyACLRules(networkName string, rules []ACLRule) erro
 			}
 
 			nftRules = append(nftRules, nftRule)
+			addConnLimitSet(&rule, 6, connLimitSetName)
 		} else if nftRule == "" {
 			return fmt.Errorf("Invalid empty rule generated")
 		}
@@ -734,6 +946,7 @@ func (d Nftables) NetworkApplyACLRules(networkName string, rules []ACLRule) erro
 		"networkName":    networkName,
 		"family":         "inet",
 		"rules":          nftRules,
+		"connLimitSets":  connLimitSets,
 	}
 
 	config := &strings.Builder{}
@@ -747,11 +960,92 @@ func (d Nftables) NetworkApplyACLRules(networkName string, rules []ACLRule) erro
 		return err
 	}
 
+	// Remove the sets of connection limited rules that have been removed or changed.
+	err = d.removeSets("inet", d.aclConnLimitSetPrefix(networkName), connLimitSetNames...)
+	if err != nil {
+		return fmt.Errorf("Failed removing unused nftables sets for network %q: %w", networkName, err)
+	}
+
+	return nil
+}
+
+// NetworkApplyPeers registers the bridge network with the firewall and applies its isolation rules.
+// When isolated, forwarded traffic between the network and the other bridge networks is dropped, except for the
+// peer networks specified.
+func (d Nftables) NetworkApplyPeers(networkName string, isolated bool, peerNetworks []string) error {
+	tplFields := map[string]any{
+		"namespace":      nftablesNamespace,
+		"chainSeparator": nftablesChainSeparator,
+		"networkName":    networkName,
+		"family":         "inet",
+		"isolated":       isolated,
+		"peers":          peerNetworks,
+	}
+
+	config := &strings.Builder{}
+	err := nftablesNetPeers.Execute(config, tplFields)
+	if err != nil {
+		return fmt.Errorf("Failed running %q template: %w", nftablesNetPeers.Name(), err)
+	}
+
+	err = shared.RunCommandWithFds(context.TODO(), strings.NewReader(config.String()), nil, "nft", "-f", "-")
+	if err != nil {
+		return fmt.Errorf("Failed applying peering rules for network %q: %w", networkName, err)
+	}
+
+	return nil
+}
+
+// NetworkClearPeers removes the isolation rules of the bridge network and unregisters it from the firewall.
+func (d Nftables) NetworkClearPeers(networkName string) error {
+	err := d.removeChains([]string{"inet"}, networkName, "peer")
+	if err != nil {
+		return fmt.Errorf("Failed clearing peering rules for network %q: %w", networkName, err)
+	}
+
+	tplFields := map[string]any{
+		"namespace":   nftablesNamespace,
+		"networkName": networkName,
+		"family":      "inet",
+	}
+
+	config := &strings.Builder{}
+	err = nftablesNetPeersClear.Execute(config, tplFields)
+	if err != nil {
+		return fmt.Errorf("Failed running %q template: %w", nftablesNetPeersClear.Name(), err)
+	}
+
+	err = shared.RunCommandWithFds(context.TODO(), strings.NewReader(config.String()), nil, "nft", "-f", "-")
+	if err != nil {
+		return fmt.Errorf("Failed clearing peering rules for network %q: %w", networkName, err)
+	}
+
 	return nil
 }
 
+// aclConnLimitSetPrefix returns the prefix of the names of the sets used by the connection limited ACL rules of
+// the network.
+func (d Nftables) aclConnLimitSetPrefix(networkName string) string {
+	return "aclconn" + nftablesChainSeparator + networkName + nftablesChainSeparator
+}
+
+// aclConnLimitSetName returns the name of the set used to track the connections per source address of an ACL rule.
+// The name is derived from the rule itself rather than its position, so that the tracked connections are kept
+// when rules are added, removed or reordered, and a changed rule starts with a new set.
+func (d Nftables) aclConnLimitSetName(networkName string, rule *ACLRule, ipVersion uint) string {
+	ruleKey := *rule
+	ruleKey.CounterName = ""
+	ruleKey.Log = false
+	ruleKey.LogName = ""
+
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%+v", ruleKey)))
+
+	return fmt.Sprintf("%s%x%sv%d", d.aclConnLimitSetPrefix(networkName), hash[:8], nftablesChainSeparator, ipVersion)
+}
+
 // aclRuleCriteriaToRules converts an ACL rule into 1 or more nftables rules.
-func (d Nftables) aclRuleCriteriaToRules(networkName string, ipVersion uint, rule *ACLRule) (string, bool, error) {
+// The connLimitSetName is the name of the set used to track connections if the rule has a connection limit.
+func (d Nftables) aclRuleCriteriaToRules(networkName string, ipVersion uint, connLimitSetName string, rule *ACLRule) (string, bool, error) {
 	var args []string
 
 	if rule.Direction == "ingress" {
@@ -846,6 +1140,27 @@ func (d Nftables) aclRuleCriteriaToRules(networkName string, ipVersion uint, rul
 		}
 	}
 
+	// Handle connection limit.
+	if rule.ConnectionLimit != "" {
+		// Connections are tracked per source address, so a rule without any IP family specific criteria
+		// needs generating for each IP family.
+		if rule.Source == "" && rule.Destination == "" && !shared.ValueInSlice(rule.Protocol, []string{"icmp4", "icmp6"}) {
+			isPartialRule = true
+		}
+
+		family := "ip"
+		if ipVersion == 6 {
+			family = "ip6"
+		}
+
+		args = append(args, "ct", "state", "new", "add", "@"+connLimitSetName, "{", family, "saddr", "ct", "count", rule.ConnectionLimit, "}")
+	}
+
+	// Handle rate limit.
+	if rule.RateLimit != "" {
+		args = append(args, "limit", "rate", rule.RateLimit)
+	}
+
 	// Handle logging.
 	if rule.Log {
 		args = append(args, "log")
@@ -856,6 +1171,11 @@ func (d Nftables) aclRuleCriteriaToRules(networkName string, ipVersion uint, rul
 		}
 	}
 
+	// Handle counting.
+	if rule.CounterName != "" {
+		args = append(args, "counter")
+	}
+
 	// Handle action.
 	action := rule.Action
 	if action == "allow" {
@@ -864,9 +1184,65 @@ func (d Nftables) aclRuleCriteriaToRules(networkName string, ipVersion uint, rul
 
 	args = append(args, action)
 
+	// Add the counter name as a comment so the rule's counter can be identified later.
+	if rule.CounterName != "" {
+		args = append(args, "comment", `"`+rule.CounterName+`"`)
+	}
+
 	return strings.Join(args, " "), isPartialRule, nil
 }
 
+// NetworkACLRuleCounters returns the counters of the ACL rules applied to the network, keyed on counter name.
+// Counters of rules sharing the same counter name (such as the IPv4 and IPv6 variants of a rule) are summed.
+func (d Nftables) NetworkACLRuleCounters(networkName string) (map[string]ACLRuleCounters, error) {
+	chain := "acl" + nftablesChainSeparator + networkName
+
+	// Dump chain as JSON. Use -nn flags to avoid doing DNS lookups of IPs mentioned in any rules.
+	output, err := shared.RunCommandCLocale("nft", "--json", "-nn", "list", "chain", "inet", nftablesNamespace, chain)
+	if err != nil {
+		return nil, fmt.Errorf("Failed listing nftables chain %q: %w", chain, err)
+	}
+
+	// This only extracts the rule comments and counter expressions, see man libnftables-json for more info.
+	v := &struct {
+		Nftables []struct {
+			Rule *struct {
+				Comment string           `json:"comment"`
+				Expr    []map[string]any `json:"expr"`
+			} `json:"rule"`
+		} `json:"nftables"`
+	}{}
+
+	err = json.Unmarshal([]byte(output), v)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing nftables chain %q: %w", chain, err)
+	}
+
+	counters := make(map[string]ACLRuleCounters)
+	for _, item := range v.Nftables {
+		if item.Rule == nil || item.Rule.Comment == "" {
+			continue
+		}
+
+		for _, expr := range item.Rule.Expr {
+			counter, ok := expr["counter"].(map[string]any)
+			if !ok {
+				continue
+			}
+
+			packets, _ := counter["packets"].(float64)
+			bytes, _ := counter["bytes"].(float64)
+
+			ruleCounters := counters[item.Rule.Comment]
+			ruleCounters.Packets += uint64(packets)
+			ruleCounters.Bytes += uint64(bytes)
+			counters[item.Rule.Comment] = ruleCounters
+		}
+	}
+
+	return counters, nil
+}
+
 // aclRuleSubjectToACLMatch converts direction (source/destination) and subject criteria list into xtables args.
 // Returns nil if none of the subjects are appropriate for the ipVersion.
 func (d Nftables) aclRuleSubjectToACLMatch(direction string, ipVersion uint, subjectCriteria ...string) ([]string, bool, error) {
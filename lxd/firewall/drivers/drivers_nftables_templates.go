@@ -173,6 +173,9 @@ table {{.family}} {{.namespace}} {
 `))
 
 var nftablesNetACLRules = template.Must(template.New("nftablesNetACLRules").Parse(`
+{{- range .connLimitSets}}
+add set {{$.family}} {{$.namespace}} {{.name}} { type {{.type}}; flags dynamic; }
+{{- end}}
 flush chain {{.family}} {{.namespace}} acl{{.chainSeparator}}{{.networkName}}
 
 table {{.family}} {{.namespace}} {
@@ -186,6 +189,39 @@ table {{.family}} {{.namespace}} {
 }
 `))
 
+// nftablesNetPeers defines the rules isolating a bridge network from the other bridge networks on the host, except
+// for its peers. All bridge networks register themselves in the bridges set so that isolated networks can tell them
+// apart from the other interfaces. Accepting traffic in this chain doesn't bypass the ACL chains of the networks.
+var nftablesNetPeers = template.Must(template.New("nftablesNetPeers").Parse(`
+add table {{.family}} {{.namespace}}
+add set {{.family}} {{.namespace}} bridges { type ifname; }
+add element {{.family}} {{.namespace}} bridges { "{{.networkName}}" }
+add chain {{.family}} {{.namespace}} peer{{.chainSeparator}}{{.networkName}} {type filter hook forward priority filter; policy accept;}
+flush chain {{.family}} {{.namespace}} peer{{.chainSeparator}}{{.networkName}}
+
+{{if .isolated -}}
+table {{.family}} {{.namespace}} {
+	chain peer{{.chainSeparator}}{{.networkName}} {
+		{{- range .peers}}
+		iifname "{{$.networkName}}" oifname "{{.}}" accept
+		oifname "{{$.networkName}}" iifname "{{.}}" accept
+		{{- end}}
+		iifname "{{.networkName}}" oifname != "{{.networkName}}" oifname @bridges drop
+		oifname "{{.networkName}}" iifname != "{{.networkName}}" iifname @bridges drop
+	}
+}
+{{- end}}
+`))
+
+// nftablesNetPeersClear removes the bridge network from the bridges set.
+// The element is added first so that deleting it doesn't fail if it isn't in the set.
+var nftablesNetPeersClear = template.Must(template.New("nftablesNetPeersClear").Parse(`
+add table {{.family}} {{.namespace}}
+add set {{.family}} {{.namespace}} bridges { type ifname; }
+add element {{.family}} {{.namespace}} bridges { "{{.networkName}}" }
+delete element {{.family}} {{.namespace}} bridges { "{{.networkName}}" }
+`))
+
 // nftablesInstanceBridgeFilter defines the rules needed for MAC, IPv4 and IPv6 bridge security filtering.
 // To prevent instances from using IPs that are different from their assigned IPs we use ARP and NDP filtering
 // to prevent neighbour advertisements that are not allowed. However in order for DHCPv4 & DHCPv6 to work back to
@@ -273,6 +309,46 @@ chain prert{{.chainSeparator}}{{.deviceLabel}} {
 }
 `))
 
+// nftablesInstanceSNAT defines the rules translating the outbound traffic of an instance device to its own SNAT
+// addresses. The chain runs before the network's outbound NAT chain, so that these rules take precedence.
+var nftablesInstanceSNAT = template.Must(template.New("nftablesInstanceSNAT").Parse(`
+chain snat{{.chainSeparator}}{{.deviceLabel}} {
+	type nat hook postrouting priority 99; policy accept;
+
+	{{- range $ipFamily, $config := .rules}}
+	{{$ipFamily}} saddr {{$config.Subnet}} oifname != {{$.networkName}} snat {{$config.SNATAddress}}
+	{{- end}}
+}
+`))
+
+// nftablesInstanceNetLimits defines the rules enforcing the aggregate rate limits of a project on an instance device.
+// The named limits are shared by the devices of all the instances of the project, so the rates apply to the total
+// traffic of the project. Traffic sent to the instance leaves through the egress hook of the host interface and
+// traffic sent by the instance enters through its ingress hook.
+var nftablesInstanceNetLimits = template.Must(template.New("nftablesInstanceNetLimits").Parse(`
+{{- if .ingressLimit}}
+limit {{.ingressLimit}} {
+	rate over {{.ingressRate}} bytes/second
+}
+
+chain egress{{.chainSeparator}}netlimit{{.chainSeparator}}{{.deviceLabel}} {
+	type filter hook egress device "{{.deviceName}}" priority 0;
+	limit name "{{.ingressLimit}}" drop
+}
+{{- end}}
+{{- if .egressLimit}}
+
+limit {{.egressLimit}} {
+	rate over {{.egressRate}} bytes/second
+}
+
+chain ingress{{.chainSeparator}}netlimit{{.chainSeparator}}{{.deviceLabel}} {
+	type filter hook ingress device "{{.deviceName}}" priority 0;
+	limit name "{{.egressLimit}}" drop
+}
+{{- end}}
+`))
+
 // nftablesInstanceNetPrio defines the rules to perform setting of skb->priority.
 var nftablesInstanceNetPrio = template.Must(template.New("nftablesInstanceNetPrio").Parse(`
 chain egress{{.chainSeparator}}netprio{{.chainSeparator}}{{.deviceLabel}} {
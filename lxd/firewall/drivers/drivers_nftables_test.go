@@ -0,0 +1,76 @@
+package drivers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_nftablesNetPeers(t *testing.T) {
+	dropRules := []string{
+		`iifname "lxdbr1" oifname != "lxdbr1" oifname @bridges drop`,
+		`oifname "lxdbr1" iifname != "lxdbr1" iifname @bridges drop`,
+	}
+
+	tests := []struct {
+		name     string
+		isolated bool
+		peers    []string
+		want     []string
+		wantNot  []string
+	}{
+		{
+			name:    "Not isolated",
+			want:    []string{`add element inet lxd bridges { "lxdbr1" }`, `add chain inet lxd peer.lxdbr1 {type filter hook forward priority filter; policy accept;}`},
+			wantNot: append([]string{"accept\n"}, dropRules...),
+		},
+		{
+			name:     "Isolated",
+			isolated: true,
+			want:     dropRules,
+			wantNot:  []string{`oifname "lxdbr2" accept`},
+		},
+		{
+			name:     "Isolated with peers",
+			isolated: true,
+			peers:    []string{"lxdbr2", "lxdbr3"},
+			want: append([]string{
+				`iifname "lxdbr1" oifname "lxdbr2" accept`,
+				`oifname "lxdbr1" iifname "lxdbr2" accept`,
+				`iifname "lxdbr1" oifname "lxdbr3" accept`,
+				`oifname "lxdbr1" iifname "lxdbr3" accept`,
+			}, dropRules...),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tplFields := map[string]any{
+				"namespace":      nftablesNamespace,
+				"chainSeparator": nftablesChainSeparator,
+				"networkName":    "lxdbr1",
+				"family":         "inet",
+				"isolated":       tt.isolated,
+				"peers":          tt.peers,
+			}
+
+			config := &strings.Builder{}
+			require.NoError(t, nftablesNetPeers.Execute(config, tplFields))
+
+			// Check the rules are present, in order, so that the peers are accepted before the other bridges are dropped.
+			rules := config.String()
+			offset := 0
+			for _, rule := range tt.want {
+				index := strings.Index(rules[offset:], rule)
+				require.NotEqual(t, -1, index, "Missing or misplaced rule %q in:\n%s", rule, rules)
+				offset += index + len(rule)
+			}
+
+			for _, rule := range tt.wantNot {
+				assert.NotContains(t, rules, rule)
+			}
+		})
+	}
+}
@@ -594,6 +594,53 @@ func (d Xtables) NetworkApplyACLRules(networkName string, rules []ACLRule) error
 	return nil
 }
 
+// NetworkACLRuleCounters returns the counters of the ACL rules applied to the network, keyed on counter name.
+// Counters of rules sharing the same counter name (such as the IPv4 and IPv6 variants of a rule) are summed.
+func (d Xtables) NetworkACLRuleCounters(networkName string) (map[string]ACLRuleCounters, error) {
+	chain := iptablesChainACLFilterPrefix + "_" + networkName
+
+	counters := make(map[string]ACLRuleCounters)
+	for _, cmd := range []string{"iptables", "ip6tables"} {
+		// List rules with exact counters. Use -n flag to avoid doing DNS lookups of IPs mentioned in any rules.
+		output, err := shared.RunCommandContext(context.TODO(), cmd, "-w", "-t", "filter", "-L", chain, "-n", "-v", "-x")
+		if err != nil {
+			return nil, fmt.Errorf("Failed listing %q chain %q in table %q: %w", cmd, chain, "filter", err)
+		}
+
+		for _, line := range strings.Split(output, "\n") {
+			// Rule comments are displayed as "/* comment */" at the end of the line.
+			commentStart := strings.Index(line, "/* ")
+			commentEnd := strings.LastIndex(line, " */")
+			if commentStart < 0 || commentEnd <= commentStart {
+				continue
+			}
+
+			fields := strings.Fields(line)
+			if len(fields) < 2 {
+				continue
+			}
+
+			packets, err := strconv.ParseUint(fields[0], 10, 64)
+			if err != nil {
+				continue // Not a rule line.
+			}
+
+			bytes, err := strconv.ParseUint(fields[1], 10, 64)
+			if err != nil {
+				continue // Not a rule line.
+			}
+
+			counterName := line[commentStart+3 : commentEnd]
+			ruleCounters := counters[counterName]
+			ruleCounters.Packets += packets
+			ruleCounters.Bytes += bytes
+			counters[counterName] = ruleCounters
+		}
+	}
+
+	return counters, nil
+}
+
 // aclRuleCriteriaToArgs converts an ACL rule into an set of arguments for an xtables rule.
 // Returns the arguments to use for the action command and separately the arguments for logging if enabled.
 // Returns nil arguments if the rule is not appropriate for the ipVersion.
@@ -691,13 +738,35 @@ func (d Xtables) aclRuleCriteriaToArgs(networkName string, ipVersion uint, rule
 		}
 	}
 
+	// Handle connection limit.
+	if rule.ConnectionLimit != "" {
+		mask := "32"
+		if ipVersion == 6 {
+			mask = "128"
+		}
+
+		args = append(args, "-m", "conntrack", "--ctstate", "NEW", "-m", "connlimit", "--connlimit-upto", rule.ConnectionLimit, "--connlimit-mask", mask, "--connlimit-saddr")
+	}
+
+	// Handle rate limit.
+	if rule.RateLimit != "" {
+		args = append(args, "-m", "limit", "--limit", rule.RateLimit)
+	}
+
 	// Handle action.
 	action := rule.Action
 	if action == "allow" {
 		action = "accept"
 	}
 
-	actionArgs := append(args, "-j", strings.ToUpper(action))
+	actionArgs := append([]string{}, args...)
+
+	// Add the counter name as a comment so the rule's counter can be identified later.
+	if rule.CounterName != "" {
+		actionArgs = append(actionArgs, "-m", "comment", "--comment", rule.CounterName)
+	}
+
+	actionArgs = append(actionArgs, "-j", strings.ToUpper(action))
 
 	// Handle logging.
 	var logArgs []string
@@ -1518,6 +1587,85 @@ func (d Xtables) iptablesChainDelete(ipVersion uint, table string, chain string,
 	return nil
 }
 
+// NetworkApplyPeers applies the isolation rules of the bridge network.
+// Network isolation isn't supported by the xtables driver, so this only fails if isolation is requested.
+func (d Xtables) NetworkApplyPeers(networkName string, isolated bool, peerNetworks []string) error {
+	if isolated {
+		return fmt.Errorf("Network isolation is not supported by the xtables firewall driver")
+	}
+
+	return nil
+}
+
+// NetworkClearPeers removes the isolation rules of the bridge network (no-op for xtables).
+func (d Xtables) NetworkClearPeers(networkName string) error {
+	return nil
+}
+
+// InstanceSetupSNAT applies the SNAT rules translating the outbound traffic of the instance device to its own
+// SNAT addresses rather than those of the network.
+func (d Xtables) InstanceSetupSNAT(projectName string, instanceName string, deviceName string, networkName string, SNATV4 *SNATOpts, SNATV6 *SNATOpts) error {
+	err := d.InstanceClearSNAT(projectName, instanceName, deviceName)
+	if err != nil {
+		return err
+	}
+
+	comment := d.instanceDeviceIPTablesComment(projectName, instanceName, deviceName)
+
+	for ipVersion, opts := range map[uint]*SNATOpts{4: SNATV4, 6: SNATV6} {
+		if opts == nil {
+			continue
+		}
+
+		// Prepend the rule so that it takes precedence over the network's outbound NAT rule.
+		err := d.iptablesPrepend(ipVersion, comment, "nat", "POSTROUTING", "-s", opts.Subnet.String(), "!", "-o", networkName, "-j", "SNAT", "--to", opts.SNATAddress.String())
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// InstanceClearSNAT removes the SNAT rules of the instance device.
+func (d Xtables) InstanceClearSNAT(projectName string, instanceName string, deviceName string) error {
+	comment := d.instanceDeviceIPTablesComment(projectName, instanceName, deviceName)
+	errs := []error{}
+
+	for _, ipVersion := range []uint{4, 6} {
+		err := d.iptablesClear(ipVersion, []string{comment}, "nat")
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("Failed to remove SNAT rules for %q: %v", deviceName, errs)
+	}
+
+	return nil
+}
+
+// InstanceSetupNetLimits applies the aggregate rate limits of the project to the instance device.
+// Project network limits aren't supported by the xtables driver, so this only fails if limits are requested.
+func (d Xtables) InstanceSetupNetLimits(projectName string, instanceName string, deviceName string, ingressRate int64, egressRate int64) error {
+	if ingressRate > 0 || egressRate > 0 {
+		return fmt.Errorf("Project network limits are not supported by the xtables firewall driver")
+	}
+
+	return nil
+}
+
+// InstanceClearNetLimits removes the aggregate rate limits of the project from the instance device (no-op for xtables).
+func (d Xtables) InstanceClearNetLimits(projectName string, instanceName string, deviceName string) error {
+	return nil
+}
+
+// ProjectClearUnusedNetLimits removes the aggregate rate limits of the project no longer in use (no-op for xtables).
+func (d Xtables) ProjectClearUnusedNetLimits(projectName string, ingressRate int64, egressRate int64) error {
+	return nil
+}
+
 // NetworkApplyForwards apply network address forward rules to firewall.
 func (d Xtables) NetworkApplyForwards(networkName string, rules []AddressForward) error {
 	// Validate all rules first.
@@ -14,7 +14,10 @@ type Firewall interface {
 	NetworkSetup(networkName string, ip4Address net.IP, ip6Address net.IP, opts drivers.Opts) error
 	NetworkClear(networkName string, delete bool, ipVersions []uint) error
 	NetworkApplyACLRules(networkName string, rules []drivers.ACLRule) error
+	NetworkACLRuleCounters(networkName string) (map[string]drivers.ACLRuleCounters, error)
 	NetworkApplyForwards(networkName string, rules []drivers.AddressForward) error
+	NetworkApplyPeers(networkName string, isolated bool, peerNetworks []string) error
+	NetworkClearPeers(networkName string) error
 
 	InstanceSetupBridgeFilter(projectName string, instanceName string, deviceName string, parentName string, hostName string, hwAddr string, IPv4Nets []*net.IPNet, IPv6Nets []*net.IPNet, parentManaged bool) error
 	InstanceClearBridgeFilter(projectName string, instanceName string, deviceName string, parentName string, hostName string, hwAddr string, IPv4Nets []*net.IPNet, IPv6Nets []*net.IPNet) error
@@ -27,4 +30,11 @@ type Firewall interface {
 
 	InstanceSetupNetPrio(projectName string, instanceName string, deviceName string, netPrio uint32) error
 	InstanceClearNetPrio(projectName string, instanceName string, deviceName string) error
+
+	InstanceSetupSNAT(projectName string, instanceName string, deviceName string, networkName string, SNATV4 *drivers.SNATOpts, SNATV6 *drivers.SNATOpts) error
+	InstanceClearSNAT(projectName string, instanceName string, deviceName string) error
+
+	InstanceSetupNetLimits(projectName string, instanceName string, deviceName string, ingressRate int64, egressRate int64) error
+	InstanceClearNetLimits(projectName string, instanceName string, deviceName string) error
+	ProjectClearUnusedNetLimits(projectName string, ingressRate int64, egressRate int64) error
 }
@@ -40,6 +40,7 @@ var fanotifyEventToFSMonitorEvent = map[uint64]fsmonitor.Event{
 	unix.FAN_DELETE_SELF: fsmonitor.EventRemove,
 	unix.FAN_CLOSE_WRITE: fsmonitor.EventWrite,
 	unix.FAN_MOVED_TO:    fsmonitor.EventRename,
+	unix.FAN_MODIFY:      fsmonitor.EventModify,
 }
 
 var fsMonitorEventToFANotifyEvent = map[fsmonitor.Event]uint64{
@@ -47,6 +48,7 @@ var fsMonitorEventToFANotifyEvent = map[fsmonitor.Event]uint64{
 	fsmonitor.EventRemove: unix.FAN_DELETE | unix.FAN_DELETE_SELF,
 	fsmonitor.EventWrite:  unix.FAN_CLOSE_WRITE,
 	fsmonitor.EventRename: unix.FAN_MOVED_TO,
+	fsmonitor.EventModify: unix.FAN_MODIFY,
 }
 
 func (d *fanotify) toFSMonitorEvent(mask uint64) (fsmonitor.Event, error) {
@@ -27,6 +27,7 @@ var inotifyEventToFSMonitorEvent = map[uint32]fsmonitor.Event{
 	in.InDeleteSelf: fsmonitor.EventRemove,
 	in.InCloseWrite: fsmonitor.EventWrite,
 	in.InMovedTo:    fsmonitor.EventRename,
+	in.InModify:     fsmonitor.EventModify,
 }
 
 var fsMonitorEventToINotifyEvent = map[fsmonitor.Event]uint32{
@@ -34,6 +35,7 @@ var fsMonitorEventToINotifyEvent = map[fsmonitor.Event]uint32{
 	fsmonitor.EventRemove: in.InDelete | in.InDeleteSelf,
 	fsmonitor.EventWrite:  in.InCloseWrite,
 	fsmonitor.EventRename: in.InMovedTo,
+	fsmonitor.EventModify: in.InModify,
 }
 
 var errIgnoreEvent = errors.New("Intentionally ignored event")
@@ -17,6 +17,10 @@ const (
 	// e.g. If watching `/some-dir`, and a file is renamed from `/some-dir/file.txt.tmp` to `/some-dir/file.txt`, the
 	// event fires for `/some-dir/file.txt`, and not for `/some-dir/file.txt.tmp`.
 	EventRename
+
+	// EventModify represents the modify event (a file was written to). Unlike EventWrite, this fires for files that
+	// are kept open by the writer, e.g. files that are rewritten in place.
+	EventModify
 )
 
 // String implements fmt.Stringer for Event.
@@ -26,5 +30,6 @@ func (e Event) String() string {
 		EventRemove: "remove",
 		EventWrite:  "write",
 		EventRename: "rename",
+		EventModify: "modify",
 	}[e]
 }
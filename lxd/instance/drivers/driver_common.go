@@ -397,6 +397,11 @@ func (d *common) ExecOutputPath() string {
 	return filepath.Join(d.Path(), "exec-output")
 }
 
+// SessionRecordingPath returns the instance's session recording path.
+func (d *common) SessionRecordingPath() string {
+	return filepath.Join(d.Path(), "session-recordings")
+}
+
 // RootfsPath returns the instance's rootfs path.
 func (d *common) RootfsPath() string {
 	return filepath.Join(d.Path(), "rootfs")
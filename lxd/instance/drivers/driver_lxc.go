@@ -2999,6 +2999,14 @@ func (d *lxc) onStop(args map[string]string) error {
 				op.Done(fmt.Errorf("Failed deleting ephemeral instance: %w", err))
 				return
 			}
+
+			return
+		}
+
+		// Apply the restart policy if the container stopped by itself.
+		// A clean shutdown can't be told apart from a crash of the init process, so treat it as a failure.
+		if op.GetInstanceInitiated() {
+			RestartPolicyApply(d.state, d, true)
 		}
 	}(d, target, op)
 
@@ -431,11 +431,21 @@ func (d *qemu) getMonitorEventHandler() func(event string, data map[string]any)
 				d.logger.Debug("Instance stopped", logger.Ctx{"target": target, "reason": data["reason"]})
 			}
 
+			// Check whether the stop was requested through LXD before the onStop hook picks up the operation.
+			op := operationlock.Get(d.Project().Name, d.Name())
+			requested := op != nil && op.ActionMatch(operationlock.ActionStart, operationlock.ActionRestart, operationlock.ActionStop, operationlock.ActionRestore)
+
 			err = d.onStop(target)
 			if err != nil {
 				d.logger.Error("Failed to cleanly stop instance", logger.Ctx{"err": err})
 				return
 			}
+
+			// Apply the restart policy if the VM stopped by itself, treating anything other than a guest
+			// initiated shutdown (such as a crash of QEMU) as a failure.
+			if !requested && target == "stop" {
+				RestartPolicyApply(d.state, d, entry != "guest-shutdown")
+			}
 		}
 	}
 }
@@ -3235,8 +3245,8 @@ func (d *qemu) generateQemuConfigFile(cpuInfo *cpuTopology, mountInfo *storagePo
 	// QMP socket.
 	cfg = append(cfg, qemuControlSocket(&qemuControlSocketOpts{d.monitorPath()})...)
 
-	// Console output.
-	cfg = append(cfg, qemuConsole(&qemuConsoleOpts{d.consolePath()})...)
+	// Console output, also written to the console log file.
+	cfg = append(cfg, qemuConsole(&qemuConsoleOpts{path: d.consolePath(), logPath: d.ConsoleBufferLogPath()})...)
 
 	// Setup the bus allocator.
 	bus := qemuNewBus(busName, &cfg)
@@ -5696,7 +5706,10 @@ func (d *qemu) Update(args db.InstanceArgs, userRequested bool) error {
 			"boot.",
 			"cloud-init.",
 			"environment.",
+			"healthcheck.",
 			"image.",
+			"restart.",
+			"schedule.",
 			"snapshots.",
 			"user.",
 			"volatile.",
@@ -8097,6 +8110,24 @@ func (d *qemu) agentGetState() (*api.InstanceState, error) {
 	return status, nil
 }
 
+// AgentHeartbeat checks that the lxd-agent of the VM is responding.
+func (d *qemu) AgentHeartbeat() error {
+	client, err := d.getAgentClient()
+	if err != nil {
+		return err
+	}
+
+	// Connecting to the agent retrieves its server information, which checks that it is responding.
+	agent, err := lxd.ConnectLXDHTTP(nil, client)
+	if err != nil {
+		return fmt.Errorf("Failed connecting to agent: %w", err)
+	}
+
+	agent.Disconnect()
+
+	return nil
+}
+
 // IsRunning returns whether or not the instance is running.
 func (d *qemu) IsRunning() bool {
 	return d.isRunningStatusCode(d.statusCode())
@@ -704,11 +704,12 @@ func TestQemuConfigTemplates(t *testing.T) {
 			opts     qemuConsoleOpts
 			expected string
 		}{{
-			qemuConsoleOpts{"/dev/shm/console-socket"},
+			qemuConsoleOpts{"/dev/shm/console-socket", "/var/log/console.log"},
 			`# Console
 			[chardev "console"]
 			backend = "socket"
 			path = "/dev/shm/console-socket"
+			logfile = "/var/log/console.log"
 			server = "on"
 			wait = "off"`,
 		}}
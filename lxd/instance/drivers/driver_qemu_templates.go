@@ -610,7 +610,8 @@ func qemuControlSocket(opts *qemuControlSocketOpts) []cfgSection {
 }
 
 type qemuConsoleOpts struct {
-	path string
+	path    string
+	logPath string
 }
 
 func qemuConsole(opts *qemuConsoleOpts) []cfgSection {
@@ -620,6 +621,7 @@ func qemuConsole(opts *qemuConsoleOpts) []cfgSection {
 		entries: []cfgEntry{
 			{key: "backend", value: "socket"},
 			{key: "path", value: opts.path},
+			{key: "logfile", value: opts.logPath},
 			{key: "server", value: "on"},
 			{key: "wait", value: "off"},
 		},
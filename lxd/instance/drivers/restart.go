@@ -0,0 +1,143 @@
+package drivers
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/instance/operationlock"
+	"github.com/canonical/lxd/lxd/lifecycle"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// restartPolicyMaxBackoff is the maximum delay between consecutive automatic restarts.
+const restartPolicyMaxBackoff = 5 * time.Minute
+
+// restartPolicyResetAfter is how long an instance must run for its automatic restart counter to be reset.
+const restartPolicyResetAfter = 10 * time.Minute
+
+// Instances with a pending automatic restart, keyed by project and instance name.
+var restartPendingMu sync.Mutex
+var restartPending = map[string]bool{}
+
+// restartPolicyDelay returns how long to wait before the next automatic restart of an instance with the
+// specified restart.backoff setting that has already been automatically restarted count times.
+func restartPolicyDelay(backoff string, count int) time.Duration {
+	delay := 10 * time.Second
+	if backoff != "" {
+		seconds, err := strconv.Atoi(backoff)
+		if err == nil {
+			delay = time.Duration(seconds) * time.Second
+		}
+	}
+
+	for i := 0; i < count && delay < restartPolicyMaxBackoff; i++ {
+		delay *= 2
+	}
+
+	return min(delay, restartPolicyMaxBackoff)
+}
+
+// RestartPolicyApply restarts the instance in the background if its restart.policy requires it.
+// It is called when an instance stopped without being asked to through LXD, or when it became unhealthy.
+// The failed argument indicates whether this was due to a failure rather than a clean shutdown from inside the
+// instance, as only the "always" policy restarts instances after a clean shutdown.
+func RestartPolicyApply(s *state.State, inst instance.Instance, failed bool) {
+	policy := inst.ExpandedConfig()["restart.policy"]
+	if policy == "" || policy == "never" || (policy == "on-failure" && !failed) {
+		return
+	}
+
+	if inst.IsSnapshot() || inst.IsEphemeral() {
+		return
+	}
+
+	projectName := inst.Project().Name
+	instanceName := inst.Name()
+	key := projectName + "/" + instanceName
+
+	restartPendingMu.Lock()
+	if restartPending[key] {
+		restartPendingMu.Unlock()
+		return
+	}
+
+	restartPending[key] = true
+	restartPendingMu.Unlock()
+
+	// Reset the counter if the instance ran for long enough since it was last started.
+	count, _ := strconv.Atoi(inst.LocalConfig()["volatile.restart.count"])
+	if time.Since(inst.LastUsedDate()) > restartPolicyResetAfter {
+		count = 0
+	}
+
+	delay := restartPolicyDelay(inst.ExpandedConfig()["restart.backoff"], count)
+
+	l := logger.AddContext(logger.Ctx{"project": projectName, "instance": instanceName, "policy": policy, "delay": delay, "count": count})
+	l.Info("Scheduling automatic instance restart")
+
+	go func() {
+		defer func() {
+			restartPendingMu.Lock()
+			delete(restartPending, key)
+			restartPendingMu.Unlock()
+		}()
+
+		select {
+		case <-time.After(delay):
+		case <-s.ShutdownCtx.Done():
+			return
+		}
+
+		// Reload the instance to pick up any change made while waiting.
+		inst, err := instance.LoadByProjectAndName(s, projectName, instanceName)
+		if err != nil {
+			l.Warn("Failed loading instance for automatic restart", logger.Ctx{"err": err})
+			return
+		}
+
+		policy := inst.ExpandedConfig()["restart.policy"]
+		if policy == "" || policy == "never" {
+			return
+		}
+
+		// Wait for any ongoing operation (such as the stop cleanup) to complete.
+		op := operationlock.Get(projectName, instanceName)
+		if op != nil {
+			_ = op.Wait(context.Background())
+		}
+
+		if inst.IsRunning() && inst.LocalConfig()["volatile.last_state.health"] != "unhealthy" {
+			// The instance was started again or recovered in the meantime.
+			return
+		}
+
+		err = inst.VolatileSet(map[string]string{"volatile.restart.count": strconv.Itoa(count + 1)})
+		if err != nil {
+			l.Warn("Failed recording automatic restart count", logger.Ctx{"err": err})
+		}
+
+		if inst.IsRunning() {
+			timeout := 30 * time.Second
+			hostShutdownTimeout, err := strconv.Atoi(inst.ExpandedConfig()["boot.host_shutdown_timeout"])
+			if err == nil {
+				timeout = time.Duration(hostShutdownTimeout) * time.Second
+			}
+
+			err = inst.Restart(timeout)
+		} else {
+			err = inst.Start(false)
+		}
+
+		if err != nil {
+			l.Error("Failed automatically restarting instance", logger.Ctx{"err": err})
+			return
+		}
+
+		l.Info("Automatically restarted instance")
+		s.Events.SendLifecycle(projectName, lifecycle.InstanceRestarted.Event(inst, map[string]any{"reason": "restart-policy"}))
+	}()
+}
@@ -147,6 +147,7 @@ type Instance interface {
 	// Paths.
 	Path() string
 	ExecOutputPath() string
+	SessionRecordingPath() string
 	RootfsPath() string
 	TemplatesPath() string
 	StatePath() string
@@ -193,6 +194,7 @@ type VM interface {
 	Instance
 
 	AgentCertificate() *x509.Certificate
+	AgentHeartbeat() error
 
 	FirmwarePath() string
 
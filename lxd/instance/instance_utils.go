@@ -115,6 +115,31 @@ func ValidConfig(sysOS *sys.OS, config map[string]string, expanded bool, instanc
 		return fmt.Errorf("nvidia.runtime is incompatible with Ubuntu Core")
 	}
 
+	// Validate that the health check has what it needs to run.
+	if expanded {
+		switch config["healthcheck.type"] {
+		case "exec":
+			if config["healthcheck.command"] == "" {
+				return fmt.Errorf(`"healthcheck.command" is required when "healthcheck.type" is "exec"`)
+			}
+
+		case "tcp":
+			if config["healthcheck.address"] == "" {
+				return fmt.Errorf(`"healthcheck.address" is required when "healthcheck.type" is "tcp"`)
+			}
+
+		case "http":
+			if config["healthcheck.url"] == "" {
+				return fmt.Errorf(`"healthcheck.url" is required when "healthcheck.type" is "http"`)
+			}
+
+		case "agent":
+			if instanceType == instancetype.Container {
+				return fmt.Errorf(`The "agent" health check is only supported for virtual machines`)
+			}
+		}
+	}
+
 	// Validate pinning strategy when limits.cpu specifies static pinning.
 	cpuPinStrategy := config["limits.cpu.pin_strategy"]
 	cpuLimit := config["limits.cpu"]
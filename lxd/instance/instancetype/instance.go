@@ -3,6 +3,9 @@ package instancetype
 import (
 	"errors"
 	"fmt"
+	"math"
+	"net"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
@@ -137,6 +140,26 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  shortdesc: What order to start the instances in
 	"boot.autostart.priority": validate.Optional(validate.IsInt64),
 
+	// lxdmeta:generate(entities=instance; group=boot; key=boot.depends_on)
+	// Comma-separated list of instances in the same project that must be ready before the instance is started.
+	// An instance is ready when its `volatile.last_state.ready` key is set by `devlxd` or by the `lxd-agent`.
+	// The dependencies are honored when LXD starts, during cluster evacuation and restore, and by `lxc start --with-deps`.
+	// ---
+	//  type: string
+	//  liveupdate: no
+	//  shortdesc: Instances to wait for before starting the instance
+	"boot.depends_on": validate.Optional(validate.IsListOf(validate.IsHostname)),
+
+	// lxdmeta:generate(entities=instance; group=boot; key=boot.depends_on.timeout)
+	// Number of seconds to wait for the instances listed in {config:option}`instance-boot:boot.depends_on` to become ready.
+	// If they don't become ready in time, the instance is not started.
+	// ---
+	//  type: integer
+	//  defaultdesc: `300`
+	//  liveupdate: no
+	//  shortdesc: How long to wait for the dependencies of the instance
+	"boot.depends_on.timeout": validate.Optional(validate.IsUint32),
+
 	// lxdmeta:generate(entities=instance; group=boot; key=boot.stop.priority)
 	// The instance with the highest value is shut down first.
 	// ---
@@ -212,6 +235,95 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  condition: If supported by image
 	//  shortdesc: Legacy version of `cloud-init.vendor-data`
 
+	// lxdmeta:generate(entities=instance; group=health; key=healthcheck.type)
+	// Possible values are `exec` (run {config:option}`instance-health:healthcheck.command` inside the instance), `tcp` (connect to {config:option}`instance-health:healthcheck.address`), `http` (request {config:option}`instance-health:healthcheck.url`) and `agent` (check that the `lxd-agent` responds, VMs only).
+	// Health checks are disabled when this option is not set.
+	// See {ref}`instances-health-checks` for more information.
+	// ---
+	//  type: string
+	//  liveupdate: yes
+	//  shortdesc: Type of health check
+	"healthcheck.type": validate.Optional(validate.IsOneOf("exec", "tcp", "http", "agent")),
+
+	// lxdmeta:generate(entities=instance; group=health; key=healthcheck.command)
+	// The command is run with `sh -c` inside the instance, and the instance is healthy if the command exits with status `0`.
+	// ---
+	//  type: string
+	//  liveupdate: yes
+	//  condition: `healthcheck.type` is `exec`
+	//  shortdesc: Command to run to check the health of the instance
+	"healthcheck.command": validate.IsAny,
+
+	// lxdmeta:generate(entities=instance; group=health; key=healthcheck.address)
+	// Specify the address as `<IP>:<port>`, where the IP address is one of the instance's own global addresses.
+	// The connection is made from the LXD server, and the instance is healthy if the connection is accepted.
+	// ---
+	//  type: string
+	//  liveupdate: yes
+	//  condition: `healthcheck.type` is `tcp`
+	//  shortdesc: Address to connect to for the health check
+	"healthcheck.address": validate.Optional(validate.IsListenAddress(false, false, true)),
+
+	// lxdmeta:generate(entities=instance; group=health; key=healthcheck.url)
+	// The host of the URL must be one of the instance's own global IP addresses.
+	// The request is made from the LXD server without following redirects, and the instance is healthy if the response has a status code below 400.
+	// ---
+	//  type: string
+	//  liveupdate: yes
+	//  condition: `healthcheck.type` is `http`
+	//  shortdesc: URL to request for the health check
+	"healthcheck.url": validate.Optional(isHealthCheckURL),
+
+	// lxdmeta:generate(entities=instance; group=health; key=healthcheck.interval)
+	// Specify the interval in seconds.
+	// The minimum value is `10`.
+	// ---
+	//  type: integer
+	//  defaultdesc: `30`
+	//  liveupdate: yes
+	//  shortdesc: How often to run the health check
+	"healthcheck.interval": validate.Optional(validate.IsInRange(10, math.MaxInt32)),
+
+	// lxdmeta:generate(entities=instance; group=health; key=healthcheck.timeout)
+	// Specify the timeout in seconds.
+	// A check that does not complete within the timeout is considered failed.
+	// ---
+	//  type: integer
+	//  defaultdesc: `10`
+	//  liveupdate: yes
+	//  shortdesc: How long to wait for the health check to complete
+	"healthcheck.timeout": validate.Optional(validate.IsInRange(1, math.MaxInt32)),
+
+	// lxdmeta:generate(entities=instance; group=health; key=healthcheck.threshold)
+	// The instance is marked as unhealthy after this number of consecutive failed checks.
+	// ---
+	//  type: integer
+	//  defaultdesc: `3`
+	//  liveupdate: yes
+	//  shortdesc: Number of failed checks before the instance is unhealthy
+	"healthcheck.threshold": validate.Optional(validate.IsInRange(1, math.MaxInt32)),
+
+	// lxdmeta:generate(entities=instance; group=health; key=restart.policy)
+	// Possible values are `never`, `on-failure` (restart the instance when it becomes unhealthy or stops due to a failure) and `always` (also restart the instance when it shuts down by itself).
+	// Instances stopped through LXD are never restarted.
+	// See {ref}`instances-restart-policy` for more information.
+	// ---
+	//  type: string
+	//  defaultdesc: `never`
+	//  liveupdate: yes
+	//  shortdesc: When to automatically restart the instance
+	"restart.policy": validate.Optional(validate.IsOneOf("never", "on-failure", "always")),
+
+	// lxdmeta:generate(entities=instance; group=health; key=restart.backoff)
+	// Specify the delay in seconds.
+	// The delay doubles after each consecutive automatic restart, up to a maximum of 300 seconds.
+	// ---
+	//  type: integer
+	//  defaultdesc: `10`
+	//  liveupdate: yes
+	//  shortdesc: Delay before the first automatic restart
+	"restart.backoff": validate.Optional(validate.IsInRange(0, 300)),
+
 	// lxdmeta:generate(entities=instance; group=miscellaneous; key=cluster.evacuate)
 	// The `cluster.evacuate` provides control over how instances are handled when a cluster member is being evacuated.
 	//
@@ -231,6 +343,18 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  shortdesc: What to do when evacuating the instance
 	"cluster.evacuate": validate.Optional(validate.IsOneOf("auto", "migrate", "live-migrate", "stop")),
 
+	// lxdmeta:generate(entities=instance; group=miscellaneous; key=placement.group)
+	// The placement group must exist in the project of the instance.
+	// Its policy is honored when LXD picks a cluster member for the instance, when it is moved, and during cluster evacuation and healing.
+	// In strict mode, an explicit target member must satisfy it too.
+	//
+	// See {ref}`placement-groups` for more information.
+	// ---
+	//  type: string
+	//  liveupdate: yes
+	//  shortdesc: Placement group of the instance
+	"placement.group": validate.Optional(validate.IsHostname),
+
 	// lxdmeta:generate(entities=instance; group=resource-limits; key=limits.cpu)
 	// A number or a specific range of CPUs to expose to the instance.
 	//
@@ -340,6 +464,17 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  shortdesc: Controls the availability of the `/1.0/images` API over `devlxd`
 	"security.devlxd.images": validate.Optional(validate.IsBool),
 
+	// lxdmeta:generate(entities=instance; group=security; key=security.exec.record)
+	// When enabled, the input and output of `exec` and `console` sessions are recorded in asciicast format, along with the identity that opened each session.
+	// Sessions are also recorded if the {config:option}`project-specific:security.exec.record` option of the project is enabled.
+	// See {ref}`run-commands-record` for more information.
+	// ---
+	//  type: bool
+	//  defaultdesc: `false`
+	//  liveupdate: yes
+	//  shortdesc: Whether to record `exec` and `console` sessions
+	"security.exec.record": validate.Optional(validate.IsBool),
+
 	// lxdmeta:generate(entities=instance; group=security; key=security.protection.delete)
 	//
 	// ---
@@ -358,6 +493,28 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  shortdesc: Whether to prevent the instance from being started
 	"security.protection.start": validate.Optional(validate.IsBool),
 
+	// lxdmeta:generate(entities=instance; group=schedule; key=schedule.start)
+	// Specify either a cron expression (`<minute> <hour> <dom> <month> <dow>`), a comma-separated list of schedule aliases (`@hourly`, `@daily`, `@midnight`, `@weekly`, `@monthly`, `@annually`, `@yearly`), or leave empty to disable scheduled starts.
+	//
+	// See {ref}`instances-manage-schedule` for more information.
+	// ---
+	//  type: string
+	//  defaultdesc: empty
+	//  liveupdate: yes
+	//  shortdesc: Schedule for automatically starting the instance
+	"schedule.start": validate.Optional(validate.IsCron([]string{"@hourly", "@daily", "@midnight", "@weekly", "@monthly", "@annually", "@yearly", "@never"})),
+
+	// lxdmeta:generate(entities=instance; group=schedule; key=schedule.stop)
+	// Specify either a cron expression (`<minute> <hour> <dom> <month> <dow>`), a comma-separated list of schedule aliases (`@hourly`, `@daily`, `@midnight`, `@weekly`, `@monthly`, `@annually`, `@yearly`), or leave empty to disable scheduled stops.
+	//
+	// The instance is shut down cleanly, respecting {config:option}`instance-boot:boot.host_shutdown_timeout`, and is forcefully stopped if it doesn't shut down in time.
+	// ---
+	//  type: string
+	//  defaultdesc: empty
+	//  liveupdate: yes
+	//  shortdesc: Schedule for automatically stopping the instance
+	"schedule.stop": validate.Optional(validate.IsCron([]string{"@hourly", "@daily", "@midnight", "@weekly", "@monthly", "@annually", "@yearly", "@never"})),
+
 	// lxdmeta:generate(entities=instance; group=snapshots; key=snapshots.schedule)
 	// Specify either a cron expression (`<minute> <hour> <dom> <month> <dow>`), a comma-separated list of schedule aliases (`@hourly`, `@daily`, `@midnight`, `@weekly`, `@monthly`, `@annually`, `@yearly`), or leave empty to disable automatic snapshots.
 	//
@@ -439,6 +596,35 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	"volatile.last_state.power": validate.IsAny,
 	"volatile.last_state.ready": validate.IsBool,
 	"volatile.apply_quota":      validate.IsAny,
+
+	// lxdmeta:generate(entities=instance; group=volatile; key=volatile.last_state.health)
+	// Possible values are `healthy` and `unhealthy`.
+	// ---
+	//  type: string
+	//  shortdesc: Result of the last health check
+	"volatile.last_state.health": validate.IsAny,
+
+	// lxdmeta:generate(entities=instance; group=volatile; key=volatile.rebalance.last_move)
+	// Set when the instance is moved by {ref}`automatic cluster rebalancing <cluster-rebalance>`.
+	// ---
+	//  type: string
+	//  shortdesc: Time of the last move of the instance by cluster rebalancing
+	"volatile.rebalance.last_move": validate.IsAny,
+
+	// lxdmeta:generate(entities=instance; group=volatile; key=volatile.restart.count)
+	// The counter is reset when the instance runs for more than ten minutes.
+	// ---
+	//  type: integer
+	//  shortdesc: Number of consecutive automatic restarts
+	"volatile.restart.count": validate.Optional(validate.IsUint32),
+
+	// lxdmeta:generate(entities=instance; group=volatile; key=volatile.instance_group)
+	// Set on the instances that are created by an {ref}`instance group <instance-groups>`.
+	// ---
+	//  type: string
+	//  shortdesc: Name of the instance group that the instance belongs to
+	"volatile.instance_group": validate.IsAny,
+
 	// lxdmeta:generate(entities=instance; group=volatile; key=volatile.uuid)
 	// The instance UUID is globally unique across all servers and projects.
 	// ---
@@ -1302,6 +1488,29 @@ func ConfigKeyChecker(key string, instanceType Type) (func(value string) error,
 	return nil, fmt.Errorf("Unknown configuration key: %q", key)
 }
 
+// isHealthCheckURL validates a HTTP health check URL, which must use an IP address as its host.
+func isHealthCheckURL(value string) error {
+	err := validate.IsRequestURL(value)
+	if err != nil {
+		return err
+	}
+
+	u, err := url.Parse(value)
+	if err != nil {
+		return fmt.Errorf("Invalid URL: %w", err)
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("Only http and https URLs are supported")
+	}
+
+	if net.ParseIP(u.Hostname()) == nil {
+		return fmt.Errorf("The URL host must be an IP address")
+	}
+
+	return nil
+}
+
 // InstanceIncludeWhenCopying is used to decide whether to include a config item or not when copying an instance.
 // The remoteCopy argument indicates if the copy is remote (i.e between LXD nodes) as this affects the keys kept.
 func InstanceIncludeWhenCopying(configKey string, remoteCopy bool) bool {
@@ -21,6 +21,7 @@ import (
 	"github.com/canonical/lxd/lxd/db/operationtype"
 	"github.com/canonical/lxd/lxd/instance"
 	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/lifecycle"
 	"github.com/canonical/lxd/lxd/operations"
 	"github.com/canonical/lxd/lxd/request"
 	"github.com/canonical/lxd/lxd/response"
@@ -61,6 +62,12 @@ type consoleWs struct {
 
 	// channel type (either console or vga)
 	protocol string
+
+	// identity that opened the console
+	requestor *api.EventLifecycleRequestor
+
+	// whether to record the console session
+	record bool
 }
 
 // Metadata returns a map of metadata.
@@ -220,6 +227,20 @@ func (s *consoleWs) doConsole(op *operations.Operation) error {
 		_ = shared.SetSize(int(console.Fd()), s.width, s.height)
 	}
 
+	// Record the console input and output if required.
+	var consoleRWC io.ReadWriteCloser = console
+	var recorder *sessionRecorder
+	if s.record {
+		recorder, err = newSessionRecorder(s.instance, "console", op.ID(), s.requestor, nil, nil, s.width, s.height)
+		if err != nil {
+			return err
+		}
+
+		defer func() { _ = recorder.Close() }()
+
+		consoleRWC = newSessionRecordedReadWriteCloser(console, recorder)
+	}
+
 	consoleDoneCh := make(chan struct{})
 
 	// Wait for control socket to connect and then read messages from the remote side in a loop.
@@ -276,6 +297,10 @@ func (s *consoleWs) doConsole(op *operations.Operation) error {
 				}
 
 				logger.Debugf("Set window size to: %dx%d", winchWidth, winchHeight)
+
+				if recorder != nil {
+					recorder.Resize(winchWidth, winchHeight)
+				}
 			}
 		}
 	}()
@@ -291,7 +316,7 @@ func (s *consoleWs) doConsole(op *operations.Operation) error {
 		defer l.Debug("Finished mirroring websocket to console")
 
 		l.Debug("Started mirroring websocket")
-		readDone, writeDone := ws.Mirror(conn, console)
+		readDone, writeDone := ws.Mirror(conn, consoleRWC)
 
 		<-readDone
 		l.Debug("Finished mirroring console to websocket")
@@ -512,6 +537,8 @@ func instanceConsolePost(d *Daemon, r *http.Request) response.Response {
 	ws.width = post.Width
 	ws.height = post.Height
 	ws.protocol = post.Type
+	ws.requestor = request.CreateRequestor(r)
+	ws.record = post.Type == instance.ConsoleTypeConsole && sessionRecordingEnabled(inst)
 
 	resources := map[string][]api.URL{}
 	resources["instances"] = []api.URL{*api.NewURL().Path(version.APIVersion, "instances", ws.instance.Name())}
@@ -543,6 +570,11 @@ func instanceConsolePost(d *Daemon, r *http.Request) response.Response {
 //	    description: Project name
 //	    type: string
 //	    example: default
+//	  - in: query
+//	    name: follow
+//	    description: Keep the connection open and stream new console output
+//	    type: boolean
+//	    example: true
 //	responses:
 //	  "200":
 //	     description: Raw console log
@@ -592,8 +624,26 @@ func instanceConsoleLogGet(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
-	if inst.Type() != instancetype.Container {
-		return response.SmartError(fmt.Errorf("Instance is not container type"))
+	if shared.IsTrue(request.QueryParam(r, "follow")) {
+		s.Events.SendLifecycle(projectName, lifecycle.InstanceConsoleRetrieved.Event(inst, nil))
+
+		// The console output is also written to the console log file while the instance is running.
+		return logFollowResponse(r, inst.ConsoleBufferLogPath())
+	}
+
+	if inst.Type() == instancetype.VM {
+		// Hand back the contents of the console log file written by QEMU.
+		consoleBufferLogPath := inst.ConsoleBufferLogPath()
+		if !shared.PathExists(consoleBufferLogPath) {
+			return response.FileResponse([]response.FileResponseEntry{}, nil)
+		}
+
+		ent := response.FileResponseEntry{
+			Path:     consoleBufferLogPath,
+			Filename: consoleBufferLogPath,
+		}
+
+		return response.FileResponse([]response.FileResponseEntry{ent}, nil)
 	}
 
 	c, ok := inst.(instance.Container)
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/project"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// instanceDependencies returns the names of the instances listed in the boot.depends_on setting of an instance.
+func instanceDependencies(inst instance.Instance) []string {
+	var dependencies []string
+
+	for _, name := range shared.SplitNTrimSpace(inst.ExpandedConfig()["boot.depends_on"], ",", -1, true) {
+		if name == inst.Name() || shared.ValueInSlice(name, dependencies) {
+			continue
+		}
+
+		dependencies = append(dependencies, name)
+	}
+
+	return dependencies
+}
+
+// instancesSortByDependencies returns the instances reordered so that every instance comes after the instances it
+// depends on through boot.depends_on. Otherwise the original order of the instances is preserved.
+// Dependency cycles are logged and broken at the instance where they are detected.
+func instancesSortByDependencies(instances []instance.Instance) []instance.Instance {
+	byName := make(map[string]instance.Instance, len(instances))
+	for _, inst := range instances {
+		byName[project.Instance(inst.Project().Name, inst.Name())] = inst
+	}
+
+	const (
+		visiting = iota + 1
+		visited
+	)
+
+	sorted := make([]instance.Instance, 0, len(instances))
+	status := make(map[string]int, len(instances))
+
+	var visit func(inst instance.Instance)
+	visit = func(inst instance.Instance) {
+		key := project.Instance(inst.Project().Name, inst.Name())
+
+		switch status[key] {
+		case visited:
+			return
+		case visiting:
+			logger.Warn("Instance boot dependency cycle detected", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
+			return
+		}
+
+		status[key] = visiting
+
+		for _, name := range instanceDependencies(inst) {
+			dependency, ok := byName[project.Instance(inst.Project().Name, name)]
+			if ok {
+				visit(dependency)
+			}
+		}
+
+		status[key] = visited
+		sorted = append(sorted, inst)
+	}
+
+	for _, inst := range instances {
+		visit(inst)
+	}
+
+	return sorted
+}
+
+// instanceDependenciesWait waits for all the instances listed in the boot.depends_on setting of an instance to be
+// ready, that is to be running with volatile.last_state.ready set by devlxd or the lxd-agent.
+// Returns an error if a dependency doesn't exist, is stopped or doesn't become ready within boot.depends_on.timeout.
+func instanceDependenciesWait(ctx context.Context, s *state.State, inst instance.Instance) error {
+	dependencies := instanceDependencies(inst)
+	if len(dependencies) == 0 {
+		return nil
+	}
+
+	timeout := 300 * time.Second
+	timeoutSeconds, err := strconv.Atoi(inst.ExpandedConfig()["boot.depends_on.timeout"])
+	if err == nil {
+		timeout = time.Duration(timeoutSeconds) * time.Second
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	projectName := inst.Project().Name
+	l := logger.AddContext(logger.Ctx{"project": projectName, "instance": inst.Name()})
+
+	for _, name := range dependencies {
+		l.Debug("Waiting for instance dependency to be ready", logger.Ctx{"dependency": name})
+
+		for {
+			// The volatile keys are read from the database so this works for instances on other cluster members too.
+			dependency, err := instance.LoadByProjectAndName(s, projectName, name)
+			if err != nil {
+				return fmt.Errorf("Failed loading dependency %q: %w", name, err)
+			}
+
+			if shared.IsTrue(dependency.LocalConfig()["volatile.last_state.ready"]) {
+				break
+			}
+
+			if dependency.LocalConfig()["volatile.last_state.power"] != instance.PowerStateRunning {
+				return fmt.Errorf("Dependency %q is not running", name)
+			}
+
+			select {
+			case <-ctx.Done():
+				if ctx.Err() == context.DeadlineExceeded {
+					return fmt.Errorf("Timed out after %s waiting for dependency %q to be ready", timeout, name)
+				}
+
+				return ctx.Err()
+			case <-time.After(time.Second):
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/shared/api"
+)
+
+// dependencyTestInstance is an instance with just enough implemented to test the boot dependencies.
+type dependencyTestInstance struct {
+	instance.Instance
+
+	projectName string
+	name        string
+	dependsOn   string
+}
+
+func (i *dependencyTestInstance) Name() string {
+	return i.name
+}
+
+func (i *dependencyTestInstance) Project() api.Project {
+	return api.Project{Name: i.projectName}
+}
+
+func (i *dependencyTestInstance) ExpandedConfig() map[string]string {
+	return map[string]string{"boot.depends_on": i.dependsOn}
+}
+
+func Test_instanceDependencies(t *testing.T) {
+	inst := &dependencyTestInstance{projectName: api.ProjectDefaultName, name: "c1", dependsOn: "db, c1, cache,db"}
+	assert.Equal(t, []string{"db", "cache"}, instanceDependencies(inst))
+
+	inst = &dependencyTestInstance{projectName: api.ProjectDefaultName, name: "c1"}
+	assert.Empty(t, instanceDependencies(inst))
+}
+
+func Test_instancesSortByDependencies(t *testing.T) {
+	tests := []struct {
+		name      string
+		instances []*dependencyTestInstance
+		want      []string
+	}{
+		{
+			name: "No dependencies keeps the order",
+			instances: []*dependencyTestInstance{
+				{name: "c3"},
+				{name: "c1"},
+				{name: "c2"},
+			},
+			want: []string{"c3", "c1", "c2"},
+		},
+		{
+			name: "Dependencies come first",
+			instances: []*dependencyTestInstance{
+				{name: "web", dependsOn: "app"},
+				{name: "app", dependsOn: "db,cache"},
+				{name: "other"},
+				{name: "cache"},
+				{name: "db"},
+			},
+			want: []string{"db", "cache", "app", "web", "other"},
+		},
+		{
+			name: "Missing and self dependencies are ignored",
+			instances: []*dependencyTestInstance{
+				{name: "c1", dependsOn: "c1,missing"},
+				{name: "c2"},
+			},
+			want: []string{"c1", "c2"},
+		},
+		{
+			name: "Cycles are broken",
+			instances: []*dependencyTestInstance{
+				{name: "c1", dependsOn: "c2"},
+				{name: "c2", dependsOn: "c3"},
+				{name: "c3", dependsOn: "c1"},
+				{name: "c4", dependsOn: "c3"},
+			},
+			want: []string{"c3", "c2", "c1", "c4"},
+		},
+		{
+			name: "Dependencies are per project",
+			instances: []*dependencyTestInstance{
+				{projectName: "p1", name: "app", dependsOn: "db"},
+				{projectName: "p2", name: "db"},
+				{projectName: "p1", name: "db"},
+			},
+			want: []string{"p1_db", "p1_app", "p2_db"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instances := make([]instance.Instance, 0, len(tt.instances))
+			for _, inst := range tt.instances {
+				if inst.projectName == "" {
+					inst.projectName = api.ProjectDefaultName
+				}
+
+				instances = append(instances, inst)
+			}
+
+			got := []string{}
+			for _, inst := range instancesSortByDependencies(instances) {
+				if inst.Project().Name == api.ProjectDefaultName {
+					got = append(got, inst.Name())
+				} else {
+					got = append(got, inst.Project().Name+"_"+inst.Name())
+				}
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
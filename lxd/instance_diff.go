@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/gorilla/mux"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/db/operationtype"
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/operations"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/storage"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/version"
+)
+
+var instanceDiffCmd = APIEndpoint{
+	Name:        "instanceDiff",
+	Path:        "instances/{name}/diff",
+	MetricsType: entity.TypeInstance,
+	Aliases: []APIEndpointAlias{
+		{Name: "containerDiff", Path: "containers/{name}/diff"},
+		{Name: "vmDiff", Path: "virtual-machines/{name}/diff"},
+	},
+
+	Get: APIEndpointAction{Handler: instanceDiffGet, AccessHandler: allowPermission(entity.TypeInstance, auth.EntitlementCanAccessFiles, "name")},
+}
+
+// swagger:operation GET /1.0/instances/{name}/diff instances instance_diff_get
+//
+//	Get the file changes
+//
+//	Starts a background operation that compares the files of the instance against the image it was created
+//	from, or against the given snapshot. Once the operation succeeds, its "changes" metadata field holds the list
+//	of files that were added, removed or modified (InstanceFileChange structs).
+//
+//	This is only supported for containers.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: snapshot
+//	    description: Name of the snapshot to compare against instead of the image
+//	    type: string
+//	    example: snap0
+//	responses:
+//	  "202":
+//	    $ref: "#/responses/Operation"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceDiffGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	instanceType, err := urlInstanceTypeDetect(r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	projectName := request.ProjectParam(r)
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if shared.IsSnapshot(name) {
+		return response.BadRequest(fmt.Errorf("Invalid instance name"))
+	}
+
+	snapshotName := request.QueryParam(r, "snapshot")
+	if snapshotName != "" && shared.IsSnapshot(snapshotName) {
+		return response.BadRequest(fmt.Errorf("Invalid snapshot name"))
+	}
+
+	// Handle requests targeted to an instance on a different member.
+	resp, err := forwardedResponseIfInstanceIsRemote(s, r, projectName, name, instanceType)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if resp != nil {
+		return resp
+	}
+
+	inst, err := instance.LoadByProjectAndName(s, projectName, name)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if inst.Type() != instancetype.Container {
+		return response.BadRequest(fmt.Errorf("Diff is only supported for containers"))
+	}
+
+	var snapInst instance.Instance
+	if snapshotName != "" {
+		snapInst, err = instance.LoadByProjectAndName(s, projectName, name+shared.SnapshotDelimiter+snapshotName)
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
+	pool, err := storage.LoadByInstance(s, inst)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Comparing large file trees takes a while, so run it in the background.
+	run := func(op *operations.Operation) error {
+		changes, err := pool.DiffInstance(inst, snapInst, op)
+		if err != nil {
+			return err
+		}
+
+		return op.ExtendMetadata(shared.Jmap{"changes": changes})
+	}
+
+	resources := map[string][]api.URL{}
+	resources["instances"] = []api.URL{*api.NewURL().Path(version.APIVersion, "instances", name)}
+	resources["containers"] = resources["instances"]
+
+	op, err := operations.OperationCreate(s, projectName, operations.OperationClassTask, operationtype.InstanceDiff, resources, nil, run, nil, nil, r)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	return operations.OperationResponse(op)
+}
@@ -53,6 +53,9 @@ type execWs struct {
 	waitControlConnected  *cancel.Canceller
 	fds                   map[int]string
 	s                     *state.State
+	requestor             *api.EventLifecycleRequestor
+	record                bool
+	recorder              *sessionRecorder
 }
 
 // Metadata returns a map of metadata.
@@ -81,83 +84,107 @@ func (s *execWs) Connect(op *operations.Operation, r *http.Request, w http.Respo
 		return fmt.Errorf("missing secret")
 	}
 
-	for fd, fdSecret := range s.fds {
-		if secret == fdSecret {
-			conn, err := ws.Upgrader.Upgrade(w, r, nil)
-			if err != nil {
-				return err
-			}
-
-			s.connsLock.Lock()
+	// Find the websocket that the secret belongs to.
+	s.connsLock.Lock()
+	fd, found := s.fdBySecret(secret)
+	s.connsLock.Unlock()
 
-			val, found := s.conns[fd]
-			if found && val == nil {
-				s.conns[fd] = conn
+	/* If we didn't find the right secret, the user provided a bad one,
+	 * which 403, not 404, since this operation actually exists */
+	if !found {
+		return os.ErrPermission
+	}
 
-				// Set TCP timeout options.
-				remoteTCP, _ := tcp.ExtractConn(conn.UnderlyingConn())
-				if remoteTCP != nil {
-					err = tcp.SetTimeouts(remoteTCP, 0)
-					if err != nil {
-						logger.Warn("Failed setting TCP timeouts on remote connection", logger.Ctx{"err": err})
-					}
+	conn, err := ws.Upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return err
+	}
 
-					// Start channel keep alive to run until channel is closed.
-					go func() {
-						pingInterval := time.Second * 10
-						t := time.NewTicker(pingInterval)
-						defer t.Stop()
+	s.connsLock.Lock()
 
-						for {
-							err := conn.WriteControl(websocket.PingMessage, []byte("keepalive"), time.Now().Add(5*time.Second))
-							if err != nil {
-								return
-							}
+	val, found := s.conns[fd]
+	if found && val == nil {
+		s.conns[fd] = conn
 
-							<-t.C
-						}
-					}()
-				}
+		// Set TCP timeout options.
+		remoteTCP, _ := tcp.ExtractConn(conn.UnderlyingConn())
+		if remoteTCP != nil {
+			err = tcp.SetTimeouts(remoteTCP, 0)
+			if err != nil {
+				logger.Warn("Failed setting TCP timeouts on remote connection", logger.Ctx{"err": err})
+			}
 
-				if fd == execWSControl {
-					s.waitControlConnected.Cancel() // Control connection connected.
-				}
+			// Start channel keep alive to run until channel is closed.
+			go func() {
+				pingInterval := time.Second * 10
+				t := time.NewTicker(pingInterval)
+				defer t.Stop()
 
-				for i, c := range s.conns {
-					if i == execWSControl && s.req.WaitForWS && !s.req.Interactive {
-						// Due to a historical bug in the LXC CLI command, we cannot force
-						// the client to connect a control socket when in non-interactive
-						// mode. This is because the older CLI tools did not connect this
-						// channel and so we would prevent the older CLIs connecting to
-						// newer servers. So skip the control connection from being
-						// considered as a required connection in this case.
-						continue
+				for {
+					err := conn.WriteControl(websocket.PingMessage, []byte("keepalive"), time.Now().Add(5*time.Second))
+					if err != nil {
+						return
 					}
 
-					if c == nil {
-						s.connsLock.Unlock()
-						return nil // Not all required connections connected yet.
-					}
+					<-t.C
 				}
+			}()
+		}
+
+		if fd == execWSControl {
+			s.waitControlConnected.Cancel() // Control connection connected.
+		}
+
+		for i, c := range s.conns {
+			if i == execWSControl && s.req.WaitForWS && !s.req.Interactive {
+				// Due to a historical bug in the LXC CLI command, we cannot force
+				// the client to connect a control socket when in non-interactive
+				// mode. This is because the older CLI tools did not connect this
+				// channel and so we would prevent the older CLIs connecting to
+				// newer servers. So skip the control connection from being
+				// considered as a required connection in this case.
+				continue
+			}
 
-				s.waitRequiredConnected.Cancel() // All required connections now connected.
+			if c == nil {
 				s.connsLock.Unlock()
-				return nil
-			} else if !found {
-				return fmt.Errorf("Unknown websocket number")
+				return nil // Not all required connections connected yet.
 			}
+		}
+
+		s.waitRequiredConnected.Cancel() // All required connections now connected.
+		s.connsLock.Unlock()
+		return nil
+	}
+
+	s.connsLock.Unlock()
+	_ = conn.Close()
+
+	if !found {
+		return fmt.Errorf("Unknown websocket number")
+	}
 
-			return fmt.Errorf("Websocket number already connected")
+	return fmt.Errorf("Websocket number already connected")
+}
+
+// fdBySecret returns the websocket number that the given secret belongs to.
+// Must be called with connsLock held.
+func (s *execWs) fdBySecret(secret string) (int, bool) {
+	for fd, fdSecret := range s.fds {
+		if secret == fdSecret {
+			return fd, true
 		}
 	}
 
-	/* If we didn't find the right secret, the user provided a bad one,
-	 * which 403, not 404, since this operation actually exists */
-	return os.ErrPermission
+	return 0, false
 }
 
 // Do connects to the websocket and executes the operation.
 func (s *execWs) Do(op *operations.Operation) error {
+	if s.req.Persistent {
+		return s.doPersistent(op)
+	}
+
 	// Once this function ends ensure that any connected websockets are closed.
 	defer func() {
 		s.connsLock.Lock()
@@ -178,85 +205,19 @@ func (s *execWs) Do(op *operations.Operation) error {
 		return fmt.Errorf("Timed out waiting for websockets to connect")
 	}
 
-	var err error
-	var ttys []*os.File
-	var ptys []*os.File
-
-	var stdin *os.File
-	var stdout *os.File
-	var stderr *os.File
-
-	if s.req.Interactive {
-		if s.instance.Type() == instancetype.Container {
-			// For containers, we setup a PTY on the LXD server.
-			ttys = make([]*os.File, 1)
-			ptys = make([]*os.File, 1)
-
-			var rootUID, rootGID int64
-			var devptsFd *os.File
-
-			c, ok := s.instance.(instance.Container)
-			if !ok {
-				return fmt.Errorf("Invalid instance type")
-			}
-
-			idmapset, err := c.CurrentIdmap()
-			if err != nil {
-				return err
-			}
-
-			if idmapset != nil {
-				rootUID, rootGID = idmapset.ShiftIntoNs(0, 0)
-			}
-
-			devptsFd, _ = c.DevptsFd()
-
-			if devptsFd != nil && s.s.OS.NativeTerminals {
-				ptys[0], ttys[0], err = shared.OpenPtyInDevpts(int(devptsFd.Fd()), rootUID, rootGID)
-				_ = devptsFd.Close()
-				devptsFd = nil
-			} else {
-				ptys[0], ttys[0], err = shared.OpenPty(rootUID, rootGID)
-			}
-
-			if err != nil {
-				return fmt.Errorf("Unable to open the PTY device: %w", err)
-			}
-
-			stdin = ttys[0]
-			stdout = ttys[0]
-			stderr = ttys[0]
-
-			if s.req.Width > 0 && s.req.Height > 0 {
-				_ = shared.SetSize(int(ptys[0].Fd()), s.req.Width, s.req.Height)
-			}
-		} else {
-			// For VMs we rely on the lxd-agent PTY running inside the VM guest.
-			ttys = make([]*os.File, 2)
-			ptys = make([]*os.File, 2)
-			for i := 0; i < len(ttys); i++ {
-				ptys[i], ttys[i], err = os.Pipe()
-				if err != nil {
-					return err
-				}
-			}
-
-			stdin = ptys[execWSStdin]
-			stdout = ttys[execWSStdout]
-		}
-	} else {
-		ttys = make([]*os.File, 3)
-		ptys = make([]*os.File, 3)
-		for i := 0; i < len(ttys); i++ {
-			ptys[i], ttys[i], err = os.Pipe()
-			if err != nil {
-				return err
-			}
+	if s.record {
+		recorder, err := newSessionRecorder(s.instance, "exec", op.ID(), s.requestor, s.req.Command, s.req.Environment, s.req.Width, s.req.Height)
+		if err != nil {
+			return err
 		}
 
-		stdin = ptys[execWSStdin]
-		stdout = ttys[execWSStdout]
-		stderr = ttys[execWSStderr]
+		s.recorder = recorder
+		defer func() { _ = recorder.Close() }()
+	}
+
+	ttys, ptys, stdin, stdout, stderr, err := s.openTTYs()
+	if err != nil {
+		return err
 	}
 
 	waitAttachedChildIsDead, markAttachedChildIsDead := context.WithCancel(context.Background())
@@ -383,32 +344,7 @@ func (s *execWs) Do(op *operations.Operation) error {
 				continue
 			}
 
-			// Only handle window-resize requests for interactive sessions.
-			if command.Command == "window-resize" && s.req.Interactive {
-				winchWidth, err := strconv.Atoi(command.Args["width"])
-				if err != nil {
-					l.Debug("Unable to extract window width", logger.Ctx{"err": err})
-					continue
-				}
-
-				winchHeight, err := strconv.Atoi(command.Args["height"])
-				if err != nil {
-					l.Debug("Unable to extract window height", logger.Ctx{"err": err})
-					continue
-				}
-
-				err = cmd.WindowResize(int(ptys[0].Fd()), winchWidth, winchHeight)
-				if err != nil {
-					l.Debug("Failed to set window size", logger.Ctx{"err": err, "width": winchWidth, "height": winchHeight})
-					continue
-				}
-			} else if command.Command == "signal" {
-				err := cmd.Signal(unix.Signal(command.Signal))
-				if err != nil {
-					l.Debug("Failed forwarding signal", logger.Ctx{"err": err, "signal": command.Signal})
-					continue
-				}
-			}
+			s.handleControl(cmd, int(ptys[0].Fd()), command, l)
 		}
 	}()
 
@@ -432,10 +368,22 @@ func (s *execWs) Do(op *operations.Operation) error {
 			if s.instance.Type() == instancetype.Container {
 				// For containers, we are running the command via the local LXD managed PTY and so
 				// need to use the same PTY handle for both read and write.
-				readDone, writeDone = ws.Mirror(conn, shared.NewExecWrapper(waitAttachedChildIsDead, ptys[0]))
+				var pty io.ReadWriteCloser = shared.NewExecWrapper(waitAttachedChildIsDead, ptys[0])
+				if s.recorder != nil {
+					pty = newSessionRecordedReadWriteCloser(pty, s.recorder)
+				}
+
+				readDone, writeDone = ws.Mirror(conn, pty)
 			} else {
-				readDone = ws.MirrorRead(conn, ptys[execWSStdout])
-				writeDone = ws.MirrorWrite(conn, ttys[execWSStdin])
+				var output io.Reader = ptys[execWSStdout]
+				var input io.Writer = ttys[execWSStdin]
+				if s.recorder != nil {
+					output = io.TeeReader(output, s.recorder)
+					input = io.MultiWriter(input, s.recorder.Stream("i"))
+				}
+
+				readDone = ws.MirrorRead(conn, output)
+				writeDone = ws.MirrorWrite(conn, input)
 			}
 
 			readErr = <-readDone
@@ -489,10 +437,20 @@ func (s *execWs) Do(op *operations.Operation) error {
 				}
 
 				if i == execWSStdin {
-					err = <-ws.MirrorWrite(conn, ttys[i])
+					var input io.Writer = ttys[i]
+					if s.recorder != nil {
+						input = io.MultiWriter(input, s.recorder.Stream("i"))
+					}
+
+					err = <-ws.MirrorWrite(conn, input)
 					_ = ttys[i].Close()
 				} else {
-					err = <-ws.MirrorRead(conn, shared.NewExecWrapper(waitAttachedChildIsDead, ptys[i]))
+					var output io.Reader = shared.NewExecWrapper(waitAttachedChildIsDead, ptys[i])
+					if s.recorder != nil {
+						output = io.TeeReader(output, s.recorder.Stream("o"))
+					}
+
+					err = <-ws.MirrorRead(conn, output)
 					_ = ptys[i].Close()
 					wgEOF.Done()
 				}
@@ -506,6 +464,120 @@ func (s *execWs) Do(op *operations.Operation) error {
 	return finisher(exitStatus, err)
 }
 
+// handleControl applies a message received on the control websocket to the running command.
+func (s *execWs) handleControl(cmd instance.Cmd, resizeFd int, command api.InstanceExecControl, l logger.Logger) {
+	// Only handle window-resize requests for interactive sessions.
+	if command.Command == "window-resize" && s.req.Interactive {
+		winchWidth, err := strconv.Atoi(command.Args["width"])
+		if err != nil {
+			l.Debug("Unable to extract window width", logger.Ctx{"err": err})
+			return
+		}
+
+		winchHeight, err := strconv.Atoi(command.Args["height"])
+		if err != nil {
+			l.Debug("Unable to extract window height", logger.Ctx{"err": err})
+			return
+		}
+
+		err = cmd.WindowResize(resizeFd, winchWidth, winchHeight)
+		if err != nil {
+			l.Debug("Failed to set window size", logger.Ctx{"err": err, "width": winchWidth, "height": winchHeight})
+			return
+		}
+
+		if s.recorder != nil {
+			s.recorder.Resize(winchWidth, winchHeight)
+		}
+	} else if command.Command == "signal" {
+		err := cmd.Signal(unix.Signal(command.Signal))
+		if err != nil {
+			l.Debug("Failed forwarding signal", logger.Ctx{"err": err, "signal": command.Signal})
+			return
+		}
+	}
+}
+
+// openTTYs sets up the TTYs or pipes that the command is run with, depending on the instance type and on whether the
+// command is interactive. It returns the sides used by the command (ttys) and by LXD (ptys), along with the stdin,
+// stdout and stderr to pass to the command.
+func (s *execWs) openTTYs() (ttys []*os.File, ptys []*os.File, stdin *os.File, stdout *os.File, stderr *os.File, err error) {
+	if s.req.Interactive {
+		if s.instance.Type() == instancetype.Container {
+			// For containers, we setup a PTY on the LXD server.
+			ttys = make([]*os.File, 1)
+			ptys = make([]*os.File, 1)
+
+			var rootUID, rootGID int64
+			var devptsFd *os.File
+
+			c, ok := s.instance.(instance.Container)
+			if !ok {
+				return nil, nil, nil, nil, nil, fmt.Errorf("Invalid instance type")
+			}
+
+			idmapset, err := c.CurrentIdmap()
+			if err != nil {
+				return nil, nil, nil, nil, nil, err
+			}
+
+			if idmapset != nil {
+				rootUID, rootGID = idmapset.ShiftIntoNs(0, 0)
+			}
+
+			devptsFd, _ = c.DevptsFd()
+
+			if devptsFd != nil && s.s.OS.NativeTerminals {
+				ptys[0], ttys[0], err = shared.OpenPtyInDevpts(int(devptsFd.Fd()), rootUID, rootGID)
+				_ = devptsFd.Close()
+				devptsFd = nil
+			} else {
+				ptys[0], ttys[0], err = shared.OpenPty(rootUID, rootGID)
+			}
+
+			if err != nil {
+				return nil, nil, nil, nil, nil, fmt.Errorf("Unable to open the PTY device: %w", err)
+			}
+
+			stdin = ttys[0]
+			stdout = ttys[0]
+			stderr = ttys[0]
+
+			if s.req.Width > 0 && s.req.Height > 0 {
+				_ = shared.SetSize(int(ptys[0].Fd()), s.req.Width, s.req.Height)
+			}
+		} else {
+			// For VMs we rely on the lxd-agent PTY running inside the VM guest.
+			ttys = make([]*os.File, 2)
+			ptys = make([]*os.File, 2)
+			for i := 0; i < len(ttys); i++ {
+				ptys[i], ttys[i], err = os.Pipe()
+				if err != nil {
+					return nil, nil, nil, nil, nil, err
+				}
+			}
+
+			stdin = ptys[execWSStdin]
+			stdout = ttys[execWSStdout]
+		}
+	} else {
+		ttys = make([]*os.File, 3)
+		ptys = make([]*os.File, 3)
+		for i := 0; i < len(ttys); i++ {
+			ptys[i], ttys[i], err = os.Pipe()
+			if err != nil {
+				return nil, nil, nil, nil, nil, err
+			}
+		}
+
+		stdin = ptys[execWSStdin]
+		stdout = ttys[execWSStdout]
+		stderr = ttys[execWSStderr]
+	}
+
+	return ttys, ptys, stdin, stdout, stderr, nil
+}
+
 // swagger:operation POST /1.0/instances/{name}/exec instances instance_exec_post
 //
 //	Run a command
@@ -582,6 +654,10 @@ func instanceExecPost(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(fmt.Errorf("Cannot use %q in combination with %q", "interactive", "record-output"))
 	}
 
+	if post.Persistent && (!post.Interactive || !post.WaitForWS) {
+		return response.BadRequest(fmt.Errorf("%q requires %q and %q", "persistent", "interactive", "wait-for-websocket"))
+	}
+
 	// Forward the request if the container is remote.
 	client, err := cluster.ConnectIfInstanceIsRemote(s, projectName, name, r, instanceType)
 	if err != nil {
@@ -702,6 +778,8 @@ func instanceExecPost(d *Daemon, r *http.Request) response.Response {
 
 		ws.instance = inst
 		ws.req = post
+		ws.requestor = request.CreateRequestor(r)
+		ws.record = sessionRecordingEnabled(inst)
 
 		resources := map[string][]api.URL{}
 		resources["instances"] = []api.URL{*api.NewURL().Path(version.APIVersion, "instances", ws.instance.Name())}
@@ -718,6 +796,8 @@ func instanceExecPost(d *Daemon, r *http.Request) response.Response {
 		return operations.OperationResponse(op)
 	}
 
+	requestor := request.CreateRequestor(r)
+
 	run := func(op *operations.Operation) error {
 		metadata := shared.Jmap{}
 
@@ -754,9 +834,39 @@ func instanceExecPost(d *Daemon, r *http.Request) response.Response {
 			}
 		}
 
+		// Record the output of the command if required, in addition to the record-output files.
+		cmdStdout, cmdStderr := stdout, stderr
+		finishRecording := func() {}
+		if sessionRecordingEnabled(inst) {
+			recorder, err := newSessionRecorder(inst, "exec", op.ID(), requestor, post.Command, post.Environment, post.Width, post.Height)
+			if err != nil {
+				return err
+			}
+
+			defer func() { _ = recorder.Close() }()
+
+			var finishStdout, finishStderr func()
+			cmdStdout, finishStdout, err = sessionRecordingPipe(recorder, stdout)
+			if err != nil {
+				return err
+			}
+
+			cmdStderr, finishStderr, err = sessionRecordingPipe(recorder, stderr)
+			if err != nil {
+				finishStdout()
+				return err
+			}
+
+			finishRecording = func() {
+				finishStdout()
+				finishStderr()
+			}
+		}
+
 		// Run the command.
-		cmd, err := inst.Exec(post, nil, stdout, stderr)
+		cmd, err := inst.Exec(post, nil, cmdStdout, cmdStderr)
 		if err != nil {
+			finishRecording()
 			return err
 		}
 
@@ -766,6 +876,8 @@ func instanceExecPost(d *Daemon, r *http.Request) response.Response {
 		exitStatus, cmdErr := cmd.Wait()
 		l.Debug("Instance process stopped", logger.Ctx{"err": cmdErr, "exitStatus": exitStatus})
 
+		finishRecording()
+
 		metadata["return"] = exitStatus
 		err = op.ExtendMetadata(metadata)
 		if err != nil {
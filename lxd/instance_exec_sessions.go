@@ -0,0 +1,840 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"golang.org/x/sys/unix"
+
+	"github.com/canonical/lxd/lxd/cluster"
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/instance/drivers"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/operations"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/cancel"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/version"
+	"github.com/canonical/lxd/shared/ws"
+)
+
+// execSessionOutputSize is the amount of recent output kept for a persistent exec session. It is replayed to
+// clients when they attach so they can redraw their terminal.
+const execSessionOutputSize = 64 * 1024
+
+// execSession is a persistent exec session, whose command keeps running when its client disconnects.
+type execSession struct {
+	ws        *execWs
+	op        *operations.Operation
+	cmd       instance.Cmd
+	resizeFd  int
+	createdAt time.Time
+
+	// done is closed once the command has exited and all of its output has been read.
+	done chan struct{}
+
+	mu             sync.Mutex
+	attached       bool
+	lastAttachedAt time.Time
+	output         []byte
+	data           *websocket.Conn
+}
+
+// execSessions holds the persistent exec sessions running on this member, indexed by operation ID.
+var execSessions = map[string]*execSession{}
+var execSessionsMu sync.Mutex
+
+// execSessionLoad returns the persistent exec session with the given ID of an instance.
+func execSessionLoad(projectName string, instanceName string, id string) (*execSession, error) {
+	execSessionsMu.Lock()
+	session, ok := execSessions[id]
+	execSessionsMu.Unlock()
+
+	if !ok || session.ws.instance.Project().Name != projectName || session.ws.instance.Name() != instanceName {
+		return nil, api.StatusErrorf(http.StatusNotFound, "Exec session not found")
+	}
+
+	return session, nil
+}
+
+// render returns the API representation of the session.
+func (e *execSession) render() api.InstanceExecSession {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return api.InstanceExecSession{
+		ID:             e.op.ID(),
+		Command:        e.ws.req.Command,
+		Attached:       e.attached,
+		CreatedAt:      e.createdAt,
+		LastAttachedAt: e.lastAttachedAt,
+		Operation:      e.op.URL(),
+	}
+}
+
+// writeOutput records the output of the command and sends it to the attached client, if any.
+func (e *execSession) writeOutput(buf []byte) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.output = append(e.output, buf...)
+	if len(e.output) > execSessionOutputSize {
+		e.output = e.output[len(e.output)-execSessionOutputSize:]
+	}
+
+	if e.data != nil {
+		err := e.data.WriteMessage(websocket.BinaryMessage, buf)
+		if err != nil {
+			logger.Debug("Failed sending exec session output", logger.Ctx{"session": e.op.ID(), "err": err})
+		}
+	}
+}
+
+// resetConns closes the websockets of the session and generates new secrets for them, so that only the next
+// client attaching to the session can connect.
+func (e *execSession) resetConns() error {
+	e.ws.connsLock.Lock()
+	defer e.ws.connsLock.Unlock()
+
+	return e.resetConnsLocked()
+}
+
+// resetConnsLocked is like resetConns but expects the caller to hold the lock of the websockets.
+func (e *execSession) resetConnsLocked() error {
+	s := e.ws
+
+	for fd, conn := range s.conns {
+		if conn != nil {
+			_ = conn.Close()
+		}
+
+		s.conns[fd] = nil
+
+		secret, err := shared.RandomCryptoString()
+		if err != nil {
+			return err
+		}
+
+		s.fds[fd] = secret
+	}
+
+	// Only renew the cancellers once used, as the session may be waiting on them for the next client.
+	if s.waitRequiredConnected.Err() != nil {
+		s.waitRequiredConnected = cancel.New(context.Background())
+	}
+
+	if s.waitControlConnected.Err() != nil {
+		s.waitControlConnected = cancel.New(context.Background())
+	}
+
+	return nil
+}
+
+// prepareAttach checks that no client is attached to the session and generates new secrets for the next client,
+// which also disconnects any client that didn't complete a previous attach. The check and the renewal of the secrets
+// happen atomically so that concurrent attach requests can't both succeed. It returns the new secrets.
+func (e *execSession) prepareAttach() (shared.Jmap, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.ws.connsLock.Lock()
+	defer e.ws.connsLock.Unlock()
+
+	if e.attached || e.ws.waitRequiredConnected.Err() != nil {
+		return nil, api.StatusErrorf(http.StatusConflict, "A client is already attached to the exec session")
+	}
+
+	err := e.resetConnsLocked()
+	if err != nil {
+		return nil, err
+	}
+
+	fds := shared.Jmap{}
+	for fd, secret := range e.ws.fds {
+		if fd == execWSControl {
+			fds[api.SecretNameControl] = secret
+		} else {
+			fds[strconv.Itoa(fd)] = secret
+		}
+	}
+
+	return fds, nil
+}
+
+// attach serves the client connected to the session until it detaches or the command exits.
+func (e *execSession) attach(input io.Writer, l logger.Logger) {
+	s := e.ws
+
+	s.connsLock.Lock()
+	data := s.conns[0]
+	control := s.conns[execWSControl]
+	s.connsLock.Unlock()
+
+	e.mu.Lock()
+	e.attached = true
+	e.lastAttachedAt = time.Now()
+	e.data = data
+
+	if len(e.output) > 0 {
+		_ = data.WriteMessage(websocket.BinaryMessage, e.output)
+	}
+
+	e.mu.Unlock()
+
+	l.Debug("Client attached to exec session")
+	defer l.Debug("Client detached from exec session")
+
+	detached := make(chan struct{})
+	var detachOnce sync.Once
+	detach := func() {
+		detachOnce.Do(func() { close(detached) })
+	}
+
+	// Mirror the input of the client to the command. The client sends a barrier when it detaches.
+	go func() {
+		_, _ = io.Copy(input, ws.NewWrapper(data))
+		detach()
+	}()
+
+	// Unlike regular exec, closing the control websocket detaches the client instead of killing the command.
+	go func() {
+		for {
+			mt, buf, err := control.ReadMessage()
+			if err != nil || mt == websocket.CloseMessage {
+				detach()
+				return
+			}
+
+			command := api.InstanceExecControl{}
+
+			err = json.Unmarshal(buf, &command)
+			if err != nil {
+				l.Debug("Failed to unmarshal control socket command", logger.Ctx{"err": err})
+				continue
+			}
+
+			s.handleControl(e.cmd, e.resizeFd, command, l)
+		}
+	}()
+
+	select {
+	case <-detached:
+	case <-e.done:
+	}
+
+	// Send the barrier so the client knows that all output was received.
+	e.mu.Lock()
+	_ = ws.NewWrapper(data).Close()
+	e.attached = false
+	e.data = nil
+	e.mu.Unlock()
+
+	err := e.resetConns()
+	if err != nil {
+		l.Warn("Failed resetting exec session websockets", logger.Ctx{"err": err})
+	}
+}
+
+// doPersistent runs the command of a persistent exec session. Unlike Do, the command keeps running when the client
+// disconnects, and clients can attach to it again until it exits.
+func (s *execWs) doPersistent(op *operations.Operation) error {
+	logger.Debug("Waiting for exec websockets to connect")
+	select {
+	case <-s.waitRequiredConnected.Done():
+	case <-time.After(time.Second * 5):
+		return fmt.Errorf("Timed out waiting for websockets to connect")
+	}
+
+	closeConns := func() {
+		s.connsLock.Lock()
+		for _, conn := range s.conns {
+			if conn != nil {
+				_ = conn.Close()
+			}
+		}
+		s.connsLock.Unlock()
+	}
+
+	if s.record {
+		recorder, err := newSessionRecorder(s.instance, "exec", op.ID(), s.requestor, s.req.Command, s.req.Environment, s.req.Width, s.req.Height)
+		if err != nil {
+			closeConns()
+			return err
+		}
+
+		s.recorder = recorder
+		defer func() { _ = recorder.Close() }()
+	}
+
+	ttys, ptys, stdin, stdout, stderr, err := s.openTTYs()
+	if err != nil {
+		closeConns()
+		return err
+	}
+
+	defer func() {
+		for _, pty := range ptys {
+			_ = pty.Close()
+		}
+	}()
+
+	closeTTYs := func() {
+		for _, tty := range ttys {
+			_ = tty.Close()
+		}
+	}
+
+	cmd, err := s.instance.Exec(s.req, stdin, stdout, stderr)
+	if err != nil {
+		closeTTYs()
+		closeConns()
+
+		return err
+	}
+
+	l := logger.AddContext(logger.Ctx{"project": s.instance.Project().Name, "instance": s.instance.Name(), "PID": cmd.PID(), "session": op.ID()})
+	l.Debug("Instance process started")
+
+	session := &execSession{
+		ws:        s,
+		op:        op,
+		cmd:       cmd,
+		resizeFd:  int(ptys[0].Fd()),
+		createdAt: time.Now(),
+		done:      make(chan struct{}),
+	}
+
+	execSessionsMu.Lock()
+	execSessions[op.ID()] = session
+	execSessionsMu.Unlock()
+
+	defer func() {
+		execSessionsMu.Lock()
+		delete(execSessions, op.ID())
+		execSessionsMu.Unlock()
+	}()
+
+	waitAttachedChildIsDead, markAttachedChildIsDead := context.WithCancel(context.Background())
+
+	var output io.Reader
+	var input io.Writer
+	if s.instance.Type() == instancetype.Container {
+		output = shared.NewExecWrapper(waitAttachedChildIsDead, ptys[0])
+		input = ptys[0]
+	} else {
+		output = ptys[execWSStdout]
+		input = ttys[execWSStdin]
+	}
+
+	if s.recorder != nil {
+		input = io.MultiWriter(input, s.recorder.Stream("i"))
+	}
+
+	// Read the output of the command for as long as it runs, whether a client is attached or not.
+	outputDone := make(chan struct{})
+	go func() {
+		defer close(outputDone)
+
+		buf := make([]byte, 32*1024)
+		for {
+			n, err := output.Read(buf)
+			if n > 0 {
+				session.writeOutput(buf[:n])
+
+				if s.recorder != nil {
+					_, _ = s.recorder.Write(buf[:n])
+				}
+			}
+
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	// Serve the clients attaching to the session until the command exits.
+	attachDone := make(chan struct{})
+	go func() {
+		defer close(attachDone)
+
+		for {
+			s.connsLock.Lock()
+			waitRequiredConnected := s.waitRequiredConnected
+			s.connsLock.Unlock()
+
+			select {
+			case <-waitRequiredConnected.Done():
+				session.attach(input, l)
+			case <-session.done:
+				return
+			}
+		}
+	}()
+
+	exitStatus, cmdErr := cmd.Wait()
+	l.Debug("Instance process stopped", logger.Ctx{"err": cmdErr, "exitStatus": exitStatus})
+
+	markAttachedChildIsDead()
+	closeTTYs()
+
+	<-outputDone
+	close(session.done)
+	<-attachDone
+
+	// Make VM disconnections (shutdown/reboot) match containers.
+	if cmdErr == drivers.ErrExecDisconnected {
+		exitStatus = 129
+		cmdErr = nil
+	}
+
+	err = op.ExtendMetadata(shared.Jmap{"return": exitStatus})
+	if err != nil {
+		return err
+	}
+
+	return cmdErr
+}
+
+// swagger:operation GET /1.0/instances/{name}/exec-sessions instances instance_exec_sessions_get
+//
+//	Get the exec sessions
+//
+//	Returns a list of persistent exec sessions (URLs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of endpoints
+//	          items:
+//	            type: string
+//	          example: |-
+//	            [
+//	              "/1.0/instances/foo/exec-sessions/b8d84888-1dc2-44fd-b386-7f679e171ba5"
+//	            ]
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+
+// swagger:operation GET /1.0/instances/{name}/exec-sessions?recursion=1 instances instance_exec_sessions_get_recursion1
+//
+//	Get the exec sessions
+//
+//	Returns a list of persistent exec sessions (structs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of exec sessions
+//	          items:
+//	            $ref: "#/definitions/InstanceExecSession"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceExecSessionsGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	instanceType, err := urlInstanceTypeDetect(r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	projectName := request.ProjectParam(r)
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if shared.IsSnapshot(name) {
+		return response.BadRequest(fmt.Errorf("Invalid instance name"))
+	}
+
+	// Handle requests targeted to an instance on a different member.
+	resp, err := forwardedResponseIfInstanceIsRemote(s, r, projectName, name, instanceType)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if resp != nil {
+		return resp
+	}
+
+	// Ensure instance exists.
+	_, err = instance.LoadByProjectAndName(s, projectName, name)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	execSessionsMu.Lock()
+	sessions := make([]*execSession, 0, len(execSessions))
+	for _, session := range execSessions {
+		if session.ws.instance.Project().Name == projectName && session.ws.instance.Name() == name {
+			sessions = append(sessions, session)
+		}
+	}
+
+	execSessionsMu.Unlock()
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].createdAt.Before(sessions[j].createdAt)
+	})
+
+	if util.IsRecursionRequest(r) {
+		result := make([]api.InstanceExecSession, 0, len(sessions))
+		for _, session := range sessions {
+			result = append(result, session.render())
+		}
+
+		return response.SyncResponse(true, result)
+	}
+
+	result := make([]string, 0, len(sessions))
+	for _, session := range sessions {
+		result = append(result, api.NewURL().Path(version.APIVersion, "instances", name, "exec-sessions", session.op.ID()).String())
+	}
+
+	return response.SyncResponse(true, result)
+}
+
+// swagger:operation GET /1.0/instances/{name}/exec-sessions/{id} instances instance_exec_session_get
+//
+//	Get the exec session
+//
+//	Gets a specific persistent exec session.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: Exec session
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/InstanceExecSession"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceExecSessionGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	instanceType, err := urlInstanceTypeDetect(r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	projectName := request.ProjectParam(r)
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	id, err := url.PathUnescape(mux.Vars(r)["id"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if shared.IsSnapshot(name) {
+		return response.BadRequest(fmt.Errorf("Invalid instance name"))
+	}
+
+	// Handle requests targeted to an instance on a different member.
+	resp, err := forwardedResponseIfInstanceIsRemote(s, r, projectName, name, instanceType)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if resp != nil {
+		return resp
+	}
+
+	session, err := execSessionLoad(projectName, name, id)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, session.render())
+}
+
+// swagger:operation POST /1.0/instances/{name}/exec-sessions/{id} instances instance_exec_session_post
+//
+//	Attach to the exec session
+//
+//	Attaches to a persistent exec session.
+//
+//	The returned operation is the exec operation of the session. Its metadata contains new secrets
+//	for the bi-directional websocket and the control websocket, which replace the previous ones.
+//	The output recently produced by the command is sent to the client when it connects.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: session
+//	    description: Attach request
+//	    schema:
+//	      $ref: "#/definitions/InstanceExecSessionPost"
+//	responses:
+//	  "202":
+//	    $ref: "#/responses/Operation"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "409":
+//	    description: A client is already attached to the session
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceExecSessionPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	instanceType, err := urlInstanceTypeDetect(r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	projectName := request.ProjectParam(r)
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	id, err := url.PathUnescape(mux.Vars(r)["id"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if shared.IsSnapshot(name) {
+		return response.BadRequest(fmt.Errorf("Invalid instance name"))
+	}
+
+	req := api.InstanceExecSessionPost{}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	// Forward the request if the instance is remote.
+	client, err := cluster.ConnectIfInstanceIsRemote(s, projectName, name, r, instanceType)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if client != nil {
+		url := api.NewURL().Path(version.APIVersion, "instances", name, "exec-sessions", id).Project(projectName)
+		resp, _, err := client.RawQuery("POST", url.String(), req, "")
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		opAPI, err := resp.MetadataAsOperation()
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		return operations.ForwardedOperationResponse(projectName, opAPI)
+	}
+
+	session, err := execSessionLoad(projectName, name, id)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	fds, err := session.prepareAttach()
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = session.op.ExtendMetadata(shared.Jmap{"fds": fds})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if session.ws.recorder != nil {
+		requestor := request.CreateRequestor(r)
+		session.ws.recorder.Marker(fmt.Sprintf("Attached by %s (%s) from %s", requestor.Username, requestor.Protocol, requestor.Address))
+	}
+
+	if req.Width > 0 && req.Height > 0 {
+		err = session.cmd.WindowResize(session.resizeFd, req.Width, req.Height)
+		if err != nil {
+			logger.Debug("Failed to set window size", logger.Ctx{"err": err, "session": id, "width": req.Width, "height": req.Height})
+		} else if session.ws.recorder != nil {
+			session.ws.recorder.Resize(req.Width, req.Height)
+		}
+	}
+
+	return operations.OperationResponse(session.op)
+}
+
+// swagger:operation DELETE /1.0/instances/{name}/exec-sessions/{id} instances instance_exec_session_delete
+//
+//	Terminate the exec session
+//
+//	Kills the command of a persistent exec session.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceExecSessionDelete(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	instanceType, err := urlInstanceTypeDetect(r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	projectName := request.ProjectParam(r)
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	id, err := url.PathUnescape(mux.Vars(r)["id"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if shared.IsSnapshot(name) {
+		return response.BadRequest(fmt.Errorf("Invalid instance name"))
+	}
+
+	// Handle requests targeted to an instance on a different member.
+	resp, err := forwardedResponseIfInstanceIsRemote(s, r, projectName, name, instanceType)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if resp != nil {
+		return resp
+	}
+
+	session, err := execSessionLoad(projectName, name, id)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = session.cmd.Signal(unix.SIGKILL)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.EmptySyncResponse
+}
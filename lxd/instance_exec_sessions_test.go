@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/cancel"
+)
+
+// newTestExecSession returns a persistent exec session of the given instance that no client is attached to.
+func newTestExecSession(projectName string, instanceName string) *execSession {
+	return &execSession{
+		ws: &execWs{
+			instance:              &dependencyTestInstance{projectName: projectName, name: instanceName},
+			conns:                 map[int]*websocket.Conn{execWSControl: nil, 0: nil},
+			fds:                   map[int]string{execWSControl: "control", 0: "data"},
+			waitRequiredConnected: cancel.New(context.Background()),
+			waitControlConnected:  cancel.New(context.Background()),
+		},
+		done: make(chan struct{}),
+	}
+}
+
+func Test_execSessionLoad(t *testing.T) {
+	session := newTestExecSession("p1", "c1")
+
+	execSessionsMu.Lock()
+	execSessions["session"] = session
+	execSessionsMu.Unlock()
+
+	defer func() {
+		execSessionsMu.Lock()
+		delete(execSessions, "session")
+		execSessionsMu.Unlock()
+	}()
+
+	loaded, err := execSessionLoad("p1", "c1", "session")
+	require.NoError(t, err)
+	assert.Same(t, session, loaded)
+
+	// Sessions are only found through their own instance.
+	for _, args := range [][]string{{"p1", "c1", "other"}, {"p1", "c2", "session"}, {"p2", "c1", "session"}} {
+		_, err = execSessionLoad(args[0], args[1], args[2])
+		assert.True(t, api.StatusErrorCheck(err, http.StatusNotFound), "Unexpected error for %v: %v", args, err)
+	}
+}
+
+func Test_execSessionWriteOutput(t *testing.T) {
+	session := newTestExecSession("p1", "c1")
+
+	session.writeOutput([]byte("hello"))
+	assert.Equal(t, []byte("hello"), session.output)
+
+	// Only the most recent output is kept.
+	session.writeOutput(bytes.Repeat([]byte("a"), execSessionOutputSize-2))
+	session.writeOutput([]byte("bc"))
+	assert.Len(t, session.output, execSessionOutputSize)
+	assert.Equal(t, []byte("aabc"), session.output[execSessionOutputSize-4:])
+	assert.Equal(t, byte('a'), session.output[0])
+}
+
+func Test_execSessionPrepareAttach(t *testing.T) {
+	session := newTestExecSession("p1", "c1")
+
+	// Attaching renews the secrets of the websockets.
+	fds, err := session.prepareAttach()
+	require.NoError(t, err)
+	assert.Len(t, fds, 2)
+	assert.NotEqual(t, "data", fds["0"])
+	assert.NotEqual(t, "control", fds[api.SecretNameControl])
+	assert.Equal(t, session.ws.fds[0], fds["0"])
+	assert.Equal(t, session.ws.fds[execWSControl], fds[api.SecretNameControl])
+
+	// Attaching fails while a client is connected.
+	session.ws.waitRequiredConnected.Cancel()
+	_, err = session.prepareAttach()
+	assert.True(t, api.StatusErrorCheck(err, http.StatusConflict), "Unexpected error: %v", err)
+
+	// Attaching fails while a client is attached.
+	session = newTestExecSession("p1", "c1")
+	session.attached = true
+	_, err = session.prepareAttach()
+	assert.True(t, api.StatusErrorCheck(err, http.StatusConflict), "Unexpected error: %v", err)
+
+	// Concurrent attach requests each get their own secrets, and the last one wins.
+	session = newTestExecSession("p1", "c1")
+
+	var wg sync.WaitGroup
+	results := make(chan string, 10)
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			fds, err := session.prepareAttach()
+			if err == nil {
+				results <- fds["0"].(string)
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(results)
+
+	secrets := map[string]bool{}
+	for secret := range results {
+		secrets[secret] = true
+	}
+
+	assert.Len(t, secrets, 10)
+	assert.True(t, secrets[session.ws.fds[0]])
+}
@@ -0,0 +1,797 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/db"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/lifecycle"
+	"github.com/canonical/lxd/lxd/project"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/validate"
+	"github.com/canonical/lxd/shared/version"
+)
+
+var instanceGroupsCmd = APIEndpoint{
+	Path:        "instance-groups",
+	MetricsType: entity.TypeInstance,
+
+	Get:  APIEndpointAction{Handler: instanceGroupsGet, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanViewInstances)},
+	Post: APIEndpointAction{Handler: instanceGroupsPost, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanCreateInstances)},
+}
+
+var instanceGroupCmd = APIEndpoint{
+	Path:        "instance-groups/{name}",
+	MetricsType: entity.TypeInstance,
+
+	Delete: APIEndpointAction{Handler: instanceGroupDelete, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanDeleteInstances)},
+	Get:    APIEndpointAction{Handler: instanceGroupGet, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanViewInstances)},
+	Put:    APIEndpointAction{Handler: instanceGroupPut, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanCreateInstances)},
+	Patch:  APIEndpointAction{Handler: instanceGroupPut, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanCreateInstances)},
+}
+
+// instanceGroupConfigKeys contains the validators of the instance group configuration keys.
+var instanceGroupConfigKeys = map[string]func(value string) error{
+	// lxdmeta:generate(entities=instance-group; group=group-conf; key=load_balancer.network)
+	// The load balancer must exist on this network.
+	// ---
+	//  type: string
+	//  shortdesc: Network of the load balancer to register the members in
+	"load_balancer.network": validate.IsAny,
+
+	// lxdmeta:generate(entities=instance-group; group=group-conf; key=load_balancer.listen_address)
+	//
+	// ---
+	//  type: string
+	//  shortdesc: Listen address of the load balancer to register the members in
+	"load_balancer.listen_address": validate.Optional(validate.IsNetworkAddress),
+
+	// lxdmeta:generate(entities=instance-group; group=group-conf; key=load_balancer.listen_port)
+	// For example: `80` or `8080-8090`
+	// ---
+	//  type: string
+	//  shortdesc: Load balancer port or ports to forward to the members
+	"load_balancer.listen_port": validate.Optional(validate.IsListOf(validate.IsNetworkPortRange)),
+
+	// lxdmeta:generate(entities=instance-group; group=group-conf; key=load_balancer.protocol)
+	// Possible values are `tcp` and `udp`.
+	// ---
+	//  type: string
+	//  defaultdesc: `tcp`
+	//  shortdesc: Protocol of the load balancer port
+	"load_balancer.protocol": validate.Optional(validate.IsOneOf("tcp", "udp")),
+
+	// lxdmeta:generate(entities=instance-group; group=group-conf; key=load_balancer.target_port)
+	// For example: `8080`
+	// ---
+	//  type: string
+	//  defaultdesc: same as {config:option}`instance-group-group-conf:load_balancer.listen_port`
+	//  shortdesc: Port or ports of the members to forward to
+	"load_balancer.target_port": validate.Optional(validate.IsListOf(validate.IsNetworkPortRange)),
+}
+
+// instanceGroupMemberName returns the name of the member of an instance group with the given index.
+func instanceGroupMemberName(groupName string, index int) string {
+	return fmt.Sprintf("%s-%d", groupName, index)
+}
+
+// instanceGroupValidate validates the name and the writable fields of an instance group.
+func instanceGroupValidate(name string, req api.InstanceGroupPut) error {
+	err := instancetype.ValidName(name, false)
+	if err != nil {
+		return api.StatusErrorf(http.StatusBadRequest, "Invalid instance group name: %w", err)
+	}
+
+	if req.Replicas < 0 {
+		return api.StatusErrorf(http.StatusBadRequest, "The number of replicas cannot be negative")
+	}
+
+	// Check that the members can be named after the group.
+	err = instancetype.ValidName(instanceGroupMemberName(name, max(req.Replicas, 1)), false)
+	if err != nil {
+		return api.StatusErrorf(http.StatusBadRequest, "Invalid instance group name for member naming: %w", err)
+	}
+
+	// Validate the template.
+	if !shared.ValueInSlice(req.Template.Type, []api.InstanceType{"", api.InstanceTypeContainer, api.InstanceTypeVM}) {
+		return api.StatusErrorf(http.StatusBadRequest, "Invalid instance type %q in template", req.Template.Type)
+	}
+
+	if req.Template.Source.Type == "" {
+		return api.StatusErrorf(http.StatusBadRequest, "The template must have a source")
+	}
+
+	for key := range req.Template.Config {
+		if strings.HasPrefix(key, instancetype.ConfigVolatilePrefix) {
+			return api.StatusErrorf(http.StatusBadRequest, "Volatile keys cannot be set in the template")
+		}
+	}
+
+	// Validate the config.
+	for key, value := range req.Config {
+		validator, ok := instanceGroupConfigKeys[key]
+		if !ok {
+			return api.StatusErrorf(http.StatusBadRequest, "Invalid instance group configuration key %q", key)
+		}
+
+		err := validator(value)
+		if err != nil {
+			return api.StatusErrorf(http.StatusBadRequest, "Invalid value for instance group configuration key %q: %w", key, err)
+		}
+	}
+
+	// Registering the members in a load balancer requires to know the load balancer and the port to use.
+	if req.Config["load_balancer.network"] != "" || req.Config["load_balancer.listen_address"] != "" || req.Config["load_balancer.listen_port"] != "" {
+		for _, key := range []string{"load_balancer.network", "load_balancer.listen_address", "load_balancer.listen_port"} {
+			if req.Config[key] == "" {
+				return api.StatusErrorf(http.StatusBadRequest, "%q is required to register the members in a load balancer", key)
+			}
+		}
+	}
+
+	return nil
+}
+
+// instanceGroupCheckAccess checks that the requester can use the source of the template and edit the load balancer
+// of an instance group, as the members are created and registered on their behalf.
+func instanceGroupCheckAccess(ctx context.Context, s *state.State, projectName string, req api.InstanceGroupPut) error {
+	source := req.Template.Source
+
+	sourceProject := source.Project
+	if sourceProject == "" {
+		sourceProject = projectName
+	}
+
+	var sourceURL *api.URL
+	var entitlement auth.Entitlement
+
+	switch source.Type {
+	case api.SourceTypeCopy:
+		instanceName, _, _ := api.GetParentAndSnapshotName(source.Source)
+		sourceURL = entity.InstanceURL(sourceProject, instanceName)
+		entitlement = auth.EntitlementCanView
+	case api.SourceTypeImage:
+		// Images from a remote server aren't subject to the local permissions.
+		if source.Server != "" {
+			break
+		}
+
+		if source.Fingerprint != "" {
+			sourceURL = entity.ImageURL(sourceProject, source.Fingerprint)
+			entitlement = auth.EntitlementCanView
+		} else if source.Alias != "" {
+			sourceURL = entity.ImageAliasURL(sourceProject, source.Alias)
+			entitlement = auth.EntitlementCanView
+		} else {
+			sourceURL = entity.ProjectURL(sourceProject)
+			entitlement = auth.EntitlementCanViewImages
+		}
+	}
+
+	if sourceURL != nil {
+		err := s.Authorizer.CheckPermission(ctx, sourceURL, entitlement)
+		if err != nil {
+			return err
+		}
+	}
+
+	networkName := req.Config["load_balancer.network"]
+	if networkName != "" {
+		networkProject, _, err := project.NetworkProject(s.DB.Cluster, projectName)
+		if err != nil {
+			return fmt.Errorf("Failed loading network project: %w", err)
+		}
+
+		err = s.Authorizer.CheckPermission(ctx, entity.NetworkURL(networkProject, networkName), auth.EntitlementCanEdit)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// instanceGroupLoad returns the instance group with the given name along with the URLs of its members.
+func instanceGroupLoad(ctx context.Context, tx *db.ClusterTx, projectName string, name string) (*api.InstanceGroup, error) {
+	_, group, err := tx.GetInstanceGroup(ctx, projectName, name)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := tx.GetInstanceGroupMembers(ctx, projectName, name)
+	if err != nil {
+		return nil, err
+	}
+
+	group.UsedBy = make([]string, 0, len(members))
+	for _, member := range members {
+		group.UsedBy = append(group.UsedBy, api.NewURL().Path(version.APIVersion, "instances", member).Project(projectName).String())
+	}
+
+	return group, nil
+}
+
+// API endpoints
+
+// swagger:operation GET /1.0/instance-groups instance-groups instance_groups_get
+//
+//	Get the instance groups
+//
+//	Returns a list of instance groups (URLs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of endpoints
+//	          items:
+//	            type: string
+//	          example: |-
+//	            [
+//	              "/1.0/instance-groups/web",
+//	              "/1.0/instance-groups/workers"
+//	            ]
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+
+// swagger:operation GET /1.0/instance-groups?recursion=1 instance-groups instance_groups_get_recursion1
+//
+//	Get the instance groups
+//
+//	Returns a list of instance groups (structs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of instance groups
+//	          items:
+//	            $ref: "#/definitions/InstanceGroup"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceGroupsGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+	recursion := util.IsRecursionRequest(r)
+
+	var groups []api.InstanceGroup
+	var names []string
+
+	err := s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		projectGroups, err := tx.GetInstanceGroupNames(ctx, projectName)
+		if err != nil {
+			return err
+		}
+
+		names = projectGroups[projectName]
+
+		if !recursion {
+			return nil
+		}
+
+		for _, name := range names {
+			group, err := instanceGroupLoad(ctx, tx, projectName, name)
+			if err != nil {
+				return err
+			}
+
+			groups = append(groups, *group)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed loading instance groups: %w", err))
+	}
+
+	if recursion {
+		if groups == nil {
+			groups = []api.InstanceGroup{}
+		}
+
+		return response.SyncResponse(true, groups)
+	}
+
+	groupURLs := make([]string, 0, len(names))
+	for _, name := range names {
+		groupURLs = append(groupURLs, fmt.Sprintf("/%s/instance-groups/%s", version.APIVersion, url.PathEscape(name)))
+	}
+
+	return response.SyncResponse(true, groupURLs)
+}
+
+// swagger:operation POST /1.0/instance-groups instance-groups instance_groups_post
+//
+//	Add an instance group
+//
+//	Creates a new instance group. The instances of the group are created in the background.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: group
+//	    description: Instance group
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/InstanceGroupsPost"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceGroupsPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	// The members of the instance groups are only managed by the cluster leader.
+	resp := forwardedResponseIfNotLeader(s, r)
+	if resp != nil {
+		return resp
+	}
+
+	projectName := request.ProjectParam(r)
+
+	req := api.InstanceGroupsPost{}
+
+	// Parse the request.
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	req.Normalise()
+
+	err = instanceGroupValidate(req.Name, req.InstanceGroupPut)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = instanceGroupCheckAccess(r.Context(), s, projectName, req.InstanceGroupPut)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	var group *api.InstanceGroup
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		// Check that the project exists.
+		_, err := dbCluster.GetProject(ctx, tx.Tx(), projectName)
+		if err != nil {
+			return fmt.Errorf("Failed loading project: %w", err)
+		}
+
+		_, _, err = tx.GetInstanceGroup(ctx, projectName, req.Name)
+		if err == nil {
+			return api.StatusErrorf(http.StatusConflict, "The instance group already exists")
+		}
+
+		_, err = tx.CreateInstanceGroup(ctx, projectName, &req)
+		if err != nil {
+			return fmt.Errorf("Failed creating instance group: %w", err)
+		}
+
+		group, err = instanceGroupLoad(ctx, tx, projectName, req.Name)
+
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Create the instances of the group in the background.
+	go func() {
+		err := instanceGroupReconcile(s.ShutdownCtx, d, projectName, group)
+		if err != nil {
+			logger.Warn("Failed reconciling instance group", logger.Ctx{"project": projectName, "group": req.Name, "err": err})
+		}
+	}()
+
+	lc := lifecycle.InstanceGroupCreated.Event(projectName, req.Name, request.CreateRequestor(r), nil)
+	s.Events.SendLifecycle(projectName, lc)
+
+	return response.SyncResponseLocation(true, nil, lc.Source)
+}
+
+// swagger:operation GET /1.0/instance-groups/{name} instance-groups instance_group_get
+//
+//	Get the instance group
+//
+//	Gets a specific instance group.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: Instance group
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/InstanceGroup"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceGroupGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	var group *api.InstanceGroup
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		group, err = instanceGroupLoad(ctx, tx, projectName, name)
+
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponseETag(true, group, group.Etag())
+}
+
+// swagger:operation PATCH /1.0/instance-groups/{name} instance-groups instance_group_patch
+//
+//	Partially update the instance group
+//
+//	Updates a subset of the instance group configuration.
+//	The instances of the group are created or deleted in the background to match the number of replicas.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: group
+//	    description: Instance group configuration
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/InstanceGroupPut"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "412":
+//	    $ref: "#/responses/PreconditionFailed"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+
+// swagger:operation PUT /1.0/instance-groups/{name} instance-groups instance_group_put
+//
+//	Update the instance group
+//
+//	Updates the entire instance group configuration.
+//	The instances of the group are created or deleted in the background to match the number of replicas.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: group
+//	    description: Instance group configuration
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/InstanceGroupPut"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "412":
+//	    $ref: "#/responses/PreconditionFailed"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceGroupPut(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	// The members of the instance groups are only managed by the cluster leader.
+	resp := forwardedResponseIfNotLeader(s, r)
+	if resp != nil {
+		return resp
+	}
+
+	projectName := request.ProjectParam(r)
+
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	var id int64
+	var current *api.InstanceGroup
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		id, current, err = tx.GetInstanceGroup(ctx, projectName, name)
+
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Validate the ETag.
+	err = util.EtagCheck(r, current.Etag())
+	if err != nil {
+		return response.PreconditionFailed(err)
+	}
+
+	req := api.InstanceGroupPut{}
+
+	if r.Method == http.MethodPatch {
+		// If the group is being updated via "patch" method, only the fields and config keys that are
+		// present in the request are modified.
+		req = current.Writable()
+		req.Config = make(map[string]string, len(current.Config))
+		for k, v := range current.Config {
+			req.Config[k] = v
+		}
+	}
+
+	// Decode the request.
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	req.Normalise()
+
+	err = instanceGroupValidate(name, req)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = instanceGroupCheckAccess(r.Context(), s, projectName, req)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Scaling down deletes instances.
+	if req.Replicas < current.Replicas {
+		err = s.Authorizer.CheckPermission(r.Context(), entity.ProjectURL(projectName), auth.EntitlementCanDeleteInstances)
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
+	var group *api.InstanceGroup
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		err := tx.UpdateInstanceGroup(ctx, id, req)
+		if err != nil {
+			return fmt.Errorf("Failed updating instance group: %w", err)
+		}
+
+		group, err = instanceGroupLoad(ctx, tx, projectName, name)
+
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Apply the changes in the background.
+	go func() {
+		l := logger.AddContext(logger.Ctx{"project": projectName, "group": name})
+
+		// Unregister the members from the load balancer they were previously registered in.
+		if !instanceGroupSameLoadBalancer(current.Config, group.Config) {
+			err := instanceGroupLoadBalancerSync(d, projectName, name, current.Config, nil)
+			if err != nil {
+				l.Warn("Failed unregistering instance group members from load balancer", logger.Ctx{"err": err})
+			}
+		}
+
+		err := instanceGroupReconcile(s.ShutdownCtx, d, projectName, group)
+		if err != nil {
+			l.Warn("Failed reconciling instance group", logger.Ctx{"err": err})
+		}
+	}()
+
+	s.Events.SendLifecycle(projectName, lifecycle.InstanceGroupUpdated.Event(projectName, name, request.CreateRequestor(r), map[string]any{"replicas": group.Replicas}))
+
+	return response.EmptySyncResponse
+}
+
+// swagger:operation DELETE /1.0/instance-groups/{name} instance-groups instance_group_delete
+//
+//	Delete the instance group
+//
+//	Removes the instance group. Its instances are deleted in the background.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceGroupDelete(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	// The members of the instance groups are only managed by the cluster leader.
+	resp := forwardedResponseIfNotLeader(s, r)
+	if resp != nil {
+		return resp
+	}
+
+	projectName := request.ProjectParam(r)
+
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	var group *api.InstanceGroup
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var id int64
+
+		id, group, err = tx.GetInstanceGroup(ctx, projectName, name)
+		if err != nil {
+			return err
+		}
+
+		return tx.DeleteInstanceGroup(ctx, id)
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Delete the instances of the group in the background.
+	go func() {
+		group.Replicas = 0
+
+		err := instanceGroupReconcile(s.ShutdownCtx, d, projectName, group)
+		if err != nil {
+			logger.Warn("Failed deleting instance group members", logger.Ctx{"project": projectName, "group": name, "err": err})
+		}
+	}()
+
+	s.Events.SendLifecycle(projectName, lifecycle.InstanceGroupDeleted.Event(projectName, name, request.CreateRequestor(r), nil))
+
+	return response.EmptySyncResponse
+}
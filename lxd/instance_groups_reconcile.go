@@ -0,0 +1,422 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/canonical/lxd/client"
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/task"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// instanceGroupsMu serializes the reconciliation of the instance groups. The instance groups are only reconciled
+// on the cluster leader, to which the instance group requests are forwarded.
+var instanceGroupsMu sync.Mutex
+
+// instanceGroupMemberIndex returns the index of an instance named after the member naming scheme of the given
+// instance group, or 0 if the name doesn't follow it.
+func instanceGroupMemberIndex(groupName string, instanceName string) int {
+	suffix, found := strings.CutPrefix(instanceName, groupName+"-")
+	if !found {
+		return 0
+	}
+
+	index, err := strconv.Atoi(suffix)
+	if err != nil || index < 1 || strconv.Itoa(index) != suffix {
+		return 0
+	}
+
+	return index
+}
+
+// instanceGroupConnect returns a client connected to the local LXD, using the project of the instance group.
+func instanceGroupConnect(d *Daemon, projectName string) (lxd.InstanceServer, error) {
+	c, err := lxd.ConnectLXDUnix(d.UnixSocket(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to connect to local LXD: %w", err)
+	}
+
+	return c.UseProject(projectName), nil
+}
+
+// instanceGroupReconcile creates, starts and deletes the instances of an instance group so that the number of
+// running members matches the number of replicas of the group, and registers the members in the configured load
+// balancer. The instances are managed through the API so that the usual placement, limits and checks apply.
+func instanceGroupReconcile(ctx context.Context, d *Daemon, projectName string, group *api.InstanceGroup) error {
+	instanceGroupsMu.Lock()
+	defer instanceGroupsMu.Unlock()
+
+	l := logger.AddContext(logger.Ctx{"project": projectName, "group": group.Name})
+
+	c, err := instanceGroupConnect(d, projectName)
+	if err != nil {
+		return err
+	}
+
+	instances, err := c.GetInstancesFull(api.InstanceTypeAny)
+	if err != nil {
+		return fmt.Errorf("Failed listing instances: %w", err)
+	}
+
+	members := make(map[int]api.InstanceFull)
+	names := make(map[string]bool, len(instances))
+	for _, inst := range instances {
+		names[inst.Name] = true
+
+		index := instanceGroupMemberIndex(group.Name, inst.Name)
+		if index > 0 && inst.Config["volatile.instance_group"] == group.Name {
+			members[index] = inst
+		}
+	}
+
+	var errs []error
+
+	// Create the missing members and start the stopped ones.
+	for index := 1; index <= group.Replicas; index++ {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		name := instanceGroupMemberName(group.Name, index)
+
+		member, ok := members[index]
+		if ok {
+			if member.StatusCode != api.Stopped {
+				continue
+			}
+
+			l.Info("Starting instance group member", logger.Ctx{"instance": name})
+
+			op, err := c.UpdateInstanceState(name, api.InstanceStatePut{Action: "start", Timeout: -1}, "")
+			if err == nil {
+				err = op.Wait()
+			}
+
+			if err != nil {
+				errs = append(errs, fmt.Errorf("Failed starting instance %q: %w", name, err))
+			}
+
+			continue
+		}
+
+		if names[name] {
+			errs = append(errs, fmt.Errorf("Instance %q already exists and isn't a member of the group", name))
+			continue
+		}
+
+		l.Info("Creating instance group member", logger.Ctx{"instance": name})
+
+		config := make(map[string]string, len(group.Template.Config)+1)
+		for k, v := range group.Template.Config {
+			config[k] = v
+		}
+
+		config["volatile.instance_group"] = group.Name
+
+		req := api.InstancesPost{
+			Name:   name,
+			Type:   group.Template.Type,
+			Source: group.Template.Source,
+			InstancePut: api.InstancePut{
+				Profiles: group.Template.Profiles,
+				Config:   config,
+				Devices:  group.Template.Devices,
+			},
+			Start: true,
+		}
+
+		op, err := c.CreateInstance(req)
+		if err == nil {
+			err = op.Wait()
+		}
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("Failed creating instance %q: %w", name, err))
+		}
+	}
+
+	// Delete the members beyond the number of replicas, highest index first.
+	indexes := make([]int, 0, len(members))
+	for index := range members {
+		if index > group.Replicas {
+			indexes = append(indexes, index)
+		}
+	}
+
+	slices.Sort(indexes)
+	slices.Reverse(indexes)
+
+	for _, index := range indexes {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		member := members[index]
+
+		l.Info("Deleting instance group member", logger.Ctx{"instance": member.Name})
+
+		if member.StatusCode != api.Stopped {
+			op, err := c.UpdateInstanceState(member.Name, api.InstanceStatePut{Action: "stop", Force: true, Timeout: -1}, "")
+			if err == nil {
+				err = op.Wait()
+			}
+
+			if err != nil {
+				errs = append(errs, fmt.Errorf("Failed stopping instance %q: %w", member.Name, err))
+				continue
+			}
+		}
+
+		op, err := c.DeleteInstance(member.Name)
+		if err == nil {
+			err = op.Wait()
+		}
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("Failed deleting instance %q: %w", member.Name, err))
+		}
+	}
+
+	// Register the current members in the load balancer.
+	if group.Config["load_balancer.network"] != "" {
+		instances, err := c.GetInstancesFull(api.InstanceTypeAny)
+		if err != nil {
+			return fmt.Errorf("Failed listing instances: %w", err)
+		}
+
+		addresses := make(map[string]string)
+		for _, inst := range instances {
+			index := instanceGroupMemberIndex(group.Name, inst.Name)
+			if index < 1 || index > group.Replicas || inst.Config["volatile.instance_group"] != group.Name {
+				continue
+			}
+
+			address := instanceGroupMemberAddress(inst, group.Config["load_balancer.network"])
+			if address != "" {
+				addresses[inst.Name] = address
+			}
+		}
+
+		err = instanceGroupLoadBalancerSync(d, projectName, group.Name, group.Config, addresses)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("Failed registering members in load balancer: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// instanceGroupMemberAddress returns the address of a running instance on the given network, preferring IPv4.
+// Returns an empty string if the instance has no global address on that network.
+func instanceGroupMemberAddress(inst api.InstanceFull, networkName string) string {
+	if inst.State == nil {
+		return ""
+	}
+
+	for devName, dev := range inst.ExpandedDevices {
+		if dev["type"] != "nic" || dev["network"] != networkName {
+			continue
+		}
+
+		hwaddr := inst.ExpandedConfig["volatile."+devName+".hwaddr"]
+
+		for _, family := range []string{"inet", "inet6"} {
+			for _, nic := range inst.State.Network {
+				if hwaddr == "" || !strings.EqualFold(nic.Hwaddr, hwaddr) {
+					continue
+				}
+
+				for _, addr := range nic.Addresses {
+					if addr.Family == family && addr.Scope == "global" && net.ParseIP(addr.Address) != nil {
+						return addr.Address
+					}
+				}
+			}
+		}
+	}
+
+	return ""
+}
+
+// instanceGroupSameLoadBalancer returns whether two instance group configurations use the same load balancer port.
+func instanceGroupSameLoadBalancer(oldConfig map[string]string, newConfig map[string]string) bool {
+	for _, key := range []string{"load_balancer.network", "load_balancer.listen_address", "load_balancer.listen_port", "load_balancer.protocol"} {
+		if oldConfig[key] != newConfig[key] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// instanceGroupLoadBalancerSync updates the load balancer configured for an instance group so that its members
+// are its only backends on the configured port. The addresses map holds the address of each member to register,
+// passing an empty map removes the group from the load balancer.
+func instanceGroupLoadBalancerSync(d *Daemon, projectName string, groupName string, config map[string]string, addresses map[string]string) error {
+	networkName := config["load_balancer.network"]
+	listenAddress := config["load_balancer.listen_address"]
+	if networkName == "" || listenAddress == "" {
+		return nil
+	}
+
+	protocol := config["load_balancer.protocol"]
+	if protocol == "" {
+		protocol = "tcp"
+	}
+
+	c, err := instanceGroupConnect(d, projectName)
+	if err != nil {
+		return err
+	}
+
+	lb, etag, err := c.GetNetworkLoadBalancer(networkName, listenAddress)
+	if err != nil {
+		return fmt.Errorf("Failed getting load balancer %q on network %q: %w", listenAddress, networkName, err)
+	}
+
+	current := lb.Writable()
+	put := lb.Writable()
+
+	// Replace the backends of the group with its current members.
+	put.Backends = make([]api.NetworkLoadBalancerBackend, 0, len(current.Backends)+len(addresses))
+	for _, backend := range current.Backends {
+		if instanceGroupMemberIndex(groupName, backend.Name) == 0 {
+			put.Backends = append(put.Backends, backend)
+		}
+	}
+
+	backendNames := make([]string, 0, len(addresses))
+	for name := range addresses {
+		backendNames = append(backendNames, name)
+	}
+
+	slices.Sort(backendNames)
+
+	for _, name := range backendNames {
+		put.Backends = append(put.Backends, api.NetworkLoadBalancerBackend{
+			Name:          name,
+			Description:   fmt.Sprintf("Member of instance group %q", groupName),
+			TargetAddress: addresses[name],
+			TargetPort:    config["load_balancer.target_port"],
+		})
+	}
+
+	// Replace the port of the group so that it forwards to the current members.
+	put.Ports = make([]api.NetworkLoadBalancerPort, 0, len(current.Ports)+1)
+	for _, port := range current.Ports {
+		if port.Protocol == protocol && port.ListenPort == config["load_balancer.listen_port"] {
+			continue
+		}
+
+		// Forget about the members that are no longer registered in the other ports.
+		port.TargetBackend = shared.RemoveElementsFromSlice(slices.Clone(port.TargetBackend), instanceGroupStaleBackends(groupName, port.TargetBackend, addresses)...)
+		put.Ports = append(put.Ports, port)
+	}
+
+	if len(backendNames) > 0 {
+		put.Ports = append(put.Ports, api.NetworkLoadBalancerPort{
+			Description:   fmt.Sprintf("Instance group %q", groupName),
+			Protocol:      protocol,
+			ListenPort:    config["load_balancer.listen_port"],
+			TargetBackend: backendNames,
+		})
+	}
+
+	if reflect.DeepEqual(current, put) {
+		return nil
+	}
+
+	return c.UpdateNetworkLoadBalancer(networkName, listenAddress, put, etag)
+}
+
+// instanceGroupStaleBackends returns the backends of the list that belong to the instance group but aren't
+// registered anymore.
+func instanceGroupStaleBackends(groupName string, backends []string, addresses map[string]string) []string {
+	var stale []string
+	for _, backend := range backends {
+		_, ok := addresses[backend]
+		if !ok && instanceGroupMemberIndex(groupName, backend) > 0 {
+			stale = append(stale, backend)
+		}
+	}
+
+	return stale
+}
+
+func instanceGroupsReconcileTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := d.State()
+
+		// Only one cluster member reconciles the instance groups.
+		leaderInfo, err := s.LeaderInfo()
+		if err != nil {
+			logger.Error("Failed to determine cluster leader", logger.Ctx{"err": err})
+			return
+		}
+
+		if leaderInfo.Clustered && !leaderInfo.Leader {
+			return
+		}
+
+		var groups map[string][]*api.InstanceGroup
+
+		err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			names, err := tx.GetInstanceGroupNames(ctx, "")
+			if err != nil {
+				return err
+			}
+
+			groups = make(map[string][]*api.InstanceGroup, len(names))
+			for projectName, projectGroups := range names {
+				for _, name := range projectGroups {
+					_, group, err := tx.GetInstanceGroup(ctx, projectName, name)
+					if err != nil {
+						return err
+					}
+
+					groups[projectName] = append(groups[projectName], group)
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			logger.Error("Failed loading instance groups", logger.Ctx{"err": err})
+			return
+		}
+
+		for projectName, projectGroups := range groups {
+			for _, group := range projectGroups {
+				err := instanceGroupReconcile(ctx, d, projectName, group)
+				if err != nil {
+					logger.Warn("Failed reconciling instance group", logger.Ctx{"project": projectName, "group": group.Name, "err": err})
+				}
+			}
+		}
+	}
+
+	first := true
+	schedule := func() (time.Duration, error) {
+		interval := time.Minute
+
+		if first {
+			first = false
+			return interval, task.ErrSkip
+		}
+
+		return interval, nil
+	}
+
+	return f, schedule
+}
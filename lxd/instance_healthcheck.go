@@ -0,0 +1,364 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/canonical/lxd/lxd/instance"
+	instanceDrivers "github.com/canonical/lxd/lxd/instance/drivers"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/lifecycle"
+	"github.com/canonical/lxd/lxd/project"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/task"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// Health states stored in volatile.last_state.health.
+const (
+	instanceHealthHealthy   = "healthy"
+	instanceHealthUnhealthy = "unhealthy"
+)
+
+// instanceHealthState tracks the health checks of a running instance.
+type instanceHealthState struct {
+	startedAt time.Time // When the instance was started, used to detect restarts.
+	lastCheck time.Time
+	checks    int
+	failures  int
+	health    string
+	running   bool
+}
+
+// Health check state of the local instances, keyed by project and instance name.
+var instanceHealthStatesMu sync.Mutex
+var instanceHealthStates = map[string]*instanceHealthState{}
+
+// instanceHealthConfigInt returns the integer value of an instance health check setting or its default.
+func instanceHealthConfigInt(config map[string]string, key string, defaultValue int) int {
+	value, err := strconv.Atoi(config[key])
+	if err != nil {
+		return defaultValue
+	}
+
+	return value
+}
+
+func instanceHealthCheckTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := d.State()
+
+		instances, err := instance.LoadNodeAll(s, instancetype.Any)
+		if err != nil {
+			logger.Error("Failed loading instances for health checks", logger.Ctx{"err": err})
+			return
+		}
+
+		seen := make(map[string]bool)
+
+		for _, inst := range instances {
+			config := inst.ExpandedConfig()
+
+			// Clear the health state of instances that no longer have a health check.
+			if config["healthcheck.type"] == "" {
+				if inst.LocalConfig()["volatile.last_state.health"] != "" {
+					err := inst.VolatileSet(map[string]string{"volatile.last_state.health": ""})
+					if err != nil {
+						logger.Warn("Failed clearing instance health state", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "err": err})
+					}
+				}
+
+				continue
+			}
+
+			if !inst.IsRunning() || inst.IsFrozen() {
+				continue
+			}
+
+			key := project.Instance(inst.Project().Name, inst.Name())
+			seen[key] = true
+
+			interval := time.Duration(instanceHealthConfigInt(config, "healthcheck.interval", 30)) * time.Second
+
+			instanceHealthStatesMu.Lock()
+
+			// Start tracking from scratch when the instance was (re)started.
+			hs := instanceHealthStates[key]
+			if hs == nil || !hs.startedAt.Equal(inst.LastUsedDate()) {
+				hs = &instanceHealthState{startedAt: inst.LastUsedDate(), health: inst.LocalConfig()["volatile.last_state.health"]}
+				instanceHealthStates[key] = hs
+			}
+
+			if hs.running || time.Since(hs.lastCheck) < interval {
+				instanceHealthStatesMu.Unlock()
+				continue
+			}
+
+			hs.running = true
+			hs.lastCheck = time.Now()
+			instanceHealthStatesMu.Unlock()
+
+			go instanceHealthCheck(ctx, s, inst, hs)
+		}
+
+		// Forget about the instances that are no longer checked.
+		instanceHealthStatesMu.Lock()
+		for key := range instanceHealthStates {
+			if !seen[key] {
+				delete(instanceHealthStates, key)
+			}
+		}
+
+		instanceHealthStatesMu.Unlock()
+	}
+
+	return f, task.Every(10 * time.Second)
+}
+
+// instanceHealthCheck runs the health check of an instance, records any change of its health and applies its
+// restart policy when it becomes unhealthy.
+func instanceHealthCheck(ctx context.Context, s *state.State, inst instance.Instance, hs *instanceHealthState) {
+	l := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
+
+	defer func() {
+		instanceHealthStatesMu.Lock()
+		hs.running = false
+		instanceHealthStatesMu.Unlock()
+	}()
+
+	// Clear the health state left from before the instance was started, so that its new health is reported.
+	if hs.checks == 0 && hs.health != "" {
+		err := inst.VolatileSet(map[string]string{"volatile.last_state.health": ""})
+		if err != nil {
+			l.Warn("Failed clearing instance health state", logger.Ctx{"err": err})
+		}
+
+		hs.health = ""
+	}
+
+	checkErr := instanceHealthCheckRun(ctx, inst)
+
+	instanceHealthStatesMu.Lock()
+	hs.checks++
+	if checkErr != nil {
+		hs.failures++
+	} else {
+		hs.failures = 0
+	}
+
+	failures := hs.failures
+	instanceHealthStatesMu.Unlock()
+
+	health := instanceHealthHealthy
+	if checkErr != nil {
+		l.Debug("Instance health check failed", logger.Ctx{"err": checkErr, "failures": failures})
+
+		if failures < instanceHealthConfigInt(inst.ExpandedConfig(), "healthcheck.threshold", 3) {
+			return
+		}
+
+		health = instanceHealthUnhealthy
+	}
+
+	if health == hs.health {
+		return
+	}
+
+	err := inst.VolatileSet(map[string]string{"volatile.last_state.health": health})
+	if err != nil {
+		l.Error("Failed recording instance health state", logger.Ctx{"err": err})
+		return
+	}
+
+	hs.health = health
+
+	ctxMap := map[string]any{"health": health}
+	if checkErr != nil {
+		ctxMap["error"] = checkErr.Error()
+		l.Warn("Instance is unhealthy", logger.Ctx{"err": checkErr, "failures": failures})
+	} else {
+		l.Info("Instance is healthy")
+	}
+
+	s.Events.SendLifecycle(inst.Project().Name, lifecycle.InstanceHealthChanged.Event(inst, ctxMap))
+
+	if health == instanceHealthUnhealthy {
+		instanceDrivers.RestartPolicyApply(s, inst, true)
+	}
+}
+
+// instanceHealthCheckRun runs the configured health check of an instance once.
+// Returns nil if the instance is healthy, or the reason why the check failed.
+func instanceHealthCheckRun(ctx context.Context, inst instance.Instance) error {
+	config := inst.ExpandedConfig()
+
+	timeout := time.Duration(instanceHealthConfigInt(config, "healthcheck.timeout", 10)) * time.Second
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	switch config["healthcheck.type"] {
+	case "exec":
+		req := api.InstanceExecPost{
+			Command:     []string{"sh", "-c", config["healthcheck.command"]},
+			Environment: map[string]string{"PATH": "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"},
+		}
+
+		// The output of the command isn't used, only its exit status.
+		devNull, err := os.OpenFile(os.DevNull, os.O_RDWR, 0)
+		if err != nil {
+			return fmt.Errorf("Failed opening %q: %w", os.DevNull, err)
+		}
+
+		defer func() { _ = devNull.Close() }()
+
+		cmd, err := inst.Exec(req, devNull, devNull, devNull)
+		if err != nil {
+			return fmt.Errorf("Failed running health check command: %w", err)
+		}
+
+		type result struct {
+			exitStatus int
+			err        error
+		}
+
+		done := make(chan result, 1)
+		go func() {
+			exitStatus, err := cmd.Wait()
+			done <- result{exitStatus: exitStatus, err: err}
+		}()
+
+		select {
+		case <-ctx.Done():
+			_ = cmd.Signal(unix.SIGKILL)
+			return fmt.Errorf("Health check command timed out after %s", timeout)
+		case res := <-done:
+			if res.err != nil {
+				return fmt.Errorf("Failed running health check command: %w", res.err)
+			}
+
+			if res.exitStatus != 0 {
+				return fmt.Errorf("Health check command exited with status %d", res.exitStatus)
+			}
+		}
+
+	case "tcp":
+		dialer, err := instanceHealthCheckDialer(inst)
+		if err != nil {
+			return err
+		}
+
+		conn, err := dialer.DialContext(ctx, "tcp", config["healthcheck.address"])
+		if err != nil {
+			logger.Debug("Health check connection failed", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "err": err})
+			return fmt.Errorf("Failed connecting to %q", config["healthcheck.address"])
+		}
+
+		_ = conn.Close()
+
+	case "http":
+		dialer, err := instanceHealthCheckDialer(inst)
+		if err != nil {
+			return err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, config["healthcheck.url"], nil)
+		if err != nil {
+			return err
+		}
+
+		// Only connect to the instance directly, without using a proxy or following redirects.
+		client := &http.Client{
+			Transport: &http.Transport{
+				DialContext: dialer.DialContext,
+			},
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		}
+
+		defer client.CloseIdleConnections()
+
+		resp, err := client.Do(req)
+		if err != nil {
+			logger.Debug("Health check request failed", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "err": err})
+			return fmt.Errorf("Failed requesting %q", config["healthcheck.url"])
+		}
+
+		_ = resp.Body.Close()
+
+		if resp.StatusCode >= http.StatusBadRequest {
+			return fmt.Errorf("Request to %q returned status %d", config["healthcheck.url"], resp.StatusCode)
+		}
+
+	case "agent":
+		vm, ok := inst.(instance.VM)
+		if !ok {
+			return fmt.Errorf("The agent health check is only supported for virtual machines")
+		}
+
+		err := vm.AgentHeartbeat()
+		if err != nil {
+			return fmt.Errorf("Agent not responding: %w", err)
+		}
+
+	default:
+		return fmt.Errorf("Unknown health check type %q", config["healthcheck.type"])
+	}
+
+	return nil
+}
+
+// instanceHealthCheckDialer returns a dialer that can only connect to the global addresses of the instance's own
+// network interfaces, so that the health checks can't be used to reach other hosts from the LXD server.
+func instanceHealthCheckDialer(inst instance.Instance) (*net.Dialer, error) {
+	hostInterfaces, _ := net.Interfaces()
+
+	instState, err := inst.RenderState(hostInterfaces)
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting instance addresses: %w", err)
+	}
+
+	allowedIPs := []net.IP{}
+	for _, nic := range instState.Network {
+		for _, address := range nic.Addresses {
+			if address.Scope != "global" {
+				continue
+			}
+
+			ip := net.ParseIP(address.Address)
+			if ip != nil {
+				allowedIPs = append(allowedIPs, ip)
+			}
+		}
+	}
+
+	dialer := &net.Dialer{
+		Control: func(network string, address string, c syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+
+			ip := net.ParseIP(host)
+			for _, allowedIP := range allowedIPs {
+				if allowedIP.Equal(ip) {
+					return nil
+				}
+			}
+
+			return fmt.Errorf("Address %q is not an address of the instance", host)
+		},
+	}
+
+	return dialer, nil
+}
@@ -1,12 +1,16 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 
@@ -198,6 +202,11 @@ func instanceLogsGet(d *Daemon, r *http.Request) response.Response {
 //	    description: Project name
 //	    type: string
 //	    example: default
+//	  - in: query
+//	    name: follow
+//	    description: Keep the connection open and stream data appended to the log file
+//	    type: boolean
+//	    example: true
 //	responses:
 //	  "200":
 //	     description: Raw file
@@ -257,13 +266,17 @@ func instanceLogGet(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(fmt.Errorf("Log file name %q not valid", file))
 	}
 
+	s.Events.SendLifecycle(projectName, lifecycle.InstanceLogRetrieved.Event(file, inst, request.CreateRequestor(r), nil))
+
+	if shared.IsTrue(request.QueryParam(r, "follow")) {
+		return logFollowResponse(r, filepath.Join(inst.LogPath(), file))
+	}
+
 	ent := response.FileResponseEntry{
 		Path:     filepath.Join(inst.LogPath(), file),
 		Filename: file,
 	}
 
-	s.Events.SendLifecycle(projectName, lifecycle.InstanceLogRetrieved.Event(file, inst, request.CreateRequestor(r), nil))
-
 	return response.FileResponse([]response.FileResponseEntry{ent}, nil)
 }
 
@@ -661,6 +674,110 @@ func instanceExecOutputDelete(d *Daemon, r *http.Request) response.Response {
 	return response.EmptySyncResponse
 }
 
+// logFollowInterval is how often a followed log file is checked for new data.
+const logFollowInterval = 250 * time.Millisecond
+
+// logFollowResponse returns a response that streams the content of the log file at path, followed by any data
+// appended to it, until the client disconnects.
+// The log file doesn't need to exist yet. If it is truncated or replaced, for example when the instance is
+// restarted, the stream continues from the start of the new content.
+func logFollowResponse(r *http.Request, path string) response.Response {
+	return response.ManualResponse(func(w http.ResponseWriter) error {
+		// Write header to avoid client side timeouts.
+		w.Header().Set("Connection", "keep-alive")
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.WriteHeader(http.StatusOK)
+
+		f, ok := w.(http.Flusher)
+		if !ok {
+			return fmt.Errorf("http.ResponseWriter is not type http.Flusher")
+		}
+
+		f.Flush()
+
+		var file *os.File
+		defer func() {
+			if file != nil {
+				_ = file.Close()
+			}
+		}()
+
+		ticker := time.NewTicker(logFollowInterval)
+		defer ticker.Stop()
+
+		for {
+			// Switch to the file currently at path if it was created or replaced.
+			// The rest of the previous file is sent first so that no data is lost.
+			pathInfo, err := os.Stat(path)
+			if err == nil {
+				var fileInfo fs.FileInfo
+				if file != nil {
+					fileInfo, err = file.Stat()
+					if err != nil {
+						return err
+					}
+				}
+
+				if fileInfo == nil || !os.SameFile(pathInfo, fileInfo) {
+					if file != nil {
+						_, err = io.Copy(w, file)
+						if err != nil {
+							return err
+						}
+
+						f.Flush()
+						_ = file.Close()
+						file = nil
+					}
+
+					file, err = os.Open(path)
+					if err != nil {
+						return err
+					}
+				}
+			} else if !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+
+			if file != nil {
+				fileInfo, err := file.Stat()
+				if err != nil {
+					return err
+				}
+
+				// Start over if the file was truncated.
+				offset, err := file.Seek(0, io.SeekCurrent)
+				if err != nil {
+					return err
+				}
+
+				if fileInfo.Size() < offset {
+					_, err = file.Seek(0, io.SeekStart)
+					if err != nil {
+						return err
+					}
+				}
+
+				n, err := io.Copy(w, file)
+				if err != nil {
+					return err
+				}
+
+				if n > 0 {
+					f.Flush()
+				}
+			}
+
+			select {
+			case <-r.Context().Done():
+				return nil
+			case <-ticker.C:
+			}
+		}
+	})
+}
+
 func validLogFileName(fname string) bool {
 	if strings.Contains(fname, "/") || strings.Contains(fname, "\\") || strings.Contains(fname, "..") {
 		return false
@@ -15,6 +15,7 @@ import (
 	"github.com/canonical/lxd/lxd/db/cluster"
 	deviceConfig "github.com/canonical/lxd/lxd/device/config"
 	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/lxd/project/limits"
 	"github.com/canonical/lxd/lxd/request"
 	"github.com/canonical/lxd/lxd/response"
@@ -210,6 +211,11 @@ func instancePatch(d *Daemon, r *http.Request) response.Response {
 			apiProfiles = append(apiProfiles, *apiProfile)
 		}
 
+		err = placementGroupCheckExists(ctx, tx, projectName, instancetype.ExpandInstanceConfig(nil, req.Config, apiProfiles))
+		if err != nil {
+			return err
+		}
+
 		return limits.AllowInstanceUpdate(ctx, s.GlobalConfig, tx, projectName, name, req, c.LocalConfig())
 	})
 	if err != nil {
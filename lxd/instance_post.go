@@ -223,6 +223,18 @@ func instancePost(d *Daemon, r *http.Request) response.Response {
 				if err != nil {
 					return err
 				}
+
+				// Only keep the members that satisfy the placement group of the instance.
+				candidateMembers, err = placementGroupCandidates(ctx, tx, projectName, name, inst.ExpandedConfig(), candidateMembers)
+				if err != nil {
+					return err
+				}
+			} else {
+				// Check that the target member satisfies the placement group of the instance.
+				err = placementGroupCheckTarget(ctx, tx, projectName, name, inst.ExpandedConfig(), *targetMemberInfo)
+				if err != nil {
+					return err
+				}
 			}
 
 			return nil
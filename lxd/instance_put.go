@@ -158,6 +158,11 @@ func instancePut(d *Daemon, r *http.Request) response.Response {
 				apiProfiles = append(apiProfiles, *apiProfile)
 			}
 
+			err = placementGroupCheckExists(ctx, tx, projectName, instancetype.ExpandInstanceConfig(nil, configRaw.Config, apiProfiles))
+			if err != nil {
+				return err
+			}
+
 			return limits.AllowInstanceUpdate(ctx, s.GlobalConfig, tx, projectName, name, configRaw, inst.LocalConfig())
 		})
 		if err != nil {
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/db/warningtype"
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/instance/operationlock"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/task"
+	"github.com/canonical/lxd/lxd/warnings"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+func instanceScheduleTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := d.State()
+
+		instances, err := instance.LoadNodeAll(s, instancetype.Any)
+		if err != nil {
+			logger.Error("Failed loading instances for scheduled start and stop", logger.Ctx{"err": err})
+			return
+		}
+
+		wg := sync.WaitGroup{}
+
+		for _, inst := range instances {
+			start, stop := instanceScheduleActions(inst.ExpandedConfig(), int64(inst.ID()))
+			if !start && !stop {
+				continue
+			}
+
+			wg.Add(1)
+			go func(inst instance.Instance) {
+				defer wg.Done()
+
+				l := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
+
+				var err error
+				if start && stop {
+					err = fmt.Errorf("Skipped scheduled start and stop as both are scheduled at the same time")
+				} else if start {
+					err = instanceScheduleStart(ctx, s, inst)
+				} else {
+					err = instanceScheduleStop(inst)
+				}
+
+				if err != nil {
+					l.Warn("Failed running instance schedule", logger.Ctx{"err": err})
+
+					warnErr := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+						return tx.UpsertWarningLocalNode(ctx, inst.Project().Name, entity.TypeInstance, inst.ID(), warningtype.InstanceScheduleFailure, err.Error())
+					})
+					if warnErr != nil {
+						l.Warn("Failed to create instance schedule failure warning", logger.Ctx{"err": warnErr})
+					}
+
+					return
+				}
+
+				// Resolve any previous warning.
+				warnErr := warnings.ResolveWarningsByLocalNodeAndProjectAndTypeAndEntity(s.DB.Cluster, inst.Project().Name, warningtype.InstanceScheduleFailure, entity.TypeInstance, inst.ID())
+				if warnErr != nil {
+					l.Warn("Failed to resolve instance schedule failure warning", logger.Ctx{"err": warnErr})
+				}
+			}(inst)
+		}
+
+		wg.Wait()
+	}
+
+	first := true
+	schedule := func() (time.Duration, error) {
+		interval := time.Minute
+
+		if first {
+			first = false
+			return interval, task.ErrSkip
+		}
+
+		return interval, nil
+	}
+
+	return f, schedule
+}
+
+// instanceScheduleActions returns whether the schedule.start and schedule.stop settings of an instance are due now.
+func instanceScheduleActions(config map[string]string, instanceID int64) (bool, bool) {
+	start := config["schedule.start"] != "" && snapshotIsScheduledNow(config["schedule.start"], instanceID)
+	stop := config["schedule.stop"] != "" && snapshotIsScheduledNow(config["schedule.stop"], instanceID)
+
+	return start, stop
+}
+
+// instanceScheduleStart starts an instance on behalf of its schedule.start setting.
+// Returns an error if the start was skipped or failed.
+func instanceScheduleStart(ctx context.Context, s *state.State, inst instance.Instance) error {
+	if inst.IsRunning() {
+		return nil
+	}
+
+	if s.DB.Cluster.LocalNodeIsEvacuated() {
+		return fmt.Errorf("Skipped scheduled start as the cluster member is evacuated")
+	}
+
+	if shared.IsTrue(inst.ExpandedConfig()["security.protection.start"]) {
+		return fmt.Errorf("Skipped scheduled start as the instance is protected from being started")
+	}
+
+	if operationlock.Get(inst.Project().Name, inst.Name()) != nil {
+		return fmt.Errorf("Skipped scheduled start as another operation is running on the instance")
+	}
+
+	err := instanceDependenciesWait(ctx, s, inst)
+	if err != nil {
+		return fmt.Errorf("Skipped scheduled start: %w", err)
+	}
+
+	logger.Info("Starting instance on schedule", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
+
+	err = inst.Start(false)
+	if err != nil {
+		return fmt.Errorf("Failed scheduled start: %w", err)
+	}
+
+	return nil
+}
+
+// instanceScheduleStop cleanly shuts down an instance on behalf of its schedule.stop setting, forcefully stopping
+// it if it doesn't shut down within boot.host_shutdown_timeout. Returns an error if the stop was skipped or failed.
+func instanceScheduleStop(inst instance.Instance) error {
+	if !inst.IsRunning() {
+		return nil
+	}
+
+	if operationlock.Get(inst.Project().Name, inst.Name()) != nil {
+		return fmt.Errorf("Skipped scheduled stop as another operation is running on the instance")
+	}
+
+	l := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
+	l.Info("Stopping instance on schedule")
+
+	timeoutSeconds := 30
+	value, ok := inst.ExpandedConfig()["boot.host_shutdown_timeout"]
+	if ok {
+		timeoutSeconds, _ = strconv.Atoi(value)
+	}
+
+	err := inst.Shutdown(time.Second * time.Duration(timeoutSeconds))
+	if err != nil {
+		l.Warn("Failed shutting down instance, forcefully stopping", logger.Ctx{"err": err})
+
+		err = inst.Stop(false)
+		if err != nil {
+			return fmt.Errorf("Failed scheduled stop: %w", err)
+		}
+	}
+
+	return nil
+}
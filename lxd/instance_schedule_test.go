@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_instanceScheduleActions(t *testing.T) {
+	// A schedule for a minute other than the current one.
+	otherMinute := fmt.Sprintf("%d * * * *", (time.Now().Minute()+30)%60)
+
+	tests := []struct {
+		name      string
+		config    map[string]string
+		wantStart bool
+		wantStop  bool
+	}{
+		{
+			name:   "No schedule",
+			config: map[string]string{},
+		},
+		{
+			name:      "Start now",
+			config:    map[string]string{"schedule.start": "* * * * *", "schedule.stop": otherMinute},
+			wantStart: true,
+		},
+		{
+			name:     "Stop now",
+			config:   map[string]string{"schedule.start": otherMinute, "schedule.stop": "* * * * *"},
+			wantStop: true,
+		},
+		{
+			name:      "Start and stop now",
+			config:    map[string]string{"schedule.start": "* * * * *", "schedule.stop": "* * * * *"},
+			wantStart: true,
+			wantStop:  true,
+		},
+		{
+			name:      "Aliases",
+			config:    map[string]string{"schedule.start": "@daily, * * * * *", "schedule.stop": "@never"},
+			wantStart: true,
+		},
+		{
+			name:   "Invalid schedule",
+			config: map[string]string{"schedule.start": "not a schedule", "schedule.stop": "* * *"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, stop := instanceScheduleActions(tt.config, 1)
+			assert.Equal(t, tt.wantStart, start)
+			assert.Equal(t, tt.wantStop, stop)
+		})
+	}
+}
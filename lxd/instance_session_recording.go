@@ -0,0 +1,740 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gorilla/mux"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/lifecycle"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/storage"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/revert"
+	"github.com/canonical/lxd/shared/version"
+)
+
+var instanceSessionRecordingCmd = APIEndpoint{
+	Name:        "instanceSessionRecording",
+	Path:        "instances/{name}/logs/session-recordings/{file}",
+	MetricsType: entity.TypeInstance,
+	Aliases: []APIEndpointAlias{
+		{Name: "containerSessionRecording", Path: "containers/{name}/logs/session-recordings/{file}"},
+		{Name: "vmSessionRecording", Path: "virtual-machines/{name}/logs/session-recordings/{file}"},
+	},
+
+	// Recordings are an audit trail of the instance's users, so deleting them requires editing the project.
+	Delete: APIEndpointAction{Handler: instanceSessionRecordingDelete, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanEdit)},
+	Get:    APIEndpointAction{Handler: instanceSessionRecordingGet, AccessHandler: allowPermission(entity.TypeInstance, auth.EntitlementCanEdit, "name")},
+}
+
+var instanceSessionRecordingsCmd = APIEndpoint{
+	Name:        "instanceSessionRecordings",
+	Path:        "instances/{name}/logs/session-recordings",
+	MetricsType: entity.TypeInstance,
+	Aliases: []APIEndpointAlias{
+		{Name: "containerSessionRecordings", Path: "containers/{name}/logs/session-recordings"},
+		{Name: "vmSessionRecordings", Path: "virtual-machines/{name}/logs/session-recordings"},
+	},
+
+	Get: APIEndpointAction{Handler: instanceSessionRecordingsGet, AccessHandler: allowPermission(entity.TypeInstance, auth.EntitlementCanEdit, "name")},
+}
+
+// sessionRecordingEnabled returns whether the exec and console sessions of the instance must be recorded.
+// Recording is enabled by either the instance or its project, so that an instance can't opt out of the project policy.
+func sessionRecordingEnabled(inst instance.Instance) bool {
+	return shared.IsTrue(inst.ExpandedConfig()["security.exec.record"]) || shared.IsTrue(inst.Project().Config["security.exec.record"])
+}
+
+// sessionRecordingHeader is the header line of a recording in asciicast v2 format.
+// The fields prefixed with lxd_ are ignored by asciicast players and record who opened the session.
+type sessionRecordingHeader struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Command   string            `json:"command,omitempty"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+
+	Type     string `json:"lxd_type"`
+	Username string `json:"lxd_username"`
+	Protocol string `json:"lxd_protocol"`
+	Address  string `json:"lxd_address"`
+}
+
+// sessionRecorder records the input and output of a session in asciicast v2 format.
+type sessionRecorder struct {
+	mu     sync.Mutex
+	file   *os.File
+	start  time.Time
+	output *sessionRecorderStream
+}
+
+// sessionRecorderStream records one stream of a session as events of the same code.
+type sessionRecorderStream struct {
+	recorder *sessionRecorder
+	code     string
+	pending  []byte
+}
+
+// newSessionRecorder creates the recording of a session of the given type ("exec" or "console") in the session
+// recording path of the instance.
+func newSessionRecorder(inst instance.Instance, sessionType string, id string, requestor *api.EventLifecycleRequestor, command []string, env map[string]string, width int, height int) (*sessionRecorder, error) {
+	recordingDir := inst.SessionRecordingPath()
+	err := os.Mkdir(recordingDir, 0600)
+	if err != nil && !errors.Is(err, fs.ErrExist) {
+		return nil, fmt.Errorf("Failed creating session recording directory: %w", err)
+	}
+
+	file, err := os.OpenFile(filepath.Join(recordingDir, fmt.Sprintf("%s_%s.cast", sessionType, id)), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("Failed creating session recording: %w", err)
+	}
+
+	if width <= 0 || height <= 0 {
+		width = 80
+		height = 24
+	}
+
+	now := time.Now()
+	header := sessionRecordingHeader{
+		Version:   2,
+		Width:     width,
+		Height:    height,
+		Timestamp: now.Unix(),
+		Command:   strings.Join(command, " "),
+		Title:     fmt.Sprintf("%s session of instance %q", sessionType, inst.Name()),
+		Type:      sessionType,
+	}
+
+	if env["TERM"] != "" {
+		header.Env = map[string]string{"TERM": env["TERM"]}
+	}
+
+	if requestor != nil {
+		header.Username = requestor.Username
+		header.Protocol = requestor.Protocol
+		header.Address = requestor.Address
+	}
+
+	buf, err := json.Marshal(header)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	_, err = file.Write(append(buf, '\n'))
+	if err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("Failed writing session recording: %w", err)
+	}
+
+	recorder := &sessionRecorder{file: file, start: now}
+	recorder.output = recorder.Stream("o")
+
+	return recorder, nil
+}
+
+// event appends an event to the recording. Must be called with mu held.
+func (r *sessionRecorder) event(code string, data string) error {
+	elapsed := math.Round(time.Since(r.start).Seconds()*1e6) / 1e6
+
+	buf, err := json.Marshal([]any{elapsed, code, data})
+	if err != nil {
+		return err
+	}
+
+	_, err = r.file.Write(append(buf, '\n'))
+	return err
+}
+
+// Stream returns a writer recording the data written to it as events of the given code, "o" for output and
+// "i" for input. Each stream holds back its own incomplete UTF-8 sequences, so that streams written to
+// concurrently, such as stdout and stderr, don't split each other's characters.
+func (r *sessionRecorder) Stream(code string) *sessionRecorderStream {
+	return &sessionRecorderStream{recorder: r, code: code}
+}
+
+// Write records output of the session.
+func (r *sessionRecorder) Write(p []byte) (int, error) {
+	return r.output.Write(p)
+}
+
+// Write records the data as events of the stream.
+// Incomplete UTF-8 sequences are held back until the rest of the sequence is written.
+func (s *sessionRecorderStream) Write(p []byte) (int, error) {
+	s.recorder.mu.Lock()
+	defer s.recorder.mu.Unlock()
+
+	data := append(s.pending, p...)
+	end := len(data)
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				end = i
+			}
+
+			break
+		}
+	}
+
+	s.pending = append([]byte(nil), data[end:]...)
+	if end == 0 {
+		return len(p), nil
+	}
+
+	err := s.recorder.event(s.code, string(data[:end]))
+	if err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// Resize records a change of the terminal size.
+func (r *sessionRecorder) Resize(width int, height int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_ = r.event("r", fmt.Sprintf("%dx%d", width, height))
+}
+
+// Marker records a marker, such as a client attaching to a persistent exec session.
+func (r *sessionRecorder) Marker(label string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_ = r.event("m", label)
+}
+
+// Close closes the recording.
+func (r *sessionRecorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.file.Close()
+}
+
+// sessionRecordedReadWriteCloser records everything read from the wrapped ReadWriteCloser as output and
+// everything written to it as input.
+type sessionRecordedReadWriteCloser struct {
+	io.ReadWriteCloser
+
+	recorder *sessionRecorder
+	input    *sessionRecorderStream
+}
+
+// newSessionRecordedReadWriteCloser returns a ReadWriteCloser recording the data going through rwc.
+func newSessionRecordedReadWriteCloser(rwc io.ReadWriteCloser, recorder *sessionRecorder) *sessionRecordedReadWriteCloser {
+	return &sessionRecordedReadWriteCloser{ReadWriteCloser: rwc, recorder: recorder, input: recorder.Stream("i")}
+}
+
+// Read reads from the wrapped ReadWriteCloser and records the data.
+func (r *sessionRecordedReadWriteCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadWriteCloser.Read(p)
+	if n > 0 {
+		_, _ = r.recorder.Write(p[:n])
+	}
+
+	return n, err
+}
+
+// Write writes to the wrapped ReadWriteCloser and records the data.
+func (r *sessionRecordedReadWriteCloser) Write(p []byte) (int, error) {
+	n, err := r.ReadWriteCloser.Write(p)
+	if n > 0 {
+		_, _ = r.input.Write(p[:n])
+	}
+
+	return n, err
+}
+
+// sessionRecordingPipe returns the write end of a pipe whose data is recorded as output of the session and
+// copied to dst, if not nil. This is used to record commands whose output is written to files rather than to
+// websockets. The returned function must be called once the command has exited to wait for the remaining data
+// to be recorded.
+func sessionRecordingPipe(recorder *sessionRecorder, dst *os.File) (*os.File, func(), error) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var out io.Writer = recorder.Stream("o")
+	if dst != nil {
+		out = io.MultiWriter(dst, out)
+	}
+
+	// Stop reading once the command has exited, even if background processes keep the pipe open.
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		_, _ = io.Copy(out, shared.NewExecWrapper(ctx, reader))
+		_ = reader.Close()
+	}()
+
+	finish := func() {
+		_ = writer.Close()
+		cancel()
+		<-done
+	}
+
+	return writer, finish, nil
+}
+
+// validSessionRecordingFileName returns whether the name is the name of a session recording file.
+func validSessionRecordingFileName(fName string) bool {
+	if strings.Contains(fName, "/") || strings.Contains(fName, "\\") || strings.Contains(fName, "..") {
+		return false
+	}
+
+	return strings.HasSuffix(fName, ".cast") && (strings.HasPrefix(fName, "exec_") || strings.HasPrefix(fName, "console_"))
+}
+
+// sessionRecordingLoad returns the API representation of a session recording file.
+func sessionRecordingLoad(path string) (*api.InstanceSessionRecording, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = file.Close() }()
+
+	fi, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	line, err := bufio.NewReader(file).ReadBytes('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	header := sessionRecordingHeader{}
+	err = json.Unmarshal(line, &header)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid session recording header: %w", err)
+	}
+
+	return &api.InstanceSessionRecording{
+		Name:      filepath.Base(path),
+		Type:      header.Type,
+		Command:   header.Command,
+		Username:  header.Username,
+		Protocol:  header.Protocol,
+		Address:   header.Address,
+		CreatedAt: time.Unix(header.Timestamp, 0),
+		Size:      fi.Size(),
+	}, nil
+}
+
+// swagger:operation GET /1.0/instances/{name}/logs/session-recordings instances instance_session-recordings_get
+//
+//	Get the session recordings
+//
+//	Returns a list of recordings of exec and console sessions (URLs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of endpoints
+//	          items:
+//	            type: string
+//	          example: |-
+//	            [
+//	              "/1.0/instances/foo/logs/session-recordings/exec_b8d84888-1dc2-44fd-b386-7f679e171ba5.cast",
+//	              "/1.0/instances/foo/logs/session-recordings/console_d0a89537-0617-4ed6-a79b-c2e88a970965.cast"
+//	            ]
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+
+// swagger:operation GET /1.0/instances/{name}/logs/session-recordings?recursion=1 instances instance_session-recordings_get_recursion1
+//
+//	Get the session recordings
+//
+//	Returns a list of recordings of exec and console sessions (structs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of session recordings
+//	          items:
+//	            $ref: "#/definitions/InstanceSessionRecording"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceSessionRecordingsGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	instanceType, err := urlInstanceTypeDetect(r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	projectName := request.ProjectParam(r)
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if shared.IsSnapshot(name) {
+		return response.BadRequest(fmt.Errorf("Invalid instance name"))
+	}
+
+	// Handle requests targeted to an instance on a different member.
+	resp, err := forwardedResponseIfInstanceIsRemote(s, r, projectName, name, instanceType)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if resp != nil {
+		return resp
+	}
+
+	// Ensure instance exists.
+	inst, err := instance.LoadByProjectAndName(s, projectName, name)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Mount the instance's root volume.
+	pool, err := storage.LoadByInstance(s, inst)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	_, err = pool.MountInstance(inst, nil)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	defer func() { _ = pool.UnmountInstance(inst, nil) }()
+
+	// Read the session recording files.
+	dents, err := os.ReadDir(inst.SessionRecordingPath())
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return response.SmartError(err)
+	}
+
+	recursion := util.IsRecursionRequest(r)
+
+	urls := []string{}
+	recordings := []api.InstanceSessionRecording{}
+	for _, f := range dents {
+		if !validSessionRecordingFileName(f.Name()) {
+			continue
+		}
+
+		if !recursion {
+			urls = append(urls, api.NewURL().Path(version.APIVersion, "instances", name, "logs", "session-recordings", f.Name()).String())
+			continue
+		}
+
+		recording, err := sessionRecordingLoad(filepath.Join(inst.SessionRecordingPath(), f.Name()))
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		recordings = append(recordings, *recording)
+	}
+
+	if recursion {
+		return response.SyncResponse(true, recordings)
+	}
+
+	return response.SyncResponse(true, urls)
+}
+
+// swagger:operation GET /1.0/instances/{name}/logs/session-recordings/{filename} instances instance_session-recording_get
+//
+//	Get the session recording
+//
+//	Downloads the recording of an exec or console session in asciicast v2 format.
+//
+//	---
+//	produces:
+//	  - application/json
+//	  - application/octet-stream
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	     description: Raw file
+//	     content:
+//	       application/octet-stream:
+//	         schema:
+//	           type: string
+//	           example: some-text
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceSessionRecordingGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	instanceType, err := urlInstanceTypeDetect(r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	projectName := request.ProjectParam(r)
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if shared.IsSnapshot(name) {
+		return response.BadRequest(fmt.Errorf("Invalid instance name"))
+	}
+
+	// Handle requests targeted to an instance on a different member.
+	resp, err := forwardedResponseIfInstanceIsRemote(s, r, projectName, name, instanceType)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if resp != nil {
+		return resp
+	}
+
+	file, err := url.PathUnescape(mux.Vars(r)["file"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if !validSessionRecordingFileName(file) {
+		return response.BadRequest(fmt.Errorf("Session recording file name %q not valid", file))
+	}
+
+	// Ensure instance exists.
+	inst, err := instance.LoadByProjectAndName(s, projectName, name)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Mount the instance's root volume.
+	pool, err := storage.LoadByInstance(s, inst)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	_, err = pool.MountInstance(inst, nil)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	revert.Add(func() { _ = pool.UnmountInstance(inst, nil) })
+
+	path := filepath.Join(inst.SessionRecordingPath(), file)
+	if !shared.PathExists(path) {
+		return response.NotFound(fmt.Errorf("Session recording %q not found", file))
+	}
+
+	cleanup := revert.Clone()
+	revert.Success()
+
+	ent := response.FileResponseEntry{
+		Path:     path,
+		Filename: file,
+		Cleanup:  cleanup.Fail,
+	}
+
+	s.Events.SendLifecycle(projectName, lifecycle.InstanceLogRetrieved.Event(file, inst, request.CreateRequestor(r), nil))
+
+	return response.FileResponse([]response.FileResponseEntry{ent}, nil)
+}
+
+// swagger:operation DELETE /1.0/instances/{name}/logs/session-recordings/{filename} instances instance_session-recording_delete
+//
+//	Delete the session recording
+//
+//	Removes the recording of an exec or console session.
+//	Requires the can_edit entitlement on the project.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceSessionRecordingDelete(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	instanceType, err := urlInstanceTypeDetect(r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	projectName := request.ProjectParam(r)
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if shared.IsSnapshot(name) {
+		return response.BadRequest(fmt.Errorf("Invalid instance name"))
+	}
+
+	// Handle requests targeted to an instance on a different member.
+	resp, err := forwardedResponseIfInstanceIsRemote(s, r, projectName, name, instanceType)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if resp != nil {
+		return resp
+	}
+
+	file, err := url.PathUnescape(mux.Vars(r)["file"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if !validSessionRecordingFileName(file) {
+		return response.BadRequest(fmt.Errorf("Session recording file name %q not valid", file))
+	}
+
+	// Ensure instance exists.
+	inst, err := instance.LoadByProjectAndName(s, projectName, name)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Mount the instance's root volume.
+	pool, err := storage.LoadByInstance(s, inst)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	_, err = pool.MountInstance(inst, nil)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	defer func() { _ = pool.UnmountInstance(inst, nil) }()
+
+	err = os.Remove(filepath.Join(inst.SessionRecordingPath(), file))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return response.NotFound(fmt.Errorf("Session recording %q not found", file))
+		}
+
+		return response.SmartError(err)
+	}
+
+	s.Events.SendLifecycle(projectName, lifecycle.InstanceLogDeleted.Event(file, inst, request.CreateRequestor(r), nil))
+
+	return response.EmptySyncResponse
+}
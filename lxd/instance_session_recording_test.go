@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestSessionRecorder returns a recorder writing to a file in a temporary directory, and the path of the file.
+func newTestSessionRecorder(t *testing.T) (*sessionRecorder, string) {
+	path := filepath.Join(t.TempDir(), "exec_test.cast")
+
+	file, err := os.Create(path)
+	require.NoError(t, err)
+
+	recorder := &sessionRecorder{file: file, start: time.Now()}
+	recorder.output = recorder.Stream("o")
+
+	return recorder, path
+}
+
+// sessionRecordingEvents returns the codes and data of the events of a recording.
+func sessionRecordingEvents(t *testing.T, path string) [][2]string {
+	file, err := os.Open(path)
+	require.NoError(t, err)
+
+	defer func() { _ = file.Close() }()
+
+	events := [][2]string{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		event := []any{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		require.Len(t, event, 3)
+
+		events = append(events, [2]string{event[1].(string), event[2].(string)})
+	}
+
+	require.NoError(t, scanner.Err())
+
+	return events
+}
+
+func Test_sessionRecorderWrite(t *testing.T) {
+	euro := []byte("€") // 3 bytes.
+
+	tests := []struct {
+		name   string
+		writes [][]byte
+		want   [][2]string
+	}{
+		{
+			name:   "ASCII",
+			writes: [][]byte{[]byte("foo"), []byte("bar")},
+			want:   [][2]string{{"o", "foo"}, {"o", "bar"}},
+		},
+		{
+			name:   "Complete sequence",
+			writes: [][]byte{append([]byte("a"), euro...)},
+			want:   [][2]string{{"o", "a€"}},
+		},
+		{
+			name:   "Sequence split across writes",
+			writes: [][]byte{append([]byte("a"), euro[:1]...), euro[1:2], append(euro[2:], 'b')},
+			want:   [][2]string{{"o", "a"}, {"o", "€b"}},
+		},
+		{
+			name:   "Write of continuation bytes only",
+			writes: [][]byte{euro[:2], euro[2:]},
+			want:   [][2]string{{"o", "€"}},
+		},
+		{
+			name:   "Invalid byte isn't held back",
+			writes: [][]byte{{'a', 0xff}, []byte("b")},
+			want:   [][2]string{{"o", "a�"}, {"o", "b"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder, path := newTestSessionRecorder(t)
+
+			for _, p := range tt.writes {
+				n, err := recorder.Write(p)
+				require.NoError(t, err)
+				assert.Equal(t, len(p), n)
+			}
+
+			require.NoError(t, recorder.Close())
+			assert.Equal(t, tt.want, sessionRecordingEvents(t, path))
+		})
+	}
+}
+
+func Test_sessionRecorderStreams(t *testing.T) {
+	recorder, path := newTestSessionRecorder(t)
+	euro := []byte("€")
+
+	input := recorder.Stream("i")
+	stderr := recorder.Stream("o")
+
+	// Incomplete sequences of one stream aren't completed by the data of another stream.
+	_, err := recorder.Write(euro[:1])
+	require.NoError(t, err)
+
+	_, err = input.Write([]byte("ls\r"))
+	require.NoError(t, err)
+
+	_, err = stderr.Write(euro[:2])
+	require.NoError(t, err)
+
+	_, err = recorder.Write(euro[1:])
+	require.NoError(t, err)
+
+	_, err = stderr.Write(euro[2:])
+	require.NoError(t, err)
+
+	require.NoError(t, recorder.Close())
+	assert.Equal(t, [][2]string{{"i", "ls\r"}, {"o", "€"}, {"o", "€"}}, sessionRecordingEvents(t, path))
+}
@@ -175,6 +175,32 @@ var instanceExecCmd = APIEndpoint{
 	Post: APIEndpointAction{Handler: instanceExecPost, AccessHandler: allowPermission(entity.TypeInstance, auth.EntitlementCanExec, "name")},
 }
 
+var instanceExecSessionsCmd = APIEndpoint{
+	Name:        "instanceExecSessions",
+	Path:        "instances/{name}/exec-sessions",
+	MetricsType: entity.TypeInstance,
+	Aliases: []APIEndpointAlias{
+		{Name: "containerExecSessions", Path: "containers/{name}/exec-sessions"},
+		{Name: "vmExecSessions", Path: "virtual-machines/{name}/exec-sessions"},
+	},
+
+	Get: APIEndpointAction{Handler: instanceExecSessionsGet, AccessHandler: allowPermission(entity.TypeInstance, auth.EntitlementCanExec, "name")},
+}
+
+var instanceExecSessionCmd = APIEndpoint{
+	Name:        "instanceExecSession",
+	Path:        "instances/{name}/exec-sessions/{id}",
+	MetricsType: entity.TypeInstance,
+	Aliases: []APIEndpointAlias{
+		{Name: "containerExecSession", Path: "containers/{name}/exec-sessions/{id}"},
+		{Name: "vmExecSession", Path: "virtual-machines/{name}/exec-sessions/{id}"},
+	},
+
+	Delete: APIEndpointAction{Handler: instanceExecSessionDelete, AccessHandler: allowPermission(entity.TypeInstance, auth.EntitlementCanExec, "name")},
+	Get:    APIEndpointAction{Handler: instanceExecSessionGet, AccessHandler: allowPermission(entity.TypeInstance, auth.EntitlementCanExec, "name")},
+	Post:   APIEndpointAction{Handler: instanceExecSessionPost, AccessHandler: allowPermission(entity.TypeInstance, auth.EntitlementCanExec, "name")},
+}
+
 var instanceMetadataCmd = APIEndpoint{
 	Name:        "instanceMetadata",
 	Path:        "instances/{name}/metadata",
@@ -290,8 +316,9 @@ func instancesStart(s *state.State, instances []instance.Instance) {
 	instancesStartMu.Lock()
 	defer instancesStartMu.Unlock()
 
-	// Sort based on instance boot priority.
+	// Sort based on instance boot priority, then make sure that dependencies are started first.
 	sort.Sort(instanceAutostartList(instances))
+	instances = instancesSortByDependencies(instances)
 
 	// Let's make up to 3 attempts to start instances.
 	maxAttempts := 3
@@ -313,6 +340,21 @@ func instancesStart(s *state.State, instances []instance.Instance) {
 
 		instLogger := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
 
+		// Wait for the instances it depends on to be ready.
+		err := instanceDependenciesWait(s.ShutdownCtx, s, inst)
+		if err != nil {
+			warnErr := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+				return tx.UpsertWarningLocalNode(ctx, inst.Project().Name, entity.TypeInstance, inst.ID(), warningtype.InstanceAutostartFailure, fmt.Sprint(err))
+			})
+			if warnErr != nil {
+				instLogger.Warn("Failed to create instance autostart failure warning", logger.Ctx{"err": warnErr})
+			}
+
+			instLogger.Error("Failed to auto start instance", logger.Ctx{"err": err})
+
+			continue
+		}
+
 		// Try to start the instance.
 		var attempt = 0
 		for {
@@ -1224,6 +1224,15 @@ func instancesPost(d *Daemon, r *http.Request) response.Response {
 			logger.Debug("No name provided for new instance, using auto-generated name", logger.Ctx{"project": targetProjectName, "instance": req.Name})
 		}
 
+		expandedConfig := instancetype.ExpandInstanceConfig(nil, req.Config, profiles)
+
+		if !clusterNotification {
+			err = placementGroupCheckExists(ctx, tx, targetProjectName, expandedConfig)
+			if err != nil {
+				return err
+			}
+		}
+
 		if s.ServerClustered && !clusterNotification && targetMemberInfo == nil {
 			architectures, err := instance.SuitableArchitectures(ctx, s, tx, targetProjectName, sourceInst, sourceImageRef, req)
 			if err != nil {
@@ -1256,6 +1265,18 @@ func instancesPost(d *Daemon, r *http.Request) response.Response {
 			if err != nil {
 				return err
 			}
+
+			// Only keep the members that satisfy the placement group of the instance.
+			candidateMembers, err = placementGroupCandidates(ctx, tx, targetProjectName, req.Name, expandedConfig, candidateMembers)
+			if err != nil {
+				return err
+			}
+		} else if s.ServerClustered && !clusterNotification {
+			// Check that the target member satisfies the placement group of the instance.
+			err = placementGroupCheckTarget(ctx, tx, targetProjectName, req.Name, expandedConfig, *targetMemberInfo)
+			if err != nil {
+				return err
+			}
 		}
 
 		if !clusterNotification {
@@ -0,0 +1,78 @@
+package ip
+
+import (
+	"net"
+	"strings"
+
+	"github.com/canonical/lxd/shared"
+)
+
+// FDB represents arguments for bridge forwarding database entry manipulation.
+type FDB struct {
+	DevName string
+	MAC     net.HardwareAddr
+	Dst     net.IP
+}
+
+// Show lists forwarding database entries of the device that have a remote destination.
+func (f *FDB) Show() ([]FDB, error) {
+	out, err := shared.RunCommand("bridge", "fdb", "show", "dev", f.DevName)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []FDB{}
+
+	for _, line := range shared.SplitNTrimSpace(out, "\n", -1, true) {
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+
+		mac, err := net.ParseMAC(fields[0])
+		if err != nil {
+			continue
+		}
+
+		for i, field := range fields[:len(fields)-1] {
+			if field != "dst" {
+				continue
+			}
+
+			dst := net.ParseIP(fields[i+1])
+			if dst == nil {
+				break
+			}
+
+			entries = append(entries, FDB{
+				DevName: f.DevName,
+				MAC:     mac,
+				Dst:     dst,
+			})
+
+			break
+		}
+	}
+
+	return entries, nil
+}
+
+// Append adds a forwarding database entry, keeping any existing entries for the same MAC address.
+func (f *FDB) Append() error {
+	_, err := shared.RunCommand("bridge", "fdb", "append", f.MAC.String(), "dev", f.DevName, "dst", f.Dst.String())
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Delete deletes a forwarding database entry.
+func (f *FDB) Delete() error {
+	_, err := shared.RunCommand("bridge", "fdb", "delete", f.MAC.String(), "dev", f.DevName, "dst", f.Dst.String())
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -39,6 +39,14 @@ func (a *ActionPolice) AddAction() []string {
 	return result
 }
 
+// ActionDrop represents an action of 'drop' type.
+type ActionDrop struct{}
+
+// AddAction generates a part of command specific for 'drop' action.
+func (a *ActionDrop) AddAction() []string {
+	return []string{"action", "drop"}
+}
+
 // Filter represents filter object.
 type Filter struct {
 	Dev      string
@@ -81,3 +89,60 @@ func (u32 *U32Filter) Add() error {
 
 	return nil
 }
+
+// FlowerFilter represents a flow based traffic control filter.
+type FlowerFilter struct {
+	Filter
+	SrcMAC      string
+	SrcIP       string
+	ARPSenderIP string
+	IPProto     string
+	ICMPType    string
+	DstPort     string
+	Actions     []Action
+}
+
+// Add adds flow based traffic control filter to a node.
+func (flower *FlowerFilter) Add() error {
+	cmd := []string{"filter", "add", "dev", flower.Dev}
+	if flower.Parent != "" {
+		cmd = append(cmd, "parent", flower.Parent)
+	}
+
+	cmd = append(cmd, "protocol", flower.Protocol, "flower")
+	if flower.SrcMAC != "" {
+		cmd = append(cmd, "src_mac", flower.SrcMAC)
+	}
+
+	if flower.SrcIP != "" {
+		cmd = append(cmd, "src_ip", flower.SrcIP)
+	}
+
+	if flower.ARPSenderIP != "" {
+		cmd = append(cmd, "arp_sip", flower.ARPSenderIP)
+	}
+
+	if flower.IPProto != "" {
+		cmd = append(cmd, "ip_proto", flower.IPProto)
+	}
+
+	if flower.ICMPType != "" {
+		cmd = append(cmd, "type", flower.ICMPType)
+	}
+
+	if flower.DstPort != "" {
+		cmd = append(cmd, "dst_port", flower.DstPort)
+	}
+
+	for _, action := range flower.Actions {
+		actionCmd := action.AddAction()
+		cmd = append(cmd, actionCmd...)
+	}
+
+	_, err := shared.RunCommand("tc", cmd...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
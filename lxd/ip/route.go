@@ -15,6 +15,7 @@ type Route struct {
 	Proto   string
 	Family  string
 	Via     string
+	Metric  string
 }
 
 // Add adds new route.
@@ -28,7 +29,11 @@ func (r *Route) Add() error {
 		cmd = append(cmd, "via", r.Via)
 	}
 
-	cmd = append(cmd, r.Route, "dev", r.DevName)
+	cmd = append(cmd, r.Route)
+	if r.DevName != "" {
+		cmd = append(cmd, "dev", r.DevName)
+	}
+
 	if r.Src != "" {
 		cmd = append(cmd, "src", r.Src)
 	}
@@ -37,6 +42,10 @@ func (r *Route) Add() error {
 		cmd = append(cmd, "proto", r.Proto)
 	}
 
+	if r.Metric != "" {
+		cmd = append(cmd, "metric", r.Metric)
+	}
+
 	_, err := shared.RunCommand("ip", cmd...)
 	if err != nil {
 		return err
@@ -47,7 +56,29 @@ func (r *Route) Add() error {
 
 // Delete deletes routing table.
 func (r *Route) Delete() error {
-	_, err := shared.RunCommand("ip", r.Family, "route", "delete", "table", r.Table, r.Route, "dev", r.DevName)
+	cmd := []string{r.Family, "route", "delete"}
+	if r.Table != "" {
+		cmd = append(cmd, "table", r.Table)
+	}
+
+	cmd = append(cmd, r.Route)
+	if r.Via != "" {
+		cmd = append(cmd, "via", r.Via)
+	}
+
+	if r.DevName != "" {
+		cmd = append(cmd, "dev", r.DevName)
+	}
+
+	if r.Proto != "" {
+		cmd = append(cmd, "proto", r.Proto)
+	}
+
+	if r.Metric != "" {
+		cmd = append(cmd, "metric", r.Metric)
+	}
+
+	_, err := shared.RunCommand("ip", cmd...)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,28 @@
+package lifecycle
+
+import (
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/version"
+)
+
+// InstanceGroupAction represents a lifecycle event action for instance groups.
+type InstanceGroupAction string
+
+// All supported lifecycle events for instance groups.
+const (
+	InstanceGroupCreated = InstanceGroupAction(api.EventLifecycleInstanceGroupCreated)
+	InstanceGroupDeleted = InstanceGroupAction(api.EventLifecycleInstanceGroupDeleted)
+	InstanceGroupUpdated = InstanceGroupAction(api.EventLifecycleInstanceGroupUpdated)
+)
+
+// Event creates the lifecycle event for an action on an instance group.
+func (a InstanceGroupAction) Event(projectName string, name string, requestor *api.EventLifecycleRequestor, ctx map[string]any) api.EventLifecycle {
+	u := api.NewURL().Path(version.APIVersion, "instance-groups", name).Project(projectName)
+
+	return api.EventLifecycle{
+		Action:    string(a),
+		Source:    u.String(),
+		Context:   ctx,
+		Requestor: requestor,
+	}
+}
@@ -20,6 +20,10 @@ const (
 	NetworkDeleted = NetworkAction(api.EventLifecycleNetworkDeleted)
 	NetworkUpdated = NetworkAction(api.EventLifecycleNetworkUpdated)
 	NetworkRenamed = NetworkAction(api.EventLifecycleNetworkRenamed)
+
+	NetworkLeaseAdded   = NetworkAction(api.EventLifecycleNetworkLeaseAdded)
+	NetworkLeaseRenewed = NetworkAction(api.EventLifecycleNetworkLeaseRenewed)
+	NetworkLeaseExpired = NetworkAction(api.EventLifecycleNetworkLeaseExpired)
 )
 
 // Event creates the lifecycle event for an action on a network device.
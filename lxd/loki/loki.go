@@ -400,6 +400,70 @@ func (c *Client) HandleEvent(event api.Event) {
 
 		message.WriteString(logEvent.Message)
 
+		entry.Line = message.String()
+	} else if event.Type == api.EventTypeNetworkFlow {
+		flowEvent := api.EventNetworkFlow{}
+
+		err := json.Unmarshal(event.Metadata, &flowEvent)
+		if err != nil {
+			return
+		}
+
+		entry.labels["name"] = flowEvent.Instance
+		entry.labels["project"] = flowEvent.Project
+
+		// Build map. These key-value pairs will either be added as labels, or be part of the
+		// log message itself.
+		context["network"] = flowEvent.Network
+		context["direction"] = flowEvent.Direction
+		context["protocol"] = flowEvent.Protocol
+		context["source"] = flowEvent.Source
+		context["destination"] = flowEvent.Destination
+
+		if flowEvent.SourcePort > 0 {
+			context["source-port"] = strconv.FormatUint(uint64(flowEvent.SourcePort), 10)
+		}
+
+		if flowEvent.DestinationPort > 0 {
+			context["destination-port"] = strconv.FormatUint(uint64(flowEvent.DestinationPort), 10)
+		}
+
+		if flowEvent.Action == "end" {
+			context["bytes-sent"] = strconv.FormatUint(flowEvent.BytesSent, 10)
+			context["bytes-received"] = strconv.FormatUint(flowEvent.BytesReceived, 10)
+			context["packets-sent"] = strconv.FormatUint(flowEvent.PacketsSent, 10)
+			context["packets-received"] = strconv.FormatUint(flowEvent.PacketsReceived, 10)
+		}
+
+		// Add key-value pairs as labels but don't override any labels.
+		for k, v := range context {
+			if shared.ValueInSlice(k, c.cfg.labels) {
+				_, ok := entry.labels[k]
+				if !ok {
+					// Label names may not contain any hyphens.
+					entry.labels[strings.ReplaceAll(k, "-", "_")] = v
+					delete(context, k)
+				}
+			}
+		}
+
+		keys := make([]string, 0, len(context))
+
+		for k := range context {
+			keys = append(keys, k)
+		}
+
+		sort.Strings(keys)
+
+		var message strings.Builder
+
+		// Add the remaining context as the message prefix. The keys are sorted alphabetically.
+		for _, k := range keys {
+			message.WriteString(k + `="` + context[k] + `" `)
+		}
+
+		message.WriteString(flowEvent.Action)
+
 		entry.Line = message.String()
 	}
 
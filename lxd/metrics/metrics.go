@@ -96,6 +96,11 @@ func (m *MetricSet) String() string {
 		GoHeapObjects,
 		Instances,
 		APIOngoingRequests,
+		BGPPeerPrefixesAccepted,
+		BGPPeerPrefixesAdvertised,
+		BGPPeerPrefixesReceived,
+		BGPPeerSessionEstablished,
+		BGPPeerUptimeSeconds,
 	}
 
 	for _, metricType := range metricTypes {
@@ -52,6 +52,11 @@ func OVNACLNetworkPortGroupName(networkACLID int64, networkID int64) openvswitch
 	return openvswitch.OVNPortGroup(fmt.Sprintf("%s%d_net%d", ovnACLPortGroupPrefix, networkACLID, networkID))
 }
 
+// ovnACLMeterPrefix returns the prefix of the names of the meters used by the rules applied to an ACL port group.
+func ovnACLMeterPrefix(portGroupName openvswitch.OVNPortGroup) string {
+	return fmt.Sprintf("%s-", portGroupName)
+}
+
 // OVNIntSwitchPortGroupName returns the port group name for a Network ID.
 func OVNIntSwitchPortGroupName(networkID int64) openvswitch.OVNPortGroup {
 	return openvswitch.OVNPortGroup(fmt.Sprintf("lxd_net%d", networkID))
@@ -341,6 +346,7 @@ func ovnApplyToPortGroup(l logger.Logger, client *openvswitch.OVN, aclInfo *api.
 	portGroupRules := make([]openvswitch.OVNACLRule, 0, len(aclInfo.Ingress)+len(aclInfo.Egress)+1)
 	networkRules := make([]openvswitch.OVNACLRule, 0)
 	networkPeersNeeded := make([]db.NetworkPeer, 0)
+	meters := make([]openvswitch.OVNMeter, 0)
 
 	// convertACLRules converts the ACL rules to OVN ACL rules.
 	convertACLRules := func(direction string, rules ...api.NetworkACLRule) error {
@@ -359,6 +365,18 @@ func ovnApplyToPortGroup(l logger.Logger, client *openvswitch.OVN, aclInfo *api.
 				ovnACLRule.LogName = fmt.Sprintf("%s-%s-%d", portGroupName, direction, ruleIndex)
 			}
 
+			meter, err := ovnRuleMeter(fmt.Sprintf("%s%s-%d", ovnACLMeterPrefix(portGroupName), direction, ruleIndex), &rule)
+			if err != nil {
+				return err
+			}
+
+			if meter != nil {
+				ovnACLRule.Meter = meter.Name
+				meters = append(meters, *meter)
+			}
+
+			ovnACLRule.CounterName = ruleCounterName(aclNameIDs[aclInfo.Name], direction, ruleIndex)
+
 			if networkSpecific {
 				networkRules = append(networkRules, ovnACLRule)
 			} else {
@@ -404,6 +422,12 @@ func ovnApplyToPortGroup(l logger.Logger, client *openvswitch.OVN, aclInfo *api.
 		}
 	}
 
+	// Replace the meters used by the rules of the ACL before the rules referencing them.
+	err = client.MeterApply(ovnACLMeterPrefix(portGroupName), meters...)
+	if err != nil {
+		return fmt.Errorf("Failed applying ACL %q meters for port group %q: %w", aclInfo.Name, portGroupName, err)
+	}
+
 	// Clear all existing ACL rules from port group then add the new rules to the port group.
 	err = client.PortGroupSetACLRules(portGroupName, nil, portGroupRules...)
 	if err != nil {
@@ -522,6 +546,52 @@ func ovnRuleCriteriaToOVNACLRule(direction string, rule *api.NetworkACLRule, por
 	return portGroupRule, networkSpecific, networkPeersNeeded, nil
 }
 
+// ovnRuleMeter returns the meter enforcing the rate and connection limits of a LXD ACL rule, or nil if the rule
+// has no limits. OVN meters work in packets per second, so a rate limit is converted to packets per second
+// (rounded up) with a burst of its packet count. OVN cannot count concurrent connections, so a connection limit caps
+// the number of new connections per second instead, as only the packets starting connections are evaluated.
+func ovnRuleMeter(meterName string, rule *api.NetworkACLRule) (*openvswitch.OVNMeter, error) {
+	if rule.RateLimit == "" && rule.ConnectionLimit == "" {
+		return nil, nil
+	}
+
+	meter := &openvswitch.OVNMeter{Name: meterName}
+
+	if rule.RateLimit != "" {
+		packetsStr, unit, _ := strings.Cut(rule.RateLimit, "/")
+		packets, err := strconv.ParseUint(packetsStr, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid rate limit %q: %w", rule.RateLimit, err)
+		}
+
+		unitSeconds := map[string]uint64{"second": 1, "minute": 60, "hour": 60 * 60, "day": 24 * 60 * 60}
+		seconds, found := unitSeconds[unit]
+		if !found {
+			return nil, fmt.Errorf("Invalid rate limit %q: Unknown unit %q", rule.RateLimit, unit)
+		}
+
+		meter.Rate = (packets + seconds - 1) / seconds
+		meter.Burst = packets
+	}
+
+	if rule.ConnectionLimit != "" {
+		connections, err := strconv.ParseUint(rule.ConnectionLimit, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid connection limit %q: %w", rule.ConnectionLimit, err)
+		}
+
+		if meter.Rate == 0 || connections < meter.Rate {
+			meter.Rate = connections
+		}
+
+		if meter.Burst == 0 || connections < meter.Burst {
+			meter.Burst = connections
+		}
+	}
+
+	return meter, nil
+}
+
 // ovnRulePortToOVNACLMatch converts protocol (tcp/udp), direction (src/dst) and port criteria list into an OVN
 // match statement.
 func ovnRulePortToOVNACLMatch(protocol string, direction string, portCriteria ...string) string {
@@ -977,6 +1047,14 @@ func OVNPortGroupDeleteIfUnused(s *state.State, l logger.Logger, client *openvsw
 		if err != nil {
 			return fmt.Errorf("Failed to delete unused OVN port groups: %w", err)
 		}
+
+		// Remove the meters used by the rules of the removed port groups.
+		for _, portGroupName := range removePortGroups {
+			err = client.MeterDelete(ovnACLMeterPrefix(portGroupName))
+			if err != nil {
+				return fmt.Errorf("Failed to delete unused OVN meters: %w", err)
+			}
+		}
 	}
 
 	return nil
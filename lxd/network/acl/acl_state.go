@@ -0,0 +1,171 @@
+package acl
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/metrics"
+	"github.com/canonical/lxd/lxd/network/openvswitch"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+)
+
+// ruleCounterName returns the name used to identify the counters of an ACL rule in the firewall and in OVN.
+func ruleCounterName(aclID int64, direction string, ruleIndex int) string {
+	return fmt.Sprintf("%s-%s-%d", OVNACLPortGroupName(aclID), direction, ruleIndex)
+}
+
+// localRuleCounters returns the counters of the ACL rules applied to the specified networks on this member, keyed
+// on counter name. Only the counters whose name starts with counterNamePrefix are returned.
+func localRuleCounters(s *state.State, aclNets map[string]NetworkACLUsage, counterNamePrefix string) (map[string]api.NetworkACLRuleState, error) {
+	counters := make(map[string]api.NetworkACLRuleState)
+	hasOVNNets := false
+
+	for _, aclNet := range aclNets {
+		if aclNet.Type == "ovn" {
+			hasOVNNets = true
+			continue
+		}
+
+		// Skip bridge networks that are not started on this member or that don't use any ACLs, as they have no
+		// ACL chain in the firewall.
+		if aclNet.Type != "bridge" || aclNet.Config["security.acls"] == "" || !shared.PathExists(fmt.Sprintf("/sys/class/net/%s", aclNet.Name)) {
+			continue
+		}
+
+		netCounters, err := s.Firewall.NetworkACLRuleCounters(aclNet.Name)
+		if err != nil {
+			return nil, fmt.Errorf("Failed getting ACL rule counters for network %q: %w", aclNet.Name, err)
+		}
+
+		for counterName, netCounter := range netCounters {
+			if !shared.StringHasPrefix(counterName, counterNamePrefix) {
+				continue
+			}
+
+			ruleCounters := counters[counterName]
+			ruleCounters.Packets += netCounter.Packets
+			ruleCounters.Bytes += netCounter.Bytes
+			counters[counterName] = ruleCounters
+		}
+	}
+
+	// OVN networks share ACL rules, so only get the OVN counters once.
+	if hasOVNNets {
+		client, err := openvswitch.NewOVN(s)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get OVN client: %w", err)
+		}
+
+		ovnCounters, err := client.ACLRuleCounters(s.GlobalConfig.NetworkOVNIntegrationBridge(), counterNamePrefix)
+		if err != nil {
+			return nil, fmt.Errorf("Failed getting OVN ACL rule counters: %w", err)
+		}
+
+		for counterName, ovnCounter := range ovnCounters {
+			ruleCounters := counters[counterName]
+			ruleCounters.Packets += ovnCounter.Packets
+			ruleCounters.Bytes += ovnCounter.Bytes
+			counters[counterName] = ruleCounters
+		}
+	}
+
+	return counters, nil
+}
+
+// aclStateFromCounters returns the state of the ACL using the supplied counters keyed on counter name.
+func aclStateFromCounters(aclID int64, aclInfo *api.NetworkACL, counters map[string]api.NetworkACLRuleState) *api.NetworkACLState {
+	aclState := &api.NetworkACLState{
+		Ingress: make([]api.NetworkACLRuleState, len(aclInfo.Ingress)),
+		Egress:  make([]api.NetworkACLRuleState, len(aclInfo.Egress)),
+	}
+
+	for ruleIndex := range aclInfo.Ingress {
+		aclState.Ingress[ruleIndex] = counters[ruleCounterName(aclID, "ingress", ruleIndex)]
+	}
+
+	for ruleIndex := range aclInfo.Egress {
+		aclState.Egress[ruleIndex] = counters[ruleCounterName(aclID, "egress", ruleIndex)]
+	}
+
+	return aclState
+}
+
+// Metrics returns the local ACL rule counters of all ACLs in the project as a metric set.
+func Metrics(s *state.State, projectName string) (*metrics.MetricSet, error) {
+	aclNets := map[string]NetworkACLUsage{}
+	aclInfos := map[int64]*api.NetworkACL{}
+
+	err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		aclNameIDs, err := tx.GetNetworkACLIDsByNames(ctx, projectName)
+		if err != nil {
+			return err
+		}
+
+		// Skip loading the networks if there are no ACLs.
+		if len(aclNameIDs) == 0 {
+			return nil
+		}
+
+		for aclName := range aclNameIDs {
+			aclID, aclInfo, err := tx.GetNetworkACL(ctx, projectName, aclName)
+			if err != nil {
+				return err
+			}
+
+			aclInfos[aclID] = aclInfo
+		}
+
+		networks, err := tx.GetCreatedNetworksByProject(ctx, projectName)
+		if err != nil {
+			return err
+		}
+
+		for networkID, network := range networks {
+			aclNets[network.Name] = NetworkACLUsage{
+				ID:     networkID,
+				Name:   network.Name,
+				Type:   network.Type,
+				Config: network.Config,
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading network ACLs for project %q: %w", projectName, err)
+	}
+
+	metricSet := metrics.NewMetricSet(map[string]string{"project": projectName})
+	if len(aclInfos) == 0 {
+		return metricSet, nil
+	}
+
+	counters, err := localRuleCounters(s, aclNets, ovnACLPortGroupPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	for aclID, aclInfo := range aclInfos {
+		aclState := aclStateFromCounters(aclID, aclInfo, counters)
+
+		addSamples := func(direction string, rules []api.NetworkACLRuleState) {
+			for ruleIndex, rule := range rules {
+				labels := func() map[string]string {
+					return map[string]string{"acl": aclInfo.Name, "direction": direction, "rule": strconv.Itoa(ruleIndex)}
+				}
+
+				metricSet.AddSamples(metrics.NetworkACLRuleMatchedPacketsTotal, metrics.Sample{Value: float64(rule.Packets), Labels: labels()})
+				metricSet.AddSamples(metrics.NetworkACLRuleMatchedBytesTotal, metrics.Sample{Value: float64(rule.Bytes), Labels: labels()})
+			}
+		}
+
+		addSamples("ingress", aclState.Ingress)
+		addSamples("egress", aclState.Egress)
+	}
+
+	return metricSet, nil
+}
@@ -7,6 +7,7 @@ import (
 	"net"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -443,6 +444,50 @@ func (d *common) validateRule(direction ruleDirection, rule api.NetworkACLRule)
 		}
 	}
 
+	// Validate RateLimit field.
+	if rule.RateLimit != "" {
+		if rule.Action != "allow" {
+			return fmt.Errorf("Rate limit can only be used with the %q action", "allow")
+		}
+
+		err := d.validateRateLimit(rule.RateLimit)
+		if err != nil {
+			return fmt.Errorf("Invalid rate limit: %w", err)
+		}
+	}
+
+	// Validate ConnectionLimit field.
+	if rule.ConnectionLimit != "" {
+		if rule.Action != "allow" {
+			return fmt.Errorf("Connection limit can only be used with the %q action", "allow")
+		}
+
+		connLimit, err := strconv.ParseUint(rule.ConnectionLimit, 10, 32)
+		if err != nil || connLimit == 0 {
+			return fmt.Errorf("Invalid connection limit %q: Must be a positive integer", rule.ConnectionLimit)
+		}
+	}
+
+	return nil
+}
+
+// validateRateLimit checks that the rate limit is in the "<packets>/<unit>" format.
+func (d *common) validateRateLimit(rateLimit string) error {
+	packetsStr, unit, found := strings.Cut(rateLimit, "/")
+	if !found {
+		return fmt.Errorf("Rate limit %q must be in the format <packets>/<unit>", rateLimit)
+	}
+
+	packets, err := strconv.ParseUint(packetsStr, 10, 32)
+	if err != nil || packets == 0 {
+		return fmt.Errorf("Packets %q must be a positive integer", packetsStr)
+	}
+
+	validUnits := []string{"second", "minute", "hour", "day"}
+	if !shared.ValueInSlice(unit, validUnits) {
+		return fmt.Errorf("Unit must be one of: %s", strings.Join(validUnits, ", "))
+	}
+
 	return nil
 }
 
@@ -849,3 +894,63 @@ func (d *common) GetLog(ctx context.Context, clientType request.ClientType) (str
 
 	return strings.Join(logEntries, "\n") + "\n", nil
 }
+
+// GetState gets the ACL rule counters.
+func (d *common) GetState(clientType request.ClientType) (*api.NetworkACLState, error) {
+	// Get a list of networks that are using this ACL (either directly or indirectly via a NIC).
+	aclNets := map[string]NetworkACLUsage{}
+	err := NetworkUsage(d.state, d.projectName, []string{d.info.Name}, aclNets)
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting ACL network usage: %w", err)
+	}
+
+	counters, err := localRuleCounters(d.state, aclNets, fmt.Sprintf("%s-", OVNACLPortGroupName(d.id)))
+	if err != nil {
+		return nil, err
+	}
+
+	aclState := aclStateFromCounters(d.id, d.info, counters)
+
+	// Aggregates the counters from the rest of the cluster.
+	if clientType == request.ClientTypeNormal {
+		// Setup notifier to reach the rest of the cluster.
+		notifier, err := cluster.NewNotifier(d.state, d.state.Endpoints.NetworkCert(), d.state.ServerCert(), cluster.NotifyAll)
+		if err != nil {
+			return nil, err
+		}
+
+		mu := sync.Mutex{}
+		err = notifier(func(member db.NodeInfo, client lxd.InstanceServer) error {
+			memberState, err := client.UseProject(d.projectName).GetNetworkACLState(d.info.Name)
+			if err != nil {
+				return err
+			}
+
+			// Prevent concurrent writes to the ACL state.
+			mu.Lock()
+			defer mu.Unlock()
+
+			// Rules are matched by index, ignore the member response if the rules have changed meanwhile.
+			if len(memberState.Ingress) != len(aclState.Ingress) || len(memberState.Egress) != len(aclState.Egress) {
+				return nil
+			}
+
+			for i, rule := range memberState.Ingress {
+				aclState.Ingress[i].Packets += rule.Packets
+				aclState.Ingress[i].Bytes += rule.Bytes
+			}
+
+			for i, rule := range memberState.Egress {
+				aclState.Egress[i].Packets += rule.Packets
+				aclState.Egress[i].Bytes += rule.Bytes
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return aclState, nil
+}
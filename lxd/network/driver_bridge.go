@@ -10,6 +10,7 @@ import (
 	"os"
 	"os/exec"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -19,6 +20,7 @@ import (
 
 	lxd "github.com/canonical/lxd/client"
 	"github.com/canonical/lxd/lxd/apparmor"
+	"github.com/canonical/lxd/lxd/bgp"
 	"github.com/canonical/lxd/lxd/cluster"
 	"github.com/canonical/lxd/lxd/cluster/request"
 	"github.com/canonical/lxd/lxd/daemon"
@@ -53,9 +55,17 @@ const ForkdnsServersListFile = "servers.conf"
 
 var forkdnsServersLock sync.Mutex
 
+// vxlanDHCPSlots records the share of the DHCP ranges served by this member for each VXLAN bridge, keyed on
+// network ID. It is used to detect when the ranges need splitting again after cluster membership changes.
+var vxlanDHCPSlots = map[int64][2]int{}
+var vxlanDHCPSlotsMu sync.Mutex
+
 // Default MTU for bridge interface.
 const bridgeMTUDefault = 1500
 
+// bridgeMulticastKeys are the config keys controlling the multicast snooping settings of native bridges.
+var bridgeMulticastKeys = []string{"bridge.multicast.snooping", "bridge.multicast.querier", "bridge.multicast.igmp_version", "bridge.multicast.mld_version"}
+
 // bridge represents a LXD bridge network.
 type bridge struct {
 	common
@@ -70,6 +80,8 @@ func (n *bridge) DBType() db.NetworkType {
 func (n *bridge) Info() Info {
 	info := n.common.Info()
 	info.AddressForwards = true
+	info.Peering = true
+	info.Reservations = true
 
 	return info
 }
@@ -118,7 +130,8 @@ func (n *bridge) FillConfig(config map[string]string) error {
 			config["ipv4.nat"] = "true"
 		}
 
-		if config["ipv6.address"] == "" {
+		// IPv6 prefix delegation provides the IPv6 address.
+		if config["ipv6.address"] == "" && config["ipv6.delegation.parent"] == "" {
 			content, err := os.ReadFile("/proc/sys/net/ipv6/conf/default/disable_ipv6")
 			if err == nil && string(content) == "0\n" {
 				config["ipv6.address"] = "auto"
@@ -233,6 +246,17 @@ func (n *bridge) Validate(config map[string]string) error {
 		//  shortdesc: Peer session hold time
 		//  scope: global
 
+		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=bgp.peers.NAME.import)
+		// When enabled, the routes learned from the peer are installed into the host routing table.
+		// See {ref}`network-bgp-import`.
+		// ---
+		//  type: bool
+		//  condition: BGP server
+		//  defaultdesc: `false`
+		//  required: no
+		//  shortdesc: Whether to import the routes learned from the peer
+		//  scope: global
+
 		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=bgp.ipv4.nexthop)
 		//
 		// ---
@@ -284,21 +308,59 @@ func (n *bridge) Validate(config map[string]string) error {
 		//  scope: global
 		"bridge.hwaddr": validate.Optional(validate.IsNetworkMAC),
 		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=bridge.mtu)
-		// The default value varies depending on whether the bridge uses a tunnel or a fan setup.
+		// The default value varies depending on whether the bridge uses a tunnel, a fan or a VXLAN overlay setup.
 		// ---
 		//  type: integer
-		//  defaultdesc: `1500` if `bridge.mode=standard`, `1480` if `bridge.mode=fan` and `fan.type=ipip`, or `1450` if `bridge.mode=fan` and `fan.type=vxlan`
+		//  defaultdesc: `1500` if `bridge.mode=standard`, `1480` if `bridge.mode=fan` and `fan.type=ipip`, or `1450` if `bridge.mode=fan` and `fan.type=vxlan` or if `bridge.mode=vxlan`
 		//  shortdesc: Bridge MTU
 		//  scope: global
 		"bridge.mtu": validate.Optional(validate.IsNetworkMTU),
 		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=bridge.mode)
-		// Possible values are `standard` and `fan`.
+		// Possible values are `standard`, `fan` and `vxlan`.
 		// ---
 		//  type: string
 		//  defaultdesc: `standard`
 		//  shortdesc: Bridge operation mode
 		//  scope: global
-		"bridge.mode": validate.Optional(validate.IsOneOf("standard", "fan")),
+		"bridge.mode": validate.Optional(validate.IsOneOf("standard", "fan", "vxlan")),
+		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=bridge.multicast.snooping)
+		// When enabled, the bridge tracks IGMP and MLD group membership and only forwards multicast traffic to the ports that joined the group.
+		// See {ref}`network-bridge-multicast`.
+		// ---
+		//  type: bool
+		//  condition: native bridge driver
+		//  defaultdesc: `true`
+		//  shortdesc: Whether to enable IGMP and MLD snooping
+		//  scope: global
+		"bridge.multicast.snooping": validate.Optional(validate.IsBool),
+		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=bridge.multicast.querier)
+		// Enable this option if there is no other multicast querier on the network.
+		// Without a querier, group memberships expire and multicast traffic is flooded to all ports again.
+		// ---
+		//  type: bool
+		//  condition: native bridge driver
+		//  defaultdesc: `false`
+		//  shortdesc: Whether the bridge sends IGMP and MLD queries
+		//  scope: global
+		"bridge.multicast.querier": validate.Optional(validate.IsBool),
+		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=bridge.multicast.igmp_version)
+		// Possible values are `2` and `3`.
+		// ---
+		//  type: integer
+		//  condition: native bridge driver
+		//  defaultdesc: `2`
+		//  shortdesc: IGMP version used by the querier
+		//  scope: global
+		"bridge.multicast.igmp_version": validate.Optional(validate.IsOneOf("2", "3")),
+		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=bridge.multicast.mld_version)
+		// Possible values are `1` and `2`.
+		// ---
+		//  type: integer
+		//  condition: native bridge driver
+		//  defaultdesc: `1`
+		//  shortdesc: MLD version used by the querier
+		//  scope: global
+		"bridge.multicast.mld_version": validate.Optional(validate.IsOneOf("1", "2")),
 		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=fan.overlay_subnet)
 		// Use CIDR notation.
 		// ---
@@ -422,6 +484,22 @@ func (n *bridge) Validate(config map[string]string) error {
 		//  shortdesc: IPv4 ranges to use for DHCP
 		//  scope: global
 		"ipv4.dhcp.ranges": validate.Optional(validate.IsListOf(validate.IsNetworkRangeV4)),
+		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=ipv4.dhcp.boot.filename)
+		// This is used to boot instances over the network (PXE).
+		// ---
+		//  type: string
+		//  condition: IPv4 DHCP
+		//  shortdesc: Boot file name sent to DHCP clients
+		//  scope: global
+		"ipv4.dhcp.boot.filename": validate.Optional(DHCPBootFilenameValidate),
+		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=ipv4.dhcp.boot.next_server)
+		// This is the address of the TFTP server that clients load the boot file from.
+		// ---
+		//  type: string
+		//  condition: IPv4 DHCP
+		//  shortdesc: Next server address sent to DHCP clients
+		//  scope: global
+		"ipv4.dhcp.boot.next_server": validate.Optional(validate.IsNetworkAddressV4),
 		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=ipv4.routes)
 		// Specify a comma-separated list of IPv4 CIDR subnets.
 		// ---
@@ -463,6 +541,36 @@ func (n *bridge) Validate(config map[string]string) error {
 
 			return validate.IsNetworkAddressCIDRV6(value)
 		}),
+		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=ipv6.delegation.parent)
+		// When set, LXD requests an IPv6 prefix on this interface using DHCPv6 prefix delegation and
+		// configures the bridge with a `/64` subnet of the delegated prefix.
+		// The bridge address, router advertisements and firewall rules follow the prefix when it changes.
+		//
+		// This option cannot be used together with `ipv6.address`.
+		// See {ref}`network-bridge-ipv6-delegation`.
+		// ---
+		//  type: string
+		//  condition: standard mode
+		//  shortdesc: Uplink interface to request an IPv6 prefix on
+		//  scope: global
+		"ipv6.delegation.parent": validate.Optional(validate.IsInterfaceName),
+		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=ipv6.delegation.prefix_length)
+		// The length is sent to the DHCPv6 server as a hint, and the server might delegate a prefix of a different length.
+		// ---
+		//  type: integer
+		//  condition: IPv6 prefix delegation
+		//  shortdesc: Length of the prefix to request
+		//  scope: global
+		"ipv6.delegation.prefix_length": validate.Optional(validate.IsInRange(1, 64)),
+		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=ipv6.delegation.subnet)
+		// Bridges using the same parent interface share the delegated prefix and must use different subnets.
+		// ---
+		//  type: integer
+		//  condition: IPv6 prefix delegation
+		//  defaultdesc: `0`
+		//  shortdesc: Index of the `/64` subnet of the delegated prefix to use for the bridge
+		//  scope: global
+		"ipv6.delegation.subnet": validate.Optional(validate.IsUint32),
 		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=ipv6.firewall)
 		//
 		// ---
@@ -673,6 +781,53 @@ func (n *bridge) Validate(config map[string]string) error {
 		//  shortdesc: Whether to log egress traffic that doesn’t match any ACL rule
 		//  scope: global
 		"security.acls.default.egress.logged": validate.Optional(validate.IsBool),
+		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=security.flows.logged)
+		// When enabled, the connections opened and closed by the instances on the network are emitted as `network-flow` events.
+		//
+		// See {ref}`network-flow-log`.
+		// ---
+		//  type: bool
+		//  defaultdesc: `false`
+		//  shortdesc: Whether to log the connections of the instances
+		//  scope: global
+		"security.flows.logged": validate.Optional(validate.IsBool),
+		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=security.isolated)
+		// When enabled, traffic routed between this network and the other bridge networks on the host is blocked, except for the networks it is peered with.
+		// Networks with peerings are always isolated.
+		//
+		// See {ref}`network-bridge-peering`.
+		// ---
+		//  type: bool
+		//  defaultdesc: `false`
+		//  shortdesc: Whether to isolate the network from the other bridge networks
+		//  scope: global
+		"security.isolated": validate.Optional(validate.IsBool),
+		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=vxlan.id)
+		// The VXLAN network identifier must be the same on all cluster members and unique among the VXLAN overlays sharing the same underlay.
+		// ---
+		//  type: integer
+		//  condition: VXLAN mode
+		//  defaultdesc: ID of the network
+		//  shortdesc: VXLAN network identifier of the overlay
+		//  scope: global
+		"vxlan.id": validate.Optional(validate.IsInRange(1, 16777215)),
+		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=vxlan.port)
+		//
+		// ---
+		//  type: integer
+		//  condition: VXLAN mode
+		//  defaultdesc: `4789`
+		//  shortdesc: UDP port used by the VXLAN overlay
+		//  scope: global
+		"vxlan.port": validate.Optional(validate.IsNetworkPort),
+		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=vxlan.interface)
+		//
+		// ---
+		//  type: string
+		//  condition: VXLAN mode
+		//  shortdesc: Host interface to use for the VXLAN overlay
+		//  scope: global
+		"vxlan.interface": validate.Optional(validate.IsInterfaceName),
 
 		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=user.*)
 		//
@@ -784,6 +939,28 @@ func (n *bridge) Validate(config map[string]string) error {
 		rules[k] = v
 	}
 
+	// Add the DHCP option validation rules.
+	// lxdmeta:generate(entities=network-bridge; group=network-conf; key=ipv4.dhcp.option.NAME)
+	// `NAME` is either a supported option name or a numeric option code.
+	// See {ref}`network-dhcp-options` for more information.
+	// ---
+	//  type: string
+	//  condition: IPv4 DHCP
+	//  shortdesc: Additional DHCPv4 option sent to clients
+	//  scope: global
+
+	// lxdmeta:generate(entities=network-bridge; group=network-conf; key=ipv6.dhcp.option.NAME)
+	// `NAME` is either a supported option name or a numeric option code.
+	// See {ref}`network-dhcp-options` for more information.
+	// ---
+	//  type: string
+	//  condition: IPv6 DHCP
+	//  shortdesc: Additional DHCPv6 option sent to clients
+	//  scope: global
+	for k, v := range DHCPOptionsValidationRules(config, false) {
+		rules[k] = v
+	}
+
 	// Validate the configuration.
 	err = n.validate(config, rules)
 	if err != nil {
@@ -804,6 +981,11 @@ func (n *bridge) Validate(config map[string]string) error {
 		return fmt.Errorf("Network name too long to use with the FAN (must be 11 characters or less)")
 	}
 
+	// Validate network name when used in VXLAN mode.
+	if bridgeMode == "vxlan" && len(n.name) > 12 {
+		return fmt.Errorf("Network name too long to use with a VXLAN overlay (must be 12 characters or less)")
+	}
+
 	for k, v := range config {
 		key := k
 		// Bridge mode checks
@@ -819,6 +1001,14 @@ func (n *bridge) Validate(config map[string]string) error {
 			return fmt.Errorf("FAN configuration may only be set when in 'fan' mode")
 		}
 
+		if bridgeMode != "vxlan" && strings.HasPrefix(key, "vxlan.") && v != "" {
+			return fmt.Errorf("VXLAN configuration may only be set when in 'vxlan' mode")
+		}
+
+		if bridgeMode == "vxlan" && strings.HasPrefix(key, "tunnel.") && v != "" {
+			return fmt.Errorf("Tunnels may not be set when in 'vxlan' mode")
+		}
+
 		// MTU checks
 		if key == "bridge.mtu" && v != "" {
 			mtu, err := strconv.ParseInt(v, 10, 64)
@@ -827,7 +1017,7 @@ func (n *bridge) Validate(config map[string]string) error {
 			}
 
 			ipv6 := config["ipv6.address"]
-			if ipv6 != "" && ipv6 != "none" && mtu < 1280 {
+			if ((ipv6 != "" && ipv6 != "none") || config["ipv6.delegation.parent"] != "") && mtu < 1280 {
 				return fmt.Errorf("The minimum MTU for an IPv6 network is 1280")
 			}
 
@@ -847,9 +1037,24 @@ func (n *bridge) Validate(config map[string]string) error {
 					}
 				}
 			}
+
+			if config["bridge.mode"] == "vxlan" && mtu > 1450 {
+				return fmt.Errorf("Maximum MTU for a VXLAN overlay bridge is 1450")
+			}
 		}
 	}
 
+	// Check multicast settings are only used with native bridges and that the querier has snooping enabled.
+	for _, key := range bridgeMulticastKeys {
+		if config[key] != "" && config["bridge.driver"] == "openvswitch" {
+			return fmt.Errorf("%q cannot be used with the openvswitch bridge driver", key)
+		}
+	}
+
+	if shared.IsTrue(config["bridge.multicast.querier"]) && shared.IsFalse(config["bridge.multicast.snooping"]) {
+		return fmt.Errorf(`"bridge.multicast.querier" requires "bridge.multicast.snooping" to be enabled`)
+	}
+
 	// Check using same MAC address on every cluster node is safe.
 	if config["bridge.hwaddr"] != "" {
 		err = n.checkClusterWideMACSafe(config)
@@ -858,6 +1063,35 @@ func (n *bridge) Validate(config map[string]string) error {
 		}
 	}
 
+	// Check IPv6 prefix delegation.
+	if config["ipv6.delegation.parent"] != "" {
+		if shared.ValueInSlice(bridgeMode, []string{"fan", "vxlan"}) {
+			return fmt.Errorf(`"ipv6.delegation.parent" cannot be used with "bridge.mode" set to %q`, bridgeMode)
+		}
+
+		if config["ipv6.address"] != "" {
+			return fmt.Errorf(`"ipv6.address" cannot be set when "ipv6.delegation.parent" is set`)
+		}
+
+		if config["ipv6.dhcp.ranges"] != "" {
+			return fmt.Errorf(`"ipv6.dhcp.ranges" cannot be set when "ipv6.delegation.parent" is set`)
+		}
+
+		if config["ipv6.delegation.prefix_length"] != "" && config["ipv6.delegation.subnet"] != "" {
+			prefixLength, _ := strconv.ParseUint(config["ipv6.delegation.prefix_length"], 10, 64)
+			index, _ := strconv.ParseUint(config["ipv6.delegation.subnet"], 10, 64)
+			if prefixLength > 0 && index >= uint64(1)<<(64-prefixLength) {
+				return fmt.Errorf(`"ipv6.delegation.subnet" must be lower than %d for a /%d prefix`, uint64(1)<<(64-prefixLength), prefixLength)
+			}
+		}
+	} else {
+		for _, key := range []string{"ipv6.delegation.prefix_length", "ipv6.delegation.subnet"} {
+			if config[key] != "" {
+				return fmt.Errorf("%q requires %q to be set", key, "ipv6.delegation.parent")
+			}
+		}
+	}
+
 	// Check IPv4 OVN ranges.
 	if config["ipv4.ovn.ranges"] != "" && shared.IsTrueOrEmpty(config["ipv4.dhcp"]) {
 		dhcpSubnet := n.DHCPv4Subnet()
@@ -934,6 +1168,11 @@ func (n *bridge) Validate(config map[string]string) error {
 		}
 	}
 
+	// Check network isolation is supported by the firewall driver.
+	if shared.IsTrue(config["security.isolated"]) && n.state.Firewall.String() == "xtables" {
+		return fmt.Errorf(`"security.isolated" is not supported by the xtables firewall driver`)
+	}
+
 	return nil
 }
 
@@ -970,6 +1209,8 @@ func (n *bridge) Delete(clientType request.ClientType) error {
 		return err
 	}
 
+	n.vxlanDHCPSlotsDelete()
+
 	return n.common.delete()
 }
 
@@ -1057,6 +1298,15 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 
 	var err error
 
+	// Request the IPv6 prefix of the bridge when using prefix delegation.
+	err = n.delegationSetup(oldConfig)
+	if err != nil {
+		return err
+	}
+
+	// Get the IPv6 address of the bridge (empty until a prefix is delegated when using prefix delegation).
+	bridgeIPv6Address := n.ipv6Address()
+
 	// Build up the bridge interface's settings.
 	bridge := ip.Bridge{
 		Link: ip.Link{
@@ -1068,6 +1318,22 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 	// Get a list of tunnels.
 	tunnels := n.getTunnels()
 
+	// Get the VXLAN overlay peers and the share of the DHCP ranges served by this member.
+	var vxlanPeers []net.IP
+	vxlanSlot, vxlanSlots := 0, 1
+	if n.config["bridge.mode"] == "vxlan" {
+		vxlanPeers, vxlanSlot, vxlanSlots, err = n.vxlanMembers()
+		if err != nil {
+			return err
+		}
+
+		vxlanDHCPSlotsMu.Lock()
+		vxlanDHCPSlots[n.id] = [2]int{vxlanSlot, vxlanSlots}
+		vxlanDHCPSlotsMu.Unlock()
+	} else {
+		n.vxlanDHCPSlotsDelete()
+	}
+
 	// Decide the MTU for the bridge interface.
 	if n.config["bridge.mtu"] != "" {
 		mtuInt, err := strconv.ParseUint(n.config["bridge.mtu"], 10, 32)
@@ -1078,6 +1344,8 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 		bridge.MTU = uint32(mtuInt)
 	} else if len(tunnels) > 0 {
 		bridge.MTU = 1400
+	} else if n.config["bridge.mode"] == "vxlan" {
+		bridge.MTU = 1450
 	} else if n.config["bridge.mode"] == "fan" {
 		if n.config["fan.type"] == "ipip" {
 			bridge.MTU = 1480
@@ -1178,7 +1446,7 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 	}
 
 	// IPv6 bridge configuration.
-	if !shared.ValueInSlice(n.config["ipv6.address"], []string{"", "none"}) {
+	if !shared.ValueInSlice(bridgeIPv6Address, []string{"", "none"}) {
 		if !shared.PathExists("/proc/sys/net/ipv6") {
 			return fmt.Errorf("Network has ipv6.address but kernel IPv6 support is missing")
 		}
@@ -1252,6 +1520,28 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 		if err != nil {
 			n.logger.Warn(fmt.Sprintf("Failed enabling VLAN filtering: %v", err))
 		}
+
+		// Apply the multicast snooping settings if any are set or have changed (reverting to the kernel
+		// defaults when unset). Otherwise leave the bridge's kernel defaults alone.
+		multicastChanged := false
+		for _, key := range bridgeMulticastKeys {
+			if n.config[key] != "" || (oldConfig != nil && oldConfig[key] != n.config[key]) {
+				multicastChanged = true
+				break
+			}
+		}
+
+		if multicastChanged {
+			err = BridgeMulticastSet(n.name, BridgeMulticastConfig{
+				Snooping:    shared.IsTrueOrEmpty(n.config["bridge.multicast.snooping"]),
+				Querier:     shared.IsTrue(n.config["bridge.multicast.querier"]),
+				IGMPVersion: n.config["bridge.multicast.igmp_version"],
+				MLDVersion:  n.config["bridge.multicast.mld_version"],
+			})
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	// Bring it up.
@@ -1447,13 +1737,19 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 				expiry = n.config["ipv4.dhcp.expiry"]
 			}
 
-			if n.config["ipv4.dhcp.ranges"] != "" {
-				for _, dhcpRange := range strings.Split(n.config["ipv4.dhcp.ranges"], ",") {
-					dhcpRange = strings.TrimSpace(dhcpRange)
-					dnsmasqCmd = append(dnsmasqCmd, []string{"--dhcp-range", fmt.Sprintf("%s,%s", strings.Replace(dhcpRange, "-", ",", -1), expiry)}...)
+			dhcpRanges := n.DHCPv4Ranges()
+			if len(dhcpRanges) == 0 {
+				dhcpRanges = append(dhcpRanges, shared.IPRange{Start: dhcpalloc.GetIP(subnet, 2), End: dhcpalloc.GetIP(subnet, -2)})
+			}
+
+			for _, dhcpRange := range dhcpRanges {
+				// Each member of a VXLAN overlay only serves its own share of the ranges.
+				memberRange := IPRangeSplit(dhcpRange, vxlanSlot, vxlanSlots)
+				if memberRange == nil {
+					continue
 				}
-			} else {
-				dnsmasqCmd = append(dnsmasqCmd, []string{"--dhcp-range", fmt.Sprintf("%s,%s,%s", dhcpalloc.GetIP(subnet, 2).String(), dhcpalloc.GetIP(subnet, -2).String(), expiry)}...)
+
+				dnsmasqCmd = append(dnsmasqCmd, []string{"--dhcp-range", fmt.Sprintf("%s,%s,%s", memberRange.Start.String(), memberRange.End.String(), expiry)}...)
 			}
 		}
 
@@ -1542,7 +1838,7 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 	var ipv6Address net.IP
 
 	// Configure IPv6.
-	if !shared.ValueInSlice(n.config["ipv6.address"], []string{"", "none"}) {
+	if !shared.ValueInSlice(bridgeIPv6Address, []string{"", "none"}) {
 		// Enable IPv6 for the subnet.
 		err := util.SysctlSet(fmt.Sprintf("net/ipv6/conf/%s/disable_ipv6", n.name), "0")
 		if err != nil {
@@ -1552,7 +1848,7 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 		var subnet *net.IPNet
 
 		// Parse the subnet.
-		ipv6Address, subnet, err = net.ParseCIDR(n.config["ipv6.address"])
+		ipv6Address, subnet, err = net.ParseCIDR(bridgeIPv6Address)
 		if err != nil {
 			return fmt.Errorf("Failed parsing ipv6.address: %w", err)
 		}
@@ -1592,13 +1888,19 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 			}
 
 			if shared.IsTrue(n.config["ipv6.dhcp.stateful"]) {
-				if n.config["ipv6.dhcp.ranges"] != "" {
-					for _, dhcpRange := range strings.Split(n.config["ipv6.dhcp.ranges"], ",") {
-						dhcpRange = strings.TrimSpace(dhcpRange)
-						dnsmasqCmd = append(dnsmasqCmd, []string{"--dhcp-range", fmt.Sprintf("%s,%d,%s", strings.Replace(dhcpRange, "-", ",", -1), subnetSize, expiry)}...)
+				dhcpRanges := n.DHCPv6Ranges()
+				if len(dhcpRanges) == 0 {
+					dhcpRanges = append(dhcpRanges, shared.IPRange{Start: dhcpalloc.GetIP(subnet, 2), End: dhcpalloc.GetIP(subnet, -1)})
+				}
+
+				for _, dhcpRange := range dhcpRanges {
+					// Each member of a VXLAN overlay only serves its own share of the ranges.
+					memberRange := IPRangeSplit(dhcpRange, vxlanSlot, vxlanSlots)
+					if memberRange == nil {
+						continue
 					}
-				} else {
-					dnsmasqCmd = append(dnsmasqCmd, []string{"--dhcp-range", fmt.Sprintf("%s,%s,%d,%s", dhcpalloc.GetIP(subnet, 2), dhcpalloc.GetIP(subnet, -1), subnetSize, expiry)}...)
+
+					dnsmasqCmd = append(dnsmasqCmd, []string{"--dhcp-range", fmt.Sprintf("%s,%s,%d,%s", memberRange.Start.String(), memberRange.End.String(), subnetSize, expiry)}...)
 				}
 			} else {
 				dnsmasqCmd = append(dnsmasqCmd, []string{"--dhcp-range", fmt.Sprintf("::,constructor:%s,ra-stateless,ra-names", n.name)}...)
@@ -1652,7 +1954,7 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 		// Add the address.
 		addr := &ip.Addr{
 			DevName: n.name,
-			Address: n.config["ipv6.address"],
+			Address: bridgeIPv6Address,
 			Family:  ip.FamilyV6,
 		}
 
@@ -2008,6 +2310,14 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 		}
 	}
 
+	// Configure the VXLAN overlay.
+	if n.config["bridge.mode"] == "vxlan" {
+		err = n.vxlanSetup(bridge.MTU, bridge.Address, vxlanPeers)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Generate and load apparmor profiles.
 	err = apparmor.NetworkLoad(n.state.OS, n)
 	if err != nil {
@@ -2070,6 +2380,35 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 			}
 		}
 
+		// Create DHCP reservations directory.
+		if shared.ValueInSlice("--dhcp-no-override", dnsmasqCmd) {
+			err = os.MkdirAll(dnsmasq.ReservationsPath(n.name), 0755)
+			if err != nil {
+				return err
+			}
+
+			dnsmasqCmd = append(dnsmasqCmd, fmt.Sprintf("--dhcp-hostsfile=%s", dnsmasq.ReservationsPath(n.name)))
+
+			err = os.MkdirAll(dnsmasq.ReservationOptionsPath(n.name), 0755)
+			if err != nil {
+				return err
+			}
+
+			dnsmasqCmd = append(dnsmasqCmd, fmt.Sprintf("--dhcp-optsfile=%s", dnsmasq.ReservationOptionsPath(n.name)))
+
+			// Add the network wide DHCP options and the instance device specific DHCP options directory.
+			for _, dhcpOption := range DHCPOptionsDNSMasq(n.config) {
+				dnsmasqCmd = append(dnsmasqCmd, fmt.Sprintf("--dhcp-option=%s", dhcpOption))
+			}
+
+			err = os.MkdirAll(dnsmasq.OptionsPath(n.name), 0755)
+			if err != nil {
+				return err
+			}
+
+			dnsmasqCmd = append(dnsmasqCmd, fmt.Sprintf("--dhcp-optsfile=%s", dnsmasq.OptionsPath(n.name)))
+		}
+
 		// Check for dnsmasq.
 		_, err := exec.LookPath("dnsmasq")
 		if err != nil {
@@ -2082,6 +2421,12 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 			return err
 		}
 
+		// Update the DHCP reservations.
+		err = n.reservationsUpdateDNSMasq()
+		if err != nil {
+			return err
+		}
+
 		// Create subprocess object dnsmasq.
 		dnsmasqLogPath := shared.LogPath(fmt.Sprintf("dnsmasq.%s.log", n.name))
 		p, err := subprocess.NewProcess(command, dnsmasqCmd, "", dnsmasqLogPath)
@@ -2181,8 +2526,82 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 	}
 
 	// Setup firewall.
+	err = n.setupFirewall(ipv4Address, ipv6Address, fwOpts)
+	if err != nil {
+		return err
+	}
+
+	// Record the firewall options so that the rules can be reapplied when the delegated IPv6 prefix changes.
+	n.delegationRecordFirewall(ipv4Address, fwOpts)
+
+	// Setup BGP.
+	err = n.bgpSetup(oldConfig)
+	if err != nil {
+		return err
+	}
+
+	n.bgpImportSetup(oldConfig)
+
+	// Follow the DHCP leases handed out by dnsmasq.
+	n.leasesWatch()
+
+	// Log the connections of the instances.
+	if shared.IsTrue(n.config["security.flows.logged"]) {
+		n.flowLogSetup()
+	} else {
+		flowLogUnregister(n)
+	}
+
+	revert.Success()
+	return nil
+}
+
+// bgpImportSetup installs the routes learned from the BGP peers that have route import enabled into the host
+// routing table, and keeps them in sync as they change.
+func (n *bridge) bgpImportSetup(oldConfig map[string]string) {
+	peers := bgpImportPeers(n.config)
+
+	// Stop removes the importer, and is followed by a setup with the old config when the bridge driver changes.
+	// So only skip the setup if the importer is still registered, rather than relying on the config alone.
+	if oldConfig != nil && fmt.Sprint(peers) == fmt.Sprint(bgpImportPeers(oldConfig)) && (len(peers) == 0 || n.state.BGP.HasRouteImporter(n.bgpImportOwner())) {
+		return // No change in the peers to import routes from.
+	}
+
+	if len(peers) == 0 {
+		n.state.BGP.RemoveRouteImporter(n.bgpImportOwner())
+		return
+	}
+
+	importRoute := func(route bgp.Route) *ip.Route {
+		family := ip.FamilyV4
+		if route.Prefix.IP.To4() == nil {
+			family = ip.FamilyV6
+		}
+
+		return &ip.Route{
+			Route:  route.Prefix.String(),
+			Via:    route.Nexthop.String(),
+			Proto:  "bgp",
+			Metric: bgpImportRouteMetric,
+			Family: family,
+		}
+	}
+
+	addRoute := func(route bgp.Route) error {
+		return importRoute(route).Add()
+	}
+
+	removeRoute := func(route bgp.Route) error {
+		return importRoute(route).Delete()
+	}
+
+	n.state.BGP.AddRouteImporter(n.bgpImportOwner(), peers, n.bgpImportRouteHandler(addRoute, removeRoute))
+}
+
+// setupFirewall applies the firewall rules of the network, its ACLs, address forwards and peerings.
+func (n *bridge) setupFirewall(ipv4Address net.IP, ipv6Address net.IP, fwOpts firewallDrivers.Opts) error {
 	n.logger.Debug("Setting up firewall")
-	err = n.state.Firewall.NetworkSetup(n.name, ipv4Address, ipv6Address, fwOpts)
+	err := n.state.Firewall.NetworkSetup(n.name, ipv4Address, ipv6Address, fwOpts)
 	if err != nil {
 		return fmt.Errorf("Failed to setup firewall: %w", err)
 	}
@@ -2208,13 +2627,12 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 		return err
 	}
 
-	// Setup BGP.
-	err = n.bgpSetup(oldConfig)
+	// Setup network isolation and peerings.
+	err = n.peerSetupFirewall()
 	if err != nil {
 		return err
 	}
 
-	revert.Success()
 	return nil
 }
 
@@ -2232,6 +2650,24 @@ func (n *bridge) Stop() error {
 		return err
 	}
 
+	// Release the delegated IPv6 prefix.
+	n.delegationClear()
+
+	// Remove network isolation and peerings.
+	err = n.state.Firewall.NetworkClearPeers(n.name)
+	if err != nil {
+		return err
+	}
+
+	// Stop following the DHCP leases.
+	n.leasesUnwatch()
+
+	// Stop logging the connections of the instances.
+	flowLogUnregister(n)
+
+	// Forget the share of the DHCP ranges, so that it is recomputed when the network starts again.
+	n.vxlanDHCPSlotsDelete()
+
 	// Destroy the bridge interface
 	if n.config["bridge.driver"] == "openvswitch" {
 		ovs := openvswitch.NewOVS()
@@ -2439,16 +2875,43 @@ func (n *bridge) spawnForkDNS(listenAddress string) error {
 	return nil
 }
 
-// HandleHeartbeat refreshes forkdns servers. Retrieves the IPv4 address of each cluster node (excluding ourselves)
-// for this network. It then updates the forkdns server list file if there are changes.
+// HandleHeartbeat refreshes the VXLAN overlay peers and forkdns servers.
+// For VXLAN overlays, the DHCP ranges are split again between the members when the cluster members have changed.
+// For fan bridges, it retrieves the IPv4 address of each cluster node (excluding ourselves) for this network and
+// updates the forkdns server list file if there are changes.
 func (n *bridge) HandleHeartbeat(heartbeatData *cluster.APIHeartbeat) error {
+	localClusterAddress := n.state.LocalConfig.ClusterAddress()
+
+	// Refresh the VXLAN overlay peers from the cluster members.
+	if n.config["bridge.mode"] == "vxlan" && InterfaceExists(n.vxlanName()) {
+		peers, slot, slots, err := n.vxlanMembers()
+		if err != nil {
+			return err
+		}
+
+		vxlanDHCPSlotsMu.Lock()
+		curSlots, found := vxlanDHCPSlots[n.id]
+		vxlanDHCPSlotsMu.Unlock()
+
+		// Apply the new share of the DHCP ranges, this also refreshes the peers.
+		if !found || curSlots != [2]int{slot, slots} {
+			n.logger.Info("Cluster members changed, refreshing VXLAN overlay", logger.Ctx{"slot": slot, "slots": slots})
+
+			return n.setup(n.config)
+		}
+
+		err = n.vxlanPeersSync(peers)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Make sure forkdns has been setup.
 	if !shared.PathExists(shared.VarPath("networks", n.name, "forkdns.pid")) {
 		return nil
 	}
 
 	addresses := []string{}
-	localClusterAddress := n.state.LocalConfig.ClusterAddress()
 
 	n.logger.Info("Refreshing forkdns peers")
 
@@ -2506,6 +2969,218 @@ func (n *bridge) HandleHeartbeat(heartbeatData *cluster.APIHeartbeat) error {
 	return nil
 }
 
+// vxlanName returns the name of the VXLAN overlay interface.
+func (n *bridge) vxlanName() string {
+	return fmt.Sprintf("%s-vx", n.name)
+}
+
+// vxlanUnderlayAddress returns the IP address of a cluster member address, or nil if not an IP address.
+func vxlanUnderlayAddress(address string) net.IP {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		host = address
+	}
+
+	return net.ParseIP(strings.Trim(host, "[]"))
+}
+
+// vxlanMembers returns the underlay addresses of the other cluster members, along with the index of this member
+// in the list of members ordered by ID and the number of members. The index and number of members are used to
+// split the DHCP ranges between the members so that their dynamic leases never overlap.
+func (n *bridge) vxlanMembers() ([]net.IP, int, int, error) {
+	var members []db.NodeInfo
+
+	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		members, err = tx.GetNodes(ctx)
+
+		return err
+	})
+	if err != nil {
+		return nil, -1, -1, fmt.Errorf("Failed loading cluster members: %w", err)
+	}
+
+	sort.Slice(members, func(i, j int) bool { return members[i].ID < members[j].ID })
+
+	localMemberID := n.state.DB.Cluster.GetNodeID()
+	peers := []net.IP{}
+	slot := 0
+
+	for i, member := range members {
+		if member.ID == localMemberID {
+			slot = i
+			continue
+		}
+
+		peer := vxlanUnderlayAddress(member.Address)
+		if peer != nil {
+			peers = append(peers, peer)
+		}
+	}
+
+	return peers, slot, len(members), nil
+}
+
+// vxlanSetup creates the VXLAN overlay interface, connects it to the bridge and configures its peers.
+// The gateway MAC address is used to filter the neighbour advertisements of the gateways of the other members.
+func (n *bridge) vxlanSetup(mtu uint32, gatewayMAC net.HardwareAddr, peers []net.IP) error {
+	vxlanID := n.config["vxlan.id"]
+	if vxlanID == "" {
+		vxlanID = fmt.Sprint(n.ID())
+	}
+
+	vxlanPort := n.config["vxlan.port"]
+	if vxlanPort == "" {
+		vxlanPort = "4789"
+	}
+
+	vxlan := &ip.Vxlan{
+		Link:    ip.Link{Name: n.vxlanName()},
+		VxlanID: vxlanID,
+		DevName: n.config["vxlan.interface"],
+		DstPort: vxlanPort,
+	}
+
+	// Use the cluster address as the local underlay address.
+	local := vxlanUnderlayAddress(n.state.LocalConfig.ClusterAddress())
+	if local != nil {
+		vxlan.Local = local.String()
+	}
+
+	err := vxlan.Add()
+	if err != nil {
+		return fmt.Errorf("Failed creating VXLAN interface %q: %w", vxlan.Name, err)
+	}
+
+	// Drop the DHCP traffic and the gateway announcements coming from the other members, as each member serves
+	// its own instances using the same gateway addresses.
+	qdisc := &ip.Qdisc{Dev: vxlan.Name, Handle: "ffff:0", Ingress: true}
+	err = qdisc.Add()
+	if err != nil {
+		return fmt.Errorf("Failed adding ingress qdisc to %q: %w", vxlan.Name, err)
+	}
+
+	for _, filter := range n.vxlanFilters(gatewayMAC) {
+		filter.Dev = vxlan.Name
+		filter.Parent = "ffff:0"
+		filter.Actions = []ip.Action{&ip.ActionDrop{}}
+
+		err = filter.Add()
+		if err != nil {
+			return fmt.Errorf("Failed adding filter to %q: %w", vxlan.Name, err)
+		}
+	}
+
+	// Bridge it and bring up.
+	err = AttachInterface(n.name, vxlan.Name)
+	if err != nil {
+		return err
+	}
+
+	err = vxlan.SetMTU(mtu)
+	if err != nil {
+		return err
+	}
+
+	err = vxlan.SetUp()
+	if err != nil {
+		return err
+	}
+
+	return n.vxlanPeersSync(peers)
+}
+
+// vxlanFilters returns the filters matching the traffic from the other members that must not reach the local
+// instances. This is the DHCP traffic, the ARP packets and IPv6 neighbour advertisements for the gateway addresses,
+// and the IPv6 router advertisements.
+func (n *bridge) vxlanFilters(gatewayMAC net.HardwareAddr) []*ip.FlowerFilter {
+	filters := []*ip.FlowerFilter{
+		{Filter: ip.Filter{Protocol: "ip"}, IPProto: "udp", DstPort: "67"},
+		{Filter: ip.Filter{Protocol: "ip"}, IPProto: "udp", DstPort: "68"},
+		{Filter: ip.Filter{Protocol: "ipv6"}, IPProto: "udp", DstPort: "546"},
+		{Filter: ip.Filter{Protocol: "ipv6"}, IPProto: "udp", DstPort: "547"},
+		{Filter: ip.Filter{Protocol: "ipv6"}, IPProto: "icmpv6", ICMPType: "134"},
+	}
+
+	// The gateways share the same link-local address when they share the same MAC address.
+	if gatewayMAC != nil {
+		filters = append(filters, &ip.FlowerFilter{Filter: ip.Filter{Protocol: "ipv6"}, SrcMAC: gatewayMAC.String(), IPProto: "icmpv6", ICMPType: "136"})
+	}
+
+	gatewayIPv4, _, err := net.ParseCIDR(n.config["ipv4.address"])
+	if err == nil {
+		filters = append(filters, &ip.FlowerFilter{Filter: ip.Filter{Protocol: "arp"}, ARPSenderIP: gatewayIPv4.String()})
+	}
+
+	gatewayIPv6, _, err := net.ParseCIDR(n.config["ipv6.address"])
+	if err == nil {
+		filters = append(filters, &ip.FlowerFilter{Filter: ip.Filter{Protocol: "ipv6"}, SrcIP: gatewayIPv6.String(), IPProto: "icmpv6", ICMPType: "136"})
+	}
+
+	return filters
+}
+
+// vxlanDHCPSlotsDelete forgets the share of the DHCP ranges served by this member.
+func (n *bridge) vxlanDHCPSlotsDelete() {
+	vxlanDHCPSlotsMu.Lock()
+	delete(vxlanDHCPSlots, n.id)
+	vxlanDHCPSlotsMu.Unlock()
+}
+
+// vxlanPeersSync ensures the VXLAN overlay floods broadcast and unknown traffic to the supplied peers only.
+func (n *bridge) vxlanPeersSync(peers []net.IP) error {
+	floodMAC := net.HardwareAddr{0, 0, 0, 0, 0, 0}
+	fdb := &ip.FDB{DevName: n.vxlanName()}
+
+	entries, err := fdb.Show()
+	if err != nil {
+		return fmt.Errorf("Failed listing forwarding database entries of %q: %w", fdb.DevName, err)
+	}
+
+	existing := map[string]struct{}{}
+	for _, entry := range entries {
+		if entry.MAC.String() != floodMAC.String() {
+			continue
+		}
+
+		found := false
+		for _, peer := range peers {
+			if peer.Equal(entry.Dst) {
+				found = true
+				break
+			}
+		}
+
+		if found {
+			existing[entry.Dst.String()] = struct{}{}
+			continue
+		}
+
+		err = entry.Delete()
+		if err != nil {
+			return fmt.Errorf("Failed removing VXLAN peer %q: %w", entry.Dst.String(), err)
+		}
+	}
+
+	for _, peer := range peers {
+		_, found := existing[peer.String()]
+		if found {
+			continue
+		}
+
+		entry := &ip.FDB{DevName: fdb.DevName, MAC: floodMAC, Dst: peer}
+		err = entry.Append()
+		if err != nil {
+			return fmt.Errorf("Failed adding VXLAN peer %q: %w", peer.String(), err)
+		}
+
+		n.logger.Debug("Added VXLAN peer", logger.Ctx{"peer": peer.String()})
+	}
+
+	return nil
+}
+
 func (n *bridge) getTunnels() []string {
 	tunnels := []string{}
 
@@ -2745,7 +3420,7 @@ func (n *bridge) hasIPv4Firewall() bool {
 // hasIPv6Firewall indicates whether the network has IPv6 firewall enabled.
 func (n *bridge) hasIPv6Firewall() bool {
 	// IPv6 firewall is only enabled if there is a bridge ipv6.address and ipv6.firewall enabled.
-	if !shared.ValueInSlice(n.config["ipv6.address"], []string{"", "none"}) && shared.IsTrueOrEmpty(n.config["ipv6.firewall"]) {
+	if !shared.ValueInSlice(n.ipv6Address(), []string{"", "none"}) && shared.IsTrueOrEmpty(n.config["ipv6.firewall"]) {
 		return true
 	}
 
@@ -2814,7 +3489,7 @@ func (n *bridge) DHCPv6Subnet() *net.IPNet {
 		return nil
 	}
 
-	_, subnet, err := net.ParseCIDR(n.config["ipv6.address"])
+	_, subnet, err := net.ParseCIDR(n.ipv6Address())
 	if err != nil {
 		return nil
 	}
@@ -2822,6 +3497,57 @@ func (n *bridge) DHCPv6Subnet() *net.IPNet {
 	return subnet
 }
 
+// InstanceDeviceValidateNATAddresses validates the SNAT addresses of an instance NIC connected to the network.
+// If the instance's project is restricted, the addresses must be within its restricted subnets for the network.
+func (n *bridge) InstanceDeviceValidateNATAddresses(p *api.Project, natAddresses []net.IP) error {
+	projectRestrictedSubnets, err := projectRestrictedSubnets(p, n.name)
+	if err != nil {
+		return err
+	}
+
+	// A restricted project without any restricted subnets isn't allowed any SNAT addresses.
+	if shared.IsTrue(p.Config["restricted"]) && projectRestrictedSubnets == nil {
+		projectRestrictedSubnets = []*net.IPNet{}
+	}
+
+	for _, natAddress := range natAddresses {
+		keyPrefix := "ipv4"
+		if natAddress.To4() == nil {
+			keyPrefix = "ipv6"
+		}
+
+		if shared.IsFalseOrEmpty(n.config[keyPrefix+".nat"]) {
+			return fmt.Errorf("Cannot specify %q when %q is disabled on network %q", keyPrefix+".nat.address", keyPrefix+".nat", n.name)
+		}
+
+		if projectRestrictedSubnets == nil {
+			continue
+		}
+
+		natIPNet := IPToNet(natAddress)
+
+		foundMatch := false
+		for _, projectRestrictedSubnet := range projectRestrictedSubnets {
+			if SubnetContains(projectRestrictedSubnet, &natIPNet) {
+				foundMatch = true
+				break
+			}
+		}
+
+		if !foundMatch {
+			return fmt.Errorf("Project doesn't contain %q in its restricted subnets for network %q", natAddress.String(), n.name)
+		}
+	}
+
+	// Check the addresses aren't used by network forwards, other networks or the NICs of other projects.
+	externalSubnetsInUse, err := n.getExternalSubnetInUse()
+	if err != nil {
+		return err
+	}
+
+	return natAddressesCheckNotInUse(externalSubnetsInUse, n.project, n.name, p.Name, natAddresses)
+}
+
 // forwardConvertToFirewallForward converts forwards into format compatible with the firewall package.
 func (n *bridge) forwardConvertToFirewallForwards(listenAddress net.IP, defaultTargetAddress net.IP, portMaps []*forwardPortMap) []firewallDrivers.AddressForward {
 	var vips []firewallDrivers.AddressForward
@@ -2937,8 +3663,8 @@ func (n *bridge) bridgeNetworkExternalSubnets(bridgeProjectNetworks map[string][
 	return externalSubnets, nil
 }
 
-// bridgedNICExternalRoutes returns a list of external routes currently used by bridged NICs that are connected to
-// networks specified.
+// bridgedNICExternalRoutes returns a list of external routes and SNAT addresses currently used by bridged NICs that
+// are connected to networks specified.
 func (n *bridge) bridgedNICExternalRoutes(bridgeProjectNetworks map[string][]*api.Network) ([]externalSubnetUsage, error) {
 	externalRoutes := make([]externalSubnetUsage, 0)
 
@@ -2986,6 +3712,24 @@ func (n *bridge) bridgedNICExternalRoutes(bridgeProjectNetworks map[string][]*ap
 						})
 					}
 				}
+
+				// Add the NIC's own SNAT addresses.
+				for _, key := range []string{"ipv4.nat.address", "ipv6.nat.address"} {
+					natAddress := net.ParseIP(devConfig[key])
+					if natAddress == nil {
+						continue
+					}
+
+					externalRoutes = append(externalRoutes, externalSubnetUsage{
+						subnet:          IPToNet(natAddress),
+						networkProject:  instNetworkProject,
+						networkName:     devConfig["network"],
+						instanceProject: inst.Project,
+						instanceName:    inst.Name,
+						instanceDevice:  devName,
+						usageType:       subnetUsageInstanceSNAT,
+					})
+				}
 			}
 
 			return nil
@@ -3505,7 +4249,7 @@ func (n *bridge) Leases(projectName string, clientType request.ClientType) ([]ap
 		// If requested project matches network's project then include gateway and downstream uplink IPs.
 		if projectName == n.project || projectName == "" {
 			// Add our own gateway IPs.
-			for _, addr := range []string{n.config["ipv4.address"], n.config["ipv6.address"]} {
+			for _, addr := range []string{n.config["ipv4.address"], n.ipv6Address()} {
 				ip, _, _ := net.ParseCIDR(addr)
 				if ip != nil {
 					leases = append(leases, api.NetworkLease{
@@ -3596,7 +4340,7 @@ func (n *bridge) Leases(projectName string, clientType request.ClientType) ([]ap
 			}
 
 			// Add EUI64 records.
-			_, netIP6, _ := net.ParseCIDR(n.config["ipv6.address"])
+			_, netIP6, _ := net.ParseCIDR(n.ipv6Address())
 			if netIP6 != nil && hwAddr != nil && shared.IsFalseOrEmpty(n.config["ipv6.dhcp.stateful"]) {
 				eui64IP6, err := eui64.ParseMAC(netIP6.IP, hwAddr)
 				if err == nil {
@@ -3727,5 +4471,5 @@ func (n *bridge) Leases(projectName string, clientType request.ClientType) ([]ap
 
 // UsesDNSMasq indicates if network's config indicates if it needs to use dnsmasq.
 func (n *bridge) UsesDNSMasq() bool {
-	return n.config["bridge.mode"] == "fan" || !shared.ValueInSlice(n.config["ipv4.address"], []string{"", "none"}) || !shared.ValueInSlice(n.config["ipv6.address"], []string{"", "none"})
+	return n.config["bridge.mode"] == "fan" || !shared.ValueInSlice(n.config["ipv4.address"], []string{"", "none"}) || !shared.ValueInSlice(n.ipv6Address(), []string{"", "none"})
 }
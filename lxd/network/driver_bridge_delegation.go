@@ -0,0 +1,424 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/canonical/lxd/lxd/dhcpv6"
+	"github.com/canonical/lxd/lxd/dnsmasq/dhcpalloc"
+	firewallDrivers "github.com/canonical/lxd/lxd/firewall/drivers"
+	"github.com/canonical/lxd/lxd/ip"
+	"github.com/canonical/lxd/lxd/subprocess"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// prefixDelegation is a DHCPv6 prefix delegation client shared by the bridges using the same parent interface.
+type prefixDelegation struct {
+	client *dhcpv6.Client
+	prefix *net.IPNet
+
+	// Functions reapplying the configuration of the bridges using the delegated prefix, keyed on bridge name.
+	bridges map[string]func()
+
+	// generation is incremented on every prefix change, so that the reconfigurations for older prefixes that
+	// haven't started yet are skipped.
+	generation uint64
+
+	// refreshMu serializes the reconfigurations of the bridges, so that they are applied in order.
+	refreshMu sync.Mutex
+}
+
+// prefixDelegations holds the active prefix delegations keyed on parent interface.
+var prefixDelegations = map[string]*prefixDelegation{}
+var prefixDelegationsMu sync.Mutex
+
+// delegationFirewall is the firewall configuration last applied to a bridge using a delegated IPv6 prefix.
+type delegationFirewall struct {
+	ipv4Address net.IP
+	opts        firewallDrivers.Opts
+}
+
+// delegationFirewalls holds the firewall configuration of the bridges using a delegated IPv6 prefix, keyed on
+// network ID, so that it can be reapplied for a new prefix without a full setup of the bridge.
+var delegationFirewalls = map[int64]delegationFirewall{}
+var delegationFirewallsMu sync.Mutex
+
+// delegatedPrefix returns the prefix currently delegated on the parent interface (nil if none).
+func delegatedPrefix(parent string) *net.IPNet {
+	prefixDelegationsMu.Lock()
+	defer prefixDelegationsMu.Unlock()
+
+	pd, found := prefixDelegations[parent]
+	if !found {
+		return nil
+	}
+
+	return pd.prefix
+}
+
+// delegationPrefixPath returns the path of the file recording the last prefix delegated to the bridge.
+func delegationPrefixPath(networkName string) string {
+	return shared.VarPath("networks", networkName, "dhcpv6.prefix")
+}
+
+// ipv6Address returns the IPv6 address of the bridge in CIDR notation.
+// When IPv6 prefix delegation is used, this is the first address of the bridge's subnet within the delegated
+// prefix, or an empty string if no prefix is currently delegated.
+func (n *bridge) ipv6Address() string {
+	parent := n.config["ipv6.delegation.parent"]
+	if parent == "" {
+		return n.config["ipv6.address"]
+	}
+
+	prefix := delegatedPrefix(parent)
+	if prefix == nil {
+		return ""
+	}
+
+	index, _ := strconv.ParseUint(n.config["ipv6.delegation.subnet"], 10, 64)
+
+	subnet, err := SubnetSplitV6(prefix, index)
+	if err != nil {
+		n.logger.Warn("Failed getting subnet from delegated IPv6 prefix", logger.Ctx{"err": err})
+		return ""
+	}
+
+	return fmt.Sprintf("%s/64", dhcpalloc.GetIP(subnet, 1).String())
+}
+
+// delegationSetup requests an IPv6 prefix on the parent interface using DHCPv6 prefix delegation.
+// The bridges using the same parent interface share the delegated prefix and are reconfigured every time it changes.
+func (n *bridge) delegationSetup(oldConfig map[string]string) error {
+	parent := n.config["ipv6.delegation.parent"]
+
+	// Leave any prefix delegation no longer used by the bridge.
+	oldParent := oldConfig["ipv6.delegation.parent"]
+	if oldParent != "" && (oldParent != parent || oldConfig["ipv6.delegation.prefix_length"] != n.config["ipv6.delegation.prefix_length"]) {
+		delegationRelease(oldParent, n.name)
+	}
+
+	if parent == "" {
+		return nil
+	}
+
+	if !InterfaceExists(parent) {
+		return fmt.Errorf("Parent interface %q not found", parent)
+	}
+
+	s := n.state
+	projectName := n.project
+	networkName := n.name
+
+	refresh := func() {
+		// Reload the network so that its current configuration is applied.
+		reloaded, err := LoadByName(s, projectName, networkName)
+		if err != nil {
+			logger.Warn("Failed loading network for delegated IPv6 prefix change", logger.Ctx{"project": projectName, "network": networkName, "err": err})
+			return
+		}
+
+		b, ok := reloaded.(*bridge)
+		if !ok || !b.isRunning() {
+			return
+		}
+
+		err = b.delegationApply()
+		if err != nil {
+			b.logger.Warn("Failed applying delegated IPv6 prefix", logger.Ctx{"err": err})
+		}
+	}
+
+	prefixDelegationsMu.Lock()
+	defer prefixDelegationsMu.Unlock()
+
+	pd, found := prefixDelegations[parent]
+	if found {
+		pd.bridges[networkName] = refresh
+		return nil
+	}
+
+	prefixLength, _ := strconv.Atoi(n.config["ipv6.delegation.prefix_length"])
+
+	// Ask for the previously delegated prefix so that it is kept across restarts where possible.
+	var hint *net.IPNet
+	content, err := os.ReadFile(delegationPrefixPath(networkName))
+	if err == nil {
+		_, hint, _ = net.ParseCIDR(strings.TrimSpace(string(content)))
+	}
+
+	var client *dhcpv6.Client
+	client, err = dhcpv6.NewClient(parent, prefixLength, hint, func(lease *dhcpv6.Lease) {
+		delegationUpdate(parent, client, lease)
+	})
+	if err != nil {
+		return err
+	}
+
+	err = client.Start()
+	if err != nil {
+		return err
+	}
+
+	prefixDelegations[parent] = &prefixDelegation{
+		client:  client,
+		bridges: map[string]func(){networkName: refresh},
+	}
+
+	return nil
+}
+
+// delegationClear stops using the delegated IPv6 prefix of the bridge.
+func (n *bridge) delegationClear() {
+	delegationFirewallsMu.Lock()
+	delete(delegationFirewalls, n.id)
+	delegationFirewallsMu.Unlock()
+
+	parent := n.config["ipv6.delegation.parent"]
+	if parent == "" {
+		return
+	}
+
+	delegationRelease(parent, n.name)
+}
+
+// delegationRecordFirewall records the firewall configuration applied to the bridge by setup.
+func (n *bridge) delegationRecordFirewall(ipv4Address net.IP, fwOpts firewallDrivers.Opts) {
+	delegationFirewallsMu.Lock()
+	defer delegationFirewallsMu.Unlock()
+
+	if n.config["ipv6.delegation.parent"] == "" {
+		delete(delegationFirewalls, n.id)
+		return
+	}
+
+	delegationFirewalls[n.id] = delegationFirewall{ipv4Address: ipv4Address, opts: fwOpts}
+}
+
+// delegationApply reconfigures the bridge for the currently delegated IPv6 prefix.
+// When the bridge moves from one delegated prefix to another, only the bridge address, the DNS and DHCP service
+// and the firewall rules are updated. A full setup is done when the bridge gains or loses its IPv6 subnet, or
+// when the subnet is also used by outbound NAT or stateful DHCPv6 ranges.
+func (n *bridge) delegationApply() error {
+	newAddress := n.ipv6Address()
+
+	// Get the address currently configured on the bridge.
+	oldAddress := ""
+	iface, err := net.InterfaceByName(n.name)
+	if err != nil {
+		return err
+	}
+
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return err
+	}
+
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.To4() != nil || !ipNet.IP.IsGlobalUnicast() {
+			continue
+		}
+
+		oldAddress = ipNet.String()
+		break
+	}
+
+	if oldAddress == newAddress {
+		return nil
+	}
+
+	delegationFirewallsMu.Lock()
+	fw, found := delegationFirewalls[n.id]
+	delegationFirewallsMu.Unlock()
+
+	if !found || oldAddress == "" || newAddress == "" || shared.IsTrue(n.config["ipv6.nat"]) || shared.IsTrue(n.config["ipv6.dhcp.stateful"]) {
+		return n.setup(n.config)
+	}
+
+	n.logger.Debug("Applying delegated IPv6 prefix", logger.Ctx{"old": oldAddress, "new": newAddress})
+
+	oldIP, _, err := net.ParseCIDR(oldAddress)
+	if err != nil {
+		return err
+	}
+
+	newIP, _, err := net.ParseCIDR(newAddress)
+	if err != nil {
+		return err
+	}
+
+	// Add the new address before removing the old one so that the bridge keeps its IPv6 configuration.
+	addr := &ip.Addr{
+		DevName: n.name,
+		Address: newAddress,
+		Family:  ip.FamilyV6,
+	}
+
+	err = addr.Add()
+	if err != nil {
+		return err
+	}
+
+	addr = &ip.Addr{
+		DevName: n.name,
+		Address: oldAddress,
+		Family:  ip.FamilyV6,
+	}
+
+	err = addr.Delete()
+	if err != nil {
+		return err
+	}
+
+	// Restart dnsmasq listening on the new address, the router advertisements follow the bridge addresses.
+	pidPath := shared.VarPath("networks", n.name, "dnsmasq.pid")
+	if shared.PathExists(pidPath) {
+		p, err := subprocess.ImportProcess(pidPath)
+		if err != nil {
+			return err
+		}
+
+		args := make([]string, 0, len(p.Args))
+		for _, arg := range p.Args {
+			if arg == fmt.Sprintf("--listen-address=%s", oldIP.String()) {
+				arg = fmt.Sprintf("--listen-address=%s", newIP.String())
+			}
+
+			args = append(args, arg)
+		}
+
+		err = p.Stop()
+		if err != nil && !errors.Is(err, subprocess.ErrNotRunning) {
+			return fmt.Errorf("Failed stopping dnsmasq: %w", err)
+		}
+
+		newProcess, err := subprocess.NewProcess(p.Name, args, "", shared.LogPath(fmt.Sprintf("dnsmasq.%s.log", n.name)))
+		if err != nil {
+			return fmt.Errorf("Failed to create subprocess: %w", err)
+		}
+
+		if p.Apparmor != "" {
+			newProcess.SetApparmor(p.Apparmor)
+		}
+
+		err = newProcess.Start(context.Background())
+		if err != nil {
+			return fmt.Errorf("Failed to run: %s %s: %w", p.Name, strings.Join(args, " "), err)
+		}
+
+		err = newProcess.Save(pidPath)
+		if err != nil {
+			return fmt.Errorf("Failed to save subprocess details: %w", err)
+		}
+	}
+
+	// Reapply the firewall rules as they reference the bridge address.
+	fwClearIPVersions := []uint{}
+
+	if usesIPv4Firewall(n.config) {
+		fwClearIPVersions = append(fwClearIPVersions, 4)
+	}
+
+	if usesIPv6Firewall(n.config) {
+		fwClearIPVersions = append(fwClearIPVersions, 6)
+	}
+
+	if len(fwClearIPVersions) > 0 {
+		err = n.state.Firewall.NetworkClear(n.name, false, fwClearIPVersions)
+		if err != nil {
+			return fmt.Errorf("Failed clearing firewall: %w", err)
+		}
+	}
+
+	return n.setupFirewall(fw.ipv4Address, newIP, fw.opts)
+}
+
+// delegationUpdate records the new prefix delegated on the parent interface and reconfigures the bridges using it.
+func delegationUpdate(parent string, client *dhcpv6.Client, lease *dhcpv6.Lease) {
+	prefixDelegationsMu.Lock()
+
+	pd, found := prefixDelegations[parent]
+	if !found || pd.client != client {
+		prefixDelegationsMu.Unlock()
+		return
+	}
+
+	pd.prefix = nil
+	if lease != nil {
+		pd.prefix = &lease.Prefix
+	}
+
+	pd.generation++
+	generation := pd.generation
+
+	bridges := make(map[string]func(), len(pd.bridges))
+	for networkName, refresh := range pd.bridges {
+		bridges[networkName] = refresh
+	}
+
+	prefixDelegationsMu.Unlock()
+
+	prefix := ""
+	if lease != nil {
+		prefix = lease.Prefix.String()
+	}
+
+	logger.Info("Delegated IPv6 prefix changed", logger.Ctx{"parent": parent, "prefix": prefix})
+
+	// Reconfigure the bridges in the background as the client waits for this function to return.
+	go func() {
+		pd.refreshMu.Lock()
+		defer pd.refreshMu.Unlock()
+
+		// Skip the reconfiguration if the prefix has changed again while waiting, the newer change applies it.
+		prefixDelegationsMu.Lock()
+		stale := pd.generation != generation
+		prefixDelegationsMu.Unlock()
+
+		if stale {
+			return
+		}
+
+		for networkName, refresh := range bridges {
+			if prefix != "" {
+				err := os.WriteFile(delegationPrefixPath(networkName), []byte(prefix+"\n"), 0600)
+				if err != nil {
+					logger.Warn("Failed recording delegated IPv6 prefix", logger.Ctx{"network": networkName, "err": err})
+				}
+			}
+
+			refresh()
+		}
+	}()
+}
+
+// delegationRelease removes the bridge from the users of the prefix delegation on the parent interface.
+// The delegated prefix is released once no bridge uses it anymore.
+func delegationRelease(parent string, networkName string) {
+	prefixDelegationsMu.Lock()
+
+	pd, found := prefixDelegations[parent]
+	if !found {
+		prefixDelegationsMu.Unlock()
+		return
+	}
+
+	delete(pd.bridges, networkName)
+	if len(pd.bridges) > 0 {
+		prefixDelegationsMu.Unlock()
+		return
+	}
+
+	delete(prefixDelegations, parent)
+	prefixDelegationsMu.Unlock()
+
+	// Stop the client outside of the lock as it may be waiting on it to report a prefix change.
+	pd.client.Stop()
+}
@@ -0,0 +1,57 @@
+package network
+
+import (
+	"net"
+
+	"github.com/canonical/lxd/lxd/cluster/request"
+	"github.com/canonical/lxd/lxd/network/flowlog"
+	"github.com/canonical/lxd/shared/api"
+)
+
+// flowLogSetup starts emitting network-flow events for the connections of the instances on the network.
+func (n *bridge) flowLogSetup() {
+	// Instances are identified from their static addresses and DHCP leases.
+	addresses := &flowLogCache[string]{
+		load: func() (map[string]flowLogInstance, error) {
+			leases, err := n.Leases("", request.ClientTypeNormal)
+			if err != nil {
+				return nil, err
+			}
+
+			entries := make(map[string]flowLogInstance, len(leases))
+			for _, lease := range leases {
+				if lease.Project == "" || (lease.Type != "static" && lease.Type != "dynamic") {
+					continue
+				}
+
+				ip := net.ParseIP(lease.Address)
+				if ip == nil {
+					continue
+				}
+
+				entries[ip.String()] = flowLogInstance{project: lease.Project, name: lease.Hostname}
+			}
+
+			return entries, nil
+		},
+	}
+
+	flowLogRegister(n, func(flow *flowlog.Flow) {
+		// Connections in other conntrack zones belong to OVN networks.
+		if flow.Zone != 0 {
+			return
+		}
+
+		// The instance initiated the connection.
+		inst, found := addresses.get(flow.Original.Source.String())
+		if found {
+			_ = n.state.Events.Send(inst.project, api.EventTypeNetworkFlow, flowLogRecord(n.name, inst, flow, true))
+		}
+
+		// The instance received the connection (the reply tuple accounts for forwards and load balancers).
+		inst, found = addresses.get(flow.Reply.Source.String())
+		if found {
+			_ = n.state.Events.Send(inst.project, api.EventTypeNetworkFlow, flowLogRecord(n.name, inst, flow, false))
+		}
+	})
+}
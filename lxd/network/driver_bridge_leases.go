@@ -0,0 +1,241 @@
+package network
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/fsmonitor"
+	"github.com/canonical/lxd/lxd/lifecycle"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// leasesWatchDelay is the time to wait after a change to a leases file before reading it.
+// This allows dnsmasq to finish rewriting the file, as it does so in multiple writes.
+const leasesWatchDelay = 500 * time.Millisecond
+
+// leasesWatchIdentifier is the identifier used for the watches of the leases files.
+const leasesWatchIdentifier = "network-leases"
+
+// dnsmasqLease represents a single entry of a dnsmasq leases file.
+type dnsmasqLease struct {
+	expiry   int64
+	hwaddr   string
+	address  string
+	hostname string
+}
+
+// dnsmasqLeasesRead returns the entries of the dnsmasq leases file keyed on address.
+func dnsmasqLeasesRead(leasesPath string) (map[string]dnsmasqLease, error) {
+	content, err := os.ReadFile(leasesPath)
+	if err != nil {
+		return nil, err
+	}
+
+	leases := map[string]dnsmasqLease{}
+	for _, line := range strings.Split(string(content), "\n") {
+		// Lease lines are in the form "<expiry> <MAC or IAID> <address> <hostname> <client ID or DUID>".
+		fields := strings.Fields(line)
+		if len(fields) < 5 {
+			continue
+		}
+
+		expiry, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		hwaddr := strings.Join(GetMACSlice(fields[1]), ":")
+
+		// DHCPv6 leases only carry the MAC address at the end of the DUID.
+		if len(hwaddr) < 17 && len(fields[4]) >= 17 {
+			hwaddr = fields[4][len(fields[4])-17:]
+		}
+
+		hostname := fields[3]
+		if hostname == "*" {
+			hostname = ""
+		}
+
+		leases[fields[2]] = dnsmasqLease{
+			expiry:   expiry,
+			hwaddr:   hwaddr,
+			address:  fields[2],
+			hostname: hostname,
+		}
+	}
+
+	return leases, nil
+}
+
+// leasesWatcher keeps track of the leases of a network to detect the changes made by dnsmasq.
+type leasesWatcher struct {
+	mu     sync.Mutex
+	timer  *time.Timer
+	leases map[string]dnsmasqLease
+}
+
+// leasesWatchers holds the active leases watchers keyed on leases file path.
+var leasesWatchers = map[string]*leasesWatcher{}
+var leasesWatchersMu sync.Mutex
+
+// leasesWatch starts emitting lifecycle events for the DHCP leases added, renewed and expired on the network.
+func (n *bridge) leasesWatch() {
+	if n.state.NetworkMonitor == nil {
+		return
+	}
+
+	leasesPath := shared.VarPath("networks", n.name, "dnsmasq.leases")
+
+	// Record the existing leases so that only the subsequent changes generate events.
+	leases, err := dnsmasqLeasesRead(leasesPath)
+	if err != nil {
+		leases = map[string]dnsmasqLease{}
+	}
+
+	watcher := &leasesWatcher{leases: leases}
+
+	n.leasesUnwatch()
+
+	leasesWatchersMu.Lock()
+	leasesWatchers[leasesPath] = watcher
+	leasesWatchersMu.Unlock()
+
+	err = n.state.NetworkMonitor.Watch(leasesPath, leasesWatchIdentifier, func(path string, event fsmonitor.Event) bool {
+		watcher.mu.Lock()
+		defer watcher.mu.Unlock()
+
+		// Coalesce the events generated while dnsmasq rewrites the file.
+		if watcher.timer == nil {
+			watcher.timer = time.AfterFunc(leasesWatchDelay, func() { n.leasesRefresh(watcher, leasesPath) })
+		} else {
+			watcher.timer.Reset(leasesWatchDelay)
+		}
+
+		return true
+	})
+	if err != nil {
+		n.logger.Warn("Failed watching DHCP leases", logger.Ctx{"err": err})
+	}
+}
+
+// leasesUnwatch stops emitting lifecycle events for the DHCP leases of the network.
+func (n *bridge) leasesUnwatch() {
+	if n.state.NetworkMonitor == nil {
+		return
+	}
+
+	leasesPath := shared.VarPath("networks", n.name, "dnsmasq.leases")
+
+	_ = n.state.NetworkMonitor.Unwatch(leasesPath, leasesWatchIdentifier)
+
+	leasesWatchersMu.Lock()
+	watcher, found := leasesWatchers[leasesPath]
+	delete(leasesWatchers, leasesPath)
+	leasesWatchersMu.Unlock()
+
+	if found {
+		watcher.mu.Lock()
+		if watcher.timer != nil {
+			watcher.timer.Stop()
+		}
+
+		watcher.mu.Unlock()
+	}
+}
+
+// leasesRefresh compares the leases file with the previously seen leases and emits the matching lifecycle events.
+func (n *bridge) leasesRefresh(watcher *leasesWatcher, leasesPath string) {
+	watcher.mu.Lock()
+	defer watcher.mu.Unlock()
+
+	leases, err := dnsmasqLeasesRead(leasesPath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			n.logger.Warn("Failed reading DHCP leases", logger.Ctx{"err": err})
+			return
+		}
+
+		leases = map[string]dnsmasqLease{}
+	}
+
+	type leaseChange struct {
+		action lifecycle.NetworkAction
+		lease  dnsmasqLease
+	}
+
+	changes := []leaseChange{}
+	for address, lease := range leases {
+		oldLease, found := watcher.leases[address]
+		if found && oldLease.hwaddr == lease.hwaddr {
+			if oldLease.expiry != lease.expiry {
+				changes = append(changes, leaseChange{action: lifecycle.NetworkLeaseRenewed, lease: lease})
+			}
+
+			continue
+		}
+
+		if found {
+			changes = append(changes, leaseChange{action: lifecycle.NetworkLeaseExpired, lease: oldLease})
+		}
+
+		changes = append(changes, leaseChange{action: lifecycle.NetworkLeaseAdded, lease: lease})
+	}
+
+	for address, oldLease := range watcher.leases {
+		_, found := leases[address]
+		if !found {
+			changes = append(changes, leaseChange{action: lifecycle.NetworkLeaseExpired, lease: oldLease})
+		}
+	}
+
+	watcher.leases = leases
+
+	if len(changes) == 0 {
+		return
+	}
+
+	// Find the instances the leases belong to.
+	instances := map[string]db.InstanceArgs{}
+	err = UsedByInstanceDevices(n.state, n.Project(), n.Name(), n.Type(), func(inst db.InstanceArgs, nicName string, nicConfig map[string]string) error {
+		hwaddr := nicConfig["hwaddr"]
+		if hwaddr == "" {
+			hwaddr = inst.Config[fmt.Sprintf("volatile.%s.hwaddr", nicName)]
+		}
+
+		instances[strings.ToLower(hwaddr)] = inst
+
+		return nil
+	})
+	if err != nil {
+		n.logger.Warn("Failed getting instances for DHCP leases", logger.Ctx{"err": err})
+	}
+
+	for _, change := range changes {
+		ctx := map[string]any{
+			"hwaddr":   change.lease.hwaddr,
+			"address":  change.lease.address,
+			"hostname": change.lease.hostname,
+			"location": n.state.ServerName,
+		}
+
+		if change.action != lifecycle.NetworkLeaseExpired {
+			ctx["expiry"] = time.Unix(change.lease.expiry, 0).UTC()
+		}
+
+		// Send the event to the project of the instance if known, as this can differ from the network's project.
+		projectName := n.Project()
+		inst, found := instances[change.lease.hwaddr]
+		if found {
+			ctx["instance"] = inst.Name
+			projectName = inst.Project
+		}
+
+		n.state.Events.SendLifecycle(projectName, change.action.Event(n, nil, ctx))
+	}
+}
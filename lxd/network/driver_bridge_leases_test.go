@@ -0,0 +1,66 @@
+package network
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_dnsmasqLeasesRead(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]dnsmasqLease
+	}{
+		{
+			name:    "IPv4",
+			content: "1700000000 00:16:3E:00:00:01 192.0.2.10 c1 *\n",
+			want: map[string]dnsmasqLease{
+				"192.0.2.10": {expiry: 1700000000, hwaddr: "00:16:3e:00:00:01", address: "192.0.2.10", hostname: "c1"},
+			},
+		},
+		{
+			name:    "IPv4 with client ID and no hostname",
+			content: "1700000000 00:16:3e:00:00:02 192.0.2.11 * 01:00:16:3e:00:00:02\n",
+			want: map[string]dnsmasqLease{
+				"192.0.2.11": {expiry: 1700000000, hwaddr: "00:16:3e:00:00:02", address: "192.0.2.11"},
+			},
+		},
+		{
+			name:    "IPv6",
+			content: "duid 00:01:00:01:2a:bb:cc:dd:00:16:3e:00:00:ff\n1700000000 1234567 fd42::10 c3 00:01:00:01:2a:bb:cc:dd:00:16:3e:00:00:03\n",
+			want: map[string]dnsmasqLease{
+				"fd42::10": {expiry: 1700000000, hwaddr: "00:16:3e:00:00:03", address: "fd42::10", hostname: "c3"},
+			},
+		},
+		{
+			name:    "Malformed lines",
+			content: "\n1700000000 00:16:3e:00:00:04 192.0.2.12\nnever 00:16:3e:00:00:05 192.0.2.13 c5 *\n1700000000 00:16:3e:00:00:06 192.0.2.14 c6 *\n",
+			want: map[string]dnsmasqLease{
+				"192.0.2.14": {expiry: 1700000000, hwaddr: "00:16:3e:00:00:06", address: "192.0.2.14", hostname: "c6"},
+			},
+		},
+		{
+			name:    "Empty",
+			content: "",
+			want:    map[string]dnsmasqLease{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			leasesPath := filepath.Join(t.TempDir(), "dnsmasq.leases")
+			require.NoError(t, os.WriteFile(leasesPath, []byte(tt.content), 0644))
+
+			leases, err := dnsmasqLeasesRead(leasesPath)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, leases)
+		})
+	}
+
+	_, err := dnsmasqLeasesRead(filepath.Join(t.TempDir(), "missing.leases"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
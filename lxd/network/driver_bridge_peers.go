@@ -0,0 +1,289 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	lxd "github.com/canonical/lxd/client"
+	"github.com/canonical/lxd/lxd/cluster"
+	"github.com/canonical/lxd/lxd/cluster/request"
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/revert"
+)
+
+// PeerCreate creates a network peering.
+func (n *bridge) PeerCreate(peer api.NetworkPeersPost, clientType request.ClientType) error {
+	// The peering has already been recorded by the member that received the request, only apply it locally.
+	if clientType == request.ClientTypeNotifier {
+		return n.peerRefreshFirewall()
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	// Default to network's project if target project not specified.
+	if peer.TargetProject == "" {
+		peer.TargetProject = n.Project()
+	}
+
+	// Target network name is required.
+	if peer.TargetNetwork == "" {
+		return api.StatusErrorf(http.StatusBadRequest, "Target network is required")
+	}
+
+	var peers map[int64]*api.NetworkPeer
+
+	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		// Check if there is an existing peer using the same name, or whether there is already a peering (in any
+		// state) to the target network.
+		peers, err = tx.GetNetworkPeers(ctx, n.ID())
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, existingPeer := range peers {
+		if peer.Name == existingPeer.Name {
+			return api.StatusErrorf(http.StatusConflict, "A peer for that name already exists")
+		}
+
+		if peer.TargetProject == existingPeer.TargetProject && peer.TargetNetwork == existingPeer.TargetNetwork {
+			return api.StatusErrorf(http.StatusConflict, "A peer for that target network already exists")
+		}
+	}
+
+	// Peerings rely on the network isolation rules.
+	if n.state.Firewall.String() == "xtables" {
+		return api.StatusErrorf(http.StatusBadRequest, "Network peering is not supported by the xtables firewall driver")
+	}
+
+	// Perform general (create and update) validation.
+	err = n.peerValidate(peer.Name, &peer.NetworkPeerPut)
+	if err != nil {
+		return err
+	}
+
+	var peerID int64
+	var mutualExists bool
+
+	err = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		// Create peer DB record.
+		peerID, mutualExists, err = tx.CreateNetworkPeer(ctx, n.ID(), &peer)
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	revert.Add(func() {
+		_ = n.state.DB.Cluster.DeleteNetworkPeer(n.ID(), peerID)
+	})
+
+	if mutualExists {
+		var peerInfo *api.NetworkPeer
+
+		err = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			// Load peering to get mutual peering info.
+			_, peerInfo, err = tx.GetNetworkPeer(ctx, n.ID(), peer.Name)
+
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		if peerInfo.Status != api.NetworkStatusCreated {
+			return fmt.Errorf("Only peerings in %q state can be setup", api.NetworkStatusCreated)
+		}
+
+		targetNet, err := LoadByName(n.state, peerInfo.TargetProject, peerInfo.TargetNetwork)
+		if err != nil {
+			return fmt.Errorf("Failed loading target network: %w", err)
+		}
+
+		_, ok := targetNet.(*bridge)
+		if !ok {
+			return fmt.Errorf("Target network is not bridge interface type")
+		}
+
+		err = n.peerRefreshFirewall()
+		if err != nil {
+			return err
+		}
+
+		err = n.notifyMembers(func(client lxd.InstanceServer) error {
+			return client.CreateNetworkPeer(n.name, peer)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	revert.Success()
+	return nil
+}
+
+// PeerUpdate updates a network peering.
+func (n *bridge) PeerUpdate(peerName string, req api.NetworkPeerPut) error {
+	return n.peerUpdate(peerName, req)
+}
+
+// PeerDelete deletes a network peering.
+func (n *bridge) PeerDelete(peerName string, clientType request.ClientType) error {
+	// The peering has already been removed by the member that received the request, only apply it locally.
+	if clientType == request.ClientTypeNotifier {
+		return n.peerRefreshFirewall()
+	}
+
+	var peerID int64
+	var peer *api.NetworkPeer
+
+	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		peerID, peer, err = tx.GetNetworkPeer(ctx, n.ID(), peerName)
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	isUsed, err := n.peerIsUsed(peer.Name)
+	if err != nil {
+		return err
+	}
+
+	if isUsed {
+		return fmt.Errorf("Cannot delete a Peer that is in use")
+	}
+
+	err = n.state.DB.Cluster.DeleteNetworkPeer(n.ID(), peerID)
+	if err != nil {
+		return err
+	}
+
+	if peer.Status == api.NetworkStatusCreated {
+		err = n.peerRefreshFirewall()
+		if err != nil {
+			return err
+		}
+
+		err = n.notifyMembers(func(client lxd.InstanceServer) error {
+			return client.DeleteNetworkPeer(n.name, peerName)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// notifyMembers runs the function supplied against the other cluster members, so that they apply the changes locally.
+func (n *bridge) notifyMembers(f func(client lxd.InstanceServer) error) error {
+	if !n.state.ServerClustered {
+		return nil
+	}
+
+	notifier, err := cluster.NewNotifier(n.state, n.state.Endpoints.NetworkCert(), n.state.ServerCert(), cluster.NotifyAll)
+	if err != nil {
+		return err
+	}
+
+	return notifier(func(member db.NodeInfo, client lxd.InstanceServer) error {
+		return f(client.UseProject(n.project))
+	})
+}
+
+// peerSetupFirewall applies the isolation rules of the network, allowing the traffic with its created peerings.
+// Networks are isolated when security.isolated is enabled or as soon as they have a created peering.
+func (n *bridge) peerSetupFirewall() error {
+	var peers map[int64]*api.NetworkPeer
+
+	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		peers, err = tx.GetNetworkPeers(ctx, n.ID())
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("Failed loading network peers: %w", err)
+	}
+
+	// The interface name of a bridge network is the network name.
+	peerNetworks := make([]string, 0, len(peers))
+	for _, peer := range peers {
+		if peer.Status != api.NetworkStatusCreated {
+			continue
+		}
+
+		peerNetworks = append(peerNetworks, peer.TargetNetwork)
+	}
+
+	// A network with peerings is isolated from the bridge networks it isn't peered with.
+	isolated := shared.IsTrue(n.config["security.isolated"]) || len(peerNetworks) > 0
+
+	err = n.state.Firewall.NetworkApplyPeers(n.name, isolated, peerNetworks)
+	if err != nil {
+		return fmt.Errorf("Failed applying network peers: %w", err)
+	}
+
+	return nil
+}
+
+// peerRefreshFirewall reapplies the isolation rules of the network and of the networks peered with it that are
+// running on this member.
+func (n *bridge) peerRefreshFirewall() error {
+	if n.isRunning() {
+		err := n.peerSetupFirewall()
+		if err != nil {
+			return err
+		}
+	}
+
+	var targetNetworks map[string][]string
+
+	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		targetNetworks, err = tx.GetNetworkPeerNetworks(ctx, n.ID())
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("Failed loading peer networks: %w", err)
+	}
+
+	for projectName, networkNames := range targetNetworks {
+		for _, networkName := range networkNames {
+			targetNet, err := LoadByName(n.state, projectName, networkName)
+			if err != nil {
+				n.logger.Warn("Failed loading peer network", logger.Ctx{"project": projectName, "network": networkName, "err": err})
+				continue
+			}
+
+			targetBridge, ok := targetNet.(*bridge)
+			if !ok || !targetBridge.isRunning() {
+				continue
+			}
+
+			err = targetBridge.peerSetupFirewall()
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
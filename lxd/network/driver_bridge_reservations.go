@@ -0,0 +1,294 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	lxd "github.com/canonical/lxd/client"
+	"github.com/canonical/lxd/lxd/cluster/request"
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/dnsmasq"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/revert"
+)
+
+// ReservationCreate creates a DHCP reservation.
+func (n *bridge) ReservationCreate(reservation api.NetworkReservationsPost, clientType request.ClientType) error {
+	// The reservation has already been recorded by the member that received the request, only apply it locally.
+	if clientType == request.ClientTypeNotifier {
+		return n.reservationsUpdateDNSMasq()
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	reservation.Normalise()
+
+	address := net.ParseIP(reservation.Address)
+
+	// Check if there is an existing reservation using the same address.
+	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		_, _, err := tx.GetNetworkReservation(ctx, n.ID(), reservation.Address)
+
+		return err
+	})
+	if err == nil {
+		return api.StatusErrorf(http.StatusConflict, "A reservation for that address already exists")
+	} else if !api.StatusErrorCheck(err, http.StatusNotFound) {
+		return err
+	}
+
+	err = n.reservationValidate(address, &reservation.NetworkReservationPut, []string{n.config["ipv4.address"], n.ipv6Address()}, false)
+	if err != nil {
+		return err
+	}
+
+	err = n.reservationCheckInstanceAddresses(address, reservation.Config["hwaddr"])
+	if err != nil {
+		return err
+	}
+
+	var reservationID int64
+
+	err = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		reservationID, err = tx.CreateNetworkReservation(ctx, n.ID(), &reservation)
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	revert.Add(func() {
+		_ = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			return tx.DeleteNetworkReservation(ctx, n.ID(), reservationID)
+		})
+
+		_ = n.reservationsUpdateDNSMasq()
+	})
+
+	err = n.reservationsUpdateDNSMasq()
+	if err != nil {
+		return err
+	}
+
+	err = n.notifyMembers(func(client lxd.InstanceServer) error {
+		return client.CreateNetworkReservation(n.name, reservation)
+	})
+	if err != nil {
+		return err
+	}
+
+	revert.Success()
+	return nil
+}
+
+// ReservationUpdate updates a DHCP reservation.
+func (n *bridge) ReservationUpdate(address string, req api.NetworkReservationPut, clientType request.ClientType) error {
+	// The reservation has already been recorded by the member that received the request, only apply it locally.
+	if clientType == request.ClientTypeNotifier {
+		return n.reservationsUpdateDNSMasq()
+	}
+
+	var curReservationID int64
+	var curReservation *api.NetworkReservation
+
+	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		curReservationID, curReservation, err = tx.GetNetworkReservation(ctx, n.ID(), address)
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	req.Normalise()
+
+	err = n.reservationValidate(net.ParseIP(curReservation.Address), &req, []string{n.config["ipv4.address"], n.ipv6Address()}, false)
+	if err != nil {
+		return err
+	}
+
+	err = n.reservationCheckInstanceAddresses(net.ParseIP(curReservation.Address), req.Config["hwaddr"])
+	if err != nil {
+		return err
+	}
+
+	curReservationEtagHash, err := util.EtagHash(curReservation.Etag())
+	if err != nil {
+		return err
+	}
+
+	newReservation := api.NetworkReservation{
+		Address: curReservation.Address,
+	}
+
+	newReservation.SetWritable(req)
+
+	newReservationEtagHash, err := util.EtagHash(newReservation.Etag())
+	if err != nil {
+		return err
+	}
+
+	if curReservationEtagHash == newReservationEtagHash {
+		return nil // Nothing has changed.
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	err = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.UpdateNetworkReservation(ctx, n.ID(), curReservationID, newReservation.Writable())
+	})
+	if err != nil {
+		return err
+	}
+
+	revert.Add(func() {
+		_ = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			return tx.UpdateNetworkReservation(ctx, n.ID(), curReservationID, curReservation.Writable())
+		})
+
+		_ = n.reservationsUpdateDNSMasq()
+	})
+
+	err = n.reservationsUpdateDNSMasq()
+	if err != nil {
+		return err
+	}
+
+	err = n.notifyMembers(func(client lxd.InstanceServer) error {
+		return client.UpdateNetworkReservation(n.name, curReservation.Address, newReservation.Writable(), "")
+	})
+	if err != nil {
+		return err
+	}
+
+	revert.Success()
+	return nil
+}
+
+// ReservationDelete deletes a DHCP reservation.
+func (n *bridge) ReservationDelete(address string, clientType request.ClientType) error {
+	// The reservation has already been removed by the member that received the request, only apply it locally.
+	if clientType == request.ClientTypeNotifier {
+		return n.reservationsUpdateDNSMasq()
+	}
+
+	var reservationID int64
+	var reservation *api.NetworkReservation
+
+	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		reservationID, reservation, err = tx.GetNetworkReservation(ctx, n.ID(), address)
+		if err != nil {
+			return err
+		}
+
+		return tx.DeleteNetworkReservation(ctx, n.ID(), reservationID)
+	})
+	if err != nil {
+		return err
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	revert.Add(func() {
+		newReservation := api.NetworkReservationsPost{
+			NetworkReservationPut: reservation.Writable(),
+			Address:               reservation.Address,
+		}
+
+		_ = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			_, _ = tx.CreateNetworkReservation(ctx, n.ID(), &newReservation)
+
+			return nil
+		})
+
+		_ = n.reservationsUpdateDNSMasq()
+	})
+
+	err = n.reservationsUpdateDNSMasq()
+	if err != nil {
+		return err
+	}
+
+	err = n.notifyMembers(func(client lxd.InstanceServer) error {
+		return client.DeleteNetworkReservation(n.name, reservation.Address)
+	})
+	if err != nil {
+		return err
+	}
+
+	revert.Success()
+	return nil
+}
+
+// reservationsUpdateDNSMasq rebuilds the dnsmasq DHCP reservations of the network and reloads dnsmasq.
+// This is a no-op when dnsmasq isn't providing DHCP for the network on this member.
+func (n *bridge) reservationsUpdateDNSMasq() error {
+	reservationsPath := dnsmasq.ReservationsPath(n.name)
+	if !shared.PathExists(reservationsPath) {
+		return nil
+	}
+
+	var reservations map[int64]*api.NetworkReservation
+
+	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		reservations, err = tx.GetNetworkReservations(ctx, n.ID())
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("Failed loading network reservations: %w", err)
+	}
+
+	dnsmasq.ConfigMutex.Lock()
+	defer dnsmasq.ConfigMutex.Unlock()
+
+	// Remove the stale reservations and their DHCP options.
+	for _, path := range []string{reservationsPath, dnsmasq.ReservationOptionsPath(n.name)} {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+
+			return err
+		}
+
+		for _, entry := range entries {
+			err = os.Remove(filepath.Join(path, entry.Name()))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, reservation := range reservations {
+		err = dnsmasq.UpdateReservation(n.name, reservation.Address, reservation.Config["hwaddr"], reservation.Config["client_id"], reservation.Config["hostname"], reservation.Config["lease_time"], DHCPOptionsDNSMasq(reservation.Config))
+		if err != nil {
+			return fmt.Errorf("Failed writing reservation %q: %w", reservation.Address, err)
+		}
+	}
+
+	// Reload dnsmasq so that it picks up the changes (a no-op if it isn't running yet).
+	err = dnsmasq.Kill(n.name, true)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
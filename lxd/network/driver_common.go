@@ -4,12 +4,15 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"net/http"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"unicode"
 
 	"github.com/canonical/lxd/client"
+	"github.com/canonical/lxd/lxd/bgp"
 	"github.com/canonical/lxd/lxd/cluster"
 	"github.com/canonical/lxd/lxd/cluster/request"
 	"github.com/canonical/lxd/lxd/db"
@@ -18,6 +21,7 @@ import (
 	"github.com/canonical/lxd/lxd/project/limits"
 	"github.com/canonical/lxd/lxd/resources"
 	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/util"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/entity"
@@ -33,6 +37,7 @@ type Info struct {
 	AddressForwards    bool // Indicates if driver supports address forwards.
 	LoadBalancers      bool // Indicates if driver supports load balancers.
 	Peering            bool // Indicates if the driver supports network peering.
+	Reservations       bool // Indicates if the driver supports DHCP reservations.
 }
 
 // forwardTarget represents a single port forward target.
@@ -63,6 +68,7 @@ const (
 	subnetUsageNetworkForward
 	subnetUsageNetworkLoadBalancer
 	subnetUsageInstance
+	subnetUsageInstanceSNAT
 	subnetUsageProxy
 	subnetUsageVolatileIP
 )
@@ -531,8 +537,19 @@ func (n *common) HandleHeartbeat(heartbeatData *cluster.APIHeartbeat) error {
 
 // notifyDependentNetworks allows any dependent networks to apply changes to themselves when this network changes.
 func (n *common) notifyDependentNetworks(changedKeys []string) {
+	for _, depNet := range n.dependentNetworks() {
+		err := depNet.handleDependencyChange(n.Name(), n.Config(), changedKeys)
+		if err != nil {
+			n.logger.Error("Failed notifying dependent network", logger.Ctx{"project": depNet.Project(), "dependentNetwork": depNet.Name(), "err": err})
+			continue // Continue to next network.
+		}
+	}
+}
+
+// dependentNetworks returns the networks using this network as their uplink.
+func (n *common) dependentNetworks() []Network {
 	if n.Project() != api.ProjectDefaultName {
-		return // Only networks in the default project can be used as dependent networks.
+		return nil // Only networks in the default project can be used as dependent networks.
 	}
 
 	// Get a list of projects.
@@ -545,9 +562,10 @@ func (n *common) notifyDependentNetworks(changedKeys []string) {
 	})
 	if err != nil {
 		n.logger.Error("Failed to load projects", logger.Ctx{"err": err})
-		return
+		return nil
 	}
 
+	dependentNets := []Network{}
 	for _, projectName := range projectNames {
 		var depNets []string
 
@@ -573,13 +591,11 @@ func (n *common) notifyDependentNetworks(changedKeys []string) {
 				continue // Skip network, as does not depend on our network.
 			}
 
-			err = depNet.handleDependencyChange(n.Name(), n.Config(), changedKeys)
-			if err != nil {
-				n.logger.Error("Failed notifying dependent network", logger.Ctx{"project": projectName, "dependentNetwork": depName, "err": err})
-				continue // Continue to next network.
-			}
+			dependentNets = append(dependentNets, depNet)
 		}
 	}
+
+	return dependentNets
 }
 
 // handleDependencyChange is a placeholder for networks that don't need to handle changes from dependent networks.
@@ -614,6 +630,8 @@ func (n *common) bgpValidationRules(config map[string]string) (map[string]func(v
 			rules[k] = validate.IsAny
 		case "holdtime":
 			rules[k] = validate.Optional(validate.IsInRange(9, 65535))
+		case "import":
+			rules[k] = validate.Optional(validate.IsBool)
 		}
 	}
 
@@ -661,6 +679,9 @@ func (n *common) bgpClear(config map[string]string) error {
 		return err
 	}
 
+	// Remove the imported routes.
+	n.state.BGP.RemoveRouteImporter(n.bgpImportOwner())
+
 	return nil
 }
 
@@ -830,6 +851,145 @@ func (n *common) bgpGetPeers(config map[string]string) []string {
 	return peers
 }
 
+// bgpPeerNames returns the sorted names of the BGP peers defined in the config.
+func bgpPeerNames(config map[string]string) []string {
+	peerNames := []string{}
+	for k := range config {
+		if !strings.HasPrefix(k, "bgp.peers.") {
+			continue
+		}
+
+		fields := strings.Split(k, ".")
+		if len(fields) == 4 && !shared.ValueInSlice(fields[2], peerNames) {
+			peerNames = append(peerNames, fields[2])
+		}
+	}
+
+	sort.Strings(peerNames)
+
+	return peerNames
+}
+
+// bgpImportPeers returns the addresses of the BGP peers whose routes should be imported.
+func bgpImportPeers(config map[string]string) []net.IP {
+	peers := []net.IP{}
+	for _, peerName := range bgpPeerNames(config) {
+		if shared.IsFalseOrEmpty(config[fmt.Sprintf("bgp.peers.%s.import", peerName)]) {
+			continue
+		}
+
+		peerAddress := net.ParseIP(config[fmt.Sprintf("bgp.peers.%s.address", peerName)])
+		if peerAddress != nil {
+			peers = append(peers, peerAddress)
+		}
+	}
+
+	return peers
+}
+
+// bgpImportRouteMetric is the metric of the routes imported from BGP peers into the host routing table.
+// This matches the metric commonly used for eBGP routes so that statically configured routes take precedence.
+const bgpImportRouteMetric = "20"
+
+// bgpImportOwner returns the owner name used for the BGP route importer of the network.
+func (n *common) bgpImportOwner() string {
+	return fmt.Sprintf("network_%d_import", n.id)
+}
+
+// bgpImportRouteHandler returns a BGP route handler that keeps the installed routes in sync with the routes learned
+// from the peers, using the functions supplied to install and remove individual routes.
+func (n *common) bgpImportRouteHandler(addRoute func(route bgp.Route) error, removeRoute func(route bgp.Route) error) bgp.RouteHandler {
+	installed := map[string]bgp.Route{}
+
+	return func(routes []bgp.Route) {
+		wanted := make(map[string]bgp.Route, len(routes))
+		for _, route := range routes {
+			wanted[route.Prefix.String()] = route
+		}
+
+		// Remove the routes that have been withdrawn or whose next hop has changed.
+		for prefix, route := range installed {
+			wantedRoute, found := wanted[prefix]
+			if found && wantedRoute.Nexthop.Equal(route.Nexthop) {
+				continue
+			}
+
+			err := removeRoute(route)
+			if err != nil {
+				n.logger.Warn("Failed removing imported BGP route", logger.Ctx{"prefix": prefix, "nexthop": route.Nexthop.String(), "err": err})
+			}
+
+			delete(installed, prefix)
+		}
+
+		// Install the new routes.
+		for prefix, route := range wanted {
+			_, found := installed[prefix]
+			if found {
+				continue
+			}
+
+			err := addRoute(route)
+			if err != nil {
+				n.logger.Warn("Failed importing BGP route", logger.Ctx{"prefix": prefix, "nexthop": route.Nexthop.String(), "err": err})
+				continue
+			}
+
+			installed[prefix] = route
+		}
+	}
+}
+
+// bgpState returns the session state of the network's BGP peers on the local member.
+// Returns nil if the network has no BGP peers.
+func (n *common) bgpState() (*api.NetworkStateBGP, error) {
+	peerNames := bgpPeerNames(n.config)
+	if len(peerNames) == 0 {
+		return nil, nil
+	}
+
+	statuses, err := n.state.BGP.PeerStatuses()
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting BGP peer status: %w", err)
+	}
+
+	bgpState := &api.NetworkStateBGP{
+		Peers: make([]api.NetworkStateBGPPeer, 0, len(peerNames)),
+	}
+
+	for _, peerName := range peerNames {
+		peerAddress := net.ParseIP(n.config[fmt.Sprintf("bgp.peers.%s.address", peerName)])
+		if peerAddress == nil {
+			continue
+		}
+
+		peerState := api.NetworkStateBGPPeer{
+			Name:    peerName,
+			Address: peerAddress.String(),
+			State:   "unknown",
+			Import:  shared.IsTrue(n.config[fmt.Sprintf("bgp.peers.%s.import", peerName)]),
+		}
+
+		for _, status := range statuses {
+			if !status.Address.Equal(peerAddress) {
+				continue
+			}
+
+			peerState.ASN = status.ASN
+			peerState.State = status.State
+			peerState.Uptime = uint64(status.Uptime.Seconds())
+			peerState.PrefixesReceived = status.PrefixesReceived
+			peerState.PrefixesAccepted = status.PrefixesAccepted
+			peerState.PrefixesAdvertised = status.PrefixesAdvertised
+			break
+		}
+
+		bgpState.Peers = append(bgpState.Peers, peerState)
+	}
+
+	return bgpState, nil
+}
+
 // projectUplinkIPQuotaAvailable checks if a project has quota available to assign new uplink IPs in a certain network.
 func (n *common) projectUplinkIPQuotaAvailable(ctx context.Context, tx *db.ClusterTx, p *api.Project, uplinkName string) (ipv4QuotaAvailable bool, ipv6QuotaAvailable bool, err error) {
 	rawIPV4Quota, hasIPV4Quota := p.Config["limits.networks.uplink_ips.ipv4."+uplinkName]
@@ -1422,7 +1582,7 @@ func (n *common) Leases(projectName string, clientType request.ClientType) ([]ap
 }
 
 // PeerCreate returns ErrNotImplemented for drivers that do not support forwards.
-func (n *common) PeerCreate(forward api.NetworkPeersPost) error {
+func (n *common) PeerCreate(peer api.NetworkPeersPost, clientType request.ClientType) error {
 	return ErrNotImplemented
 }
 
@@ -1432,7 +1592,7 @@ func (n *common) PeerUpdate(peerName string, newPeer api.NetworkPeerPut) error {
 }
 
 // PeerDelete returns ErrNotImplemented for drivers that do not support forwards.
-func (n *common) PeerDelete(peerName string) error {
+func (n *common) PeerDelete(peerName string, clientType request.ClientType) error {
 	return ErrNotImplemented
 }
 
@@ -1464,6 +1624,57 @@ func (n *common) peerValidate(peerName string, peer *api.NetworkPeerPut) error {
 	return nil
 }
 
+// peerUpdate updates the network peering record.
+func (n *common) peerUpdate(peerName string, req api.NetworkPeerPut) error {
+	var curPeerID int64
+	var curPeer *api.NetworkPeer
+
+	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		curPeerID, curPeer, err = tx.GetNetworkPeer(ctx, n.ID(), peerName)
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	err = n.peerValidate(peerName, &req)
+	if err != nil {
+		return err
+	}
+
+	curPeerEtagHash, err := util.EtagHash(curPeer.Etag())
+	if err != nil {
+		return err
+	}
+
+	newPeer := api.NetworkPeer{
+		Name: curPeer.Name,
+	}
+
+	newPeer.SetWritable(req)
+
+	newPeerEtagHash, err := util.EtagHash(newPeer.Etag())
+	if err != nil {
+		return err
+	}
+
+	if curPeerEtagHash == newPeerEtagHash {
+		return nil // Nothing has changed.
+	}
+
+	err = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.UpdateNetworkPeer(ctx, n.ID(), curPeerID, newPeer.Writable())
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // PeerUsedBy returns a list of API endpoints referencing this peer.
 func (n *common) PeerUsedBy(peerName string) ([]string, error) {
 	return n.peerUsedBy(peerName, false)
@@ -1552,9 +1763,254 @@ func (n *common) peerUsedBy(peerName string, firstOnly bool) ([]string, error) {
 	return usedBy, nil
 }
 
+// ReservationCreate returns ErrNotImplemented for drivers that do not support DHCP reservations.
+func (n *common) ReservationCreate(reservation api.NetworkReservationsPost, clientType request.ClientType) error {
+	return ErrNotImplemented
+}
+
+// ReservationUpdate returns ErrNotImplemented for drivers that do not support DHCP reservations.
+func (n *common) ReservationUpdate(address string, newReservation api.NetworkReservationPut, clientType request.ClientType) error {
+	return ErrNotImplemented
+}
+
+// ReservationDelete returns ErrNotImplemented for drivers that do not support DHCP reservations.
+func (n *common) ReservationDelete(address string, clientType request.ClientType) error {
+	return ErrNotImplemented
+}
+
+// reservationValidate validates the DHCP reservation request.
+// The address must be within one of the network's subnets (in CIDR format) without being the network's own address.
+// If requireHWAddr is true then the host must be identified by its MAC address rather than by a client identifier.
+func (n *common) reservationValidate(address net.IP, req *api.NetworkReservationPut, networkAddresses []string, requireHWAddr bool) error {
+	if address == nil {
+		return api.StatusErrorf(http.StatusBadRequest, "Invalid reservation address")
+	}
+
+	inSubnet := false
+	for _, networkAddress := range networkAddresses {
+		if networkAddress == "" || networkAddress == "none" {
+			continue
+		}
+
+		gateway, subnet, err := net.ParseCIDR(networkAddress)
+		if err != nil {
+			continue
+		}
+
+		if gateway.Equal(address) {
+			return api.StatusErrorf(http.StatusBadRequest, "Reservation address cannot be the network address")
+		}
+
+		if SubnetContainsIP(subnet, address) {
+			inSubnet = true
+			break
+		}
+	}
+
+	if !inSubnet {
+		return api.StatusErrorf(http.StatusBadRequest, "Reservation address %q is not within the network subnet", address.String())
+	}
+
+	rules := map[string]func(value string) error{
+		// lxdmeta:generate(entities=network-reservation; group=reservation-conf; key=hwaddr)
+		// Either this option or `client_id` must be set.
+		// ---
+		//  type: string
+		//  shortdesc: MAC address of the host
+		"hwaddr": validate.Optional(validate.IsNetworkMAC),
+		// lxdmeta:generate(entities=network-reservation; group=reservation-conf; key=client_id)
+		// The DHCP client identifier as colon-separated hexadecimal octets.
+		// Hosts can only be identified by their client identifier on bridge networks.
+		// ---
+		//  type: string
+		//  shortdesc: DHCP client identifier of the host
+		"client_id": validate.Optional(reservationValidateClientID),
+		// lxdmeta:generate(entities=network-reservation; group=reservation-conf; key=hostname)
+		// The host name is registered in the network's DNS when `dns.mode` is `managed`.
+		// ---
+		//  type: string
+		//  shortdesc: Host name to assign to the host
+		"hostname": validate.Optional(validate.IsHostname),
+		// lxdmeta:generate(entities=network-reservation; group=reservation-conf; key=lease_time)
+		// Specify the time as a number followed by `s`, `m`, `h`, `d` or `w`, or `infinite`.
+		// Only used on bridge networks.
+		// ---
+		//  type: string
+		//  defaultdesc: `ipv4.dhcp.expiry` or `ipv6.dhcp.expiry` of the network
+		//  shortdesc: Lease time for the reserved address
+		"lease_time": validate.Optional(reservationValidateLeaseTime),
+		// lxdmeta:generate(entities=network-reservation; group=reservation-conf; key=ipv4.dhcp.boot.filename)
+		// This overrides the network's boot file name for the host.
+		// Only applies to IPv4 reservations.
+		// ---
+		//  type: string
+		//  shortdesc: Boot file name sent to the host
+		"ipv4.dhcp.boot.filename": validate.Optional(DHCPBootFilenameValidate),
+		// lxdmeta:generate(entities=network-reservation; group=reservation-conf; key=ipv4.dhcp.boot.next_server)
+		// This overrides the network's next server address for the host.
+		// Only applies to IPv4 reservations.
+		// ---
+		//  type: string
+		//  shortdesc: Next server address sent to the host
+		"ipv4.dhcp.boot.next_server": validate.Optional(validate.IsNetworkAddressV4),
+	}
+
+	// lxdmeta:generate(entities=network-reservation; group=reservation-conf; key=ipv4.dhcp.option.NAME)
+	// `NAME` is either a supported option name or a numeric option code.
+	// This overrides the network's option for the host and only applies to IPv4 reservations.
+	// See {ref}`network-dhcp-options` for more information.
+	// ---
+	//  type: string
+	//  shortdesc: Additional DHCPv4 option sent to the host
+
+	// lxdmeta:generate(entities=network-reservation; group=reservation-conf; key=ipv6.dhcp.option.NAME)
+	// `NAME` is either a supported option name or a numeric option code.
+	// This overrides the network's option for the host and only applies to IPv6 reservations.
+	// See {ref}`network-dhcp-options` for more information.
+	// ---
+	//  type: string
+	//  shortdesc: Additional DHCPv6 option sent to the host
+	for k, v := range DHCPOptionsValidationRules(req.Config, n.netType == "ovn") {
+		rules[k] = v
+	}
+
+	for k, v := range req.Config {
+		// User keys are not validated.
+		if shared.IsUserConfig(k) {
+			continue
+		}
+
+		validator, found := rules[k]
+		if !found {
+			return api.StatusErrorf(http.StatusBadRequest, "Invalid option %q", k)
+		}
+
+		err := validator(v)
+		if err != nil {
+			return api.StatusErrorf(http.StatusBadRequest, "Invalid value for option %q: %v", k, err)
+		}
+	}
+
+	if req.Config["hwaddr"] == "" && req.Config["client_id"] == "" {
+		return api.StatusErrorf(http.StatusBadRequest, `Either "hwaddr" or "client_id" must be set`)
+	}
+
+	if req.Config["hwaddr"] != "" && req.Config["client_id"] != "" {
+		return api.StatusErrorf(http.StatusBadRequest, `Only one of "hwaddr" or "client_id" can be set`)
+	}
+
+	if requireHWAddr && req.Config["hwaddr"] == "" {
+		return api.StatusErrorf(http.StatusBadRequest, `The "hwaddr" option is required for this network type`)
+	}
+
+	// DHCP options are only sent to the host over the IP protocol of the reserved address.
+	for k := range dhcpOptionsConfig(req.Config, 6) {
+		if address.To4() != nil {
+			return api.StatusErrorf(http.StatusBadRequest, "Option %q cannot be set on an IPv4 reservation", k)
+		}
+	}
+
+	for k := range dhcpOptionsConfig(req.Config, 4) {
+		if address.To4() == nil {
+			return api.StatusErrorf(http.StatusBadRequest, "Option %q cannot be set on an IPv6 reservation", k)
+		}
+	}
+
+	// Check the host doesn't already have a reservation for the same IP protocol.
+	var reservations map[int64]*api.NetworkReservation
+
+	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		reservations, err = tx.GetNetworkReservations(ctx, n.id)
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("Failed loading network reservations: %w", err)
+	}
+
+	for _, reservation := range reservations {
+		existingAddress := net.ParseIP(reservation.Address)
+		if existingAddress == nil || existingAddress.Equal(address) || (existingAddress.To4() == nil) != (address.To4() == nil) {
+			continue
+		}
+
+		if req.Config["hwaddr"] != "" && reservation.Config["hwaddr"] == req.Config["hwaddr"] {
+			return api.StatusErrorf(http.StatusConflict, "A reservation for MAC address %q already exists", req.Config["hwaddr"])
+		}
+
+		if req.Config["client_id"] != "" && reservation.Config["client_id"] == req.Config["client_id"] {
+			return api.StatusErrorf(http.StatusConflict, "A reservation for client identifier %q already exists", req.Config["client_id"])
+		}
+	}
+
+	return nil
+}
+
+// reservationCheckInstanceAddresses checks the reserved address isn't statically assigned to an instance NIC
+// connected to the network, unless the NIC has the reserved MAC address.
+func (n *common) reservationCheckInstanceAddresses(address net.IP, hwaddr string) error {
+	return UsedByInstanceDevices(n.state, n.Project(), n.Name(), n.Type(), func(inst db.InstanceArgs, nicName string, nicConfig map[string]string) error {
+		for _, key := range []string{"ipv4.address", "ipv6.address"} {
+			ip := net.ParseIP(nicConfig[key])
+			if ip != nil && ip.Equal(address) && (hwaddr == "" || !strings.EqualFold(nicConfig["hwaddr"], hwaddr)) {
+				return api.StatusErrorf(http.StatusConflict, "Address is already in use by NIC %q of instance %q", nicName, inst.Name)
+			}
+		}
+
+		return nil
+	})
+}
+
+// reservationValidateClientID validates a DHCP client identifier made of colon-separated hexadecimal octets.
+func reservationValidateClientID(value string) error {
+	for _, octet := range strings.Split(value, ":") {
+		if len(octet) != 2 {
+			return fmt.Errorf("Invalid client identifier %q", value)
+		}
+
+		_, err := strconv.ParseUint(octet, 16, 8)
+		if err != nil {
+			return fmt.Errorf("Invalid client identifier %q", value)
+		}
+	}
+
+	return nil
+}
+
+// reservationValidateLeaseTime validates a dnsmasq lease time.
+func reservationValidateLeaseTime(value string) error {
+	if value == "infinite" {
+		return nil
+	}
+
+	number := strings.TrimRight(value, "smhdw")
+	if len(value)-len(number) > 1 {
+		return fmt.Errorf("Invalid lease time %q", value)
+	}
+
+	_, err := strconv.ParseUint(number, 10, 32)
+	if err != nil {
+		return fmt.Errorf("Invalid lease time %q", value)
+	}
+
+	return nil
+}
+
 // State returns the api.NetworkState for the network.
 func (n *common) State() (*api.NetworkState, error) {
-	return resources.GetNetworkState(n.name)
+	state, err := resources.GetNetworkState(n.name)
+	if err != nil {
+		return nil, err
+	}
+
+	state.BGP, err = n.bgpState()
+	if err != nil {
+		return nil, err
+	}
+
+	return state, nil
 }
 
 func (n *common) setUnavailable() {
@@ -12,12 +12,12 @@ import (
 	"slices"
 	"sort"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/mdlayher/netx/eui64"
 
 	"github.com/canonical/lxd/client"
+	"github.com/canonical/lxd/lxd/bgp"
 	"github.com/canonical/lxd/lxd/cluster"
 	"github.com/canonical/lxd/lxd/cluster/request"
 	"github.com/canonical/lxd/lxd/db"
@@ -28,6 +28,7 @@ import (
 	"github.com/canonical/lxd/lxd/ip"
 	"github.com/canonical/lxd/lxd/locking"
 	"github.com/canonical/lxd/lxd/network/acl"
+	"github.com/canonical/lxd/lxd/network/flowlog"
 	"github.com/canonical/lxd/lxd/network/openvswitch"
 	"github.com/canonical/lxd/lxd/project"
 	"github.com/canonical/lxd/lxd/util"
@@ -102,6 +103,7 @@ func (n *ovn) Info() Info {
 	info.AddressForwards = true
 	info.LoadBalancers = true
 	info.Peering = true
+	info.Reservations = true
 
 	return info
 }
@@ -183,39 +185,6 @@ func (n *ovn) uplinkRoutes(uplink *api.Network) ([]*net.IPNet, error) {
 	return uplinkRoutes, nil
 }
 
-// projectRestrictedSubnets parses the restrict.networks.subnets project setting and returns slice of *net.IPNet.
-// Returns nil slice if no project restrictions, or empty slice if no allowed subnets.
-func (n *ovn) projectRestrictedSubnets(p *api.Project, uplinkNetworkName string) ([]*net.IPNet, error) {
-	// Parse project's restricted subnets.
-	var projectRestrictedSubnets []*net.IPNet // Nil value indicates not restricted.
-	if shared.IsTrue(p.Config["restricted"]) && p.Config["restricted.networks.subnets"] != "" {
-		projectRestrictedSubnets = []*net.IPNet{} // Empty slice indicates no allowed subnets.
-
-		for _, subnetRaw := range shared.SplitNTrimSpace(p.Config["restricted.networks.subnets"], ",", -1, false) {
-			subnetParts := strings.SplitN(subnetRaw, ":", 2)
-			if len(subnetParts) != 2 {
-				return nil, fmt.Errorf(`Project subnet %q invalid, must be in the format of "<uplink network>:<subnet>"`, subnetRaw)
-			}
-
-			subnetUplinkName := subnetParts[0]
-			subnetStr := subnetParts[1]
-
-			if subnetUplinkName != uplinkNetworkName {
-				continue // Only include subnets for our uplink.
-			}
-
-			_, restrictedSubnet, err := net.ParseCIDR(subnetStr)
-			if err != nil {
-				return nil, err
-			}
-
-			projectRestrictedSubnets = append(projectRestrictedSubnets, restrictedSubnet)
-		}
-	}
-
-	return projectRestrictedSubnets, nil
-}
-
 func (n *ovn) randomExternalAddress(ctx context.Context, ipVersion int, uplinkRoutes []*net.IPNet, projectRestrictedSubnets []*net.IPNet, validator func(*net.IPNet) (bool, error)) (net.IP, error) {
 	// Ensure a sensible deadline is set.
 	_, hasDeadline := ctx.Deadline()
@@ -424,6 +393,20 @@ func (n *ovn) Validate(config map[string]string) error {
 		//  defaultdesc: `true`
 		//  shortdesc: Whether to allocate IPv4 addresses using DHCP
 		"ipv4.dhcp": validate.Optional(validate.IsBool),
+		// lxdmeta:generate(entities=network-ovn; group=network-conf; key=ipv4.dhcp.boot.filename)
+		// This is used to boot instances over the network (PXE).
+		// ---
+		//  type: string
+		//  condition: IPv4 DHCP
+		//  shortdesc: Boot file name sent to DHCP clients
+		"ipv4.dhcp.boot.filename": validate.Optional(DHCPBootFilenameValidate),
+		// lxdmeta:generate(entities=network-ovn; group=network-conf; key=ipv4.dhcp.boot.next_server)
+		// This is the address of the TFTP server that clients load the boot file from.
+		// ---
+		//  type: string
+		//  condition: IPv4 DHCP
+		//  shortdesc: Next server address sent to DHCP clients
+		"ipv4.dhcp.boot.next_server": validate.Optional(validate.IsNetworkAddressV4),
 		// lxdmeta:generate(entities=network-ovn; group=network-conf; key=ipv6.address)
 		// Use CIDR notation.
 		//
@@ -572,6 +555,15 @@ func (n *ovn) Validate(config map[string]string) error {
 		//  defaultdesc: `false`
 		//  shortdesc: Whether to log egress traffic that doesn’t match any ACL rule
 		"security.acls.default.egress.logged": validate.Optional(validate.IsBool),
+		// lxdmeta:generate(entities=network-ovn; group=network-conf; key=security.flows.logged)
+		// When enabled, the connections opened and closed by the instances on the network are emitted as `network-flow` events.
+		//
+		// See {ref}`network-flow-log`.
+		// ---
+		//  type: bool
+		//  defaultdesc: `false`
+		//  shortdesc: Whether to log the connections of the instances
+		"security.flows.logged": validate.Optional(validate.IsBool),
 
 		// lxdmeta:generate(entities=network-ovn; group=network-conf; key=user.*)
 		//
@@ -584,6 +576,26 @@ func (n *ovn) Validate(config map[string]string) error {
 		ovnVolatileUplinkIPv6: validate.Optional(validate.IsNetworkAddressV6),
 	}
 
+	// Add the DHCP option validation rules.
+	// lxdmeta:generate(entities=network-ovn; group=network-conf; key=ipv4.dhcp.option.NAME)
+	// `NAME` is a supported option name.
+	// See {ref}`network-dhcp-options` for more information.
+	// ---
+	//  type: string
+	//  condition: IPv4 DHCP
+	//  shortdesc: Additional DHCPv4 option sent to clients
+
+	// lxdmeta:generate(entities=network-ovn; group=network-conf; key=ipv6.dhcp.option.NAME)
+	// `NAME` is a supported option name.
+	// See {ref}`network-dhcp-options` for more information.
+	// ---
+	//  type: string
+	//  condition: IPv6 DHCP
+	//  shortdesc: Additional DHCPv6 option sent to clients
+	for k, v := range DHCPOptionsValidationRules(config, true) {
+		rules[k] = v
+	}
+
 	err := n.validate(config, rules)
 	if err != nil {
 		return err
@@ -696,7 +708,7 @@ func (n *ovn) Validate(config map[string]string) error {
 	}
 
 	// Get project restricted routes.
-	projectRestrictedSubnets, err := n.projectRestrictedSubnets(p, uplink.Name)
+	projectRestrictedSubnets, err := projectRestrictedSubnets(p, uplink.Name)
 	if err != nil {
 		return err
 	}
@@ -1162,7 +1174,7 @@ func (n *ovn) setupUplinkPort(routerMAC net.HardwareAddr) (*ovnUplinkVars, error
 	switch uplinkNet.Type() {
 	case "bridge":
 		return n.setupUplinkPortBridge(uplinkNet, routerMAC)
-	case "physical":
+	case "physical", "wireguard":
 		return n.setupUplinkPortPhysical(uplinkNet, routerMAC)
 	}
 
@@ -1212,13 +1224,14 @@ func (n *ovn) allocateUplinkPortIPs(uplinkNet Network, routerMAC net.HardwareAdd
 	v.extSwitchProviderName = uplinkNet.Name()
 
 	// Detect uplink gateway setting.
+	// The addresses of WireGuard uplinks are those of the tunnel, their gateway is always set separately.
 	uplinkIPv4CIDR := uplinkNetConf["ipv4.address"]
-	if uplinkIPv4CIDR == "" {
+	if uplinkIPv4CIDR == "" || uplinkNet.Type() == "wireguard" {
 		uplinkIPv4CIDR = uplinkNetConf["ipv4.gateway"]
 	}
 
 	uplinkIPv6CIDR := uplinkNetConf["ipv6.address"]
-	if uplinkIPv6CIDR == "" {
+	if uplinkIPv6CIDR == "" || uplinkNet.Type() == "wireguard" {
 		uplinkIPv6CIDR = uplinkNetConf["ipv6.gateway"]
 	}
 
@@ -1500,6 +1513,8 @@ func (n *ovn) startUplinkPort() error {
 		return n.startUplinkPortBridge(uplinkNet)
 	case "physical":
 		return n.startUplinkPortPhysical(uplinkNet)
+	case "wireguard":
+		return n.startUplinkPortWireGuard(uplinkNet)
 	}
 
 	return fmt.Errorf("Failed starting uplink port, network type %q unsupported as OVN uplink", uplinkNet.Type())
@@ -1531,6 +1546,16 @@ func (n *ovn) startUplinkPortBridge(uplinkNet Network) error {
 	return n.startUplinkPortBridgeOVS(uplinkNet, uplinkNet.Name())
 }
 
+// startUplinkPortWireGuard connects the OVN logical router to the local uplink bridge of a WireGuard network.
+func (n *ovn) startUplinkPortWireGuard(uplinkNet Network) error {
+	bridgeDevice := wireguardUplinkBridgeName(uplinkNet.ID())
+	if !InterfaceExists(bridgeDevice) {
+		return fmt.Errorf("Uplink network %q has no gateway configured", uplinkNet.Name())
+	}
+
+	return n.startUplinkPortBridgeNative(uplinkNet, bridgeDevice)
+}
+
 // startUplinkPortBridgeNative connects an OVN logical router to an uplink native bridge.
 func (n *ovn) startUplinkPortBridgeNative(uplinkNet Network, bridgeDevice string) error {
 	// Do this after gaining lock so that on failure we revert before release locking.
@@ -1562,12 +1587,16 @@ func (n *ovn) startUplinkPortBridgeNative(uplinkNet Network, bridgeDevice string
 	// Ensure that the veth interfaces inherit the uplink bridge's MTU (which the OVS bridge also inherits).
 	uplinkNetConfig := uplinkNet.Config()
 
-	// Uplink may have type "bridge" or "physical"
+	// Uplink may have type "bridge", "physical" or "wireguard"
 	uplinkNetMTU, hasBridgeMTU := uplinkNetConfig["bridge.mtu"]
 	if !hasBridgeMTU {
 		uplinkNetMTU = uplinkNetConfig["mtu"]
 	}
 
+	if uplinkNetMTU == "" && uplinkNet.Type() == "wireguard" {
+		uplinkNetMTU = strconv.Itoa(wireguardDefaultMTU)
+	}
+
 	if uplinkNetMTU != "" {
 		mtu, err := strconv.ParseUint(uplinkNetMTU, 10, 32)
 		if err != nil {
@@ -1854,6 +1883,8 @@ func (n *ovn) deleteUplinkPort() error {
 			return n.deleteUplinkPortBridge(uplinkNet)
 		case "physical":
 			return n.deleteUplinkPortPhysical(uplinkNet)
+		case "wireguard":
+			return n.deleteUplinkPortBridgeNative(uplinkNet)
 		}
 
 		return fmt.Errorf("Failed deleting uplink port, network type %q unsupported as OVN uplink", uplinkNet.Type())
@@ -2133,6 +2164,25 @@ func (n *ovn) getDHCPv4Reservations() ([]shared.IPRange, error) {
 		return nil, err
 	}
 
+	// Add the addresses of the network's DHCP reservations.
+	var reservationAddresses map[int64]string
+
+	err = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		reservationAddresses, err = tx.GetNetworkReservationAddresses(ctx, n.ID())
+
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading network reservations: %w", err)
+	}
+
+	for _, address := range reservationAddresses {
+		ip := net.ParseIP(address)
+		if ip != nil && ip.To4() != nil {
+			dhcpReserveIPv4s = append(dhcpReserveIPv4s, shared.IPRange{Start: ip})
+		}
+	}
+
 	return dhcpReserveIPv4s, nil
 }
 
@@ -2362,10 +2412,17 @@ func (n *ovn) setup(update bool) error {
 			return fmt.Errorf("Failed linking external switch provider port to external provider network: %w", err)
 		}
 
-		// Remove any existing SNAT rules on update. As currently these are only defined from the network
-		// config rather than from any instance NIC config, so we can re-create the active config below.
+		// Remove any existing network SNAT rules on update, so we can re-create the active config below.
+		// The SNAT rules of instance NICs using their own SNAT addresses are left in place.
 		if update {
-			err = client.LogicalRouterSNATDeleteAll(n.getRouterName())
+			removeSNATNets := make([]*net.IPNet, 0, 2)
+			for _, routerIntPortIPNet := range []*net.IPNet{routerIntPortIPv4Net, routerIntPortIPv6Net} {
+				if routerIntPortIPNet != nil {
+					removeSNATNets = append(removeSNATNets, routerIntPortIPNet)
+				}
+			}
+
+			err = client.LogicalRouterSNATDelete(n.getRouterName(), removeSNATNets...)
 			if err != nil {
 				return fmt.Errorf("Failed removing existing router SNAT rules: %w", err)
 			}
@@ -2564,6 +2621,7 @@ func (n *ovn) setup(update bool) error {
 	var dhcpv4UUID, dhcpv6UUID openvswitch.OVNDHCPOptionsUUID
 	dhcpV4Subnet := n.DHCPv4Subnet()
 	dhcpV6Subnet := n.DHCPv6Subnet()
+	dhcpV4Options, dhcpV6Options := DHCPOptionsOVN(n.config)
 
 	if update {
 		// Find first existing DHCP options set for IPv4 and IPv6 and update them instead of adding sets.
@@ -2576,6 +2634,11 @@ func (n *ovn) setup(update bool) error {
 		var deleteDHCPRecords []openvswitch.OVNDHCPOptionsUUID
 
 		for _, existingOpt := range existingOpts {
+			// Skip instance NIC specific option sets, these are refreshed from the switch ones.
+			if existingOpt.Port != "" {
+				continue
+			}
+
 			if existingOpt.CIDR.IP.To4() == nil {
 				if dhcpv6UUID == "" {
 					dhcpv6UUID = existingOpt.UUID
@@ -2620,6 +2683,7 @@ func (n *ovn) setup(update bool) error {
 			LeaseTime:          time.Duration(time.Hour * 1),
 			MTU:                bridgeMTU,
 			Netmask:            dhcpV4Netmask,
+			Options:            dhcpV4Options,
 		})
 		if err != nil {
 			return fmt.Errorf("Failed adding DHCPv4 settings for internal switch: %w", err)
@@ -2632,6 +2696,7 @@ func (n *ovn) setup(update bool) error {
 			ServerID:           routerMAC,
 			RecursiveDNSServer: uplinkNet.dnsIPv6,
 			DNSSearchList:      n.getDNSSearchList(),
+			Options:            dhcpV6Options,
 		})
 		if err != nil {
 			return fmt.Errorf("Failed adding DHCPv6 settings for internal switch: %w", err)
@@ -3155,6 +3220,25 @@ func (n *ovn) Start() error {
 		return err
 	}
 
+	// Import the routes learned from the uplink's BGP peers.
+	if n.config["network"] != "" {
+		uplinkNet, err := LoadByName(n.state, api.ProjectDefaultName, n.config["network"])
+		if err != nil {
+			return fmt.Errorf("Failed loading uplink network %q: %w", n.config["network"], err)
+		}
+
+		if uplinkNet.Type() == "physical" {
+			n.bgpImportSetup(uplinkNet.Config())
+		}
+	}
+
+	// Log the connections of the instances.
+	if shared.IsTrue(n.config["security.flows.logged"]) {
+		n.flowLogSetup()
+	} else {
+		flowLogUnregister(n)
+	}
+
 	revert.Success()
 
 	// Ensure network is marked as available now its started.
@@ -3186,9 +3270,178 @@ func (n *ovn) Stop() error {
 		return err
 	}
 
+	// Stop logging the connections of the instances.
+	flowLogUnregister(n)
+
 	return nil
 }
 
+// flowLogSetup starts emitting network-flow events for the connections of the instances on the network.
+func (n *ovn) flowLogSetup() {
+	// Instances are identified from the conntrack zone that ovn-controller assigned to their local ports.
+	// Using the zone rather than the addresses avoids logging a connection between two instances of the
+	// network twice for each of them, as it is tracked in the zones of both ports.
+	ports := &flowLogCache[uint16]{
+		load: func() (map[uint16]flowLogInstance, error) {
+			zones, err := openvswitch.NewOVS().OVNConntrackZones(n.state.GlobalConfig.NetworkOVNIntegrationBridge())
+			if err != nil {
+				return nil, fmt.Errorf("Failed getting OVN conntrack zones: %w", err)
+			}
+
+			portZones := make(map[openvswitch.OVNSwitchPort]uint16, len(zones))
+			for zone, portName := range zones {
+				portZones[portName] = zone
+			}
+
+			entries := map[uint16]flowLogInstance{}
+			err = UsedByInstanceDevices(n.state, n.Project(), n.Name(), n.Type(), func(inst db.InstanceArgs, nicName string, nicConfig map[string]string) error {
+				instanceUUID := inst.Config["volatile.uuid"]
+				if instanceUUID == "" {
+					return nil
+				}
+
+				// Skip the ports that aren't bound to the local chassis.
+				zone, found := portZones[n.getInstanceDevicePortName(instanceUUID, nicName)]
+				if !found {
+					return nil
+				}
+
+				devIPs, err := n.InstanceDevicePortIPs(instanceUUID, nicName)
+				if err != nil {
+					return nil // There is likely no active port.
+				}
+
+				addresses := make([]string, 0, len(devIPs))
+				for _, ip := range devIPs {
+					addresses = append(addresses, ip.String())
+				}
+
+				entries[zone] = flowLogInstance{project: inst.Project, name: inst.Name, addresses: addresses}
+
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			return entries, nil
+		},
+	}
+
+	flowLogRegister(n, func(flow *flowlog.Flow) {
+		inst, found := ports.get(flow.Zone)
+		if !found {
+			return
+		}
+
+		egress := shared.ValueInSlice(flow.Original.Source.String(), inst.addresses)
+		_ = n.state.Events.Send(inst.project, api.EventTypeNetworkFlow, flowLogRecord(n.name, inst, flow, egress))
+	})
+}
+
+// HandleHeartbeat hands the import of the routes learned from the uplink's BGP peers over to the new cluster leader
+// when the leadership changes.
+func (n *ovn) HandleHeartbeat(heartbeatData *cluster.APIHeartbeat) error {
+	if n.config["network"] == "" || !n.IsManaged() || n.LocalStatus() != api.NetworkStatusCreated {
+		return nil
+	}
+
+	leaderInfo, err := n.state.LeaderInfo()
+	if err != nil {
+		return err
+	}
+
+	// Nothing to do if the member already imports the routes when leader, and only then.
+	if leaderInfo.Leader == n.state.BGP.HasRouteImporter(n.bgpImportOwner()) {
+		return nil
+	}
+
+	uplinkNet, err := LoadByName(n.state, api.ProjectDefaultName, n.config["network"])
+	if err != nil {
+		return fmt.Errorf("Failed loading uplink network %q: %w", n.config["network"], err)
+	}
+
+	if uplinkNet.Type() == "physical" {
+		n.bgpImportSetup(uplinkNet.Config())
+	}
+
+	return nil
+}
+
+// bgpImportSetup installs the routes learned from the uplink's BGP peers that have route import enabled into the
+// logical router, and keeps them in sync as they change.
+// As the logical router is shared by all cluster members, only the cluster leader imports the routes.
+func (n *ovn) bgpImportSetup(uplinkConfig map[string]string) {
+	peers := bgpImportPeers(uplinkConfig)
+	if len(peers) == 0 {
+		n.state.BGP.RemoveRouteImporter(n.bgpImportOwner())
+		return
+	}
+
+	leaderInfo, err := n.state.LeaderInfo()
+	if err != nil {
+		n.logger.Warn("Failed getting cluster leader, skipping BGP route import", logger.Ctx{"err": err})
+		return
+	}
+
+	if !leaderInfo.Leader {
+		// Leave the routes in place for the leader to take over.
+		n.state.BGP.ForgetRouteImporter(n.bgpImportOwner())
+		return
+	}
+
+	addRoute := func(route bgp.Route) error {
+		client, err := openvswitch.NewOVN(n.state)
+		if err != nil {
+			return fmt.Errorf("Failed to get OVN client: %w", err)
+		}
+
+		return client.LogicalRouterRouteAdd(n.getRouterName(), true, openvswitch.OVNRouterRoute{
+			Prefix:  route.Prefix,
+			NextHop: route.Nexthop,
+			Port:    n.getRouterExtPortName(),
+		})
+	}
+
+	removeRoute := func(route bgp.Route) error {
+		client, err := openvswitch.NewOVN(n.state)
+		if err != nil {
+			return fmt.Errorf("Failed to get OVN client: %w", err)
+		}
+
+		err = client.LogicalRouterRouteDelete(n.getRouterName(), route.Prefix)
+		if err != nil {
+			return err
+		}
+
+		// An imported default route replaces the one via the uplink gateway, so restore it.
+		ones, _ := route.Prefix.Mask.Size()
+		if ones != 0 {
+			return nil
+		}
+
+		gatewayKey := "ipv4.gateway"
+		routerExtAddressKey := ovnVolatileUplinkIPv4
+		if route.Prefix.IP.To4() == nil {
+			gatewayKey = "ipv6.gateway"
+			routerExtAddressKey = ovnVolatileUplinkIPv6
+		}
+
+		uplinkGateway, _, err := net.ParseCIDR(uplinkConfig[gatewayKey])
+		if err != nil || n.config[routerExtAddressKey] == "" {
+			return nil
+		}
+
+		return client.LogicalRouterRouteAdd(n.getRouterName(), true, openvswitch.OVNRouterRoute{
+			Prefix:  route.Prefix,
+			NextHop: uplinkGateway,
+			Port:    n.getRouterExtPortName(),
+		})
+	}
+
+	n.state.BGP.AddRouteImporter(n.bgpImportOwner(), peers, n.bgpImportRouteHandler(addRoute, removeRoute))
+}
+
 // instanceNICGetRoutes returns list of routes defined in nicConfig.
 func (n *ovn) instanceNICGetRoutes(nicConfig map[string]string) []net.IPNet {
 	var routes []net.IPNet
@@ -3344,6 +3597,28 @@ func (n *ovn) Update(newNetwork api.NetworkPut, targetNode string, clientType re
 
 		aclConfigChanged := len(addedACLs) > 0 || len(removedACLs) > 0 || len(changedDefaultRuleKeys) > 0
 
+		// Get the switch DHCP option sets, used to refresh the instance NIC specific DHCP option sets.
+		var dhcpV4ID, dhcpV6ID openvswitch.OVNDHCPOptionsUUID
+
+		existingDHCPOpts, err := client.LogicalSwitchDHCPOptionsGet(n.getIntSwitchName())
+		if err != nil {
+			return fmt.Errorf("Failed getting existing DHCP settings for internal switch: %w", err)
+		}
+
+		for _, existingOpt := range existingDHCPOpts {
+			if existingOpt.Port != "" {
+				continue
+			}
+
+			if existingOpt.CIDR.IP.To4() == nil {
+				if dhcpV6ID == "" {
+					dhcpV6ID = existingOpt.UUID
+				}
+			} else if dhcpV4ID == "" {
+				dhcpV4ID = existingOpt.UUID
+			}
+		}
+
 		var localNICRoutes []net.IPNet
 
 		// Apply ACL changes to running instance NICs that use this network.
@@ -3358,6 +3633,12 @@ func (n *ovn) Update(newNetwork api.NetworkPut, targetNode string, clientType re
 				return nil // No need to update a port that isn't started yet.
 			}
 
+			// Refresh the instance NIC specific DHCP option sets from the updated switch ones.
+			_, _, err = n.instanceDevicePortDHCPOptionsSet(client, instancePortName, nicConfig, dhcpV4ID, dhcpV6ID)
+			if err != nil {
+				return err
+			}
+
 			// Apply security ACL and default rule changes.
 			if aclConfigChanged {
 				// Check whether we need to add any of the new ACLs to the NIC.
@@ -3480,6 +3761,12 @@ func (n *ovn) Update(newNetwork api.NetworkPut, targetNode string, clientType re
 					if err != nil {
 						return fmt.Errorf("Failed removing old network subnet %q from switch address set: %w", oldRouterIntPortIPNet.String(), err)
 					}
+
+					// Remove the SNAT rule of the old subnet, as it is no longer removed on setup.
+					err = client.LogicalRouterSNATDelete(n.getRouterName(), oldRouterIntPortIPNet)
+					if err != nil {
+						return fmt.Errorf("Failed removing old network subnet %q SNAT rule: %w", oldRouterIntPortIPNet.String(), err)
+					}
 				}
 			}
 		}
@@ -3519,6 +3806,15 @@ func (n *ovn) Update(newNetwork api.NetworkPut, targetNode string, clientType re
 		return err
 	}
 
+	// Start or stop logging the connections of the instances.
+	if shared.ValueInSlice("security.flows.logged", changedKeys) {
+		if shared.IsTrue(n.config["security.flows.logged"]) {
+			n.flowLogSetup()
+		} else {
+			flowLogUnregister(n)
+		}
+	}
+
 	revert.Success()
 	return nil
 }
@@ -3614,7 +3910,7 @@ func (n *ovn) InstanceDevicePortValidateExternalRoutes(deviceInstance instance.I
 	}
 
 	// Get project restricted routes.
-	projectRestrictedSubnets, err := n.projectRestrictedSubnets(p, n.config["network"])
+	projectRestrictedSubnets, err := projectRestrictedSubnets(p, n.config["network"])
 	if err != nil {
 		return err
 	}
@@ -3671,6 +3967,67 @@ func (n *ovn) InstanceDevicePortValidateExternalRoutes(deviceInstance instance.I
 	return nil
 }
 
+// InstanceDevicePortValidateNATAddresses validates the SNAT addresses of an instance NIC connected to the network.
+// The addresses must be within the uplink's routes and, if the instance's project is restricted, within its
+// restricted subnets for the uplink.
+func (n *ovn) InstanceDevicePortValidateNATAddresses(p *api.Project, natAddresses []net.IP) error {
+	var uplink *api.Network
+
+	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		_, uplink, _, err = tx.GetNetworkInAnyState(ctx, api.ProjectDefaultName, n.config["network"])
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to load uplink network %q: %w", n.config["network"], err)
+	}
+
+	uplinkRoutes, err := n.uplinkRoutes(uplink)
+	if err != nil {
+		return err
+	}
+
+	projectRestrictedSubnets, err := projectRestrictedSubnets(p, n.config["network"])
+	if err != nil {
+		return err
+	}
+
+	for _, natAddress := range natAddresses {
+		keyPrefix := "ipv4"
+		if natAddress.To4() == nil {
+			keyPrefix = "ipv6"
+		}
+
+		snatAddressKey := keyPrefix + ".nat.address"
+
+		if shared.IsFalseOrEmpty(n.config[keyPrefix+".nat"]) {
+			return fmt.Errorf("Cannot specify %q when %q is disabled on network %q", snatAddressKey, keyPrefix+".nat", n.name)
+		}
+
+		if uplink.Config["ovn.ingress_mode"] != "routed" {
+			return fmt.Errorf(`Cannot specify %q when uplink ovn.ingress_mode is not "routed"`, snatAddressKey)
+		}
+
+		natIPNet := IPToNet(natAddress)
+
+		err = n.validateExternalSubnet(uplinkRoutes, projectRestrictedSubnets, &natIPNet)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Check the addresses aren't used by network forwards, load balancers, other networks or the NICs of other
+	// projects on the uplink.
+	externalSubnetsInUse, err := n.getExternalSubnetInUse(n.config["network"])
+	if err != nil {
+		return err
+	}
+
+	return natAddressesCheckNotInUse(externalSubnetsInUse, n.project, n.name, p.Name, natAddresses)
+}
+
 // InstanceDevicePortAdd adds empty DNS record (to indicate port has been added) and any DHCP reservations for
 // instance device port.
 func (n *ovn) InstanceDevicePortAdd(instanceUUID string, deviceName string, deviceConfig deviceConfig.Device) error {
@@ -3714,6 +4071,66 @@ func (n *ovn) InstanceDevicePortAdd(instanceUUID string, deviceName string, devi
 	return nil
 }
 
+// instanceDevicePortDHCPOptionsSet creates or updates the instance NIC specific DHCP option sets if the NIC
+// or its DHCP reservations override any DHCP options. These are copies of the switch's DHCP option sets with the
+// reservation's and then the NIC's options applied.
+// Returns the DHCPv4 and DHCPv6 option sets to use for the instance NIC's logical switch port.
+func (n *ovn) instanceDevicePortDHCPOptionsSet(client *openvswitch.OVN, instancePortName openvswitch.OVNSwitchPort, deviceConfig deviceConfig.Device, dhcpV4ID openvswitch.OVNDHCPOptionsUUID, dhcpV6ID openvswitch.OVNDHCPOptionsUUID) (openvswitch.OVNDHCPOptionsUUID, openvswitch.OVNDHCPOptionsUUID, error) {
+	dhcpConfig, err := n.reservationDHCPOptionsConfig(deviceConfig)
+	if err != nil {
+		return "", "", err
+	}
+
+	// The NIC's options take precedence over the reservation's.
+	for k, v := range deviceConfig {
+		if v != "" {
+			dhcpConfig[k] = v
+		}
+	}
+
+	dhcpV4Options, dhcpV6Options := DHCPOptionsOVN(dhcpConfig)
+
+	if dhcpV4ID != "" && len(dhcpV4Options) > 0 {
+		dhcpV4ID, err = client.LogicalSwitchPortDHCPOptionsSet(n.getIntSwitchName(), instancePortName, dhcpV4ID, dhcpV4Options)
+		if err != nil {
+			return "", "", fmt.Errorf("Failed setting DHCPv4 settings for instance port: %w", err)
+		}
+	}
+
+	if dhcpV6ID != "" && len(dhcpV6Options) > 0 {
+		dhcpV6ID, err = client.LogicalSwitchPortDHCPOptionsSet(n.getIntSwitchName(), instancePortName, dhcpV6ID, dhcpV6Options)
+		if err != nil {
+			return "", "", fmt.Errorf("Failed setting DHCPv6 settings for instance port: %w", err)
+		}
+	}
+
+	return dhcpV4ID, dhcpV6ID, nil
+}
+
+// instanceDevicePortDHCPOptionsDelete deletes the instance NIC specific DHCP option sets.
+func (n *ovn) instanceDevicePortDHCPOptionsDelete(client *openvswitch.OVN, instancePortName openvswitch.OVNSwitchPort) error {
+	existingOpts, err := client.LogicalSwitchDHCPOptionsGet(n.getIntSwitchName())
+	if err != nil {
+		return fmt.Errorf("Failed getting existing DHCP settings for internal switch: %w", err)
+	}
+
+	deleteUUIDs := []openvswitch.OVNDHCPOptionsUUID{}
+	for _, existingOpt := range existingOpts {
+		if existingOpt.Port == instancePortName {
+			deleteUUIDs = append(deleteUUIDs, existingOpt.UUID)
+		}
+	}
+
+	if len(deleteUUIDs) > 0 {
+		err = client.LogicalSwitchDHCPOptionsDelete(n.getIntSwitchName(), deleteUUIDs...)
+		if err != nil {
+			return fmt.Errorf("Failed deleting DHCP settings for instance port: %w", err)
+		}
+	}
+
+	return nil
+}
+
 // hasDHCPv4Reservation returns whether IP is in the supplied reservation list.
 func (n *ovn) hasDHCPv4Reservation(dhcpReservations []shared.IPRange, ip net.IP) bool {
 	for _, dhcpReservation := range dhcpReservations {
@@ -3752,6 +4169,20 @@ func (n *ovn) InstanceDevicePortStart(opts *OVNInstanceNICSetupOpts, securityACL
 		staticIPs = append(staticIPs, ip)
 	}
 
+	// Use the network's DHCP reservations for the NIC's MAC address when no static address is set.
+	if opts.DeviceConfig["ipv4.address"] == "" || opts.DeviceConfig["ipv6.address"] == "" {
+		reservedIPs, err := n.reservationAddresses(mac)
+		if err != nil {
+			return "", err
+		}
+
+		for _, ip := range reservedIPs {
+			if (ip.To4() != nil && opts.DeviceConfig["ipv4.address"] == "") || (ip.To4() == nil && opts.DeviceConfig["ipv6.address"] == "") {
+				staticIPs = append(staticIPs, ip)
+			}
+		}
+	}
+
 	internalRoutes, externalRoutes, err := n.instanceDevicePortRoutesParse(opts.DeviceConfig)
 	if err != nil {
 		return "", fmt.Errorf("Failed parsing NIC device routes: %w", err)
@@ -3776,17 +4207,18 @@ func (n *ovn) InstanceDevicePortStart(opts *OVNInstanceNICSetupOpts, securityACL
 	dhcpv4Subnet := n.DHCPv4Subnet()
 	dhcpv6Subnet := n.DHCPv6Subnet()
 	var dhcpV4ID, dhcpv6ID openvswitch.OVNDHCPOptionsUUID
+	var existingOpts []openvswitch.OVNDHCPOptsSet
 
 	if dhcpv4Subnet != nil || dhcpv6Subnet != nil {
 		// Find existing DHCP options set for IPv4 and IPv6 and update them instead of adding sets.
-		existingOpts, err := client.LogicalSwitchDHCPOptionsGet(n.getIntSwitchName())
+		existingOpts, err = client.LogicalSwitchDHCPOptionsGet(n.getIntSwitchName())
 		if err != nil {
 			return "", fmt.Errorf("Failed getting existing DHCP settings for internal switch: %w", err)
 		}
 
 		if dhcpv4Subnet != nil {
 			for _, existingOpt := range existingOpts {
-				if existingOpt.CIDR.String() == dhcpv4Subnet.String() {
+				if existingOpt.Port == "" && existingOpt.CIDR.String() == dhcpv4Subnet.String() {
 					if dhcpV4ID != "" {
 						return "", fmt.Errorf("Multiple matching DHCP option sets found for switch %q and subnet %q", n.getIntSwitchName(), dhcpv4Subnet.String())
 					}
@@ -3802,7 +4234,7 @@ func (n *ovn) InstanceDevicePortStart(opts *OVNInstanceNICSetupOpts, securityACL
 
 		if dhcpv6Subnet != nil {
 			for _, existingOpt := range existingOpts {
-				if existingOpt.CIDR.String() == dhcpv6Subnet.String() {
+				if existingOpt.Port == "" && existingOpt.CIDR.String() == dhcpv6Subnet.String() {
 					if dhcpv6ID != "" {
 						return "", fmt.Errorf("Multiple matching DHCP option sets found for switch %q and subnet %q", n.getIntSwitchName(), dhcpv6Subnet.String())
 					}
@@ -3844,6 +4276,12 @@ func (n *ovn) InstanceDevicePortStart(opts *OVNInstanceNICSetupOpts, securityACL
 
 	instancePortName := n.getInstanceDevicePortName(opts.InstanceUUID, opts.DeviceName)
 
+	// Use instance NIC specific DHCP option sets if the NIC overrides any DHCP options.
+	dhcpV4ID, dhcpv6ID, err = n.instanceDevicePortDHCPOptionsSet(client, instancePortName, opts.DeviceConfig, dhcpV4ID, dhcpv6ID)
+	if err != nil {
+		return "", err
+	}
+
 	var nestedPortParentName openvswitch.OVNSwitchPort
 	var nestedPortVLAN uint16
 	if opts.DeviceConfig["nested"] != "" {
@@ -3874,6 +4312,21 @@ func (n *ovn) InstanceDevicePortStart(opts *OVNInstanceNICSetupOpts, securityACL
 
 	revert.Add(func() { _ = client.LogicalSwitchPortDelete(instancePortName) })
 
+	// Remove instance NIC specific DHCP option sets that are no longer used.
+	staleDHCPOpts := []openvswitch.OVNDHCPOptionsUUID{}
+	for _, existingOpt := range existingOpts {
+		if existingOpt.Port == instancePortName && existingOpt.UUID != dhcpV4ID && existingOpt.UUID != dhcpv6ID {
+			staleDHCPOpts = append(staleDHCPOpts, existingOpt.UUID)
+		}
+	}
+
+	if len(staleDHCPOpts) > 0 {
+		err = client.LogicalSwitchDHCPOptionsDelete(n.getIntSwitchName(), staleDHCPOpts...)
+		if err != nil {
+			return "", fmt.Errorf("Failed deleting unused DHCP settings for instance port: %w", err)
+		}
+	}
+
 	// Add DNS records for port's IPs, and retrieve the IP addresses used.
 	var dnsIPv4, dnsIPv6 net.IP
 	dnsIPs := make([]net.IP, 0, 2)
@@ -3977,6 +4430,43 @@ func (n *ovn) InstanceDevicePortStart(opts *OVNInstanceNICSetupOpts, securityACL
 		}
 	}
 
+	// Add SNAT rules for the NIC's own SNAT addresses. These take precedence over the network's SNAT rules
+	// as they are more specific.
+	for _, keyPrefix := range []string{"ipv4", "ipv6"} {
+		snatAddressKey := keyPrefix + ".nat.address"
+		if opts.DeviceConfig[snatAddressKey] == "" {
+			continue
+		}
+
+		snatIP := net.ParseIP(opts.DeviceConfig[snatAddressKey])
+		if snatIP == nil {
+			return "", fmt.Errorf("Failed parsing %q", snatAddressKey)
+		}
+
+		ip := dnsIPv4
+		if keyPrefix == "ipv6" {
+			ip = dnsIPv6
+		}
+
+		if ip == nil {
+			return "", fmt.Errorf("Cannot apply %q as the NIC has no address of the same family", snatAddressKey)
+		}
+
+		ipNet := IPToNet(ip)
+
+		err = client.LogicalRouterSNATDelete(n.getRouterName(), &ipNet)
+		if err != nil {
+			return "", err
+		}
+
+		err = client.LogicalRouterSNATAdd(n.getRouterName(), &ipNet, snatIP, false)
+		if err != nil {
+			return "", fmt.Errorf("Failed adding router SNAT rule for %q: %w", snatAddressKey, err)
+		}
+
+		revert.Add(func() { _ = client.LogicalRouterSNATDelete(n.getRouterName(), &ipNet) })
+	}
+
 	routes := make([]openvswitch.OVNRouterRoute, 0, len(internalRoutes)+len(externalRoutes))
 
 	// In l3only mode we add the instance port's IPs as static routes to the router.
@@ -4329,6 +4819,12 @@ func (n *ovn) InstanceDevicePortRemove(instanceUUID string, deviceName string, d
 		return err
 	}
 
+	// Delete the instance NIC specific DHCP option sets.
+	err = n.instanceDevicePortDHCPOptionsDelete(client, instancePortName)
+	if err != nil {
+		return err
+	}
+
 	removeRoutes := make([]net.IPNet, 0, len(dnsIPs)+len(internalRoutes)+len(externalRoutes))
 	var removeNATIPs []net.IP
 
@@ -4402,6 +4898,20 @@ func (n *ovn) InstanceDevicePortRemove(instanceUUID string, deviceName string, d
 		}
 	}
 
+	// Delete any SNAT rules for the NIC's own SNAT addresses.
+	if len(dnsIPs) > 0 {
+		removeSNATNets := make([]*net.IPNet, 0, len(dnsIPs))
+		for _, dnsIP := range dnsIPs {
+			ipNet := IPToNet(dnsIP)
+			removeSNATNets = append(removeSNATNets, &ipNet)
+		}
+
+		err = client.LogicalRouterSNATDelete(n.getRouterName(), removeSNATNets...)
+		if err != nil {
+			return err
+		}
+	}
+
 	revert := revert.New()
 	defer revert.Fail()
 
@@ -4555,8 +5065,8 @@ func (n *ovn) ovnNetworkExternalSubnets(ovnProjectNetworksWithOurUplink map[stri
 	return externalSubnets, nil
 }
 
-// ovnNICExternalRoutes returns a list of external routes currently used by OVN NICs that are connected to OVN
-// networks that share the same uplink as this network uses.
+// ovnNICExternalRoutes returns a list of external routes and SNAT addresses currently used by OVN NICs that are
+// connected to OVN networks that share the same uplink as this network uses.
 func (n *ovn) ovnNICExternalRoutes(ovnProjectNetworksWithOurUplink map[string][]*api.Network) ([]externalSubnetUsage, error) {
 	externalRoutes := make([]externalSubnetUsage, 0)
 
@@ -4599,6 +5109,24 @@ func (n *ovn) ovnNICExternalRoutes(ovnProjectNetworksWithOurUplink map[string][]
 						})
 					}
 				}
+
+				// Add the NIC's own SNAT addresses.
+				for _, key := range []string{"ipv4.nat.address", "ipv6.nat.address"} {
+					natAddress := net.ParseIP(devConfig[key])
+					if natAddress == nil {
+						continue
+					}
+
+					externalRoutes = append(externalRoutes, externalSubnetUsage{
+						subnet:          IPToNet(natAddress),
+						networkProject:  instNetworkProject,
+						networkName:     devConfig["network"],
+						instanceProject: inst.Project,
+						instanceName:    inst.Name,
+						instanceDevice:  devName,
+						usageType:       subnetUsageInstanceSNAT,
+					})
+				}
 			}
 
 			return nil
@@ -4830,7 +5358,7 @@ func (n *ovn) allocateUplinkAddress(listenIPAddress net.IP) (net.IP, error) {
 	}
 
 	// Get project restricted routes.
-	projectRestrictedSubnets, err := n.projectRestrictedSubnets(p, n.config["network"])
+	projectRestrictedSubnets, err := projectRestrictedSubnets(p, n.config["network"])
 	if err != nil {
 		return nil, err
 	}
@@ -5502,7 +6030,7 @@ func (n *ovn) Leases(projectName string, clientType request.ClientType) ([]api.N
 }
 
 // PeerCreate creates a network peering.
-func (n *ovn) PeerCreate(peer api.NetworkPeersPost) error {
+func (n *ovn) PeerCreate(peer api.NetworkPeersPost, clientType request.ClientType) error {
 	revert := revert.New()
 	defer revert.Fail()
 
@@ -5782,61 +6310,11 @@ func (n *ovn) peerSetup(client *openvswitch.OVN, targetOVNNet *ovn, opts openvsw
 
 // PeerUpdate updates a network peering.
 func (n *ovn) PeerUpdate(peerName string, req api.NetworkPeerPut) error {
-	revert := revert.New()
-	defer revert.Fail()
-
-	var curPeerID int64
-	var curPeer *api.NetworkPeer
-
-	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
-		var err error
-
-		curPeerID, curPeer, err = tx.GetNetworkPeer(ctx, n.ID(), peerName)
-
-		return err
-	})
-	if err != nil {
-		return err
-	}
-
-	err = n.peerValidate(peerName, &req)
-	if err != nil {
-		return err
-	}
-
-	curPeerEtagHash, err := util.EtagHash(curPeer.Etag())
-	if err != nil {
-		return err
-	}
-
-	newPeer := api.NetworkPeer{
-		Name: curPeer.Name,
-	}
-
-	newPeer.SetWritable(req)
-
-	newPeerEtagHash, err := util.EtagHash(newPeer.Etag())
-	if err != nil {
-		return err
-	}
-
-	if curPeerEtagHash == newPeerEtagHash {
-		return nil // Nothing has changed.
-	}
-
-	err = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
-		return tx.UpdateNetworkPeer(ctx, n.ID(), curPeerID, newPeer.Writable())
-	})
-	if err != nil {
-		return err
-	}
-
-	revert.Success()
-	return nil
+	return n.peerUpdate(peerName, req)
 }
 
 // PeerDelete deletes a network peering.
-func (n *ovn) PeerDelete(peerName string) error {
+func (n *ovn) PeerDelete(peerName string, clientType request.ClientType) error {
 	var peerID int64
 	var peer *api.NetworkPeer
 
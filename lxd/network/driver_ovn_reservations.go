@@ -0,0 +1,299 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/canonical/lxd/lxd/cluster/request"
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/network/openvswitch"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/revert"
+)
+
+// ReservationCreate creates a DHCP reservation.
+// OVN only serves DHCP to the logical switch ports it knows about, so reservations apply to the instance NICs
+// with a matching MAC address the next time they are started.
+func (n *ovn) ReservationCreate(reservation api.NetworkReservationsPost, clientType request.ClientType) error {
+	if clientType != request.ClientTypeNormal {
+		return nil
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	reservation.Normalise()
+
+	address := net.ParseIP(reservation.Address)
+
+	// Check if there is an existing reservation using the same address.
+	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		_, _, err := tx.GetNetworkReservation(ctx, n.ID(), reservation.Address)
+
+		return err
+	})
+	if err == nil {
+		return api.StatusErrorf(http.StatusConflict, "A reservation for that address already exists")
+	} else if !api.StatusErrorCheck(err, http.StatusNotFound) {
+		return err
+	}
+
+	err = n.reservationValidate(address, &reservation.NetworkReservationPut, []string{n.config["ipv4.address"], n.config["ipv6.address"]}, true)
+	if err != nil {
+		return err
+	}
+
+	err = n.reservationCheckInstanceAddresses(address, reservation.Config["hwaddr"])
+	if err != nil {
+		return err
+	}
+
+	var reservationID int64
+
+	err = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		reservationID, err = tx.CreateNetworkReservation(ctx, n.ID(), &reservation)
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	revert.Add(func() {
+		_ = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			return tx.DeleteNetworkReservation(ctx, n.ID(), reservationID)
+		})
+	})
+
+	// Exclude the address from the dynamic allocations of the logical switch.
+	if address.To4() != nil {
+		client, err := openvswitch.NewOVN(n.state)
+		if err != nil {
+			return fmt.Errorf("Failed to get OVN client: %w", err)
+		}
+
+		dhcpReservations, err := client.LogicalSwitchDHCPv4RevervationsGet(n.getIntSwitchName())
+		if err != nil {
+			return fmt.Errorf("Failed getting DHCPv4 reservations: %w", err)
+		}
+
+		if !n.hasDHCPv4Reservation(dhcpReservations, address) {
+			dhcpReservations = append(dhcpReservations, shared.IPRange{Start: address})
+			err = client.LogicalSwitchDHCPv4RevervationsSet(n.getIntSwitchName(), dhcpReservations)
+			if err != nil {
+				return fmt.Errorf("Failed adding DHCPv4 reservation for %q: %w", address.String(), err)
+			}
+		}
+	}
+
+	revert.Success()
+	return nil
+}
+
+// ReservationUpdate updates a DHCP reservation.
+func (n *ovn) ReservationUpdate(address string, req api.NetworkReservationPut, clientType request.ClientType) error {
+	if clientType != request.ClientTypeNormal {
+		return nil
+	}
+
+	var curReservationID int64
+	var curReservation *api.NetworkReservation
+
+	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		curReservationID, curReservation, err = tx.GetNetworkReservation(ctx, n.ID(), address)
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	req.Normalise()
+
+	err = n.reservationValidate(net.ParseIP(curReservation.Address), &req, []string{n.config["ipv4.address"], n.config["ipv6.address"]}, true)
+	if err != nil {
+		return err
+	}
+
+	err = n.reservationCheckInstanceAddresses(net.ParseIP(curReservation.Address), req.Config["hwaddr"])
+	if err != nil {
+		return err
+	}
+
+	curReservationEtagHash, err := util.EtagHash(curReservation.Etag())
+	if err != nil {
+		return err
+	}
+
+	newReservation := api.NetworkReservation{
+		Address: curReservation.Address,
+	}
+
+	newReservation.SetWritable(req)
+
+	newReservationEtagHash, err := util.EtagHash(newReservation.Etag())
+	if err != nil {
+		return err
+	}
+
+	if curReservationEtagHash == newReservationEtagHash {
+		return nil // Nothing has changed.
+	}
+
+	return n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.UpdateNetworkReservation(ctx, n.ID(), curReservationID, newReservation.Writable())
+	})
+}
+
+// ReservationDelete deletes a DHCP reservation.
+func (n *ovn) ReservationDelete(address string, clientType request.ClientType) error {
+	if clientType != request.ClientTypeNormal {
+		return nil
+	}
+
+	var reservation *api.NetworkReservation
+
+	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		reservationID, curReservation, err := tx.GetNetworkReservation(ctx, n.ID(), address)
+		if err != nil {
+			return err
+		}
+
+		reservation = curReservation
+
+		return tx.DeleteNetworkReservation(ctx, n.ID(), reservationID)
+	})
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(reservation.Address)
+	if ip.To4() == nil {
+		return nil
+	}
+
+	// Return the address to the dynamic allocations of the logical switch, unless it is still reserved by
+	// the router or an instance NIC.
+	remainingReservations, err := n.getDHCPv4Reservations()
+	if err != nil {
+		return fmt.Errorf("Failed getting DHCPv4 IP reservations: %w", err)
+	}
+
+	if n.hasDHCPv4Reservation(remainingReservations, ip) {
+		return nil
+	}
+
+	client, err := openvswitch.NewOVN(n.state)
+	if err != nil {
+		return fmt.Errorf("Failed to get OVN client: %w", err)
+	}
+
+	dhcpReservations, err := client.LogicalSwitchDHCPv4RevervationsGet(n.getIntSwitchName())
+	if err != nil {
+		return fmt.Errorf("Failed getting DHCPv4 reservations: %w", err)
+	}
+
+	dhcpReservationsNew := make([]shared.IPRange, 0, len(dhcpReservations))
+	for _, dhcpReservation := range dhcpReservations {
+		if dhcpReservation.Start.Equal(ip) && dhcpReservation.End == nil {
+			continue
+		}
+
+		dhcpReservationsNew = append(dhcpReservationsNew, dhcpReservation)
+	}
+
+	if len(dhcpReservationsNew) != len(dhcpReservations) {
+		err = client.LogicalSwitchDHCPv4RevervationsSet(n.getIntSwitchName(), dhcpReservationsNew)
+		if err != nil {
+			return fmt.Errorf("Failed removing DHCPv4 reservation for %q: %w", ip.String(), err)
+		}
+	}
+
+	return nil
+}
+
+// reservationsGet returns the reservations for the MAC address.
+func (n *ovn) reservationsGet(mac net.HardwareAddr) ([]*api.NetworkReservation, error) {
+	var reservations map[int64]*api.NetworkReservation
+
+	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		reservations, err = tx.GetNetworkReservations(ctx, n.ID())
+
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading network reservations: %w", err)
+	}
+
+	macReservations := []*api.NetworkReservation{}
+	for _, reservation := range reservations {
+		if reservation.Config["hwaddr"] == mac.String() {
+			macReservations = append(macReservations, reservation)
+		}
+	}
+
+	return macReservations, nil
+}
+
+// reservationAddresses returns the addresses reserved for the MAC address.
+func (n *ovn) reservationAddresses(mac net.HardwareAddr) ([]net.IP, error) {
+	reservations, err := n.reservationsGet(mac)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := []net.IP{}
+	for _, reservation := range reservations {
+		ip := net.ParseIP(reservation.Address)
+		if ip != nil {
+			addresses = append(addresses, ip)
+		}
+	}
+
+	return addresses, nil
+}
+
+// reservationDHCPOptionsConfig returns the DHCP option keys of the reservations used by an instance NIC.
+// Reservations for an IP protocol that the NIC has a static address for aren't used and so are skipped.
+func (n *ovn) reservationDHCPOptionsConfig(deviceConfig map[string]string) (map[string]string, error) {
+	mac, err := net.ParseMAC(deviceConfig["hwaddr"])
+	if err != nil {
+		return nil, err
+	}
+
+	reservations, err := n.reservationsGet(mac)
+	if err != nil {
+		return nil, err
+	}
+
+	options := make(map[string]string)
+	for _, reservation := range reservations {
+		ip := net.ParseIP(reservation.Address)
+		if ip == nil {
+			continue
+		}
+
+		family := uint(4)
+		if ip.To4() == nil {
+			family = 6
+		}
+
+		if deviceConfig[fmt.Sprintf("ipv%d.address", family)] != "" {
+			continue
+		}
+
+		for k, v := range dhcpOptionsConfig(reservation.Config, family) {
+			options[k] = v
+		}
+	}
+
+	return options, nil
+}
@@ -204,6 +204,17 @@ func (n *physical) Validate(config map[string]string) error {
 	//  required: no
 	//  shortdesc: Peer session hold time
 	//  scope: global
+
+	// lxdmeta:generate(entities=network-physical; group=network-conf; key=bgp.peers.NAME.import)
+	// When enabled, the routes learned from the peer are installed into the routers of the `ovn` downstream networks.
+	// See {ref}`network-bgp-import`.
+	// ---
+	//  type: bool
+	//  condition: BGP server
+	//  defaultdesc: `false`
+	//  required: no
+	//  shortdesc: Whether to import the routes learned from the peer
+	//  scope: global
 	bgpRules, err := n.bgpValidationRules(config)
 	if err != nil {
 		return err
@@ -498,6 +509,16 @@ func (n *physical) Update(newNetwork api.NetworkPut, targetNode string, clientTy
 
 	revert.Success()
 
+	// Refresh the BGP route import of the dependent OVN networks on the local member.
+	if fmt.Sprint(bgpImportPeers(oldNetwork.Config)) != fmt.Sprint(bgpImportPeers(n.config)) {
+		for _, depNet := range n.dependentNetworks() {
+			ovnNet, ok := depNet.(*ovn)
+			if ok {
+				ovnNet.bgpImportSetup(n.config)
+			}
+		}
+	}
+
 	// Notify dependent networks (those using this network as their uplink) of the changes.
 	// Do this after the network has been successfully updated so that a failure to notify a dependent network
 	// doesn't prevent the network itself from being updated.
@@ -548,5 +569,10 @@ func (n *physical) State() (*api.NetworkState, error) {
 		return nil, err
 	}
 
+	state.BGP, err = n.bgpState()
+	if err != nil {
+		return nil, err
+	}
+
 	return state, nil
 }
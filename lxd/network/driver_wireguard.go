@@ -0,0 +1,1092 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/canonical/lxd/lxd/cluster"
+	"github.com/canonical/lxd/lxd/cluster/request"
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/dnsmasq/dhcpalloc"
+	"github.com/canonical/lxd/lxd/ip"
+	"github.com/canonical/lxd/lxd/network/openvswitch"
+	"github.com/canonical/lxd/lxd/resources"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/revert"
+	"github.com/canonical/lxd/shared/validate"
+)
+
+// Default WireGuard settings.
+const wireguardDefaultMTU = 1420
+const wireguardDefaultPort = "51820"
+
+// wireguard represents a LXD WireGuard network.
+type wireguard struct {
+	common
+}
+
+// DBType returns the network type DB ID.
+func (n *wireguard) DBType() db.NetworkType {
+	return db.NetworkTypeWireGuard
+}
+
+// ValidateName validates network name.
+func (n *wireguard) ValidateName(name string) error {
+	err := validate.IsInterfaceName(name)
+	if err != nil {
+		return err
+	}
+
+	// Apply common name validation that applies to all network types.
+	return n.common.ValidateName(name)
+}
+
+// Validate network config.
+func (n *wireguard) Validate(config map[string]string) error {
+	rules := map[string]func(value string) error{
+		// lxdmeta:generate(entities=network-wireguard; group=network-conf; key=ipv4.address)
+		// Use CIDR notation for the subnet of the tunnel.
+		// Each cluster member uses the address of the subnet that matches its member ID.
+		// ---
+		//  type: string
+		//  shortdesc: IPv4 subnet of the tunnel
+		//  scope: global
+		"ipv4.address": validate.Optional(validate.IsNetworkV4),
+		// lxdmeta:generate(entities=network-wireguard; group=network-conf; key=ipv6.address)
+		// Use CIDR notation for the subnet of the tunnel.
+		// Each cluster member uses the address of the subnet that matches its member ID.
+		// ---
+		//  type: string
+		//  shortdesc: IPv6 subnet of the tunnel
+		//  scope: global
+		"ipv6.address": validate.Optional(validate.IsNetworkV6),
+		// lxdmeta:generate(entities=network-wireguard; group=network-conf; key=ipv4.gateway)
+		// Use CIDR notation.
+		// Setting this key creates a local uplink bridge on each cluster member that holds the gateway address, so that the network can be used as uplink for `ovn` networks.
+		// ---
+		//  type: string
+		//  shortdesc: IPv4 gateway and subnet of the OVN uplink
+		//  scope: global
+		"ipv4.gateway": validate.Optional(validate.IsNetworkAddressCIDRV4),
+		// lxdmeta:generate(entities=network-wireguard; group=network-conf; key=ipv6.gateway)
+		// Use CIDR notation.
+		// Setting this key creates a local uplink bridge on each cluster member that holds the gateway address, so that the network can be used as uplink for `ovn` networks.
+		// ---
+		//  type: string
+		//  shortdesc: IPv6 gateway and subnet of the OVN uplink
+		//  scope: global
+		"ipv6.gateway": validate.Optional(validate.IsNetworkAddressCIDRV6),
+		// lxdmeta:generate(entities=network-wireguard; group=network-conf; key=ipv4.ovn.ranges)
+		// Specify a comma-separated list of IPv4 ranges in FIRST-LAST format.
+		// ---
+		//  type: string
+		//  shortdesc: IPv4 ranges to use for child OVN network routers
+		//  scope: global
+		"ipv4.ovn.ranges": validate.Optional(validate.IsListOf(validate.IsNetworkRangeV4)),
+		// lxdmeta:generate(entities=network-wireguard; group=network-conf; key=ipv6.ovn.ranges)
+		// Specify a comma-separated list of IPv6 ranges in FIRST-LAST format.
+		// ---
+		//  type: string
+		//  shortdesc: IPv6 ranges to use for child OVN network routers
+		//  scope: global
+		"ipv6.ovn.ranges": validate.Optional(validate.IsListOf(validate.IsNetworkRangeV6)),
+		// lxdmeta:generate(entities=network-wireguard; group=network-conf; key=dns.nameservers)
+		// Specify a list of DNS server IPs.
+		// ---
+		//  type: string
+		//  shortdesc: DNS server IPs advertised to child OVN networks
+		//  scope: global
+		"dns.nameservers": validate.Optional(validate.IsListOf(validate.IsNetworkAddress)),
+		// lxdmeta:generate(entities=network-wireguard; group=network-conf; key=mtu)
+		//
+		// ---
+		//  type: integer
+		//  defaultdesc: `1420`
+		//  shortdesc: MTU of the WireGuard interface
+		//  scope: global
+		"mtu": validate.Optional(validate.IsNetworkMTU),
+		// lxdmeta:generate(entities=network-wireguard; group=network-conf; key=wireguard.port)
+		//
+		// ---
+		//  type: integer
+		//  defaultdesc: `51820`
+		//  shortdesc: UDP port the WireGuard interface listens on
+		//  scope: global
+		"wireguard.port": validate.Optional(validate.IsNetworkPort),
+		// lxdmeta:generate(entities=network-wireguard; group=network-conf; key=wireguard.endpoint)
+		// Use the `<host>` or `<host>:<port>` format.
+		// If not set, the cluster address of the member is used with the port from {config:option}`network-wireguard-network-conf:wireguard.port`.
+		// ---
+		//  type: string
+		//  shortdesc: Endpoint the other cluster members use to reach this member
+		//  scope: local
+		"wireguard.endpoint": validate.Optional(n.validateEndpoint),
+		// lxdmeta:generate(entities=network-wireguard; group=network-conf; key=wireguard.cluster_peering)
+		//
+		// ---
+		//  type: bool
+		//  defaultdesc: `true`
+		//  shortdesc: Whether to automatically peer with the other cluster members
+		//  scope: global
+		"wireguard.cluster_peering": validate.Optional(validate.IsBool),
+		// lxdmeta:generate(entities=network-wireguard; group=network-conf; key=wireguard.persistent_keepalive)
+		// Specify the interval in seconds, or `0` to disable keepalive packets.
+		// ---
+		//  type: integer
+		//  defaultdesc: `0`
+		//  shortdesc: Keepalive interval used for the cluster member peers
+		//  scope: global
+		"wireguard.persistent_keepalive": validate.Optional(validate.IsInRange(0, 65535)),
+
+		// lxdmeta:generate(entities=network-wireguard; group=network-conf; key=user.*)
+		//
+		// ---
+		//  type: string
+		//  shortdesc: User-provided free-form key/value pairs
+		//  scope: global
+	}
+
+	// Add the peer validation rules.
+
+	// lxdmeta:generate(entities=network-wireguard; group=network-conf; key=peers.NAME.public_key)
+	//
+	// ---
+	//  type: string
+	//  shortdesc: Public key of the static peer
+	//  scope: global
+
+	// lxdmeta:generate(entities=network-wireguard; group=network-conf; key=peers.NAME.allowed_ips)
+	// Specify a comma-separated list of CIDR subnets.
+	// ---
+	//  type: string
+	//  shortdesc: Subnets routed to the static peer
+	//  scope: global
+
+	// lxdmeta:generate(entities=network-wireguard; group=network-conf; key=peers.NAME.endpoint)
+	// Use the `<host>:<port>` format.
+	// ---
+	//  type: string
+	//  required: no
+	//  shortdesc: Endpoint of the static peer
+	//  scope: global
+
+	// lxdmeta:generate(entities=network-wireguard; group=network-conf; key=peers.NAME.persistent_keepalive)
+	// Specify the interval in seconds, or `0` to disable keepalive packets.
+	// ---
+	//  type: integer
+	//  defaultdesc: `0`
+	//  required: no
+	//  shortdesc: Keepalive interval used for the static peer
+	//  scope: global
+	for k := range config {
+		// Peer keys have the peer name in their name, extract the suffix.
+		if !strings.HasPrefix(k, "peers.") {
+			continue
+		}
+
+		fields := strings.Split(k, ".")
+		if len(fields) != 3 {
+			return fmt.Errorf("Invalid network configuration key: %q", k)
+		}
+
+		// Add the correct validation rule for the dynamic field based on last part of key.
+		switch fields[2] {
+		case "public_key":
+			rules[k] = validate.Required(wireguardValidateKey)
+		case "allowed_ips":
+			rules[k] = validate.Required(validate.IsListOf(validate.IsNetwork))
+		case "endpoint":
+			rules[k] = validate.Optional(wireguardValidatePeerEndpoint)
+		case "persistent_keepalive":
+			rules[k] = validate.Optional(validate.IsInRange(0, 65535))
+		}
+	}
+
+	// Validate the configuration.
+	err := n.validate(config, rules)
+	if err != nil {
+		return err
+	}
+
+	// Check that the OVN ranges are within the uplink gateway subnets.
+	for _, family := range []string{"ipv4", "ipv6"} {
+		if config[family+".ovn.ranges"] == "" {
+			continue
+		}
+
+		_, gatewaySubnet, err := net.ParseCIDR(config[family+".gateway"])
+		if err != nil {
+			return fmt.Errorf("%q requires %q to be set", family+".ovn.ranges", family+".gateway")
+		}
+
+		_, err = shared.ParseIPRanges(config[family+".ovn.ranges"], gatewaySubnet)
+		if err != nil {
+			return fmt.Errorf("Invalid %q: %w", family+".ovn.ranges", err)
+		}
+	}
+
+	// Check that each static peer has a public key and allowed IPs.
+	for _, peer := range n.staticPeers(config) {
+		if peer.PublicKey == "" || len(peer.AllowedIPs) == 0 {
+			return fmt.Errorf("Static peers require both a public key and allowed IPs")
+		}
+	}
+
+	return nil
+}
+
+// validateEndpoint validates the endpoint advertised to the other cluster members.
+func (n *wireguard) validateEndpoint(value string) error {
+	host, port, err := net.SplitHostPort(value)
+	if err != nil {
+		host = value
+	} else {
+		err = validate.IsNetworkPort(port)
+		if err != nil {
+			return err
+		}
+	}
+
+	if strings.Trim(host, "[]") == "" {
+		return fmt.Errorf("Invalid endpoint %q", value)
+	}
+
+	return nil
+}
+
+// staticPeers returns the static peers defined in the supplied config keyed on peer name.
+func (n *wireguard) staticPeers(config map[string]string) map[string]wireguardPeer {
+	peers := map[string]wireguardPeer{}
+
+	for k, v := range config {
+		if !strings.HasPrefix(k, "peers.") {
+			continue
+		}
+
+		fields := strings.Split(k, ".")
+		if len(fields) != 3 {
+			continue
+		}
+
+		peer := peers[fields[1]]
+
+		switch fields[2] {
+		case "public_key":
+			peer.PublicKey = v
+		case "allowed_ips":
+			peer.AllowedIPs = shared.SplitNTrimSpace(v, ",", -1, true)
+		case "endpoint":
+			peer.Endpoint = v
+		case "persistent_keepalive":
+			peer.PersistentKeepalive = v
+		}
+
+		peers[fields[1]] = peer
+	}
+
+	return peers
+}
+
+// keyPath returns the path of the private key of the network on this member.
+func (n *wireguard) keyPath() string {
+	return shared.VarPath("networks", n.name, "wireguard.key")
+}
+
+// wireguardUplinkBridgeName returns the name of the local bridge used to connect OVN networks to the network.
+func wireguardUplinkBridgeName(networkID int64) string {
+	return fmt.Sprintf("lxdwg%d", networkID)
+}
+
+// uplinkGateways returns the OVN uplink gateway addresses in CIDR format.
+func (n *wireguard) uplinkGateways(config map[string]string) []string {
+	gateways := []string{}
+	for _, key := range []string{"ipv4.gateway", "ipv6.gateway"} {
+		if config[key] != "" {
+			gateways = append(gateways, config[key])
+		}
+	}
+
+	return gateways
+}
+
+// port returns the UDP port the interface listens on.
+func (n *wireguard) port() string {
+	if n.config["wireguard.port"] != "" {
+		return n.config["wireguard.port"]
+	}
+
+	return wireguardDefaultPort
+}
+
+// tunnelAddresses returns the addresses of this member on the tunnel in CIDR format.
+func (n *wireguard) tunnelAddresses(config map[string]string) ([]string, error) {
+	addresses := []string{}
+	memberID := n.state.DB.Cluster.GetNodeID()
+
+	for _, key := range []string{"ipv4.address", "ipv6.address"} {
+		if config[key] == "" {
+			continue
+		}
+
+		_, subnet, err := net.ParseCIDR(config[key])
+		if err != nil {
+			return nil, fmt.Errorf("Failed parsing %q: %w", key, err)
+		}
+
+		address := dhcpalloc.GetIP(subnet, memberID)
+		if !SubnetContainsIP(subnet, address) || (subnet.IP.To4() != nil && address.Equal(dhcpalloc.GetIP(subnet, -1))) {
+			return nil, fmt.Errorf("Subnet %q is too small to allocate an address for member ID %d", config[key], memberID)
+		}
+
+		ones, _ := subnet.Mask.Size()
+		addresses = append(addresses, fmt.Sprintf("%s/%d", address.String(), ones))
+	}
+
+	return addresses, nil
+}
+
+// localEndpoint returns the endpoint advertised to the other cluster members.
+func (n *wireguard) localEndpoint() string {
+	if n.config["wireguard.endpoint"] != "" {
+		return wireguardEndpoint(n.config["wireguard.endpoint"], n.port())
+	}
+
+	host, _, err := net.SplitHostPort(n.state.LocalConfig.ClusterAddress())
+	if err != nil {
+		return ""
+	}
+
+	return net.JoinHostPort(host, n.port())
+}
+
+// localAllowedIPs returns the addresses that the other cluster members should route to this member.
+// This includes the tunnel addresses of the member and the addresses of the routed NICs using the network.
+func (n *wireguard) localAllowedIPs() ([]string, error) {
+	allowedIPs := []string{}
+
+	addresses, err := n.tunnelAddresses(n.config)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, address := range addresses {
+		addr, _, err := net.ParseCIDR(address)
+		if err != nil {
+			return nil, err
+		}
+
+		allowedIPs = append(allowedIPs, wireguardHostRoute(addr))
+	}
+
+	neighProxy := &ip.NeighProxy{DevName: n.name}
+	entries, err := neighProxy.Show()
+	if err != nil {
+		return nil, fmt.Errorf("Failed listing neighbour proxy entries on %q: %w", n.name, err)
+	}
+
+	for _, entry := range entries {
+		allowedIPs = append(allowedIPs, wireguardHostRoute(entry.Addr))
+	}
+
+	routerIPs, err := n.localOVNRouterIPs()
+	if err != nil {
+		return nil, err
+	}
+
+	allowedIPs = append(allowedIPs, routerIPs...)
+
+	return allowedIPs, nil
+}
+
+// localOVNRouterIPs returns the uplink addresses of the OVN networks using the network as uplink whose
+// external router port is active on this member.
+func (n *wireguard) localOVNRouterIPs() ([]string, error) {
+	// Uplink networks are always in the default project.
+	if n.project != api.ProjectDefaultName || len(n.uplinkGateways(n.config)) == 0 {
+		return nil, nil
+	}
+
+	var err error
+	var projectNetworks map[string]map[int64]api.Network
+
+	err = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		projectNetworks, err = tx.GetCreatedNetworks(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load all networks: %w", err)
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, err
+	}
+
+	var client *openvswitch.OVN
+	routerIPs := []string{}
+
+	for projectName, networks := range projectNetworks {
+		for _, network := range networks {
+			if network.Type != "ovn" || network.Config["network"] != n.name {
+				continue
+			}
+
+			if client == nil {
+				client, err = openvswitch.NewOVN(n.state)
+				if err != nil {
+					return nil, fmt.Errorf("Failed to get OVN client: %w", err)
+				}
+			}
+
+			netw, err := LoadByName(n.state, projectName, network.Name)
+			if err != nil {
+				return nil, fmt.Errorf("Failed loading network %q in project %q: %w", network.Name, projectName, err)
+			}
+
+			ovnNet, ok := netw.(*ovn)
+			if !ok {
+				continue
+			}
+
+			// Only advertise the routers that are reached through this member.
+			chassis, err := client.GetLogicalRouterPortActiveChassisHostname(ovnNet.getRouterExtPortName())
+			if err != nil {
+				n.logger.Warn("Failed getting active chassis of OVN network", logger.Ctx{"project": projectName, "network": network.Name, "err": err})
+				continue
+			}
+
+			if chassis != hostname {
+				continue
+			}
+
+			for _, key := range []string{ovnVolatileUplinkIPv4, ovnVolatileUplinkIPv6} {
+				routerIP := net.ParseIP(network.Config[key])
+				if routerIP != nil {
+					routerIPs = append(routerIPs, wireguardHostRoute(routerIP))
+				}
+			}
+		}
+	}
+
+	return routerIPs, nil
+}
+
+// wireguardHostRoute returns the single host subnet for the IP supplied.
+func wireguardHostRoute(addr net.IP) string {
+	if addr.To4() != nil {
+		return fmt.Sprintf("%s/32", addr.String())
+	}
+
+	return fmt.Sprintf("%s/128", addr.String())
+}
+
+// Create checks that the interface name isn't already in use on this member.
+func (n *wireguard) Create(clientType request.ClientType) error {
+	n.logger.Debug("Create", logger.Ctx{"clientType": clientType, "config": n.config})
+
+	if InterfaceExists(n.name) {
+		return fmt.Errorf("Network interface %q already exists", n.name)
+	}
+
+	return nil
+}
+
+// Delete deletes a network.
+func (n *wireguard) Delete(clientType request.ClientType) error {
+	n.logger.Debug("Delete", logger.Ctx{"clientType": clientType})
+
+	err := n.Stop()
+	if err != nil {
+		return err
+	}
+
+	// Delete the private key along with the rest of the network's directory.
+	return n.common.delete()
+}
+
+// Rename renames a network.
+func (n *wireguard) Rename(newName string) error {
+	n.logger.Debug("Rename", logger.Ctx{"newName": newName})
+
+	if InterfaceExists(newName) {
+		return fmt.Errorf("Network interface %q already exists", newName)
+	}
+
+	// Bring the network down.
+	running := InterfaceExists(n.name)
+	if running {
+		err := n.Stop()
+		if err != nil {
+			return err
+		}
+	}
+
+	// Rename common steps.
+	err := n.common.rename(newName)
+	if err != nil {
+		return err
+	}
+
+	// Bring the network up.
+	if running {
+		err = n.Start()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Start creates the WireGuard interface and configures its static peers.
+func (n *wireguard) Start() error {
+	n.logger.Debug("Start")
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	revert.Add(func() { n.setUnavailable() })
+
+	err := n.setup(nil)
+	if err != nil {
+		return err
+	}
+
+	revert.Success()
+
+	// Ensure network is marked as available now its started.
+	n.setAvailable()
+
+	return nil
+}
+
+func (n *wireguard) setup(oldConfig map[string]string) error {
+	revert := revert.New()
+	defer revert.Fail()
+
+	// Create directory holding the private key.
+	if !shared.PathExists(shared.VarPath("networks", n.name)) {
+		err := os.MkdirAll(shared.VarPath("networks", n.name), 0711)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := wireguardLoadOrCreateKey(n.keyPath())
+	if err != nil {
+		return err
+	}
+
+	// Create the interface if needed.
+	link := &ip.Wireguard{Link: ip.Link{Name: n.name}}
+	if !InterfaceExists(n.name) {
+		err = link.Add()
+		if err != nil {
+			return fmt.Errorf("Failed creating WireGuard interface %q: %w", n.name, err)
+		}
+
+		revert.Add(func() { _ = InterfaceRemove(n.name) })
+	}
+
+	// Set the MTU.
+	mtu := uint64(wireguardDefaultMTU)
+	if n.config["mtu"] != "" {
+		mtu, err = strconv.ParseUint(n.config["mtu"], 10, 32)
+		if err != nil {
+			return fmt.Errorf("Invalid MTU %q: %w", n.config["mtu"], err)
+		}
+	}
+
+	err = link.SetMTU(uint32(mtu))
+	if err != nil {
+		return fmt.Errorf("Failed setting MTU %d on %q: %w", mtu, n.name, err)
+	}
+
+	err = wireguardSetDevice(n.name, n.keyPath(), n.port())
+	if err != nil {
+		return err
+	}
+
+	// Configure the tunnel addresses.
+	addresses, err := n.tunnelAddresses(n.config)
+	if err != nil {
+		return err
+	}
+
+	addr := &ip.Addr{DevName: n.name, Scope: "global"}
+	err = addr.Flush()
+	if err != nil {
+		return fmt.Errorf("Failed flushing addresses on %q: %w", n.name, err)
+	}
+
+	for _, address := range addresses {
+		family := ip.FamilyV4
+		if strings.Contains(address, ":") {
+			family = ip.FamilyV6
+		}
+
+		addr := &ip.Addr{DevName: n.name, Address: address, Family: family}
+		err = addr.Add()
+		if err != nil {
+			return fmt.Errorf("Failed adding address %q to %q: %w", address, n.name, err)
+		}
+	}
+
+	// Allow forwarding and neighbour proxying so the network can be used as parent of routed NICs.
+	sysctls := map[string]string{
+		fmt.Sprintf("net/ipv4/conf/%s/forwarding", n.name): "1",
+		fmt.Sprintf("net/ipv6/conf/%s/forwarding", n.name): "1",
+		fmt.Sprintf("net/ipv6/conf/%s/proxy_ndp", n.name):  "1",
+	}
+
+	for path, value := range sysctls {
+		err = util.SysctlSet(path, value)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = link.SetUp()
+	if err != nil {
+		return fmt.Errorf("Failed bringing up %q: %w", n.name, err)
+	}
+
+	err = n.uplinkBridgeSetup(uint32(mtu))
+	if err != nil {
+		return err
+	}
+
+	// Remove the static peers that are no longer configured.
+	newPeers := n.staticPeers(n.config)
+	for peerName, oldPeer := range n.staticPeers(oldConfig) {
+		newPeer, found := newPeers[peerName]
+		if found && newPeer.PublicKey == oldPeer.PublicKey {
+			continue
+		}
+
+		err = wireguardRemovePeer(n.name, oldPeer.PublicKey)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Configure the static peers.
+	for _, peer := range newPeers {
+		err = wireguardSetPeer(n.name, peer)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = n.routesSync()
+	if err != nil {
+		return err
+	}
+
+	revert.Success()
+	return nil
+}
+
+// uplinkBridgeSetup creates the local bridge holding the OVN uplink gateway addresses when the gateways are set
+// and removes it otherwise.
+func (n *wireguard) uplinkBridgeSetup(mtu uint32) error {
+	bridgeName := wireguardUplinkBridgeName(n.id)
+	gateways := n.uplinkGateways(n.config)
+
+	if len(gateways) == 0 {
+		if InterfaceExists(bridgeName) {
+			err := InterfaceRemove(bridgeName)
+			if err != nil {
+				return fmt.Errorf("Failed removing uplink bridge %q: %w", bridgeName, err)
+			}
+		}
+
+		return nil
+	}
+
+	bridgeLink := &ip.Bridge{Link: ip.Link{Name: bridgeName}}
+	if !InterfaceExists(bridgeName) {
+		err := bridgeLink.Add()
+		if err != nil {
+			return fmt.Errorf("Failed creating uplink bridge %q: %w", bridgeName, err)
+		}
+	}
+
+	err := bridgeLink.SetMTU(mtu)
+	if err != nil {
+		return fmt.Errorf("Failed setting MTU %d on %q: %w", mtu, bridgeName, err)
+	}
+
+	addr := &ip.Addr{DevName: bridgeName, Scope: "global"}
+	err = addr.Flush()
+	if err != nil {
+		return fmt.Errorf("Failed flushing addresses on %q: %w", bridgeName, err)
+	}
+
+	for _, gateway := range gateways {
+		family := ip.FamilyV4
+		if strings.Contains(gateway, ":") {
+			family = ip.FamilyV6
+		}
+
+		addr := &ip.Addr{DevName: bridgeName, Address: gateway, Family: family}
+		err = addr.Add()
+		if err != nil {
+			return fmt.Errorf("Failed adding address %q to %q: %w", gateway, bridgeName, err)
+		}
+	}
+
+	// Route the traffic of the OVN routers over the tunnel.
+	err = util.SysctlSet(
+		fmt.Sprintf("net/ipv4/conf/%s/forwarding", bridgeName), "1",
+		fmt.Sprintf("net/ipv6/conf/%s/forwarding", bridgeName), "1",
+	)
+	if err != nil {
+		return err
+	}
+
+	err = bridgeLink.SetUp()
+	if err != nil {
+		return fmt.Errorf("Failed bringing up %q: %w", bridgeName, err)
+	}
+
+	return nil
+}
+
+// routesSync ensures the subnets routed to the peers of the interface have a route via the interface.
+// Default routes are never added so that the peer endpoints remain reachable.
+func (n *wireguard) routesSync() error {
+	wgState, err := wireguardDeviceState(n.name)
+	if err != nil {
+		return err
+	}
+
+	wantRoutes := map[string][]string{ip.FamilyV4: {}, ip.FamilyV6: {}}
+	for _, peer := range wgState.Peers {
+		for _, allowedIP := range peer.AllowedIPs {
+			_, subnet, err := net.ParseCIDR(allowedIP)
+			if err != nil {
+				continue
+			}
+
+			ones, _ := subnet.Mask.Size()
+			if ones == 0 {
+				continue
+			}
+
+			family := ip.FamilyV4
+			if subnet.IP.To4() == nil {
+				family = ip.FamilyV6
+			}
+
+			wantRoutes[family] = append(wantRoutes[family], subnet.String())
+		}
+	}
+
+	for family, routes := range wantRoutes {
+		r := &ip.Route{DevName: n.name, Proto: "static", Family: family}
+
+		existingRoutes, err := r.Show()
+		if err != nil {
+			return fmt.Errorf("Failed listing routes on %q: %w", n.name, err)
+		}
+
+		// Remove stale routes.
+		for _, existingRoute := range existingRoutes {
+			fields := strings.Fields(existingRoute)
+			if len(fields) == 0 {
+				continue
+			}
+
+			existingSubnet := fields[0]
+			if !strings.Contains(existingSubnet, "/") {
+				existingIP := net.ParseIP(existingSubnet)
+				if existingIP == nil {
+					continue
+				}
+
+				existingSubnet = wireguardHostRoute(existingIP)
+			}
+
+			if shared.ValueInSlice(existingSubnet, routes) {
+				continue
+			}
+
+			staleRoute := &ip.Route{DevName: n.name, Route: existingSubnet, Proto: "static", Family: family}
+			err = staleRoute.Flush()
+			if err != nil {
+				return fmt.Errorf("Failed removing route %q from %q: %w", existingSubnet, n.name, err)
+			}
+		}
+
+		for _, route := range routes {
+			err = r.Replace([]string{route})
+			if err != nil {
+				return fmt.Errorf("Failed adding route %q to %q: %w", route, n.name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Stop removes the WireGuard interface.
+func (n *wireguard) Stop() error {
+	n.logger.Debug("Stop")
+
+	bridgeName := wireguardUplinkBridgeName(n.id)
+	if InterfaceExists(bridgeName) {
+		err := InterfaceRemove(bridgeName)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !InterfaceExists(n.name) {
+		return nil
+	}
+
+	err := InterfaceRemove(n.name)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DHCPv4Subnet returns the IPv4 subnet of the OVN uplink.
+func (n *wireguard) DHCPv4Subnet() *net.IPNet {
+	_, subnet, err := net.ParseCIDR(n.config["ipv4.gateway"])
+	if err != nil {
+		return nil
+	}
+
+	return subnet
+}
+
+// DHCPv6Subnet returns the IPv6 subnet of the OVN uplink.
+func (n *wireguard) DHCPv6Subnet() *net.IPNet {
+	_, subnet, err := net.ParseCIDR(n.config["ipv6.gateway"])
+	if err != nil {
+		return nil
+	}
+
+	return subnet
+}
+
+// Update updates the network. Accepts notification boolean indicating if this update request is coming from a
+// cluster notification, in which case do not update the database, just apply local changes needed.
+func (n *wireguard) Update(newNetwork api.NetworkPut, targetNode string, clientType request.ClientType) error {
+	n.logger.Debug("Update", logger.Ctx{"clientType": clientType, "newNetwork": newNetwork})
+
+	dbUpdateNeeded, _, oldNetwork, err := n.common.configChanged(newNetwork)
+	if err != nil {
+		return err
+	}
+
+	if !dbUpdateNeeded {
+		return nil // Nothing changed.
+	}
+
+	// If the network as a whole has not had any previous creation attempts, or the node itself is still
+	// pending, then don't apply the new settings to the node, just to the database record (ready for the
+	// actual global create request to be initiated).
+	if n.Status() == api.NetworkStatusPending || n.LocalStatus() == api.NetworkStatusPending {
+		return n.common.update(newNetwork, targetNode, clientType)
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	// Define a function which reverts everything.
+	revert.Add(func() {
+		// Reset changes to all nodes and database.
+		_ = n.common.update(oldNetwork, targetNode, clientType)
+	})
+
+	// Apply changes to all nodes and databse.
+	err = n.common.update(newNetwork, targetNode, clientType)
+	if err != nil {
+		return err
+	}
+
+	err = n.setup(oldNetwork.Config)
+	if err != nil {
+		return err
+	}
+
+	revert.Success()
+	return nil
+}
+
+// HandleHeartbeat peers the WireGuard interface with the same network on the other cluster members.
+// Peers that are neither configured as static peers nor found on an online cluster member are removed.
+func (n *wireguard) HandleHeartbeat(heartbeatData *cluster.APIHeartbeat) error {
+	// Make sure the network has been setup.
+	if !InterfaceExists(n.name) {
+		return nil
+	}
+
+	wantPeers := map[string]wireguardPeer{}
+	for _, peer := range n.staticPeers(n.config) {
+		wantPeers[peer.PublicKey] = peer
+	}
+
+	// Don't remove peers if the state of some members couldn't be retrieved.
+	removeStale := true
+
+	if shared.IsTrueOrEmpty(n.config["wireguard.cluster_peering"]) {
+		localClusterAddress := n.state.LocalConfig.ClusterAddress()
+		networkCert := n.state.Endpoints.NetworkCert()
+
+		for _, node := range heartbeatData.Members {
+			if node.Address == localClusterAddress {
+				// No need to query ourselves.
+				continue
+			}
+
+			if !node.Online {
+				n.logger.Warn("Excluding offline member from WireGuard peers refresh", logger.Ctx{"address": node.Address, "ID": node.ID, "raftID": node.RaftID, "lastHeartbeat": node.LastHeartbeat})
+				removeStale = false
+				continue
+			}
+
+			client, err := cluster.Connect(node.Address, networkCert, n.state.ServerCert(), nil, true)
+			if err != nil {
+				n.logger.Warn("Failed connecting to member for WireGuard peers refresh", logger.Ctx{"address": node.Address, "err": err})
+				removeStale = false
+				continue
+			}
+
+			// Keep peering with the other members if one of them can't be queried.
+			state, err := client.UseProject(n.project).GetNetworkState(n.name)
+			if err != nil {
+				n.logger.Warn("Failed getting WireGuard state of member", logger.Ctx{"address": node.Address, "err": err})
+				removeStale = false
+				continue
+			}
+
+			// Skip members where the network isn't setup yet.
+			if state.WireGuard == nil || state.WireGuard.PublicKey == "" {
+				removeStale = false
+				continue
+			}
+
+			wantPeers[state.WireGuard.PublicKey] = wireguardPeer{
+				PublicKey:           state.WireGuard.PublicKey,
+				Endpoint:            state.WireGuard.Endpoint,
+				AllowedIPs:          state.WireGuard.AllowedIPs,
+				PersistentKeepalive: n.config["wireguard.persistent_keepalive"],
+			}
+		}
+	}
+
+	wgState, err := wireguardDeviceState(n.name)
+	if err != nil {
+		return err
+	}
+
+	curPeers := make(map[string]api.NetworkStateWireGuardPeer, len(wgState.Peers))
+	for _, peer := range wgState.Peers {
+		curPeers[peer.PublicKey] = peer
+	}
+
+	changed := false
+
+	// Remove the stale peers.
+	if removeStale {
+		for publicKey := range curPeers {
+			_, found := wantPeers[publicKey]
+			if found {
+				continue
+			}
+
+			err = wireguardRemovePeer(n.name, publicKey)
+			if err != nil {
+				return err
+			}
+
+			changed = true
+		}
+	}
+
+	// Add the new peers and update the allowed IPs and endpoints of the existing ones.
+	for publicKey, peer := range wantPeers {
+		curPeer, found := curPeers[publicKey]
+		if found {
+			curAllowedIPs := append([]string{}, curPeer.AllowedIPs...)
+			wantAllowedIPs := append([]string{}, peer.AllowedIPs...)
+			sort.Strings(curAllowedIPs)
+			sort.Strings(wantAllowedIPs)
+
+			if strings.Join(curAllowedIPs, ",") == strings.Join(wantAllowedIPs, ",") && !wireguardEndpointChanged(curPeer.Endpoint, peer.Endpoint) {
+				continue
+			}
+		}
+
+		err = wireguardSetPeer(n.name, peer)
+		if err != nil {
+			return err
+		}
+
+		changed = true
+	}
+
+	if !changed {
+		return nil
+	}
+
+	err = n.routesSync()
+	if err != nil {
+		return err
+	}
+
+	n.logger.Info("Updated WireGuard peers", logger.Ctx{"peers": len(wantPeers)})
+	return nil
+}
+
+// State returns the api.NetworkState for the network.
+func (n *wireguard) State() (*api.NetworkState, error) {
+	state, err := resources.GetNetworkState(n.name)
+	if err != nil {
+		// If the interface is not found, return a response indicating the network is unavailable.
+		if api.StatusErrorCheck(err, http.StatusNotFound) {
+			return &api.NetworkState{
+				State: "unavailable",
+				Type:  "unknown",
+			}, nil
+		}
+
+		// In all other cases, return the original error.
+		return nil, err
+	}
+
+	wgState, err := wireguardDeviceState(n.name)
+	if err != nil {
+		return nil, err
+	}
+
+	wgState.Endpoint = n.localEndpoint()
+	wgState.AllowedIPs, err = n.localAllowedIPs()
+	if err != nil {
+		return nil, err
+	}
+
+	state.WireGuard = wgState
+
+	state.BGP, err = n.bgpState()
+	if err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
@@ -0,0 +1,220 @@
+package flowlog
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"syscall"
+
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+)
+
+// Conntrack netlink multicast groups (from linux/netfilter/nfnetlink_compat.h).
+const (
+	nfnlGroupConntrackNew     = 1
+	nfnlGroupConntrackDestroy = 3
+)
+
+// Conntrack netlink subsystem and message types (from linux/netfilter/nfnetlink.h).
+const (
+	nfnlSubsysCTNetlink = 1
+	ipctnlMsgCTNew      = 0
+	ipctnlMsgCTDelete   = 2
+)
+
+// nlaTypeMask strips the nested and byte order flags from a netlink attribute type.
+const nlaTypeMask = ^uint16(unix.NLA_F_NESTED | unix.NLA_F_NET_BYTEORDER)
+
+// Action describes the conntrack event that produced a flow record.
+type Action string
+
+// Flow actions.
+const (
+	ActionNew Action = "new"
+	ActionEnd Action = "end"
+)
+
+// Tuple represents one direction of a connection.
+type Tuple struct {
+	Source          net.IP
+	Destination     net.IP
+	Protocol        uint8
+	SourcePort      uint16
+	DestinationPort uint16
+}
+
+// Counters represents the packet and byte counters of one direction of a connection.
+type Counters struct {
+	Packets uint64
+	Bytes   uint64
+}
+
+// Flow represents a conntrack event.
+type Flow struct {
+	Action Action
+	Zone   uint16
+
+	// Original is the tuple of the connection initiator, Reply the tuple of the responder.
+	Original Tuple
+	Reply    Tuple
+
+	// Counters are only populated on ActionEnd and require nf_conntrack_acct to be enabled.
+	OriginalCounters Counters
+	ReplyCounters    Counters
+}
+
+// ProtocolName returns the name of the flow's layer 4 protocol.
+func (f *Flow) ProtocolName() string {
+	switch f.Original.Protocol {
+	case unix.IPPROTO_TCP:
+		return "tcp"
+	case unix.IPPROTO_UDP:
+		return "udp"
+	case unix.IPPROTO_ICMP:
+		return "icmp"
+	case unix.IPPROTO_ICMPV6:
+		return "icmpv6"
+	case unix.IPPROTO_SCTP:
+		return "sctp"
+	}
+
+	return fmt.Sprintf("%d", f.Original.Protocol)
+}
+
+// parseMessage parses a conntrack netlink message.
+// Returns nil if the message isn't a conntrack new or destroy event.
+func parseMessage(msg syscall.NetlinkMessage) (*Flow, error) {
+	if msg.Header.Type>>8 != nfnlSubsysCTNetlink {
+		return nil, nil
+	}
+
+	flow := &Flow{}
+
+	switch msg.Header.Type & 0xff {
+	case ipctnlMsgCTNew:
+		// Updates to existing entries are also sent as new messages but without the create flag.
+		if msg.Header.Flags&unix.NLM_F_CREATE == 0 {
+			return nil, nil
+		}
+
+		flow.Action = ActionNew
+	case ipctnlMsgCTDelete:
+		flow.Action = ActionEnd
+	default:
+		return nil, nil
+	}
+
+	if len(msg.Data) < nl.SizeofNfgenmsg {
+		return nil, fmt.Errorf("Conntrack message too short")
+	}
+
+	attrs, err := nl.ParseRouteAttr(msg.Data[nl.SizeofNfgenmsg:])
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing conntrack attributes: %w", err)
+	}
+
+	for _, attr := range attrs {
+		switch attr.Attr.Type & nlaTypeMask {
+		case nl.CTA_TUPLE_ORIG:
+			err = parseTuple(attr.Value, &flow.Original)
+		case nl.CTA_TUPLE_REPLY:
+			err = parseTuple(attr.Value, &flow.Reply)
+		case nl.CTA_COUNTERS_ORIG:
+			err = parseCounters(attr.Value, &flow.OriginalCounters)
+		case nl.CTA_COUNTERS_REPLY:
+			err = parseCounters(attr.Value, &flow.ReplyCounters)
+		case nl.CTA_ZONE:
+			if len(attr.Value) >= 2 {
+				flow.Zone = binary.BigEndian.Uint16(attr.Value)
+			}
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if flow.Original.Source == nil || flow.Original.Destination == nil {
+		return nil, fmt.Errorf("Conntrack message missing original tuple")
+	}
+
+	return flow, nil
+}
+
+// parseTuple parses a nested CTA_TUPLE_* attribute.
+func parseTuple(data []byte, tuple *Tuple) error {
+	attrs, err := nl.ParseRouteAttr(data)
+	if err != nil {
+		return fmt.Errorf("Failed parsing conntrack tuple: %w", err)
+	}
+
+	for _, attr := range attrs {
+		switch attr.Attr.Type & nlaTypeMask {
+		case nl.CTA_TUPLE_IP:
+			ipAttrs, err := nl.ParseRouteAttr(attr.Value)
+			if err != nil {
+				return fmt.Errorf("Failed parsing conntrack tuple addresses: %w", err)
+			}
+
+			for _, ipAttr := range ipAttrs {
+				switch ipAttr.Attr.Type & nlaTypeMask {
+				case nl.CTA_IP_V4_SRC, nl.CTA_IP_V6_SRC:
+					tuple.Source = net.IP(ipAttr.Value)
+				case nl.CTA_IP_V4_DST, nl.CTA_IP_V6_DST:
+					tuple.Destination = net.IP(ipAttr.Value)
+				}
+			}
+
+		case nl.CTA_TUPLE_PROTO:
+			protoAttrs, err := nl.ParseRouteAttr(attr.Value)
+			if err != nil {
+				return fmt.Errorf("Failed parsing conntrack tuple protocol: %w", err)
+			}
+
+			for _, protoAttr := range protoAttrs {
+				switch protoAttr.Attr.Type & nlaTypeMask {
+				case nl.CTA_PROTO_NUM:
+					if len(protoAttr.Value) >= 1 {
+						tuple.Protocol = protoAttr.Value[0]
+					}
+
+				case nl.CTA_PROTO_SRC_PORT:
+					if len(protoAttr.Value) >= 2 {
+						tuple.SourcePort = binary.BigEndian.Uint16(protoAttr.Value)
+					}
+
+				case nl.CTA_PROTO_DST_PORT:
+					if len(protoAttr.Value) >= 2 {
+						tuple.DestinationPort = binary.BigEndian.Uint16(protoAttr.Value)
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// parseCounters parses a nested CTA_COUNTERS_* attribute.
+func parseCounters(data []byte, counters *Counters) error {
+	attrs, err := nl.ParseRouteAttr(data)
+	if err != nil {
+		return fmt.Errorf("Failed parsing conntrack counters: %w", err)
+	}
+
+	for _, attr := range attrs {
+		if len(attr.Value) < 8 {
+			continue
+		}
+
+		switch attr.Attr.Type & nlaTypeMask {
+		case nl.CTA_COUNTERS_PACKETS:
+			counters.Packets = binary.BigEndian.Uint64(attr.Value)
+		case nl.CTA_COUNTERS_BYTES:
+			counters.Bytes = binary.BigEndian.Uint64(attr.Value)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,135 @@
+package flowlog
+
+import (
+	"encoding/binary"
+	"net"
+	"syscall"
+	"testing"
+
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+)
+
+// attr encodes a netlink attribute.
+func attr(attrType uint16, value []byte) []byte {
+	length := unix.NLA_HDRLEN + len(value)
+	data := make([]byte, (length+unix.NLA_ALIGNTO-1)&^(unix.NLA_ALIGNTO-1))
+	nl.NativeEndian().PutUint16(data[0:2], uint16(length))
+	nl.NativeEndian().PutUint16(data[2:4], attrType)
+	copy(data[unix.NLA_HDRLEN:], value)
+
+	return data
+}
+
+// nested encodes a nested netlink attribute.
+func nested(attrType uint16, children ...[]byte) []byte {
+	value := []byte{}
+	for _, child := range children {
+		value = append(value, child...)
+	}
+
+	return attr(attrType|unix.NLA_F_NESTED, value)
+}
+
+func be16(v uint16) []byte {
+	data := make([]byte, 2)
+	binary.BigEndian.PutUint16(data, v)
+	return data
+}
+
+func be64(v uint64) []byte {
+	data := make([]byte, 8)
+	binary.BigEndian.PutUint64(data, v)
+	return data
+}
+
+func tuple(attrType uint16, src string, dst string, sport uint16, dport uint16) []byte {
+	return nested(attrType,
+		nested(nl.CTA_TUPLE_IP,
+			attr(nl.CTA_IP_V4_SRC, net.ParseIP(src).To4()),
+			attr(nl.CTA_IP_V4_DST, net.ParseIP(dst).To4()),
+		),
+		nested(nl.CTA_TUPLE_PROTO,
+			attr(nl.CTA_PROTO_NUM, []byte{unix.IPPROTO_TCP}),
+			attr(nl.CTA_PROTO_SRC_PORT, be16(sport)),
+			attr(nl.CTA_PROTO_DST_PORT, be16(dport)),
+		),
+	)
+}
+
+func message(msgType uint16, flags uint16, attrs ...[]byte) syscall.NetlinkMessage {
+	data := []byte{unix.AF_INET, 0, 0, 0}
+	for _, a := range attrs {
+		data = append(data, a...)
+	}
+
+	return syscall.NetlinkMessage{
+		Header: syscall.NlMsghdr{Type: nfnlSubsysCTNetlink<<8 | msgType, Flags: flags},
+		Data:   data,
+	}
+}
+
+func TestParseMessageDestroy(t *testing.T) {
+	msg := message(ipctnlMsgCTDelete, 0,
+		tuple(nl.CTA_TUPLE_ORIG, "10.0.0.2", "192.0.2.1", 40000, 443),
+		tuple(nl.CTA_TUPLE_REPLY, "192.0.2.1", "198.51.100.1", 443, 40000),
+		nested(nl.CTA_COUNTERS_ORIG, attr(nl.CTA_COUNTERS_PACKETS, be64(10)), attr(nl.CTA_COUNTERS_BYTES, be64(1200))),
+		nested(nl.CTA_COUNTERS_REPLY, attr(nl.CTA_COUNTERS_PACKETS, be64(8)), attr(nl.CTA_COUNTERS_BYTES, be64(9000))),
+		attr(nl.CTA_ZONE, be16(5)),
+	)
+
+	flow, err := parseMessage(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flow == nil {
+		t.Fatal("Expected a flow")
+	}
+
+	if flow.Action != ActionEnd || flow.Zone != 5 || flow.ProtocolName() != "tcp" {
+		t.Fatalf("Unexpected flow: %+v", flow)
+	}
+
+	if flow.Original.Source.String() != "10.0.0.2" || flow.Original.Destination.String() != "192.0.2.1" || flow.Original.SourcePort != 40000 || flow.Original.DestinationPort != 443 {
+		t.Fatalf("Unexpected original tuple: %+v", flow.Original)
+	}
+
+	if flow.Reply.Destination.String() != "198.51.100.1" {
+		t.Fatalf("Unexpected reply tuple: %+v", flow.Reply)
+	}
+
+	if flow.OriginalCounters.Bytes != 1200 || flow.OriginalCounters.Packets != 10 || flow.ReplyCounters.Bytes != 9000 || flow.ReplyCounters.Packets != 8 {
+		t.Fatalf("Unexpected counters: %+v %+v", flow.OriginalCounters, flow.ReplyCounters)
+	}
+}
+
+func TestParseMessageNew(t *testing.T) {
+	orig := tuple(nl.CTA_TUPLE_ORIG, "10.0.0.2", "10.0.0.3", 50000, 22)
+
+	flow, err := parseMessage(message(ipctnlMsgCTNew, unix.NLM_F_CREATE, orig))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flow == nil || flow.Action != ActionNew {
+		t.Fatalf("Expected a new flow, got %+v", flow)
+	}
+
+	// Updates to existing entries are ignored.
+	flow, err = parseMessage(message(ipctnlMsgCTNew, 0, orig))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flow != nil {
+		t.Fatalf("Expected update to be ignored, got %+v", flow)
+	}
+}
+
+func TestParseMessageInvalid(t *testing.T) {
+	_, err := parseMessage(message(ipctnlMsgCTDelete, 0))
+	if err == nil {
+		t.Fatal("Expected error for message without original tuple")
+	}
+}
@@ -0,0 +1,117 @@
+package flowlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+const conntrackAcctSysctl = "net/netfilter/nf_conntrack_acct"
+
+// accountingMu protects accountingUsers and accountingPrevious.
+var accountingMu sync.Mutex
+
+// accountingUsers is the number of running monitors relying on conntrack accounting.
+var accountingUsers int
+
+// accountingPrevious is the value of the conntrack accounting sysctl before the first monitor enabled it.
+var accountingPrevious string
+
+// accountingEnable enables conntrack accounting, recording its previous value when no other monitor is running.
+func accountingEnable() {
+	accountingMu.Lock()
+	defer accountingMu.Unlock()
+
+	accountingUsers++
+	if accountingUsers > 1 {
+		return
+	}
+
+	previous, err := util.SysctlGet(conntrackAcctSysctl)
+	if err != nil {
+		logger.Warn("Failed getting conntrack accounting, flow byte counts will be unavailable", logger.Ctx{"err": err})
+		return
+	}
+
+	accountingPrevious = strings.TrimSpace(previous)
+
+	err = util.SysctlSet(conntrackAcctSysctl, "1")
+	if err != nil {
+		logger.Warn("Failed enabling conntrack accounting, flow byte counts will be unavailable", logger.Ctx{"err": err})
+	}
+}
+
+// accountingRestore restores conntrack accounting to its previous value when the last monitor stops.
+func accountingRestore() {
+	accountingMu.Lock()
+	defer accountingMu.Unlock()
+
+	accountingUsers--
+	if accountingUsers > 0 || accountingPrevious == "" {
+		return
+	}
+
+	err := util.SysctlSet(conntrackAcctSysctl, accountingPrevious)
+	if err != nil {
+		logger.Warn("Failed restoring conntrack accounting", logger.Ctx{"err": err, "value": accountingPrevious})
+	}
+
+	accountingPrevious = ""
+}
+
+// Monitor subscribes to the conntrack event stream and calls handler for each new and closed connection.
+// It blocks until the context is cancelled or the subscription fails.
+// Conntrack accounting is enabled on the host while monitors are running and restored when the last one stops.
+func Monitor(ctx context.Context, handler func(flow *Flow)) error {
+	// Byte and packet counters are only included in destroy events when accounting is enabled.
+	accountingEnable()
+	defer accountingRestore()
+
+	sock, err := nl.Subscribe(unix.NETLINK_NETFILTER, nfnlGroupConntrackNew, nfnlGroupConntrackDestroy)
+	if err != nil {
+		return fmt.Errorf("Failed subscribing to conntrack events: %w", err)
+	}
+
+	// Closing the socket unblocks any pending receive.
+	go func() {
+		<-ctx.Done()
+		sock.Close()
+	}()
+
+	for {
+		msgs, _, err := sock.Receive()
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		if err != nil {
+			// The kernel drops events when the receive buffer overflows, keep going with the next ones.
+			if errors.Is(err, unix.ENOBUFS) {
+				logger.Warn("Conntrack event buffer overflow, some flows were not logged")
+				continue
+			}
+
+			return fmt.Errorf("Failed receiving conntrack events: %w", err)
+		}
+
+		for _, msg := range msgs {
+			flow, err := parseMessage(msg)
+			if err != nil {
+				logger.Debug("Failed parsing conntrack event", logger.Ctx{"err": err})
+				continue
+			}
+
+			if flow != nil {
+				handler(flow)
+			}
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package network
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/canonical/lxd/lxd/network/flowlog"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// flowLogCacheExpiry is how long the instance attribution data of a network is used before being refreshed.
+const flowLogCacheExpiry = 10 * time.Second
+
+// flowLogHandlers holds the flow handlers of the networks with flow logging enabled keyed on project and name.
+var flowLogHandlers = map[string]func(flow *flowlog.Flow){}
+var flowLogMu sync.Mutex
+
+// flowLogCancel stops the conntrack monitor, it is nil when the monitor isn't running.
+var flowLogCancel context.CancelFunc
+
+// flowLogInstance identifies the instance a connection belongs to.
+type flowLogInstance struct {
+	project   string
+	name      string
+	addresses []string
+}
+
+// flowLogCache holds the instance attribution data of a network and refreshes it in the background once expired.
+// This avoids querying the database while handling conntrack events.
+type flowLogCache[K comparable] struct {
+	mu         sync.Mutex
+	load       func() (map[K]flowLogInstance, error)
+	entries    map[K]flowLogInstance
+	expiry     time.Time
+	refreshing bool
+}
+
+// get returns the instance for the key, triggering a refresh of the cache if it has expired.
+func (c *flowLogCache[K]) get(key K) (flowLogInstance, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.refreshing && time.Now().After(c.expiry) {
+		c.refreshing = true
+
+		go func() {
+			entries, err := c.load()
+			if err != nil {
+				logger.Warn("Failed refreshing flow log instances", logger.Ctx{"err": err})
+			}
+
+			c.mu.Lock()
+			defer c.mu.Unlock()
+
+			if err == nil {
+				c.entries = entries
+			}
+
+			c.expiry = time.Now().Add(flowLogCacheExpiry)
+			c.refreshing = false
+		}()
+	}
+
+	inst, found := c.entries[key]
+
+	return inst, found
+}
+
+// flowLogRecord returns the flow event for the instance side of a connection.
+// The instance is the initiator of the connection when egress is true.
+func flowLogRecord(networkName string, inst flowLogInstance, flow *flowlog.Flow, egress bool) api.EventNetworkFlow {
+	record := api.EventNetworkFlow{
+		Action:          string(flow.Action),
+		Network:         networkName,
+		Project:         inst.project,
+		Instance:        inst.name,
+		Direction:       "ingress",
+		Protocol:        flow.ProtocolName(),
+		Source:          flow.Original.Source.String(),
+		Destination:     flow.Original.Destination.String(),
+		BytesSent:       flow.ReplyCounters.Bytes,
+		BytesReceived:   flow.OriginalCounters.Bytes,
+		PacketsSent:     flow.ReplyCounters.Packets,
+		PacketsReceived: flow.OriginalCounters.Packets,
+	}
+
+	if egress {
+		record.Direction = "egress"
+		record.BytesSent, record.BytesReceived = record.BytesReceived, record.BytesSent
+		record.PacketsSent, record.PacketsReceived = record.PacketsReceived, record.PacketsSent
+	}
+
+	// Ports are only meaningful for the protocols that have them (for ICMP they carry the ID, type and code).
+	switch record.Protocol {
+	case "tcp", "udp", "sctp":
+		record.SourcePort = flow.Original.SourcePort
+		record.DestinationPort = flow.Original.DestinationPort
+	}
+
+	return record
+}
+
+// flowLogRegister starts passing the conntrack events to the handler of the network.
+// The conntrack monitor is started with the first registered network.
+func flowLogRegister(n Network, handler func(flow *flowlog.Flow)) {
+	flowLogMu.Lock()
+	defer flowLogMu.Unlock()
+
+	flowLogHandlers[n.Project()+"/"+n.Name()] = handler
+
+	if flowLogCancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	flowLogCancel = cancel
+
+	go func() {
+		err := flowlog.Monitor(ctx, func(flow *flowlog.Flow) {
+			flowLogMu.Lock()
+			handlers := make([]func(flow *flowlog.Flow), 0, len(flowLogHandlers))
+			for _, handler := range flowLogHandlers {
+				handlers = append(handlers, handler)
+			}
+
+			flowLogMu.Unlock()
+
+			for _, handler := range handlers {
+				handler(flow)
+			}
+		})
+		if err != nil {
+			logger.Error("Network flow logging stopped", logger.Ctx{"err": err})
+		}
+
+		// Allow the monitor to be started again by the next registration.
+		flowLogMu.Lock()
+		if ctx.Err() == nil {
+			flowLogCancel = nil
+		}
+
+		flowLogMu.Unlock()
+		cancel()
+	}()
+}
+
+// flowLogUnregister stops passing the conntrack events to the network.
+// The conntrack monitor is stopped with the last unregistered network.
+func flowLogUnregister(n Network) {
+	flowLogMu.Lock()
+	defer flowLogMu.Unlock()
+
+	delete(flowLogHandlers, n.Project()+"/"+n.Name())
+
+	if len(flowLogHandlers) == 0 && flowLogCancel != nil {
+		flowLogCancel()
+		flowLogCancel = nil
+	}
+}
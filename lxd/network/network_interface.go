@@ -69,8 +69,13 @@ type Network interface {
 	LoadBalancerDelete(listenAddress string, clientType request.ClientType) error
 
 	// Peerings.
-	PeerCreate(forward api.NetworkPeersPost) error
+	PeerCreate(peer api.NetworkPeersPost, clientType request.ClientType) error
 	PeerUpdate(peerName string, newPeer api.NetworkPeerPut) error
-	PeerDelete(peerName string) error
+	PeerDelete(peerName string, clientType request.ClientType) error
 	PeerUsedBy(peerName string) ([]string, error)
+
+	// DHCP Reservations.
+	ReservationCreate(reservation api.NetworkReservationsPost, clientType request.ClientType) error
+	ReservationUpdate(address string, newReservation api.NetworkReservationPut, clientType request.ClientType) error
+	ReservationDelete(address string, clientType request.ClientType) error
 }
@@ -5,12 +5,15 @@ import (
 	"bytes"
 	"context"
 	cryptorand "crypto/rand"
+	"encoding/binary"
 	"encoding/hex"
 	"fmt"
 	"math/big"
 	"math/rand"
 	"net"
+	"net/http"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
@@ -431,6 +434,7 @@ func UpdateDNSMasqStatic(s *state.State, networkName string) error {
 
 	// Build a list of dhcp host entries.
 	entries := map[string][][]string{}
+	dhcpOptions := map[string][]string{}
 	for _, inst := range insts {
 		// Go through all its devices (including profiles).
 		for deviceName, d := range inst.ExpandedDevices() {
@@ -483,6 +487,7 @@ func UpdateDNSMasqStatic(s *state.State, networkName string) error {
 			}
 
 			entries[d["parent"]] = append(entries[d["parent"]], []string{d["hwaddr"], inst.Project().Name, inst.Name(), d["ipv4.address"], d["ipv6.address"], deviceName})
+			dhcpOptions[dnsmasq.StaticAllocationFileName(inst.Project().Name, inst.Name(), deviceName)] = DHCPOptionsDNSMasq(d)
 		}
 	}
 
@@ -516,6 +521,18 @@ func UpdateDNSMasqStatic(s *state.State, networkName string) error {
 			}
 		}
 
+		files, err = os.ReadDir(dnsmasq.OptionsPath(network))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		for _, entry := range files {
+			err = os.Remove(filepath.Join(dnsmasq.OptionsPath(network), entry.Name()))
+			if err != nil {
+				return err
+			}
+		}
+
 		// Apply the changes.
 		for entryIdx, entry := range entries {
 			hwaddr := entry[0]
@@ -560,7 +577,7 @@ func UpdateDNSMasqStatic(s *state.State, networkName string) error {
 			}
 
 			// Generate the dhcp-host line.
-			err := dnsmasq.UpdateStaticEntry(network, projectName, cName, deviceName, config, hwaddr, ipv4Address, ipv6Address)
+			err := dnsmasq.UpdateStaticEntry(network, projectName, cName, deviceName, config, hwaddr, ipv4Address, ipv6Address, dhcpOptions[dnsmasq.StaticAllocationFileName(projectName, cName, deviceName)])
 			if err != nil {
 				return err
 			}
@@ -1048,6 +1065,76 @@ func GetMACSlice(hwaddr string) []string {
 	return buf
 }
 
+// projectRestrictedSubnets parses the restrict.networks.subnets project setting and returns slice of *net.IPNet.
+// Returns nil slice if no project restrictions, or empty slice if no allowed subnets.
+func projectRestrictedSubnets(p *api.Project, uplinkNetworkName string) ([]*net.IPNet, error) {
+	// Parse project's restricted subnets.
+	var projectRestrictedSubnets []*net.IPNet // Nil value indicates not restricted.
+	if shared.IsTrue(p.Config["restricted"]) && p.Config["restricted.networks.subnets"] != "" {
+		projectRestrictedSubnets = []*net.IPNet{} // Empty slice indicates no allowed subnets.
+
+		for _, subnetRaw := range shared.SplitNTrimSpace(p.Config["restricted.networks.subnets"], ",", -1, false) {
+			subnetParts := strings.SplitN(subnetRaw, ":", 2)
+			if len(subnetParts) != 2 {
+				return nil, fmt.Errorf(`Project subnet %q invalid, must be in the format of "<uplink network>:<subnet>"`, subnetRaw)
+			}
+
+			subnetUplinkName := subnetParts[0]
+			subnetStr := subnetParts[1]
+
+			if subnetUplinkName != uplinkNetworkName {
+				continue // Only include subnets for our uplink.
+			}
+
+			_, restrictedSubnet, err := net.ParseCIDR(subnetStr)
+			if err != nil {
+				return nil, err
+			}
+
+			projectRestrictedSubnets = append(projectRestrictedSubnets, restrictedSubnet)
+		}
+	}
+
+	return projectRestrictedSubnets, nil
+}
+
+// natAddressesCheckNotInUse checks that none of the SNAT addresses of an instance NIC is used as the listen address
+// of a network forward or load balancer, as the SNAT address of another network or as the SNAT address of a NIC of
+// another project. The NICs of the same project can share SNAT addresses.
+func natAddressesCheckNotInUse(externalSubnetsInUse []externalSubnetUsage, networkProject string, networkName string, instanceProject string, natAddresses []net.IP) error {
+	for _, natAddress := range natAddresses {
+		natIPNet := IPToNet(natAddress)
+
+		for _, externalSubnetUser := range externalSubnetsInUse {
+			switch externalSubnetUser.usageType {
+			case subnetUsageNetworkForward, subnetUsageNetworkLoadBalancer:
+			case subnetUsageNetworkSNAT, subnetUsageVolatileIP:
+				// Skip our own network's SNAT addresses.
+				if externalSubnetUser.networkProject == networkProject && externalSubnetUser.networkName == networkName {
+					continue
+				}
+
+			case subnetUsageInstanceSNAT:
+				// Skip the NICs of our own project.
+				if externalSubnetUser.instanceProject == instanceProject {
+					continue
+				}
+
+			default:
+				continue
+			}
+
+			// This error is purposefully vague so that it doesn't reveal any names of resources potentially
+			// outside of the instance's project.
+			if SubnetContains(&externalSubnetUser.subnet, &natIPNet) {
+				return api.StatusErrorf(http.StatusConflict, "SNAT address %q is already in use", natAddress.String())
+			}
+		}
+	}
+
+	return nil
+}
+
 // usesIPv4Firewall returns whether network config will need to use the IPv4 firewall.
 func usesIPv4Firewall(netConfig map[string]string) bool {
 	if netConfig == nil {
@@ -1245,6 +1332,69 @@ func SubnetIterate(subnet *net.IPNet, ipFunc func(ip net.IP) error) error {
 	return nil
 }
 
+// IPRangeSplit splits an IP range into the specified number of equally sized parts and returns the part at the
+// index supplied. Any remainder is added to the last part. Returns nil if the range is too small to be split.
+func IPRangeSplit(ipRange shared.IPRange, index int, parts int) *shared.IPRange {
+	ipLen := net.IPv6len
+	if ipRange.Start.To4() != nil {
+		ipLen = net.IPv4len
+	}
+
+	toIP := func(value *big.Int) net.IP {
+		ip := make(net.IP, ipLen)
+		value.FillBytes(ip)
+
+		return ip
+	}
+
+	startBig := big.NewInt(0).SetBytes(ipRange.Start.To16()[net.IPv6len-ipLen:])
+	endBig := big.NewInt(0).SetBytes(ipRange.End.To16()[net.IPv6len-ipLen:])
+
+	sizeBig := big.NewInt(0).Sub(endBig, startBig)
+	sizeBig.Add(sizeBig, big.NewInt(1))
+
+	partSizeBig := big.NewInt(0).Div(sizeBig, big.NewInt(int64(parts)))
+	if partSizeBig.Sign() <= 0 {
+		return nil
+	}
+
+	partStartBig := big.NewInt(0).Mul(partSizeBig, big.NewInt(int64(index)))
+	partStartBig.Add(partStartBig, startBig)
+
+	partEndBig := endBig
+	if index < parts-1 {
+		partEndBig = big.NewInt(0).Add(partStartBig, partSizeBig)
+		partEndBig.Sub(partEndBig, big.NewInt(1))
+	}
+
+	return &shared.IPRange{
+		Start: toIP(partStartBig),
+		End:   toIP(partEndBig),
+	}
+}
+
+// SubnetSplitV6 returns the /64 subnet at the index supplied within a larger IPv6 prefix.
+// This is used to sub-delegate a prefix obtained through DHCPv6 prefix delegation.
+func SubnetSplitV6(prefix *net.IPNet, index uint64) (*net.IPNet, error) {
+	prefixSize, bits := prefix.Mask.Size()
+	if bits != 128 || prefixSize > 64 {
+		return nil, fmt.Errorf("Prefix %q is not an IPv6 prefix of size /64 or larger", prefix.String())
+	}
+
+	if prefixSize > 0 && index >= uint64(1)<<(64-prefixSize) {
+		return nil, fmt.Errorf("Subnet index %d is out of range for prefix %q", index, prefix.String())
+	}
+
+	ip := make(net.IP, net.IPv6len)
+	copy(ip, prefix.IP.To16())
+
+	network := binary.BigEndian.Uint64(ip[0:8]) | index
+	binary.BigEndian.PutUint64(ip[0:8], network)
+	binary.BigEndian.PutUint64(ip[8:16], 0)
+
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(64, 128)}, nil
+}
+
 // SubnetParseAppend parses one or more string CIDR subnets. Appends to the supplied slice. Returns subnets slice.
 func SubnetParseAppend(subnets []*net.IPNet, parseSubnet ...string) ([]*net.IPNet, error) {
 	for _, subnetStr := range parseSubnet {
@@ -1476,7 +1626,7 @@ func AllowedUplinkNetworks(ctx context.Context, tx *db.ClusterTx, projectConfig
 
 	// Add any compatible networks to the uplink network list.
 	for _, network := range networks {
-		if network.Type == "bridge" || network.Type == "physical" {
+		if network.Type == "bridge" || network.Type == "physical" || network.Type == "wireguard" {
 			uplinkNetworkNames = append(uplinkNetworkNames, network.Name)
 		}
 	}
@@ -2,9 +2,14 @@ package network
 
 import (
 	"fmt"
+	"net"
 	"os"
+	"strconv"
 	"strings"
 
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+
 	"github.com/canonical/lxd/lxd/ip"
 	"github.com/canonical/lxd/lxd/network/openvswitch"
 	"github.com/canonical/lxd/shared"
@@ -50,6 +55,110 @@ func BridgeVLANSetDefaultPVID(interfaceName string, vlanID string) error {
 	return nil
 }
 
+// BridgeMulticastConfig represents the multicast snooping settings of a native bridge.
+type BridgeMulticastConfig struct {
+	Snooping    bool
+	Querier     bool
+	IGMPVersion string // Empty for the kernel default.
+	MLDVersion  string // Empty for the kernel default.
+}
+
+// bridgePortMulticastRouterModes maps the multicast.router NIC setting to the kernel bridge port values.
+var bridgePortMulticastRouterModes = map[string]uint8{
+	"disabled":  0,
+	"auto":      1,
+	"permanent": 2,
+}
+
+// bridgeLinkRequest sends a netlink link request of the specified type for the interface with the specified attribute.
+func bridgeLinkRequest(interfaceName string, msgType int, family int, attr *nl.RtAttr) error {
+	iface, err := net.InterfaceByName(interfaceName)
+	if err != nil {
+		return err
+	}
+
+	req := nl.NewNetlinkRequest(msgType, unix.NLM_F_ACK)
+	msg := nl.NewIfInfomsg(family)
+	msg.Index = int32(iface.Index)
+	req.AddData(msg)
+	req.AddData(attr)
+
+	_, err = req.Execute(unix.NETLINK_ROUTE, 0)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// BridgeMulticastSet applies the multicast snooping settings to a native bridge using netlink.
+func BridgeMulticastSet(interfaceName string, config BridgeMulticastConfig) error {
+	boolAttr := func(value bool) []byte {
+		if value {
+			return nl.Uint8Attr(1)
+		}
+
+		return nl.Uint8Attr(0)
+	}
+
+	// Default to the kernel defaults so that unsetting a key reverts its setting.
+	igmpVersion := uint64(2)
+	if config.IGMPVersion != "" {
+		var err error
+		igmpVersion, err = strconv.ParseUint(config.IGMPVersion, 10, 8)
+		if err != nil {
+			return fmt.Errorf("Invalid IGMP version %q: %w", config.IGMPVersion, err)
+		}
+	}
+
+	mldVersion := uint64(1)
+	if config.MLDVersion != "" {
+		var err error
+		mldVersion, err = strconv.ParseUint(config.MLDVersion, 10, 8)
+		if err != nil {
+			return fmt.Errorf("Invalid MLD version %q: %w", config.MLDVersion, err)
+		}
+	}
+
+	linkInfo := nl.NewRtAttr(unix.IFLA_LINKINFO, nil)
+	linkInfo.AddRtAttr(nl.IFLA_INFO_KIND, nl.NonZeroTerminated("bridge"))
+	data := linkInfo.AddRtAttr(nl.IFLA_INFO_DATA, nil)
+	data.AddRtAttr(nl.IFLA_BR_MCAST_SNOOPING, boolAttr(config.Snooping))
+	data.AddRtAttr(nl.IFLA_BR_MCAST_QUERIER, boolAttr(config.Querier))
+	data.AddRtAttr(nl.IFLA_BR_MCAST_IGMP_VERSION, nl.Uint8Attr(uint8(igmpVersion)))
+	data.AddRtAttr(nl.IFLA_BR_MCAST_MLD_VERSION, nl.Uint8Attr(uint8(mldVersion)))
+
+	err := bridgeLinkRequest(interfaceName, unix.RTM_NEWLINK, unix.AF_UNSPEC, linkInfo)
+	if err != nil {
+		return fmt.Errorf("Failed setting multicast settings on bridge %q: %w", interfaceName, err)
+	}
+
+	return nil
+}
+
+// BridgePortMulticastRouterSet sets the multicast router mode of a native bridge port using netlink.
+// The mode is one of "disabled", "auto" or "permanent", with an empty mode meaning "auto".
+func BridgePortMulticastRouterSet(interfaceName string, mode string) error {
+	if mode == "" {
+		mode = "auto"
+	}
+
+	value, found := bridgePortMulticastRouterModes[mode]
+	if !found {
+		return fmt.Errorf("Invalid multicast router mode %q", mode)
+	}
+
+	protInfo := nl.NewRtAttr(unix.IFLA_PROTINFO|unix.NLA_F_NESTED, nil)
+	protInfo.AddRtAttr(nl.IFLA_BRPORT_MULTICAST_ROUTER, nl.Uint8Attr(value))
+
+	err := bridgeLinkRequest(interfaceName, unix.RTM_SETLINK, unix.AF_BRIDGE, protInfo)
+	if err != nil {
+		return fmt.Errorf("Failed setting multicast router mode on bridge port %q: %w", interfaceName, err)
+	}
+
+	return nil
+}
+
 // IsNativeBridge returns whether the bridge name specified is a Linux native bridge.
 func IsNativeBridge(bridgeName string) bool {
 	return shared.PathExists(fmt.Sprintf("/sys/class/net/%s/bridge", bridgeName))
@@ -0,0 +1,265 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/validate"
+)
+
+// dhcpOptionType describes the format of a DHCP option value.
+type dhcpOptionType int
+
+const (
+	// dhcpOptionTypeAddresses is a comma separated list of IP addresses.
+	dhcpOptionTypeAddresses dhcpOptionType = iota
+
+	// dhcpOptionTypeString is a free form string.
+	dhcpOptionTypeString
+
+	// dhcpOptionTypeRaw is a value passed as-is to the DHCP server (used for numeric option codes).
+	dhcpOptionTypeRaw
+)
+
+// dhcpOption describes a DHCP option that can be set using the ipv{4,6}.dhcp.option.NAME keys.
+type dhcpOption struct {
+	dnsmasq    string // Option name or code as understood by dnsmasq.
+	ovn        string // Option name as understood by OVN (empty if not supported by OVN).
+	optionType dhcpOptionType
+}
+
+// dhcpv4Options are the named DHCPv4 options that can be set on networks and NICs.
+var dhcpv4Options = map[string]dhcpOption{
+	"log-server":          {dnsmasq: "option:log-server", ovn: "log_server", optionType: dhcpOptionTypeAddresses},
+	"ntp-server":          {dnsmasq: "option:ntp-server", ovn: "ntp_server", optionType: dhcpOptionTypeAddresses},
+	"tftp-server":         {dnsmasq: "option:tftp-server", ovn: "tftp_server", optionType: dhcpOptionTypeString},
+	"tftp-server-address": {dnsmasq: "150", ovn: "tftp_server_address", optionType: dhcpOptionTypeAddresses},
+	"wpad":                {dnsmasq: "252", ovn: "wpad", optionType: dhcpOptionTypeString},
+}
+
+// dhcpv6Options are the named DHCPv6 options that can be set on networks and NICs.
+var dhcpv6Options = map[string]dhcpOption{
+	"bootfile-url": {dnsmasq: "option6:bootfile-url", ovn: "bootfile_name", optionType: dhcpOptionTypeString},
+	"ntp-server":   {dnsmasq: "option6:ntp-server", optionType: dhcpOptionTypeAddresses},
+}
+
+// dhcpOptionKey parses a ipv{4,6}.dhcp.option.NAME key and returns the IP family and option name.
+func dhcpOptionKey(key string) (uint, string, bool) {
+	for _, family := range []uint{4, 6} {
+		name, found := strings.CutPrefix(key, fmt.Sprintf("ipv%d.dhcp.option.", family))
+		if found {
+			return family, name, true
+		}
+	}
+
+	return 0, "", false
+}
+
+// dhcpOptionLookup returns the DHCP option definition for the name and IP family.
+// As well as the named options, numeric option codes are accepted and passed through to dnsmasq as-is.
+func dhcpOptionLookup(family uint, name string) (*dhcpOption, error) {
+	options := dhcpv4Options
+	maxCode := uint64(254)
+	if family == 6 {
+		options = dhcpv6Options
+		maxCode = 65535
+	}
+
+	option, found := options[name]
+	if found {
+		return &option, nil
+	}
+
+	code, err := strconv.ParseUint(name, 10, 16)
+	if err != nil || code < 1 || code > maxCode {
+		return nil, fmt.Errorf("Unknown DHCPv%d option %q", family, name)
+	}
+
+	option = dhcpOption{dnsmasq: name, optionType: dhcpOptionTypeRaw}
+	if family == 6 {
+		option.dnsmasq = fmt.Sprintf("option6:%s", name)
+	}
+
+	return &option, nil
+}
+
+// DHCPOptionsValidationRules returns the validation rules for the ipv{4,6}.dhcp.option.NAME keys found in config.
+// If ovn is true then options that OVN cannot provide are rejected.
+func DHCPOptionsValidationRules(config map[string]string, ovn bool) map[string]func(value string) error {
+	rules := make(map[string]func(value string) error)
+
+	for k := range config {
+		family, name, ok := dhcpOptionKey(k)
+		if !ok {
+			continue
+		}
+
+		rules[k] = func(value string) error {
+			option, err := dhcpOptionLookup(family, name)
+			if err != nil {
+				return err
+			}
+
+			if ovn && option.ovn == "" {
+				return fmt.Errorf("DHCPv%d option %q is not supported on OVN networks", family, name)
+			}
+
+			if value == "" {
+				return nil
+			}
+
+			if strings.ContainsAny(value, "\"\n") {
+				return fmt.Errorf("Value cannot contain double quotes or line breaks")
+			}
+
+			if option.optionType == dhcpOptionTypeAddresses {
+				if family == 4 {
+					return validate.IsListOf(validate.IsNetworkAddressV4)(value)
+				}
+
+				return validate.IsListOf(validate.IsNetworkAddressV6)(value)
+			}
+
+			return nil
+		}
+	}
+
+	return rules
+}
+
+// DHCPBootFilenameValidate validates the ipv4.dhcp.boot.filename setting.
+func DHCPBootFilenameValidate(value string) error {
+	if strings.ContainsAny(value, "\"\n") {
+		return fmt.Errorf("Value cannot contain double quotes or line breaks")
+	}
+
+	return nil
+}
+
+// dhcpOptionKeys returns the ipv{4,6}.dhcp.option.NAME keys with a value in config, sorted.
+func dhcpOptionKeys(config map[string]string) []string {
+	keys := []string{}
+	for k, v := range config {
+		_, _, ok := dhcpOptionKey(k)
+		if ok && v != "" {
+			keys = append(keys, k)
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// dhcpOptionsConfig returns the DHCP option keys of config (including the boot keys) for the IP family.
+func dhcpOptionsConfig(config map[string]string, family uint) map[string]string {
+	options := make(map[string]string)
+
+	for k, v := range config {
+		optionFamily, _, ok := dhcpOptionKey(k)
+		if !ok && strings.HasPrefix(k, "ipv4.dhcp.boot.") {
+			optionFamily = 4
+			ok = true
+		}
+
+		if ok && optionFamily == family {
+			options[k] = v
+		}
+	}
+
+	return options
+}
+
+// DHCPOptionsDNSMasq returns the dnsmasq dhcp-option values for the DHCP option keys of a network or NIC config.
+func DHCPOptionsDNSMasq(config map[string]string) []string {
+	options := []string{}
+
+	// The bootfile-name and server-ip-address pseudo options set the BOOTP file and next server fields.
+	if config["ipv4.dhcp.boot.filename"] != "" {
+		options = append(options, fmt.Sprintf(`option:bootfile-name,"%s"`, config["ipv4.dhcp.boot.filename"]))
+	}
+
+	if config["ipv4.dhcp.boot.next_server"] != "" {
+		options = append(options, fmt.Sprintf("option:server-ip-address,%s", config["ipv4.dhcp.boot.next_server"]))
+	}
+
+	for _, k := range dhcpOptionKeys(config) {
+		family, name, _ := dhcpOptionKey(k)
+		option, err := dhcpOptionLookup(family, name)
+		if err != nil {
+			continue // Invalid options are rejected during validation.
+		}
+
+		value := config[k]
+
+		switch option.optionType {
+		case dhcpOptionTypeAddresses:
+			if family == 6 {
+				addresses := shared.SplitNTrimSpace(value, ",", -1, true)
+				for i := range addresses {
+					addresses[i] = fmt.Sprintf("[%s]", addresses[i])
+				}
+
+				value = strings.Join(addresses, ",")
+			}
+
+		case dhcpOptionTypeString:
+			value = fmt.Sprintf(`"%s"`, value)
+		}
+
+		options = append(options, fmt.Sprintf("%s,%s", option.dnsmasq, value))
+	}
+
+	return options
+}
+
+// DHCPOptionsOVN returns the OVN DHCPv4 and DHCPv6 options for the DHCP option keys of a network or NIC config.
+// Options not supported by OVN are skipped.
+func DHCPOptionsOVN(config map[string]string) (map[string]string, map[string]string) {
+	dhcpv4Opts := make(map[string]string)
+	dhcpv6Opts := make(map[string]string)
+
+	if config["ipv4.dhcp.boot.filename"] != "" {
+		dhcpv4Opts["bootfile_name"] = fmt.Sprintf(`"%s"`, config["ipv4.dhcp.boot.filename"])
+	}
+
+	if config["ipv4.dhcp.boot.next_server"] != "" {
+		dhcpv4Opts["next_server"] = config["ipv4.dhcp.boot.next_server"]
+	}
+
+	for _, k := range dhcpOptionKeys(config) {
+		family, name, _ := dhcpOptionKey(k)
+		option, err := dhcpOptionLookup(family, name)
+		if err != nil || option.ovn == "" {
+			continue
+		}
+
+		value := config[k]
+
+		switch option.optionType {
+		case dhcpOptionTypeAddresses:
+			addresses := shared.SplitNTrimSpace(value, ",", -1, true)
+			if len(addresses) > 1 {
+				value = fmt.Sprintf("{%s}", strings.Join(addresses, ","))
+			}
+
+		case dhcpOptionTypeString:
+			// The TFTP server can be given by address, in which case it mustn't be quoted.
+			if name != "tftp-server" || net.ParseIP(value) == nil {
+				value = fmt.Sprintf(`"%s"`, value)
+			}
+		}
+
+		if family == 4 {
+			dhcpv4Opts[option.ovn] = value
+		} else {
+			dhcpv6Opts[option.ovn] = value
+		}
+	}
+
+	return dhcpv4Opts, dhcpv6Opts
+}
@@ -10,6 +10,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/canonical/lxd/shared"
 )
 
 func Test_randomAddressInSubnet(t *testing.T) {
@@ -167,3 +169,325 @@ func Test_randomAddressInSubnet(t *testing.T) {
 		})
 	}
 }
+
+func TestIPRangeSplit(t *testing.T) {
+	tests := []struct {
+		start     string
+		end       string
+		index     int
+		parts     int
+		wantStart string
+		wantEnd   string
+	}{
+		{
+			start:     "10.0.0.2",
+			end:       "10.0.0.254",
+			index:     0,
+			parts:     1,
+			wantStart: "10.0.0.2",
+			wantEnd:   "10.0.0.254",
+		},
+		{
+			start:     "10.0.0.2",
+			end:       "10.0.0.254",
+			index:     0,
+			parts:     3,
+			wantStart: "10.0.0.2",
+			wantEnd:   "10.0.0.85",
+		},
+		{
+			start:     "10.0.0.2",
+			end:       "10.0.0.254",
+			index:     2,
+			parts:     3,
+			wantStart: "10.0.0.170",
+			wantEnd:   "10.0.0.254",
+		},
+		{
+			start:     "10.0.0.250",
+			end:       "10.0.1.9",
+			index:     1,
+			parts:     2,
+			wantStart: "10.0.1.2",
+			wantEnd:   "10.0.1.9",
+		},
+		{
+			start:     "fd42::2",
+			end:       "fd42::ffff",
+			index:     1,
+			parts:     2,
+			wantStart: "fd42::8001",
+			wantEnd:   "fd42::ffff",
+		},
+		{
+			start: "10.0.0.2",
+			end:   "10.0.0.3",
+			index: 0,
+			parts: 3,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("Case %d", i), func(t *testing.T) {
+			got := IPRangeSplit(shared.IPRange{Start: net.ParseIP(tt.start), End: net.ParseIP(tt.end)}, tt.index, tt.parts)
+			if tt.wantStart == "" {
+				assert.Nil(t, got)
+				return
+			}
+
+			require.NotNil(t, got)
+			assert.Equal(t, tt.wantStart, got.Start.String())
+			assert.Equal(t, tt.wantEnd, got.End.String())
+		})
+	}
+}
+
+func TestSubnetSplitV6(t *testing.T) {
+	tests := []struct {
+		prefix  string
+		index   uint64
+		want    string
+		wantErr bool
+	}{
+		{prefix: "2001:db8:1200::/56", index: 0, want: "2001:db8:1200::/64"},
+		{prefix: "2001:db8:1200::/56", index: 1, want: "2001:db8:1200:1::/64"},
+		{prefix: "2001:db8:1200::/56", index: 255, want: "2001:db8:1200:ff::/64"},
+		{prefix: "2001:db8:1200::/56", index: 256, wantErr: true},
+		{prefix: "2001:db8:1234:5678::/64", index: 0, want: "2001:db8:1234:5678::/64"},
+		{prefix: "2001:db8:1234:5678::/64", index: 1, wantErr: true},
+		{prefix: "2001:db8::/80", index: 0, wantErr: true},
+		{prefix: "192.0.2.0/24", index: 0, wantErr: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("Case %d", i), func(t *testing.T) {
+			_, prefix, err := net.ParseCIDR(tt.prefix)
+			require.NoError(t, err)
+
+			got, err := SubnetSplitV6(prefix, tt.index)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.String())
+		})
+	}
+}
+
+func Test_natAddressesCheckNotInUse(t *testing.T) {
+	_, forwardSubnet, _ := net.ParseCIDR("192.0.2.10/32")
+	_, loadBalancerSubnet, _ := net.ParseCIDR("2001:db8::10/128")
+	_, networkSubnet, _ := net.ParseCIDR("192.0.2.0/24")
+	_, instanceSubnet, _ := net.ParseCIDR("198.51.100.0/24")
+	_, ownNetworkSNATSubnet, _ := net.ParseCIDR("203.0.113.1/32")
+	_, otherNetworkSNATSubnet, _ := net.ParseCIDR("203.0.113.2/32")
+	_, otherNetworkVolatileSubnet, _ := net.ParseCIDR("203.0.113.3/32")
+	_, projectNICSNATSubnet, _ := net.ParseCIDR("203.0.113.4/32")
+	_, otherProjectNICSNATSubnet, _ := net.ParseCIDR("203.0.113.5/32")
+
+	externalSubnetsInUse := []externalSubnetUsage{
+		{subnet: *forwardSubnet, usageType: subnetUsageNetworkForward},
+		{subnet: *loadBalancerSubnet, usageType: subnetUsageNetworkLoadBalancer},
+		{subnet: *networkSubnet, usageType: subnetUsageNetwork},
+		{subnet: *instanceSubnet, usageType: subnetUsageInstance},
+		{subnet: *ownNetworkSNATSubnet, usageType: subnetUsageNetworkSNAT, networkProject: "default", networkName: "lxdbr0"},
+		{subnet: *otherNetworkSNATSubnet, usageType: subnetUsageNetworkSNAT, networkProject: "default", networkName: "lxdbr1"},
+		{subnet: *otherNetworkVolatileSubnet, usageType: subnetUsageVolatileIP, networkProject: "foo", networkName: "ovn0"},
+		{subnet: *projectNICSNATSubnet, usageType: subnetUsageInstanceSNAT, instanceProject: "foo", instanceName: "c1", instanceDevice: "eth0"},
+		{subnet: *otherProjectNICSNATSubnet, usageType: subnetUsageInstanceSNAT, instanceProject: "bar", instanceName: "c1", instanceDevice: "eth0"},
+	}
+
+	tests := []struct {
+		natAddresses []string
+		wantErr      bool
+	}{
+		{natAddresses: []string{}},
+		{natAddresses: []string{"192.0.2.11"}},
+		{natAddresses: []string{"198.51.100.1", "2001:db8::11"}},
+		{natAddresses: []string{"192.0.2.10"}, wantErr: true},
+		{natAddresses: []string{"192.0.2.11", "2001:db8::10"}, wantErr: true},
+		{natAddresses: []string{"203.0.113.1"}},
+		{natAddresses: []string{"203.0.113.2"}, wantErr: true},
+		{natAddresses: []string{"203.0.113.3"}, wantErr: true},
+		{natAddresses: []string{"203.0.113.4"}},
+		{natAddresses: []string{"203.0.113.5"}, wantErr: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("Case %d", i), func(t *testing.T) {
+			natAddresses := make([]net.IP, 0, len(tt.natAddresses))
+			for _, natAddress := range tt.natAddresses {
+				natAddresses = append(natAddresses, net.ParseIP(natAddress))
+			}
+
+			err := natAddressesCheckNotInUse(externalSubnetsInUse, "default", "lxdbr0", "foo", natAddresses)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func Test_dhcpOptionsConfig(t *testing.T) {
+	config := map[string]string{
+		"hwaddr":                      "00:16:3e:00:00:01",
+		"ipv4.dhcp.boot.filename":     "pxelinux.0",
+		"ipv4.dhcp.option.ntp-server": "192.0.2.123",
+		"ipv6.dhcp.option.ntp-server": "fd42::123",
+		"user.foo":                    "bar",
+	}
+
+	assert.Equal(t, map[string]string{
+		"ipv4.dhcp.boot.filename":     "pxelinux.0",
+		"ipv4.dhcp.option.ntp-server": "192.0.2.123",
+	}, dhcpOptionsConfig(config, 4))
+
+	assert.Equal(t, map[string]string{
+		"ipv6.dhcp.option.ntp-server": "fd42::123",
+	}, dhcpOptionsConfig(config, 6))
+}
+
+func Test_reservationValidateClientID(t *testing.T) {
+	for _, value := range []string{"01", "01:02:03", "ff:0a:BC"} {
+		assert.NoError(t, reservationValidateClientID(value), value)
+	}
+
+	for _, value := range []string{"", "1", "01:2", "01::02", "0g", "01-02"} {
+		assert.Error(t, reservationValidateClientID(value), value)
+	}
+}
+
+func Test_reservationValidateLeaseTime(t *testing.T) {
+	for _, value := range []string{"infinite", "3600", "60s", "30m", "12h", "1d", "2w"} {
+		assert.NoError(t, reservationValidateLeaseTime(value), value)
+	}
+
+	for _, value := range []string{"", "h", "1hh", "-1h", "1y", "forever"} {
+		assert.Error(t, reservationValidateLeaseTime(value), value)
+	}
+}
+
+func Test_DHCPOptionsDNSMasq(t *testing.T) {
+	config := map[string]string{
+		"ipv4.address":                         "192.0.2.1/24",
+		"ipv4.dhcp.boot.filename":              "pxelinux.0",
+		"ipv4.dhcp.boot.next_server":           "192.0.2.2",
+		"ipv4.dhcp.option.ntp-server":          "192.0.2.123,192.0.2.124",
+		"ipv4.dhcp.option.tftp-server":         "tftp.example.net",
+		"ipv4.dhcp.option.tftp-server-address": "192.0.2.3",
+		"ipv4.dhcp.option.wpad":                "http://wpad.example.net/wpad.dat",
+		"ipv4.dhcp.option.210":                 "/srv/boot",
+		"ipv4.dhcp.option.log-server":          "",
+		"ipv4.dhcp.option.unknown":             "foo",
+		"ipv6.dhcp.option.ntp-server":          "fd42::123,fd42::124",
+		"ipv6.dhcp.option.bootfile-url":        "tftp://[fd42::2]/boot.efi",
+		"ipv6.dhcp.option.1000":                "foo",
+	}
+
+	assert.Equal(t, []string{
+		`option:bootfile-name,"pxelinux.0"`,
+		"option:server-ip-address,192.0.2.2",
+		"210,/srv/boot",
+		"option:ntp-server,192.0.2.123,192.0.2.124",
+		`option:tftp-server,"tftp.example.net"`,
+		"150,192.0.2.3",
+		`252,"http://wpad.example.net/wpad.dat"`,
+		"option6:1000,foo",
+		`option6:bootfile-url,"tftp://[fd42::2]/boot.efi"`,
+		"option6:ntp-server,[fd42::123],[fd42::124]",
+	}, DHCPOptionsDNSMasq(config))
+
+	assert.Empty(t, DHCPOptionsDNSMasq(map[string]string{"ipv4.address": "192.0.2.1/24"}))
+}
+
+func Test_DHCPOptionsOVN(t *testing.T) {
+	config := map[string]string{
+		"ipv4.dhcp.boot.filename":              "pxelinux.0",
+		"ipv4.dhcp.boot.next_server":           "192.0.2.2",
+		"ipv4.dhcp.option.ntp-server":          "192.0.2.123, 192.0.2.124",
+		"ipv4.dhcp.option.log-server":          "192.0.2.5",
+		"ipv4.dhcp.option.tftp-server":         "192.0.2.3",
+		"ipv4.dhcp.option.tftp-server-address": "192.0.2.3",
+		"ipv4.dhcp.option.wpad":                "http://wpad.example.net/wpad.dat",
+		"ipv4.dhcp.option.210":                 "/srv/boot",
+		"ipv6.dhcp.option.bootfile-url":        "tftp://[fd42::2]/boot.efi",
+		"ipv6.dhcp.option.ntp-server":          "fd42::123",
+	}
+
+	dhcpv4Opts, dhcpv6Opts := DHCPOptionsOVN(config)
+
+	assert.Equal(t, map[string]string{
+		"bootfile_name":       `"pxelinux.0"`,
+		"next_server":         "192.0.2.2",
+		"ntp_server":          "{192.0.2.123,192.0.2.124}",
+		"log_server":          "192.0.2.5",
+		"tftp_server":         "192.0.2.3",
+		"tftp_server_address": "192.0.2.3",
+		"wpad":                `"http://wpad.example.net/wpad.dat"`,
+	}, dhcpv4Opts)
+
+	assert.Equal(t, map[string]string{
+		"bootfile_name": `"tftp://[fd42::2]/boot.efi"`,
+	}, dhcpv6Opts)
+
+	dhcpv4Opts, _ = DHCPOptionsOVN(map[string]string{"ipv4.dhcp.option.tftp-server": "tftp.example.net"})
+	assert.Equal(t, map[string]string{"tftp_server": `"tftp.example.net"`}, dhcpv4Opts)
+}
+
+func Test_DHCPOptionsValidationRules(t *testing.T) {
+	config := map[string]string{
+		"ipv4.dhcp.option.ntp-server": "",
+		"ipv4.dhcp.option.210":        "",
+		"ipv4.dhcp.option.255":        "",
+		"ipv4.dhcp.option.unknown":    "",
+		"ipv6.dhcp.option.ntp-server": "",
+		"ipv4.address":                "",
+	}
+
+	rules := DHCPOptionsValidationRules(config, false)
+	assert.Len(t, rules, 5)
+
+	assert.NoError(t, rules["ipv4.dhcp.option.ntp-server"]("192.0.2.123,192.0.2.124"))
+	assert.Error(t, rules["ipv4.dhcp.option.ntp-server"]("fd42::123"))
+	assert.Error(t, rules["ipv4.dhcp.option.ntp-server"]("ntp.example.net"))
+	assert.NoError(t, rules["ipv4.dhcp.option.210"]("/srv/boot"))
+	assert.Error(t, rules["ipv4.dhcp.option.210"]("\"quoted\""))
+	assert.Error(t, rules["ipv4.dhcp.option.255"]("foo"))
+	assert.Error(t, rules["ipv4.dhcp.option.unknown"]("foo"))
+	assert.NoError(t, rules["ipv6.dhcp.option.ntp-server"]("fd42::123"))
+
+	// OVN doesn't support numeric options nor DHCPv6 NTP servers.
+	rules = DHCPOptionsValidationRules(config, true)
+	assert.NoError(t, rules["ipv4.dhcp.option.ntp-server"]("192.0.2.123"))
+	assert.Error(t, rules["ipv4.dhcp.option.210"]("/srv/boot"))
+	assert.Error(t, rules["ipv6.dhcp.option.ntp-server"]("fd42::123"))
+}
+
+func Test_wireguardEndpointChanged(t *testing.T) {
+	tests := []struct {
+		curEndpoint  string
+		wantEndpoint string
+		changed      bool
+	}{
+		{curEndpoint: "192.0.2.10:51820", wantEndpoint: ""},
+		{curEndpoint: "", wantEndpoint: ""},
+		{curEndpoint: "192.0.2.10:51820", wantEndpoint: "192.0.2.10:51820"},
+		{curEndpoint: "[2001:db8::10]:51820", wantEndpoint: "[2001:db8::10]:51820"},
+		{curEndpoint: "127.0.0.1:51820", wantEndpoint: "localhost:51820"},
+		{curEndpoint: "", wantEndpoint: "192.0.2.10:51820", changed: true},
+		{curEndpoint: "192.0.2.10:51820", wantEndpoint: "192.0.2.11:51820", changed: true},
+		{curEndpoint: "192.0.2.10:51820", wantEndpoint: "192.0.2.10:51821", changed: true},
+		{curEndpoint: "192.0.2.10:51820", wantEndpoint: "localhost:51820", changed: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("Case %d", i), func(t *testing.T) {
+			assert.Equal(t, tt.changed, wireguardEndpointChanged(tt.curEndpoint, tt.wantEndpoint))
+		})
+	}
+}
@@ -0,0 +1,251 @@
+package network
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/curve25519"
+
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/validate"
+)
+
+// wireguardKeyGenerate generates a new base64 encoded WireGuard private key.
+func wireguardKeyGenerate() (string, error) {
+	key := make([]byte, curve25519.ScalarSize)
+
+	_, err := rand.Read(key)
+	if err != nil {
+		return "", fmt.Errorf("Failed generating WireGuard private key: %w", err)
+	}
+
+	// Clamp the key as described in https://cr.yp.to/ecdh.html.
+	key[0] &= 248
+	key[31] &= 127
+	key[31] |= 64
+
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// wireguardValidateKey validates a base64 encoded WireGuard key.
+func wireguardValidateKey(value string) error {
+	key, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return fmt.Errorf("Invalid WireGuard key: %w", err)
+	}
+
+	if len(key) != curve25519.PointSize {
+		return fmt.Errorf("Invalid WireGuard key length %d (expected %d)", len(key), curve25519.PointSize)
+	}
+
+	return nil
+}
+
+// wireguardValidatePeerEndpoint validates the endpoint of a WireGuard peer in the host:port format.
+func wireguardValidatePeerEndpoint(value string) error {
+	host, port, err := net.SplitHostPort(value)
+	if err != nil {
+		return fmt.Errorf("Invalid endpoint %q: %w", value, err)
+	}
+
+	if host == "" {
+		return fmt.Errorf("Invalid endpoint %q: Missing host", value)
+	}
+
+	return validate.IsNetworkPort(port)
+}
+
+// wireguardPeer represents a WireGuard peer to be configured on an interface.
+type wireguardPeer struct {
+	PublicKey           string
+	Endpoint            string
+	AllowedIPs          []string
+	PersistentKeepalive string
+}
+
+// wireguardSetDevice applies the private key and listen port to the WireGuard interface.
+func wireguardSetDevice(devName string, privateKeyPath string, listenPort string) error {
+	_, err := shared.RunCommand("wg", "set", devName, "listen-port", listenPort, "private-key", privateKeyPath)
+	if err != nil {
+		return fmt.Errorf("Failed configuring WireGuard interface %q: %w", devName, err)
+	}
+
+	return nil
+}
+
+// wireguardSetPeer adds or updates a peer on the WireGuard interface.
+// The allowed IPs of an existing peer are replaced by the ones supplied.
+func wireguardSetPeer(devName string, peer wireguardPeer) error {
+	args := []string{"set", devName, "peer", peer.PublicKey, "allowed-ips", strings.Join(peer.AllowedIPs, ",")}
+
+	if peer.Endpoint != "" {
+		args = append(args, "endpoint", peer.Endpoint)
+	}
+
+	if peer.PersistentKeepalive != "" {
+		args = append(args, "persistent-keepalive", peer.PersistentKeepalive)
+	}
+
+	_, err := shared.RunCommand("wg", args...)
+	if err != nil {
+		return fmt.Errorf("Failed setting WireGuard peer %q on %q: %w", peer.PublicKey, devName, err)
+	}
+
+	return nil
+}
+
+// wireguardRemovePeer removes a peer from the WireGuard interface.
+func wireguardRemovePeer(devName string, publicKey string) error {
+	_, err := shared.RunCommand("wg", "set", devName, "peer", publicKey, "remove")
+	if err != nil {
+		return fmt.Errorf("Failed removing WireGuard peer %q from %q: %w", publicKey, devName, err)
+	}
+
+	return nil
+}
+
+// wireguardDeviceState returns the state of the WireGuard interface and its peers.
+func wireguardDeviceState(devName string) (*api.NetworkStateWireGuard, error) {
+	out, err := shared.RunCommand("wg", "show", devName, "dump")
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting WireGuard state of %q: %w", devName, err)
+	}
+
+	lines := shared.SplitNTrimSpace(out, "\n", -1, true)
+	if len(lines) < 1 {
+		return nil, fmt.Errorf("Unexpected empty WireGuard state for %q", devName)
+	}
+
+	// The first line describes the interface: private-key public-key listen-port fwmark.
+	fields := strings.Split(lines[0], "\t")
+	if len(fields) < 3 {
+		return nil, fmt.Errorf("Unexpected WireGuard interface state %q", lines[0])
+	}
+
+	listenPort, err := strconv.ParseUint(fields[2], 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid WireGuard listen port %q: %w", fields[2], err)
+	}
+
+	wgState := &api.NetworkStateWireGuard{
+		PublicKey:  fields[1],
+		ListenPort: listenPort,
+		Peers:      []api.NetworkStateWireGuardPeer{},
+	}
+
+	// The remaining lines describe the peers: public-key preshared-key endpoint allowed-ips latest-handshake
+	// transfer-rx transfer-tx persistent-keepalive.
+	for _, line := range lines[1:] {
+		fields := strings.Split(line, "\t")
+		if len(fields) < 7 {
+			return nil, fmt.Errorf("Unexpected WireGuard peer state %q", line)
+		}
+
+		peer := api.NetworkStateWireGuardPeer{
+			PublicKey:  fields[0],
+			AllowedIPs: []string{},
+		}
+
+		if fields[2] != "(none)" {
+			peer.Endpoint = fields[2]
+		}
+
+		if fields[3] != "(none)" {
+			peer.AllowedIPs = strings.Split(fields[3], ",")
+		}
+
+		handshake, err := strconv.ParseInt(fields[4], 10, 64)
+		if err == nil && handshake > 0 {
+			peer.LatestHandshake = time.Unix(handshake, 0).UTC()
+		}
+
+		peer.BytesReceived, _ = strconv.ParseUint(fields[5], 10, 64)
+		peer.BytesSent, _ = strconv.ParseUint(fields[6], 10, 64)
+
+		wgState.Peers = append(wgState.Peers, peer)
+	}
+
+	return wgState, nil
+}
+
+// wireguardLoadOrCreateKey returns the private key stored at the path supplied, generating a new one if missing.
+func wireguardLoadOrCreateKey(keyPath string) (string, error) {
+	content, err := os.ReadFile(keyPath)
+	if err == nil {
+		return strings.TrimSpace(string(content)), nil
+	}
+
+	if !os.IsNotExist(err) {
+		return "", fmt.Errorf("Failed reading WireGuard private key: %w", err)
+	}
+
+	privateKey, err := wireguardKeyGenerate()
+	if err != nil {
+		return "", err
+	}
+
+	err = os.WriteFile(keyPath, []byte(privateKey+"\n"), 0600)
+	if err != nil {
+		return "", fmt.Errorf("Failed writing WireGuard private key: %w", err)
+	}
+
+	return privateKey, nil
+}
+
+// wireguardEndpoint returns a WireGuard endpoint string from the host address and default port supplied.
+// If the address already contains a port, it is used as is.
+func wireguardEndpoint(address string, defaultPort string) string {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return net.JoinHostPort(strings.Trim(address, "[]"), defaultPort)
+	}
+
+	return net.JoinHostPort(host, port)
+}
+
+// wireguardEndpointChanged returns whether the endpoint a WireGuard peer uses differs from the wanted endpoint.
+// WireGuard resolves endpoints when they are set, so a wanted endpoint using a host name is considered unchanged if
+// the current endpoint uses one of the addresses of that host name.
+func wireguardEndpointChanged(curEndpoint string, wantEndpoint string) bool {
+	if wantEndpoint == "" {
+		return false
+	}
+
+	curAddrPort, err := netip.ParseAddrPort(curEndpoint)
+	if err != nil {
+		return true
+	}
+
+	host, port, err := net.SplitHostPort(wantEndpoint)
+	if err != nil || port != strconv.Itoa(int(curAddrPort.Port())) {
+		return true
+	}
+
+	hostAddr, err := netip.ParseAddr(host)
+	if err == nil {
+		return hostAddr.Unmap() != curAddrPort.Addr().Unmap()
+	}
+
+	hostAddrs, err := net.LookupHost(host)
+	if err != nil {
+		// Keep the current endpoint if the host name can't be resolved.
+		return false
+	}
+
+	for _, addr := range hostAddrs {
+		hostAddr, err := netip.ParseAddr(addr)
+		if err == nil && hostAddr.Unmap() == curAddrPort.Addr().Unmap() {
+			return false
+		}
+	}
+
+	return true
+}
@@ -2,9 +2,11 @@ package openvswitch
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -76,6 +78,7 @@ const ovnExtIDLXDSwitchPort = "lxd_switch_port"
 const ovnExtIDLXDProjectID = "lxd_project_id"
 const ovnExtIDLXDPortGroup = "lxd_port_group"
 const ovnExtIDLXDLocation = "lxd_location"
+const ovnExtIDLXDACLRule = "lxd_acl_rule"
 
 // OVNIPv6RAOpts IPv6 router advertisements options that can be applied to a router.
 type OVNIPv6RAOpts struct {
@@ -92,6 +95,7 @@ type OVNIPv6RAOpts struct {
 type OVNDHCPOptsSet struct {
 	UUID OVNDHCPOptionsUUID
 	CIDR *net.IPNet
+	Port OVNSwitchPort // Set if the options set is specific to a switch port.
 }
 
 // OVNDHCPv4Opts IPv4 DHCP options that can be applied to a switch port.
@@ -104,6 +108,7 @@ type OVNDHCPv4Opts struct {
 	LeaseTime          time.Duration
 	MTU                uint32
 	Netmask            string
+	Options            map[string]string // Additional options keyed on OVN option name, values in OVN format.
 }
 
 // OVNDHCPv6Opts IPv6 DHCP option set that can be created (and then applied to a switch port by resulting ID).
@@ -111,6 +116,7 @@ type OVNDHCPv6Opts struct {
 	ServerID           net.HardwareAddr
 	RecursiveDNSServer []net.IP
 	DNSSearchList      []string
+	Options            map[string]string // Additional options keyed on OVN option name, values in OVN format.
 }
 
 // OVNSwitchPortOpts options that can be applied to a swich port.
@@ -132,6 +138,22 @@ type OVNACLRule struct {
 	Priority  int    // Priority (between 0 and 32767, inclusive). Higher values take precedence.
 	Log       bool   // Whether or not to log matched packets.
 	LogName   string // Log label name (requires Log be true).
+	Meter     string // Optional, name of the meter that limits the rate of matched packets.
+
+	CounterName string // Optional, name used to identify the rule when retrieving its counters.
+}
+
+// OVNMeter represents an OVN meter that drops the packets exceeding its rate.
+type OVNMeter struct {
+	Name  string
+	Rate  uint64 // Packets per second.
+	Burst uint64 // Packets.
+}
+
+// OVNACLRuleCounters represents the packet and byte counters of one or more ACL rules sharing a counter name.
+type OVNACLRuleCounters struct {
+	Packets uint64
+	Bytes   uint64
 }
 
 // OVNLoadBalancerTarget represents an OVN load balancer Virtual IP target.
@@ -392,6 +414,30 @@ func (o *OVN) LogicalRouterSNATAdd(routerName OVNRouter, intNet *net.IPNet, extI
 	return nil
 }
 
+// LogicalRouterSNATDelete deletes the SNAT rules translating packets from the specified intNets from a logical router.
+func (o *OVN) LogicalRouterSNATDelete(routerName OVNRouter, intNets ...*net.IPNet) error {
+	args := []string{}
+
+	for _, intNet := range intNets {
+		if len(args) > 0 {
+			args = append(args, "--")
+		}
+
+		args = append(args, "--if-exists", "lr-nat-del", string(routerName), "snat", intNet.String())
+	}
+
+	if len(args) == 0 {
+		return nil
+	}
+
+	_, err := o.nbctl(args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // LogicalRouterDNATSNATDeleteAll deletes all DNAT_AND_SNAT rules from a logical router.
 func (o *OVN) LogicalRouterDNATSNATDeleteAll(routerName OVNRouter) error {
 	_, err := o.nbctl("--if-exists", "lr-nat-del", string(routerName), "dnat_and_snat")
@@ -961,6 +1007,8 @@ func (o *OVN) LogicalSwitchDHCPv4OptionsSet(switchName OVNSwitch, uuid OVNDHCPOp
 		args = append(args, fmt.Sprintf("netmask=%s", opts.Netmask))
 	}
 
+	args = append(args, dhcpOptionsArgs(opts.Options)...)
+
 	_, err = o.nbctl(args...)
 	if err != nil {
 		return err
@@ -1019,6 +1067,8 @@ func (o *OVN) LogicalSwitchDHCPv6OptionsSet(switchName OVNSwitch, uuid OVNDHCPOp
 		args = append(args, fmt.Sprintf("dns_server={%s}", strings.Join(nsIPs, ",")))
 	}
 
+	args = append(args, dhcpOptionsArgs(opts.Options)...)
+
 	_, err = o.nbctl(args...)
 	if err != nil {
 		return err
@@ -1027,16 +1077,121 @@ func (o *OVN) LogicalSwitchDHCPv6OptionsSet(switchName OVNSwitch, uuid OVNDHCPOp
 	return nil
 }
 
+// dhcpOptionsArgs returns the key=value arguments for the supplied DHCP options in a stable order.
+func dhcpOptionsArgs(options map[string]string) []string {
+	keys := make([]string, 0, len(options))
+	for key := range options {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	args := make([]string, 0, len(keys))
+	for _, key := range keys {
+		args = append(args, fmt.Sprintf("%s=%s", key, options[key]))
+	}
+
+	return args
+}
+
+// LogicalSwitchPortDHCPOptionsSet creates or updates a DHCP option set specific to a switch port.
+// The options of the switch's DHCP option set specified by switchOptsUUID are copied and the supplied options are
+// applied on top of them. Returns the UUID of the switch port's DHCP option set.
+func (o *OVN) LogicalSwitchPortDHCPOptionsSet(switchName OVNSwitch, portName OVNSwitchPort, switchOptsUUID OVNDHCPOptionsUUID, options map[string]string) (OVNDHCPOptionsUUID, error) {
+	output, err := o.nbctl("--format=json", "--columns=cidr,options", "list", "dhcp_options", string(switchOptsUUID))
+	if err != nil {
+		return "", err
+	}
+
+	// The options column is represented as ["map", [[key, value], ...]].
+	var rows struct {
+		Data [][]any `json:"data"`
+	}
+
+	err = json.Unmarshal([]byte(output), &rows)
+	if err != nil {
+		return "", fmt.Errorf("Failed parsing DHCP options %q: %w", switchOptsUUID, err)
+	}
+
+	if len(rows.Data) != 1 || len(rows.Data[0]) != 2 {
+		return "", fmt.Errorf("DHCP options %q not found", switchOptsUUID)
+	}
+
+	cidr, _ := rows.Data[0][0].(string)
+	_, subnet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return "", fmt.Errorf("Invalid DHCP options CIDR %q: %w", cidr, err)
+	}
+
+	portOptions := make(map[string]string)
+
+	optionsCol, _ := rows.Data[0][1].([]any)
+	if len(optionsCol) == 2 {
+		pairs, _ := optionsCol[1].([]any)
+		for _, pair := range pairs {
+			kv, _ := pair.([]any)
+			if len(kv) != 2 {
+				continue
+			}
+
+			key, _ := kv[0].(string)
+			value, _ := kv[1].(string)
+			portOptions[key] = value
+		}
+	}
+
+	for key, value := range options {
+		portOptions[key] = value
+	}
+
+	// Find the existing option set of the port for the subnet.
+	existingOpts, err := o.LogicalSwitchDHCPOptionsGet(switchName)
+	if err != nil {
+		return "", err
+	}
+
+	var uuid OVNDHCPOptionsUUID
+	for _, existingOpt := range existingOpts {
+		if existingOpt.Port == portName && existingOpt.CIDR.String() == subnet.String() {
+			uuid = existingOpt.UUID
+			break
+		}
+	}
+
+	if uuid == "" {
+		uuidRaw, err := o.nbctl("create", "dhcp_option",
+			fmt.Sprintf("external_ids:%s=%s", ovnExtIDLXDSwitch, switchName),
+			fmt.Sprintf("external_ids:%s=%s", ovnExtIDLXDSwitchPort, portName),
+			fmt.Sprintf(`cidr="%s"`, subnet.String()), // Special quoting to allow IPv6 address.
+		)
+		if err != nil {
+			return "", err
+		}
+
+		uuid = OVNDHCPOptionsUUID(strings.TrimSpace(uuidRaw))
+	}
+
+	args := []string{"dhcp-options-set-options", string(uuid)}
+	args = append(args, dhcpOptionsArgs(portOptions)...)
+
+	_, err = o.nbctl(args...)
+	if err != nil {
+		return "", err
+	}
+
+	return uuid, nil
+}
+
 // LogicalSwitchDHCPOptionsGet retrieves the existing DHCP options defined for a logical switch.
 func (o *OVN) LogicalSwitchDHCPOptionsGet(switchName OVNSwitch) ([]OVNDHCPOptsSet, error) {
-	output, err := o.nbctl("--format=csv", "--no-headings", "--data=bare", "--colum=_uuid,cidr", "find", "dhcp_options",
+	output, err := o.nbctl("--format=csv", "--no-headings", "--data=bare", "--colum=_uuid,cidr,external_ids", "find", "dhcp_options",
 		fmt.Sprintf("external_ids:%s=%s", ovnExtIDLXDSwitch, switchName),
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	colCount := 2
+	colCount := 3
 	dhcpOpts := []OVNDHCPOptsSet{}
 	output = strings.TrimSpace(output)
 	if output != "" {
@@ -1054,6 +1209,7 @@ func (o *OVN) LogicalSwitchDHCPOptionsGet(switchName OVNSwitch) ([]OVNDHCPOptsSe
 			dhcpOpts = append(dhcpOpts, OVNDHCPOptsSet{
 				UUID: OVNDHCPOptionsUUID(rowParts[0]),
 				CIDR: cidr,
+				Port: OVNSwitchPort(parseExternalIDs(rowParts[2])[ovnExtIDLXDSwitchPort]),
 			})
 		}
 	}
@@ -1540,6 +1696,18 @@ func (o *OVN) LogicalSwitchPortCleanup(portName OVNSwitchPort, switchName OVNSwi
 		args = o.logicalSwitchPortDeleteDNSAppendArgs(args, switchName, dnsUUID, false)
 	}
 
+	// Remove port specific DHCP options.
+	dhcpOptsUUIDs, err := o.nbctl("--format=csv", "--no-headings", "--data=bare", "--colum=_uuid", "find", "dhcp_options",
+		fmt.Sprintf("external_ids:%s=%s", ovnExtIDLXDSwitchPort, portName),
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, dhcpOptsUUID := range shared.SplitNTrimSpace(strings.TrimSpace(dhcpOptsUUIDs), "\n", -1, true) {
+		args = append(args, "--", "destroy", "dhcp_options", dhcpOptsUUID)
+	}
+
 	_, err = o.nbctl(args...)
 	if err != nil {
 		return err
@@ -1840,6 +2008,10 @@ func (o *OVN) aclRuleAddAppendArgs(args []string, entityTable string, entityName
 			fmt.Sprintf("match=%s", strconv.Quote(rule.Match)),
 		)
 
+		if rule.Meter != "" {
+			args = append(args, fmt.Sprintf("meter=%s", strconv.Quote(rule.Meter)))
+		}
+
 		if rule.Log {
 			args = append(args, "log=true")
 
@@ -1852,6 +2024,10 @@ func (o *OVN) aclRuleAddAppendArgs(args []string, entityTable string, entityName
 			args = append(args, fmt.Sprintf("external_ids:%s=%s", k, v))
 		}
 
+		if rule.CounterName != "" {
+			args = append(args, fmt.Sprintf("external_ids:%s=%s", ovnExtIDLXDACLRule, rule.CounterName))
+		}
+
 		// Add command to assign ACL rule to entity.
 		args = append(args, "--", "add", entityTable, entityName, "acl", fmt.Sprintf("@id%d", i))
 	}
@@ -1920,6 +2096,106 @@ func (o *OVN) PortGroupPortClearACLRules(portGroupName OVNPortGroup, portName OV
 	return nil
 }
 
+// parseExternalIDs parses the bare format of an external_ids column into a map.
+func parseExternalIDs(field string) map[string]string {
+	externalIDs := make(map[string]string)
+	for _, pair := range strings.Fields(strings.Trim(field, `"`)) {
+		key, value, found := strings.Cut(pair, "=")
+		if found {
+			externalIDs[key] = strings.Trim(value, `"`)
+		}
+	}
+
+	return externalIDs
+}
+
+// ACLRuleCounters returns the counters of the ACL rules whose counter name starts with counterNamePrefix, keyed on
+// counter name. The counters are taken from the OpenFlow flows installed on the local chassis' integration bridge
+// for each ACL rule, so only reflect the traffic handled by the local chassis.
+func (o *OVN) ACLRuleCounters(integrationBridge string, counterNamePrefix string) (map[string]OVNACLRuleCounters, error) {
+	// Get the ACL rules that have a matching counter name, keyed on the first 8 characters of their UUID.
+	// This is the stage hint that ovn-northd adds to the logical flows it generates for an ACL rule.
+	output, err := o.nbctl("--format=csv", "--no-headings", "--data=bare", "--columns=_uuid,external_ids", "list", "acl")
+	if err != nil {
+		return nil, err
+	}
+
+	aclStageHints := make(map[string]string)
+	for _, line := range shared.SplitNTrimSpace(strings.TrimSpace(output), "\n", -1, true) {
+		fields := shared.SplitNTrimSpace(line, ",", 2, false)
+		if len(fields) != 2 || len(fields[0]) < 8 {
+			continue
+		}
+
+		counterName := parseExternalIDs(fields[1])[ovnExtIDLXDACLRule]
+		if counterName == "" || !strings.HasPrefix(counterName, counterNamePrefix) {
+			continue
+		}
+
+		aclStageHints[fields[0][:8]] = counterName
+	}
+
+	counters := make(map[string]OVNACLRuleCounters)
+	if len(aclStageHints) == 0 {
+		return counters, nil
+	}
+
+	// Get the ACL evaluation logical flows for each of the ACL rules, keyed on the OpenFlow cookie that
+	// ovn-controller uses for the flows it installs for the logical flow (the first 32 bits of its UUID).
+	output, err = o.sbctl("--format=csv", "--no-headings", "--data=bare", "--columns=_uuid,external_ids", "list", "logical_flow")
+	if err != nil {
+		return nil, err
+	}
+
+	flowCookies := make(map[uint64]string)
+	for _, line := range shared.SplitNTrimSpace(strings.TrimSpace(output), "\n", -1, true) {
+		fields := shared.SplitNTrimSpace(line, ",", 2, false)
+		if len(fields) != 2 || len(fields[0]) < 8 {
+			continue
+		}
+
+		externalIDs := parseExternalIDs(fields[1])
+
+		// Only count the flows that evaluate the ACL, as other stages may reference the same ACL.
+		stageName := externalIDs["stage-name"]
+		if !strings.HasSuffix(stageName, "_acl") && !strings.HasSuffix(stageName, "_acl_eval") {
+			continue
+		}
+
+		counterName, found := aclStageHints[externalIDs["stage-hint"]]
+		if !found {
+			continue
+		}
+
+		cookie, err := strconv.ParseUint(fields[0][:8], 16, 64)
+		if err != nil {
+			continue
+		}
+
+		flowCookies[cookie] = counterName
+	}
+
+	// Sum the local OpenFlow flow counters for each ACL rule.
+	flowStats, err := NewOVS().BridgeFlowStats(integrationBridge)
+	if err != nil {
+		return nil, err
+	}
+
+	for cookie, counterName := range flowCookies {
+		stats, found := flowStats[cookie]
+		if !found {
+			continue
+		}
+
+		ruleCounters := counters[counterName]
+		ruleCounters.Packets += stats.Packets
+		ruleCounters.Bytes += stats.Bytes
+		counters[counterName] = ruleCounters
+	}
+
+	return counters, nil
+}
+
 // loadBalancerUUIDs returns list of UUID records for named load balancer.
 func (o *OVN) loadBalancerUUIDs(loadBalancerName OVNLoadBalancer) ([]string, error) {
 	lbTCPName := fmt.Sprintf("%s-tcp", loadBalancerName)
@@ -2207,6 +2483,78 @@ func (o *OVN) AddressSetDelete(addressSetPrefix OVNAddressSet) error {
 	return nil
 }
 
+// meterUUIDs returns the UUIDs of the meters whose name starts with namePrefix.
+func (o *OVN) meterUUIDs(namePrefix string) ([]string, error) {
+	output, err := o.nbctl("--format=csv", "--no-headings", "--data=bare", "--colum=_uuid,name", "list", "meter")
+	if err != nil {
+		return nil, err
+	}
+
+	var meterUUIDs []string //nolint:prealloc
+	for _, line := range shared.SplitNTrimSpace(strings.TrimSpace(output), "\n", -1, true) {
+		uuid, name, found := strings.Cut(line, ",")
+		if !found || !strings.HasPrefix(name, namePrefix) {
+			continue
+		}
+
+		meterUUIDs = append(meterUUIDs, uuid)
+	}
+
+	return meterUUIDs, nil
+}
+
+// MeterApply replaces the meters whose name starts with namePrefix with the provided meters.
+// The meters are fair, so that each ACL rule referencing a meter is limited on its own.
+func (o *OVN) MeterApply(namePrefix string, meters ...OVNMeter) error {
+	meterUUIDs, err := o.meterUUIDs(namePrefix)
+	if err != nil {
+		return err
+	}
+
+	args := []string{}
+
+	// Remove existing meters, including their bands.
+	for _, meterUUID := range meterUUIDs {
+		if len(args) > 0 {
+			args = append(args, "--")
+		}
+
+		args = append(args, "--if-exists", "destroy", "meter", meterUUID)
+	}
+
+	// Add new meters.
+	for i, meter := range meters {
+		if len(args) > 0 {
+			args = append(args, "--")
+		}
+
+		args = append(args, fmt.Sprintf("--id=@band%d", i), "create", "meter_band",
+			"action=drop",
+			fmt.Sprintf("rate=%d", meter.Rate),
+			fmt.Sprintf("burst_size=%d", meter.Burst),
+			"--", "create", "meter",
+			fmt.Sprintf("name=%s", strconv.Quote(meter.Name)),
+			"unit=pktps",
+			"fair=true",
+			fmt.Sprintf("bands=@band%d", i),
+		)
+	}
+
+	if len(args) > 0 {
+		_, err = o.nbctl(args...)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MeterDelete deletes the meters whose name starts with namePrefix.
+func (o *OVN) MeterDelete(namePrefix string) error {
+	return o.MeterApply(namePrefix)
+}
+
 // LogicalRouterPolicyApply removes any existing policies and applies the new policies to the specified router.
 func (o *OVN) LogicalRouterPolicyApply(routerName OVNRouter, policies ...OVNRouterPolicy) error {
 	args := []string{"lr-policy-del", string(routerName)}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net"
 	"os/exec"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -27,6 +28,12 @@ const (
 	TCPNS  = 0x100
 )
 
+// OVSFlowStats represents the packet and byte counters of one or more OpenFlow flows.
+type OVSFlowStats struct {
+	Packets uint64
+	Bytes   uint64
+}
+
 // NewOVS initialises new OVS wrapper.
 func NewOVS() *OVS {
 	return &OVS{}
@@ -335,6 +342,94 @@ func (o *OVS) BridgePortList(bridgeName string) ([]string, error) {
 	return ports, nil
 }
 
+// BridgeFlowStats returns the counters of the OpenFlow flows installed on the bridge, summed by flow cookie.
+func (o *OVS) BridgeFlowStats(bridgeName string) (map[uint64]OVSFlowStats, error) {
+	output, err := shared.RunCommand("ovs-ofctl", "dump-flows", bridgeName)
+	if err != nil {
+		return nil, err
+	}
+
+	flowStats := make(map[uint64]OVSFlowStats)
+	for _, line := range strings.Split(output, "\n") {
+		// E.g. " cookie=0x4a2b7c1d, duration=12.3s, table=44, n_packets=10, n_bytes=980, priority=2002,ip actions=..."
+		var cookie, packets, bytes uint64
+		var hasCookie bool
+
+		for _, field := range strings.Split(line, ",") {
+			key, value, found := strings.Cut(strings.TrimSpace(field), "=")
+			if !found {
+				continue
+			}
+
+			switch key {
+			case "cookie":
+				cookie, err = strconv.ParseUint(strings.TrimPrefix(value, "0x"), 16, 64)
+				hasCookie = err == nil
+			case "n_packets":
+				packets, _ = strconv.ParseUint(value, 10, 64)
+			case "n_bytes":
+				bytes, _ = strconv.ParseUint(value, 10, 64)
+			}
+		}
+
+		if !hasCookie {
+			continue
+		}
+
+		stats := flowStats[cookie]
+		stats.Packets += packets
+		stats.Bytes += bytes
+		flowStats[cookie] = stats
+	}
+
+	return flowStats, nil
+}
+
+// OVNConntrackZones returns the conntrack zones that ovn-controller has assigned to the logical switch ports
+// bound to the local chassis.
+func (o *OVS) OVNConntrackZones(bridgeName string) (map[uint16]OVNSwitchPort, error) {
+	// ovn-controller records its zone allocations in the integration bridge's external_ids.
+	// E.g. {ct-zone-lxd-net1-instance-xxx-eth0="3", ovn-nb-cfg="12"}.
+	output, err := shared.RunCommand("ovs-vsctl", "get", "bridge", bridgeName, "external_ids")
+	if err != nil {
+		return nil, err
+	}
+
+	output = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(output), "{"), "}")
+
+	zones := make(map[uint16]OVNSwitchPort)
+	for _, field := range strings.Split(output, ", ") {
+		key, value, found := strings.Cut(field, "=")
+		if !found {
+			continue
+		}
+
+		key, err = unquote(key)
+		if err != nil {
+			return nil, fmt.Errorf("Failed unquoting: %w", err)
+		}
+
+		portName, found := strings.CutPrefix(key, "ct-zone-")
+		if !found {
+			continue
+		}
+
+		value, err = unquote(value)
+		if err != nil {
+			return nil, fmt.Errorf("Failed unquoting: %w", err)
+		}
+
+		zone, err := strconv.ParseUint(value, 10, 16)
+		if err != nil {
+			continue
+		}
+
+		zones[uint16(zone)] = OVNSwitchPort(portName)
+	}
+
+	return zones, nil
+}
+
 // HardwareOffloadingEnabled returns true if hardware offloading is enabled.
 func (o *OVS) HardwareOffloadingEnabled() bool {
 	// ovs-vsctl's get command doesn't support its --format flag, so we always get the output quoted.
@@ -1,6 +1,7 @@
 package zone
 
 import (
+	"io"
 	"strings"
 
 	"github.com/canonical/lxd/lxd/cluster/request"
@@ -20,6 +21,7 @@ type NetworkZone interface {
 	Etag() []any
 	UsedBy() ([]string, error)
 	Content() (*strings.Builder, error)
+	Export() (*strings.Builder, error)
 	SOA() (*strings.Builder, error)
 
 	// Records.
@@ -28,6 +30,7 @@ type NetworkZone interface {
 	GetRecord(name string) (*api.NetworkZoneRecord, error)
 	UpdateRecord(name string, req api.NetworkZoneRecordPut, clientType request.ClientType) error
 	DeleteRecord(name string) error
+	ImportRecords(content io.Reader) error
 
 	// Internal validation.
 	validateName(name string) error
@@ -3,6 +3,9 @@ package zone
 import (
 	"context"
 	"fmt"
+	"io"
+	"net/http"
+	"strings"
 
 	"github.com/miekg/dns"
 
@@ -186,6 +189,138 @@ func (d *zone) DeleteRecord(name string) error {
 	return nil
 }
 
+// ImportRecords adds the records of a RFC 1035 master file to the custom records of the zone.
+// Records named after the zone apex are stored under the "@" name. The records generated by LXD, which include the
+// SOA and apex NS records, are skipped so that an exported zone can be imported back.
+// Entries are added to the existing records of the same name, and all records are imported in a single transaction.
+func (d *zone) ImportRecords(content io.Reader) error {
+	zoneName := strings.ToLower(dns.Fqdn(d.info.Name))
+
+	// Get the records generated by LXD.
+	generatedContent, err := d.content(false, false)
+	if err != nil {
+		return err
+	}
+
+	generated := map[string]bool{}
+	parser := dns.NewZoneParser(strings.NewReader(generatedContent.String()), zoneName, "")
+	for rr, ok := parser.Next(); ok; rr, ok = parser.Next() {
+		generated[zoneRecordKey(rr)] = true
+	}
+
+	// Parse the zone file into records.
+	records := map[string]*api.NetworkZoneRecordsPost{}
+	recordNames := []string{}
+
+	parser = dns.NewZoneParser(content, zoneName, "")
+	for rr, ok := parser.Next(); ok; rr, ok = parser.Next() {
+		hdr := rr.Header()
+		owner := strings.ToLower(hdr.Name)
+
+		// The SOA record is generated from the zone configuration with a new serial each time.
+		if hdr.Rrtype == dns.TypeSOA || generated[zoneRecordKey(rr)] {
+			continue
+		}
+
+		if !dns.IsSubDomain(zoneName, owner) {
+			return api.StatusErrorf(http.StatusBadRequest, "Record %q is not within the zone", strings.TrimSpace(rr.String()))
+		}
+
+		name := "@"
+		if owner != zoneName {
+			name = strings.TrimSuffix(owner, "."+zoneName)
+		}
+
+		entry := api.NetworkZoneRecordEntry{
+			Type:  dns.TypeToString[hdr.Rrtype],
+			TTL:   uint64(hdr.Ttl),
+			Value: strings.TrimPrefix(rr.String(), hdr.String()),
+		}
+
+		record, found := records[name]
+		if !found {
+			record = &api.NetworkZoneRecordsPost{Name: name}
+			records[name] = record
+			recordNames = append(recordNames, name)
+		}
+
+		if !zoneRecordHasEntry(record.Entries, entry) {
+			record.Entries = append(record.Entries, entry)
+		}
+	}
+
+	err = parser.Err()
+	if err != nil {
+		return api.StatusErrorf(http.StatusBadRequest, "Failed parsing zone file: %w", err)
+	}
+
+	for _, name := range recordNames {
+		err = d.validateEntries(records[name].NetworkZoneRecordPut)
+		if err != nil {
+			return api.StatusErrorf(http.StatusBadRequest, "Invalid record %q: %w", name, err)
+		}
+	}
+
+	return d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		for _, name := range recordNames {
+			record := records[name]
+
+			id, existing, err := tx.GetNetworkZoneRecord(ctx, d.id, name)
+			if err != nil && !api.StatusErrorCheck(err, http.StatusNotFound) {
+				return err
+			}
+
+			if existing == nil {
+				_, err = tx.CreateNetworkZoneRecord(ctx, d.id, *record)
+				if err != nil {
+					return fmt.Errorf("Failed creating record %q: %w", name, err)
+				}
+
+				continue
+			}
+
+			// Merge the entries into the existing record.
+			changed := false
+			for _, entry := range record.Entries {
+				if !zoneRecordHasEntry(existing.Entries, entry) {
+					existing.Entries = append(existing.Entries, entry)
+					changed = true
+				}
+			}
+
+			if !changed {
+				continue
+			}
+
+			err = tx.UpdateNetworkZoneRecord(ctx, id, existing.Writable())
+			if err != nil {
+				return fmt.Errorf("Failed updating record %q: %w", name, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// zoneRecordKey returns a key identifying the record regardless of its TTL.
+func zoneRecordKey(rr dns.RR) string {
+	rr = dns.Copy(rr)
+	rr.Header().Ttl = 0
+
+	return strings.ToLower(rr.String())
+}
+
+// zoneRecordHasEntry returns whether the entries already contain an entry of the same type and value.
+func zoneRecordHasEntry(entries []api.NetworkZoneRecordEntry, entry api.NetworkZoneRecordEntry) bool {
+	for _, existing := range entries {
+		if strings.EqualFold(existing.Type, entry.Type) && existing.Value == entry.Value {
+			return true
+		}
+	}
+
+	return false
+}
+
 // validateRecordConfig checks the config and rules are valid.
 func (d *zone) validateRecordConfig(info api.NetworkZoneRecordPut) error {
 	rules := map[string]func(value string) error{}
@@ -346,8 +346,18 @@ func (d *zone) Delete() error {
 	return nil
 }
 
-// Content returns the DNS zone content.
+// Content returns the DNS zone content in the form used for zone transfers.
 func (d *zone) Content() (*strings.Builder, error) {
+	return d.content(true, true)
+}
+
+// Export returns the DNS zone content as a RFC 1035 master file.
+func (d *zone) Export() (*strings.Builder, error) {
+	return d.content(false, true)
+}
+
+// generatedRecords returns the records generated from the networks using the zone.
+func (d *zone) generatedRecords() ([]map[string]string, error) {
 	var err error
 	records := []map[string]string{}
 
@@ -494,12 +504,26 @@ func (d *zone) Content() (*strings.Builder, error) {
 		}
 	}
 
-	// Add the extra records.
-	extraRecords, err := d.GetRecords()
+	return records, nil
+}
+
+// content returns the DNS zone content, including the generated records and, if custom is true, the custom records.
+// When transfer is true, the SOA record is repeated at the end of the content as expected for zone transfers.
+func (d *zone) content(transfer bool, custom bool) (*strings.Builder, error) {
+	records, err := d.generatedRecords()
 	if err != nil {
 		return nil, err
 	}
 
+	// Add the extra records.
+	extraRecords := []api.NetworkZoneRecord{}
+	if custom {
+		extraRecords, err = d.GetRecords()
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	for _, extraRecord := range extraRecords {
 		for _, entry := range extraRecord.Entries {
 			record := map[string]string{}
@@ -541,6 +565,7 @@ func (d *zone) Content() (*strings.Builder, error) {
 		"zone":        d.info.Name,
 		"serial":      time.Now().Unix(),
 		"records":     records,
+		"transfer":    transfer,
 	})
 	if err != nil {
 		return nil, err
@@ -575,6 +600,7 @@ func (d *zone) SOA() (*strings.Builder, error) {
 		"zone":        d.info.Name,
 		"serial":      time.Now().Unix(),
 		"records":     map[string]string{},
+		"transfer":    true,
 	})
 	if err != nil {
 		return nil, err
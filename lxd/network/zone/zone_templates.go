@@ -5,13 +5,17 @@ import (
 )
 
 // DNS zone template.
+// When used for a zone transfer, the SOA record is repeated at the end as required by RFC 5936.
+// Records named "@" are placed at the zone apex.
 var zoneTemplate = template.Must(template.New("zoneTemplate").Parse(`
 {{.zone}}. 3600 IN SOA {{.zone}}. {{.primary}}. {{.serial}} 120 60 86400 30
 {{- range $index, $element := .nameservers}}
 {{$.zone}}. 300 IN NS {{$element}}.
 {{- end}}
 {{- range .records}}
-{{.name}}.{{$.zone}}. {{.ttl}} IN {{.type}} {{.value}}
+{{if eq .name "@"}}{{$.zone}}.{{else}}{{.name}}.{{$.zone}}.{{end}} {{.ttl}} IN {{.type}} {{.value}}
 {{- end}}
+{{- if .transfer}}
 {{.zone}}. 3600 IN SOA {{.zone}}. {{.primary}}. {{.serial}} 120 60 86400 30
+{{- end}}
 `))
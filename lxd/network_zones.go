@@ -44,6 +44,20 @@ var networkZoneCmd = APIEndpoint{
 	Patch:  APIEndpointAction{Handler: networkZonePut, AccessHandler: networkZoneAccessHandler(auth.EntitlementCanEdit)},
 }
 
+var networkZoneExportCmd = APIEndpoint{
+	Path:        "network-zones/{zone}/export",
+	MetricsType: entity.TypeNetwork,
+
+	Get: APIEndpointAction{Handler: networkZoneExportGet, AccessHandler: networkZoneAccessHandler(auth.EntitlementCanView)},
+}
+
+var networkZoneImportCmd = APIEndpoint{
+	Path:        "network-zones/{zone}/import",
+	MetricsType: entity.TypeNetwork,
+
+	Post: APIEndpointAction{Handler: networkZoneImportPost, AccessHandler: networkZoneAccessHandler(auth.EntitlementCanEdit)},
+}
+
 // ctxNetworkZoneDetails should be used only for getting/setting networkZoneDetails in the request context.
 const ctxNetworkZoneDetails request.CtxKey = "network-zone-details"
 
@@ -642,3 +656,122 @@ func networkZonePut(d *Daemon, r *http.Request) response.Response {
 
 	return response.EmptySyncResponse
 }
+
+// swagger:operation GET /1.0/network-zones/{zone}/export network-zones network_zone_export_get
+//
+//	Export the network zone
+//
+//	Gets the content of the network zone as a RFC 1035 master file.
+//	This includes both the records generated from the networks using the zone and the custom records.
+//
+//	---
+//	produces:
+//	  - text/plain
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: Zone file
+//	    schema:
+//	      type: string
+//	      description: Zone file content
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkZoneExportGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	effectiveProjectName, err := request.GetCtxValue[string](r.Context(), request.CtxEffectiveProjectName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	details, err := request.GetCtxValue[networkZoneDetails](r.Context(), ctxNetworkZoneDetails)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	netzone, err := zone.LoadByNameAndProject(s, effectiveProjectName, details.zoneName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	content, err := netzone.Export()
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponsePlain(true, false, content.String())
+}
+
+// swagger:operation POST /1.0/network-zones/{zone}/import network-zones network_zone_import_post
+//
+//	Import records into the network zone
+//
+//	Adds the records of a RFC 1035 master file to the custom records of the network zone.
+//	Entries are added to the existing records of the same name, and records for the zone apex are stored under the "@" name.
+//	The records generated by LXD, including the SOA and apex NS records, are skipped.
+//	All records are imported at once, so the zone is left unchanged if any record fails.
+//
+//	---
+//	consumes:
+//	  - application/octet-stream
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: zone
+//	    description: Zone file content
+//	    required: true
+//	    schema:
+//	      type: string
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkZoneImportPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	effectiveProjectName, err := request.GetCtxValue[string](r.Context(), request.CtxEffectiveProjectName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	details, err := request.GetCtxValue[networkZoneDetails](r.Context(), ctxNetworkZoneDetails)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	netzone, err := zone.LoadByNameAndProject(s, effectiveProjectName, details.zoneName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = netzone.ImportRecords(r.Body)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	s.Events.SendLifecycle(effectiveProjectName, lifecycle.NetworkZoneUpdated.Event(netzone, request.CreateRequestor(r), nil))
+
+	return response.EmptySyncResponse
+}
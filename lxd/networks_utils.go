@@ -2,8 +2,10 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/canonical/lxd/lxd/cluster"
 	"github.com/canonical/lxd/lxd/db"
@@ -15,6 +17,13 @@ import (
 
 var networkOVNChassis *bool
 
+// networkWireGuardRefreshInterval is how often the WireGuard networks refresh their cluster member peers when the
+// cluster members haven't changed. Refreshing queries the network state of every other member.
+const networkWireGuardRefreshInterval = time.Minute
+
+// networkWireGuardRefreshed is when the WireGuard networks last refreshed their cluster member peers successfully.
+var networkWireGuardRefreshed time.Time
+
 func networkAutoAttach(cluster *db.Cluster, devName string) error {
 	var networkName string
 	_ = cluster.Transaction(context.TODO(), func(ctx context.Context, c *db.ClusterTx) error {
@@ -38,18 +47,17 @@ func networkAutoAttach(cluster *db.Cluster, devName string) error {
 	return nil
 }
 
-// networkUpdateForkdnsServersTask runs every 30s and refreshes the forkdns servers list.
-func networkUpdateForkdnsServersTask(s *state.State, heartbeatData *cluster.APIHeartbeat) error {
-	logger.Debug("Refreshing forkdns servers")
-
-	// Use api.ProjectDefaultName here as forkdns (fan bridge) networks don't support projects.
-	projectName := api.ProjectDefaultName
+// networkHeartbeatTask is called on heartbeats and lets the networks refresh their view of the cluster members,
+// such as the forkdns servers of fan bridges, the peers of VXLAN bridges and the peers of WireGuard networks.
+// Fan bridges query every member to refresh their forkdns servers, so they are only refreshed when the state of
+// the members has changed. WireGuard networks query every member too, so they are also refreshed periodically.
+func networkHeartbeatTask(s *state.State, heartbeatData *cluster.APIHeartbeat, memberStateChanged bool) error {
+	var projectNetworks map[string]map[int64]api.Network
 
-	// Get a list of managed networks
-	var networks []string
-	err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, c *db.ClusterTx) error {
+	err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
 		var err error
-		networks, err = c.GetCreatedNetworkNamesByProject(ctx, projectName)
+
+		projectNetworks, err = tx.GetCreatedNetworks(ctx)
 
 		return err
 	})
@@ -57,22 +65,47 @@ func networkUpdateForkdnsServersTask(s *state.State, heartbeatData *cluster.APIH
 		return err
 	}
 
-	for _, name := range networks {
-		n, err := network.LoadByName(s, projectName, name)
-		if err != nil {
-			logger.Errorf("Failed to load network %q from project %q for heartbeat", name, projectName)
-			continue
-		}
+	var errs []error
+
+	// The peers of the WireGuard networks only change along with the cluster members or the addresses they route,
+	// so only refresh them on member state changes and periodically, rather than on every heartbeat.
+	refreshWireGuard := memberStateChanged || time.Since(networkWireGuardRefreshed) >= networkWireGuardRefreshInterval
+	wireGuardFailed := false
 
-		if n.Type() == "bridge" && n.Config()["bridge.mode"] == "fan" {
-			err := n.HandleHeartbeat(heartbeatData)
+	for projectName, networks := range projectNetworks {
+		for _, netInfo := range networks {
+			if netInfo.Type == "bridge" && netInfo.Config["bridge.mode"] == "fan" && !memberStateChanged {
+				continue
+			}
+
+			if netInfo.Type == "wireguard" && !refreshWireGuard {
+				continue
+			}
+
+			n, err := network.LoadByName(s, projectName, netInfo.Name)
 			if err != nil {
-				return err
+				logger.Error("Failed to load network for heartbeat", logger.Ctx{"project": projectName, "network": netInfo.Name, "err": err})
+				continue
+			}
+
+			// Refresh the other networks even if one of them fails, the failed ones are retried next heartbeat.
+			err = n.HandleHeartbeat(heartbeatData)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("Failed handling heartbeat for network %q in project %q: %w", netInfo.Name, projectName, err))
+
+				if netInfo.Type == "wireguard" {
+					wireGuardFailed = true
+				}
 			}
 		}
 	}
 
-	return nil
+	// Retry failed WireGuard refreshes on the next heartbeat.
+	if refreshWireGuard && !wireGuardFailed {
+		networkWireGuardRefreshed = time.Now()
+	}
+
+	return errors.Join(errs...)
 }
 
 // networkUpdateOVNChassis gets called on heartbeats to check if OVN needs reconfiguring.
@@ -0,0 +1,741 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+
+	"github.com/gorilla/mux"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/db"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/lifecycle"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/validate"
+	"github.com/canonical/lxd/shared/version"
+)
+
+var placementGroupsCmd = APIEndpoint{
+	Path:        "placement-groups",
+	MetricsType: entity.TypeProject,
+
+	Get:  APIEndpointAction{Handler: placementGroupsGet, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanView)},
+	Post: APIEndpointAction{Handler: placementGroupsPost, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanEdit)},
+}
+
+var placementGroupCmd = APIEndpoint{
+	Path:        "placement-groups/{name}",
+	MetricsType: entity.TypeProject,
+
+	Delete: APIEndpointAction{Handler: placementGroupDelete, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanEdit)},
+	Get:    APIEndpointAction{Handler: placementGroupGet, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanView)},
+	Put:    APIEndpointAction{Handler: placementGroupPut, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanEdit)},
+	Patch:  APIEndpointAction{Handler: placementGroupPut, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanEdit)},
+}
+
+// placementGroupConfigKeys contains the validators of the placement group configuration keys.
+var placementGroupConfigKeys = map[string]func(value string) error{
+	// lxdmeta:generate(entities=placement-group; group=group-conf; key=policy)
+	// Possible values are `spread` (place the members of the group on different cluster members) and
+	// `compact` (place the members of the group on the same cluster member).
+	// ---
+	//  type: string
+	//  defaultdesc: `spread`
+	//  shortdesc: Placement policy of the members of the group
+	"policy": validate.Optional(validate.IsOneOf("spread", "compact")),
+
+	// lxdmeta:generate(entities=placement-group; group=group-conf; key=mode)
+	// In `strict` mode, an instance isn't placed if no cluster member satisfies the policy.
+	// In `soft` mode, the cluster member that satisfies the policy best is used instead.
+	// ---
+	//  type: string
+	//  defaultdesc: `strict`
+	//  shortdesc: Whether the policy must be satisfied
+	"mode": validate.Optional(validate.IsOneOf("strict", "soft")),
+}
+
+// placementGroupValidate validates the name and the writable fields of a placement group.
+func placementGroupValidate(name string, req api.PlacementGroupPut) error {
+	err := validate.IsHostname(name)
+	if err != nil {
+		return api.StatusErrorf(http.StatusBadRequest, "Invalid placement group name: %w", err)
+	}
+
+	for key, value := range req.Config {
+		validator, ok := placementGroupConfigKeys[key]
+		if !ok {
+			return api.StatusErrorf(http.StatusBadRequest, "Invalid placement group configuration key %q", key)
+		}
+
+		err := validator(value)
+		if err != nil {
+			return api.StatusErrorf(http.StatusBadRequest, "Invalid value for placement group configuration key %q: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+// placementGroupMembers returns the cluster member of each instance of the given project that belongs to the
+// placement group with the given name. Instances can join a group through their own config or their profiles.
+func placementGroupMembers(ctx context.Context, tx *db.ClusterTx, projectName string, groupName string) (map[string]string, error) {
+	members := make(map[string]string)
+
+	err := tx.InstanceList(ctx, func(inst db.InstanceArgs, p api.Project) error {
+		expandedConfig := instancetype.ExpandInstanceConfig(nil, inst.Config, inst.Profiles)
+		if expandedConfig["placement.group"] == groupName {
+			members[inst.Name] = inst.Node
+		}
+
+		return nil
+	}, dbCluster.InstanceFilter{Project: &projectName})
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading placement group members: %w", err)
+	}
+
+	return members, nil
+}
+
+// placementGroupCandidates returns the candidate cluster members that satisfy the placement group of an instance.
+// The config is the expanded config of the instance being placed. The candidates are returned unchanged if the
+// instance doesn't belong to a placement group.
+func placementGroupCandidates(ctx context.Context, tx *db.ClusterTx, projectName string, instanceName string, config map[string]string, candidates []db.NodeInfo) ([]db.NodeInfo, error) {
+	groupName := config["placement.group"]
+	if groupName == "" || len(candidates) == 0 {
+		return candidates, nil
+	}
+
+	_, group, err := tx.GetPlacementGroup(ctx, projectName, groupName)
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading placement group %q: %w", groupName, err)
+	}
+
+	members, err := placementGroupMembers(ctx, tx, projectName, groupName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Count the other members of the group on each cluster member.
+	counts := make(map[string]int)
+	for name, location := range members {
+		if name != instanceName {
+			counts[location]++
+		}
+	}
+
+	return placementGroupFilterCandidates(group, counts, candidates)
+}
+
+// placementGroupFilterCandidates returns the candidate cluster members that satisfy the policy of a placement group,
+// given the number of other instances of the group hosted on each cluster member.
+func placementGroupFilterCandidates(group *api.PlacementGroup, counts map[string]int, candidates []db.NodeInfo) ([]db.NodeInfo, error) {
+	groupName := group.Name
+	strict := group.Config["mode"] != "soft"
+
+	var filtered []db.NodeInfo
+
+	if group.Config["policy"] == "compact" {
+		// Keep the cluster members that host the most members of the group.
+		most := 0
+		for _, candidate := range candidates {
+			most = max(most, counts[candidate.Name])
+		}
+
+		// Any cluster member will do for the first member of the group.
+		if most == 0 {
+			if len(counts) > 0 && strict {
+				return nil, api.StatusErrorf(http.StatusNotFound, "No cluster member hosting the members of placement group %q is available", groupName)
+			}
+
+			return candidates, nil
+		}
+
+		for _, candidate := range candidates {
+			if counts[candidate.Name] == most {
+				filtered = append(filtered, candidate)
+			}
+		}
+
+		return filtered, nil
+	}
+
+	// Keep the cluster members that host the fewest members of the group.
+	fewest := -1
+	for _, candidate := range candidates {
+		if fewest < 0 || counts[candidate.Name] < fewest {
+			fewest = counts[candidate.Name]
+		}
+	}
+
+	if fewest > 0 && strict {
+		return nil, api.StatusErrorf(http.StatusNotFound, "No cluster member without members of placement group %q is available", groupName)
+	}
+
+	for _, candidate := range candidates {
+		if counts[candidate.Name] == fewest {
+			filtered = append(filtered, candidate)
+		}
+	}
+
+	return filtered, nil
+}
+
+// placementGroupCheckTarget checks that the cluster member explicitly targeted for an instance satisfies the
+// placement group of the instance. Any cluster member satisfies a placement group in soft mode.
+func placementGroupCheckTarget(ctx context.Context, tx *db.ClusterTx, projectName string, instanceName string, config map[string]string, member db.NodeInfo) error {
+	_, err := placementGroupCandidates(ctx, tx, projectName, instanceName, config, []db.NodeInfo{member})
+	if err != nil {
+		if api.StatusErrorCheck(err, http.StatusNotFound) {
+			return api.StatusErrorf(http.StatusBadRequest, "Cluster member %q doesn't satisfy placement group %q: %w", member.Name, config["placement.group"], err)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// placementGroupCheckExists checks that the placement group set in an instance or profile config exists in the
+// given project.
+func placementGroupCheckExists(ctx context.Context, tx *db.ClusterTx, projectName string, config map[string]string) error {
+	groupName := config["placement.group"]
+	if groupName == "" {
+		return nil
+	}
+
+	_, _, err := tx.GetPlacementGroup(ctx, projectName, groupName)
+	if err != nil {
+		if api.StatusErrorCheck(err, http.StatusNotFound) {
+			return api.StatusErrorf(http.StatusBadRequest, "Placement group %q doesn't exist", groupName)
+		}
+
+		return fmt.Errorf("Failed loading placement group %q: %w", groupName, err)
+	}
+
+	return nil
+}
+
+// placementGroupLoad returns the placement group with the given name along with the URLs of its members.
+func placementGroupLoad(ctx context.Context, tx *db.ClusterTx, projectName string, name string) (*api.PlacementGroup, error) {
+	_, group, err := tx.GetPlacementGroup(ctx, projectName, name)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := placementGroupMembers(ctx, tx, projectName, name)
+	if err != nil {
+		return nil, err
+	}
+
+	group.UsedBy = make([]string, 0, len(members))
+	for member := range members {
+		group.UsedBy = append(group.UsedBy, api.NewURL().Path(version.APIVersion, "instances", member).Project(projectName).String())
+	}
+
+	slices.Sort(group.UsedBy)
+
+	return group, nil
+}
+
+// API endpoints
+
+// swagger:operation GET /1.0/placement-groups placement-groups placement_groups_get
+//
+//	Get the placement groups
+//
+//	Returns a list of placement groups (URLs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of endpoints
+//	          items:
+//	            type: string
+//	          example: |-
+//	            [
+//	              "/1.0/placement-groups/web",
+//	              "/1.0/placement-groups/db"
+//	            ]
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+
+// swagger:operation GET /1.0/placement-groups?recursion=1 placement-groups placement_groups_get_recursion1
+//
+//	Get the placement groups
+//
+//	Returns a list of placement groups (structs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of placement groups
+//	          items:
+//	            $ref: "#/definitions/PlacementGroup"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func placementGroupsGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+	recursion := util.IsRecursionRequest(r)
+
+	var groups []api.PlacementGroup
+	var names []string
+
+	err := s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		names, err = tx.GetPlacementGroupNames(ctx, projectName)
+		if err != nil {
+			return err
+		}
+
+		if !recursion {
+			return nil
+		}
+
+		for _, name := range names {
+			group, err := placementGroupLoad(ctx, tx, projectName, name)
+			if err != nil {
+				return err
+			}
+
+			groups = append(groups, *group)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed loading placement groups: %w", err))
+	}
+
+	if recursion {
+		if groups == nil {
+			groups = []api.PlacementGroup{}
+		}
+
+		return response.SyncResponse(true, groups)
+	}
+
+	groupURLs := make([]string, 0, len(names))
+	for _, name := range names {
+		groupURLs = append(groupURLs, fmt.Sprintf("/%s/placement-groups/%s", version.APIVersion, url.PathEscape(name)))
+	}
+
+	return response.SyncResponse(true, groupURLs)
+}
+
+// swagger:operation POST /1.0/placement-groups placement-groups placement_groups_post
+//
+//	Add a placement group
+//
+//	Creates a new placement group.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: group
+//	    description: Placement group
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/PlacementGroupsPost"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func placementGroupsPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+
+	req := api.PlacementGroupsPost{}
+
+	// Parse the request.
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	req.Normalise()
+
+	err = placementGroupValidate(req.Name, req.PlacementGroupPut)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		// Check that the project exists.
+		_, err := dbCluster.GetProject(ctx, tx.Tx(), projectName)
+		if err != nil {
+			return fmt.Errorf("Failed loading project: %w", err)
+		}
+
+		_, _, err = tx.GetPlacementGroup(ctx, projectName, req.Name)
+		if err == nil {
+			return api.StatusErrorf(http.StatusConflict, "The placement group already exists")
+		}
+
+		_, err = tx.CreatePlacementGroup(ctx, projectName, &req)
+		if err != nil {
+			return fmt.Errorf("Failed creating placement group: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	lc := lifecycle.PlacementGroupCreated.Event(projectName, req.Name, request.CreateRequestor(r), nil)
+	s.Events.SendLifecycle(projectName, lc)
+
+	return response.SyncResponseLocation(true, nil, lc.Source)
+}
+
+// swagger:operation GET /1.0/placement-groups/{name} placement-groups placement_group_get
+//
+//	Get the placement group
+//
+//	Gets a specific placement group.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: Placement group
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/PlacementGroup"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func placementGroupGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	var group *api.PlacementGroup
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		group, err = placementGroupLoad(ctx, tx, projectName, name)
+
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponseETag(true, group, group.Etag())
+}
+
+// swagger:operation PATCH /1.0/placement-groups/{name} placement-groups placement_group_patch
+//
+//	Partially update the placement group
+//
+//	Updates a subset of the placement group configuration.
+//	The instances that are already placed aren't moved.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: group
+//	    description: Placement group configuration
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/PlacementGroupPut"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "412":
+//	    $ref: "#/responses/PreconditionFailed"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+
+// swagger:operation PUT /1.0/placement-groups/{name} placement-groups placement_group_put
+//
+//	Update the placement group
+//
+//	Updates the entire placement group configuration.
+//	The instances that are already placed aren't moved.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: group
+//	    description: Placement group configuration
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/PlacementGroupPut"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "412":
+//	    $ref: "#/responses/PreconditionFailed"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func placementGroupPut(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	var id int64
+	var current *api.PlacementGroup
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		id, current, err = tx.GetPlacementGroup(ctx, projectName, name)
+
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Validate the ETag.
+	err = util.EtagCheck(r, current.Etag())
+	if err != nil {
+		return response.PreconditionFailed(err)
+	}
+
+	req := api.PlacementGroupPut{}
+
+	if r.Method == http.MethodPatch {
+		// If the group is being updated via "patch" method, only the fields and config keys that are
+		// present in the request are modified.
+		req = current.Writable()
+		req.Config = make(map[string]string, len(current.Config))
+		for k, v := range current.Config {
+			req.Config[k] = v
+		}
+	}
+
+	// Decode the request.
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	req.Normalise()
+
+	err = placementGroupValidate(name, req)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		err := tx.UpdatePlacementGroup(ctx, id, req)
+		if err != nil {
+			return fmt.Errorf("Failed updating placement group: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	s.Events.SendLifecycle(projectName, lifecycle.PlacementGroupUpdated.Event(projectName, name, request.CreateRequestor(r), nil))
+
+	return response.EmptySyncResponse
+}
+
+// swagger:operation DELETE /1.0/placement-groups/{name} placement-groups placement_group_delete
+//
+//	Delete the placement group
+//
+//	Removes the placement group. The group must not be used by any instance.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func placementGroupDelete(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		id, _, err := tx.GetPlacementGroup(ctx, projectName, name)
+		if err != nil {
+			return err
+		}
+
+		members, err := placementGroupMembers(ctx, tx, projectName, name)
+		if err != nil {
+			return err
+		}
+
+		if len(members) > 0 {
+			return api.StatusErrorf(http.StatusBadRequest, "The placement group is currently in use")
+		}
+
+		return tx.DeletePlacementGroup(ctx, id)
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	s.Events.SendLifecycle(projectName, lifecycle.PlacementGroupDeleted.Event(projectName, name, request.CreateRequestor(r), nil))
+
+	return response.EmptySyncResponse
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/shared/api"
+)
+
+func Test_placementGroupFilterCandidates(t *testing.T) {
+	candidates := []db.NodeInfo{{Name: "m1"}, {Name: "m2"}, {Name: "m3"}}
+
+	tests := []struct {
+		name       string
+		config     map[string]string
+		counts     map[string]int
+		candidates []db.NodeInfo
+		want       []string
+		wantStatus int
+	}{
+		{
+			name:   "Spread strict without members",
+			config: map[string]string{"policy": "spread", "mode": "strict"},
+			counts: map[string]int{},
+			want:   []string{"m1", "m2", "m3"},
+		},
+		{
+			name:   "Spread strict with a free cluster member",
+			config: map[string]string{},
+			counts: map[string]int{"m1": 1, "m3": 1},
+			want:   []string{"m2"},
+		},
+		{
+			name:       "Spread strict without a free cluster member",
+			config:     map[string]string{"policy": "spread", "mode": "strict"},
+			counts:     map[string]int{"m1": 1, "m2": 1, "m3": 2},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:   "Spread soft without a free cluster member",
+			config: map[string]string{"policy": "spread", "mode": "soft"},
+			counts: map[string]int{"m1": 2, "m2": 1, "m3": 1},
+			want:   []string{"m2", "m3"},
+		},
+		{
+			name:   "Compact strict without members",
+			config: map[string]string{"policy": "compact", "mode": "strict"},
+			counts: map[string]int{},
+			want:   []string{"m1", "m2", "m3"},
+		},
+		{
+			name:   "Compact strict with members",
+			config: map[string]string{"policy": "compact"},
+			counts: map[string]int{"m1": 1, "m2": 2},
+			want:   []string{"m2"},
+		},
+		{
+			name:       "Compact strict with members on unavailable cluster members",
+			config:     map[string]string{"policy": "compact", "mode": "strict"},
+			counts:     map[string]int{"m4": 2},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:   "Compact soft with members on unavailable cluster members",
+			config: map[string]string{"policy": "compact", "mode": "soft"},
+			counts: map[string]int{"m4": 2},
+			want:   []string{"m1", "m2", "m3"},
+		},
+		{
+			name:   "Compact soft with members",
+			config: map[string]string{"policy": "compact", "mode": "soft"},
+			counts: map[string]int{"m1": 1, "m3": 1, "m4": 3},
+			want:   []string{"m1", "m3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			group := &api.PlacementGroup{Name: "group", Config: tt.config}
+
+			got, err := placementGroupFilterCandidates(group, tt.counts, candidates)
+			if tt.wantStatus != 0 {
+				assert.True(t, api.StatusErrorCheck(err, tt.wantStatus), "Unexpected error: %v", err)
+				return
+			}
+
+			assert.NoError(t, err)
+
+			names := make([]string, 0, len(got))
+			for _, member := range got {
+				names = append(names, member.Name)
+			}
+
+			assert.Equal(t, tt.want, names)
+		})
+	}
+}
@@ -423,6 +423,11 @@ func profilesPost(d *Daemon, r *http.Request) response.Response {
 			return fmt.Errorf("The profile already exists")
 		}
 
+		err = placementGroupCheckExists(ctx, tx, p.Name, req.Config)
+		if err != nil {
+			return err
+		}
+
 		profile := dbCluster.Profile{
 			Project:     p.Name,
 			Name:        req.Name,
@@ -18,6 +18,11 @@ import (
 func doProfileUpdate(s *state.State, p api.Project, profileName string, id int64, profile *api.Profile, req api.ProfilePut) error {
 	// Check project limits.
 	err := s.DB.Cluster.Transaction(s.ShutdownCtx, func(ctx context.Context, tx *db.ClusterTx) error {
+		err := placementGroupCheckExists(ctx, tx, p.Name, req.Config)
+		if err != nil {
+			return err
+		}
+
 		return limits.AllowProfileUpdate(ctx, s.GlobalConfig, tx, p.Name, profileName, req)
 	})
 	if err != nil {
@@ -0,0 +1,44 @@
+package limits
+
+import (
+	"fmt"
+
+	"github.com/canonical/lxd/shared/units"
+)
+
+// NetworkRates returns the aggregate ingress and egress rates in bits per second that the network limits of the
+// project allow for the NICs of its instances on each cluster member. A rate of 0 means unlimited.
+func NetworkRates(config map[string]string) (ingress int64, egress int64, err error) {
+	ingress, err = parseNetworkRate(config, "limits.network.ingress")
+	if err != nil {
+		return 0, 0, err
+	}
+
+	egress, err = parseNetworkRate(config, "limits.network.egress")
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return ingress, egress, nil
+}
+
+// ValidateNetworkRate validates a project network rate limit.
+func ValidateNetworkRate(value string) error {
+	_, err := units.ParseBitSizeString(value)
+
+	return err
+}
+
+// parseNetworkRate parses the network rate limit set in the project config key specified.
+func parseNetworkRate(config map[string]string, key string) (int64, error) {
+	if config[key] == "" {
+		return 0, nil
+	}
+
+	rate, err := units.ParseBitSizeString(config[key])
+	if err != nil {
+		return 0, fmt.Errorf("Invalid value for %q: %w", key, err)
+	}
+
+	return rate, nil
+}
@@ -0,0 +1,34 @@
+package limits
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNetworkRates(t *testing.T) {
+	ingress, egress, err := NetworkRates(map[string]string{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), ingress)
+	assert.Equal(t, int64(0), egress)
+
+	ingress, egress, err = NetworkRates(map[string]string{
+		"limits.network.ingress": "100Mbit",
+		"limits.network.egress":  "1Gbit",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(100000000), ingress)
+	assert.Equal(t, int64(1000000000), egress)
+
+	_, _, err = NetworkRates(map[string]string{"limits.network.egress": "foo"})
+	assert.Error(t, err)
+}
+
+func TestValidateNetworkRate(t *testing.T) {
+	assert.NoError(t, ValidateNetworkRate("10Mbit"))
+	assert.NoError(t, ValidateNetworkRate("0"))
+	assert.Error(t, ValidateNetworkRate("10MiB/s"))
+	assert.Error(t, ValidateNetworkRate("-10Mbit"))
+}
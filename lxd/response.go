@@ -75,3 +75,22 @@ func forwardedResponseIfVolumeIsRemote(s *state.State, r *http.Request) response
 
 	return response.ForwardedResponse(client, r)
 }
+
+// forwardedResponseIfNotLeader forwards a request to the cluster leader if the local member isn't the leader.
+func forwardedResponseIfNotLeader(s *state.State, r *http.Request) response.Response {
+	leaderInfo, err := s.LeaderInfo()
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if leaderInfo.Leader {
+		return nil
+	}
+
+	client, err := cluster.Connect(leaderInfo.Address, s.Endpoints.NetworkCert(), s.ServerCert(), r, false)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.ForwardedResponse(client, r)
+}
@@ -567,7 +567,7 @@ func (r *forwardedResponse) Render(w http.ResponseWriter, req *http.Request) err
 	}
 
 	url := info.Addresses[0] + req.URL.RequestURI()
-	forwarded, err := http.NewRequest(req.Method, url, req.Body)
+	forwarded, err := http.NewRequestWithContext(req.Context(), req.Method, url, req.Body)
 	if err != nil {
 		return err
 	}
@@ -586,18 +586,42 @@ func (r *forwardedResponse) Render(w http.ResponseWriter, req *http.Request) err
 		return err
 	}
 
+	defer func() { _ = response.Body.Close() }()
+
 	for key := range response.Header {
 		w.Header().Set(key, response.Header.Get(key))
 	}
 
-	if w.Header().Get("Connection") != "keep-alive" {
+	streamed := w.Header().Get("Connection") == "keep-alive"
+	if !streamed {
 		w.WriteHeader(response.StatusCode)
 	}
 
+	// Flush streamed responses as the data is received so that it isn't held back.
+	f, ok := w.(http.Flusher)
+	if streamed && ok {
+		_, err = io.Copy(&flushWriter{w: w, f: f}, response.Body)
+		return err
+	}
+
 	_, err = io.Copy(w, response.Body)
 	return err
 }
 
+// flushWriter flushes the wrapped writer after each write.
+type flushWriter struct {
+	w io.Writer
+	f http.Flusher
+}
+
+// Write writes to the wrapped writer and flushes it.
+func (fw *flushWriter) Write(p []byte) (int, error) {
+	n, err := fw.w.Write(p)
+	fw.f.Flush()
+
+	return n, err
+}
+
 func (r *forwardedResponse) String() string {
 	return "forwarded response"
 }
@@ -75,6 +75,9 @@ type State struct {
 	// Filesystem monitor
 	DevMonitor fsmonitor.FSMonitor
 
+	// Filesystem monitor for the network state directories
+	NetworkMonitor fsmonitor.FSMonitor
+
 	// Global configuration
 	GlobalConfig *clusterConfig.Config
 
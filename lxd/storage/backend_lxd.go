@@ -33,6 +33,7 @@ import (
 	"github.com/canonical/lxd/lxd/cluster/request"
 	"github.com/canonical/lxd/lxd/db"
 	"github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/idmap"
 	"github.com/canonical/lxd/lxd/instance"
 	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/lxd/instancewriter"
@@ -3336,6 +3337,167 @@ func (b *lxdBackend) GetInstanceUsage(inst instance.Instance) (*VolumeUsage, err
 	return &val, nil
 }
 
+// DiffInstance returns the files of the instance's root volume that differ from the given snapshot, or from the
+// image the instance was created from if snapshot is nil. Paths are relative to the root of the instance.
+func (b *lxdBackend) DiffInstance(inst instance.Instance, snapshot instance.Instance, op *operations.Operation) ([]api.InstanceFileChange, error) {
+	l := b.logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
+	l.Debug("DiffInstance started")
+	defer l.Debug("DiffInstance finished")
+
+	err := b.isStatusReady()
+	if err != nil {
+		return nil, err
+	}
+
+	if inst.Type() != instancetype.Container {
+		return nil, api.StatusErrorf(http.StatusBadRequest, "Diff is only supported for containers")
+	}
+
+	c, ok := inst.(instance.Container)
+	if !ok {
+		return nil, fmt.Errorf("Instance is not container type")
+	}
+
+	// Load the instance volume.
+	dbVol, err := VolumeDBGet(b, inst.Project().Name, inst.Name(), drivers.VolumeTypeContainer)
+	if err != nil {
+		return nil, err
+	}
+
+	vol := b.GetVolume(drivers.VolumeTypeContainer, drivers.ContentTypeFS, project.Instance(inst.Project().Name, inst.Name()), dbVol.Config)
+	err = b.applyInstanceRootDiskOverrides(inst, &vol)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = b.MountInstance(inst, op)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = b.UnmountInstance(inst, op) }()
+
+	var srcVol drivers.Volume
+	var srcPath string
+
+	// The idmap of the source files on disk, nil if unshifted like image volumes.
+	var srcIdmap *idmap.IdmapSet
+
+	if snapshot != nil {
+		snapC, ok := snapshot.(instance.Container)
+		if !ok {
+			return nil, fmt.Errorf("Snapshot is not container type")
+		}
+
+		// The idmap of the instance may have changed since the snapshot was taken.
+		srcIdmap, err = snapC.DiskIdmap()
+		if err != nil {
+			return nil, err
+		}
+
+		dbSnapVol, err := VolumeDBGet(b, snapshot.Project().Name, snapshot.Name(), drivers.VolumeTypeContainer)
+		if err != nil {
+			return nil, err
+		}
+
+		srcVol = b.GetVolume(drivers.VolumeTypeContainer, drivers.ContentTypeFS, project.Instance(snapshot.Project().Name, snapshot.Name()), dbSnapVol.Config)
+
+		_, err = b.MountInstanceSnapshot(snapshot, op)
+		if err != nil {
+			return nil, err
+		}
+
+		defer func() { _ = b.UnmountInstanceSnapshot(snapshot, op) }()
+
+		srcPath = filepath.Join(srcVol.MountPath(), "rootfs")
+	} else {
+		fingerprint := inst.LocalConfig()["volatile.base_image"]
+		if fingerprint == "" {
+			return nil, api.StatusErrorf(http.StatusBadRequest, "Instance wasn't created from an image")
+		}
+
+		// Use the image volume on the pool if there is one, otherwise unpack the image.
+		imgDBVol, err := VolumeDBGet(b, api.ProjectDefaultName, fingerprint, drivers.VolumeTypeImage)
+		if err != nil && !response.IsNotFoundError(err) {
+			return nil, err
+		}
+
+		if imgDBVol != nil {
+			srcVol = b.GetVolume(drivers.VolumeTypeImage, drivers.ContentTypeFS, fingerprint, imgDBVol.Config)
+
+			err = b.driver.MountVolume(srcVol, op)
+			if err != nil {
+				return nil, err
+			}
+
+			defer func() { _, _ = b.driver.UnmountVolume(srcVol, false, op) }()
+
+			srcPath = filepath.Join(srcVol.MountPath(), "rootfs")
+		} else {
+			imageFile := shared.VarPath("images", fingerprint)
+			if !shared.PathExists(imageFile) {
+				return nil, api.StatusErrorf(http.StatusNotFound, "Image %q of the instance isn't available", fingerprint)
+			}
+
+			tmpDir, err := os.MkdirTemp(shared.VarPath("images"), "lxd_diff_")
+			if err != nil {
+				return nil, err
+			}
+
+			defer func() { _ = os.RemoveAll(tmpDir) }()
+
+			err = imageUnpackContainer(imageFile, tmpDir, false, b.state.OS, nil)
+			if err != nil {
+				return nil, err
+			}
+
+			srcPath = filepath.Join(tmpDir, "rootfs")
+		}
+	}
+
+	// The idmap of the instance's files on disk, nil or empty if unshifted.
+	diskIdmap, err := c.DiskIdmap()
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []api.InstanceFileChange
+
+	// Let the driver compute the difference if it can, this is only meaningful when the files on both sides are
+	// shifted the same way as otherwise the ownership of every file differs.
+	if srcVol.Name() != "" && diskIdmap.Equals(srcIdmap) {
+		volChanges, err := b.driver.DiffVolume(vol, srcVol, op)
+		if err != nil && !errors.Is(err, drivers.ErrNotSupported) {
+			return nil, err
+		}
+
+		if err == nil {
+			changes = []api.InstanceFileChange{}
+			for _, change := range volChanges {
+				path, ok := strings.CutPrefix(change.Path, "/rootfs/")
+				if !ok {
+					continue
+				}
+
+				changes = append(changes, api.InstanceFileChange{Path: "/" + path, Type: change.Type})
+			}
+		}
+	}
+
+	if changes == nil {
+		changes, err = drivers.DiffPaths(srcPath, filepath.Join(vol.MountPath(), "rootfs"), srcIdmap, diskIdmap)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	slices.SortFunc(changes, func(a api.InstanceFileChange, b api.InstanceFileChange) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	return changes, nil
+}
+
 // SetInstanceQuota sets the quota on the instance's root volume.
 // Returns ErrInUse if the instance is running and the storage driver doesn't support online resizing.
 func (b *lxdBackend) SetInstanceQuota(inst instance.Instance, size string, vmStateSize string, op *operations.Operation) error {
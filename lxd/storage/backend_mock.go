@@ -232,6 +232,11 @@ func (b *mockBackend) GetInstanceUsage(inst instance.Instance) (*VolumeUsage, er
 	return nil, nil
 }
 
+// DiffInstance ...
+func (b *mockBackend) DiffInstance(inst instance.Instance, snapshot instance.Instance, op *operations.Operation) ([]api.InstanceFileChange, error) {
+	return nil, nil
+}
+
 // SetInstanceQuota ...
 func (b *mockBackend) SetInstanceQuota(inst instance.Instance, size string, vmStateSize string, op *operations.Operation) error {
 	return nil
@@ -656,3 +656,226 @@ func (d *btrfs) receiveSubVolume(r io.Reader, receivePath string, tracker *iopro
 
 	return subVolPath, nil
 }
+
+// parseBtrfsDump parses the output of "btrfs receive --dump" for an incremental send stream into changes relative
+// to the root of the sent subvolume.
+// New files are created under temporary names and then renamed, so the paths are tracked until the end of the
+// stream. Renamed files are reported as removed from their old path and added at their new path.
+func parseBtrfsDump(output string) ([]api.InstanceFileChange, error) {
+	root := ""
+	created := map[string]bool{}   // Files created by the stream, by current path.
+	renamed := map[string]string{} // Original path of existing files that were renamed, by current path.
+	removed := map[string]bool{}   // Original path of existing files that were removed.
+	modified := map[string]bool{}  // Existing files that were modified, by current path.
+
+	// relPath converts an escaped path from the output into a path relative to the root of the subvolume.
+	relPath := func(path string) (string, error) {
+		path = btrfsDumpUnescape(path)
+		if path != root && !strings.HasPrefix(path, root+"/") {
+			return "", fmt.Errorf("Path %q is outside of subvolume %q", path, root)
+		}
+
+		return strings.TrimSuffix(strings.TrimPrefix(path, root), "/"), nil
+	}
+
+	// move renames the tracked path and any tracked path below it.
+	move := func(paths map[string]bool, from string, to string) {
+		for path := range paths {
+			if path == from || strings.HasPrefix(path, from+"/") {
+				delete(paths, path)
+				paths[to+strings.TrimPrefix(path, from)] = true
+			}
+		}
+	}
+
+	// originalPath returns the path that an existing file had before it or one of its parents was renamed.
+	originalPath := func(path string) string {
+		for current, original := range renamed {
+			if path == current || strings.HasPrefix(path, current+"/") {
+				return original + strings.TrimPrefix(path, current)
+			}
+		}
+
+		return path
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		fields := btrfsDumpFields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("Invalid btrfs dump line %q", line)
+		}
+
+		if fields[0] == "snapshot" || fields[0] == "subvol" {
+			root = btrfsDumpUnescape(fields[1])
+			continue
+		}
+
+		if root == "" {
+			return nil, fmt.Errorf("Missing btrfs dump subvolume line before %q", line)
+		}
+
+		path, err := relPath(fields[1])
+		if err != nil {
+			return nil, err
+		}
+
+		switch fields[0] {
+		case "mkfile", "mkdir", "mknod", "mkfifo", "mksock", "symlink", "link":
+			created[path] = true
+		case "rename":
+			dest := ""
+			for _, field := range fields[2:] {
+				value, ok := strings.CutPrefix(field, "dest=")
+				if ok {
+					dest, err = relPath(value)
+					if err != nil {
+						return nil, err
+					}
+				}
+			}
+
+			if dest == "" {
+				return nil, fmt.Errorf("Invalid btrfs dump line %q", line)
+			}
+
+			original := originalPath(path)
+			for current, currentOriginal := range renamed {
+				if current == path || strings.HasPrefix(current, path+"/") {
+					delete(renamed, current)
+					renamed[dest+strings.TrimPrefix(current, path)] = currentOriginal
+				}
+			}
+
+			if !created[path] {
+				renamed[dest] = original
+			}
+
+			move(created, path, dest)
+			move(modified, path, dest)
+		case "unlink", "rmdir":
+			if created[path] {
+				delete(created, path)
+			} else {
+				removed[originalPath(path)] = true
+				delete(renamed, path)
+			}
+
+			delete(modified, path)
+		default:
+			// Any other command changes the content or the metadata of the file.
+			if !created[path] {
+				modified[path] = true
+			}
+		}
+	}
+
+	changes := []api.InstanceFileChange{}
+	addChange := func(path string, changeType string) {
+		if path != "" {
+			changes = append(changes, api.InstanceFileChange{Path: path, Type: changeType})
+		}
+	}
+
+	for path := range created {
+		addChange(path, api.InstanceFileChangeAdded)
+	}
+
+	for path := range removed {
+		addChange(path, api.InstanceFileChangeRemoved)
+	}
+
+	for current, original := range renamed {
+		if current != original {
+			addChange(original, api.InstanceFileChangeRemoved)
+			addChange(current, api.InstanceFileChangeAdded)
+		} else if modified[current] {
+			addChange(current, api.InstanceFileChangeModified)
+		}
+	}
+
+	for path := range modified {
+		if renamed[path] == "" {
+			addChange(path, api.InstanceFileChangeModified)
+		}
+	}
+
+	sort.Slice(changes, func(i int, j int) bool {
+		if changes[i].Path != changes[j].Path {
+			return changes[i].Path < changes[j].Path
+		}
+
+		return changes[i].Type < changes[j].Type
+	})
+
+	return changes, nil
+}
+
+// btrfsDumpFields splits a line of "btrfs receive --dump" output into its fields, which are separated by
+// whitespace. Whitespace in paths is escaped with a backslash.
+func btrfsDumpFields(line string) []string {
+	fields := []string{}
+
+	var sb strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line):
+			sb.WriteByte(line[i])
+			sb.WriteByte(line[i+1])
+			i++
+		case line[i] == ' ' || line[i] == '\t':
+			if sb.Len() > 0 {
+				fields = append(fields, sb.String())
+				sb.Reset()
+			}
+
+		default:
+			sb.WriteByte(line[i])
+		}
+	}
+
+	if sb.Len() > 0 {
+		fields = append(fields, sb.String())
+	}
+
+	return fields
+}
+
+// btrfsDumpUnescape reverses the escaping of paths by "btrfs receive --dump", which prints whitespace, backslashes
+// and control characters as C-style escape sequences and other non-printable characters as a backslash followed
+// by three octal digits.
+func btrfsDumpUnescape(path string) string {
+	if !strings.Contains(path, "\\") {
+		return path
+	}
+
+	escapes := map[byte]byte{'a': '\a', 'b': '\b', 'e': 0x1b, 'f': '\f', 'n': '\n', 'r': '\r', 't': '\t', 'v': '\v', ' ': ' ', '\\': '\\'}
+
+	var sb strings.Builder
+	for i := 0; i < len(path); i++ {
+		if path[i] == '\\' && i+1 < len(path) {
+			char, ok := escapes[path[i+1]]
+			if ok {
+				sb.WriteByte(char)
+				i++
+				continue
+			}
+
+			if i+3 < len(path) {
+				value, err := strconv.ParseUint(path[i+1:i+4], 8, 8)
+				if err == nil {
+					sb.WriteByte(byte(value))
+					i += 3
+					continue
+				}
+			}
+		}
+
+		sb.WriteByte(path[i])
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,63 @@
+package drivers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+func TestParseBtrfsDump(t *testing.T) {
+	output := `snapshot        ./c1                            uuid=0b1b2f0c-6f4a-4a43-9d0c-6c8a8c1a2b3c transid=42 parent_uuid=5f0e0d6e-8a1f-4b9e-9a0e-2c3d4e5f6a7b parent_transid=12
+utimes          ./c1/                           atime=2024-01-01T00:00:00+0000 mtime=2024-01-01T00:00:00+0000 ctime=2024-01-01T00:00:00+0000
+update_extent   ./c1/rootfs/etc/hosts           offset=0 len=24
+utimes          ./c1/rootfs/etc/hosts           atime=2024-01-01T00:00:00+0000 mtime=2024-01-01T00:00:00+0000 ctime=2024-01-01T00:00:00+0000
+mkfile          ./c1/o257-42-0
+rename          ./c1/o257-42-0                  dest=./c1/rootfs/etc/my\ file
+chmod           ./c1/rootfs/etc/my\ file        mode=644
+mkdir           ./c1/o258-42-0
+rename          ./c1/o258-42-0                  dest=./c1/rootfs/srv
+mkfile          ./c1/o259-42-0
+rename          ./c1/o259-42-0                  dest=./c1/rootfs/srv/data
+rename          ./c1/rootfs/etc/a               dest=./c1/rootfs/etc/b
+unlink          ./c1/rootfs/etc/localtime
+rename          ./c1/rootfs/etc/old             dest=./c1/o260-12-0
+unlink          ./c1/o260-12-0/file
+rmdir           ./c1/o260-12-0
+mkfile          ./c1/o261-42-0
+rename          ./c1/o261-42-0                  dest=./c1/rootfs/tmp/scratch
+unlink          ./c1/rootfs/tmp/scratch
+chown           ./c1/rootfs/etc/back\\slash     gid=0 uid=1000
+`
+
+	changes, err := parseBtrfsDump(output)
+	assert.NoError(t, err)
+	assert.Equal(t, []api.InstanceFileChange{
+		{Path: "/rootfs/etc/a", Type: api.InstanceFileChangeRemoved},
+		{Path: "/rootfs/etc/b", Type: api.InstanceFileChangeAdded},
+		{Path: "/rootfs/etc/back\\slash", Type: api.InstanceFileChangeModified},
+		{Path: "/rootfs/etc/hosts", Type: api.InstanceFileChangeModified},
+		{Path: "/rootfs/etc/localtime", Type: api.InstanceFileChangeRemoved},
+		{Path: "/rootfs/etc/my file", Type: api.InstanceFileChangeAdded},
+		{Path: "/rootfs/etc/old", Type: api.InstanceFileChangeRemoved},
+		{Path: "/rootfs/etc/old/file", Type: api.InstanceFileChangeRemoved},
+		{Path: "/rootfs/srv", Type: api.InstanceFileChangeAdded},
+		{Path: "/rootfs/srv/data", Type: api.InstanceFileChangeAdded},
+	}, changes)
+
+	_, err = parseBtrfsDump("mkfile          ./c1/o257-42-0\n")
+	assert.Error(t, err)
+
+	_, err = parseBtrfsDump("snapshot        ./c1\nmkfile          ./c2/o257-42-0\n")
+	assert.Error(t, err)
+}
+
+func TestBtrfsDumpUnescape(t *testing.T) {
+	assert.Equal(t, "./c1/etc/hosts", btrfsDumpUnescape("./c1/etc/hosts"))
+	assert.Equal(t, "./c1/etc/my file", btrfsDumpUnescape("./c1/etc/my\\ file"))
+	assert.Equal(t, "./c1/etc/back\\slash", btrfsDumpUnescape("./c1/etc/back\\\\slash"))
+	assert.Equal(t, "./c1/etc/new\nline", btrfsDumpUnescape("./c1/etc/new\\nline"))
+	assert.Equal(t, "./c1/etc/\x7f", btrfsDumpUnescape("./c1/etc/\\177"))
+	assert.Equal(t, "./c1/etc/trailing\\", btrfsDumpUnescape("./c1/etc/trailing\\"))
+}
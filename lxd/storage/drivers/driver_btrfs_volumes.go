@@ -1868,6 +1868,49 @@ func (d *btrfs) RestoreVolume(vol Volume, snapVol Volume, op *operations.Operati
 	return d.deleteSubvolume(backupSubvolume, true)
 }
 
+// DiffVolume returns the paths that differ between srcVol and vol using an incremental "btrfs send" stream without
+// file data, which only needs to compare the metadata of the subvolumes.
+func (d *btrfs) DiffVolume(vol Volume, srcVol Volume, op *operations.Operation) ([]api.InstanceFileChange, error) {
+	if vol.contentType != ContentTypeFS || (srcVol.volType != VolumeTypeImage && !srcVol.IsSnapshot()) {
+		return nil, ErrNotSupported
+	}
+
+	// The send stream of a subvolume doesn't include the subvolumes nested in it.
+	for _, path := range []string{vol.MountPath(), srcVol.MountPath()} {
+		subVolPaths, err := d.getSubvolumes(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(subVolPaths) > 0 {
+			return nil, ErrNotSupported
+		}
+	}
+
+	// Only read-only subvolumes can be sent, so send a read-only snapshot of the volume.
+	snapshotPath, cleanup, err := d.readonlySnapshot(vol)
+	if err != nil {
+		return nil, err
+	}
+
+	defer cleanup()
+
+	// The stream is written next to the snapshot so that it gets removed with it.
+	streamPath := filepath.Join(filepath.Dir(snapshotPath), "diff.stream")
+
+	_, err = shared.RunCommandContext(d.state.ShutdownCtx, "btrfs", "send", "--no-data", "-q", "-p", srcVol.MountPath(), "-f", streamPath, snapshotPath)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := shared.RunCommandContext(d.state.ShutdownCtx, "btrfs", "receive", "--dump", "-f", streamPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseBtrfsDump(output)
+}
+
 // RenameVolumeSnapshot renames a volume snapshot.
 func (d *btrfs) RenameVolumeSnapshot(snapVol Volume, newSnapshotName string, op *operations.Operation) error {
 	return genericVFSRenameVolumeSnapshot(d, snapVol, newSnapshotName, op)
@@ -415,6 +415,12 @@ func (d *common) DelegateVolume(vol Volume, pid int) error {
 	return nil
 }
 
+// DiffVolume returns the paths that differ between srcVol and vol.
+// Drivers that can't compare volumes cheaply return ErrNotSupported, so the caller compares the mounted volumes.
+func (d *common) DiffVolume(vol Volume, srcVol Volume, op *operations.Operation) ([]api.InstanceFileChange, error) {
+	return nil, ErrNotSupported
+}
+
 // RenameVolume renames the volume and all related filesystem entries.
 func (d *common) RenameVolume(vol Volume, newVolName string, op *operations.Operation) error {
 	return ErrNotSupported
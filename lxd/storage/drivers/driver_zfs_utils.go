@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/google/uuid"
@@ -538,3 +539,84 @@ func (d *zfs) delegateDataset(vol Volume, pid int) error {
 func ZFSSupportsDelegation() bool {
 	return zfsDelegate
 }
+
+// parseZFSDiff parses the output of "zfs diff -H -F" into changes relative to mountPath, the mount path of the
+// compared dataset. Renamed paths are reported as removed from their old path and added at their new path.
+func parseZFSDiff(output string, mountPath string) ([]api.InstanceFileChange, error) {
+	changes := []api.InstanceFileChange{}
+
+	// relPath converts an escaped path from the output into a path relative to mountPath.
+	relPath := func(path string) (string, bool) {
+		path = zfsDiffUnescape(path)
+		if path == mountPath || !strings.HasPrefix(path, mountPath+"/") {
+			return "", false
+		}
+
+		return strings.TrimPrefix(path, mountPath), true
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		if line == "" {
+			continue
+		}
+
+		// Each line contains the change type, the file type, the path and, for renames, the new path.
+		fields := strings.Split(line, "\t")
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("Invalid zfs diff output line %q", line)
+		}
+
+		path, ok := relPath(fields[2])
+		if !ok {
+			continue
+		}
+
+		switch fields[0] {
+		case "+":
+			changes = append(changes, api.InstanceFileChange{Path: path, Type: api.InstanceFileChangeAdded})
+		case "-":
+			changes = append(changes, api.InstanceFileChange{Path: path, Type: api.InstanceFileChangeRemoved})
+		case "M":
+			changes = append(changes, api.InstanceFileChange{Path: path, Type: api.InstanceFileChangeModified})
+		case "R":
+			if len(fields) < 4 {
+				return nil, fmt.Errorf("Invalid zfs diff output line %q", line)
+			}
+
+			changes = append(changes, api.InstanceFileChange{Path: path, Type: api.InstanceFileChangeRemoved})
+
+			newPath, ok := relPath(fields[3])
+			if ok {
+				changes = append(changes, api.InstanceFileChange{Path: newPath, Type: api.InstanceFileChangeAdded})
+			}
+		default:
+			return nil, fmt.Errorf("Unknown zfs diff change type %q", fields[0])
+		}
+	}
+
+	return changes, nil
+}
+
+// zfsDiffUnescape reverses the escaping of "zfs diff", which prints spaces, backslashes and non-printable
+// characters of paths as a backslash followed by four octal digits.
+func zfsDiffUnescape(path string) string {
+	if !strings.Contains(path, "\\") {
+		return path
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(path); i++ {
+		if path[i] == '\\' && i+4 < len(path) {
+			value, err := strconv.ParseUint(path[i+1:i+5], 8, 8)
+			if err == nil {
+				sb.WriteByte(byte(value))
+				i += 4
+				continue
+			}
+		}
+
+		sb.WriteByte(path[i])
+	}
+
+	return sb.String()
+}
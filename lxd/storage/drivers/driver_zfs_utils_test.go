@@ -0,0 +1,40 @@
+package drivers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+func TestParseZFSDiff(t *testing.T) {
+	mountPath := "/var/lib/lxd/storage-pools/default/containers/c1"
+	output := "M\t/\t/var/lib/lxd/storage-pools/default/containers/c1/rootfs/etc\n" +
+		"M\tF\t/var/lib/lxd/storage-pools/default/containers/c1/rootfs/etc/hosts\n" +
+		"+\tF\t/var/lib/lxd/storage-pools/default/containers/c1/rootfs/etc/my\\0040file\n" +
+		"-\t@\t/var/lib/lxd/storage-pools/default/containers/c1/rootfs/etc/localtime\n" +
+		"R\tF\t/var/lib/lxd/storage-pools/default/containers/c1/rootfs/etc/a\t/var/lib/lxd/storage-pools/default/containers/c1/rootfs/etc/b\n" +
+		"M\t/\t/var/lib/lxd/storage-pools/default/containers/c1\n"
+
+	changes, err := parseZFSDiff(output, mountPath)
+	assert.NoError(t, err)
+	assert.Equal(t, []api.InstanceFileChange{
+		{Path: "/rootfs/etc", Type: api.InstanceFileChangeModified},
+		{Path: "/rootfs/etc/hosts", Type: api.InstanceFileChangeModified},
+		{Path: "/rootfs/etc/my file", Type: api.InstanceFileChangeAdded},
+		{Path: "/rootfs/etc/localtime", Type: api.InstanceFileChangeRemoved},
+		{Path: "/rootfs/etc/a", Type: api.InstanceFileChangeRemoved},
+		{Path: "/rootfs/etc/b", Type: api.InstanceFileChangeAdded},
+	}, changes)
+
+	_, err = parseZFSDiff("X\tF\t"+mountPath+"/rootfs/etc/hosts\n", mountPath)
+	assert.Error(t, err)
+}
+
+func TestZFSDiffUnescape(t *testing.T) {
+	assert.Equal(t, "/etc/hosts", zfsDiffUnescape("/etc/hosts"))
+	assert.Equal(t, "/etc/my file", zfsDiffUnescape("/etc/my\\0040file"))
+	assert.Equal(t, "/etc/back\\slash", zfsDiffUnescape("/etc/back\\0134slash"))
+	assert.Equal(t, "/etc/trailing\\", zfsDiffUnescape("/etc/trailing\\"))
+}
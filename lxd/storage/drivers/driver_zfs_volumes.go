@@ -3451,6 +3451,42 @@ func (d *zfs) restoreVolume(vol Volume, snapVol Volume, migration bool, op *oper
 	return nil
 }
 
+// DiffVolume returns the paths that differ between srcVol and vol using "zfs diff", which only needs to look
+// at the blocks that changed since the snapshot that vol is compared against.
+func (d *zfs) DiffVolume(vol Volume, srcVol Volume, op *operations.Operation) ([]api.InstanceFileChange, error) {
+	if vol.contentType != ContentTypeFS || d.isBlockBacked(vol) || d.isBlockBacked(srcVol) {
+		return nil, ErrNotSupported
+	}
+
+	dataset := d.dataset(vol, false)
+
+	var srcSnapshot string
+	if srcVol.volType == VolumeTypeImage {
+		srcSnapshot = d.dataset(srcVol, false) + "@readonly"
+
+		// A dataset can only be compared against the snapshot it was cloned from.
+		origin, err := d.getDatasetProperty(dataset, "origin")
+		if err != nil {
+			return nil, err
+		}
+
+		if origin != srcSnapshot {
+			return nil, ErrNotSupported
+		}
+	} else if srcVol.IsSnapshot() {
+		srcSnapshot = d.dataset(srcVol, false)
+	} else {
+		return nil, ErrNotSupported
+	}
+
+	output, err := shared.RunCommand("zfs", "diff", "-H", "-F", srcSnapshot, dataset)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseZFSDiff(output, vol.MountPath())
+}
+
 // RenameVolumeSnapshot renames a volume snapshot.
 func (d *zfs) RenameVolumeSnapshot(vol Volume, newSnapshotName string, op *operations.Operation) error {
 	parentName, _, _ := api.GetParentAndSnapshotName(vol.name)
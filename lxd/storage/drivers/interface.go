@@ -103,6 +103,10 @@ type Driver interface {
 	CheckVolumeSnapshots(vol Volume, snapVols []Volume, op *operations.Operation) error
 	RestoreVolume(vol Volume, snapVol Volume, op *operations.Operation) error
 
+	// DiffVolume returns the paths that differ between srcVol and vol, relative to the volume's mount path.
+	// The srcVol is either a snapshot of vol or the image volume that vol was created from.
+	DiffVolume(vol Volume, srcVol Volume, op *operations.Operation) ([]api.InstanceFileChange, error)
+
 	// Migration.
 	MigrationTypes(contentType ContentType, refresh bool, copySnapshots bool) []migration.Type
 	MigrateVolume(vol VolumeCopy, conn io.ReadWriteCloser, volSrcArgs *migration.VolumeSourceArgs, op *operations.Operation) error
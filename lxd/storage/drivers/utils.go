@@ -749,6 +749,290 @@ func ShiftZFSSkipper(dir string, absPath string, fi os.FileInfo) bool {
 	return false
 }
 
+// DiffPaths returns the paths that differ between the srcPath and dstPath directory trees, relative to them.
+// Like rsync, files are compared by their metadata (type, permissions, ownership, modification time, size and
+// symlink target or device number) rather than by their content. The size of directories is ignored.
+// The ownership of the files of each tree is shifted back using the idmap of that tree, if set, before being
+// compared. This allows comparing trees that are shifted differently, such as a shifted container root filesystem
+// and the unshifted image it was created from, or a snapshot taken before the container's idmap changed.
+// The trees are walked relative to the file descriptors of their directories without following symlinks, so that
+// a running instance can't redirect the walk outside of its tree by replacing a directory with a symlink.
+func DiffPaths(srcPath string, dstPath string, srcIdmap *idmap.IdmapSet, dstIdmap *idmap.IdmapSet) ([]api.InstanceFileChange, error) {
+	changes := []api.InstanceFileChange{}
+
+	// owner returns the unshifted owner of a file of the tree with the given idmap.
+	owner := func(stat *unix.Stat_t, set *idmap.IdmapSet) (int64, int64) {
+		uid, gid := int64(stat.Uid), int64(stat.Gid)
+		if set != nil {
+			uid, gid = set.ShiftFromNs(uid, gid)
+		}
+
+		return uid, gid
+	}
+
+	// isDir returns whether the file is a directory.
+	isDir := func(stat *unix.Stat_t) bool {
+		return stat.Mode&unix.S_IFMT == unix.S_IFDIR
+	}
+
+	// openDir opens the name directory of the parent directory without following symlinks.
+	openDir := func(parent *os.File, name string) (*os.File, error) {
+		path := filepath.Join(parent.Name(), name)
+
+		fd, err := unix.Openat(int(parent.Fd()), name, unix.O_RDONLY|unix.O_DIRECTORY|unix.O_NOFOLLOW|unix.O_CLOEXEC, 0)
+		if err != nil {
+			return nil, &os.PathError{Op: "openat", Path: path, Err: err}
+		}
+
+		return os.NewFile(uintptr(fd), path), nil
+	}
+
+	// readDir returns the names of the entries of the directory sorted by name.
+	readDir := func(dir *os.File) ([]string, error) {
+		names, err := dir.Readdirnames(-1)
+		if err != nil {
+			return nil, err
+		}
+
+		sort.Strings(names)
+
+		return names, nil
+	}
+
+	// lstat returns the information of the name entry of the directory without following symlinks.
+	lstat := func(dir *os.File, name string) (*unix.Stat_t, error) {
+		var stat unix.Stat_t
+
+		err := unix.Fstatat(int(dir.Fd()), name, &stat, unix.AT_SYMLINK_NOFOLLOW)
+		if err != nil {
+			return nil, &os.PathError{Op: "fstatat", Path: filepath.Join(dir.Name(), name), Err: err}
+		}
+
+		return &stat, nil
+	}
+
+	// readlink returns the target of the name symlink of the directory.
+	readlink := func(dir *os.File, name string) (string, error) {
+		buf := make([]byte, unix.PathMax)
+
+		n, err := unix.Readlinkat(int(dir.Fd()), name, buf)
+		if err != nil {
+			return "", &os.PathError{Op: "readlinkat", Path: filepath.Join(dir.Name(), name), Err: err}
+		}
+
+		return string(buf[:n]), nil
+	}
+
+	// addChildren records everything below the name directory of parent, which is at relPath in the tree.
+	var addChildren func(parent *os.File, name string, relPath string, changeType string) error
+	addChildren = func(parent *os.File, name string, relPath string, changeType string) error {
+		dir, err := openDir(parent, name)
+		if err != nil {
+			return err
+		}
+
+		defer func() { _ = dir.Close() }()
+
+		names, err := readDir(dir)
+		if err != nil {
+			return err
+		}
+
+		for _, childName := range names {
+			childPath := filepath.Join(relPath, childName)
+			changes = append(changes, api.InstanceFileChange{Path: "/" + childPath, Type: changeType})
+
+			stat, err := lstat(dir, childName)
+			if err != nil {
+				return err
+			}
+
+			if isDir(stat) {
+				err = addChildren(dir, childName, childPath, changeType)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	}
+
+	// differs returns whether the name entry differs between the directories of the trees.
+	differs := func(srcDir *os.File, dstDir *os.File, name string, srcStat *unix.Stat_t, dstStat *unix.Stat_t) (bool, error) {
+		if srcStat.Mode != dstStat.Mode {
+			return true, nil
+		}
+
+		srcUID, srcGID := owner(srcStat, srcIdmap)
+		dstUID, dstGID := owner(dstStat, dstIdmap)
+		if srcUID != dstUID || srcGID != dstGID {
+			return true, nil
+		}
+
+		switch srcStat.Mode & unix.S_IFMT {
+		case unix.S_IFLNK:
+			srcTarget, err := readlink(srcDir, name)
+			if err != nil {
+				return false, err
+			}
+
+			dstTarget, err := readlink(dstDir, name)
+			if err != nil {
+				return false, err
+			}
+
+			return srcTarget != dstTarget, nil
+		case unix.S_IFBLK, unix.S_IFCHR:
+			return srcStat.Rdev != dstStat.Rdev, nil
+		case unix.S_IFDIR:
+			return srcStat.Mtim != dstStat.Mtim, nil
+		}
+
+		return srcStat.Size != dstStat.Size || srcStat.Mtim != dstStat.Mtim, nil
+	}
+
+	var diffChildren func(srcParent *os.File, dstParent *os.File, name string, relPath string) error
+
+	var diffDir func(srcDir *os.File, dstDir *os.File, relPath string) error
+	diffDir = func(srcDir *os.File, dstDir *os.File, relPath string) error {
+		// The entries are sorted by name, which allows walking both directories at once.
+		srcNames, err := readDir(srcDir)
+		if err != nil {
+			return err
+		}
+
+		dstNames, err := readDir(dstDir)
+		if err != nil {
+			return err
+		}
+
+		i, j := 0, 0
+		for i < len(srcNames) || j < len(dstNames) {
+			if j >= len(dstNames) || (i < len(srcNames) && srcNames[i] < dstNames[j]) {
+				name := srcNames[i]
+				entryPath := filepath.Join(relPath, name)
+				changes = append(changes, api.InstanceFileChange{Path: "/" + entryPath, Type: api.InstanceFileChangeRemoved})
+
+				srcStat, err := lstat(srcDir, name)
+				if err != nil {
+					return err
+				}
+
+				if isDir(srcStat) {
+					err = addChildren(srcDir, name, entryPath, api.InstanceFileChangeRemoved)
+					if err != nil {
+						return err
+					}
+				}
+
+				i++
+				continue
+			}
+
+			if i >= len(srcNames) || dstNames[j] < srcNames[i] {
+				name := dstNames[j]
+				entryPath := filepath.Join(relPath, name)
+				changes = append(changes, api.InstanceFileChange{Path: "/" + entryPath, Type: api.InstanceFileChangeAdded})
+
+				dstStat, err := lstat(dstDir, name)
+				if err != nil {
+					return err
+				}
+
+				if isDir(dstStat) {
+					err = addChildren(dstDir, name, entryPath, api.InstanceFileChangeAdded)
+					if err != nil {
+						return err
+					}
+				}
+
+				j++
+				continue
+			}
+
+			name := srcNames[i]
+			entryPath := filepath.Join(relPath, name)
+			i++
+			j++
+
+			srcStat, err := lstat(srcDir, name)
+			if err != nil {
+				return err
+			}
+
+			dstStat, err := lstat(dstDir, name)
+			if err != nil {
+				return err
+			}
+
+			modified, err := differs(srcDir, dstDir, name, srcStat, dstStat)
+			if err != nil {
+				return err
+			}
+
+			if modified {
+				changes = append(changes, api.InstanceFileChange{Path: "/" + entryPath, Type: api.InstanceFileChangeModified})
+			}
+
+			// Descend into directories, or record their content if a directory was replaced by another type.
+			if isDir(srcStat) && isDir(dstStat) {
+				err = diffChildren(srcDir, dstDir, name, entryPath)
+			} else if isDir(srcStat) {
+				err = addChildren(srcDir, name, entryPath, api.InstanceFileChangeRemoved)
+			} else if isDir(dstStat) {
+				err = addChildren(dstDir, name, entryPath, api.InstanceFileChangeAdded)
+			}
+
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	// diffChildren compares the name directories of the directories of the trees, which are at relPath in the trees.
+	diffChildren = func(srcParent *os.File, dstParent *os.File, name string, relPath string) error {
+		srcDir, err := openDir(srcParent, name)
+		if err != nil {
+			return err
+		}
+
+		defer func() { _ = srcDir.Close() }()
+
+		dstDir, err := openDir(dstParent, name)
+		if err != nil {
+			return err
+		}
+
+		defer func() { _ = dstDir.Close() }()
+
+		return diffDir(srcDir, dstDir, relPath)
+	}
+
+	srcDir, err := os.Open(srcPath)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = srcDir.Close() }()
+
+	dstDir, err := os.Open(dstPath)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = dstDir.Close() }()
+
+	err = diffDir(srcDir, dstDir, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
+
 // OperationLockName returns the storage specific lock name to use with locking package.
 func OperationLockName(operationName string, poolName string, volType VolumeType, contentType ContentType, volName string) string {
 	return operationName + "/" + poolName + "/" + string(volType) + "/" + string(contentType) + "/" + volName
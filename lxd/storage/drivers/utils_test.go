@@ -1,9 +1,16 @@
 package drivers
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canonical/lxd/lxd/idmap"
+	"github.com/canonical/lxd/shared/api"
 )
 
 // Test GetVolumeMountPath.
@@ -35,3 +42,109 @@ func TestGetVolumeMountPath(t *testing.T) {
 	expected = GetPoolMountPath(poolName) + "/virtual-machines/testvol"
 	assert.Equal(t, expected, path)
 }
+
+func TestDiffPaths(t *testing.T) {
+	srcPath := t.TempDir()
+	dstPath := t.TempDir()
+	mtime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Create the same tree in both paths.
+	for _, root := range []string{srcPath, dstPath} {
+		require.NoError(t, os.MkdirAll(filepath.Join(root, "etc", "old"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(root, "etc", "hosts"), []byte("127.0.0.1 localhost\n"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(root, "etc", "hostname"), []byte("c1\n"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(root, "etc", "old", "file"), []byte("old\n"), 0644))
+		require.NoError(t, os.Symlink("hosts", filepath.Join(root, "etc", "link")))
+	}
+
+	// Modify the destination tree.
+	require.NoError(t, os.WriteFile(filepath.Join(dstPath, "etc", "hosts"), []byte("127.0.0.1 localhost c1\n"), 0644))
+	require.NoError(t, os.Chmod(filepath.Join(dstPath, "etc", "hostname"), 0600))
+	require.NoError(t, os.RemoveAll(filepath.Join(dstPath, "etc", "old")))
+	require.NoError(t, os.Remove(filepath.Join(dstPath, "etc", "link")))
+	require.NoError(t, os.Symlink("hostname", filepath.Join(dstPath, "etc", "link")))
+	require.NoError(t, os.MkdirAll(filepath.Join(dstPath, "srv", "new"), 0755))
+
+	// Align the modification times of the directories and unchanged files.
+	for _, root := range []string{srcPath, dstPath} {
+		for _, path := range []string{"etc/hostname", "etc/hosts", "etc", "srv/new", "srv"} {
+			_ = os.Chtimes(filepath.Join(root, path), mtime, mtime)
+		}
+	}
+
+	changes, err := DiffPaths(srcPath, dstPath, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []api.InstanceFileChange{
+		{Path: "/etc/hostname", Type: api.InstanceFileChangeModified},
+		{Path: "/etc/hosts", Type: api.InstanceFileChangeModified},
+		{Path: "/etc/link", Type: api.InstanceFileChangeModified},
+		{Path: "/etc/old", Type: api.InstanceFileChangeRemoved},
+		{Path: "/etc/old/file", Type: api.InstanceFileChangeRemoved},
+		{Path: "/srv", Type: api.InstanceFileChangeAdded},
+		{Path: "/srv/new", Type: api.InstanceFileChangeAdded},
+	}, changes)
+}
+
+func TestDiffPathsIdmaps(t *testing.T) {
+	srcPath := t.TempDir()
+	dstPath := t.TempDir()
+	mtime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Create the same file in both paths, owned by the current user.
+	for _, root := range []string{srcPath, dstPath} {
+		require.NoError(t, os.WriteFile(filepath.Join(root, "file"), []byte("content\n"), 0644))
+		require.NoError(t, os.Chtimes(filepath.Join(root, "file"), mtime, mtime))
+	}
+
+	// newIdmap returns an idmap that maps the current user to the given ID in the namespace.
+	newIdmap := func(nsid int64) *idmap.IdmapSet {
+		return &idmap.IdmapSet{Idmap: []idmap.IdmapEntry{
+			{Isuid: true, Hostid: int64(os.Getuid()), Nsid: nsid, Maprange: 1},
+			{Isgid: true, Hostid: int64(os.Getgid()), Nsid: nsid, Maprange: 1},
+		}}
+	}
+
+	// Both trees are shifted back with their own idmap.
+	changes, err := DiffPaths(srcPath, dstPath, newIdmap(5000), newIdmap(5000))
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+
+	changes, err = DiffPaths(srcPath, dstPath, newIdmap(5000), newIdmap(6000))
+	assert.NoError(t, err)
+	assert.Equal(t, []api.InstanceFileChange{{Path: "/file", Type: api.InstanceFileChangeModified}}, changes)
+
+	// Only the shifted tree is shifted back.
+	changes, err = DiffPaths(srcPath, dstPath, nil, newIdmap(5000))
+	assert.NoError(t, err)
+	assert.Equal(t, []api.InstanceFileChange{{Path: "/file", Type: api.InstanceFileChangeModified}}, changes)
+}
+
+func TestDiffPathsSymlinks(t *testing.T) {
+	srcPath := t.TempDir()
+	dstPath := t.TempDir()
+	outsidePath := t.TempDir()
+	mtime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	require.NoError(t, os.WriteFile(filepath.Join(outsidePath, "secret"), []byte("secret\n"), 0600))
+
+	// Replace a directory by a symlink to a directory outside of the tree.
+	require.NoError(t, os.MkdirAll(filepath.Join(srcPath, "dir"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(srcPath, "dir", "file"), []byte("file\n"), 0644))
+	require.NoError(t, os.Symlink(outsidePath, filepath.Join(dstPath, "dir")))
+
+	// Add a symlink to a directory outside of the tree.
+	require.NoError(t, os.Symlink(outsidePath, filepath.Join(dstPath, "link")))
+
+	for _, root := range []string{srcPath, dstPath} {
+		_ = os.Chtimes(root, mtime, mtime)
+	}
+
+	// The symlinks are compared as such, without listing the content of their targets.
+	changes, err := DiffPaths(srcPath, dstPath, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []api.InstanceFileChange{
+		{Path: "/dir", Type: api.InstanceFileChangeModified},
+		{Path: "/dir/file", Type: api.InstanceFileChangeRemoved},
+		{Path: "/link", Type: api.InstanceFileChangeAdded},
+	}, changes)
+}
@@ -12,6 +12,9 @@ const (
 	EventTypeLogging   = "logging"
 	EventTypeOperation = "operation"
 	EventTypeOVN       = "ovn"
+
+	// API extension: network_flow_log.
+	EventTypeNetworkFlow = "network-flow"
 )
 
 // Event represents an event entry (over websocket)
@@ -117,6 +120,30 @@ func (event *Event) ToLogging() (EventLogRecord, error) {
 			},
 		}
 
+		return record, nil
+	} else if event.Type == EventTypeNetworkFlow {
+		e := &EventNetworkFlow{}
+		err := json.Unmarshal(event.Metadata, &e)
+		if err != nil {
+			return EventLogRecord{}, err
+		}
+
+		record := EventLogRecord{
+			Time: event.Timestamp,
+			Lvl:  "info",
+			Msg:  fmt.Sprintf("Action: %s, Instance: %s, Direction: %s, Protocol: %s, Source: %s, Destination: %s", e.Action, e.Instance, e.Direction, e.Protocol, e.Source, e.Destination),
+			Ctx: []any{
+				"Network", e.Network,
+				"Project", e.Project,
+				"SourcePort", e.SourcePort,
+				"DestinationPort", e.DestinationPort,
+				"BytesSent", e.BytesSent,
+				"BytesReceived", e.BytesReceived,
+				"PacketsSent", e.PacketsSent,
+				"PacketsReceived", e.PacketsReceived,
+			},
+		}
+
 		return record, nil
 	}
 
@@ -167,3 +194,65 @@ type EventLifecycleRequestor struct {
 	// API extension: event_lifecycle_requestor_address
 	Address string `yaml:"address" json:"address"`
 }
+
+// EventNetworkFlow represents a network-flow type event entry describing a connection of an instance
+// on a managed network
+//
+// API extension: network_flow_log.
+type EventNetworkFlow struct {
+	// Whether the connection was opened (new) or closed (end)
+	// Example: end
+	Action string `yaml:"action" json:"action"`
+
+	// Name of the network the connection was seen on
+	// Example: lxdbr0
+	Network string `yaml:"network" json:"network"`
+
+	// Project of the instance
+	// Example: default
+	Project string `yaml:"project" json:"project"`
+
+	// Name of the instance
+	// Example: c1
+	Instance string `yaml:"instance" json:"instance"`
+
+	// Whether the connection was initiated by the instance (egress) or towards it (ingress)
+	// Example: egress
+	Direction string `yaml:"direction" json:"direction"`
+
+	// Layer 4 protocol
+	// Example: tcp
+	Protocol string `yaml:"protocol" json:"protocol"`
+
+	// Address of the connection initiator
+	// Example: 10.0.0.2
+	Source string `yaml:"source" json:"source"`
+
+	// Port of the connection initiator (TCP, UDP and SCTP only)
+	// Example: 41234
+	SourcePort uint16 `yaml:"source_port,omitempty" json:"source_port,omitempty"`
+
+	// Address the connection was initiated towards
+	// Example: 192.0.2.1
+	Destination string `yaml:"destination" json:"destination"`
+
+	// Port the connection was initiated towards (TCP, UDP and SCTP only)
+	// Example: 443
+	DestinationPort uint16 `yaml:"destination_port,omitempty" json:"destination_port,omitempty"`
+
+	// Bytes sent by the instance (only set on end)
+	// Example: 1200
+	BytesSent uint64 `yaml:"bytes_sent" json:"bytes_sent"`
+
+	// Bytes received by the instance (only set on end)
+	// Example: 9000
+	BytesReceived uint64 `yaml:"bytes_received" json:"bytes_received"`
+
+	// Packets sent by the instance (only set on end)
+	// Example: 10
+	PacketsSent uint64 `yaml:"packets_sent" json:"packets_sent"`
+
+	// Packets received by the instance (only set on end)
+	// Example: 8
+	PacketsReceived uint64 `yaml:"packets_received" json:"packets_received"`
+}
@@ -41,6 +41,10 @@ const (
 	EventLifecycleInstanceFileDeleted               = "instance-file-deleted"
 	EventLifecycleInstanceFilePushed                = "instance-file-pushed"
 	EventLifecycleInstanceFileRetrieved             = "instance-file-retrieved"
+	EventLifecycleInstanceGroupCreated              = "instance-group-created"
+	EventLifecycleInstanceGroupDeleted              = "instance-group-deleted"
+	EventLifecycleInstanceGroupUpdated              = "instance-group-updated"
+	EventLifecycleInstanceHealthChanged             = "instance-health-changed"
 	EventLifecycleInstanceLogDeleted                = "instance-log-deleted"
 	EventLifecycleInstanceLogRetrieved              = "instance-log-retrieved"
 	EventLifecycleInstanceMetadataRetrieved         = "instance-metadata-retrieved"
@@ -71,6 +75,9 @@ const (
 	EventLifecycleNetworkForwardCreated             = "network-forward-created"
 	EventLifecycleNetworkForwardDeleted             = "network-forward-deleted"
 	EventLifecycleNetworkForwardUpdated             = "network-forward-updated"
+	EventLifecycleNetworkLeaseAdded                 = "network-lease-added"
+	EventLifecycleNetworkLeaseExpired               = "network-lease-expired"
+	EventLifecycleNetworkLeaseRenewed               = "network-lease-renewed"
 	EventLifecycleNetworkLoadBalancerCreated        = "network-load-balancer-created"
 	EventLifecycleNetworkLoadBalancerDeleted        = "network-load-balancer-deleted"
 	EventLifecycleNetworkLoadBalancerUpdated        = "network-load-balancer-updated"
@@ -78,6 +85,9 @@ const (
 	EventLifecycleNetworkPeerDeleted                = "network-peer-deleted"
 	EventLifecycleNetworkPeerUpdated                = "network-peer-updated"
 	EventLifecycleNetworkRenamed                    = "network-renamed"
+	EventLifecycleNetworkReservationCreated         = "network-reservation-created"
+	EventLifecycleNetworkReservationDeleted         = "network-reservation-deleted"
+	EventLifecycleNetworkReservationUpdated         = "network-reservation-updated"
 	EventLifecycleNetworkUpdated                    = "network-updated"
 	EventLifecycleNetworkZoneCreated                = "network-zone-created"
 	EventLifecycleNetworkZoneDeleted                = "network-zone-deleted"
@@ -86,6 +96,9 @@ const (
 	EventLifecycleNetworkZoneRecordUpdated          = "network-zone-record-updated"
 	EventLifecycleNetworkZoneUpdated                = "network-zone-updated"
 	EventLifecycleOperationCancelled                = "operation-cancelled"
+	EventLifecyclePlacementGroupCreated             = "placement-group-created"
+	EventLifecyclePlacementGroupDeleted             = "placement-group-deleted"
+	EventLifecyclePlacementGroupUpdated             = "placement-group-updated"
 	EventLifecycleProfileCreated                    = "profile-created"
 	EventLifecycleProfileDeleted                    = "profile-deleted"
 	EventLifecycleProfileRenamed                    = "profile-renamed"
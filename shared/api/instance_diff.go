@@ -0,0 +1,25 @@
+package api
+
+// InstanceFileChangeAdded is a file that was added to the instance.
+const InstanceFileChangeAdded = "added"
+
+// InstanceFileChangeRemoved is a file that was removed from the instance.
+const InstanceFileChangeRemoved = "removed"
+
+// InstanceFileChangeModified is a file of the instance whose content or metadata was modified.
+const InstanceFileChangeModified = "modified"
+
+// InstanceFileChange represents a file of an instance that differs from its base image or from one of its snapshots.
+//
+// swagger:model
+//
+// API extension: instance_diff.
+type InstanceFileChange struct {
+	// Path of the file in the instance
+	// Example: /etc/hosts
+	Path string `json:"path" yaml:"path"`
+
+	// Type of change (added, removed or modified)
+	// Example: modified
+	Type string `json:"type" yaml:"type"`
+}
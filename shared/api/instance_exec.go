@@ -53,4 +53,10 @@ type InstanceExecPost struct {
 	// Current working directory for the command
 	// Example: /home/foo/
 	Cwd string `json:"cwd" yaml:"cwd"`
+
+	// Whether to keep the command running when the client disconnects (requires interactive and wait-for-websocket)
+	// Example: true
+	//
+	// API extension: instance_exec_sessions
+	Persistent bool `json:"persistent" yaml:"persistent"`
 }
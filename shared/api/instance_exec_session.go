@@ -0,0 +1,51 @@
+package api
+
+import (
+	"time"
+)
+
+// InstanceExecSession represents a persistent exec session of an instance.
+//
+// swagger:model
+//
+// API extension: instance_exec_sessions.
+type InstanceExecSession struct {
+	// Session ID, which is also the ID of its exec operation
+	// Example: b8d84888-1dc2-44fd-b386-7f679e171ba5
+	ID string `json:"id" yaml:"id"`
+
+	// Command and its arguments
+	// Example: ["bash"]
+	Command []string `json:"command" yaml:"command"`
+
+	// Whether a client is currently attached to the session
+	// Example: false
+	Attached bool `json:"attached" yaml:"attached"`
+
+	// When the session was created
+	// Example: 2021-03-23T20:00:00-04:00
+	CreatedAt time.Time `json:"created_at" yaml:"created_at"`
+
+	// When a client was last attached to the session
+	// Example: 2021-03-23T21:00:00-04:00
+	LastAttachedAt time.Time `json:"last_attached_at" yaml:"last_attached_at"`
+
+	// URL of the exec operation of the session
+	// Example: /1.0/operations/b8d84888-1dc2-44fd-b386-7f679e171ba5
+	Operation string `json:"operation" yaml:"operation"`
+}
+
+// InstanceExecSessionPost represents a request to attach to a persistent exec session.
+//
+// swagger:model
+//
+// API extension: instance_exec_sessions.
+type InstanceExecSessionPost struct {
+	// Terminal width in characters
+	// Example: 80
+	Width int `json:"width" yaml:"width"`
+
+	// Terminal height in rows
+	// Example: 24
+	Height int `json:"height" yaml:"height"`
+}
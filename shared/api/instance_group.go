@@ -0,0 +1,160 @@
+package api
+
+import (
+	"strings"
+)
+
+// InstanceGroupTemplate represents the template used to create the members of an instance group
+//
+// swagger:model
+//
+// API extension: instance_groups.
+type InstanceGroupTemplate struct {
+	// Type of instance (container or virtual-machine)
+	// Example: container
+	Type InstanceType `json:"type" yaml:"type"`
+
+	// Creation source (usually an image)
+	Source InstanceSource `json:"source" yaml:"source"`
+
+	// List of profiles applied to the members
+	// Example: ["default"]
+	Profiles []string `json:"profiles" yaml:"profiles"`
+
+	// Instance configuration (see doc/instances.md)
+	// Example: {"limits.cpu": "2"}
+	Config map[string]string `json:"config" yaml:"config"`
+
+	// Instance devices (see doc/instances.md)
+	// Example: {"root": {"type": "disk", "pool": "default", "path": "/"}}
+	Devices map[string]map[string]string `json:"devices" yaml:"devices"`
+}
+
+// InstanceGroupsPost represents the fields of a new LXD instance group
+//
+// swagger:model
+//
+// API extension: instance_groups.
+type InstanceGroupsPost struct {
+	InstanceGroupPut `yaml:",inline"`
+
+	// lxdmeta:generate(entities=instance-group; group=group-properties; key=name)
+	// The members of the group are named `<name>-<index>`.
+	// ---
+	//  type: string
+	//  required: yes
+	//  shortdesc: Name of the instance group
+
+	// The name of the instance group
+	// Example: web
+	Name string `json:"name" yaml:"name"`
+}
+
+// InstanceGroupPut represents the modifiable fields of a LXD instance group
+//
+// swagger:model
+//
+// API extension: instance_groups.
+type InstanceGroupPut struct {
+	// lxdmeta:generate(entities=instance-group; group=group-properties; key=description)
+	//
+	// ---
+	//  type: string
+	//  required: no
+	//  shortdesc: Description of the instance group
+
+	// Description of the instance group
+	// Example: Web servers
+	Description string `json:"description" yaml:"description"`
+
+	// lxdmeta:generate(entities=instance-group; group=group-properties; key=replicas)
+	//
+	// ---
+	//  type: integer
+	//  required: yes
+	//  shortdesc: Number of instances to keep running
+
+	// Number of instances to keep running
+	// Example: 3
+	Replicas int `json:"replicas" yaml:"replicas"`
+
+	// lxdmeta:generate(entities=instance-group; group=group-properties; key=template)
+	// Contains the `type`, `source`, `profiles`, `config` and `devices` fields used to create the members of the group.
+	// ---
+	//  type: object
+	//  required: yes
+	//  shortdesc: Template for the members of the group
+
+	// Template for the members of the group
+	Template InstanceGroupTemplate `json:"template" yaml:"template"`
+
+	// lxdmeta:generate(entities=instance-group; group=group-properties; key=config)
+	// See {ref}`instance-groups-config`.
+	// ---
+	//  type: string set
+	//  required: no
+	//  shortdesc: Configuration options as key/value pairs
+
+	// Instance group configuration map (refer to doc/howto/instance_groups.md)
+	// Example: {"load_balancer.network": "ovn0", "load_balancer.listen_address": "192.0.2.10", "load_balancer.listen_port": "80"}
+	Config map[string]string `json:"config" yaml:"config"`
+}
+
+// Normalise normalises the fields in the instance group so that they are comparable with ones stored.
+func (g *InstanceGroupPut) Normalise() {
+	g.Description = strings.TrimSpace(g.Description)
+}
+
+// InstanceGroup used for displaying an instance group
+//
+// swagger:model
+//
+// API extension: instance_groups.
+type InstanceGroup struct {
+	// The name of the instance group
+	// Example: web
+	Name string `json:"name" yaml:"name"`
+
+	// Description of the instance group
+	// Example: Web servers
+	Description string `json:"description" yaml:"description"`
+
+	// Number of instances to keep running
+	// Example: 3
+	Replicas int `json:"replicas" yaml:"replicas"`
+
+	// Template for the members of the group
+	Template InstanceGroupTemplate `json:"template" yaml:"template"`
+
+	// Instance group configuration map (refer to doc/howto/instance_groups.md)
+	// Example: {"load_balancer.network": "ovn0", "load_balancer.listen_address": "192.0.2.10", "load_balancer.listen_port": "80"}
+	Config map[string]string `json:"config" yaml:"config"`
+
+	// List of URLs of the instances that are members of the group
+	// Read only: true
+	// Example: ["/1.0/instances/web-1", "/1.0/instances/web-2"]
+	UsedBy []string `json:"used_by" yaml:"used_by"`
+}
+
+// Etag returns the values used for etag generation.
+func (g *InstanceGroup) Etag() []any {
+	return []any{g.Name, g.Description, g.Replicas, g.Template, g.Config}
+}
+
+// Writable converts a full InstanceGroup struct into a InstanceGroupPut struct (filters read-only fields).
+func (g *InstanceGroup) Writable() InstanceGroupPut {
+	return InstanceGroupPut{
+		Description: g.Description,
+		Replicas:    g.Replicas,
+		Template:    g.Template,
+		Config:      g.Config,
+	}
+}
+
+// SetWritable sets applicable values from InstanceGroupPut struct to InstanceGroup struct.
+func (g *InstanceGroup) SetWritable(put InstanceGroupPut) {
+	g.Description = put.Description
+	g.Replicas = put.Replicas
+	g.Template = put.Template
+	g.Config = put.Config
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"time"
+)
+
+// InstanceSessionRecording represents the recording of an exec or console session.
+//
+// swagger:model
+//
+// API extension: instance_session_recording.
+type InstanceSessionRecording struct {
+	// Name of the recording file
+	// Example: exec_b8d84888-1dc2-44fd-b386-7f679e171ba5.cast
+	Name string `json:"name" yaml:"name"`
+
+	// Type of session (exec or console)
+	// Example: exec
+	Type string `json:"type" yaml:"type"`
+
+	// Command run by the session (exec only)
+	// Example: bash
+	Command string `json:"command" yaml:"command"`
+
+	// Username of the identity that opened the session
+	// Example: 2c32fdc35dd2ecbe1b1da8eabb5dd5e0fa02a8bd8de4ad1a0be3b80c5ea1a6b9
+	Username string `json:"username" yaml:"username"`
+
+	// Authentication protocol of the identity that opened the session
+	// Example: tls
+	Protocol string `json:"protocol" yaml:"protocol"`
+
+	// Address of the client that opened the session
+	// Example: 10.0.0.1:47682
+	Address string `json:"address" yaml:"address"`
+
+	// When the session was opened
+	// Example: 2021-03-23T20:00:00-04:00
+	CreatedAt time.Time `json:"created_at" yaml:"created_at"`
+
+	// Size of the recording in bytes
+	// Example: 16384
+	Size int64 `json:"size" yaml:"size"`
+}
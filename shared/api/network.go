@@ -1,5 +1,9 @@
 package api
 
+import (
+	"time"
+)
+
 // NetworksPost represents the fields of a new LXD network
 //
 // swagger:model
@@ -207,6 +211,16 @@ type NetworkState struct {
 	//
 	// API extension: network_state_ovn
 	OVN *NetworkStateOVN `json:"ovn" yaml:"ovn"`
+
+	// Additional WireGuard network information
+	//
+	// API extension: network_wireguard
+	WireGuard *NetworkStateWireGuard `json:"wireguard" yaml:"wireguard"`
+
+	// Additional BGP information
+	//
+	// API extension: network_bgp_import
+	BGP *NetworkStateBGP `json:"bgp" yaml:"bgp"`
 }
 
 // NetworkStateAddress represents a network address
@@ -341,3 +355,113 @@ type NetworkStateOVN struct {
 	// OVN network chassis name
 	Chassis string `json:"chassis" yaml:"chassis"`
 }
+
+// NetworkStateBGP represents the BGP state of a network
+//
+// swagger:model
+//
+// API extension: network_bgp_import.
+type NetworkStateBGP struct {
+	// List of BGP peers of the network
+	Peers []NetworkStateBGPPeer `json:"peers" yaml:"peers"`
+}
+
+// NetworkStateBGPPeer represents the session state of a BGP peer
+//
+// swagger:model
+//
+// API extension: network_bgp_import.
+type NetworkStateBGPPeer struct {
+	// Name of the peer
+	// Example: upstream
+	Name string `json:"name" yaml:"name"`
+
+	// Peer address
+	// Example: 192.0.2.1
+	Address string `json:"address" yaml:"address"`
+
+	// Peer AS number
+	// Example: 64512
+	ASN uint32 `json:"asn" yaml:"asn"`
+
+	// BGP session state
+	// Example: established
+	State string `json:"state" yaml:"state"`
+
+	// Time since the session was established (in seconds)
+	// Example: 3600
+	Uptime uint64 `json:"uptime" yaml:"uptime"`
+
+	// Number of prefixes received from the peer
+	// Example: 2
+	PrefixesReceived uint64 `json:"prefixes_received" yaml:"prefixes_received"`
+
+	// Number of prefixes accepted from the peer
+	// Example: 2
+	PrefixesAccepted uint64 `json:"prefixes_accepted" yaml:"prefixes_accepted"`
+
+	// Number of prefixes advertised to the peer
+	// Example: 1
+	PrefixesAdvertised uint64 `json:"prefixes_advertised" yaml:"prefixes_advertised"`
+
+	// Whether the routes learned from the peer are imported
+	// Example: true
+	Import bool `json:"import" yaml:"import"`
+}
+
+// NetworkStateWireGuard represents WireGuard specific state
+//
+// swagger:model
+//
+// API extension: network_wireguard.
+type NetworkStateWireGuard struct {
+	// Public key of the interface
+	// Example: xTIBA5rboUvnH4htodjb6e697QjLERt1NAB4mZqp8Dg=
+	PublicKey string `json:"public_key" yaml:"public_key"`
+
+	// UDP port the interface listens on
+	// Example: 51820
+	ListenPort uint64 `json:"listen_port" yaml:"listen_port"`
+
+	// Endpoint the other cluster members use to reach this member
+	// Example: 10.0.0.1:51820
+	Endpoint string `json:"endpoint" yaml:"endpoint"`
+
+	// Addresses routed to this member through the tunnel
+	// Example: ["10.200.0.1/32", "10.200.10.5/32"]
+	AllowedIPs []string `json:"allowed_ips" yaml:"allowed_ips"`
+
+	// List of peers configured on the interface
+	Peers []NetworkStateWireGuardPeer `json:"peers" yaml:"peers"`
+}
+
+// NetworkStateWireGuardPeer represents the state of a WireGuard peer
+//
+// swagger:model
+//
+// API extension: network_wireguard.
+type NetworkStateWireGuardPeer struct {
+	// Public key of the peer
+	// Example: HIgo9xNzJMWLKASShiTqIybxZ0U3wGLiUeJ1PKf8ykw=
+	PublicKey string `json:"public_key" yaml:"public_key"`
+
+	// Current endpoint of the peer
+	// Example: 10.0.0.2:51820
+	Endpoint string `json:"endpoint" yaml:"endpoint"`
+
+	// Addresses routed to the peer
+	// Example: ["10.200.0.2/32"]
+	AllowedIPs []string `json:"allowed_ips" yaml:"allowed_ips"`
+
+	// Time of the latest handshake with the peer
+	// Example: 2024-01-01T12:00:00Z
+	LatestHandshake time.Time `json:"latest_handshake" yaml:"latest_handshake"`
+
+	// Number of bytes received from the peer
+	// Example: 250542118
+	BytesReceived uint64 `json:"bytes_received" yaml:"bytes_received"`
+
+	// Number of bytes sent to the peer
+	// Example: 17524040140
+	BytesSent uint64 `json:"bytes_sent" yaml:"bytes_sent"`
+}
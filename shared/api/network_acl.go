@@ -104,6 +104,38 @@ type NetworkACLRule struct {
 	// Example: 0
 	ICMPCode string `json:"icmp_code,omitempty" yaml:"icmp_code,omitempty"`
 
+	// lxdmeta:generate(entities=network-acl; group=rule-properties; key=rate_limit)
+	// This option is valid only if the action is `allow`.
+	// Specify the maximum rate of packets in the format `<number>/<unit>`, where the unit is `second`, `minute`, `hour` or `day`.
+	// Packets that exceed the limit are not matched by the rule.
+	// Leave the value empty for no limit.
+	// ---
+	//  type: string
+	//  required: no
+	//  shortdesc: Maximum rate of matched packets
+
+	// Maximum rate of matched packets
+	// Example: 100/second
+	//
+	// API extension: network_acl_limits
+	RateLimit string `json:"rate_limit,omitempty" yaml:"rate_limit,omitempty"`
+
+	// lxdmeta:generate(entities=network-acl; group=rule-properties; key=connection_limit)
+	// This option is valid only if the action is `allow`.
+	// Specify the maximum number of concurrent connections per source address.
+	// Connections that exceed the limit are not matched by the rule.
+	// Leave the value empty for no limit.
+	// ---
+	//  type: string
+	//  required: no
+	//  shortdesc: Maximum concurrent connections per source address
+
+	// Maximum number of concurrent connections per source address
+	// Example: 10
+	//
+	// API extension: network_acl_limits
+	ConnectionLimit string `json:"connection_limit,omitempty" yaml:"connection_limit,omitempty"`
+
 	// lxdmeta:generate(entities=network-acl; group=rule-properties; key=description)
 	//
 	// ---
@@ -134,6 +166,8 @@ func (r *NetworkACLRule) Normalise() {
 	r.Protocol = strings.TrimSpace(r.Protocol)
 	r.ICMPType = strings.TrimSpace(r.ICMPType)
 	r.ICMPCode = strings.TrimSpace(r.ICMPCode)
+	r.RateLimit = strings.TrimSpace(r.RateLimit)
+	r.ConnectionLimit = strings.TrimSpace(r.ConnectionLimit)
 	r.Description = strings.TrimSpace(r.Description)
 	r.State = strings.TrimSpace(r.State)
 
@@ -296,3 +330,31 @@ type NetworkACLsPost struct {
 	NetworkACLPost `yaml:",inline"`
 	NetworkACLPut  `yaml:",inline"`
 }
+
+// NetworkACLRuleState represents the counters of a network ACL rule.
+//
+// swagger:model
+//
+// API extension: network_acl_state.
+type NetworkACLRuleState struct {
+	// Number of packets matched by the rule
+	// Example: 1024
+	Packets uint64 `json:"packets" yaml:"packets"`
+
+	// Number of bytes matched by the rule
+	// Example: 65536
+	Bytes uint64 `json:"bytes" yaml:"bytes"`
+}
+
+// NetworkACLState represents the state of a network ACL.
+//
+// swagger:model
+//
+// API extension: network_acl_state.
+type NetworkACLState struct {
+	// Counters of the egress rules (in the same order as the rules)
+	Egress []NetworkACLRuleState `json:"egress" yaml:"egress"`
+
+	// Counters of the ingress rules (in the same order as the rules)
+	Ingress []NetworkACLRuleState `json:"ingress" yaml:"ingress"`
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"net"
+	"strings"
+)
+
+// NetworkReservationsPost represents the fields of a new LXD network DHCP reservation
+//
+// swagger:model
+//
+// API extension: network_reservation.
+type NetworkReservationsPost struct {
+	NetworkReservationPut `yaml:",inline"`
+
+	// lxdmeta:generate(entities=network-reservation; group=reservation-properties; key=address)
+	// The address must be within the subnet of the network the reservation belongs to.
+	// ---
+	//  type: string
+	//  required: yes
+	//  shortdesc: IP address to reserve
+
+	// The reserved address
+	// Example: 10.0.0.10
+	Address string `json:"address" yaml:"address"`
+}
+
+// Normalise normalises the fields in the reservation so that they are comparable with ones stored.
+func (r *NetworkReservationsPost) Normalise() {
+	ip := net.ParseIP(r.Address)
+	if ip != nil {
+		r.Address = ip.String() // Replace with canonical form if specified.
+	}
+
+	r.NetworkReservationPut.Normalise()
+}
+
+// NetworkReservationPut represents the modifiable fields of a LXD network DHCP reservation
+//
+// swagger:model
+//
+// API extension: network_reservation.
+type NetworkReservationPut struct {
+	// lxdmeta:generate(entities=network-reservation; group=reservation-properties; key=description)
+	//
+	// ---
+	//  type: string
+	//  required: no
+	//  shortdesc: Description of the network reservation
+
+	// Description of the reservation
+	// Example: Storage appliance
+	Description string `json:"description" yaml:"description"`
+
+	// lxdmeta:generate(entities=network-reservation; group=reservation-properties; key=config)
+	// See {ref}`network-reservations-config`.
+	// ---
+	//  type: string set
+	//  required: yes
+	//  shortdesc: Configuration options as key/value pairs
+
+	// Reservation configuration map (refer to doc/howto/network_reservations.md)
+	// Example: {"hwaddr": "00:16:3e:11:22:33", "hostname": "nas"}
+	Config map[string]string `json:"config" yaml:"config"`
+}
+
+// Normalise normalises the fields in the reservation so that they are comparable with ones stored.
+func (r *NetworkReservationPut) Normalise() {
+	r.Description = strings.TrimSpace(r.Description)
+
+	mac, err := net.ParseMAC(r.Config["hwaddr"])
+	if err == nil {
+		r.Config["hwaddr"] = mac.String() // Replace with canonical form if specified.
+	}
+
+	if r.Config["client_id"] != "" {
+		r.Config["client_id"] = strings.ToLower(r.Config["client_id"])
+	}
+}
+
+// NetworkReservation used for displaying a network DHCP reservation.
+//
+// swagger:model
+//
+// API extension: network_reservation.
+type NetworkReservation struct {
+	// The reserved address
+	// Example: 10.0.0.10
+	Address string `json:"address" yaml:"address"`
+
+	// Description of the reservation
+	// Example: Storage appliance
+	Description string `json:"description" yaml:"description"`
+
+	// Reservation configuration map (refer to doc/howto/network_reservations.md)
+	// Example: {"hwaddr": "00:16:3e:11:22:33", "hostname": "nas"}
+	Config map[string]string `json:"config" yaml:"config"`
+}
+
+// Normalise normalises the fields in the reservation so that they are comparable with ones stored.
+func (r *NetworkReservation) Normalise() {
+	rPut := r.Writable()
+	rPut.Normalise()
+	r.SetWritable(rPut)
+}
+
+// Etag returns the values used for etag generation.
+func (r *NetworkReservation) Etag() []any {
+	return []any{r.Address, r.Description, r.Config}
+}
+
+// Writable converts a full NetworkReservation struct into a NetworkReservationPut struct (filters read-only fields).
+func (r *NetworkReservation) Writable() NetworkReservationPut {
+	return NetworkReservationPut{
+		Description: r.Description,
+		Config:      r.Config,
+	}
+}
+
+// SetWritable sets applicable values from NetworkReservationPut struct to NetworkReservation struct.
+func (r *NetworkReservation) SetWritable(put NetworkReservationPut) {
+	r.Description = put.Description
+	r.Config = put.Config
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"strings"
+)
+
+// PlacementGroupsPost represents the fields of a new LXD placement group
+//
+// swagger:model
+//
+// API extension: placement_groups.
+type PlacementGroupsPost struct {
+	PlacementGroupPut `yaml:",inline"`
+
+	// lxdmeta:generate(entities=placement-group; group=group-properties; key=name)
+	//
+	// ---
+	//  type: string
+	//  required: yes
+	//  shortdesc: Name of the placement group
+
+	// The name of the placement group
+	// Example: web
+	Name string `json:"name" yaml:"name"`
+}
+
+// PlacementGroupPut represents the modifiable fields of a LXD placement group
+//
+// swagger:model
+//
+// API extension: placement_groups.
+type PlacementGroupPut struct {
+	// lxdmeta:generate(entities=placement-group; group=group-properties; key=description)
+	//
+	// ---
+	//  type: string
+	//  required: no
+	//  shortdesc: Description of the placement group
+
+	// Description of the placement group
+	// Example: Web servers spread across the cluster
+	Description string `json:"description" yaml:"description"`
+
+	// lxdmeta:generate(entities=placement-group; group=group-properties; key=config)
+	// See {ref}`placement-groups-config`.
+	// ---
+	//  type: string set
+	//  required: no
+	//  shortdesc: Configuration options as key/value pairs
+
+	// Placement group configuration map (refer to doc/howto/cluster_placement_groups.md)
+	// Example: {"policy": "spread", "mode": "strict"}
+	Config map[string]string `json:"config" yaml:"config"`
+}
+
+// Normalise normalises the fields in the placement group so that they are comparable with ones stored.
+func (g *PlacementGroupPut) Normalise() {
+	g.Description = strings.TrimSpace(g.Description)
+}
+
+// PlacementGroup used for displaying a placement group
+//
+// swagger:model
+//
+// API extension: placement_groups.
+type PlacementGroup struct {
+	// The name of the placement group
+	// Example: web
+	Name string `json:"name" yaml:"name"`
+
+	// Description of the placement group
+	// Example: Web servers spread across the cluster
+	Description string `json:"description" yaml:"description"`
+
+	// Placement group configuration map (refer to doc/howto/cluster_placement_groups.md)
+	// Example: {"policy": "spread", "mode": "strict"}
+	Config map[string]string `json:"config" yaml:"config"`
+
+	// List of URLs of the instances that are members of the group
+	// Read only: true
+	// Example: ["/1.0/instances/web-1", "/1.0/instances/web-2"]
+	UsedBy []string `json:"used_by" yaml:"used_by"`
+}
+
+// Etag returns the values used for etag generation.
+func (g *PlacementGroup) Etag() []any {
+	return []any{g.Name, g.Description, g.Config}
+}
+
+// Writable converts a full PlacementGroup struct into a PlacementGroupPut struct (filters read-only fields).
+func (g *PlacementGroup) Writable() PlacementGroupPut {
+	return PlacementGroupPut{
+		Description: g.Description,
+		Config:      g.Config,
+	}
+}
+
+// SetWritable sets applicable values from PlacementGroupPut struct to PlacementGroup struct.
+func (g *PlacementGroup) SetWritable(put PlacementGroupPut) {
+	g.Description = put.Description
+	g.Config = put.Config
+}
@@ -439,6 +439,31 @@ var APIExtensions = []string{
 	"cloud_init_ssh_keys",
 	"oidc_scopes",
 	"project_default_network_and_storage",
+	"network_acl_state",
+	"network_acl_limits",
+	"network_wireguard",
+	"network_bridge_vxlan",
+	"network_bgp_import",
+	"network_lease_events",
+	"network_zone_import_export",
+	"network_bridge_ipv6_delegation",
+	"network_bridge_peering",
+	"projects_limits_network",
+	"instance_nic_nat_address",
+	"network_flow_log",
+	"network_reservation",
+	"network_dhcp_options",
+	"network_bridge_multicast",
+	"instance_healthcheck",
+	"instance_boot_dependencies",
+	"instance_schedule",
+	"instance_groups",
+	"placement_groups",
+	"cluster_rebalance",
+	"instance_exec_sessions",
+	"instance_session_recording",
+	"instance_log_follow",
+	"instance_diff",
 }
 
 // APIExtensionsCount returns the number of available API extensions.
@@ -285,6 +285,7 @@ if [ "${1:-"all"}" != "standalone" ]; then
     run_test test_clustering_image_refresh "clustering image refresh"
     run_test test_clustering_evacuation "clustering evacuation"
     run_test test_clustering_instance_placement_scriptlet "clustering instance placement scriptlet"
+    run_test test_clustering_placement_groups "clustering placement groups"
     run_test test_clustering_move "clustering move"
     run_test test_clustering_edit_configuration "clustering config edit"
     run_test test_clustering_remove_members "clustering config remove members"
@@ -295,6 +296,7 @@ if [ "${1:-"all"}" != "standalone" ]; then
     run_test test_clustering_events "clustering events"
     run_test test_clustering_uuid "clustering uuid"
     run_test test_clustering_trust_add "clustering trust add"
+    run_test test_clustering_wireguard "clustering WireGuard"
 fi
 
 if [ "${1:-"all"}" != "cluster" ]; then
@@ -311,6 +313,7 @@ if [ "${1:-"all"}" != "cluster" ]; then
     run_test test_projects_storage "projects and storage pools"
     run_test test_projects_network "projects and networks"
     run_test test_projects_limits "projects limits"
+    run_test test_projects_network_limits "projects network limits"
     run_test test_projects_usage "projects usage"
     run_test test_projects_yaml "projects with yaml initialization"
     run_test test_projects_before_init "project operations before init"
@@ -350,6 +353,11 @@ if [ "${1:-"all"}" != "cluster" ]; then
     run_test test_cloud_init "cloud-init"
     run_test test_exec "exec"
     run_test test_exec_exit_code "exec exit code"
+    run_test test_exec_sessions "exec sessions"
+    run_test test_instance_health "instance health checks"
+    run_test test_instance_dependencies "instance boot dependencies"
+    run_test test_instance_schedule "instance scheduled start and stop"
+    run_test test_instance_groups "instance groups"
     run_test test_concurrent_exec "concurrent exec"
     run_test test_concurrent "concurrent startup"
     run_test test_snapshots "container snapshots"
@@ -369,6 +377,8 @@ if [ "${1:-"all"}" != "cluster" ]; then
     run_test test_network "network management"
     run_test test_network_acl "network ACL management"
     run_test test_network_forward "network address forwards"
+    run_test test_network_bridge_peer "bridge network isolation and peering"
+    run_test test_network_reservation "network DHCP reservations"
     run_test test_network_zone "network DNS zones"
     run_test test_network_ovn "OVN network management"
     run_test test_idmap "id mapping"
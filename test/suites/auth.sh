@@ -463,6 +463,19 @@ fine_grained_authorization() {
 
   lxc auth group permission remove test-group project default can_view_events
 
+  echo "==> Checking deleting session recordings requires 'can_edit' on the project..."
+  lxc launch testimage user-foo -c security.exec.record=true
+  lxc exec user-foo -- true
+  recording="$(lxc query /1.0/instances/user-foo/logs/session-recordings | jq -r '.[0]')"
+  lxc auth group permission add test-group instance user-foo can_edit project=default
+  [ "$(lxc_remote query "${remote}:/1.0/instances/user-foo/logs/session-recordings" | jq -r '.[0]')" = "${recording}" ]
+  ! lxc_remote query -X DELETE "${remote}:${recording}" || false
+  lxc auth group permission add test-group project default can_edit
+  lxc_remote query -X DELETE "${remote}:${recording}"
+  [ "$(lxc query /1.0/instances/user-foo/logs/session-recordings | jq -r 'length')" = 0 ]
+  lxc auth group permission remove test-group project default can_edit
+  lxc delete user-foo --force
+
   echo "==> Checking 'can_view_warnings' entitlement..."
   # Delete previous warnings
   lxc query --wait /1.0/warnings\?recursion=1 | jq -r '.[].uuid' | xargs -n1 lxc warning delete
@@ -4213,3 +4213,80 @@ test_clustering_trust_add() {
   kill_lxd "${LXD_ONE_DIR}"
   kill_lxd "${LXD_TWO_DIR}"
 }
+
+test_clustering_wireguard() {
+  local LXD_DIR
+
+  if ! grep -qw wireguard /proc/modules && ! modprobe -q wireguard; then
+    echo "==> SKIP: WireGuard kernel module not available"
+    return
+  fi
+
+  setup_clustering_bridge
+  prefix="lxd$$"
+  bridge="${prefix}"
+
+  # create two cluster nodes
+  setup_clustering_netns 1
+  LXD_ONE_DIR=$(mktemp -d -p "${TEST_DIR}" XXX)
+  chmod +x "${LXD_ONE_DIR}"
+  ns1="${prefix}1"
+  spawn_lxd_and_bootstrap_cluster "${ns1}" "${bridge}" "${LXD_ONE_DIR}"
+
+  cert=$(sed ':a;N;$!ba;s/\n/\n\n/g' "${LXD_ONE_DIR}/cluster.crt")
+
+  setup_clustering_netns 2
+  LXD_TWO_DIR=$(mktemp -d -p "${TEST_DIR}" XXX)
+  chmod +x "${LXD_TWO_DIR}"
+  ns2="${prefix}2"
+  spawn_lxd_and_join_cluster "${ns2}" "${bridge}" "${cert}" 2 1 "${LXD_TWO_DIR}" "${LXD_ONE_DIR}"
+
+  # create the network on both members
+  net="${prefix}wg"
+  LXD_DIR="${LXD_ONE_DIR}" lxc network create "${net}" --type=wireguard --target node1
+  LXD_DIR="${LXD_ONE_DIR}" lxc network create "${net}" --type=wireguard --target node2
+  LXD_DIR="${LXD_ONE_DIR}" lxc network create "${net}" --type=wireguard ipv4.address=10.201.0.0/24
+
+  key1=$(LXD_DIR="${LXD_ONE_DIR}" lxc query "/1.0/networks/${net}/state?target=node1" | jq -r '.wireguard.public_key')
+  key2=$(LXD_DIR="${LXD_ONE_DIR}" lxc query "/1.0/networks/${net}/state?target=node2" | jq -r '.wireguard.public_key')
+  [ -n "${key1}" ] && [ "${key1}" != "null" ]
+  [ -n "${key2}" ] && [ "${key2}" != "null" ]
+
+  # the members peer with each other on the next heartbeat
+  for _ in $(seq 30); do
+    if LXD_DIR="${LXD_ONE_DIR}" lxc query "/1.0/networks/${net}/state?target=node1" | jq -e --arg key "${key2}" '.wireguard.peers | any(.public_key == $key)' >/dev/null && \
+       LXD_DIR="${LXD_ONE_DIR}" lxc query "/1.0/networks/${net}/state?target=node2" | jq -e --arg key "${key1}" '.wireguard.peers | any(.public_key == $key)' >/dev/null; then
+      break
+    fi
+
+    sleep 1
+  done
+
+  LXD_DIR="${LXD_ONE_DIR}" lxc query "/1.0/networks/${net}/state?target=node1" | jq -e --arg key "${key2}" '.wireguard.peers | any(.public_key == $key)'
+  LXD_DIR="${LXD_ONE_DIR}" lxc query "/1.0/networks/${net}/state?target=node2" | jq -e --arg key "${key1}" '.wireguard.peers | any(.public_key == $key)'
+
+  # the peer of each member routes the tunnel address of the other member
+  LXD_DIR="${LXD_ONE_DIR}" lxc query "/1.0/networks/${net}/state?target=node1" | jq -e '.wireguard.peers[].allowed_ips | index("10.201.0.2/32")'
+  LXD_DIR="${LXD_ONE_DIR}" lxc query "/1.0/networks/${net}/state?target=node2" | jq -e '.wireguard.peers[].allowed_ips | index("10.201.0.1/32")'
+
+  # setting an uplink gateway creates the local uplink bridge on each member
+  LXD_DIR="${LXD_ONE_DIR}" lxc network set "${net}" ipv4.gateway=10.202.0.1/24 ipv4.ovn.ranges=10.202.0.10-10.202.0.20
+  ! LXD_DIR="${LXD_ONE_DIR}" lxc network set "${net}" ipv4.ovn.ranges=10.203.0.10-10.203.0.20 || false
+  LXD_DIR="${LXD_ONE_DIR}" lxc network get "${net}" ipv4.gateway | grep -xF "10.202.0.1/24"
+  LXD_DIR="${LXD_ONE_DIR}" lxc network unset "${net}" ipv4.ovn.ranges
+  LXD_DIR="${LXD_ONE_DIR}" lxc network unset "${net}" ipv4.gateway
+
+  # cleanup
+  LXD_DIR="${LXD_ONE_DIR}" lxc network delete "${net}"
+  LXD_DIR="${LXD_TWO_DIR}" lxd shutdown
+  LXD_DIR="${LXD_ONE_DIR}" lxd shutdown
+  sleep 0.5
+  rm -f "${LXD_TWO_DIR}/unix.socket"
+  rm -f "${LXD_ONE_DIR}/unix.socket"
+
+  teardown_clustering_netns
+  teardown_clustering_bridge
+
+  kill_lxd "${LXD_ONE_DIR}"
+  kill_lxd "${LXD_TWO_DIR}"
+}
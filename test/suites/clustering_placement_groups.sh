@@ -0,0 +1,117 @@
+test_clustering_placement_groups() {
+  # shellcheck disable=SC2034
+  local LXD_DIR
+
+  setup_clustering_bridge
+  prefix="lxd$$"
+  bridge="${prefix}"
+
+  # The random storage backend is not supported in clustering tests,
+  # since we need to have the same storage driver on all nodes, so use the driver chosen for the standalone pool.
+  poolDriver=$(lxc storage show "$(lxc profile device get default root pool)" | awk '/^driver:/ {print $2}')
+
+  # Spawn first node
+  setup_clustering_netns 1
+  LXD_ONE_DIR=$(mktemp -d -p "${TEST_DIR}" XXX)
+  chmod +x "${LXD_ONE_DIR}"
+  ns1="${prefix}1"
+  spawn_lxd_and_bootstrap_cluster "${ns1}" "${bridge}" "${LXD_ONE_DIR}" "${poolDriver}"
+
+  # Add a newline at the end of each line. YAML has weird rules.
+  cert=$(sed ':a;N;$!ba;s/\n/\n\n/g' "${LXD_ONE_DIR}/cluster.crt")
+
+  # Spawn a second node
+  setup_clustering_netns 2
+  LXD_TWO_DIR=$(mktemp -d -p "${TEST_DIR}" XXX)
+  chmod +x "${LXD_TWO_DIR}"
+  ns2="${prefix}2"
+  spawn_lxd_and_join_cluster "${ns2}" "${bridge}" "${cert}" 2 1 "${LXD_TWO_DIR}" "${LXD_ONE_DIR}" "${poolDriver}"
+
+  LXD_DIR="${LXD_ONE_DIR}" ensure_import_testimage
+
+  # Check invalid placement groups are rejected.
+  ! LXD_DIR="${LXD_ONE_DIR}" lxc placement-group create -- -spread || false
+  ! LXD_DIR="${LXD_ONE_DIR}" lxc placement-group create spread policy=foo || false
+  ! LXD_DIR="${LXD_ONE_DIR}" lxc placement-group create spread mode=foo || false
+  ! LXD_DIR="${LXD_ONE_DIR}" lxc placement-group create spread foo=bar || false
+
+  # Check instances can only join existing placement groups.
+  ! LXD_DIR="${LXD_ONE_DIR}" lxc init testimage c1 -c placement.group=spread || false
+  LXD_DIR="${LXD_ONE_DIR}" lxc init testimage c1 --target node1
+  ! LXD_DIR="${LXD_ONE_DIR}" lxc config set c1 placement.group=spread || false
+  ! LXD_DIR="${LXD_ONE_DIR}" lxc profile create pg -c placement.group=spread || false
+  LXD_DIR="${LXD_ONE_DIR}" lxc profile create pg
+  ! LXD_DIR="${LXD_ONE_DIR}" lxc profile set pg placement.group=spread || false
+
+  # Check the spread policy in strict mode.
+  LXD_DIR="${LXD_ONE_DIR}" lxc placement-group create spread policy=spread mode=strict
+  LXD_DIR="${LXD_ONE_DIR}" lxc config set c1 placement.group=spread
+  LXD_DIR="${LXD_ONE_DIR}" lxc placement-group show spread | grep -F "/1.0/instances/c1"
+  ! LXD_DIR="${LXD_ONE_DIR}" lxc init testimage c2 -c placement.group=spread --target node1 || false
+  LXD_DIR="${LXD_ONE_DIR}" lxc init testimage c2 -c placement.group=spread
+  LXD_DIR="${LXD_ONE_DIR}" lxc info c2 | grep -xF "Location: node2"
+  ! LXD_DIR="${LXD_ONE_DIR}" lxc init testimage c3 -c placement.group=spread || false
+  ! LXD_DIR="${LXD_ONE_DIR}" lxc move c2 --target node1 || false
+  ! LXD_DIR="${LXD_ONE_DIR}" lxc placement-group delete spread || false
+
+  # Check the spread policy in soft mode.
+  LXD_DIR="${LXD_ONE_DIR}" lxc placement-group set spread mode=soft
+  LXD_DIR="${LXD_ONE_DIR}" lxc init testimage c3 -c placement.group=spread
+  LXD_DIR="${LXD_ONE_DIR}" lxc init testimage c4 -c placement.group=spread --target node1
+  LXD_DIR="${LXD_ONE_DIR}" lxc info c4 | grep -xF "Location: node1"
+  LXD_DIR="${LXD_ONE_DIR}" lxc delete c3 c4
+
+  # Check the placement group can be set through a profile.
+  LXD_DIR="${LXD_ONE_DIR}" lxc placement-group create compact policy=compact
+  LXD_DIR="${LXD_ONE_DIR}" lxc profile set pg placement.group=compact
+  LXD_DIR="${LXD_ONE_DIR}" lxc init testimage d1 -p default -p pg --target node2
+  LXD_DIR="${LXD_ONE_DIR}" lxc placement-group show compact | grep -F "/1.0/instances/d1"
+
+  # Check the compact policy in strict mode.
+  LXD_DIR="${LXD_ONE_DIR}" lxc init testimage d2 -p default -p pg
+  LXD_DIR="${LXD_ONE_DIR}" lxc info d2 | grep -xF "Location: node2"
+  ! LXD_DIR="${LXD_ONE_DIR}" lxc init testimage d3 -p default -p pg --target node1 || false
+
+  # Check the compact policy in soft mode.
+  LXD_DIR="${LXD_ONE_DIR}" lxc placement-group set compact mode=soft
+  LXD_DIR="${LXD_ONE_DIR}" lxc init testimage d3 -p default -p pg --target node1
+  LXD_DIR="${LXD_ONE_DIR}" lxc info d3 | grep -xF "Location: node1"
+  LXD_DIR="${LXD_ONE_DIR}" lxc delete d1 d2 d3
+
+  # Check an instance that can't be moved without breaking its strict placement group is stopped during an evacuation.
+  LXD_DIR="${LXD_ONE_DIR}" lxc placement-group set spread mode=strict
+  LXD_DIR="${LXD_ONE_DIR}" lxc config set c1 cluster.evacuate=migrate
+  LXD_DIR="${LXD_ONE_DIR}" lxc start c1
+  LXD_DIR="${LXD_ONE_DIR}" lxc cluster evacuate node1 --force
+  LXD_DIR="${LXD_ONE_DIR}" lxc cluster show node1 | grep -xF "status: Evacuated"
+  LXD_DIR="${LXD_ONE_DIR}" lxc info c1 | grep -xF "Location: node1"
+  [ "$(LXD_DIR="${LXD_ONE_DIR}" lxc list c1 -c s -f csv)" = "STOPPED" ]
+  LXD_DIR="${LXD_ONE_DIR}" lxc cluster restore node1 --force
+  LXD_DIR="${LXD_ONE_DIR}" lxc delete -f c1 c2
+
+  # Check the placement groups can be deleted once unused.
+  LXD_DIR="${LXD_ONE_DIR}" lxc profile delete pg
+  LXD_DIR="${LXD_ONE_DIR}" lxc placement-group delete spread
+  LXD_DIR="${LXD_ONE_DIR}" lxc placement-group delete compact
+  [ "$(LXD_DIR="${LXD_ONE_DIR}" lxc placement-group list -f csv | wc -l)" = "0" ]
+
+  # Delete the storage pool
+  printf 'config: {}\ndevices: {}' | LXD_DIR="${LXD_ONE_DIR}" lxc profile edit default
+  LXD_DIR="${LXD_ONE_DIR}" lxc storage delete data
+
+  # Shut down cluster
+  LXD_DIR="${LXD_ONE_DIR}" lxd shutdown
+  LXD_DIR="${LXD_TWO_DIR}" lxd shutdown
+  sleep 0.5
+  rm -f "${LXD_ONE_DIR}/unix.socket"
+  rm -f "${LXD_TWO_DIR}/unix.socket"
+
+  teardown_clustering_netns
+  teardown_clustering_bridge
+
+  kill_lxd "${LXD_ONE_DIR}"
+  kill_lxd "${LXD_TWO_DIR}"
+
+  # shellcheck disable=SC2034
+  LXD_NETNS=
+}
@@ -0,0 +1,87 @@
+test_exec_sessions() {
+  ensure_import_testimage
+
+  # Waits for the attached state of exec session $2 of instance $1 to become $3.
+  wait_for_attached() {
+    for _ in $(seq 20); do
+      if [ "$(lxc query "/1.0/instances/${1}/exec-sessions/${2}" | jq -r '.attached')" = "${3}" ]; then
+        return 0
+      fi
+
+      sleep 0.5
+    done
+
+    echo "Exec session ${2} attached state didn't become ${3}"
+    false
+  }
+
+  # Waits for instance $1 to have $2 exec sessions.
+  wait_for_sessions() {
+    for _ in $(seq 20); do
+      if [ "$(lxc query "/1.0/instances/${1}/exec-sessions" | jq -r 'length')" = "${2}" ]; then
+        return 0
+      fi
+
+      sleep 0.5
+    done
+
+    echo "Instance ${1} didn't get ${2} exec sessions"
+    false
+  }
+
+  lxc launch testimage c1
+
+  # Check persistent exec sessions require interactive mode.
+  ! lxc exec c1 --persistent -T -- true || false
+  ! lxc query --request POST /1.0/instances/c1/exec --data '{"command": ["true"], "interactive": false, "wait-for-websocket": true, "persistent": true}' || false
+  [ "$(lxc query /1.0/instances/c1/exec-sessions | jq -r 'length')" = "0" ]
+
+  # Start a persistent exec session and detach from it right away (ctrl-p ctrl-q).
+  printf '\020\021' | lxc exec c1 --persistent -t -- sh -c 'echo exec-session-marker; sleep 300' | grep -F "Detached from exec session"
+  wait_for_sessions c1 1
+  session="$(lxc query /1.0/instances/c1/exec-sessions?recursion=1 | jq -r '.[0].id')"
+  [ "$(lxc query "/1.0/instances/c1/exec-sessions/${session}" | jq -r '.command | join(" ")')" = "sh -c echo exec-session-marker; sleep 300" ]
+  wait_for_attached c1 "${session}" false
+  lxc exec-session list c1 -f csv | grep -F "${session},"
+  ! lxc query /1.0/instances/c1/exec-sessions/not-a-session || false
+  ! lxc query --request POST /1.0/instances/c1/exec-sessions/not-a-session --data '{}' || false
+
+  # Check attaching requires a terminal.
+  ! lxc exec-session attach c1 "${session}" < /dev/null || false
+
+  # Attach to the session again and check the recent output is replayed.
+  (sleep 5; printf '\020\021') | script -qfec "lxc exec-session attach c1 ${session}" /dev/null > "${TEST_DIR}/exec-session.out" &
+  attach_pid=$!
+  wait_for_attached c1 "${session}" true
+  lxc exec-session list c1 -f csv | grep -F "${session},sh -c echo exec-session-marker; sleep 300,true"
+
+  # Check only one client can be attached at a time.
+  ! lxc query --request POST "/1.0/instances/c1/exec-sessions/${session}" --data '{}' || false
+
+  wait "${attach_pid}"
+  wait_for_attached c1 "${session}" false
+  grep -F "exec-session-marker" "${TEST_DIR}/exec-session.out"
+  grep -F "Detached from exec session ${session}" "${TEST_DIR}/exec-session.out"
+  rm "${TEST_DIR}/exec-session.out"
+
+  # Check a session can be attached to again once detached.
+  lxc query --request POST "/1.0/instances/c1/exec-sessions/${session}" --data '{"width": 80, "height": 24}' | jq -r '.metadata.fds.control' | grep -v '^null$'
+
+  # Check terminating the session kills its command.
+  lxc exec-session delete c1 "${session}"
+  wait_for_sessions c1 0
+  ! lxc exec c1 -- pgrep -f exec-session-marker || false
+
+  # Check the session ends when its command exits.
+  printf '\020\021' | lxc exec c1 --persistent -t -- sleep 3
+  wait_for_sessions c1 1
+  wait_for_sessions c1 0
+
+  # Check the session ends when the instance is stopped.
+  printf '\020\021' | lxc exec c1 --persistent -t -- sleep 300
+  wait_for_sessions c1 1
+  lxc stop -f c1
+  wait_for_sessions c1 0
+
+  lxc delete c1
+}
@@ -0,0 +1,63 @@
+test_instance_dependencies() {
+  ensure_import_testimage
+
+  (
+    cd devlxd-client || return
+    # Use -buildvcs=false here to prevent git complaining about untrusted directory when tests are run as root.
+    go build -tags netgo -v -buildvcs=false ./...
+  )
+
+  lxc init testimage db
+  lxc file push --mode 0755 "devlxd-client/devlxd-client" db/bin/
+  lxc init testimage app -c boot.depends_on=db -c boot.depends_on.timeout=60
+
+  # Check invalid dependencies are rejected.
+  ! lxc config set app boot.depends_on=in_valid || false
+  ! lxc config set app boot.depends_on.timeout=-1 || false
+
+  # Check a missing dependency fails the start.
+  lxc config set app boot.depends_on=missing,db
+  ! lxc start app --with-deps || false
+  [ "$(lxc list app -c s -f csv)" = "STOPPED" ]
+  lxc config set app boot.depends_on=db
+
+  # Check the dependency is started first and the instance only once the dependency is ready.
+  lxc start app --with-deps &
+  startPID=$!
+
+  for _ in $(seq 30); do
+    if [ "$(lxc list db -c s -f csv)" = "RUNNING" ]; then
+      break
+    fi
+
+    sleep 1
+  done
+
+  [ "$(lxc list db -c s -f csv)" = "RUNNING" ]
+  sleep 2
+  [ "$(lxc list app -c s -f csv)" = "STOPPED" ]
+
+  lxc exec db -- devlxd-client ready-state true
+  wait "${startPID}"
+  [ "$(lxc list app -c s -f csv)" = "RUNNING" ]
+
+  # Check starting an instance without --with-deps ignores the dependencies.
+  lxc stop -f app db
+  lxc start app
+  [ "$(lxc list db -c s -f csv)" = "STOPPED" ]
+
+  # Check the start fails if the dependency doesn't become ready in time.
+  lxc stop -f app
+  lxc config set app boot.depends_on.timeout=5
+  ! lxc start app --with-deps || false
+  [ "$(lxc list db -c s -f csv)" = "RUNNING" ]
+  [ "$(lxc list app -c s -f csv)" = "STOPPED" ]
+
+  # Check dependency cycles don't prevent the start.
+  lxc exec db -- devlxd-client ready-state true
+  lxc config set db boot.depends_on=app
+  lxc start app --with-deps
+  [ "$(lxc list app -c s -f csv)" = "RUNNING" ]
+
+  lxc delete -f app db
+}
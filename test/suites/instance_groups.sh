@@ -0,0 +1,112 @@
+test_instance_groups() {
+  ensure_import_testimage
+
+  # Waits for the instances matching the filter $1 in project $3 (default if unset) to be exactly $2.
+  wait_for_members() {
+    for _ in $(seq 60); do
+      if [ "$(lxc list "${1}" -c n -f csv --project "${3:-default}" | sort | xargs)" = "${2}" ]; then
+        return 0
+      fi
+
+      sleep 1
+    done
+
+    echo "Instances ${1} didn't become ${2}"
+    false
+  }
+
+  # Check invalid groups are rejected.
+  ! lxc group create -- -web testimage < /dev/null || false
+  ! lxc group create web testimage --replicas -1 < /dev/null || false
+  ! lxc group create web testimage --config volatile.foo=bar < /dev/null || false
+  ! lxc group create web testimage invalid.key=foo < /dev/null || false
+  ! lxc group create web testimage load_balancer.network=lxdt$$ < /dev/null || false
+
+  # Check the members are created and started.
+  lxc group create web testimage --replicas 2 --config user.foo=bar < /dev/null
+  lxc group list | grep -wF web
+  wait_for_members web- "web-1 web-2"
+  for _ in $(seq 60); do
+    [ "$(lxc list web- -c s -f csv | xargs)" = "RUNNING RUNNING" ] && break
+    sleep 1
+  done
+
+  [ "$(lxc list web- -c s -f csv | xargs)" = "RUNNING RUNNING" ]
+  [ "$(lxc config get web-1 volatile.instance_group)" = "web" ]
+  [ "$(lxc config get web-2 user.foo)" = "bar" ]
+  lxc group show web | grep -F "/1.0/instances/web-1"
+
+  ! lxc group create web testimage < /dev/null || false
+
+  # Check scaling up and down.
+  lxc group scale web 3
+  wait_for_members web- "web-1 web-2 web-3"
+  lxc group scale web 1
+  wait_for_members web- "web-1"
+
+  # Check the configuration can be updated.
+  lxc group set web load_balancer.protocol=udp
+  [ "$(lxc group get web load_balancer.protocol)" = "udp" ]
+  lxc group unset web load_balancer.protocol
+  [ "$(lxc group get web load_balancer.protocol)" = "" ]
+
+  # Check an instance not managed by the group isn't deleted nor reused.
+  lxc init testimage web-2
+  lxc group scale web 2
+  sleep 5
+  [ -z "$(lxc config get web-2 volatile.instance_group)" ]
+  lxc delete web-2
+
+  # Check deleting the group deletes its members.
+  lxc group delete web
+  ! lxc group show web || false
+  wait_for_members web- ""
+
+  # Check a restricted identity can only use the sources it can view.
+  lxc project create ig-foo -c features.images=false -c features.profiles=false
+  lxc init testimage c1
+  lxc auth group create ig-group
+  lxc auth group permission add ig-group project ig-foo can_view
+  lxc auth group permission add ig-group project ig-foo can_view_instances
+  lxc auth group permission add ig-group project ig-foo can_create_instances
+  lxc auth group permission add ig-group project ig-foo can_delete_instances
+
+  tls_identity_token="$(lxc auth identity create tls/ig-user --quiet --group ig-group)"
+  LXD_CONF2=$(mktemp -d -p "${TEST_DIR}" XXX)
+  LXD_CONF="${LXD_CONF2}" gen_cert_and_key "client"
+  LXD_CONF="${LXD_CONF2}" lxc remote add tls "${tls_identity_token}"
+
+  cat << EOF > "${TEST_DIR}/group.yaml"
+replicas: 1
+template:
+  source:
+    type: copy
+    source: c1
+    project: default
+EOF
+
+  ! LXD_CONF="${LXD_CONF2}" lxc group create tls:copy --project ig-foo < "${TEST_DIR}/group.yaml" || false
+  ! lxc group show copy --project ig-foo || false
+
+  # Check the restricted identity cannot register the members in a load balancer it cannot edit.
+  lxc network create "lxdt$$"
+  ! LXD_CONF="${LXD_CONF2}" lxc group create tls:lb testimage --project ig-foo load_balancer.network="lxdt$$" load_balancer.listen_address=192.0.2.10 load_balancer.listen_port=80 < /dev/null || false
+  ! lxc group show lb --project ig-foo || false
+
+  # Check the same requests are allowed once the identity can view the source.
+  lxc auth group permission add ig-group instance c1 can_view project=default
+  LXD_CONF="${LXD_CONF2}" lxc group create tls:copy --project ig-foo < "${TEST_DIR}/group.yaml"
+  wait_for_members copy- "copy-1" ig-foo
+  LXD_CONF="${LXD_CONF2}" lxc group delete tls:copy --project ig-foo
+  wait_for_members copy- "" ig-foo
+
+  # Cleanup.
+  LXD_CONF="${LXD_CONF2}" lxc remote remove tls
+  lxc auth identity delete "tls/$(cert_fingerprint "${LXD_CONF2}/client.crt")"
+  rm -r "${LXD_CONF2}"
+  rm "${TEST_DIR}/group.yaml"
+  lxc auth group delete ig-group
+  lxc network delete "lxdt$$"
+  lxc delete c1
+  lxc project delete ig-foo
+}
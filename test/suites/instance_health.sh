@@ -0,0 +1,89 @@
+test_instance_health() {
+  ensure_import_testimage
+  ensure_has_localhost_remote "${LXD_ADDR}"
+
+  netName=lxdt$$
+
+  # Waits for the health of instance $1 to become $2 (an empty value waits for it to be cleared).
+  wait_for_health() {
+    for _ in $(seq 60); do
+      if [ "$(lxc config get "${1}" volatile.last_state.health)" = "${2}" ]; then
+        return 0
+      fi
+
+      sleep 1
+    done
+
+    echo "Instance ${1} health didn't become \"${2}\""
+    false
+  }
+
+  lxc network create "${netName}" ipv4.address=192.0.2.1/24 ipv6.address=none
+  lxc launch testimage c1 -n "${netName}"
+  lxc exec c1 -- ip a add 192.0.2.2/24 dev eth0
+
+  # Check invalid settings are rejected.
+  ! lxc config set c1 healthcheck.type=ping || false
+  ! lxc config set c1 healthcheck.address=localhost:8080 || false
+  ! lxc config set c1 healthcheck.address=192.0.2.2 || false
+  ! lxc config set c1 healthcheck.url=http://localhost:8080/ || false
+  ! lxc config set c1 healthcheck.url=file:///etc/passwd || false
+  ! lxc config set c1 healthcheck.interval=5 || false
+  ! lxc config set c1 restart.policy=sometimes || false
+  ! lxc config set c1 restart.backoff=301 || false
+
+  # Check the exec health check.
+  lxc config set c1 healthcheck.type=exec healthcheck.command=true healthcheck.interval=10 healthcheck.threshold=1
+  wait_for_health c1 healthy
+  lxc list c1 -c nH -f csv | grep -xF "c1,HEALTHY"
+
+  lxc config set c1 healthcheck.command=false
+  wait_for_health c1 unhealthy
+
+  # Check the TCP health check connects to the instance.
+  lxc exec c1 --disable-stdin -- sh -c "while true; do nc -l -p 8080 </dev/null; done" >/dev/null 2>&1 &
+  listenerPID=$!
+  lxc config set c1 healthcheck.type=tcp healthcheck.address=192.0.2.2:8080
+  wait_for_health c1 healthy
+
+  # Check the TCP health check can't connect to addresses that don't belong to the instance.
+  nc -l -p 8081 -q0 -s 192.0.2.1 </dev/null >/dev/null &
+  hostListenerPID=$!
+  lxc config set c1 healthcheck.address=192.0.2.1:8081
+  wait_for_health c1 unhealthy
+  kill -9 "${hostListenerPID}" || true
+
+  # Check the HTTP health check can't connect to addresses that don't belong to the instance either.
+  lxc config set c1 healthcheck.address=192.0.2.2:8080
+  wait_for_health c1 healthy
+  lxc config set c1 healthcheck.type=http healthcheck.url=http://192.0.2.1:8081/
+  wait_for_health c1 unhealthy
+  kill -9 "${listenerPID}" || true
+
+  # Check removing the health check clears the health of the instance.
+  lxc config unset c1 healthcheck.type
+  wait_for_health c1 ""
+
+  # Check an unhealthy instance is restarted when its restart policy requires it.
+  lxc config set c1 restart.policy=on-failure restart.backoff=0
+  lxc config set c1 healthcheck.type=exec healthcheck.command=false
+  for _ in $(seq 60); do
+    if [ -n "$(lxc config get c1 volatile.restart.count)" ]; then
+      break
+    fi
+
+    sleep 1
+  done
+
+  [ "$(lxc config get c1 volatile.restart.count)" -ge 1 ]
+
+  # Check an instance stopped through LXD isn't restarted.
+  lxc config unset c1 healthcheck.type
+  lxc config set c1 restart.policy=always
+  lxc stop -f c1
+  sleep 5
+  [ "$(lxc list c1 -c s -f csv)" = "STOPPED" ]
+
+  lxc delete -f c1
+  lxc network delete "${netName}"
+}
@@ -0,0 +1,54 @@
+test_instance_schedule() {
+  ensure_import_testimage
+
+  # Waits for the status of instance $1 to become $2. The schedules are checked every minute.
+  wait_for_status() {
+    for _ in $(seq 130); do
+      if [ "$(lxc list "${1}" -c s -f csv)" = "${2}" ]; then
+        return 0
+      fi
+
+      sleep 1
+    done
+
+    echo "Instance ${1} didn't become ${2}"
+    false
+  }
+
+  lxc init testimage c1
+
+  # Check invalid schedules are rejected.
+  ! lxc config set c1 schedule.start="not a schedule" || false
+  ! lxc config set c1 schedule.stop="* * *" || false
+  ! lxc config set c1 schedule.start="@startup" || false
+  lxc config set c1 schedule.start="@daily, 30 7 * * 1-5" schedule.stop="@never"
+  lxc config unset c1 schedule.stop
+
+  # Check the instance is started on schedule.
+  lxc config set c1 schedule.start="* * * * *"
+  wait_for_status c1 RUNNING
+
+  # Check the instance is stopped on schedule.
+  lxc config unset c1 schedule.start
+  lxc config set c1 schedule.stop="* * * * *" boot.host_shutdown_timeout=5
+  wait_for_status c1 STOPPED
+
+  # Check a protected instance isn't started and that a warning is raised instead.
+  lxc config unset c1 schedule.stop
+  lxc config set c1 security.protection.start=true schedule.start="* * * * *"
+  for _ in $(seq 130); do
+    if lxc warning list --format csv | grep -F "Failed to run instance schedule"; then
+      break
+    fi
+
+    sleep 1
+  done
+
+  lxc warning list --format csv | grep -F "Failed to run instance schedule"
+  [ "$(lxc list c1 -c s -f csv)" = "STOPPED" ]
+
+  lxc config unset c1 schedule.start
+  lxc config unset c1 security.protection.start
+  lxc warning delete --all
+  lxc delete -f c1
+}
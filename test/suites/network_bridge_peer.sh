@@ -0,0 +1,71 @@
+test_network_bridge_peer() {
+  ensure_import_testimage
+  ensure_has_localhost_remote "${LXD_ADDR}"
+
+  firewallDriver=$(lxc info | awk -F ":" '/firewall:/{gsub(/ /, "", $0); print $2}')
+  if [ "$firewallDriver" != "nftables" ]; then
+    echo "==> SKIP: Network isolation and peering require the nftables firewall driver"
+    return
+  fi
+
+  brPrefix="lxdt$$"
+  ctPrefix="nt$$"
+
+  lxc network create "${brPrefix}A" ipv4.address=192.0.2.1/24 ipv4.nat=false ipv6.address=none
+  lxc network create "${brPrefix}B" ipv4.address=198.51.100.1/24 ipv4.nat=false ipv6.address=none
+  lxc network create "${brPrefix}C" ipv4.address=203.0.113.1/24 ipv4.nat=false ipv6.address=none
+
+  # Start an instance on each network with a static address.
+  for net in A B C; do
+    lxc launch testimage "${ctPrefix}${net}" -n "${brPrefix}${net}"
+  done
+
+  lxc exec "${ctPrefix}A" -- ip -4 addr add 192.0.2.10/24 dev eth0
+  lxc exec "${ctPrefix}A" -- ip -4 route add default via 192.0.2.1
+  lxc exec "${ctPrefix}B" -- ip -4 addr add 198.51.100.10/24 dev eth0
+  lxc exec "${ctPrefix}B" -- ip -4 route add default via 198.51.100.1
+  lxc exec "${ctPrefix}C" -- ip -4 addr add 203.0.113.10/24 dev eth0
+  lxc exec "${ctPrefix}C" -- ip -4 route add default via 203.0.113.1
+
+  # Check the host routes traffic between bridge networks by default.
+  lxc exec "${ctPrefix}A" -- ping -c2 -W5 198.51.100.10
+  lxc exec "${ctPrefix}A" -- ping -c2 -W5 203.0.113.10
+  nft -nn list set inet lxd bridges | grep -F "${brPrefix}A"
+  ! nft -nn list chain inet lxd "peer.${brPrefix}A" | grep -F "drop" || false
+
+  # Check an isolated network can't reach the other bridge networks, in both directions.
+  lxc network set "${brPrefix}A" security.isolated=true
+  nft -nn list chain inet lxd "peer.${brPrefix}A" | grep -c "drop" | grep 2
+  ! lxc exec "${ctPrefix}A" -- ping -c2 -W5 198.51.100.10 || false
+  ! lxc exec "${ctPrefix}B" -- ping -c2 -W5 192.0.2.10 || false
+  lxc exec "${ctPrefix}B" -- ping -c2 -W5 203.0.113.10
+
+  # Check a peering only takes effect once it exists on both networks.
+  lxc network unset "${brPrefix}A" security.isolated
+  lxc network peer create "${brPrefix}A" peerB "${brPrefix}B"
+  lxc network peer create "${brPrefix}B" peerA "${brPrefix}A"
+  nft -nn list chain inet lxd "peer.${brPrefix}A" | grep -F "oifname \"${brPrefix}B\" accept"
+  nft -nn list chain inet lxd "peer.${brPrefix}B" | grep -F "oifname \"${brPrefix}A\" accept"
+
+  # Check peered networks can reach each other but are isolated from the other bridge networks.
+  lxc exec "${ctPrefix}A" -- ping -c2 -W5 198.51.100.10
+  lxc exec "${ctPrefix}B" -- ping -c2 -W5 192.0.2.10
+  ! lxc exec "${ctPrefix}A" -- ping -c2 -W5 203.0.113.10 || false
+  ! lxc exec "${ctPrefix}C" -- ping -c2 -W5 198.51.100.10 || false
+
+  # Check removing the peerings removes the isolation.
+  lxc network peer delete "${brPrefix}A" peerB
+  lxc network peer delete "${brPrefix}B" peerA
+  ! nft -nn list chain inet lxd "peer.${brPrefix}A" | grep -F "drop" || false
+  ! nft -nn list chain inet lxd "peer.${brPrefix}B" | grep -F "drop" || false
+  lxc exec "${ctPrefix}A" -- ping -c2 -W5 203.0.113.10
+  lxc exec "${ctPrefix}C" -- ping -c2 -W5 198.51.100.10
+
+  # Check deleting the networks cleans up the firewall.
+  for net in A B C; do
+    lxc delete -f "${ctPrefix}${net}"
+    lxc network delete "${brPrefix}${net}"
+    ! nft -nn list chain inet lxd "peer.${brPrefix}${net}" || false
+    ! nft -nn list set inet lxd bridges | grep -F "${brPrefix}${net}" || false
+  done
+}
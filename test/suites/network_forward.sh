@@ -132,6 +132,59 @@ test_network_forward() {
     [ "$(nft -nn list chain inet lxd "fwdpstrt.${netName}" | wc -l)" -eq 7 ]
   fi
 
+  # Check a NIC can't use the listen address of a forward as its own SNAT address.
+  lxc network set "${netName}" ipv4.nat=true
+  lxc init testimage c1
+  lxc config device add c1 eth1 nic network="${netName}" ipv4.address=192.0.2.20
+  ! lxc config device set c1 eth1 ipv4.nat.address=198.51.100.1 || false
+  lxc config device set c1 eth1 ipv4.nat.address=198.51.100.2
+
+  # Check the SNAT rules of a running NIC follow changes to its addresses.
+  lxc start c1
+  if [ "$firewallDriver" = "xtables" ]; then
+    iptables -w -t nat -S POSTROUTING | grep -F -- "-s 192.0.2.20/32" | grep -F -- "--to-source 198.51.100.2"
+  else
+    nft -nn list ruleset | grep -F "saddr 192.0.2.20 " | grep -F "snat ip to 198.51.100.2"
+  fi
+
+  lxc config device set c1 eth1 ipv4.address=192.0.2.21
+  if [ "$firewallDriver" = "xtables" ]; then
+    ! iptables -w -t nat -S POSTROUTING | grep -F -- "-s 192.0.2.20/32" || false
+    iptables -w -t nat -S POSTROUTING | grep -F -- "-s 192.0.2.21/32" | grep -F -- "--to-source 198.51.100.2"
+  else
+    ! nft -nn list ruleset | grep -F "saddr 192.0.2.20 " || false
+    nft -nn list ruleset | grep -F "saddr 192.0.2.21 " | grep -F "snat ip to 198.51.100.2"
+  fi
+
+  lxc config device set c1 eth1 ipv4.nat.address=198.51.100.3
+  if [ "$firewallDriver" = "xtables" ]; then
+    ! iptables -w -t nat -S POSTROUTING | grep -F -- "--to-source 198.51.100.2" || false
+    iptables -w -t nat -S POSTROUTING | grep -F -- "-s 192.0.2.21/32" | grep -F -- "--to-source 198.51.100.3"
+  else
+    ! nft -nn list ruleset | grep -F "snat ip to 198.51.100.2" || false
+    nft -nn list ruleset | grep -F "saddr 192.0.2.21 " | grep -F "snat ip to 198.51.100.3"
+  fi
+
+  # Check a NIC of the same project can share the SNAT address of the NIC, but not a NIC of another project or a forward.
+  lxc init testimage c2
+  lxc config device add c2 eth1 nic network="${netName}" ipv4.address=192.0.2.22 ipv4.nat.address=198.51.100.3
+  lxc project create "${netName}" -c features.images=false -c features.profiles=false -c features.networks=false
+  lxc init testimage c3 --project "${netName}"
+  ! lxc config device add c3 eth1 nic network="${netName}" ipv4.address=192.0.2.23 ipv4.nat.address=198.51.100.3 --project "${netName}" || false
+  lxc delete c3 --project "${netName}"
+  lxc project delete "${netName}"
+  ! lxc network forward create "${netName}" 198.51.100.3 || false
+
+  lxc config device unset c1 eth1 ipv4.nat.address
+  if [ "$firewallDriver" = "xtables" ]; then
+    ! iptables -w -t nat -S POSTROUTING | grep -F -- "-s 192.0.2.21/32" || false
+  else
+    ! nft -nn list ruleset | grep -F "saddr 192.0.2.21 " || false
+  fi
+
+  lxc delete -f c1 c2
+  lxc network unset "${netName}" ipv4.nat
+
   # Check forward is exported via BGP prefixes before network delete.
   lxc query /internal/testing/bgp | grep "198.51.100.1/32"
 
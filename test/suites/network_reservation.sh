@@ -0,0 +1,58 @@
+test_network_reservation() {
+  ensure_import_testimage
+  ensure_has_localhost_remote "${LXD_ADDR}"
+
+  netName=lxdt$$
+
+  lxc network create "${netName}" \
+        ipv4.address=192.0.2.1/24 \
+        ipv6.address=fd42:4242:4242:1010::1/64 \
+        ipv6.dhcp.stateful=true
+
+  # Check reservations outside of the network subnet or for the network address are rejected.
+  ! lxc network reservation create "${netName}" 198.51.100.10 hwaddr=00:16:3e:00:00:01 || false
+  ! lxc network reservation create "${netName}" 192.0.2.1 hwaddr=00:16:3e:00:00:01 || false
+
+  # Check the host must be identified by exactly one of its MAC address or client identifier.
+  ! lxc network reservation create "${netName}" 192.0.2.10 || false
+  ! lxc network reservation create "${netName}" 192.0.2.10 hwaddr=00:16:3e:00:00:01 client_id=01:02:03 || false
+
+  # Check a reservation with DHCP options is written to dnsmasq.
+  lxc network reservation create "${netName}" 192.0.2.10 hwaddr=00:16:3e:00:00:01 hostname=reserved ipv4.dhcp.option.ntp-server=192.0.2.123
+  grep -F "00:16:3e:00:00:01,set:lxd-reservation-" "${LXD_DIR}/networks/${netName}/dnsmasq.reservations/192.0.2.10"
+  grep -F "option:ntp-server,192.0.2.123" "${LXD_DIR}/networks/${netName}/dnsmasq.reservations.options/192.0.2.10"
+
+  # Check options for the other IP protocol and unknown options are rejected.
+  ! lxc network reservation set "${netName}" 192.0.2.10 ipv6.dhcp.option.ntp-server=fd42:4242:4242:1010::123 || false
+  ! lxc network reservation set "${netName}" 192.0.2.10 ipv4.dhcp.option.foo=bar || false
+
+  # Check removing the DHCP options removes the options file.
+  lxc network reservation unset "${netName}" 192.0.2.10 ipv4.dhcp.option.ntp-server
+  ! grep -F "set:" "${LXD_DIR}/networks/${netName}/dnsmasq.reservations/192.0.2.10" || false
+  [ ! -e "${LXD_DIR}/networks/${netName}/dnsmasq.reservations.options/192.0.2.10" ]
+
+  # Check the same host can't have two reservations for the same IP protocol.
+  ! lxc network reservation create "${netName}" 192.0.2.11 hwaddr=00:16:3e:00:00:01 || false
+  lxc network reservation create "${netName}" fd42:4242:4242:1010::10 hwaddr=00:16:3e:00:00:01
+
+  # Check a NIC can't use an address reserved for another host.
+  lxc init testimage c1
+  ! lxc config device add c1 eth0 nic network="${netName}" ipv4.address=192.0.2.10 || false
+  lxc config device add c1 eth0 nic network="${netName}" ipv4.address=192.0.2.10 hwaddr=00:16:3e:00:00:01
+  lxc config device remove c1 eth0
+
+  # Check a reservation can't use an address statically assigned to another NIC.
+  lxc config device add c1 eth0 nic network="${netName}" ipv4.address=192.0.2.20 hwaddr=00:16:3e:00:00:02
+  ! lxc network reservation create "${netName}" 192.0.2.20 hwaddr=00:16:3e:00:00:03 || false
+  ! lxc network reservation create "${netName}" 192.0.2.20 client_id=01:02:03 || false
+  lxc network reservation create "${netName}" 192.0.2.20 hwaddr=00:16:3e:00:00:02
+  ! lxc network reservation set "${netName}" 192.0.2.20 hwaddr=00:16:3e:00:00:03 || false
+
+  # Check deleting reservations removes them from dnsmasq.
+  lxc network reservation delete "${netName}" 192.0.2.10
+  [ ! -e "${LXD_DIR}/networks/${netName}/dnsmasq.reservations/192.0.2.10" ]
+  [ "$(lxc network reservation list "${netName}" --format csv | wc -l)" = "2" ]
+
+  lxc delete -f c1
+  lxc network delete "${netName}"
+}
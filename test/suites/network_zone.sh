@@ -143,6 +143,32 @@ test_network_zone() {
   [ "$(dig "@${DNS_ADDR}" -p "${DNS_PORT}" axfr lxdfoo.example.net | grep -Fc demo.lxdfoo.example.net)" = "6" ]
   lxc network zone record entry remove lxdfoo.example.net demo A 1.1.1.1 --project foo
 
+  # Test zone import and export
+  lxc network zone export lxd.example.net > "${TEST_DIR}/lxd.example.net.db"
+  grep -F "c1.lxd.example.net." "${TEST_DIR}/lxd.example.net.db"
+  cat >> "${TEST_DIR}/lxd.example.net.db" <<EOF
+@ 300 IN MX 10 mail.example.net.
+@ 300 IN TXT "v=spf1 -all"
+imported 300 IN A 3.3.3.3
+EOF
+  lxc network zone import lxd.example.net "${TEST_DIR}/lxd.example.net.db"
+  lxc network zone record get lxd.example.net @ | grep -F "mail.example.net."
+  lxc network zone record get lxd.example.net imported | grep -F "3.3.3.3"
+  dig "@${DNS_ADDR}" -p "${DNS_PORT}" axfr lxd.example.net | grep "^lxd.example.net.\s\+300\s\+IN\s\+MX\s\+10 mail.example.net."
+
+  # The generated records aren't imported and importing again doesn't duplicate entries
+  ! lxc network zone record get lxd.example.net c1 || false
+  lxc network zone import lxd.example.net "${TEST_DIR}/lxd.example.net.db"
+  [ "$(lxc network zone record get lxd.example.net imported | grep -cF "3.3.3.3")" = "1" ]
+
+  # Files with records outside of the zone are rejected as a whole
+  printf 'atomic 300 IN A 4.4.4.4\nother.example.com. 300 IN A 5.5.5.5\n' > "${TEST_DIR}/invalid.db"
+  ! lxc network zone import lxd.example.net "${TEST_DIR}/invalid.db" || false
+  ! lxc network zone record get lxd.example.net atomic || false
+  lxc network zone record delete lxd.example.net @
+  lxc network zone record delete lxd.example.net imported
+  rm "${TEST_DIR}/lxd.example.net.db" "${TEST_DIR}/invalid.db"
+
   # Check that the listener survives a restart of LXD
   shutdown_lxd "${LXD_DIR}"
   respawn_lxd "${LXD_DIR}" true
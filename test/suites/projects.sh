@@ -560,6 +560,64 @@ test_projects_network() {
   lxc network delete "${network}"
 }
 
+test_projects_network_limits() {
+  firewallDriver=$(lxc info | awk -F ":" '/firewall:/{gsub(/ /, "", $0); print $2}')
+
+  lxc project create foo -c features.images=false -c features.profiles=false
+
+  # Invalid rates are rejected.
+  ! lxc project set foo limits.network.egress=10MiB/s || false
+
+  if [ "${firewallDriver}" = "xtables" ]; then
+    # Project network limits are rejected when the firewall doesn't support them.
+    ! lxc project set foo limits.network.egress=10Mbit || false
+    lxc project delete foo
+    return
+  fi
+
+  ensure_import_testimage
+
+  network="lxdt$$"
+  lxc network create "${network}"
+
+  lxc project set foo limits.network.egress=10Mbit
+  lxc launch testimage c1 --project foo -n "${network}"
+  vethHostName=$(lxc config get c1 volatile.eth0.host_name --project foo)
+
+  # Check the shared limit is applied to the host side of the NIC.
+  nft -nn list limit netdev lxd "netlimit.foo.egress.1250000" | grep "rate over 1250000 bytes/second"
+  nft -nn list chain netdev lxd "ingress.netlimit.foo_c1.${vethHostName}" | grep "netlimit.foo.egress.1250000"
+
+  # Check a second instance uses the same limit.
+  lxc launch testimage c2 --project foo -n "${network}"
+  vethHostName2=$(lxc config get c2 volatile.eth0.host_name --project foo)
+  nft -nn list chain netdev lxd "ingress.netlimit.foo_c2.${vethHostName2}" | grep "netlimit.foo.egress.1250000"
+
+  # Check changing the limit applies it to the running instances and removes the old one.
+  lxc project set foo limits.network.egress=20Mbit
+  nft -nn list chain netdev lxd "ingress.netlimit.foo_c1.${vethHostName}" | grep "netlimit.foo.egress.2500000"
+  nft -nn list chain netdev lxd "ingress.netlimit.foo_c2.${vethHostName2}" | grep "netlimit.foo.egress.2500000"
+  ! nft -nn list limit netdev lxd "netlimit.foo.egress.1250000" || false
+
+  # Check unsetting the limit removes the rules.
+  lxc project unset foo limits.network.egress
+  ! nft -nn list chain netdev lxd "ingress.netlimit.foo_c1.${vethHostName}" || false
+  ! nft -nn list limit netdev lxd "netlimit.foo.egress.2500000" || false
+
+  # Check the rules are removed when the instance stops.
+  lxc project set foo limits.network.egress=10Mbit
+  nft -nn list chain netdev lxd "ingress.netlimit.foo_c1.${vethHostName}"
+  lxc stop -f c1 --project foo
+  ! nft -nn list chain netdev lxd "ingress.netlimit.foo_c1.${vethHostName}" || false
+  nft -nn list limit netdev lxd "netlimit.foo.egress.1250000"
+  lxc stop -f c2 --project foo
+  ! nft -nn list limit netdev lxd "netlimit.foo.egress.1250000" || false
+
+  lxc delete c1 c2 --project foo
+  lxc project delete foo
+  lxc network delete "${network}"
+}
+
 # Set resource limits on projects.
 test_projects_limits() {
   # Create a project